@@ -24,6 +24,8 @@ func NewCgroupCommand() *cobra.Command {
 
 	cgroupCmd.AddCommand(
 		NewRecreateCommand(),
+		NewStaleCommand(),
+		NewReapCommand(),
 	)
 
 	return cgroupCmd
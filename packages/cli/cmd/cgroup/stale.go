@@ -0,0 +1,102 @@
+package cgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/spf13/cobra"
+)
+
+func NewStaleCommand() *cobra.Command {
+	staleCmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List the stale cgroups of sandboxes",
+		Long: `List the cgroups of sandboxes which have no live process and are not
+maintained by orchestrator (e.g., left by crashed sandboxes).
+
+Example:
+sandbox-cli cgroup stale
+`,
+		SilenceUsage: true,
+		RunE:         lsStale,
+	}
+	return staleCmd
+}
+
+func NewReapCommand() *cobra.Command {
+	reapCmd := &cobra.Command{
+		Use:   "reap",
+		Short: "Remove the stale cgroups of sandboxes",
+		Long: `Remove the stale cgroups (see the stale command) with the sandbox ids.
+
+Example:
+sandbox-cli cgroup reap 554a78c8-b80b-48ab-ac60-97c1b4912993
+sandbox-cli cgroup reap --all
+`,
+		SilenceUsage: true,
+		RunE:         reap,
+	}
+	reapCmd.Flags().BoolP("all", "a", false, "Reap all stale cgroups")
+	return reapCmd
+}
+
+func newClient(cmd *cobra.Command) (orchestrator.HostManageClient, error) {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	return lib.NewOrchestratorHostManageClient(ip, port)
+}
+
+func lsStale(cmd *cobra.Command, args []string) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ListStaleCgroups(context.Background(), &empty.Empty{})
+	if err != nil {
+		return fmt.Errorf("list stale cgroups failed: %w", err)
+	}
+	for _, cg := range resp.Cgroups {
+		fmt.Printf("%s\t%s\n", cg.SandboxID, cg.Path)
+	}
+	fmt.Printf("%d stale cgroups found\n", len(resp.Cgroups))
+	return nil
+}
+
+func reap(cmd *cobra.Command, args []string) error {
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return err
+	}
+	if !all && len(args) == 0 {
+		return fmt.Errorf("either specify sandbox ids or --all")
+	}
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+	resp, err := client.ReapCgroups(context.Background(), &orchestrator.HostManageReapCgroupsRequest{
+		All:        all,
+		SandboxIDs: args,
+	})
+	if err != nil {
+		return fmt.Errorf("reap cgroups failed: %w", err)
+	}
+	for _, sandboxID := range resp.Reaped {
+		fmt.Printf("reaped: %s\n", sandboxID)
+	}
+	var finalErr error
+	for sandboxID, errMsg := range resp.Failed {
+		finalErr = errors.Join(finalErr, fmt.Errorf("reap %s failed: %s", sandboxID, errMsg))
+	}
+	return finalErr
+}
@@ -17,6 +17,7 @@ func NewNetworkCommand() *cobra.Command {
 
 	networkCmd.AddCommand(
 		NewDeleteCommand(),
+		NewListCommand(),
 	)
 	return networkCmd
 }
@@ -0,0 +1,49 @@
+package network
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/spf13/cobra"
+)
+
+func NewListCommand() *cobra.Command {
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the network environments maintained by orchestrator.",
+		Long: `List the network environments maintained by orchestrator, including
+the network index, state, the sandbox using it and its addresses.
+
+Example:
+sandbox-cli network ls
+sandbox-cli network ls --ip 127.0.0.1 --port 5000
+		`,
+		RunE:         lsSandboxNet,
+		SilenceUsage: true,
+	}
+	return lsCmd
+}
+
+func lsSandboxNet(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorHostManageClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ListNetworks(context.Background(), &empty.Empty{})
+	if err != nil {
+		return fmt.Errorf("list network env failed: %w", err)
+	}
+	lib.PrintNetworkInfo("Network environments in orchestrator", resp.Networks...)
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/X-code-interpreter/sandbox-backend/packages/cli/cmd/cgroup"
 	"github.com/X-code-interpreter/sandbox-backend/packages/cli/cmd/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/cli/cmd/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/cmd/template"
 	"github.com/spf13/cobra"
 )
 
@@ -51,6 +52,7 @@ func init() {
 		sandbox.NewSandboxCommand(),
 		cgroup.NewCgroupCommand(),
 		network.NewNetworkCommand(),
+		template.NewTemplateCommand(),
 	)
 }
 
@@ -0,0 +1,52 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewAdoptCommand() *cobra.Command {
+	adoptCmd := &cobra.Command{
+		Use:   "adopt",
+		Short: "Manage the orphan sandboxes by the orchestrator instead of purging them",
+		Long: `Take over the orphan sandboxes (see "ls --orphan"), so that they can be deleted
+or snapshotted as usual. All of them are adopted when no sandbox id is given, the ones
+cannot be adopted are skipped and left as orphans. For example:
+
+  sandbox-cli sandbox adopt 554a78c8-b80b-48ab-ac60-97c1b4912993
+`,
+		RunE: adopt,
+	}
+	return adoptCmd
+}
+
+func adopt(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.AdoptOrphans(context.Background(), &orchestrator.SandboxAdoptOrphansRequest{SandboxIDs: args})
+	if err != nil {
+		return fmt.Errorf("adopt orphan sandboxes failed: %w", err)
+	}
+	for _, info := range resp.Adopted {
+		fmt.Printf("adopted: %s\n", info.SandboxID)
+	}
+	for _, skipped := range resp.Skipped {
+		fmt.Printf("skipped: %s (%s)\n", skipped.SandboxID, skipped.Reason)
+	}
+	return nil
+}
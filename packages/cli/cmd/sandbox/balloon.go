@@ -0,0 +1,64 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewBalloonCommand() *cobra.Command {
+	balloonCmd := &cobra.Command{
+		Use:   "balloon",
+		Short: "Set the balloon size (in MiB) of a sandbox to reclaim its memory",
+		Long: `Inflate (or deflate with --deflate) the balloon of a sandbox to the target size in MiB.
+The template of the sandbox should be built with balloon enabled. For example:
+
+  sandbox-cli sandbox balloon 554a78c8-b80b-48ab-ac60-97c1b4912993 256
+  sandbox-cli sandbox balloon --deflate 554a78c8-b80b-48ab-ac60-97c1b4912993 0
+`,
+		Args: cobra.ExactArgs(2),
+		RunE: setBalloon,
+	}
+	balloonCmd.Flags().Bool("deflate", false, "deflate the balloon (instead of inflate)")
+	return balloonCmd
+}
+
+func setBalloon(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	deflate, err := cmd.Flags().GetBool("deflate")
+	if err != nil {
+		return fmt.Errorf("cannot get deflate from args: %w", err)
+	}
+	sizeMiB, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid balloon size %s: %w", args[1], err)
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	req := &orchestrator.SandboxBalloonRequest{SandboxID: args[0], SizeMiB: sizeMiB}
+	var resp *orchestrator.SandboxBalloonResponse
+	if deflate {
+		resp, err = client.DeflateBalloon(context.Background(), req)
+	} else {
+		resp, err = client.InflateBalloon(context.Background(), req)
+	}
+	if err != nil {
+		return fmt.Errorf("set balloon of %s failed: %w", args[0], err)
+	}
+	fmt.Printf("balloon of %s set to %d MiB\n", args[0], resp.SizeMiB)
+	return nil
+}
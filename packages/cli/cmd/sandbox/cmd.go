@@ -20,7 +20,15 @@ func NewSandboxCommand() *cobra.Command {
 		NewDeleteCommand(),
 		NewListCommand(),
 		NewPurgeCommand(),
+		NewPurgeFailedCommand(),
+		NewAdoptCommand(),
+		NewSelfTestCommand(),
 		NewSnapshotCommand(),
+		NewMetadataCommand(),
+		NewSyncClockCommand(),
+		NewRefreshPrometheusTargetCommand(),
+		NewBalloonCommand(),
+		NewMemCommand(),
 	)
 
 	return sandboxCmd
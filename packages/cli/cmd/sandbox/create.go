@@ -7,6 +7,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
@@ -24,6 +28,12 @@ func NewCreateCommand() *cobra.Command {
   sandbox-cli sandbox create --template default-sandbox
   # enable diff snapshot
   sandbox-cli sandbox create --template default-sandbox --enable-diff-snapshot
+  # attach cloud-init user-data (the template must enable config_drive)
+  sandbox-cli sandbox create --template default-sandbox --user-data ./user-data.yaml
+  # attach a 1 GiB empty scratch disk and a read-only disk copied from a file
+  sandbox-cli sandbox create --template default-sandbox --extra-disk 1024 --extra-disk backing=./data.img,ro
+  # create 10 sandboxes in a batch, delete all of them if any one fails
+  sandbox-cli sandbox create --template default-sandbox --count 10 --atomic
   # set the ip address and port of the orchestrator
   sandbox-cli sandbox create --ip 127.0.0.1 --port 5000 --template mini-agent
 `,
@@ -33,6 +43,24 @@ func NewCreateCommand() *cobra.Command {
 	createCmd.Flags().StringP("template", "t", "", "The template used for created sandbox")
 	createCmd.MarkFlagRequired("template")
 	createCmd.Flags().Bool("enable-diff-snapshot", false, "enable diff snapshot for the sandbox (to be used while creating snapshot later)")
+	createCmd.Flags().String("user-data", "", "path to the cloud-init user-data file exposed through the config drive")
+	createCmd.Flags().String("cgroup-cpu-max", "", "the limit written into cpu.max of sandbox cgroup (e.g., \"200000 100000\"), by default derived from the template")
+	createCmd.Flags().Int64("cgroup-memory-max", 0, "the limit (in bytes) written into memory.max of sandbox cgroup, by default derived from the template")
+	createCmd.Flags().String("cgroup-subpath", "", "place the sandbox cgroup under this subtree of the orchestrator cgroup (e.g., \"team-a/project-b\")")
+	createCmd.Flags().StringArray("extra-disk", nil, "attach an extra disk: SIZE_MB[,ro][,backing=PATH] (can be repeated)")
+	createCmd.Flags().String("working-dir", "", "the default working dir of processes spawned by envd (firecracker only)")
+	createCmd.Flags().StringToString("env", nil, "the default key=value env vars of processes spawned by envd (firecracker only)")
+	createCmd.Flags().StringArray("egress-allow", nil, "only allow the egress to: CIDR[,PROTO[,PORT]] (can be repeated)")
+	createCmd.Flags().StringArray("egress-deny", nil, "deny the egress to: CIDR[,PROTO[,PORT]] (can be repeated)")
+	createCmd.Flags().Bool("console-log", false, "tee the vmm output into console.log under the instance dir, by default follows console_log of orchestrator")
+	createCmd.Flags().String("guest-mac", "", "the mac address of guest interface applied after restored, by default keeps the one of template")
+	createCmd.Flags().Int64("memory-override-mb", 0, "the memory (in MiB) of guest after restored (cloud-hypervisor only, grown by memory hotplug), by default the one of template")
+	createCmd.Flags().Int64("disk-bandwidth-bps", 0, "limit the disk bandwidth (in bytes/s, reads and writes together) of the sandbox, by default follows the template")
+	createCmd.Flags().Int64("disk-iops", 0, "limit the disk iops (reads and writes together) of the sandbox, by default follows the template")
+	createCmd.Flags().Bool("cold-boot", false, "boot from the rootfs of template instead of restoring from its snapshot (much slower, for debugging the template)")
+	createCmd.Flags().String("kernel-image", "", "boot with this kernel image (an absolute path on the host of orchestrator) instead of the one of template, requires cold-boot")
+	createCmd.Flags().Int64("count", 1, "the number of sandboxes to create (in a batch)")
+	createCmd.Flags().Bool("atomic", false, "delete the created sandboxes in the batch if any one fails")
 	return createCmd
 }
 
@@ -54,18 +82,172 @@ func create(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("cannot get enable-diff-snapshot from args: %w", err)
 	}
+	userDataPath, err := cmd.Flags().GetString("user-data")
+	if err != nil {
+		return fmt.Errorf("cannot get user-data from args: %w", err)
+	}
+	cgroupCpuMax, err := cmd.Flags().GetString("cgroup-cpu-max")
+	if err != nil {
+		return fmt.Errorf("cannot get cgroup-cpu-max from args: %w", err)
+	}
+	cgroupMemoryMax, err := cmd.Flags().GetInt64("cgroup-memory-max")
+	if err != nil {
+		return fmt.Errorf("cannot get cgroup-memory-max from args: %w", err)
+	}
+	cgroupSubpath, err := cmd.Flags().GetString("cgroup-subpath")
+	if err != nil {
+		return fmt.Errorf("cannot get cgroup-subpath from args: %w", err)
+	}
+	count, err := cmd.Flags().GetInt64("count")
+	if err != nil {
+		return fmt.Errorf("cannot get count from args: %w", err)
+	}
+	atomic, err := cmd.Flags().GetBool("atomic")
+	if err != nil {
+		return fmt.Errorf("cannot get atomic from args: %w", err)
+	}
+	extraDiskArgs, err := cmd.Flags().GetStringArray("extra-disk")
+	if err != nil {
+		return fmt.Errorf("cannot get extra-disk from args: %w", err)
+	}
+	var extraDisks []*orchestrator.DiskSpec
+	for _, arg := range extraDiskArgs {
+		disk, err := parseDiskSpec(arg)
+		if err != nil {
+			return err
+		}
+		extraDisks = append(extraDisks, disk)
+	}
+	coldBoot, err := cmd.Flags().GetBool("cold-boot")
+	if err != nil {
+		return fmt.Errorf("cannot get cold-boot from args: %w", err)
+	}
+	kernelImage, err := cmd.Flags().GetString("kernel-image")
+	if err != nil {
+		return fmt.Errorf("cannot get kernel-image from args: %w", err)
+	}
+	workingDir, err := cmd.Flags().GetString("working-dir")
+	if err != nil {
+		return fmt.Errorf("cannot get working-dir from args: %w", err)
+	}
+	env, err := cmd.Flags().GetStringToString("env")
+	if err != nil {
+		return fmt.Errorf("cannot get env from args: %w", err)
+	}
+	egressPolicy, err := parseEgressPolicy(cmd)
+	if err != nil {
+		return err
+	}
 	client, err := lib.NewOrchestratorSbxClient(ip, port)
 	if err != nil {
 		return err
 	}
 
+	if count > 1 {
+		if userDataPath != "" {
+			return fmt.Errorf("user-data is not supported when creating in a batch")
+		}
+		if len(extraDisks) > 0 {
+			return fmt.Errorf("extra-disk is not supported when creating in a batch")
+		}
+		if workingDir != "" || len(env) > 0 {
+			return fmt.Errorf("working-dir and env are not supported when creating in a batch")
+		}
+		if egressPolicy != nil {
+			return fmt.Errorf("egress policy is not supported when creating in a batch")
+		}
+		if cmd.Flags().Changed("console-log") {
+			return fmt.Errorf("console-log is not supported when creating in a batch")
+		}
+		if cmd.Flags().Changed("guest-mac") {
+			return fmt.Errorf("guest-mac is not supported when creating in a batch")
+		}
+		if cmd.Flags().Changed("memory-override-mb") {
+			return fmt.Errorf("memory-override-mb is not supported when creating in a batch")
+		}
+		if cmd.Flags().Changed("disk-bandwidth-bps") || cmd.Flags().Changed("disk-iops") {
+			return fmt.Errorf("disk rate limit is not supported when creating in a batch")
+		}
+		if coldBoot {
+			return fmt.Errorf("cold-boot is not supported when creating in a batch")
+		}
+		if kernelImage != "" {
+			return fmt.Errorf("kernel-image is not supported when creating in a batch")
+		}
+		if cgroupSubpath != "" {
+			return fmt.Errorf("cgroup-subpath is not supported when creating in a batch")
+		}
+		return createBatch(client, &orchestrator.SandboxCreateBatchRequest{
+			TemplateID:          template,
+			Count:               count,
+			SandboxIDPrefix:     uuid.New().String(),
+			MaxInstanceLength:   3,
+			EnableDiffSnapshots: enableDiffSnapshot,
+			Atomic:              atomic,
+		})
+	}
+
 	sandboxID := uuid.New()
 	req := &orchestrator.SandboxCreateRequest{
 		TemplateID: template,
 		// NOTE(huang-jl): This has not been used for now
-		MaxInstanceLength:   3,
-		SandboxID:           sandboxID.String(),
-		EnableDiffSnapshots: enableDiffSnapshot,
+		MaxInstanceLength:       3,
+		SandboxID:               sandboxID.String(),
+		EnableDiffSnapshots:     enableDiffSnapshot,
+		ExtraDisks:              extraDisks,
+		Env:                     env,
+		EgressPolicy:            egressPolicy,
+		ColdBoot:                coldBoot,
+		CgroupSubpath:           cgroupSubpath,
+		KernelImagePathOverride: kernelImage,
+	}
+	if workingDir != "" {
+		req.WorkingDir = &workingDir
+	}
+	if cgroupCpuMax != "" {
+		req.CgroupCpuMax = &cgroupCpuMax
+	}
+	if cgroupMemoryMax != 0 {
+		req.CgroupMemoryMax = &cgroupMemoryMax
+	}
+	if cmd.Flags().Changed("console-log") {
+		consoleLog, err := cmd.Flags().GetBool("console-log")
+		if err != nil {
+			return fmt.Errorf("cannot get console-log from args: %w", err)
+		}
+		req.ConsoleLog = &consoleLog
+	}
+	if cmd.Flags().Changed("guest-mac") {
+		guestMac, err := cmd.Flags().GetString("guest-mac")
+		if err != nil {
+			return fmt.Errorf("cannot get guest-mac from args: %w", err)
+		}
+		req.GuestMac = &guestMac
+	}
+	if cmd.Flags().Changed("memory-override-mb") {
+		memoryOverrideMB, err := cmd.Flags().GetInt64("memory-override-mb")
+		if err != nil {
+			return fmt.Errorf("cannot get memory-override-mb from args: %w", err)
+		}
+		req.MemoryOverrideMB = &memoryOverrideMB
+	}
+	diskBandwidthBps, err := cmd.Flags().GetInt64("disk-bandwidth-bps")
+	if err != nil {
+		return fmt.Errorf("cannot get disk-bandwidth-bps from args: %w", err)
+	}
+	req.DiskBandwidthBps = diskBandwidthBps
+	diskIops, err := cmd.Flags().GetInt64("disk-iops")
+	if err != nil {
+		return fmt.Errorf("cannot get disk-iops from args: %w", err)
+	}
+	req.DiskIops = diskIops
+	if userDataPath != "" {
+		userData, err := os.ReadFile(userDataPath)
+		if err != nil {
+			return fmt.Errorf("cannot read user-data file: %w", err)
+		}
+		userDataStr := string(userData)
+		req.CloudInitUserData = &userDataStr
 	}
 	ctx := context.Background()
 	_, err = client.Create(ctx, req)
@@ -79,3 +261,99 @@ func create(cmd *cobra.Command, args []string) error {
 	fmt.Printf("sandbox create succeed, id: %s\n", sandboxID.String())
 	return nil
 }
+
+func createBatch(client orchestrator.SandboxClient, req *orchestrator.SandboxCreateBatchRequest) error {
+	resp, err := client.CreateBatch(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("sandbox batch created failed: %w", err)
+	}
+	var failed int
+	for _, item := range resp.Items {
+		if item.Error != "" {
+			failed++
+			fmt.Printf("sandbox create failed, id: %s, error: %s\n", item.SandboxID, item.Error)
+		} else {
+			fmt.Printf("sandbox create succeed, id: %s\n", item.SandboxID)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d sandboxes failed to create", failed, len(resp.Items))
+	}
+	return nil
+}
+
+// Parse SIZE_MB[,ro][,backing=PATH], the size can be omitted when
+// the backing file is specified.
+func parseDiskSpec(arg string) (*orchestrator.DiskSpec, error) {
+	disk := &orchestrator.DiskSpec{}
+	for _, field := range strings.Split(arg, ",") {
+		switch {
+		case field == "ro":
+			disk.ReadOnly = true
+		case strings.HasPrefix(field, "backing="):
+			path, err := filepath.Abs(strings.TrimPrefix(field, "backing="))
+			if err != nil {
+				return nil, fmt.Errorf("invalid backing file of extra disk %q: %w", arg, err)
+			}
+			disk.BackingFile = path
+		default:
+			size, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid extra disk %q: %w", arg, err)
+			}
+			disk.SizeMB = size
+		}
+	}
+	return disk, nil
+}
+
+// Parse --egress-allow and --egress-deny, return nil if neither is specified.
+func parseEgressPolicy(cmd *cobra.Command) (*orchestrator.EgressPolicy, error) {
+	allowArgs, err := cmd.Flags().GetStringArray("egress-allow")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get egress-allow from args: %w", err)
+	}
+	denyArgs, err := cmd.Flags().GetStringArray("egress-deny")
+	if err != nil {
+		return nil, fmt.Errorf("cannot get egress-deny from args: %w", err)
+	}
+	if len(allowArgs) == 0 && len(denyArgs) == 0 {
+		return nil, nil
+	}
+	policy := &orchestrator.EgressPolicy{}
+	for _, arg := range allowArgs {
+		rule, err := parseEgressRule(arg)
+		if err != nil {
+			return nil, err
+		}
+		policy.Allow = append(policy.Allow, rule)
+	}
+	for _, arg := range denyArgs {
+		rule, err := parseEgressRule(arg)
+		if err != nil {
+			return nil, err
+		}
+		policy.Deny = append(policy.Deny, rule)
+	}
+	return policy, nil
+}
+
+// Parse CIDR[,PROTO[,PORT]].
+func parseEgressRule(arg string) (*orchestrator.EgressRule, error) {
+	fields := strings.Split(arg, ",")
+	if len(fields) > 3 {
+		return nil, fmt.Errorf("invalid egress rule %q", arg)
+	}
+	rule := &orchestrator.EgressRule{Cidr: fields[0]}
+	if len(fields) > 1 {
+		rule.Protocol = fields[1]
+	}
+	if len(fields) > 2 {
+		port, err := strconv.ParseUint(fields[2], 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid port of egress rule %q: %w", arg, err)
+		}
+		rule.Port = uint32(port)
+	}
+	return rule, nil
+}
@@ -18,6 +18,8 @@ func NewListCommand() *cobra.Command {
 		Long: `list sandbox and its information, for example:
   sandbox-cli sandbox ls -a
   sandbox-cli sandbox ls --orphan
+  # only list sandboxes of template default-fc with metadata user=alice
+  sandbox-cli sandbox ls -a --template default-fc --metadata user=alice
   # set the ip address and port of the orchestrator
   sandbox-cli sandbox ls --ip 127.0.0.1 --port 5000 SandboxID-1
   sandbox-cli sandbox ls -i 192.168.47.247 -p 6666 SandboxID-1 SandboxID-2
@@ -36,17 +38,22 @@ func NewListCommand() *cobra.Command {
 	// lsCmd.Flags().CountP("all", "a", "list all sandboxes (By default only 20). If you want show all, please specify more than one 'a' (e.g., -aa)")
 	lsCmd.Flags().BoolP("all", "a", false, "list all sandboxes (excluding orphan).")
 	lsCmd.Flags().Bool("orphan", false, "list orphan sandboxes. if not set this flag, only list sandboxes maintained by orchestrator.")
+	lsCmd.Flags().StringP("template", "t", "", "used with --all, only list sandboxes of this template.")
+	lsCmd.Flags().StringToString("metadata", nil, "used with --all, only list sandboxes whose metadata contains these key=value pairs.")
 	return lsCmd
 }
 
-func lsAll(ip string, port int) error {
+func lsAll(ip string, port int, templateID string, metadata map[string]string) error {
 	slog.Info("start list all sandbox")
 	client, err := lib.NewOrchestratorSbxClient(ip, port)
 	if err != nil {
 		return err
 	}
 	ctx := context.Background()
-	req := orchestrator.SandboxListRequest{}
+	req := orchestrator.SandboxListRequest{MetadataSelector: metadata}
+	if templateID != "" {
+		req.TemplateID = &templateID
+	}
 	sandboxes, err := client.List(ctx, &req)
 	if err != nil {
 		return fmt.Errorf("error during sending grpc request: %w", err)
@@ -111,9 +118,17 @@ func lsSandbox(cmd *cobra.Command, args []string) error {
 	if all && orphan {
 		return fmt.Errorf("cannot specify both --all and --orphan")
 	}
+	templateID, err := cmd.Flags().GetString("template")
+	if err != nil {
+		return err
+	}
+	metadata, err := cmd.Flags().GetStringToString("metadata")
+	if err != nil {
+		return err
+	}
 
 	if all {
-		if err := lsAll(ip, port); err != nil {
+		if err := lsAll(ip, port, templateID, metadata); err != nil {
 			return fmt.Errorf("error while list all sandbox: %w", err)
 		}
 	} else if orphan {
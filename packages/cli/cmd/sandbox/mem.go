@@ -0,0 +1,64 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/spf13/cobra"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func NewMemCommand() *cobra.Command {
+	memCmd := &cobra.Command{
+		Use:   "mem",
+		Short: "Show the current memory consumption (on host) of sandboxes",
+		Long: `Show the memory charged to the cgroup of sandboxes on host, without deactivating
+them. All sandboxes are shown when no sandbox id is given. For example:
+
+  sandbox-cli sandbox mem
+  sandbox-cli sandbox mem 554a78c8-b80b-48ab-ac60-97c1b4912993
+`,
+		RunE: showMem,
+	}
+	return memCmd
+}
+
+func showMem(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.MemoryConsumption(context.Background(), &orchestrator.SandboxMemoryConsumptionRequest{
+		SandboxIDs: args,
+	})
+	if err != nil {
+		return fmt.Errorf("error during sending grpc request: %w", err)
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle("Memory consumption on host")
+	t.Style().Title = table.TitleOptions{Align: text.AlignCenter}
+	t.AppendHeader(table.Row{"SandboxID", "MiB"})
+	var total int64
+	for _, sbx := range resp.Sandboxes {
+		t.AppendRow(table.Row{sbx.SandboxID, fmt.Sprintf("%.1f", float64(sbx.HostMemoryBytes)/(1<<20))})
+		total += sbx.HostMemoryBytes
+	}
+	t.AppendFooter(table.Row{"Total", fmt.Sprintf("%.1f", float64(total)/(1<<20))})
+	t.Render()
+	return nil
+}
@@ -0,0 +1,61 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewMetadataCommand() *cobra.Command {
+	metadataCmd := &cobra.Command{
+		Use:   "metadata",
+		Short: "Set metadata of a running sandbox",
+		Long: `Set metadata of a running sandbox, by default the metadata is merged into the existing one.
+Example:
+  sandbox-cli sandbox metadata --set job=x SandboxID-1
+  # replace the whole metadata
+  sandbox-cli sandbox metadata --replace --set job=x,user=alice SandboxID-1
+`,
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE:         setMetadata,
+	}
+	metadataCmd.Flags().StringToString("set", nil, "the key=value pairs of metadata.")
+	metadataCmd.Flags().Bool("replace", false, "replace the whole metadata instead of merging.")
+	return metadataCmd
+}
+
+func setMetadata(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	metadata, err := cmd.Flags().GetStringToString("set")
+	if err != nil {
+		return err
+	}
+	replace, err := cmd.Flags().GetBool("replace")
+	if err != nil {
+		return err
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	req := orchestrator.SandboxSetMetadataRequest{SandboxID: args[0], Metadata: metadata, Replace: replace}
+	resp, err := client.SetMetadata(context.Background(), &req)
+	if err != nil {
+		return fmt.Errorf("set metadata failed: %w", err)
+	}
+	slog.Info("set metadata of sandbox", slog.String("sandbox-id", args[0]), slog.Any("metadata", resp.Metadata))
+	return nil
+}
@@ -0,0 +1,48 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewPurgeFailedCommand() *cobra.Command {
+	purgeFailedCmd := &cobra.Command{
+		Use:   "purge-failed",
+		Short: "Remove the kept files of sandboxes failed to create",
+		Long: `Remove the files (and release the networks) kept for the sandboxes failed to create
+(see keep_files_on_error), all of them are removed when no sandbox id is given. For example:
+
+  sandbox-cli sandbox purge-failed 554a78c8-b80b-48ab-ac60-97c1b4912993
+`,
+		RunE: purgeFailed,
+	}
+	return purgeFailedCmd
+}
+
+func purgeFailed(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.PurgeFailed(context.Background(), &orchestrator.SandboxPurgeFailedRequest{SandboxIDs: args})
+	if err != nil {
+		return fmt.Errorf("purge failed sandboxes failed: %w", err)
+	}
+	for _, sandboxID := range resp.SandboxIDs {
+		fmt.Printf("purged: %s\n", sandboxID)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewRefreshPrometheusTargetCommand() *cobra.Command {
+	refreshCmd := &cobra.Command{
+		Use:   "refresh-prometheus-target",
+		Short: "Rewrite the prometheus target file of sandboxes",
+		Long: `Rewrite the prometheus target file of sandboxes, e.g., when the
+metrics of a sandbox are not scraped. For example:
+
+  sandbox-cli sandbox refresh-prometheus-target 554a78c8-b80b-48ab-ac60-97c1b4912993
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: refreshPrometheusTarget,
+	}
+	return refreshCmd
+}
+
+func refreshPrometheusTarget(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	var finalErr error
+	for _, sandboxID := range args {
+		_, err := client.RefreshPrometheusTarget(context.Background(), &orchestrator.SandboxRefreshPrometheusTargetRequest{SandboxID: sandboxID})
+		if err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("refresh prometheus target of %s failed: %w", sandboxID, err))
+			continue
+		}
+		fmt.Printf("prometheus target refreshed: %s\n", sandboxID)
+	}
+	return finalErr
+}
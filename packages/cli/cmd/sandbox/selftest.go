@@ -0,0 +1,78 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewSelfTestCommand() *cobra.Command {
+	selfTestCmd := &cobra.Command{
+		Use:   "self-test",
+		Short: "Verify the orchestrator can create, run and delete a sandbox end-to-end",
+		Long: `Create a probe sandbox, wait for its clock synced, optionally run a command
+inside it, then delete it. The duration of each phase is printed, and the command
+fails if any phase fails. The template defaults to self_test_template of the
+orchestrator. For example:
+
+  sandbox-cli sandbox self-test --template probe --command "echo hello"
+`,
+		RunE:         selfTest,
+		SilenceUsage: true,
+	}
+	selfTestCmd.Flags().String("template", "", "the template of probe sandbox (default self_test_template of orchestrator)")
+	selfTestCmd.Flags().String("command", "", "the command run inside the probe sandbox, skipped if empty")
+	return selfTestCmd
+}
+
+func selfTest(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	templateID, err := cmd.Flags().GetString("template")
+	if err != nil {
+		return fmt.Errorf("cannot get template from args: %w", err)
+	}
+	command, err := cmd.Flags().GetString("command")
+	if err != nil {
+		return fmt.Errorf("cannot get command from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorHostManageClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.SelfTest(context.Background(), &orchestrator.HostManageSelfTestRequest{
+		TemplateID: templateID,
+		Command:    command,
+	})
+	if err != nil {
+		return fmt.Errorf("self test failed: %w", err)
+	}
+	if resp.SandboxID != "" {
+		fmt.Printf("probe sandbox: %s\n", resp.SandboxID)
+	}
+	for _, phase := range resp.Phases {
+		if phase.Error != "" {
+			fmt.Printf("%-12s %6dms  FAILED: %s\n", phase.Name, phase.DurationMs, phase.Error)
+		} else {
+			fmt.Printf("%-12s %6dms  ok\n", phase.Name, phase.DurationMs)
+		}
+	}
+	if command != "" {
+		fmt.Printf("exit code: %d\nstdout:\n%s\nstderr:\n%s\n", resp.ExitCode, resp.Stdout, resp.Stderr)
+	}
+	if !resp.Passed {
+		return fmt.Errorf("self test did not pass")
+	}
+	fmt.Println("self test passed")
+	return nil
+}
@@ -28,6 +28,10 @@ func NewSnapshotCommand() *cobra.Command {
   # set the ip address and port of the orchestrator
   sandbox-cli sandbox snapshot --ip 127.0.0.1 --port 5000 SandboxID-1
   sandbox-cli sandbox snapshot -i 192.168.47.247 -p 6666 SandboxID-1 SandboxID-2
+  # register the snapshot as a new template (only one sandbox is allowed)
+  sandbox-cli sandbox snapshot --as-template new-template SandboxID-1
+  # write the snapshot into the dir (under data_root of orchestrator, only one sandbox is allowed)
+  sandbox-cli sandbox snapshot --dest /data/staging/snapshot-1 SandboxID-1
 .`,
 		RunE: snapshot,
 	}
@@ -42,6 +46,8 @@ func NewSnapshotCommand() *cobra.Command {
 	// is called directly, e.g.:
 	// snapshotCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	snapshotCmd.Flags().Bool("delete", false, "delete the sandbox after generating snapshot, by default the sandbox will resume after generating snapshot.")
+	snapshotCmd.Flags().String("as-template", "", "register the snapshot (with current rootfs) as a new template with this id.")
+	snapshotCmd.Flags().String("dest", "", "the dir to write the snapshot into, by default the instance snapshot dir of sandbox.")
 	return snapshotCmd
 }
 
@@ -58,15 +64,38 @@ func snapshot(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("cannot get delete from args: %w", err)
 	}
+	templateID, err := cmd.Flags().GetString("as-template")
+	if err != nil {
+		return fmt.Errorf("cannot get as-template from args: %w", err)
+	}
+	if templateID != "" && len(args) != 1 {
+		return fmt.Errorf("only one sandbox can be specified with --as-template")
+	}
+	dest, err := cmd.Flags().GetString("dest")
+	if err != nil {
+		return fmt.Errorf("cannot get dest from args: %w", err)
+	}
+	if dest != "" && (templateID != "" || len(args) != 1) {
+		return fmt.Errorf("only one sandbox can be specified with --dest, which cannot be used along with --as-template")
+	}
 	client, err := lib.NewOrchestratorSbxClient(ip, port)
 	if err != nil {
 		return err
 	}
 
 	ctx := context.Background()
+	if templateID != "" {
+		req := orchestrator.SandboxSnapshotAsTemplateRequest{SandboxID: args[0], TemplateID: templateID, Delete: terminate}
+		response, err := client.SnapshotAsTemplate(ctx, &req)
+		if err != nil {
+			return err
+		}
+		slog.Info("created template from sandbox", slog.String("sandbox-id", args[0]), slog.String("template-id", response.TemplateID), slog.String("path", response.Path))
+		return nil
+	}
 	var finalErr error
 	for _, sandboxID := range args {
-		req := orchestrator.SandboxSnapshotRequest{SandboxID: sandboxID, Delete: terminate}
+		req := orchestrator.SandboxSnapshotRequest{SandboxID: sandboxID, Delete: terminate, DestinationDir: dest}
 		response, err := client.Snapshot(ctx, &req)
 		slog.Info("snapshoted sandbox", slog.String("sandbox-id", sandboxID), slog.Any("error", err), slog.String("path", response.Path))
 		finalErr = errors.Join(finalErr, err)
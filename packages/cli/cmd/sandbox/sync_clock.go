@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewSyncClockCommand() *cobra.Command {
+	syncClockCmd := &cobra.Command{
+		Use:   "sync-clock",
+		Short: "Sync the guest clock of sandboxes with the host",
+		Long: `Sync the guest clock of sandboxes with the host on demand. For example:
+
+  sandbox-cli sandbox sync-clock 554a78c8-b80b-48ab-ac60-97c1b4912993
+`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: syncClock,
+	}
+	return syncClockCmd
+}
+
+func syncClock(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorSbxClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	var finalErr error
+	for _, sandboxID := range args {
+		_, err := client.SyncClock(context.Background(), &orchestrator.SandboxSyncClockRequest{SandboxID: sandboxID})
+		if err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("sync clock of %s failed: %w", sandboxID, err))
+			continue
+		}
+		fmt.Printf("clock synced: %s\n", sandboxID)
+	}
+	return finalErr
+}
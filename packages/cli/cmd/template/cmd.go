@@ -0,0 +1,23 @@
+package template
+
+import (
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/spf13/cobra"
+)
+
+func NewTemplateCommand() *cobra.Command {
+	templateCmd := &cobra.Command{
+		Use:   "template",
+		Short: "Do operations on the templates of orchestrator.",
+	}
+	templateCmd.PersistentFlags().StringP("ip", "i", "127.0.0.1", "the ip address of the backend orchestrator")
+	templateCmd.PersistentFlags().IntP("port", "p", consts.DefaultOrchestratorPort, "the ip address of the backend orchestrator")
+
+	templateCmd.AddCommand(
+		NewListCommand(),
+		NewCompactSnapshotCommand(),
+		NewCheckCompatCommand(),
+	)
+
+	return templateCmd
+}
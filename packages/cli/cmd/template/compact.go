@@ -0,0 +1,77 @@
+package template
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewCompactSnapshotCommand() *cobra.Command {
+	compactCmd := &cobra.Command{
+		Use:   "compact-snapshot",
+		Short: "Merge a chain of firecracker diff snapshots into a full snapshot.",
+		Long: `Merge a chain of firecracker snapshots, i.e., a full snapshot and the diff
+snapshots (of a sandbox created with --enable-diff-snapshot) on top of it,
+into a full snapshot, so that restoring does not need the whole chain.
+
+The dirs are absolute paths on the host of orchestrator, and the diff
+snapshots are specified from the oldest to the newest. The snapshot dir of
+a sandbox maintained by orchestrator is refused, as it is overwritten by the
+next snapshot, copy it out first.
+
+Example:
+sandbox-cli template compact-snapshot --base /snapshots/base \
+  --diff /snapshots/diff-1 --diff /snapshots/diff-2 --output /snapshots/full
+		`,
+		RunE:         compactSnapshot,
+		SilenceUsage: true,
+	}
+	compactCmd.Flags().String("base", "", "the dir of the full snapshot which the chain is based on")
+	compactCmd.Flags().StringArray("diff", nil, "the dir of a diff snapshot, can be specified multiple times (from the oldest to the newest)")
+	compactCmd.Flags().String("output", "", "the dir where the merged full snapshot is written to")
+	compactCmd.MarkFlagRequired("base")
+	compactCmd.MarkFlagRequired("diff")
+	compactCmd.MarkFlagRequired("output")
+	return compactCmd
+}
+
+func compactSnapshot(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	baseDir, err := cmd.Flags().GetString("base")
+	if err != nil {
+		return fmt.Errorf("cannot get base from args: %w", err)
+	}
+	diffDirs, err := cmd.Flags().GetStringArray("diff")
+	if err != nil {
+		return fmt.Errorf("cannot get diff from args: %w", err)
+	}
+	outputDir, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("cannot get output from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorHostManageClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.CompactSnapshot(context.Background(), &orchestrator.HostManageCompactSnapshotRequest{
+		BaseDir:   baseDir,
+		DiffDirs:  diffDirs,
+		OutputDir: outputDir,
+	})
+	if err != nil {
+		return fmt.Errorf("compact snapshot failed: %w", err)
+	}
+	fmt.Printf("compacted snapshot written to %s\n", outputDir)
+	return nil
+}
@@ -0,0 +1,70 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/spf13/cobra"
+)
+
+func NewCheckCompatCommand() *cobra.Command {
+	compatCmd := &cobra.Command{
+		Use:   "check-compat [template-id...]",
+		Short: "Check whether the snapshots of templates can be restored on the host of orchestrator.",
+		Long: `Compare the cpu of host where the snapshot of template is taken against
+the one of orchestrator, and report why it cannot be restored. All of the
+templates are checked when no template id is given. The command fails if any
+template is incompatible.
+
+Example:
+sandbox-cli template check-compat
+sandbox-cli template check-compat my-template --ip 127.0.0.1 --port 5000
+		`,
+		RunE:         checkCompat,
+		SilenceUsage: true,
+	}
+	return compatCmd
+}
+
+func checkCompat(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorHostManageClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.CheckSnapshotCompatibility(context.Background(), &orchestrator.HostManageCheckSnapshotCompatibilityRequest{
+		TemplateIDs: args,
+	})
+	if err != nil {
+		return fmt.Errorf("check snapshot compatibility failed: %w", err)
+	}
+	fmt.Printf("host cpu: %s\n", resp.HostCPU)
+	incompatible := 0
+	for _, compat := range resp.Templates {
+		snapshotCPU := compat.SnapshotCPU
+		if snapshotCPU == "" {
+			snapshotCPU = "unknown"
+		}
+		if compat.Compatible {
+			fmt.Printf("%-24s ok (snapshot cpu: %s)\n", compat.TemplateID, snapshotCPU)
+			continue
+		}
+		incompatible++
+		fmt.Printf("%-24s INCOMPATIBLE (snapshot cpu: %s)\n  %s\n", compat.TemplateID, snapshotCPU, strings.Join(compat.Problems, "\n  "))
+	}
+	if incompatible > 0 {
+		return fmt.Errorf("%d of %d templates are incompatible", incompatible, len(resp.Templates))
+	}
+	return nil
+}
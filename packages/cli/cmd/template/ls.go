@@ -0,0 +1,49 @@
+package template
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/cli/lib"
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/spf13/cobra"
+)
+
+func NewListCommand() *cobra.Command {
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List the templates on the host of orchestrator.",
+		Long: `List the templates under the data root of orchestrator, including
+their resources and whether the image files are present.
+
+Example:
+sandbox-cli template ls
+sandbox-cli template ls --ip 127.0.0.1 --port 5000
+		`,
+		RunE:         lsTemplate,
+		SilenceUsage: true,
+	}
+	return lsCmd
+}
+
+func lsTemplate(cmd *cobra.Command, args []string) error {
+	ip, err := cmd.Flags().GetString("ip")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator ip from args: %w", err)
+	}
+	port, err := cmd.Flags().GetInt("port")
+	if err != nil {
+		return fmt.Errorf("cannot get orchestrator port from args: %w", err)
+	}
+	client, err := lib.NewOrchestratorHostManageClient(ip, port)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ListTemplates(context.Background(), &empty.Empty{})
+	if err != nil {
+		return fmt.Errorf("list templates failed: %w", err)
+	}
+	lib.PrintTemplateInfo("Templates in orchestrator", resp.Templates...)
+	return nil
+}
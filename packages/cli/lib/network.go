@@ -0,0 +1,25 @@
+package lib
+
+import (
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func PrintNetworkInfo(title string, networks ...*orchestrator.NetworkInfo) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	t.SetTitle(title)
+	t.Style().Title = table.TitleOptions{Align: text.AlignCenter}
+	t.AppendHeader(table.Row{"NetworkIdx", "State", "SandboxID", "NetNs", "VethIP", "VpeerIP", "HostClonedIP", "HostClonedIPv6"})
+	for _, n := range networks {
+		state := strings.TrimPrefix(n.State.String(), "NETWORK_")
+		t.AppendRow(table.Row{n.NetworkIdx, state, n.SandboxID, n.NetNsName, n.VethIP, n.VpeerIP, n.HostClonedIP, n.HostClonedIPv6})
+	}
+	t.Render()
+}
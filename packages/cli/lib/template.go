@@ -0,0 +1,27 @@
+package lib
+
+import (
+	"os"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func PrintTemplateInfo(title string, templates ...*orchestrator.TemplateInfo) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+
+	t.SetTitle(title)
+	t.Style().Title = table.TitleOptions{Align: text.AlignCenter}
+	t.AppendHeader(table.Row{"TemplateID", "VmmType", "VCPU", "MemoryMB", "DiskSizeMB", "Kernel", "ImagesPresent", "MissingFiles"})
+	for _, tmpl := range templates {
+		t.AppendRow(table.Row{
+			tmpl.TemplateID, tmpl.VmmType, tmpl.VcpuCount, tmpl.MemoryMB, tmpl.DiskSizeMB,
+			tmpl.KernelVersion, tmpl.ImagesPresent, strings.Join(tmpl.MissingFiles, ","),
+		})
+	}
+	t.Render()
+}
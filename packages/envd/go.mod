@@ -15,6 +15,7 @@ require (
 	github.com/rs/xid v1.5.0
 	github.com/shirou/gopsutil/v4 v4.24.5
 	go.uber.org/zap v1.26.0
+	golang.org/x/sys v0.20.0
 )
 
 require (
@@ -43,7 +44,6 @@ require (
 	golang.org/x/crypto v0.21.0 // indirect
 	golang.org/x/exp v0.0.0-20231006140011-7918f672742d // indirect
 	golang.org/x/mod v0.13.0 // indirect
-	golang.org/x/sys v0.20.0 // indirect
 	golang.org/x/tools v0.14.0 // indirect
 	google.golang.org/protobuf v1.32.0 // indirect
 )
@@ -1,7 +1,9 @@
 package clock
 
 import (
+	"fmt"
 	"os/exec"
+	"strings"
 	"sync"
 
 	"go.uber.org/zap"
@@ -19,23 +21,23 @@ func NewService(logger *zap.SugaredLogger) *Service {
 	}
 }
 
-func (s *Service) Sync() {
+// Sync the guest clock with the host (through ptp), blocks until finished.
+func (s *Service) Sync() error {
 	s.logger.Debug("Syncing clock")
 	s.mu.Lock()
-
-	go func() {
-		defer s.mu.Unlock()
-
-		// The chronyc -a makestep is not immediately stepping the clock
-		err := exec.Command("/usr/bin/bash", "-c", "/usr/bin/date -s @$(/usr/sbin/phc_ctl /dev/ptp0 get | cut -d' ' -f5)").Run()
-		if err != nil {
-			s.logger.Errorw("Failed to sync clock:",
-				"error", err,
-			)
-		} else {
-			s.logger.Debugw("Clock synced")
-		}
-	}()
+	defer s.mu.Unlock()
+
+	// The chronyc -a makestep is not immediately stepping the clock
+	output, err := exec.Command("/usr/bin/bash", "-c", "/usr/bin/date -s @$(/usr/sbin/phc_ctl /dev/ptp0 get | cut -d' ' -f5)").CombinedOutput()
+	if err != nil {
+		s.logger.Errorw("Failed to sync clock:",
+			"error", err,
+			"output", string(output),
+		)
+		return fmt.Errorf("sync clock failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	s.logger.Debugw("Clock synced")
+	return nil
 }
 
 func (s *Service) Wait() {
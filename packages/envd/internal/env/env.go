@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 
 	"github.com/e2b-dev/infra/packages/envd/internal/log"
+	"github.com/e2b-dev/infra/packages/envd/internal/log/exporter"
 	"go.uber.org/zap"
 )
 
@@ -27,15 +28,17 @@ type EnvConfig struct {
 	GatewayIP net.IP
 
 	Debug bool
+
+	LogsExporter *exporter.HTTPLogsExporter
 }
 
-func NewEnv(debug bool) (*EnvConfig, *zap.SugaredLogger, error) {
+func NewEnv(debug bool, maxLogBufferSize int64) (*EnvConfig, *zap.SugaredLogger, error) {
 	preferredShell, ok := os.LookupEnv("SHELL")
 	if !ok {
 		preferredShell = filepath.Join("/bin", "bash")
 	}
 
-	l, err := log.NewLogger(defaultLogDir, debug, true)
+	l, logsExporter, err := log.NewLogger(defaultLogDir, debug, true, maxLogBufferSize)
 	if err != nil {
 		return nil, nil, fmt.Errorf("error creating a new logger: %w", err)
 	}
@@ -46,5 +49,7 @@ func NewEnv(debug bool) (*EnvConfig, *zap.SugaredLogger, error) {
 		LogDir:    defaultLogDir,
 		Shell:     preferredShell,
 		GatewayIP: defaultGatewayIP,
+
+		LogsExporter: logsExporter,
 	}, l, nil
 }
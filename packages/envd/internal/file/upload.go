@@ -51,7 +51,7 @@ func Upload(logger *zap.SugaredLogger, w http.ResponseWriter, r *http.Request) {
 
 	var newFilePath string
 
-	uid, gid, homedir, _, userErr := user.GetUser(user.DefaultUser)
+	uid, gid, homedir, _, userErr := user.GetUser(user.Default())
 	if filepath == "" {
 		// Create a new file in the user's homedir if no path in the form is specified
 		if userErr != nil {
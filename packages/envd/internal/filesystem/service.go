@@ -277,7 +277,7 @@ func (s *Service) MakeDir(dirpath string) error {
 		return fmt.Errorf("error creating a new directory '%s': %w", dirpath, err)
 	}
 
-	uid, gid, _, _, _ := user.GetUser(user.DefaultUser)
+	uid, gid, _, _, _ := user.GetUser(user.Default())
 	if err := os.Chown(dirpath, int(uid), int(gid)); err != nil {
 		s.logger.Errorw("Failed to chown the new directory",
 			"dirpath", dirpath,
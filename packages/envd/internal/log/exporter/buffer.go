@@ -0,0 +1,188 @@
+package exporter
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// The max size of logs persisted on the guest disk when the log collector is down.
+	DefaultMaxDiskBufferSize int64 = 16 << 20
+	diskBufferFileName             = "envd-pending-logs.jsonl"
+
+	minReplayBackoff = 1 * time.Second
+	maxReplayBackoff = 60 * time.Second
+)
+
+var errDiskBufferFull = errors.New("log disk buffer is full")
+
+// PendingLogsStatus describes the logs which have not been delivered to the log collector.
+type PendingLogsStatus struct {
+	PendingEntries int   `json:"pending_entries"`
+	PendingBytes   int64 `json:"pending_bytes"`
+	// The logs discarded because the disk buffer is full.
+	DroppedEntries int64  `json:"dropped_entries"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+// diskBuffer persists the logs (one json per line) which failed to send,
+// so they can be replayed after the log collector recovers.
+type diskBuffer struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64
+
+	size      int64
+	entries   int
+	dropped   int64
+	lastError error
+}
+
+func newDiskBuffer(path string, maxSize int64) *diskBuffer {
+	b := &diskBuffer{
+		path:    path,
+		maxSize: maxSize,
+	}
+	// the logs left by previous run (e.g., the guest has been rebooted)
+	if data, err := os.ReadFile(path); err == nil {
+		b.size = int64(len(data))
+		b.entries = bytes.Count(data, []byte{'\n'})
+	}
+	return b
+}
+
+func (b *diskBuffer) pending() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.entries > 0
+}
+
+func (b *diskBuffer) status() PendingLogsStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	status := PendingLogsStatus{
+		PendingEntries: b.entries,
+		PendingBytes:   b.size,
+		DroppedEntries: b.dropped,
+	}
+	if b.lastError != nil {
+		status.LastError = b.lastError.Error()
+	}
+	return status
+}
+
+func (b *diskBuffer) setLastError(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastError = err
+}
+
+func (b *diskBuffer) append(log []byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.appendLocked([][]byte{log})
+}
+
+func (b *diskBuffer) appendLocked(logs [][]byte) error {
+	var data []byte
+	for _, log := range logs {
+		if b.size+int64(len(data)+len(log)+1) > b.maxSize {
+			b.dropped++
+			continue
+		}
+		data = append(data, bytes.TrimRight(log, "\n")...)
+		data = append(data, '\n')
+	}
+	if len(data) == 0 {
+		if len(logs) > 0 {
+			return errDiskBufferFull
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(b.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open log disk buffer failed: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("write log disk buffer failed: %w", err)
+	}
+	b.size += int64(len(data))
+	b.entries += bytes.Count(data, []byte{'\n'})
+	return nil
+}
+
+// replay sends the buffered logs in order, and stops at the first failure.
+// The logs which have not been sent are kept in the buffer.
+//
+// The lock is not held while sending, so a slow log collector does not block
+// the status queries. Only the logs read here are removed from the buffer
+// afterwards, the ones appended meanwhile are kept.
+func (b *diskBuffer) replay(send func([]byte) error) error {
+	b.mu.Lock()
+	data, err := os.ReadFile(b.path)
+	if os.IsNotExist(err) {
+		b.size, b.entries = 0, 0
+		b.mu.Unlock()
+		return nil
+	}
+	b.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("read log disk buffer failed: %w", err)
+	}
+
+	var (
+		sent    int
+		sendErr error
+	)
+	for sent < len(data) {
+		end := bytes.IndexByte(data[sent:], '\n')
+		if end < 0 {
+			end = len(data) - sent
+		}
+		if line := data[sent : sent+end]; len(line) > 0 {
+			if sendErr = send(line); sendErr != nil {
+				break
+			}
+		}
+		sent = min(sent+end+1, len(data))
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return errors.Join(sendErr, b.discardLocked(sent))
+}
+
+// discardLocked removes the first n bytes (i.e., the logs have been sent) of the buffer.
+func (b *diskBuffer) discardLocked(n int) error {
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			b.size, b.entries = 0, 0
+			return nil
+		}
+		return fmt.Errorf("read log disk buffer failed: %w", err)
+	}
+	remain := data[min(n, len(data)):]
+	if len(remain) == 0 {
+		if err := os.Remove(b.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove log disk buffer failed: %w", err)
+		}
+	} else if n > 0 {
+		tmpPath := b.path + ".tmp"
+		if err := os.WriteFile(tmpPath, remain, 0o600); err != nil {
+			return fmt.Errorf("write log disk buffer failed: %w", err)
+		}
+		if err := os.Rename(tmpPath, b.path); err != nil {
+			return fmt.Errorf("rename log disk buffer failed: %w", err)
+		}
+	}
+	b.size = int64(len(remain))
+	b.entries = bytes.Count(remain, []byte{'\n'})
+	return nil
+}
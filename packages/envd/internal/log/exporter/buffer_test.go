@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiskBufferReplay(t *testing.T) {
+	b := newDiskBuffer(filepath.Join(t.TempDir(), diskBufferFileName), DefaultMaxDiskBufferSize)
+	for _, log := range []string{"1", "2", "3"} {
+		if err := b.append([]byte(log)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sent []string
+	errSend := errors.New("send failed")
+	err := b.replay(func(log []byte) error {
+		// the lock should not be held while sending
+		if !b.mu.TryLock() {
+			t.Fatal("disk buffer is locked while sending")
+		}
+		b.mu.Unlock()
+		if string(log) == "2" {
+			// appended while replaying
+			if err := b.append([]byte("4")); err != nil {
+				t.Fatal(err)
+			}
+			return errSend
+		}
+		sent = append(sent, string(log))
+		return nil
+	})
+	if !errors.Is(err, errSend) {
+		t.Fatalf("expect send error, got %v", err)
+	}
+	if len(sent) != 1 || sent[0] != "1" {
+		t.Fatalf("unexpected sent logs %v", sent)
+	}
+	if status := b.status(); status.PendingEntries != 3 || status.PendingBytes != 6 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	sent = nil
+	if err := b.replay(func(log []byte) error {
+		sent = append(sent, string(log))
+		return nil
+	}); err != nil {
+		t.Fatalf("replay failed: %s", err)
+	}
+	if len(sent) != 3 || sent[0] != "2" || sent[1] != "3" || sent[2] != "4" {
+		t.Fatalf("unexpected sent logs %v", sent)
+	}
+	if b.pending() {
+		t.Fatalf("expect nothing pending, got %+v", b.status())
+	}
+	if _, err := os.Stat(b.path); !os.IsNotExist(err) {
+		t.Fatalf("disk buffer should be removed, stat err: %v", err)
+	}
+}
+
+func TestDiskBufferFull(t *testing.T) {
+	b := newDiskBuffer(filepath.Join(t.TempDir(), diskBufferFileName), 4)
+	if err := b.append([]byte("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.append([]byte("d")); !errors.Is(err, errDiskBufferFull) {
+		t.Fatalf("expect buffer full, got %v", err)
+	}
+	if status := b.status(); status.PendingEntries != 1 || status.DroppedEntries != 1 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	// the logs left by previous run are loaded
+	reloaded := newDiskBuffer(b.path, 4)
+	if status := reloaded.status(); status.PendingEntries != 1 || status.PendingBytes != 4 {
+		t.Fatalf("unexpected status after reload %+v", status)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -15,22 +16,41 @@ type HTTPLogsExporter struct {
 	logs     [][]byte
 	sync.Mutex
 	debug bool
+
+	// logs failed to send are persisted here, only accessed in start()
+	// except for the status.
+	buffer        *diskBuffer
+	replayBackoff time.Duration
+	nextReplay    time.Time
 }
 
-func NewHTTPLogsExporter(debug bool) *HTTPLogsExporter {
+// The logs failed to send (e.g., the log collector is down) will be persisted under
+// bufferDir (at most maxBufferSize bytes) and replayed with backoff.
+func NewHTTPLogsExporter(debug bool, bufferDir string, maxBufferSize int64) *HTTPLogsExporter {
 	exporter := &HTTPLogsExporter{
 		client: http.Client{
 			Timeout: 2 * time.Second,
 		},
 		triggers: make(chan struct{}, 1),
 		debug:    debug,
+		buffer:   newDiskBuffer(filepath.Join(bufferDir, diskBufferFileName), maxBufferSize),
 	}
 
 	go exporter.start()
 
+	// replay the logs left by previous run
+	if exporter.buffer.pending() {
+		exporter.resumeProcessing()
+	}
+
 	return exporter
 }
 
+// The status of logs which have not been delivered to the log collector.
+func (w *HTTPLogsExporter) PendingLogs() PendingLogsStatus {
+	return w.buffer.status()
+}
+
 func (w *HTTPLogsExporter) sendInstanceLogs(logs []byte, address string) error {
 	request, err := http.NewRequest("POST", address, bytes.NewBuffer(logs))
 	if err != nil {
@@ -45,9 +65,53 @@ func (w *HTTPLogsExporter) sendInstanceLogs(logs []byte, address string) error {
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("log collector returned status %d", response.StatusCode)
+	}
+
 	return nil
 }
 
+// Try to replay the logs in disk buffer. Return true if all of them have been sent.
+func (w *HTTPLogsExporter) replayBuffered(address string) bool {
+	if !w.buffer.pending() {
+		return true
+	}
+	if time.Now().Before(w.nextReplay) {
+		return false
+	}
+
+	err := w.buffer.replay(func(log []byte) error {
+		return w.sendInstanceLogs(log, address)
+	})
+	if err == nil {
+		w.replayBackoff = 0
+		w.buffer.setLastError(nil)
+		return true
+	}
+
+	fmt.Fprintf(os.Stderr, "error replaying buffered instance logs: %v\n", err)
+	w.buffer.setLastError(err)
+	w.scheduleReplay()
+	return false
+}
+
+// Schedule the next replay, the backoff is doubled on each consecutive failure.
+func (w *HTTPLogsExporter) scheduleReplay() {
+	backoff := min(max(2*w.replayBackoff, minReplayBackoff), maxReplayBackoff)
+	w.replayBackoff = backoff
+	w.nextReplay = time.Now().Add(backoff)
+	time.AfterFunc(backoff, w.resumeProcessing)
+}
+
+func (w *HTTPLogsExporter) bufferLog(log []byte) {
+	if err := w.buffer.append(log); err != nil {
+		fmt.Fprintf(os.Stderr, "error buffering instance logs: %v\n", err)
+
+		printLog(log)
+	}
+}
+
 func printLog(logs []byte) {
 	fmt.Fprintf(os.Stdout, "%v", string(logs))
 }
@@ -56,7 +120,7 @@ func (w *HTTPLogsExporter) start() {
 	for range w.triggers {
 		logs := w.getAllLogs()
 
-		if len(logs) == 0 {
+		if len(logs) == 0 && !w.buffer.pending() {
 			continue
 		}
 
@@ -90,6 +154,10 @@ func (w *HTTPLogsExporter) start() {
 			continue
 		}
 
+		// NOTE: keep the order of logs, i.e., do not send the new logs
+		// until the buffered ones have been delivered.
+		delivered := w.replayBuffered(mmdsOpts.Address)
+
 		for _, log := range logs {
 			logsWithOpts, jsonErr := mmdsOpts.addOptsToJSON(log)
 			if jsonErr != nil {
@@ -100,11 +168,21 @@ func (w *HTTPLogsExporter) start() {
 				continue
 			}
 
+			if !delivered {
+				w.bufferLog(logsWithOpts)
+
+				continue
+			}
+
 			err = w.sendInstanceLogs(logsWithOpts, mmdsOpts.Address)
 			if err != nil {
-				fmt.Fprintf(os.Stderr, fmt.Sprintf("error sending instance logs: %+v", err))
+				fmt.Fprintf(os.Stderr, "error sending instance logs: %+v\n", err)
 
-				printLog(log)
+				w.buffer.setLastError(err)
+				w.bufferLog(logsWithOpts)
+				// retry later with backoff
+				delivered = false
+				w.scheduleReplay()
 
 				continue
 			}
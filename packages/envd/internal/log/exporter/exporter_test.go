@@ -0,0 +1,62 @@
+package exporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReplayBackoff(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w := &HTTPLogsExporter{
+		triggers: make(chan struct{}, 1),
+		buffer:   newDiskBuffer(filepath.Join(t.TempDir(), diskBufferFileName), DefaultMaxDiskBufferSize),
+	}
+	if err := w.buffer.append([]byte(`{"msg":"test"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+	for i, backoff := range expected {
+		if w.replayBuffered(srv.URL) {
+			t.Fatal("expect replay failed")
+		}
+		if w.replayBackoff != backoff {
+			t.Fatalf("expect backoff %s after %d failures, got %s", backoff, i+1, w.replayBackoff)
+		}
+		if w.buffer.status().LastError == "" {
+			t.Fatal("expect the last error recorded")
+		}
+		// skip waiting for the backoff
+		w.nextReplay = time.Time{}
+	}
+	if n := requests.Load(); n != int32(len(expected)) {
+		t.Fatalf("expect %d requests, got %d", len(expected), n)
+	}
+
+	// no replay before the backoff expired
+	w.scheduleReplay()
+	if w.replayBuffered(srv.URL) {
+		t.Fatal("expect replay skipped")
+	}
+	if n := requests.Load(); n != int32(len(expected)) {
+		t.Fatalf("expect no request during backoff, got %d", n)
+	}
+
+	// capped by the max backoff
+	for i := 0; i < 10; i++ {
+		w.scheduleReplay()
+	}
+	if w.replayBackoff != maxReplayBackoff {
+		t.Fatalf("expect backoff capped at %s, got %s", maxReplayBackoff, w.replayBackoff)
+	}
+}
@@ -1,17 +1,10 @@
 package exporter
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"time"
-)
 
-const (
-	mmdsDefaultAddress  = "169.254.169.254"
-	mmdsTokenExpiration = 60 * time.Second
+	"github.com/e2b-dev/infra/packages/envd/internal/mmds"
 )
 
 type opts struct {
@@ -41,57 +34,13 @@ func (opts *opts) addOptsToJSON(jsonLogs []byte) ([]byte, error) {
 }
 
 func (w *HTTPLogsExporter) getMMDSToken() (string, error) {
-	request, err := http.NewRequest("PUT", "http://"+mmdsDefaultAddress+"/latest/api/token", new(bytes.Buffer))
-	if err != nil {
-		return "", err
-	}
-
-	request.Header["X-metadata-token-ttl-seconds"] = []string{fmt.Sprint(mmdsTokenExpiration.Seconds())}
-
-	response, err := w.client.Do(request)
-	if err != nil {
-		return "", err
-	}
-	defer response.Body.Close()
-
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return "", err
-	}
-
-	token := string(body)
-
-	if len(token) == 0 {
-		return "", fmt.Errorf("mmds token is an empty string")
-	}
-
-	return token, nil
+	return mmds.GetToken(&w.client)
 }
 
 func (w *HTTPLogsExporter) getMMDSOpts(token string) (*opts, error) {
-	request, err := http.NewRequest("GET", "http://"+mmdsDefaultAddress, new(bytes.Buffer))
-	if err != nil {
-		return nil, err
-	}
-
-	request.Header["X-metadata-token"] = []string{token}
-	request.Header["Accept"] = []string{"application/json"}
-
-	response, err := w.client.Do(request)
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-
 	var opts opts
 
-	err = json.Unmarshal(body, &opts)
+	err := mmds.GetMetadata(&w.client, token, &opts)
 	if err != nil {
 		return nil, err
 	}
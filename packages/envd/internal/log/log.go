@@ -12,9 +12,10 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-func NewLogger(logDir string, debug, mmds bool) (*zap.SugaredLogger, error) {
+// The returned exporter delivers the logs to the log collector.
+func NewLogger(logDir string, debug, mmds bool, maxLogBufferSize int64) (*zap.SugaredLogger, *exporter.HTTPLogsExporter, error) {
 	if logDir == "" {
-		return nil, fmt.Errorf("error creating logger, passed logDir string is empty")
+		return nil, nil, fmt.Errorf("error creating logger, passed logDir string is empty")
 	}
 
 	outputPaths := fmt.Sprintf("\"%s\"", path.Join(logDir, "envd.log"))
@@ -38,7 +39,7 @@ func NewLogger(logDir string, debug, mmds bool) (*zap.SugaredLogger, error) {
 
 	var cfg zap.Config
 	if err := json.Unmarshal(rawJSON, &cfg); err != nil {
-		return nil, fmt.Errorf("error unmarshalling rawJSON: %w", err)
+		return nil, nil, fmt.Errorf("error unmarshalling rawJSON: %w", err)
 	}
 
 	cfg.EncoderConfig.EncodeTime = zapcore.TimeEncoder(func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
@@ -48,24 +49,25 @@ func NewLogger(logDir string, debug, mmds bool) (*zap.SugaredLogger, error) {
 
 	l, err := cfg.Build()
 	if err != nil {
-		return nil, fmt.Errorf("error building logger: %w", err)
+		return nil, nil, fmt.Errorf("error building logger: %w", err)
 	}
 
 	// mmds is enabled, create a logger that sends logs with info from the FC's MMDS
 	var combinedLogger *zap.Logger
 
 	level := zap.DebugLevel
+	logsExporter := exporter.NewHTTPLogsExporter(debug, logDir, maxLogBufferSize)
 
 	core := zapcore.NewTee(
 		l.Core(),
 		zapcore.NewCore(
 			zapcore.NewJSONEncoder(cfg.EncoderConfig),
-			zapcore.AddSync(exporter.NewHTTPLogsExporter(debug)),
+			zapcore.AddSync(logsExporter),
 			level,
 		),
 	)
 
 	combinedLogger = zap.New(core)
 
-	return combinedLogger.Sugar(), nil
+	return combinedLogger.Sugar(), logsExporter, nil
 }
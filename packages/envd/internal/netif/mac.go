@@ -0,0 +1,26 @@
+package netif
+
+import (
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+)
+
+// Change the mac address of iface (e.g., after restored from the snapshot of
+// template, whose interfaces share the same mac). The virtio-net device
+// allows changing it while the interface is up.
+func SetMAC(iface, mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return fmt.Errorf("invalid mac address: %w", err)
+	}
+	if _, err := net.InterfaceByName(iface); err != nil {
+		return fmt.Errorf("invalid interface %s: %w", iface, err)
+	}
+	output, err := exec.Command("ip", "link", "set", "dev", iface, "address", hw.String()).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("set mac address failed: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
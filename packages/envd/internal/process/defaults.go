@@ -0,0 +1,49 @@
+package process
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/e2b-dev/infra/packages/envd/internal/mmds"
+)
+
+// The mmds is served by firecracker itself, so it should respond quickly.
+const defaultsFetchTimeout = 200 * time.Millisecond
+
+// The defaults of processes for the sandbox, specified when creating the
+// sandbox and passed through mmds by orchestrator.
+//
+// Precedence (from high to low): the Cwd and Envs of SimpleProcessCreateRequest,
+// these defaults, and the env vars inherited from envd (i.e., the template).
+// The working dir is the home dir of user if neither is specified.
+type SandboxDefaults struct {
+	WorkingDir string            `json:"workingDir,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+}
+
+var defaultsClient = &http.Client{
+	Timeout: defaultsFetchTimeout,
+}
+
+// Reload the defaults from mmds. It should be called after the sandbox is
+// restored (i.e., by /sync), as the envd is snapshotted along with the template.
+//
+// The defaults are cleared if failed (e.g., cloud hypervisor does not have mmds).
+func (m *SimpleProcessManager) ReloadDefaults() error {
+	var defaults SandboxDefaults
+	token, err := mmds.GetToken(defaultsClient)
+	if err == nil {
+		err = mmds.GetMetadata(defaultsClient, token, &defaults)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.defaults = defaults
+	return err
+}
+
+func (m *SimpleProcessManager) getDefaults() SandboxDefaults {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.defaults
+}
@@ -24,9 +24,9 @@ type Process struct {
 func New(id ID, shell, cmdToExecute string, envVars *map[string]string, rootdir string, logger *zap.SugaredLogger) (*Process, error) {
 	cmd := exec.Command(shell, "-l", "-c", cmdToExecute)
 
-	uid, gid, homedir, username, err := user.GetUser(user.DefaultUser)
+	uid, gid, homedir, username, err := user.GetUser(user.Default())
 	if err != nil {
-		return nil, fmt.Errorf("error getting user '%s': %w", user.DefaultUser, err)
+		return nil, fmt.Errorf("error getting user '%s': %w", user.Default(), err)
 	}
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{}
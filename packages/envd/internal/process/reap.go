@@ -0,0 +1,63 @@
+package process
+
+import (
+	"errors"
+	"time"
+)
+
+// The defaults of the max number of tracked simple processes and how long
+// the output of an exited process is kept.
+const (
+	DefaultMaxProcesses = 1024
+	DefaultOutputTTL    = 10 * time.Minute
+)
+
+var errTooManyProcesses = errors.New("too many processes")
+
+// Whether no more process can be tracked, i.e., the processes not waited
+// (or streamed till exit) yet reach maxProcesses.
+func (m *SimpleProcessManager) fullLocked() bool {
+	return m.maxProcesses > 0 && len(m.processes) >= m.maxProcesses
+}
+
+func (m *SimpleProcessManager) full() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.fullLocked()
+}
+
+// Remove the processes exited for more than ttl whose output is never
+// collected (by /process/wait or /process/stream), so that the clients
+// which never wait do not leak them. It returns the number removed.
+func (m *SimpleProcessManager) sweep(ttl time.Duration) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed := 0
+	for pid, proc := range m.processes {
+		select {
+		case <-proc.done:
+		default:
+			continue
+		}
+		if time.Since(proc.exitedAt) >= ttl {
+			delete(m.processes, pid)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Sweep the expired processes (see sweep) periodically, ttl <= 0 means
+// the output is kept until collected.
+func (m *SimpleProcessManager) RunSweeper(ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	ticker := time.NewTicker(max(ttl/2, time.Second))
+	defer ticker.Stop()
+	for range ticker.C {
+		if removed := m.sweep(ttl); removed > 0 {
+			m.logger.Debugw("Removed the exited processes whose output is not collected", "count", removed, "ttl", ttl)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package process
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaxProcesses(t *testing.T) {
+	m := NewSimpleProcessManager(nil, DefaultMaxBufferedOutputSize, OutputBufferTruncate, nil, 1)
+	if err := m.putProc(startTestProcess(t, "sleep 60; true")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.putProc(startTestProcess(t, "sleep 60; true")); !errors.Is(err, errTooManyProcesses) {
+		t.Fatalf("expect %v, got %v", errTooManyProcesses, err)
+	}
+	if _, err := m.create(&SimpleProcessCreateRequest{Cmd: "true"}); !errors.Is(err, errTooManyProcesses) {
+		t.Fatalf("expect %v before starting, got %v", errTooManyProcesses, err)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	m := NewSimpleProcessManager(nil, DefaultMaxBufferedOutputSize, OutputBufferTruncate, nil, 0)
+	running := startTestProcess(t, "sleep 60; true")
+	exited := startTestProcess(t, "true")
+	for _, proc := range []*SimpleProcess{running, exited} {
+		if err := m.putProc(proc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-exited.done
+
+	if removed := m.sweep(time.Hour); removed != 0 {
+		t.Fatalf("expect the output not expired yet, got %d removed", removed)
+	}
+	if removed := m.sweep(0); removed != 1 {
+		t.Fatalf("expect the exited process removed, got %d removed", removed)
+	}
+	if m.getProc(exited.cmd.Process.Pid) != nil || m.getProc(running.cmd.Process.Pid) == nil {
+		t.Fatal("expect only the running process kept")
+	}
+}
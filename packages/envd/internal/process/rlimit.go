@@ -0,0 +1,134 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// RlimitExecCommand is the (hidden) subcommand of envd to set the rlimits and
+// then exec the program, i.e., `envd rlimit-exec <limits> -- <program> [args...]`.
+//
+// NOTE: Go does not support setting rlimits for the child in SysProcAttr and
+// setrlimit in envd would apply to the envd itself, so we re-exec envd as a helper.
+const RlimitExecCommand = "rlimit-exec"
+
+// Supported resources, the unit of as/fsize is bytes, the unit of cpu is seconds.
+var rlimitResources = map[string]int{
+	"as":     unix.RLIMIT_AS,
+	"cpu":    unix.RLIMIT_CPU,
+	"fsize":  unix.RLIMIT_FSIZE,
+	"nofile": unix.RLIMIT_NOFILE,
+	"nproc":  unix.RLIMIT_NPROC,
+}
+
+// Rlimit of a resource.
+//
+// The soft limit is the one enforced by kernel (e.g., SIGXCPU is sent when exceeding
+// cpu, and allocation fails when exceeding as). The hard limit is the ceiling of soft
+// limit, the (unprivileged) process can raise its soft limit up to the hard limit but
+// can never raise the hard limit. Hard is the same as soft when omitted.
+//
+// Note that nproc counts all processes of the user, not only the process and its children.
+type Rlimit struct {
+	Soft uint64 `json:"soft"`
+	Hard uint64 `json:"hard,omitempty"`
+}
+
+func (r Rlimit) hard() uint64 {
+	if r.Hard == 0 {
+		return r.Soft
+	}
+	return r.Hard
+}
+
+// Check the rlimits are supported and do not exceed the ceilings (if configured).
+func validateRlimits(limits, ceilings map[string]Rlimit) error {
+	for name, limit := range limits {
+		if _, ok := rlimitResources[name]; !ok {
+			return fmt.Errorf("unsupported rlimit resource: %s", name)
+		}
+		if limit.Soft > limit.hard() {
+			return fmt.Errorf("soft limit of %s (%d) exceeds its hard limit (%d)", name, limit.Soft, limit.hard())
+		}
+		if ceiling, ok := ceilings[name]; ok && limit.hard() > ceiling.hard() {
+			return fmt.Errorf("rlimit of %s (%d) exceeds the ceiling (%d)", name, limit.hard(), ceiling.hard())
+		}
+	}
+	return nil
+}
+
+// ParseRlimits parses rlimits in the format of `name=soft[:hard],...`, e.g., `nofile=1024:4096,cpu=60`.
+// The limit can be `unlimited`, e.g., `cpu=60:unlimited`.
+func ParseRlimits(s string) (map[string]Rlimit, error) {
+	limits := make(map[string]Rlimit)
+	if s == "" {
+		return limits, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rlimit %q", item)
+		}
+		if _, ok := rlimitResources[name]; !ok {
+			return nil, fmt.Errorf("unsupported rlimit resource: %s", name)
+		}
+		softStr, hardStr, hasHard := strings.Cut(value, ":")
+		var (
+			limit Rlimit
+			err   error
+		)
+		if limit.Soft, err = parseRlimitValue(softStr); err != nil {
+			return nil, fmt.Errorf("invalid soft limit of %s: %w", name, err)
+		}
+		if hasHard {
+			if limit.Hard, err = parseRlimitValue(hardStr); err != nil {
+				return nil, fmt.Errorf("invalid hard limit of %s: %w", name, err)
+			}
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+func parseRlimitValue(s string) (uint64, error) {
+	if s == "unlimited" {
+		return unix.RLIM_INFINITY, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+func formatRlimits(limits map[string]Rlimit) string {
+	items := make([]string, 0, len(limits))
+	for name, limit := range limits {
+		items = append(items, fmt.Sprintf("%s=%d:%d", name, limit.Soft, limit.hard()))
+	}
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
+
+// RlimitExec is the entry of RlimitExecCommand, args are the arguments after the subcommand.
+// It only returns on error.
+func RlimitExec(args []string) error {
+	if len(args) < 3 || args[1] != "--" {
+		return fmt.Errorf("usage: %s <limits> -- <program> [args...]", RlimitExecCommand)
+	}
+	limits, err := ParseRlimits(args[0])
+	if err != nil {
+		return err
+	}
+	for name, limit := range limits {
+		// NOTE: use syscall.Setrlimit instead of unix.Setrlimit, otherwise the
+		// nofile limit will be restored by go runtime when exec.
+		rlimit := syscall.Rlimit{Cur: limit.Soft, Max: limit.hard()}
+		if err := syscall.Setrlimit(rlimitResources[name], &rlimit); err != nil {
+			return fmt.Errorf("set rlimit of %s failed: %w", name, err)
+		}
+	}
+	return syscall.Exec(args[2], args[2:], os.Environ())
+}
@@ -0,0 +1,82 @@
+package process
+
+import (
+	"maps"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestParseRlimits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected map[string]Rlimit
+		wantErr  bool
+	}{
+		{"empty", "", map[string]Rlimit{}, false},
+		{"soft only", "cpu=60", map[string]Rlimit{"cpu": {Soft: 60}}, false},
+		{"soft and hard", "nofile=1024:4096,fsize=1048576", map[string]Rlimit{
+			"nofile": {Soft: 1024, Hard: 4096},
+			"fsize":  {Soft: 1048576},
+		}, false},
+		{"unlimited hard", "cpu=60:unlimited", map[string]Rlimit{"cpu": {Soft: 60, Hard: unix.RLIM_INFINITY}}, false},
+		{"unlimited", "as=unlimited", map[string]Rlimit{"as": {Soft: unix.RLIM_INFINITY}}, false},
+		{"missing value", "cpu", nil, true},
+		{"unsupported resource", "stack=1024", nil, true},
+		{"invalid soft", "cpu=abc", nil, true},
+		{"negative soft", "cpu=-1", nil, true},
+		{"invalid hard", "cpu=1:abc", nil, true},
+		{"empty hard", "cpu=1:", nil, true},
+		{"trailing comma", "cpu=1,", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limits, err := ParseRlimits(tc.input)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expect error, got %v", limits)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse rlimits failed: %s", err)
+			}
+			if !maps.Equal(limits, tc.expected) {
+				t.Fatalf("expect %v, got %v", tc.expected, limits)
+			}
+			// the rlimits passed to the helper are parsed back as is
+			formatted, err := ParseRlimits(formatRlimits(limits))
+			if err != nil {
+				t.Fatalf("parse formatted rlimits failed: %s", err)
+			}
+			for name, limit := range limits {
+				if got := formatted[name]; got.Soft != limit.Soft || got.hard() != limit.hard() {
+					t.Fatalf("expect %s=%v after formatting, got %v", name, limit, got)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRlimits(t *testing.T) {
+	ceilings := map[string]Rlimit{"nofile": {Soft: 4096}}
+	testCases := []struct {
+		name    string
+		limits  map[string]Rlimit
+		wantErr bool
+	}{
+		{"valid", map[string]Rlimit{"nofile": {Soft: 1024, Hard: 4096}, "cpu": {Soft: 60}}, false},
+		{"unsupported", map[string]Rlimit{"stack": {Soft: 1}}, true},
+		{"soft exceeds hard", map[string]Rlimit{"cpu": {Soft: 60, Hard: 30}}, true},
+		{"exceeds ceiling", map[string]Rlimit{"nofile": {Soft: 1024, Hard: 8192}}, true},
+		{"unlimited exceeds ceiling", map[string]Rlimit{"nofile": {Soft: unix.RLIM_INFINITY}}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := validateRlimits(tc.limits, ceilings); (err != nil) != tc.wantErr {
+				t.Fatalf("expect error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
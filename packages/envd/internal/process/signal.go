@@ -0,0 +1,61 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"syscall"
+)
+
+// The signals can be sent to the simple processes (see SimpleProcessManager.Signal).
+var allowedSignals = map[string]syscall.Signal{
+	"SIGHUP":   syscall.SIGHUP,
+	"SIGINT":   syscall.SIGINT,
+	"SIGQUIT":  syscall.SIGQUIT,
+	"SIGKILL":  syscall.SIGKILL,
+	"SIGUSR1":  syscall.SIGUSR1,
+	"SIGUSR2":  syscall.SIGUSR2,
+	"SIGTERM":  syscall.SIGTERM,
+	"SIGCONT":  syscall.SIGCONT,
+	"SIGSTOP":  syscall.SIGSTOP,
+	"SIGTSTP":  syscall.SIGTSTP,
+	"SIGWINCH": syscall.SIGWINCH,
+}
+
+// Signal is a signal in the request, either the number (e.g., 15) or
+// the name with or without the SIG prefix (e.g., "SIGTERM" or "term").
+type Signal struct {
+	syscall.Signal
+}
+
+func (s *Signal) UnmarshalJSON(b []byte) error {
+	var num int
+	if err := json.Unmarshal(b, &num); err == nil {
+		s.Signal = syscall.Signal(num)
+		return nil
+	}
+	var name string
+	if err := json.Unmarshal(b, &name); err != nil {
+		return fmt.Errorf("signal should be a number or a name: %s", b)
+	}
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := allowedSignals[name]
+	if !ok {
+		return fmt.Errorf("signal %s is not allowed", name)
+	}
+	s.Signal = sig
+	return nil
+}
+
+// Check the signal is in the allowlist.
+func validateSignal(sig syscall.Signal) error {
+	for _, allowed := range allowedSignals {
+		if sig == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("signal %d is not allowed", sig)
+}
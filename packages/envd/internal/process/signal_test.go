@@ -0,0 +1,46 @@
+package process
+
+import (
+	"encoding/json"
+	"syscall"
+	"testing"
+)
+
+func TestUnmarshalSignal(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected syscall.Signal
+		wantErr  bool
+	}{
+		{`15`, syscall.SIGTERM, false},
+		{`"SIGINT"`, syscall.SIGINT, false},
+		{`"hup"`, syscall.SIGHUP, false},
+		{`"SIGSEGV"`, 0, true},
+		{`"NOPE"`, 0, true},
+		{`true`, 0, true},
+	}
+	for _, tc := range testCases {
+		var sig Signal
+		err := json.Unmarshal([]byte(tc.input), &sig)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("expect error for %s, got %v", tc.input, sig)
+			}
+			continue
+		}
+		if err != nil || sig.Signal != tc.expected {
+			t.Fatalf("expect %v for %s, got %v (err: %v)", tc.expected, tc.input, sig.Signal, err)
+		}
+	}
+}
+
+func TestValidateSignal(t *testing.T) {
+	if err := validateSignal(syscall.SIGTERM); err != nil {
+		t.Fatalf("expect SIGTERM allowed, got %s", err)
+	}
+	for _, sig := range []syscall.Signal{0, syscall.SIGSEGV, 255} {
+		if err := validateSignal(sig); err == nil {
+			t.Fatalf("expect signal %d disallowed", sig)
+		}
+	}
+}
@@ -1,30 +1,83 @@
 package process
 
 import (
-	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/e2b-dev/infra/packages/envd/internal/user"
 	"go.uber.org/zap"
 )
 
 type SimpleProcess struct {
-	cmd     *exec.Cmd
-	stdout  bytes.Buffer
-	stderr  bytes.Buffer
-	exit_ch <-chan int
+	cmd    *exec.Cmd
+	output *simpleOutput
+	// what is requested to create the process (see SimpleProcessInfo)
+	cmdline   string
+	user      string
+	startedAt time.Time
+	// closed after the process exits
+	done     chan struct{}
+	exitCode int
+	exitedAt time.Time
+	// whether the process is terminated by the watchdog (see TimeoutMs),
+	// timedOut is settled (from timeoutFired) before done is closed
+	timeoutFired atomic.Bool
+	timedOut     bool
+	// the process leads its own session and process group (i.e., pgid is
+	// its pid), so that its children are signaled (e.g., killed) together
+	pgid int
 }
 
+// Send the signal to the process group, os.ErrProcessDone if all the processes
+// in it have exited. The group exists as long as any process in it is alive
+// (or not reaped), even if the process itself has exited.
+func (p *SimpleProcess) signalGroup(sig syscall.Signal) error {
+	select {
+	case <-p.done:
+		// The process has been reaped, so its pid may be reused by another
+		// process, which may lead a new group of the same id. The id still
+		// belongs to the group only if no process has the pid, as it cannot
+		// be reused while any process of the group is alive.
+		if _, err := os.Stat(fmt.Sprintf("/proc/%d", p.pgid)); !errors.Is(err, os.ErrNotExist) {
+			return os.ErrProcessDone
+		}
+	default:
+	}
+	err := syscall.Kill(-p.pgid, sig)
+	if errors.Is(err, syscall.ESRCH) {
+		return os.ErrProcessDone
+	}
+	return err
+}
+
+// SimpleProcessManager tracks the simple processes until their output is
+// collected by /process/wait or /process/stream. At most maxProcesses are
+// tracked (the creating fails with 429 beyond it), and the output of the
+// exited processes not collected is dropped after a ttl (see RunSweeper),
+// after which the pid is unknown to the other handlers.
 type SimpleProcessManager struct {
 	mu        sync.Mutex
 	processes map[int]*SimpleProcess
 	logger    *zap.SugaredLogger
+	// <= 0 means not limited
+	maxProcesses int
+	// the max size of buffered stdout (and stderr) for each process
+	maxOutputSize int
+	outputMode    OutputBufferMode
+	// the max rlimits can be requested
+	rlimitCeilings map[string]Rlimit
+	// see ReloadDefaults
+	defaults SandboxDefaults
 }
 
 type SimpleProcessCreateRequest struct {
@@ -32,6 +85,11 @@ type SimpleProcessCreateRequest struct {
 	User string            `json:"user,omitempty"`
 	Envs map[string]string `json:"envs,omitempty"`
 	Cwd  string            `json:"cwd,omitempty"`
+	// Resource limits (keyed by as, cpu, fsize, nofile or nproc) applied to the process, see [Rlimit].
+	Rlimits map[string]Rlimit `json:"rlimits,omitempty"`
+	// Terminate the process group (SIGTERM, then SIGKILL after a grace period)
+	// if the process runs longer than it, 0 means no timeout.
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
 }
 
 type SimpleProcessCreateResponse struct {
@@ -46,17 +104,92 @@ type SimpleProcessWaitResponse struct {
 	Stdout   string `json:"stdout"`
 	Stderr   string `json:"stderr"`
 	ExitCode int    `json:"exit_code"`
+	// Whether part of the output has been discarded as it exceeds the max buffered size.
+	StdoutTruncated bool `json:"stdout_truncated"`
+	StderrTruncated bool `json:"stderr_truncated"`
+	// Whether the process is terminated as it exceeds the timeout,
+	// the exit code is -1 then.
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+type SimpleProcessStreamRequest struct {
+	Pid int `json:"pid"`
+}
+
+type SimpleProcessStreamExitEvent struct {
+	ExitCode int  `json:"exit_code"`
+	TimedOut bool `json:"timed_out,omitempty"`
+}
+
+type SimpleProcessInfo struct {
+	Pid       int       `json:"pid"`
+	Cmd       string    `json:"cmd"`
+	User      string    `json:"user"`
+	StartedAt time.Time `json:"started_at"`
+	// Whether the process has exited (but not been waited or streamed yet),
+	// the exit code (and timed out) is only meaningful then.
+	Exited   bool `json:"exited"`
+	ExitCode int  `json:"exit_code"`
+	TimedOut bool `json:"timed_out,omitempty"`
+	// The size (in bytes) of stdout and stderr buffered for /process/wait.
+	StdoutSize int `json:"stdout_size"`
+	StderrSize int `json:"stderr_size"`
+}
+
+type SimpleProcessListResponse struct {
+	Processes []SimpleProcessInfo `json:"processes"`
 }
 
 type SimpleProcessKillRequest struct {
 	Pid int `json:"pid"`
 }
 
-func NewSimpleProcessManager(logger *zap.SugaredLogger) *SimpleProcessManager {
+type SimpleProcessSignalRequest struct {
+	// The pid of process, the signal is sent to its process group.
+	Pid    int    `json:"pid"`
+	Signal Signal `json:"signal"`
+}
+
+// maxOutputSize (and maxProcesses) <= 0 means the output (and the number
+// of processes) is not limited.
+func NewSimpleProcessManager(
+	logger *zap.SugaredLogger,
+	maxOutputSize int,
+	outputMode OutputBufferMode,
+	rlimitCeilings map[string]Rlimit,
+	maxProcesses int,
+) *SimpleProcessManager {
 	return &SimpleProcessManager{
-		processes: make(map[int]*SimpleProcess),
-		logger:    logger,
+		processes:      make(map[int]*SimpleProcess),
+		logger:         logger,
+		maxProcesses:   maxProcesses,
+		maxOutputSize:  maxOutputSize,
+		outputMode:     outputMode,
+		rlimitCeilings: rlimitCeilings,
+	}
+}
+
+// The total size of output buffered by all processes (in bytes).
+func (m *SimpleProcessManager) BufferedOutputSize() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	size := 0
+	for _, proc := range m.processes {
+		size += proc.output.Size()
 	}
+	return size
+}
+
+type invalidRequestError struct {
+	err error
+}
+
+func (e *invalidRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *invalidRequestError) Unwrap() error {
+	return e.err
 }
 
 func (m *SimpleProcessManager) getProc(pid int) *SimpleProcess {
@@ -69,10 +202,43 @@ func (m *SimpleProcessManager) getProc(pid int) *SimpleProcess {
 	}
 }
 
+// The processes tracked, in the order of pid.
+func (m *SimpleProcessManager) listProcs() []*SimpleProcess {
+	m.mu.Lock()
+	procs := make([]*SimpleProcess, 0, len(m.processes))
+	for _, proc := range m.processes {
+		procs = append(procs, proc)
+	}
+	m.mu.Unlock()
+	sort.Slice(procs, func(i, j int) bool { return procs[i].cmd.Process.Pid < procs[j].cmd.Process.Pid })
+	return procs
+}
+
+func (p *SimpleProcess) info() SimpleProcessInfo {
+	info := SimpleProcessInfo{
+		Pid:       p.cmd.Process.Pid,
+		Cmd:       p.cmdline,
+		User:      p.user,
+		StartedAt: p.startedAt,
+	}
+	select {
+	case <-p.done:
+		info.Exited = true
+		info.ExitCode = p.exitCode
+		info.TimedOut = p.timedOut
+	default:
+	}
+	info.StdoutSize, info.StderrSize = p.output.Sizes()
+	return info
+}
+
 func (m *SimpleProcessManager) putProc(proc *SimpleProcess) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	pid := proc.cmd.Process.Pid
+	if m.fullLocked() {
+		return errTooManyProcesses
+	}
 	if _, exist := m.processes[pid]; !exist {
 		m.processes[pid] = proc
 		return nil
@@ -87,24 +253,44 @@ func (m *SimpleProcessManager) delProc(pid int) {
 	delete(m.processes, pid)
 }
 
-func create(req *SimpleProcessCreateRequest, logger *zap.SugaredLogger) (*SimpleProcess, error) {
-	cmd := exec.Command("/bin/bash", "-l", "-c", req.Cmd)
-	userName := user.DefaultUser
+func (m *SimpleProcessManager) create(req *SimpleProcessCreateRequest) (*SimpleProcess, error) {
+	if err := validateRlimits(req.Rlimits, m.rlimitCeilings); err != nil {
+		return nil, &invalidRequestError{err}
+	}
+	if req.TimeoutMs < 0 {
+		return nil, &invalidRequestError{fmt.Errorf("timeout cannot be negative: %d", req.TimeoutMs)}
+	}
+	// fail fast before starting, putProc checks again
+	if m.full() {
+		return nil, errTooManyProcesses
+	}
+	var cmd *exec.Cmd
+	if len(req.Rlimits) > 0 {
+		cmd = exec.Command("/proc/self/exe", RlimitExecCommand, formatRlimits(req.Rlimits), "--", "/bin/bash", "-l", "-c", req.Cmd)
+	} else {
+		cmd = exec.Command("/bin/bash", "-l", "-c", req.Cmd)
+	}
+	userName := user.Default()
 	if len(req.User) > 0 {
 		userName = req.User
 	}
 	uid, gid, homedir, username, err := user.GetUser(userName)
 	if err != nil {
-		return nil, fmt.Errorf("error getting user '%s': %w", user.DefaultUser, err)
+		return nil, fmt.Errorf("error getting user '%s': %w", userName, err)
 	}
 
-	cmd.SysProcAttr = &syscall.SysProcAttr{}
+	// in a new session, so that the children of bash are not orphaned (and
+	// keep running) when killing the process, see SimpleProcess.pgid.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
 	cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid), Groups: []uint32{uint32(gid)}, NoSetGroups: true}
 
-	if req.Cwd == "" {
-		cmd.Dir = homedir
-	} else {
+	defaults := m.getDefaults()
+	if req.Cwd != "" {
 		cmd.Dir = req.Cwd
+	} else if defaults.WorkingDir != "" {
+		cmd.Dir = defaults.WorkingDir
+	} else {
+		cmd.Dir = homedir
 	}
 	// We inherit the env vars from the root process, but we should handle this differently in the future.
 	formattedVars := os.Environ()
@@ -114,31 +300,48 @@ func create(req *SimpleProcessCreateRequest, logger *zap.SugaredLogger) (*Simple
 	formattedVars = append(formattedVars, "LOGNAME="+username)
 
 	// Only the last values of the env vars are used - this allows for overwriting defaults
+	for key, value := range defaults.Env {
+		formattedVars = append(formattedVars, key+"="+value)
+	}
 	for key, value := range req.Envs {
 		formattedVars = append(formattedVars, key+"="+value)
 	}
 
 	cmd.Env = formattedVars
 
-	exit_ch := make(chan int, 1)
 	proc := &SimpleProcess{
 		cmd:     cmd,
-		exit_ch: exit_ch,
+		output:  newSimpleOutput(m.maxOutputSize, m.outputMode),
+		done:    make(chan struct{}),
+		cmdline: req.Cmd,
+		user:    username,
 	}
-	cmd.Stdout = &proc.stdout
-	cmd.Stderr = &proc.stderr
+	cmd.Stdout = proc.output.stdout
+	cmd.Stderr = proc.output.stderr
 
 	if err = cmd.Start(); err != nil {
 		return proc, err
 	}
+	proc.pgid = cmd.Process.Pid
+	proc.startedAt = time.Now()
 
 	go func() {
 		if err := cmd.Wait(); err != nil {
-			logger.Errorw("Failed to wait for process", "processID", cmd.Process.Pid, "error", err)
+			m.logger.Errorw("Failed to wait for process", "processID", cmd.Process.Pid, "error", err)
+		}
+		// cmd.Wait() returns after all output has been copied
+		proc.output.close()
+		proc.exitCode = cmd.ProcessState.ExitCode()
+		proc.exitedAt = time.Now()
+		if proc.timeoutFired.Load() {
+			proc.timedOut = true
+			proc.exitCode = -1
 		}
-		exit_ch <- cmd.ProcessState.ExitCode()
-		close(exit_ch)
+		close(proc.done)
 	}()
+	if req.TimeoutMs > 0 {
+		go proc.watchdog(time.Duration(req.TimeoutMs)*time.Millisecond, timeoutKillGracePeriod)
+	}
 
 	return proc, nil
 }
@@ -154,13 +357,25 @@ func (m *SimpleProcessManager) Create(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		p, err := create(&req, m.logger)
+		p, err := m.create(&req)
 		if err != nil {
-			http.Error(w, fmt.Sprintf("create process failed: %s", err), http.StatusInternalServerError)
+			code := http.StatusInternalServerError
+			if errors.As(err, new(*invalidRequestError)) {
+				code = http.StatusBadRequest
+			} else if errors.Is(err, errTooManyProcesses) {
+				code = http.StatusTooManyRequests
+			}
+			http.Error(w, fmt.Sprintf("create process failed: %s", err), code)
 			return
 		}
 		if err := m.putProc(p); err != nil {
-			http.Error(w, fmt.Sprintf("create process failed: %s", err), http.StatusInternalServerError)
+			// not tracked, so nobody can wait (or kill) it
+			p.signalGroup(syscall.SIGKILL)
+			code := http.StatusInternalServerError
+			if errors.Is(err, errTooManyProcesses) {
+				code = http.StatusTooManyRequests
+			}
+			http.Error(w, fmt.Sprintf("create process failed: %s", err), code)
 			return
 		}
 
@@ -175,6 +390,9 @@ func (m *SimpleProcessManager) Create(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Wait for the process to exit and respond its output, after which the
+// process is removed. The output of an exited process never waited (nor
+// streamed) expires after the -process-output-ttl, then it responds 404.
 func (m *SimpleProcessManager) Wait(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
@@ -189,14 +407,114 @@ func (m *SimpleProcessManager) Wait(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, fmt.Sprintf("process not found: %d", req.Pid), http.StatusInternalServerError)
 			return
 		}
-		exitCode := <-p.exit_ch
+		<-p.done
 
+		stdoutTruncated, stderrTruncated := p.output.Truncated()
 		response := SimpleProcessWaitResponse{
-			ExitCode: exitCode,
-			Stdout:   p.stdout.String(),
-			Stderr:   p.stderr.String(),
+			ExitCode:        p.exitCode,
+			Stdout:          p.output.Stdout(),
+			Stderr:          p.output.Stderr(),
+			StdoutTruncated: stdoutTruncated,
+			StderrTruncated: stderrTruncated,
+			TimedOut:        p.timedOut,
+		}
+		m.delProc(req.Pid)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			http.Error(w, fmt.Sprintf("encode response failed: %s", err), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
+
+// Stream the stdout/stderr of process as server-sent events, each line is an
+// event (named stdout or stderr) whose data is an [output.OutMessage]. The output
+// before the request is sent first. Finally, an exit event is sent after the process exits.
+//
+// Similar to Wait, the process will be removed after exit event is sent.
+func (m *SimpleProcessManager) Stream(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		decoder := json.NewDecoder(r.Body)
+		var req SimpleProcessStreamRequest
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		p := m.getProc(req.Pid)
+		if p == nil {
+			http.Error(w, fmt.Sprintf("process not found: %d", req.Pid), http.StatusInternalServerError)
+			return
+		}
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+		published, sub := p.output.subscribe()
+		defer p.output.unsubscribe(sub)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+		for _, msg := range published {
+			if err := writeEvent(w, string(msg.Type), msg); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+
+	loop:
+		for {
+			select {
+			case msg, ok := <-sub.ch:
+				if !ok {
+					break loop
+				}
+				if err := writeEvent(w, string(msg.Type), msg); err != nil {
+					m.logger.Errorw("Failed to stream process output", "processID", req.Pid, "error", err)
+					return
+				}
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		<-p.done
+		if err := writeEvent(w, "exit", SimpleProcessStreamExitEvent{ExitCode: p.exitCode, TimedOut: p.timedOut}); err != nil {
+			m.logger.Errorw("Failed to stream process exit", "processID", req.Pid, "error", err)
+			return
+		}
+		flusher.Flush()
 		m.delProc(req.Pid)
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeEvent(w io.Writer, event string, data any) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
+
+// List the processes tracked, i.e., the ones not waited (or streamed till
+// exit) yet, so that the clients can reconcile after reconnected.
+func (m *SimpleProcessManager) List(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		procs := m.listProcs()
+		response := SimpleProcessListResponse{Processes: make([]SimpleProcessInfo, 0, len(procs))}
+		for _, proc := range procs {
+			response.Processes = append(response.Processes, proc.info())
+		}
 		w.Header().Set("Content-Type", "application/json")
 		if err := json.NewEncoder(w).Encode(response); err != nil {
 			http.Error(w, fmt.Sprintf("encode response failed: %s", err), http.StatusInternalServerError)
@@ -218,10 +536,17 @@ func (m *SimpleProcessManager) Kill(w http.ResponseWriter, r *http.Request) {
 		}
 		p := m.getProc(req.Pid)
 		if p == nil {
-			http.Error(w, fmt.Sprintf("process not found: %d", req.Pid), http.StatusInternalServerError)
+			http.Error(w, fmt.Sprintf("process not found: %d", req.Pid), http.StatusNotFound)
+			return
+		}
+		// kill the children too, which would keep running (and block Wait
+		// if they hold the stdout or stderr) after the bash is killed
+		err := p.signalGroup(syscall.SIGKILL)
+		if errors.Is(err, os.ErrProcessDone) {
+			http.Error(w, fmt.Sprintf("process %d has exited", req.Pid), http.StatusConflict)
 			return
 		}
-		if err := p.cmd.Process.Kill(); err != nil {
+		if err != nil {
 			http.Error(w, fmt.Sprintf("send kill to process %d failed: %s", req.Pid, err), http.StatusInternalServerError)
 			return
 		}
@@ -229,3 +554,38 @@ func (m *SimpleProcessManager) Kill(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
 	}
 }
+
+// Send a signal in the allowlist (see allowedSignals) to the process group of
+// the process. Like Kill, it responds 404 for the unknown process and 409 if all
+// the processes in the group have exited, besides 400 for the disallowed signal.
+func (m *SimpleProcessManager) Signal(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		decoder := json.NewDecoder(r.Body)
+		var req SimpleProcessSignalRequest
+		if err := decoder.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := validateSignal(req.Signal.Signal); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p := m.getProc(req.Pid)
+		if p == nil {
+			http.Error(w, fmt.Sprintf("process not found: %d", req.Pid), http.StatusNotFound)
+			return
+		}
+		err := p.signalGroup(req.Signal.Signal)
+		if errors.Is(err, os.ErrProcessDone) {
+			http.Error(w, fmt.Sprintf("process %d has exited", req.Pid), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("send %s to process %d failed: %s", req.Signal.Signal, req.Pid, err), http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,343 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/e2b-dev/infra/packages/envd/internal/output"
+)
+
+const (
+	// The default max size of stdout (and stderr) kept for /process/wait.
+	DefaultMaxBufferedOutputSize = 4 << 20
+	truncatedMarker              = "\n[envd: output truncated]\n"
+	// Split the line (when streaming) if it is too long without a newline.
+	maxStreamLineSize = 64 << 10
+	// The size of per-subscriber channel.
+	streamChannelSize = 256
+)
+
+// OutputBufferMode decides which part of the output is kept
+// when it exceeds the max buffered size.
+type OutputBufferMode string
+
+const (
+	// Keep the first N bytes, the following output is discarded.
+	OutputBufferTruncate OutputBufferMode = "truncate"
+	// Keep the last N bytes, the earlier output is discarded.
+	OutputBufferRing OutputBufferMode = "ring"
+)
+
+func ParseOutputBufferMode(s string) (OutputBufferMode, error) {
+	switch mode := OutputBufferMode(s); mode {
+	case OutputBufferTruncate, OutputBufferRing:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown output buffer mode: %s", s)
+	}
+}
+
+// cappedBuffer keeps at most `limit` bytes (no limit if limit <= 0),
+// the exceeded part will be discarded (with a marker when reading).
+type cappedBuffer struct {
+	mode  OutputBufferMode
+	limit int
+	// for truncate mode, data is the head of output.
+	// for ring mode, data is a ring (after it is full) starting at start.
+	data      []byte
+	start     int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) {
+	if b.limit <= 0 {
+		b.data = append(b.data, p...)
+		return
+	}
+	switch b.mode {
+	case OutputBufferRing:
+		b.writeRing(p)
+	default:
+		remain := b.limit - len(b.data)
+		if len(p) > remain {
+			p = p[:remain]
+			b.truncated = true
+		}
+		b.data = append(b.data, p...)
+	}
+}
+
+func (b *cappedBuffer) writeRing(p []byte) {
+	if len(p) >= b.limit {
+		// only the tail of p is kept
+		b.truncated = b.truncated || len(b.data) > 0 || len(p) > b.limit
+		b.data = append(b.data[:0], p[len(p)-b.limit:]...)
+		b.start = 0
+		return
+	}
+	if remain := b.limit - len(b.data); remain > 0 {
+		n := min(remain, len(p))
+		b.data = append(b.data, p[:n]...)
+		p = p[n:]
+	}
+	for len(p) > 0 {
+		b.truncated = true
+		n := copy(b.data[b.start:], p)
+		b.start = (b.start + n) % b.limit
+		p = p[n:]
+	}
+}
+
+// Bytes returns the kept output (without the marker).
+func (b *cappedBuffer) Bytes() []byte {
+	if b.start == 0 {
+		return b.data
+	}
+	res := make([]byte, 0, len(b.data))
+	res = append(res, b.data[b.start:]...)
+	return append(res, b.data[:b.start]...)
+}
+
+func (b *cappedBuffer) Len() int {
+	return len(b.data)
+}
+
+func (b *cappedBuffer) String() string {
+	if !b.truncated {
+		return string(b.Bytes())
+	}
+	if b.mode == OutputBufferRing {
+		return truncatedMarker[1:] + string(b.Bytes())
+	}
+	return string(b.Bytes()) + truncatedMarker
+}
+
+type outputSubscriber struct {
+	ch   chan output.OutMessage
+	done chan struct{}
+}
+
+// simpleOutput collects the output of a SimpleProcess. It keeps a (capped) copy
+// of stdout and stderr for /process/wait and also delivers the output line by
+// line to the subscribers of /process/stream.
+type simpleOutput struct {
+	mu     sync.Mutex
+	stdout *outputWriter
+	stderr *outputWriter
+	// the lines published so far (of both stdout and stderr) in the order they
+	// are published, which are replayed to the new subscribers.
+	records recordBuffer
+	closed  bool
+	// serializes the publishing, so that the subscribers receive the
+	// lines in the same order as records.
+	publishMu   sync.Mutex
+	subscribers map[*outputSubscriber]struct{}
+}
+
+func newSimpleOutput(limit int, mode OutputBufferMode) *simpleOutput {
+	o := &simpleOutput{
+		// the records keep (at most) as much as stdout and stderr together
+		records:     recordBuffer{mode: mode, limit: 2 * limit},
+		subscribers: make(map[*outputSubscriber]struct{}),
+	}
+	o.stdout = &outputWriter{out: o, typ: output.OutTypeStdout, buf: cappedBuffer{mode: mode, limit: limit}}
+	o.stderr = &outputWriter{out: o, typ: output.OutTypeStderr, buf: cappedBuffer{mode: mode, limit: limit}}
+	return o
+}
+
+func (o *simpleOutput) Stdout() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stdout.buf.String()
+}
+
+func (o *simpleOutput) Stderr() string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stderr.buf.String()
+}
+
+// Whether stdout and stderr have been truncated.
+func (o *simpleOutput) Truncated() (stdout bool, stderr bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stdout.buf.truncated, o.stderr.buf.truncated
+}
+
+// The size of buffered stdout and stderr (in bytes) respectively,
+// i.e., what /process/wait returns.
+func (o *simpleOutput) Sizes() (stdout int, stderr int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stdout.buf.Len(), o.stderr.buf.Len()
+}
+
+// The size of buffered stdout and stderr (in bytes), including the lines kept for replay.
+func (o *simpleOutput) Size() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stdout.buf.Len() + o.stderr.buf.Len() + o.records.size
+}
+
+// subscribe returns the output published so far and a subscriber for the following lines.
+// The channel of subscriber will be closed after the process exits and all output has been sent.
+func (o *simpleOutput) subscribe() ([]output.OutMessage, *outputSubscriber) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	published := o.records.Messages()
+	sub := &outputSubscriber{
+		ch:   make(chan output.OutMessage, streamChannelSize),
+		done: make(chan struct{}),
+	}
+	if o.closed {
+		close(sub.ch)
+	} else {
+		o.subscribers[sub] = struct{}{}
+	}
+	return published, sub
+}
+
+func (o *simpleOutput) unsubscribe(sub *outputSubscriber) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.subscribers[sub]; ok {
+		delete(o.subscribers, sub)
+		close(sub.done)
+	}
+}
+
+// Record msgs and return the current subscribers, which msgs should be published to.
+// Should be called with o.mu held.
+func (o *simpleOutput) recordLocked(msgs []output.OutMessage) []*outputSubscriber {
+	for _, msg := range msgs {
+		o.records.Append(msg)
+	}
+	subs := make([]*outputSubscriber, 0, len(o.subscribers))
+	for sub := range o.subscribers {
+		subs = append(subs, sub)
+	}
+	return subs
+}
+
+// Should be called after the process exits (i.e., no more writes).
+func (o *simpleOutput) close() {
+	o.publishMu.Lock()
+	defer o.publishMu.Unlock()
+	o.stdout.flushLocked()
+	o.stderr.flushLocked()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closed = true
+	for sub := range o.subscribers {
+		close(sub.ch)
+	}
+	o.subscribers = make(map[*outputSubscriber]struct{})
+}
+
+// NOTE: we block (instead of drop) when subscriber is slow, which throttles
+// the process through the pipe. A gone subscriber will close its done channel.
+func publish(subs []*outputSubscriber, msgs []output.OutMessage) {
+	for _, sub := range subs {
+		for _, msg := range msgs {
+			select {
+			case sub.ch <- msg:
+			case <-sub.done:
+			}
+		}
+	}
+}
+
+// outputWriter is used as cmd.Stdout (or cmd.Stderr) of SimpleProcess.
+// All fields are protected by out.mu.
+type outputWriter struct {
+	out *simpleOutput
+	typ output.OutType
+	buf cappedBuffer
+	// the partial line (without newline) not yet published
+	line []byte
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	var msgs []output.OutMessage
+	w.out.publishMu.Lock()
+	defer w.out.publishMu.Unlock()
+	w.out.mu.Lock()
+	w.buf.Write(p)
+	w.line = append(w.line, p...)
+	for {
+		idx := bytes.IndexByte(w.line, '\n')
+		if idx < 0 {
+			break
+		}
+		msgs = append(msgs, w.newMessage(string(w.line[:idx])))
+		w.line = w.line[idx+1:]
+	}
+	for len(w.line) >= maxStreamLineSize {
+		msgs = append(msgs, w.newMessage(string(w.line[:maxStreamLineSize])))
+		w.line = w.line[maxStreamLineSize:]
+	}
+	// do not hold the large underlying array
+	w.line = append([]byte(nil), w.line...)
+	subs := w.out.recordLocked(msgs)
+	w.out.mu.Unlock()
+
+	publish(subs, msgs)
+	return len(p), nil
+}
+
+// Publish the partial line. Should be called with out.publishMu held.
+func (w *outputWriter) flushLocked() {
+	w.out.mu.Lock()
+	if len(w.line) == 0 {
+		w.out.mu.Unlock()
+		return
+	}
+	msgs := []output.OutMessage{w.newMessage(string(w.line))}
+	w.line = nil
+	subs := w.out.recordLocked(msgs)
+	w.out.mu.Unlock()
+	publish(subs, msgs)
+}
+
+func (w *outputWriter) newMessage(line string) output.OutMessage {
+	if w.typ == output.OutTypeStdout {
+		return output.NewStdoutMessage(line)
+	}
+	return output.NewStderrMessage(line)
+}
+
+// recordBuffer keeps the published lines (in order) whose total size is at
+// most `limit` bytes (no limit if limit <= 0). Similar to cappedBuffer, the
+// earliest lines are dropped in ring mode, otherwise the following lines are.
+type recordBuffer struct {
+	mode    OutputBufferMode
+	limit   int
+	records []output.OutMessage
+	size    int
+}
+
+func (b *recordBuffer) Append(msg output.OutMessage) {
+	if b.limit > 0 && len(msg.Line) > b.limit {
+		// the line cannot be kept anyway
+		return
+	}
+	if b.limit > 0 && b.size+len(msg.Line) > b.limit {
+		if b.mode != OutputBufferRing {
+			return
+		}
+		for len(b.records) > 0 && b.size+len(msg.Line) > b.limit {
+			b.size -= len(b.records[0].Line)
+			b.records[0] = output.OutMessage{}
+			b.records = b.records[1:]
+		}
+	}
+	b.records = append(b.records, msg)
+	b.size += len(msg.Line)
+}
+
+// Messages returns a copy of the kept lines.
+func (b *recordBuffer) Messages() []output.OutMessage {
+	return slices.Clone(b.records)
+}
@@ -0,0 +1,185 @@
+package process
+
+import (
+	"testing"
+	"time"
+
+	"github.com/e2b-dev/infra/packages/envd/internal/output"
+)
+
+type line struct {
+	typ  output.OutType
+	line string
+}
+
+func toLines(msgs []output.OutMessage) []line {
+	var lines []line
+	for _, msg := range msgs {
+		lines = append(lines, line{msg.Type, msg.Line})
+	}
+	return lines
+}
+
+func expectLines(t *testing.T, got []output.OutMessage, expected ...line) {
+	t.Helper()
+	lines := toLines(got)
+	if len(lines) != len(expected) {
+		t.Fatalf("expect %v, got %v", expected, lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Fatalf("expect %v, got %v", expected, lines)
+		}
+	}
+}
+
+func receive(t *testing.T, sub *outputSubscriber) (output.OutMessage, bool) {
+	t.Helper()
+	select {
+	case msg, ok := <-sub.ch:
+		return msg, ok
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for output")
+		return output.OutMessage{}, false
+	}
+}
+
+func TestSimpleOutputReplayOrder(t *testing.T) {
+	o := newSimpleOutput(0, OutputBufferTruncate)
+	o.stdout.Write([]byte("out-1\n"))
+	o.stderr.Write([]byte("err-1\nerr-2\n"))
+	o.stdout.Write([]byte("out-2\nout-"))
+	o.stderr.Write([]byte("err-3\n"))
+	o.stdout.Write([]byte("3\n"))
+	// the partial line is not published yet
+	o.stderr.Write([]byte("err-4"))
+
+	published, sub := o.subscribe()
+	defer o.unsubscribe(sub)
+	expectLines(t, published,
+		line{output.OutTypeStdout, "out-1"},
+		line{output.OutTypeStderr, "err-1"},
+		line{output.OutTypeStderr, "err-2"},
+		line{output.OutTypeStdout, "out-2"},
+		line{output.OutTypeStderr, "err-3"},
+		line{output.OutTypeStdout, "out-3"},
+	)
+	for i := 1; i < len(published); i++ {
+		if published[i].Timestamp < published[i-1].Timestamp {
+			t.Fatalf("the timestamp of replayed lines should not decrease: %v", published)
+		}
+	}
+
+	// the following lines are delivered after the replayed ones
+	o.stdout.Write([]byte("out-4\n"))
+	msg, ok := receive(t, sub)
+	if !ok || msg.Type != output.OutTypeStdout || msg.Line != "out-4" {
+		t.Fatalf("unexpected message %v (ok: %v)", msg, ok)
+	}
+
+	o.close()
+	msg, ok = receive(t, sub)
+	if !ok || msg.Type != output.OutTypeStderr || msg.Line != "err-4" {
+		t.Fatalf("expect the partial line flushed when closing, got %v (ok: %v)", msg, ok)
+	}
+	if _, ok := receive(t, sub); ok {
+		t.Fatal("expect channel closed after the output closed")
+	}
+
+	// subscribe after closed still gets the whole output
+	published, sub = o.subscribe()
+	if len(published) != 8 {
+		t.Fatalf("expect 8 lines, got %v", toLines(published))
+	}
+	if _, ok := receive(t, sub); ok {
+		t.Fatal("expect channel closed for subscriber after the output closed")
+	}
+}
+
+func TestSimpleOutputReplayLimit(t *testing.T) {
+	// the records keep at most 2 * 4 bytes
+	ring := newSimpleOutput(4, OutputBufferRing)
+	ring.stdout.Write([]byte("aaa\n"))
+	ring.stderr.Write([]byte("bbb\n"))
+	ring.stdout.Write([]byte("ccc\n"))
+	published, _ := ring.subscribe()
+	expectLines(t, published,
+		line{output.OutTypeStderr, "bbb"},
+		line{output.OutTypeStdout, "ccc"},
+	)
+
+	truncate := newSimpleOutput(4, OutputBufferTruncate)
+	truncate.stdout.Write([]byte("aaa\n"))
+	truncate.stderr.Write([]byte("bbb\n"))
+	truncate.stdout.Write([]byte("ccc\n"))
+	published, _ = truncate.subscribe()
+	expectLines(t, published,
+		line{output.OutTypeStdout, "aaa"},
+		line{output.OutTypeStderr, "bbb"},
+	)
+	if size := truncate.Size(); size != 4+4+6 {
+		t.Fatalf("unexpected buffered size %d", size)
+	}
+}
+
+func TestCappedBuffer(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mode      OutputBufferMode
+		limit     int
+		writes    []string
+		expected  string
+		truncated bool
+	}{
+		{"no limit", OutputBufferRing, 0, []string{"abc", "defgh"}, "abcdefgh", false},
+		{"truncate", OutputBufferTruncate, 4, []string{"abc", "def"}, "abcd", true},
+		{"truncate exactly full", OutputBufferTruncate, 4, []string{"ab", "cd", ""}, "abcd", false},
+		{"truncate after full", OutputBufferTruncate, 4, []string{"abcd", "e"}, "abcd", true},
+		{"ring not full", OutputBufferRing, 4, []string{"ab", "c"}, "abc", false},
+		{"ring exactly full", OutputBufferRing, 4, []string{"ab", "cd"}, "abcd", false},
+		{"ring exactly full in one write", OutputBufferRing, 4, []string{"abcd"}, "abcd", false},
+		{"ring fill and wrap", OutputBufferRing, 4, []string{"ab", "cdef"}, "cdef", true},
+		{"ring wrap twice", OutputBufferRing, 4, []string{"abcd", "ef", "ghi"}, "fghi", true},
+		{"ring wrap to start", OutputBufferRing, 4, []string{"abcd", "ef", "gh"}, "efgh", true},
+		{"ring write larger than cap", OutputBufferRing, 4, []string{"abcdef"}, "cdef", true},
+		{"ring write cap after wrap", OutputBufferRing, 4, []string{"abc", "de", "wxyz"}, "wxyz", true},
+		{"ring write larger than cap after wrap", OutputBufferRing, 4, []string{"abc", "de", "uvwxyz", "1"}, "xyz1", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := cappedBuffer{mode: tc.mode, limit: tc.limit}
+			for _, w := range tc.writes {
+				b.Write([]byte(w))
+			}
+			if got := string(b.Bytes()); got != tc.expected {
+				t.Fatalf("expect %q, got %q", tc.expected, got)
+			}
+			if b.truncated != tc.truncated {
+				t.Fatalf("expect truncated %v, got %v", tc.truncated, b.truncated)
+			}
+			if tc.limit > 0 && b.Len() > tc.limit {
+				t.Fatalf("buffer exceeds the limit: %d > %d", b.Len(), tc.limit)
+			}
+		})
+	}
+}
+
+func TestCappedBufferString(t *testing.T) {
+	truncate := cappedBuffer{mode: OutputBufferTruncate, limit: 4}
+	truncate.Write([]byte("abcdef"))
+	if got := truncate.String(); got != "abcd"+truncatedMarker {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	ring := cappedBuffer{mode: OutputBufferRing, limit: 4}
+	ring.Write([]byte("abcdef"))
+	if got := ring.String(); got != truncatedMarker[1:]+"cdef" {
+		t.Fatalf("unexpected output %q", got)
+	}
+
+	full := cappedBuffer{mode: OutputBufferRing, limit: 4}
+	full.Write([]byte("abcd"))
+	if got := full.String(); got != "abcd" {
+		t.Fatalf("unexpected output %q", got)
+	}
+}
@@ -0,0 +1,78 @@
+package process
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestSignalGroup(t *testing.T) {
+	// the child of bash holds the stdout, so Wait returns only after it exits
+	cmd := exec.Command("/bin/bash", "-c", "sleep 60; true")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	cmd.Stdout = io.Discard
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	proc := &SimpleProcess{cmd: cmd, pgid: cmd.Process.Pid}
+
+	waited := make(chan error, 1)
+	go func() { waited <- cmd.Wait() }()
+	if err := proc.signalGroup(syscall.SIGKILL); err != nil {
+		t.Fatalf("kill process group failed: %s", err)
+	}
+	select {
+	case <-waited:
+	case <-time.After(10 * time.Second):
+		t.Fatal("expect the process group killed")
+	}
+	if err := proc.signalGroup(syscall.SIGTERM); !errors.Is(err, os.ErrProcessDone) {
+		t.Fatalf("expect process done after the group exited, got %v", err)
+	}
+}
+
+func TestSignalGroupReusedPid(t *testing.T) {
+	// another group led by the pid of the reaped process
+	other := startTestProcess(t, "sleep 60; true")
+	done := make(chan struct{})
+	close(done)
+	proc := &SimpleProcess{pgid: other.pgid, done: done}
+	if err := proc.signalGroup(syscall.SIGKILL); !errors.Is(err, os.ErrProcessDone) {
+		t.Fatalf("expect process done after reaped, got %v", err)
+	}
+	select {
+	case <-other.done:
+		t.Fatal("expect the other group not signaled")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestListProcesses(t *testing.T) {
+	m := NewSimpleProcessManager(nil, DefaultMaxBufferedOutputSize, OutputBufferTruncate, nil, 0)
+	running := startTestProcess(t, "sleep 60; true")
+	exited := startTestProcess(t, "true")
+	for _, proc := range []*SimpleProcess{running, exited} {
+		proc.output = newSimpleOutput(DefaultMaxBufferedOutputSize, OutputBufferTruncate)
+		proc.cmdline = "test"
+		if err := m.putProc(proc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-exited.done
+	exited.output.stdout.Write([]byte("hello\n"))
+
+	procs := m.listProcs()
+	if len(procs) != 2 || procs[0].cmd.Process.Pid > procs[1].cmd.Process.Pid {
+		t.Fatalf("expect 2 processes in the order of pid, got %v", procs)
+	}
+	if info := running.info(); info.Exited || info.Cmd != "test" {
+		t.Fatalf("expect the process running, got %+v", info)
+	}
+	if info := exited.info(); !info.Exited || info.ExitCode != 0 || info.StdoutSize != len("hello\n") || info.StderrSize != 0 {
+		t.Fatalf("expect the process exited with stdout buffered, got %+v", info)
+	}
+}
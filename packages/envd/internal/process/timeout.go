@@ -0,0 +1,38 @@
+package process
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+)
+
+// How long the process group has to exit after SIGTERM before SIGKILL
+// is sent, when the process times out.
+const timeoutKillGracePeriod = 5 * time.Second
+
+// Terminate the process group once the process runs longer than timeout:
+// SIGTERM first, then SIGKILL after the grace period. It returns as soon
+// as the process exits in time, so the timer does not outlive the process.
+// Once terminated, the group is killed after the grace period even if the
+// process exits, as the rest of the group may ignore SIGTERM.
+func (p *SimpleProcess) watchdog(timeout, grace time.Duration) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+		return
+	case <-timer.C:
+	}
+
+	// before signaling, as the process may be waited (and timedOut settled)
+	// right after it is terminated
+	p.timeoutFired.Store(true)
+	if err := p.signalGroup(syscall.SIGTERM); errors.Is(err, os.ErrProcessDone) {
+		p.timeoutFired.Store(false)
+		return
+	}
+	timer.Reset(grace)
+	<-timer.C
+	p.signalGroup(syscall.SIGKILL)
+}
@@ -0,0 +1,96 @@
+package process
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func startTestProcess(t *testing.T, script string) *SimpleProcess {
+	t.Helper()
+	cmd := exec.Command("/bin/bash", "-c", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	proc := &SimpleProcess{cmd: cmd, pgid: cmd.Process.Pid, done: make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		proc.exitedAt = time.Now()
+		close(proc.done)
+	}()
+	t.Cleanup(func() {
+		proc.signalGroup(syscall.SIGKILL)
+		<-proc.done
+	})
+	return proc
+}
+
+func TestWatchdog(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		fired  bool
+	}{
+		{"exited", "true", false},
+		{"terminated", "sleep 60; true", true},
+		// SIGTERM is ignored, so it is killed after the grace period
+		{"killed", "trap '' TERM; sleep 60; true", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			proc := startTestProcess(t, tc.script)
+			returned := make(chan struct{})
+			go func() {
+				proc.watchdog(100*time.Millisecond, 100*time.Millisecond)
+				close(returned)
+			}()
+			select {
+			case <-returned:
+			case <-time.After(10 * time.Second):
+				t.Fatal("expect the watchdog returned")
+			}
+			select {
+			case <-proc.done:
+			case <-time.After(10 * time.Second):
+				t.Fatal("expect the process exited")
+			}
+			if fired := proc.timeoutFired.Load(); fired != tc.fired {
+				t.Fatalf("expect timeout fired %v, got %v", tc.fired, fired)
+			}
+		})
+	}
+}
+
+func TestWatchdogKillsGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	// the process exits on SIGTERM, but the child ignoring it (and not
+	// holding the output) keeps running
+	proc := startTestProcess(t, "(trap '' TERM; exec sleep 60) >/dev/null 2>&1 & echo $! > "+pidFile+"; wait")
+	proc.watchdog(100*time.Millisecond, 100*time.Millisecond)
+	if !proc.timeoutFired.Load() {
+		t.Fatal("expect timeout fired")
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid := strings.TrimSpace(string(data))
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		// gone, or a zombie not reaped (by init) yet
+		stat, err := os.ReadFile(filepath.Join("/proc", pid, "stat"))
+		if err != nil || strings.Contains(string(stat), ") Z ") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expect the child %s killed, got %s", pid, stat)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -31,9 +31,9 @@ func New(id, shell string, rootdir *string, cols, rows uint16, envVars *map[stri
 		cmd = exec.Command(shell, "-i", "-l")
 	}
 
-	uid, gid, homedir, username, err := user.GetUser(user.DefaultUser)
+	uid, gid, homedir, username, err := user.GetUser(user.Default())
 	if err != nil {
-		return nil, fmt.Errorf("error getting user '%s': %w", user.DefaultUser, err)
+		return nil, fmt.Errorf("error getting user '%s': %w", user.Default(), err)
 	}
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{}
@@ -1,13 +1,40 @@
 package user
 
 import (
+	"errors"
 	"fmt"
 	"os/user"
 	"strconv"
 )
 
+// The default user compiled in, which can be changed by SetDefault at start.
 const DefaultUser = "user"
 
+// Read-only once envd starts serving, so it is not guarded.
+var defaultUser = DefaultUser
+
+// The user of the processes (and the owner of the files) when
+// the request does not specify one.
+func Default() string {
+	return defaultUser
+}
+
+// Change the default user (a name or a numeric uid), which fails
+// if it cannot be resolved, so that it surfaces at start instead of
+// in each request.
+func SetDefault(name string) error {
+	if name == "" {
+		return errors.New("the default user cannot be empty")
+	}
+	if _, _, _, _, err := GetUser(name); err != nil {
+		return err
+	}
+	defaultUser = name
+	return nil
+}
+
+// Resolve the user by name, or by uid if there is no user of the name and
+// it is numeric (e.g., "1000"). Empty means the current user.
 func GetUser(name string) (uid, gid int64, homedir, username string, err error) {
 	var u *user.User
 
@@ -18,6 +45,9 @@ func GetUser(name string) (uid, gid int64, homedir, username string, err error)
 		}
 	} else {
 		u, err = user.Lookup(name)
+		if _, numeric := strconv.ParseUint(name, 10, 32); err != nil && numeric == nil {
+			u, err = user.LookupId(name)
+		}
 		if err != nil {
 			return uid, gid, homedir, username, fmt.Errorf("failed to lookup user '%s': %w", name, err)
 		}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestGetUserByUID(t *testing.T) {
+	uid, gid, homedir, username, err := GetUser("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byUID, byGID, byHomedir, byUsername, err := GetUser(strconv.Itoa(os.Getuid()))
+	if err != nil {
+		t.Fatalf("lookup by uid failed: %s", err)
+	}
+	if byUID != uid || byGID != gid || byHomedir != homedir || byUsername != username {
+		t.Fatalf("expect user %s (%d:%d), got %s (%d:%d)", username, uid, gid, byUsername, byUID, byGID)
+	}
+}
+
+func TestSetDefault(t *testing.T) {
+	defer func() { defaultUser = DefaultUser }()
+
+	if err := SetDefault("no-such-user-of-envd"); err == nil {
+		t.Fatal("expect error for unknown user")
+	}
+	if err := SetDefault(""); err == nil {
+		t.Fatal("expect error for empty user")
+	}
+	if Default() != DefaultUser {
+		t.Fatalf("expect the default user unchanged, got %s", Default())
+	}
+	uid := strconv.Itoa(os.Getuid())
+	if err := SetDefault(uid); err != nil {
+		t.Fatalf("set default user failed: %s", err)
+	}
+	if Default() != uid {
+		t.Fatalf("expect the default user %s, got %s", uid, Default())
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
@@ -19,11 +20,14 @@ import (
 	"github.com/e2b-dev/infra/packages/envd/internal/env"
 	"github.com/e2b-dev/infra/packages/envd/internal/file"
 	"github.com/e2b-dev/infra/packages/envd/internal/filesystem"
+	"github.com/e2b-dev/infra/packages/envd/internal/log/exporter"
 	"github.com/e2b-dev/infra/packages/envd/internal/monitor"
+	"github.com/e2b-dev/infra/packages/envd/internal/netif"
 	"github.com/e2b-dev/infra/packages/envd/internal/port"
 	"github.com/e2b-dev/infra/packages/envd/internal/ports"
 	"github.com/e2b-dev/infra/packages/envd/internal/process"
 	"github.com/e2b-dev/infra/packages/envd/internal/terminal"
+	"github.com/e2b-dev/infra/packages/envd/internal/user"
 	"github.com/e2b-dev/infra/packages/shared/pkg/consts"
 )
 
@@ -45,6 +49,17 @@ var (
 	serverPort   int64
 	versionFlag  bool
 	startCmdFlag string
+
+	processOutputLimit int
+	processOutputMode  string
+	processMax         int
+	processOutputTTL   time.Duration
+
+	logBufferSize int64
+
+	processRlimitCeilings string
+
+	defaultUser string
 )
 
 func serveWs(w http.ResponseWriter, r *http.Request) {
@@ -52,15 +67,49 @@ func serveWs(w http.ResponseWriter, r *http.Request) {
 	wsHandler.ServeHTTP(w, r)
 }
 
-func syncHandler(clock *clock.Service) http.HandlerFunc {
+// The /sync is requested by orchestrator after each restore,
+// so also reload the process defaults of the (new) sandbox here.
+func syncHandler(clock *clock.Service, simpleProcessManager *process.SimpleProcessManager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		logger.Debug("/sync request")
-		clock.Sync()
+		if err := clock.Sync(); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := simpleProcessManager.ReloadDefaults(); err != nil {
+			logger.Debugw("Failed to reload process defaults from mmds", "error", err)
+		}
 
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
+type macRequest struct {
+	Iface string `json:"iface"`
+	Mac   string `json:"mac"`
+}
+
+// The /net/mac is requested by orchestrator after restore, when the sandbox
+// needs a mac address different from the one of template.
+func macHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	var req macRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+		return
+	}
+	logger.Debugw("/net/mac request", "iface", req.Iface, "mac", req.Mac)
+	if err := netif.SetMAC(req.Iface, req.Mac); err != nil {
+		logger.Errorw("Failed to set mac address", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
 func pingHandler(w http.ResponseWriter, r *http.Request) {
 	logger.Debug("/ping request")
 	w.WriteHeader(http.StatusOK)
@@ -71,6 +120,20 @@ func pingHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func pendingLogsHandler(logsExporter *exporter.HTTPLogsExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(logsExporter.PendingLogs()); err != nil {
+			logger.Errorw("Error writing response", "error", err)
+		}
+	}
+}
+
 func fileHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
@@ -111,10 +174,77 @@ func parseFlags() {
 		"a command to run on the daemon start",
 	)
 
+	flag.IntVar(
+		&processOutputLimit,
+		"process-output-limit",
+		process.DefaultMaxBufferedOutputSize,
+		"max bytes of stdout (and stderr) buffered for each simple process, <= 0 means no limit",
+	)
+
+	flag.StringVar(
+		&processOutputMode,
+		"process-output-mode",
+		string(process.OutputBufferTruncate),
+		"how to handle the simple process output exceeding the limit: truncate (keep the head) or ring (keep the tail)",
+	)
+
+	flag.IntVar(
+		&processMax,
+		"process-max",
+		process.DefaultMaxProcesses,
+		"max simple processes tracked (until their output is collected), creating more fails with 429, <= 0 means no limit",
+	)
+
+	flag.DurationVar(
+		&processOutputTTL,
+		"process-output-ttl",
+		process.DefaultOutputTTL,
+		"how long the output of an exited simple process is kept if never collected by wait or stream, 0 means forever",
+	)
+
+	flag.Int64Var(
+		&logBufferSize,
+		"log-buffer-size",
+		exporter.DefaultMaxDiskBufferSize,
+		"max bytes of logs persisted on disk when the log collector is unreachable",
+	)
+
+	flag.StringVar(
+		&processRlimitCeilings,
+		"process-rlimit-ceilings",
+		"",
+		"the max rlimits a simple process can request, in the format of name=soft[:hard],... (e.g., nofile=4096,cpu=3600)",
+	)
+
+	flag.StringVar(
+		&defaultUser,
+		"default-user",
+		defaultUserFromEnv(),
+		"the user (name or uid) of processes and files when the request does not specify one, by default $"+defaultUserEnv+" or \""+user.DefaultUser+"\"",
+	)
+
 	flag.Parse()
 }
 
+// The env var overriding the compiled default user, for the templates whose
+// primary user is not the default (e.g., ubuntu or jovyan).
+const defaultUserEnv = "ENVD_DEFAULT_USER"
+
+func defaultUserFromEnv() string {
+	if name := os.Getenv(defaultUserEnv); name != "" {
+		return name
+	}
+	return user.DefaultUser
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == process.RlimitExecCommand {
+		if err := process.RlimitExec(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed: %v\n", process.RlimitExecCommand, err)
+			os.Exit(1)
+		}
+	}
+
 	parseFlags()
 
 	if versionFlag {
@@ -123,7 +253,7 @@ func main() {
 		return
 	}
 
-	envConfig, l, err := env.NewEnv(debug)
+	envConfig, l, err := env.NewEnv(debug, logBufferSize)
 	if err != nil {
 		panic(err)
 	}
@@ -174,11 +304,30 @@ func main() {
 		logger.Panicw("failed to register process service", "error", err)
 	}
 
-	simpleProcessManager := process.NewSimpleProcessManager(logger)
+	outputMode, err := process.ParseOutputBufferMode(processOutputMode)
+	if err != nil {
+		logger.Panicw("invalid process output mode", "error", err)
+	}
+	if err := user.SetDefault(defaultUser); err != nil {
+		logger.Panicw("invalid default user", "error", err)
+	}
+	rlimitCeilings, err := process.ParseRlimits(processRlimitCeilings)
+	if err != nil {
+		logger.Panicw("invalid process rlimit ceilings", "error", err)
+	}
+	simpleProcessManager := process.NewSimpleProcessManager(logger.Named("simpleProcess"), processOutputLimit, outputMode, rlimitCeilings, processMax)
+	go simpleProcessManager.RunSweeper(processOutputTTL)
 
 	reg := prometheus.NewRegistry()
 	monitor := monitor.NewService(logger.Named("systemMonitor"))
 	reg.MustRegister(monitor)
+	reg.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "envd_process_buffered_output_bytes",
+			Help: "The total size of output buffered by simple processes.",
+		},
+		func() float64 { return float64(simpleProcessManager.BufferedOutputSize()) },
+	))
 
 	// Start the command passed via the -cmd flag.
 	if startCmdFlag != "" {
@@ -202,20 +351,27 @@ func main() {
 	router := mux.NewRouter()
 	wsHandler = rpcServer.WebsocketHandler([]string{"*"})
 
-	clockHandler := syncHandler(clock)
+	clockHandler := syncHandler(clock, simpleProcessManager)
 	// The /sync route is used for syncing the clock.
 	router.HandleFunc("/sync", clockHandler)
+	// The /net/mac route is used for changing the mac address of guest.
+	router.HandleFunc("/net/mac", macHandler)
 
 	router.HandleFunc("/ws", serveWs)
 	// The /ping route is used for the terminal extension to check if envd is running.
 	router.HandleFunc("/ping", pingHandler)
+
+	router.HandleFunc("/logs/pending", pendingLogsHandler(envConfig.LogsExporter))
 	// Register the profiling handlers that were added in default mux with the `net/http/pprof` import.
 	router.PathPrefix("/debug/pprof").Handler(http.DefaultServeMux)
 	// The /file route used for downloading and uploading files via SDK.
 	router.HandleFunc("/file", fileHandler)
 	router.HandleFunc("/process/create", simpleProcessManager.Create)
 	router.HandleFunc("/process/wait", simpleProcessManager.Wait)
+	router.HandleFunc("/process/stream", simpleProcessManager.Stream)
+	router.HandleFunc("/process/list", simpleProcessManager.List)
 	router.HandleFunc("/process/kill", simpleProcessManager.Kill)
+	router.HandleFunc("/process/signal", simpleProcessManager.Signal)
 	// The /metric route used to monitor the system load inside VM
 	router.HandleFunc("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{
 		Registry: reg,
@@ -5,12 +5,12 @@ go 1.23
 toolchain go1.23.1
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/X-code-interpreter/sandbox-backend/packages/shared v0.0.0
 	go.uber.org/zap v1.27.0
 )
 
 require (
-	github.com/BurntSushi/toml v1.5.0 // indirect
 	github.com/cenkalti/backoff/v4 v4.3.0 // indirect
 	github.com/go-logr/logr v1.4.1 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
@@ -41,10 +41,14 @@ func main() {
 	c := logcollector.NewLogCollector(cfg)
 	r := http.NewServeMux()
 	r.HandleFunc("/", c.EnvdLogHandler)
+	r.HandleFunc("/logs", c.LogsHandler)
+	r.HandleFunc("/logs/sandboxes", c.ListSandboxesHandler)
 	srv := http.Server{
 		Addr:    fmt.Sprintf(":%d", consts.DefaultLogCollectorPort),
 		Handler: r,
 	}
+	srv.RegisterOnShutdown(c.Shutdown)
+	go c.RunSweep()
 	go func() {
 		if err := srv.ListenAndServe(); err != nil {
 			zap.L().Error("listen and server failed", zap.Error(err))
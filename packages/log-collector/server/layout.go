@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// The logs are stored in LogDir/${TeamID}/${EnvID}/${SandboxID}.log, so that
+// they can be found by team or template, where the first log of a sandbox
+// decides the file of its following ones. The ones cannot be parsed (or
+// labeled) are stored in LogDir/_raw/raw.log instead.
+const (
+	rawBucket  = "_raw"
+	rawLogName = "raw.log"
+	// the team (or template) of the logs without one
+	unknownLabel = "_unknown"
+)
+
+var ErrInvalidLabel = errors.New("invalid label")
+
+// A label (i.e., the id of sandbox, team or template) is used as the name
+// of file or dir, so it should not escape LogDir or be a glob pattern. The
+// ones starting with _ are reserved (e.g., rawBucket).
+func validateLabel(label string) error {
+	if label == "" || strings.HasPrefix(label, "_") || strings.HasPrefix(label, ".") ||
+		filepath.Base(label) != label || strings.ContainsAny(label, `*?[\`) {
+		return fmt.Errorf("%w: %q", ErrInvalidLabel, label)
+	}
+	return nil
+}
+
+func orUnknown(label string) string {
+	if label == "" {
+		return unknownLabel
+	}
+	return label
+}
+
+func (c *LogCollector) logPath(meta *LogMeta) (string, error) {
+	if err := validateLabel(meta.SandboxID); err != nil {
+		return "", err
+	}
+	team, template := orUnknown(meta.TeamID), orUnknown(meta.EnvID)
+	for _, label := range []string{team, template} {
+		if label == unknownLabel {
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(c.cfg.LogDir(), team, template, meta.SandboxID+".log"), nil
+}
+
+func (c *LogCollector) rawLogPath() string {
+	return filepath.Join(c.cfg.LogDir(), rawBucket, rawLogName)
+}
+
+// The pattern matches the log files of the sandbox in team and template,
+// where the empty one matches all.
+func (c *LogCollector) logPattern(sandboxID, team, template string) (string, error) {
+	labels := []string{team, template, sandboxID}
+	for i, label := range labels {
+		if label == "" {
+			labels[i] = "*"
+			continue
+		}
+		if label == unknownLabel && i < 2 {
+			continue
+		}
+		if err := validateLabel(label); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(c.cfg.LogDir(), labels[0], labels[1], labels[2]+".log"), nil
+}
+
+// Find the log file of sandbox, optionally restricted to team and template.
+func (c *LogCollector) findLogPath(sandboxID, team, template string) (string, error) {
+	if sandboxID == "" {
+		return "", fmt.Errorf("%w: sandbox is required", ErrInvalidLabel)
+	}
+	pattern, err := c.logPattern(sandboxID, team, template)
+	if err != nil {
+		return "", err
+	}
+	matches, _ := filepath.Glob(pattern)
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%w: no logs of sandbox %s", fs.ErrNotExist, sandboxID)
+	}
+	return matches[0], nil
+}
+
+// List the sandboxes which have logs, e.g., GET /logs/sandboxes?team=ID&template=ID,
+// where team and template are optional.
+func (c *LogCollector) ListSandboxesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only allow get", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	pattern, err := c.logPattern("", query.Get("team"), query.Get("template"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	matches, _ := filepath.Glob(pattern)
+	sandboxes := make([]LogMeta, 0, len(matches))
+	for _, path := range matches {
+		dir, file := filepath.Split(path)
+		template := filepath.Base(dir)
+		team := filepath.Base(filepath.Dir(filepath.Clean(dir)))
+		sandboxes = append(sandboxes, LogMeta{
+			SandboxID: strings.TrimSuffix(file, ".log"),
+			EnvID:     template,
+			TeamID:    team,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sandboxes)
+}
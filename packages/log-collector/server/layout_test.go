@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnvdLogHandlerLabels(t *testing.T) {
+	c := NewLogCollector(&LogCollectorConfig{DataRoot: t.TempDir()})
+	defer c.Shutdown()
+	testCases := []struct {
+		body string
+		path string
+		line string
+	}{
+		{
+			body: "{\"sandboxID\": \"sandbox\",\n\"envID\": \"template\", \"teamID\": \"team\"}",
+			path: "team/template/sandbox.log",
+			line: `{"sandboxID":"sandbox","envID":"template","teamID":"team"}`,
+		},
+		{
+			body: `{"sandboxID": "unlabeled"}`,
+			path: filepath.Join(unknownLabel, unknownLabel, "unlabeled.log"),
+			line: `{"sandboxID":"unlabeled"}`,
+		},
+		{
+			body: "not\njson",
+			path: filepath.Join(rawBucket, rawLogName),
+			line: `not\njson`,
+		},
+		{
+			body: `{"sandboxID": "../sandbox"}`,
+			path: filepath.Join(rawBucket, rawLogName),
+			line: `{"sandboxID": "../sandbox"}`,
+		},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		c.EnvdLogHandler(w, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("post %q: expect ok, got %d", tc.body, w.Code)
+		}
+		b, err := os.ReadFile(filepath.Join(c.cfg.LogDir(), tc.path))
+		if err != nil {
+			t.Fatalf("post %q: %s", tc.body, err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+		if last := lines[len(lines)-1]; last != tc.line {
+			t.Fatalf("post %q: expect line %s, got %s", tc.body, tc.line, last)
+		}
+	}
+}
+
+func TestListSandboxesHandler(t *testing.T) {
+	c := NewLogCollector(&LogCollectorConfig{DataRoot: t.TempDir()})
+	for _, meta := range []LogMeta{
+		{SandboxID: "a", TeamID: "team1", EnvID: "template1"},
+		{SandboxID: "b", TeamID: "team1", EnvID: "template2"},
+		{SandboxID: "c", TeamID: "team2", EnvID: "template1"},
+	} {
+		path, err := c.logPath(&meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testCases := map[string][]string{
+		"":                              {"a", "b", "c"},
+		"team=team1":                    {"a", "b"},
+		"template=template1":            {"a", "c"},
+		"team=team2&template=template2": {},
+	}
+	for query, expect := range testCases {
+		w := httptest.NewRecorder()
+		c.ListSandboxesHandler(w, httptest.NewRequest(http.MethodGet, "/logs/sandboxes?"+query, nil))
+		var sandboxes []LogMeta
+		if err := json.NewDecoder(w.Body).Decode(&sandboxes); err != nil {
+			t.Fatalf("%s: decode failed: %s", query, err)
+		}
+		var got []string
+		for _, sbx := range sandboxes {
+			got = append(got, sbx.SandboxID)
+		}
+		if strings.Join(got, ",") != strings.Join(expect, ",") {
+			t.Fatalf("%s: expect %v, got %v", query, expect, got)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTailLines = 100
+	// bound the lines returned once, as they are buffered in memory
+	maxTailLines = 10000
+	// the size read each time when seeking the lines from the end of file
+	tailChunkSize = 64 << 10
+	// how often to check the new lines when following
+	followInterval = 500 * time.Millisecond
+)
+
+// Return the last N lines of envd logs of a sandbox, e.g.,
+// GET /logs?sandbox=ID&tail=N&follow=true
+//
+// The sandbox can be restricted to a team or template by team=ID or template=ID.
+//
+// The tail defaults to defaultTailLines and is bounded by maxTailLines, the
+// lines are read from the rotated (and compressed) files if not enough.
+// When follow is true, the new lines are streamed until the client goes
+// away or the log collector shuts down.
+func (c *LogCollector) LogsHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only allow get", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	path, err := c.findLogPath(query.Get("sandbox"), query.Get("team"), query.Get("template"))
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, fs.ErrNotExist) {
+			code = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	tail := defaultTailLines
+	if s := query.Get("tail"); s != "" {
+		tail, err = strconv.Atoi(s)
+		if err != nil || tail < 0 {
+			http.Error(w, fmt.Sprintf("invalid tail %q", s), http.StatusBadRequest)
+			return
+		}
+		tail = min(tail, maxTailLines)
+	}
+	var follow bool
+	if s := query.Get("follow"); s != "" {
+		follow, err = strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid follow %q", s), http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// removed by the sweep after found
+			http.Error(w, fmt.Sprintf("no logs of sandbox %s", query.Get("sandbox")), http.StatusNotFound)
+			return
+		}
+		errMsg := fmt.Errorf("error while open log file: %w", err)
+		zap.L().Error("", zap.Error(errMsg))
+		http.Error(w, errMsg.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	lines, offset, err := tailLines(file, tail)
+	if err == nil {
+		lines, err = c.tailBackups(path, lines, tail)
+	}
+	if err != nil {
+		errMsg := fmt.Errorf("error while read log file: %w", err)
+		zap.L().Error("", zap.Error(errMsg))
+		http.Error(w, errMsg.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write(lines); err != nil || !follow {
+		return
+	}
+	if err := c.follow(w, r, path, file, offset); err != nil {
+		zap.L().Error("follow log file failed", zap.Error(err), zap.String("sandbox-id", query.Get("sandbox")))
+	}
+}
+
+// Stream the content appended after offset of file (opened from path) into w.
+// When the file is rotated, it continues with the new one at path.
+func (c *LogCollector) follow(w http.ResponseWriter, r *http.Request, path string, file *os.File, offset int64) error {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("streaming is not supported by %T", w)
+	}
+	flusher.Flush()
+	// file is closed by the caller, while current is closed here if rotated
+	current := file
+	defer func() {
+		if current != file {
+			current.Close()
+		}
+	}()
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-c.done:
+			return nil
+		case <-ticker.C:
+		}
+		// check before stat the current file, so that no more content
+		// will be appended into it after the size is got if rotated.
+		latest, latestErr := os.Stat(path)
+		info, err := current.Stat()
+		if err != nil {
+			return err
+		}
+		if info.Size() < offset {
+			// truncated, start over
+			offset = 0
+		}
+		if info.Size() > offset {
+			n, err := io.Copy(w, io.NewSectionReader(current, offset, info.Size()-offset))
+			offset += n
+			if err != nil {
+				// mostly the client has gone
+				return nil
+			}
+			flusher.Flush()
+		}
+		if latestErr != nil || os.SameFile(info, latest) {
+			continue
+		}
+		next, err := os.Open(path)
+		if err != nil {
+			// try again in next round
+			continue
+		}
+		if current != file {
+			current.Close()
+		}
+		current, offset = next, 0
+	}
+}
+
+// Prepend the lines from the rotated files of path (newest first) to lines,
+// until there are n lines or no more rotated files. It is the best effort, as
+// the file may be rotated concurrently.
+func (c *LogCollector) tailBackups(path string, lines []byte, n int) ([]byte, error) {
+	for i := 1; i <= c.cfg.MaxBackups; i++ {
+		// every line ends with a line break (see appendLog)
+		remain := n - bytes.Count(lines, []byte("\n"))
+		if remain <= 0 {
+			break
+		}
+		older, err := tailBackup(utils.RotatedPathN(path, i), remain)
+		if errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		lines = append(older, lines...)
+	}
+	return lines, nil
+}
+
+// Read the last n lines of the rotated file at path, or path.gz
+// if it has been compressed.
+func tailBackup(path string, n int) ([]byte, error) {
+	file, err := os.Open(path)
+	if err == nil {
+		defer file.Close()
+		lines, _, err := tailLines(file, n)
+		return lines, err
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	file, err = os.Open(utils.CompressedPath(path))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return tailGzipLines(file, n)
+}
+
+// The gzip stream cannot be read from the end, so it is decompressed from
+// the beginning, while only the last n lines are kept.
+func tailGzipLines(r io.Reader, n int) ([]byte, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	reader := bufio.NewReader(gr)
+	// a ring of the last n lines, where next is the oldest one once it is full
+	var (
+		lines [][]byte
+		next  int
+	)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if len(lines) < n {
+				lines = append(lines, line)
+			} else {
+				lines[next] = line
+				next = (next + 1) % n
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return bytes.Join(slices.Concat(lines[next:], lines[:next]), nil), nil
+}
+
+// Read the last n lines of file by seeking from its end, so that only
+// the tail (instead of the whole file) is read. It also returns the end
+// offset of the returned lines, where the following starts from.
+func tailLines(file *os.File, n int) ([]byte, int64, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, 0, err
+	}
+	end := info.Size()
+	if n == 0 {
+		return nil, end, nil
+	}
+	var buf []byte
+	for start := end; start > 0; {
+		size := min(tailChunkSize, start)
+		start -= size
+		chunk := make([]byte, size)
+		if _, err := file.ReadAt(chunk, start); err != nil {
+			return nil, 0, err
+		}
+		buf = append(chunk, buf...)
+		if idx := lastLinesStart(buf, n); idx >= 0 {
+			return buf[idx:], end, nil
+		}
+	}
+	// the whole file has less than n lines
+	return buf, end, nil
+}
+
+// The index where the last n lines of buf start, or -1 if buf does not
+// contain the beginning of them.
+func lastLinesStart(buf []byte, n int) int {
+	// the line break at the end terminates the last line instead
+	// of starting a new one
+	body := bytes.TrimSuffix(buf, []byte("\n"))
+	for i := len(body); ; n-- {
+		i = bytes.LastIndexByte(body[:i], '\n')
+		if i < 0 {
+			return -1
+		}
+		if n == 1 {
+			return i + 1
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+func TestTailLines(t *testing.T) {
+	testCases := []struct {
+		content string
+		n       int
+		expect  string
+	}{
+		{"a\nb\nc\n", 2, "b\nc\n"},
+		{"a\nb\nc", 2, "b\nc"},
+		{"a\nb\nc\n", 3, "a\nb\nc\n"},
+		{"a\nb\nc\n", 10, "a\nb\nc\n"},
+		{"a\nb\nc\n", 0, ""},
+		{"", 10, ""},
+		// span multiple chunks
+		{strings.Repeat("x", tailChunkSize) + "\n" + strings.Repeat("y", tailChunkSize) + "\nz\n", 2, strings.Repeat("y", tailChunkSize) + "\nz\n"},
+	}
+	for _, tc := range testCases {
+		path := filepath.Join(t.TempDir(), "test.log")
+		if err := os.WriteFile(path, []byte(tc.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines, offset, err := tailLines(file, tc.n)
+		file.Close()
+		if err != nil {
+			t.Fatalf("tail lines failed: %s", err)
+		}
+		if string(lines) != tc.expect {
+			t.Fatalf("tail %d lines of %.16q: expect %.16q, got %.16q", tc.n, tc.content, tc.expect, lines)
+		}
+		if offset != int64(len(tc.content)) {
+			t.Fatalf("expect offset %d, got %d", len(tc.content), offset)
+		}
+	}
+}
+
+func TestLogsHandler(t *testing.T) {
+	cfg := &LogCollectorConfig{DataRoot: t.TempDir()}
+	if err := os.MkdirAll(filepath.Join(cfg.LogDir(), "team", "template"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.LogDir(), "team", "template", "sandbox.log"), []byte("a\nb\nc\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewLogCollector(cfg)
+	testCases := []struct {
+		query  string
+		code   int
+		expect string
+	}{
+		{"sandbox=sandbox&tail=1", http.StatusOK, "c\n"},
+		{"sandbox=sandbox", http.StatusOK, "a\nb\nc\n"},
+		{"sandbox=unknown", http.StatusNotFound, ""},
+		{"sandbox=sandbox&team=team&template=template&tail=1", http.StatusOK, "c\n"},
+		{"sandbox=sandbox&team=other", http.StatusNotFound, ""},
+		{"sandbox=sandbox&template=*", http.StatusBadRequest, ""},
+		{"tail=1", http.StatusBadRequest, ""},
+		{"sandbox=../sandbox", http.StatusBadRequest, ""},
+		{"sandbox=sandbox&tail=-1", http.StatusBadRequest, ""},
+	}
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		c.LogsHandler(w, httptest.NewRequest(http.MethodGet, "/logs?"+tc.query, nil))
+		if w.Code != tc.code {
+			t.Fatalf("%s: expect code %d, got %d", tc.query, tc.code, w.Code)
+		}
+		if tc.code == http.StatusOK && w.Body.String() != tc.expect {
+			t.Fatalf("%s: expect %q, got %q", tc.query, tc.expect, w.Body.String())
+		}
+	}
+}
+
+func TestTailBackups(t *testing.T) {
+	cfg := &LogCollectorConfig{DataRoot: t.TempDir(), MaxBackups: 3}
+	c := NewLogCollector(cfg)
+	path, err := c.logPath(&LogMeta{SandboxID: "sandbox"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("e\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte("b\nc\nd\n"))
+	w.Close()
+	if err := os.WriteFile(utils.CompressedPath(utils.RotatedPathN(path, 1)), buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(utils.RotatedPathN(path, 2), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for tail, expect := range map[int]string{1: "e\n", 3: "c\nd\ne\n", 5: "a\nb\nc\nd\ne\n", 10: "a\nb\nc\nd\ne\n"} {
+		w := httptest.NewRecorder()
+		c.LogsHandler(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/logs?sandbox=sandbox&tail=%d", tail), nil))
+		if w.Code != http.StatusOK || w.Body.String() != expect {
+			t.Fatalf("tail %d: expect %q, got %d %q", tail, expect, w.Code, w.Body.String())
+		}
+	}
+}
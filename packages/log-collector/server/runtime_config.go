@@ -1,16 +1,36 @@
 package server
 
 import (
+	"compress/gzip"
+	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+const (
+	DefaultMaxFileSizeMB = 100
+	DefaultMaxBackups    = 3
+	DefaultMaxAgeHours   = 7 * 24
 )
 
 type LogCollectorConfig struct {
-	Port     int    `toml:"port"`
-	DataRoot string `toml:"_"`
+	Port int `toml:"port"`
+	// the log file of sandbox is rotated (to .1, .2, ...) once it exceeds
+	// max_file_size_mb, and at most max_backups rotated files are kept
+	MaxFileSizeMB int64 `toml:"max_file_size_mb"`
+	MaxBackups    int   `toml:"max_backups"`
+	// the rotated files older than max_age_hours are removed by the sweep
+	MaxAgeHours int `toml:"max_age_hours"`
+	// gzip the rotated files (in background) with compress_level,
+	// which is 1 (best speed) to 9 (best compression), 0 means default
+	Compress      bool   `toml:"compress"`
+	CompressLevel int    `toml:"compress_level"`
+	DataRoot      string `toml:"_"`
 }
 
 func ParseLogCollectorConfig(configFile string) (*LogCollectorConfig, error) {
@@ -39,9 +59,37 @@ func ParseLogCollectorConfig(configFile string) (*LogCollectorConfig, error) {
 	if cfg.Port == 0 {
 		cfg.Port = consts.DefaultLogCollectorPort
 	}
+	if cfg.MaxFileSizeMB == 0 {
+		cfg.MaxFileSizeMB = DefaultMaxFileSizeMB
+	}
+	if cfg.MaxBackups == 0 {
+		cfg.MaxBackups = DefaultMaxBackups
+	}
+	if cfg.MaxAgeHours == 0 {
+		cfg.MaxAgeHours = DefaultMaxAgeHours
+	}
+	if cfg.MaxFileSizeMB < 0 || cfg.MaxBackups < 0 || cfg.MaxAgeHours < 0 {
+		return nil, fmt.Errorf("max_file_size_mb, max_backups and max_age_hours of log_collector cannot be negative")
+	}
+	if cfg.CompressLevel < 0 || cfg.CompressLevel > gzip.BestCompression {
+		return nil, fmt.Errorf("compress_level of log_collector should be in [0, %d]", gzip.BestCompression)
+	}
 	return &cfg, nil
 }
 
+func (cfg *LogCollectorConfig) maxAge() time.Duration {
+	return time.Duration(cfg.MaxAgeHours) * time.Hour
+}
+
 func (cfg *LogCollectorConfig) LogDir() string {
 	return filepath.Join(cfg.DataRoot, consts.EnvdLogDirName)
 }
+
+func (cfg *LogCollectorConfig) rotateOptions() utils.RotateOptions {
+	return utils.RotateOptions{
+		MaxSize:       cfg.MaxFileSizeMB << 20,
+		MaxBackups:    cfg.MaxBackups,
+		Compress:      cfg.Compress,
+		CompressLevel: cfg.CompressLevel,
+	}
+}
@@ -1,18 +1,22 @@
 package server
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 	"go.uber.org/zap"
 )
 
 type LogMeta struct {
-	TraceID   string `json:"traceID"`
+	TraceID   string `json:"traceID,omitempty"`
 	SandboxID string `json:"sandboxID"`
 	EnvID     string `json:"envID"`
 	TeamID    string `json:"teamID"`
@@ -20,10 +24,79 @@ type LogMeta struct {
 
 type LogCollector struct {
 	cfg *LogCollectorConfig
+	// closed when shutting down, to stop the following requests and the sweep
+	done chan struct{}
+
+	mu sync.Mutex
+	// the opened log files (sandbox id -> file), which are shared by the
+	// concurrent requests of the same sandbox
+	files map[string]*utils.RotatingFile
 }
 
 func NewLogCollector(cfg *LogCollectorConfig) *LogCollector {
-	return &LogCollector{cfg: cfg}
+	return &LogCollector{
+		cfg:   cfg,
+		done:  make(chan struct{}),
+		files: make(map[string]*utils.RotatingFile),
+	}
+}
+
+// Stop following the logs and the sweep, so that the server can shutdown
+// gracefully without waiting for the clients.
+func (c *LogCollector) Shutdown() {
+	close(c.done)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for sandboxID, f := range c.files {
+		f.Close()
+		delete(c.files, sandboxID)
+	}
+}
+
+// The log file at path (of sandbox, or rawBucket), which is opened on the
+// first write and kept opened until the sweep finds the sandbox has gone.
+func (c *LogCollector) logFile(key, path string) (*utils.RotatingFile, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f, ok := c.files[key]; ok {
+		return f, nil
+	}
+	if err := utils.CreateDirAllIfNotExists(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := utils.OpenRotatingFileWithOptions(path, c.cfg.rotateOptions())
+	if err != nil {
+		return nil, err
+	}
+	c.files[key] = f
+	return f, nil
+}
+
+func (c *LogCollector) closeLogFile(sandboxID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if f, ok := c.files[sandboxID]; ok {
+		f.Close()
+		delete(c.files, sandboxID)
+	}
+}
+
+// Append the line into the log file at path (see logFile). The line is written
+// at once, so it will not be interleaved with others or split by the rotation.
+func (c *LogCollector) appendLog(key, path string, line []byte) error {
+	f, err := c.logFile(key, path)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(line)
+	if errors.Is(err, os.ErrClosed) {
+		// closed by the sweep concurrently, open it again
+		if f, err = c.logFile(key, path); err != nil {
+			return err
+		}
+		_, err = f.Write(line)
+	}
+	return err
 }
 
 func (c *LogCollector) EnvdLogHandler(w http.ResponseWriter, r *http.Request) {
@@ -38,35 +111,27 @@ func (c *LogCollector) EnvdLogHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	var meta LogMeta
 	body, _ := io.ReadAll(r.Body)
-	err := json.Unmarshal(body, &meta)
-	if err != nil {
-		errMsg := fmt.Errorf("error while parse body: %w", err)
-		zap.L().Error("", zap.Error(errMsg))
-		http.Error(w, errMsg.Error(), http.StatusBadRequest)
-		return
-	}
-	file, err := os.OpenFile(
-		filepath.Join(c.cfg.LogDir(), meta.SandboxID+".log"),
-		os.O_APPEND|os.O_CREATE|os.O_WRONLY,
-		0644,
+	var (
+		meta LogMeta
+		line bytes.Buffer
+		key  = rawBucket
+		path = c.rawLogPath()
 	)
-	if err != nil {
-		errMsg := fmt.Errorf("error while open log file: %w", err)
-		zap.L().Error("", zap.Error(errMsg))
-		http.Error(w, errMsg.Error(), http.StatusBadRequest)
-		return
+	if err := json.Unmarshal(body, &meta); err != nil {
+		zap.L().Warn("cannot parse the log, save it as raw", zap.Error(err))
+	} else if sandboxPath, err := c.logPath(&meta); err != nil {
+		zap.L().Warn("cannot label the log, save it as raw", zap.Error(err))
+	} else {
+		key, path = meta.SandboxID, sandboxPath
 	}
-	defer file.Close()
-	if _, err = file.Write(body); err != nil {
-		errMsg := fmt.Errorf("error write log file: %w", err)
-		zap.L().Error("", zap.Error(errMsg), zap.String("sandbox-id", meta.SandboxID))
-		http.Error(w, errMsg.Error(), http.StatusBadRequest)
-		return
+	// one line per log
+	if key == rawBucket || json.Compact(&line, body) != nil {
+		line.Reset()
+		line.Write(bytes.ReplaceAll(body, []byte("\n"), []byte("\\n")))
 	}
-	// write a line break
-	if _, err = fmt.Fprint(file, "\n"); err != nil {
+	line.WriteByte('\n')
+	if err := c.appendLog(key, path, line.Bytes()); err != nil {
 		errMsg := fmt.Errorf("error write log file: %w", err)
 		zap.L().Error("", zap.Error(errMsg), zap.String("sandbox-id", meta.SandboxID))
 		http.Error(w, errMsg.Error(), http.StatusBadRequest)
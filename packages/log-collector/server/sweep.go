@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"go.uber.org/zap"
+)
+
+// how often to remove the stale log files, it is also the grace period
+// before removing the logs of a sandbox which is not found in the registry
+// of orchestrator (e.g., has not been persisted yet).
+const sweepInterval = 10 * time.Minute
+
+// Remove the stale log files periodically until Shutdown.
+func (c *LogCollector) RunSweep() {
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+		if err := c.sweep(time.Now()); err != nil {
+			zap.L().Error("sweep log files failed", zap.Error(err))
+		}
+	}
+}
+
+// Remove the log files (including the rotated and compressed ones) of the
+// sandboxes no longer exist, and the rotated files older than MaxAgeHours or
+// beyond MaxBackups (e.g., it has been lowered). The temporary files left by
+// the interrupted compressions are removed once older than MaxAgeHours.
+// The dirs of team and template are removed once empty.
+func (c *LogCollector) sweep(now time.Time) error {
+	live, err := liveSandboxes(c.cfg.DataRoot)
+	if err != nil {
+		return err
+	}
+	var dirs []string
+	err = filepath.WalkDir(c.cfg.LogDir(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// removed concurrently
+			return nil
+		}
+		if entry.IsDir() {
+			if path != c.cfg.LogDir() {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		sandboxID, suffix, ok := strings.Cut(entry.Name(), ".log")
+		if !ok {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		// the raw bucket never goes
+		gone := live != nil && !live[sandboxID] && filepath.Base(filepath.Dir(path)) != rawBucket
+		idle := now.Sub(info.ModTime())
+		var remove bool
+		switch {
+		case gone && idle > sweepInterval:
+			c.closeLogFile(sandboxID)
+			remove = true
+		case suffix == "":
+			// the log file being written
+		case idle > c.cfg.maxAge():
+			remove = true
+		default:
+			// .N or .N.gz
+			n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(suffix, "."), ".gz"))
+			remove = err == nil && n > c.cfg.MaxBackups
+		}
+		if !remove {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			zap.L().Error("remove log file failed", zap.Error(err), zap.String("sandbox-id", sandboxID))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// the deeper ones first, which fails if not empty. Hold the lock,
+	// so that it will not be removed before the log file is opened in it.
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if filepath.Base(dirs[i]) != rawBucket {
+			os.Remove(dirs[i])
+		}
+	}
+	return nil
+}
+
+// The ids of sandboxes persisted by orchestrator, nil if orchestrator does not
+// persist into the same data root, where all sandboxes are treated as live.
+func liveSandboxes(dataRoot string) (map[string]bool, error) {
+	entries, err := os.ReadDir(filepath.Join(dataRoot, consts.SandboxRegistryDirName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	live := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if sandboxID, ok := strings.CutSuffix(entry.Name(), ".json"); ok {
+			live[sandboxID] = true
+		}
+	}
+	return live, nil
+}
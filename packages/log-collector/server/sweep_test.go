@@ -0,0 +1,77 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestSweep(t *testing.T) {
+	cfg := &LogCollectorConfig{DataRoot: t.TempDir(), MaxFileSizeMB: 1, MaxBackups: 2, MaxAgeHours: 1}
+	registryDir := filepath.Join(cfg.DataRoot, consts.SandboxRegistryDirName)
+	for _, dir := range []string{cfg.LogDir(), registryDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(registryDir, "live.json"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := NewLogCollector(cfg)
+	gonePath, err := c.logPath(&LogMeta{SandboxID: "gone", TeamID: "team", EnvID: "gone-template"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.appendLog("gone", gonePath, []byte("{}\n")); err != nil {
+		t.Fatalf("append log failed: %s", err)
+	}
+
+	now := time.Now()
+	files := map[string]struct {
+		mtime  time.Time
+		remain bool
+	}{
+		"team/template/live.log":        {now.Add(-24 * time.Hour), true},
+		"team/template/live.log.1":      {now, true},
+		"team/template/live.log.2":      {now.Add(-2 * time.Hour), false},
+		"team/template/live.log.3":      {now, false},
+		"team/template/live.log.3.gz":   {now, false},
+		"team/gone-template/gone.log":   {now.Add(-time.Hour), false},
+		"team/gone-template/gone.log.1": {now.Add(-time.Hour), false},
+		// not persisted yet
+		"team/template/new.log":      {now, true},
+		rawBucket + "/" + rawLogName: {now.Add(-24 * time.Hour), true},
+	}
+	for name, f := range files {
+		path := filepath.Join(cfg.LogDir(), name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, f.mtime, f.mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.sweep(now); err != nil {
+		t.Fatalf("sweep failed: %s", err)
+	}
+	for name, f := range files {
+		_, err := os.Stat(filepath.Join(cfg.LogDir(), name))
+		if remain := err == nil; remain != f.remain {
+			t.Fatalf("expect %s remain %v, got %v", name, f.remain, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Dir(gonePath)); !os.IsNotExist(err) {
+		t.Fatalf("expect the empty template dir removed, got %v", err)
+	}
+	// reopened after the file is closed by sweep
+	if err := c.appendLog("gone", gonePath, []byte("{}\n")); err != nil {
+		t.Fatalf("append log after sweep failed: %s", err)
+	}
+	c.Shutdown()
+}
@@ -5,6 +5,17 @@ const (
 	ChBinaryName = "cloud-hypervisor"
 	// ChBinaryPath          = "/root/codes/cloud-hypervisor/target/x86_64-unknown-linux-musl/release/cloud-hypervisor"
 	PrometheusTargetsDirName = "prometheus-targets"
+	// the nginx proxy is a container of host network mode listened at port 6666
+	DefaultPrometheusProxyAddr = "host.docker.internal:6666"
+	// contains the instance dirs of the sandboxes failed to create (see keep_files_on_error)
+	FailedSandboxDirName = "failed"
+	// contains the console logs retained after the sandboxes are removed (see retain_console_log)
+	ConsoleLogsDirName = "console-logs"
+	// contains the logs and metrics of firecracker retained after the sandboxes are removed (see retain_fc_logs)
+	FcLogsDirName    = "fc-logs"
+	JailerBinaryName = "jailer"
+	// the default of jailer, which contains the chroots of firecracker (see use_jailer)
+	DefaultJailerChrootBaseDir = "/srv/jailer"
 
 	// on single host there should not be too much network
 	MaxNetworkNumber = 256 * 60
@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/server"
@@ -15,6 +16,9 @@ import (
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 )
 
+// how long to wait for the in-flight requests when shutting down
+const gracefulStopTimeout = 10 * time.Second
+
 func main() {
 	var configFile string
 
@@ -57,7 +61,18 @@ func main() {
 	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
 	sig := <-sigCh
 	logger.Sugar().Warnf("Recv signal %d, start to shutdown...", sig)
-	s.GracefulStop()
+	// the WatchEvents streams never end by themselves, so do not wait for them forever
+	stopped := make(chan struct{})
+	go func() {
+		s.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(gracefulStopTimeout):
+		logger.Sugar().Warnf("graceful stop timeout, force to stop")
+		s.Stop()
+	}
 	logger.Sugar().Warnf("start cleanup sandbox...")
 	cleanupFunc()
 }
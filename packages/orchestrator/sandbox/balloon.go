@@ -0,0 +1,61 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+var (
+	ErrBalloonDisabled    = errors.New("balloon is not enabled by the template")
+	ErrInvalidBalloonSize = errors.New("invalid balloon size")
+)
+
+// Set the target size of balloon (in MiB) of a running sandbox.
+// When inflate is true, the size should not be less than the current one
+// (and vice versa), so that the caller will not be surprised by an
+// unexpected direction.
+func (s *Sandbox) SetBalloon(ctx context.Context, tracer trace.Tracer, sizeMiB int64, inflate bool) error {
+	childCtx, childSpan := tracer.Start(ctx, "sandbox-set-balloon", trace.WithAttributes(
+		attribute.Int64("balloon.size_mib", sizeMiB),
+		attribute.Bool("balloon.inflate", inflate),
+	))
+	defer childSpan.End()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.Config.Balloon {
+		return ErrBalloonDisabled
+	}
+	if s.State != orchestrator.SandboxState_RUNNING {
+		errMsg := fmt.Errorf("error during set balloon: %w", InvalidSandboxState)
+		telemetry.ReportError(childCtx, errMsg, attribute.String("state", s.State.String()))
+		return InvalidSandboxState
+	}
+	// the guest cannot live without any memory
+	if sizeMiB < 0 || sizeMiB >= s.Config.GuestMemoryMB() {
+		return fmt.Errorf("%w: %d MiB should be in [0, %d)", ErrInvalidBalloonSize, sizeMiB, s.Config.GuestMemoryMB())
+	}
+	// read from the vmm, as the balloon may have been set before
+	// restored from a snapshot or reattached
+	currentMiB, err := s.vmm.Balloon(childCtx)
+	if err != nil {
+		errMsg := fmt.Errorf("get current balloon size failed: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+		return errMsg
+	}
+	if inflate && sizeMiB < currentMiB {
+		return fmt.Errorf("%w: inflate to %d MiB is less than current %d MiB", ErrInvalidBalloonSize, sizeMiB, currentMiB)
+	}
+	if !inflate && sizeMiB > currentMiB {
+		return fmt.Errorf("%w: deflate to %d MiB is larger than current %d MiB", ErrInvalidBalloonSize, sizeMiB, currentMiB)
+	}
+
+	return s.vmm.SetBalloon(childCtx, sizeMiB)
+}
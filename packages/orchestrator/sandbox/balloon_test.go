@@ -0,0 +1,62 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestSetBalloon(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	sbx.Config.MemoryMB = 512
+	ctx := context.Background()
+
+	if err := sbx.SetBalloon(ctx, testTracer, 128, true); !errors.Is(err, ErrBalloonDisabled) {
+		t.Fatalf("expect balloon disabled, got %v", err)
+	}
+	sbx.Config.Balloon = true
+
+	steps := []struct {
+		sizeMiB  int64
+		inflate  bool
+		expected error
+	}{
+		{-1, true, ErrInvalidBalloonSize},
+		{512, true, ErrInvalidBalloonSize},
+		{128, true, nil},
+		{64, true, ErrInvalidBalloonSize},
+		{256, false, ErrInvalidBalloonSize},
+		{64, false, nil},
+		{64, true, nil},
+		{0, false, nil},
+	}
+	for _, step := range steps {
+		prev := h.balloonMiB
+		err := sbx.SetBalloon(ctx, testTracer, step.sizeMiB, step.inflate)
+		if !errors.Is(err, step.expected) {
+			t.Fatalf("set balloon to %d (inflate: %v) from %d: expect %v, got %v", step.sizeMiB, step.inflate, prev, step.expected, err)
+		}
+		if err == nil && h.balloonMiB != step.sizeMiB {
+			t.Fatalf("expect balloon %d MiB, got %d", step.sizeMiB, h.balloonMiB)
+		}
+		if err != nil && h.balloonMiB != prev {
+			t.Fatalf("balloon should not change on error, got %d", h.balloonMiB)
+		}
+	}
+
+	// the balloon set before (e.g., in the snapshot restored from) is respected
+	h.balloonMiB = 256
+	if err := sbx.SetBalloon(ctx, testTracer, 128, true); !errors.Is(err, ErrInvalidBalloonSize) {
+		t.Fatalf("expect inflating to less than the current balloon rejected, got %v", err)
+	}
+	if err := sbx.SetBalloon(ctx, testTracer, 128, false); err != nil {
+		t.Fatalf("deflate balloon failed: %s", err)
+	}
+
+	sbx.State = orchestrator.SandboxState_STOP
+	if err := sbx.SetBalloon(ctx, testTracer, 0, false); !errors.Is(err, InvalidSandboxState) {
+		t.Fatalf("expect invalid state, got %v", err)
+	}
+}
@@ -0,0 +1,307 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+const (
+	// the default period of cpu.max, in microseconds
+	cgroupCpuPeriod = 100000
+	// extra cpu (in percentage of one core) for the vmm threads besides vcpus
+	cgroupCpuHeadroom = 100
+	// extra memory for the vmm process and page cache besides guest memory
+	cgroupMemoryHeadroomMB = 128
+)
+
+var (
+	ErrInvalidCgroupLimit   = errors.New("invalid cgroup limit")
+	ErrInvalidCgroupSubpath = errors.New("invalid cgroup subpath")
+)
+
+// Only letters, digits, '-' and '_', so that a component of cgroup subpath
+// never collides with the interface files (e.g., cpu.max) of cgroup.
+var cgroupSubpathComponentRegExp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// The intermediate cgroups created for the cgroup subpath of sandboxes (which
+// are removed once no sandbox is under them), the ones existing before
+// (e.g., created by the operator with the limits of tenant) are never removed.
+// The lock also serializes creating and removing them, so that a cgroup
+// is not removed while another sandbox is being created under it.
+var cgroupSubtree = struct {
+	sync.Mutex
+	created map[string]struct{}
+}{created: make(map[string]struct{})}
+
+// Validate the subtree (relative to the parent cgroup of all sandboxes) which
+// the cgroup of sandbox is placed under, e.g., "team-a" or "team-a/project-b".
+func ValidateCgroupSubpath(subpath string) error {
+	if subpath == "" {
+		return nil
+	}
+	for _, name := range strings.Split(subpath, "/") {
+		if !cgroupSubpathComponentRegExp.MatchString(name) {
+			return fmt.Errorf("%w: %q, each component should only contain letters, digits, '-' and '_'", ErrInvalidCgroupSubpath, subpath)
+		}
+	}
+	return nil
+}
+
+// Enable all available controllers of the cgroup for its children.
+func EnableCgroupControllers(path string) error {
+	b, err := os.ReadFile(filepath.Join(path, "cgroup.controllers"))
+	if err != nil {
+		return fmt.Errorf("read cgroup.controllers in %s failed: %w", path, err)
+	}
+	controllers := strings.Fields(string(b))
+	if len(controllers) == 0 {
+		return nil
+	}
+	for idx, c := range controllers {
+		controllers[idx] = "+" + c
+	}
+	f, err := os.OpenFile(filepath.Join(path, "cgroup.subtree_control"), os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("open cgroup.subtree_control in %s failed: %w", path, err)
+	}
+	defer f.Close()
+	enableRequest := strings.Join(controllers, " ")
+	if _, err := f.WriteString(enableRequest); err != nil {
+		return fmt.Errorf("write %s to cgroup.subtree_control in %s failed: %w", enableRequest, path, err)
+	}
+	return nil
+}
+
+// Create the cgroup of sandbox. The intermediate cgroups of CgroupSubpath are
+// created if not exist, whose controllers are enabled for their children
+// (the same as the parent cgroup of all sandboxes, see EnableCgroupControllers).
+func (cfg *SandboxConfig) createCgroup() error {
+	if cfg.CgroupSubpath == "" {
+		return utils.CreateDirAllIfNotExists(cfg.CgroupPath(), 0o755)
+	}
+	cgroupSubtree.Lock()
+	defer cgroupSubtree.Unlock()
+	path := cfg.cgroupParentPath()
+	for _, name := range strings.Split(cfg.CgroupSubpath, "/") {
+		path = filepath.Join(path, name)
+		if err := os.Mkdir(path, 0o755); err == nil {
+			cgroupSubtree.created[path] = struct{}{}
+		} else if !os.IsExist(err) {
+			return fmt.Errorf("create cgroup %s failed: %w", path, err)
+		}
+		if err := EnableCgroupControllers(path); err != nil {
+			return err
+		}
+	}
+	return utils.CreateDirAllIfNotExists(cfg.CgroupPath(), 0o755)
+}
+
+// Remove the intermediate cgroups of CgroupSubpath created by createCgroup
+// (from the deepest one) once no sandbox is under them, should be called
+// after the cgroup of sandbox is removed.
+func (cfg *SandboxConfig) removeCgroupSubtree() error {
+	if cfg.CgroupSubpath == "" {
+		return nil
+	}
+	cgroupSubtree.Lock()
+	defer cgroupSubtree.Unlock()
+	for subpath := cfg.CgroupSubpath; subpath != "."; subpath = filepath.Dir(subpath) {
+		path := filepath.Join(cfg.cgroupParentPath(), subpath)
+		if _, ok := cgroupSubtree.created[path]; !ok {
+			return nil
+		}
+		if err := syscall.Rmdir(path); err != nil {
+			if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.ENOTEMPTY) {
+				// still used by other sandboxes
+				return nil
+			}
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("remove cgroup %s failed: %w", path, err)
+			}
+		}
+		delete(cgroupSubtree.created, path)
+	}
+	return nil
+}
+
+// The cgroup subpath of the sandbox whose vmm process is pid (e.g., the orphan
+// sandboxes), derived from /proc/<pid>/cgroup. Empty if the vmm is directly
+// under the parent cgroup of all sandboxes, or not in the cgroup of sandbox.
+func ProcessCgroupSubpath(pid int, cgroupName, sandboxID string) string {
+	b, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return ""
+	}
+	return parseCgroupSubpath(string(b), cgroupName, sandboxID)
+}
+
+// Parse the cgroup subpath from the content of /proc/<pid>/cgroup, i.e., the
+// "0::/${cgroupName}/${subpath}/${sandboxID}" line of cgroup v2.
+func parseCgroupSubpath(procCgroup, cgroupName, sandboxID string) string {
+	for _, line := range strings.Split(procCgroup, "\n") {
+		path, ok := strings.CutPrefix(line, "0::")
+		if !ok {
+			continue
+		}
+		prefix := "/" + strings.Trim(cgroupName, "/") + "/"
+		subpath, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			return ""
+		}
+		subpath, ok = strings.CutSuffix(subpath, "/"+sandboxID)
+		if !ok || ValidateCgroupSubpath(subpath) != nil {
+			return ""
+		}
+		return subpath
+	}
+	return ""
+}
+
+// Validate the content written into cpu.max, i.e., "$MAX [$PERIOD]",
+// where $MAX can be "max" (no limit).
+func ValidateCgroupCpuMax(cpuMax string) error {
+	fields := strings.Fields(cpuMax)
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("%w: cpu.max %q should be \"$MAX [$PERIOD]\"", ErrInvalidCgroupLimit, cpuMax)
+	}
+	if fields[0] != "max" {
+		if quota, err := strconv.ParseUint(fields[0], 10, 64); err != nil || quota == 0 {
+			return fmt.Errorf("%w: invalid quota of cpu.max %q", ErrInvalidCgroupLimit, cpuMax)
+		}
+	}
+	if len(fields) == 2 {
+		if period, err := strconv.ParseUint(fields[1], 10, 64); err != nil || period == 0 {
+			return fmt.Errorf("%w: invalid period of cpu.max %q", ErrInvalidCgroupLimit, cpuMax)
+		}
+	}
+	return nil
+}
+
+// The content written into cpu.max of sandbox cgroup. By default, it allows
+// VCpuCount cores plus some headroom for the vmm threads.
+func (cfg *SandboxConfig) cgroupCpuMax() string {
+	if cfg.CgroupCpuMax != "" {
+		return cfg.CgroupCpuMax
+	}
+	quota := (cfg.VCpuCount*100 + cgroupCpuHeadroom) * cgroupCpuPeriod / 100
+	return fmt.Sprintf("%d %d", quota, cgroupCpuPeriod)
+}
+
+// The content written into memory.max of sandbox cgroup. By default, it allows
+// GuestMemoryMB plus some headroom for the vmm process.
+//
+// The huge pages are charged into hugetlb controller instead
+// of memory.max, so only headroom is needed in that case.
+func (cfg *SandboxConfig) cgroupMemoryMax() string {
+	if cfg.CgroupMemoryMax > 0 {
+		return strconv.FormatInt(cfg.CgroupMemoryMax, 10)
+	}
+	memoryMB := int64(cgroupMemoryHeadroomMB)
+	if cfg.HugePage().Bytes() == 0 {
+		memoryMB += cfg.GuestMemoryMB()
+	}
+	return strconv.FormatInt(memoryMB<<20, 10)
+}
+
+// Write the cpu and memory limits into the cgroup of sandbox, should be
+// called after the cgroup is created and before the vmm joins it.
+//
+// The default limits (i.e., derived from the template) are skipped when the
+// controller is not available (e.g., not delegated on this host), and the
+// skipped controllers are returned. While the limits specified explicitly
+// fail in that case.
+func (cfg *SandboxConfig) setCgroupLimits() ([]string, error) {
+	return cfg.writeCgroupLimits(cfg.CgroupPath())
+}
+
+func (cfg *SandboxConfig) writeCgroupLimits(cgroupPath string) ([]string, error) {
+	// the controllers are enabled in subtree_control of the parent cgroup
+	// when orchestrator starts (see createSandboxCgroup), or when the
+	// intermediate cgroups of CgroupSubpath are created (see createCgroup)
+	b, err := os.ReadFile(filepath.Join(cgroupPath, "cgroup.controllers"))
+	if err != nil {
+		return nil, fmt.Errorf("read cgroup.controllers failed: %w", err)
+	}
+	controllers := strings.Fields(string(b))
+
+	var skipped []string
+	for _, limit := range []struct {
+		controller string
+		file       string
+		value      string
+		explicit   bool
+	}{
+		{"cpu", "cpu.max", cfg.cgroupCpuMax(), cfg.CgroupCpuMax != ""},
+		{"memory", "memory.max", cfg.cgroupMemoryMax(), cfg.CgroupMemoryMax > 0},
+	} {
+		if !slices.Contains(controllers, limit.controller) {
+			if limit.explicit {
+				return nil, fmt.Errorf("controller %s is not enabled for %s", limit.controller, cgroupPath)
+			}
+			skipped = append(skipped, limit.controller)
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(cgroupPath, limit.file), []byte(limit.value), 0); err != nil {
+			return nil, fmt.Errorf("write %s to %s failed: %w", limit.value, limit.file, err)
+		}
+	}
+	return skipped, nil
+}
+
+// Make sure the vmm process started by previous orchestrator is in the cgroup
+// of sandbox, the cgroup (with its limits) is recreated if it has been removed.
+func (cfg *SandboxConfig) reattachCgroup(pid int) error {
+	path := cfg.CgroupPath()
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := cfg.createCgroup(); err != nil {
+			return fmt.Errorf("recreate cgroup %s failed: %w", path, err)
+		}
+		if _, err := cfg.setCgroupLimits(); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	procs, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+	if err != nil {
+		return fmt.Errorf("read cgroup.procs failed: %w", err)
+	}
+	if slices.Contains(strings.Fields(string(procs)), strconv.Itoa(pid)) {
+		return nil
+	}
+	if err := addProcToCgroup(path, pid); err != nil {
+		return fmt.Errorf("migrate vmm to cgroup failed: %w", err)
+	}
+	return nil
+}
+
+// Remove the cgroup dir, which should not contain any process.
+//
+// Maybe process has not been clean completely by kernel,
+// so retry rm cgroup dir for 3 times.
+func RemoveCgroup(path string) error {
+	var err error
+	sleepTimes := [3]time.Duration{
+		200 * time.Millisecond,
+		500 * time.Millisecond,
+		1500 * time.Millisecond,
+	}
+	for _, sleepTime := range sleepTimes {
+		if err = syscall.Rmdir(path); err == nil {
+			break
+		}
+		time.Sleep(sleepTime)
+	}
+	return err
+}
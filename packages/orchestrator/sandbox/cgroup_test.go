@@ -0,0 +1,246 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+func TestValidateCgroupCpuMax(t *testing.T) {
+	testCases := []struct {
+		cpuMax string
+		valid  bool
+	}{
+		{"max", true},
+		{"max 100000", true},
+		{"200000", true},
+		{"200000 100000", true},
+		{"", false},
+		{"  ", false},
+		{"0 100000", false},
+		{"-1 100000", false},
+		{"200000 0", false},
+		{"200000 abc", false},
+		{"max max", false},
+		{"200000 100000 1", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.cpuMax, func(t *testing.T) {
+			err := ValidateCgroupCpuMax(tc.cpuMax)
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidCgroupLimit) {
+				t.Fatalf("expect invalid cgroup limit, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateCgroupSubpath(t *testing.T) {
+	for _, subpath := range []string{"", "team-a", "team_a/project-1"} {
+		if err := ValidateCgroupSubpath(subpath); err != nil {
+			t.Fatalf("expect %q valid, got %s", subpath, err)
+		}
+	}
+	for _, subpath := range []string{"/team", "team/", "../team", "team/../..", "team//a", "./team", "cpu.max", "team a"} {
+		if err := ValidateCgroupSubpath(subpath); !errors.Is(err, ErrInvalidCgroupSubpath) {
+			t.Fatalf("expect %q invalid, got %v", subpath, err)
+		}
+	}
+}
+
+func TestParseCgroupSubpath(t *testing.T) {
+	testCases := []struct {
+		procCgroup string
+		subpath    string
+	}{
+		{"0::/sandboxes/team-a/sbx\n", "team-a"},
+		{"0::/sandboxes/team-a/project/sbx\n", "team-a/project"},
+		{"0::/sandboxes/sbx\n", ""},
+		// not in the cgroup of sandbox
+		{"0::/user.slice\n", ""},
+		{"0::/sandboxes/team-a/other\n", ""},
+	}
+	for _, tc := range testCases {
+		if subpath := parseCgroupSubpath(tc.procCgroup, "sandboxes", "sbx"); subpath != tc.subpath {
+			t.Fatalf("expect %q from %q, got %q", tc.subpath, tc.procCgroup, subpath)
+		}
+	}
+}
+
+func TestRemoveCgroupSubtree(t *testing.T) {
+	cfg := &SandboxConfig{SandboxID: "sbx", CgroupfsPath: t.TempDir(), CgroupName: "sandboxes", CgroupSubpath: "team/project"}
+	team := filepath.Join(cfg.cgroupParentPath(), "team")
+	project := filepath.Join(team, "project")
+	if err := os.MkdirAll(filepath.Join(project, "other"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// the team cgroup exists before, i.e., not created for the subpath
+	cgroupSubtree.Lock()
+	cgroupSubtree.created[project] = struct{}{}
+	cgroupSubtree.Unlock()
+	t.Cleanup(func() {
+		cgroupSubtree.Lock()
+		delete(cgroupSubtree.created, project)
+		cgroupSubtree.Unlock()
+	})
+
+	// still used by another sandbox
+	if err := cfg.removeCgroupSubtree(); err != nil {
+		t.Fatalf("remove cgroup subtree failed: %s", err)
+	}
+	if _, err := os.Stat(project); err != nil {
+		t.Fatalf("the cgroup used by other sandbox should be kept, stat err: %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(project, "other")); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.removeCgroupSubtree(); err != nil {
+		t.Fatalf("remove cgroup subtree failed: %s", err)
+	}
+	if _, err := os.Stat(project); !os.IsNotExist(err) {
+		t.Fatalf("expect the created cgroup removed, stat err: %v", err)
+	}
+	if _, err := os.Stat(team); err != nil {
+		t.Fatalf("the cgroup existing before should be kept, stat err: %v", err)
+	}
+}
+
+func TestCgroupLimits(t *testing.T) {
+	testCases := []struct {
+		name      string
+		cfg       SandboxConfig
+		cpuMax    string
+		memoryMax string
+	}{
+		{
+			name:      "derived from template",
+			cfg:       SandboxConfig{VMTemplate: config.VMTemplate{VCpuCount: 2, MemoryMB: 512}},
+			cpuMax:    "300000 100000",
+			memoryMax: "671088640",
+		},
+		{
+			name:      "huge pages are not charged",
+			cfg:       SandboxConfig{VMTemplate: config.VMTemplate{VCpuCount: 1, MemoryMB: 512, HugePageSize: config.HugePage2M}},
+			cpuMax:    "200000 100000",
+			memoryMax: "134217728",
+		},
+		{
+			name: "specified explicitly",
+			cfg: SandboxConfig{
+				VMTemplate:      config.VMTemplate{VCpuCount: 2, MemoryMB: 512},
+				CgroupCpuMax:    "max",
+				CgroupMemoryMax: 1 << 30,
+			},
+			cpuMax:    "max",
+			memoryMax: "1073741824",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.cfg.cgroupCpuMax(); got != tc.cpuMax {
+				t.Fatalf("expect cpu.max %q, got %q", tc.cpuMax, got)
+			}
+			if got := tc.cfg.cgroupMemoryMax(); got != tc.memoryMax {
+				t.Fatalf("expect memory.max %q, got %q", tc.memoryMax, got)
+			}
+		})
+	}
+}
+
+func TestWriteCgroupLimits(t *testing.T) {
+	newCgroup := func(t *testing.T, controllers string) string {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "cgroup.controllers"), []byte(controllers), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	read := func(t *testing.T, path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	derived := SandboxConfig{VMTemplate: config.VMTemplate{VCpuCount: 2, MemoryMB: 512}}
+
+	dir := newCgroup(t, "cpuset cpu io memory pids\n")
+	skipped, err := derived.writeCgroupLimits(dir)
+	if err != nil || len(skipped) != 0 {
+		t.Fatalf("expect nothing skipped, got %v (err: %v)", skipped, err)
+	}
+	if got := read(t, filepath.Join(dir, "cpu.max")); got != derived.cgroupCpuMax() {
+		t.Fatalf("unexpected cpu.max %q", got)
+	}
+	if got := read(t, filepath.Join(dir, "memory.max")); got != derived.cgroupMemoryMax() {
+		t.Fatalf("unexpected memory.max %q", got)
+	}
+
+	// the default limits are skipped without the controllers
+	dir = newCgroup(t, "memory pids\n")
+	skipped, err = derived.writeCgroupLimits(dir)
+	if err != nil || !slices.Equal(skipped, []string{"cpu"}) {
+		t.Fatalf("expect cpu skipped, got %v (err: %v)", skipped, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cpu.max")); !os.IsNotExist(err) {
+		t.Fatalf("cpu.max should not be written, stat err: %v", err)
+	}
+
+	// while the explicit ones fail
+	explicit := derived
+	explicit.CgroupCpuMax = "max"
+	if _, err := explicit.writeCgroupLimits(newCgroup(t, "memory pids\n")); err == nil {
+		t.Fatal("expect error when the controller of explicit limit is not enabled")
+	}
+
+	if _, err := derived.writeCgroupLimits(t.TempDir()); err == nil {
+		t.Fatal("expect error without cgroup.controllers")
+	}
+}
+
+func TestReattachCgroup(t *testing.T) {
+	cfg := &SandboxConfig{SandboxID: "test-sandbox", CgroupfsPath: t.TempDir(), CgroupName: "sandboxes"}
+	procsPath := filepath.Join(cfg.CgroupPath(), "cgroup.procs")
+	if err := os.MkdirAll(cfg.CgroupPath(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(procsPath, []byte("123\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.reattachCgroup(123); err != nil {
+		t.Fatalf("reattach cgroup failed: %s", err)
+	}
+	if b, _ := os.ReadFile(procsPath); string(b) != "123\n" {
+		t.Fatalf("the process already in cgroup should not be migrated, got %q", b)
+	}
+
+	if err := os.WriteFile(procsPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.reattachCgroup(456); err != nil {
+		t.Fatalf("reattach cgroup failed: %s", err)
+	}
+	if b, _ := os.ReadFile(procsPath); string(b) != "456" {
+		t.Fatalf("expect the process migrated into cgroup, got %q", b)
+	}
+
+	// the recreated cgroup is not a real one (without cgroup.controllers)
+	if err := os.RemoveAll(cfg.CgroupPath()); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.reattachCgroup(456); err == nil {
+		t.Fatal("expect error when the limits cannot be written")
+	}
+	if _, err := os.Stat(cfg.CgroupPath()); err != nil {
+		t.Fatalf("expect the cgroup recreated, stat err: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+type fileChecksum struct {
+	size    int64
+	modTime time.Time
+	sum     string
+}
+
+// The checksums computed before, keyed by the file path. The files of template
+// are rarely modified, so the checksum is only recomputed when the size or
+// mtime of file changes.
+var checksumCache = struct {
+	sync.Mutex
+	files map[string]fileChecksum
+}{files: make(map[string]fileChecksum)}
+
+func cachedChecksum(path string) (string, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false, err
+	}
+	checksumCache.Lock()
+	cached, ok := checksumCache.files[path]
+	checksumCache.Unlock()
+	if ok && cached.size == info.Size() && cached.modTime.Equal(info.ModTime()) {
+		return cached.sum, true, nil
+	}
+
+	sum, err := utils.SHA256File(path)
+	if err != nil {
+		return "", false, err
+	}
+	checksumCache.Lock()
+	checksumCache.files[path] = fileChecksum{size: info.Size(), modTime: info.ModTime(), sum: sum}
+	checksumCache.Unlock()
+	return sum, false, nil
+}
+
+// Verify the rootfs and kernel against the checksums recorded in template,
+// the files without recorded checksum (e.g., built by an old template-manager)
+// are skipped.
+func (cfg *SandboxConfig) verifyChecksums(ctx context.Context) error {
+	for name, path := range cfg.ChecksumFiles(cfg.DataRoot) {
+		expected, ok := cfg.Checksums[name]
+		if !ok {
+			continue
+		}
+		// the kernel of template is not used
+		if name == consts.KernelName && cfg.KernelImagePathOverride != "" {
+			continue
+		}
+		sum, cached, err := cachedChecksum(path)
+		if err != nil {
+			return fmt.Errorf("error computing checksum of %s: %w", name, err)
+		}
+		if sum != expected {
+			return fmt.Errorf("%w of %s (%s): expect %s, got %s", ErrChecksumMismatch, name, path, expected, sum)
+		}
+		telemetry.ReportEvent(ctx, "checksum verified",
+			attribute.String("file", name),
+			attribute.Bool("cached", cached),
+		)
+	}
+	return nil
+}
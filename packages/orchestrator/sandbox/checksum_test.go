@@ -0,0 +1,94 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestVerifyChecksums(t *testing.T) {
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{TemplateID: "checksum", KernelVersion: "6.1"},
+		DataRoot:   t.TempDir(),
+	}
+	files := cfg.ChecksumFiles(cfg.DataRoot)
+	for name, path := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// no checksum recorded
+	if err := cfg.verifyChecksums(context.Background()); err != nil {
+		t.Fatalf("expect skipping files without checksum, got %s", err)
+	}
+	if err := cfg.ComputeChecksums(cfg.DataRoot); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Checksums) != 2 {
+		t.Fatalf("expect checksums of rootfs and kernel, got %v", cfg.Checksums)
+	}
+	if err := cfg.verifyChecksums(context.Background()); err != nil {
+		t.Fatalf("verify checksums failed: %s", err)
+	}
+
+	// the kernel of template is not booted when overridden
+	if err := os.WriteFile(files[consts.KernelName], []byte("other kernel"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.verifyChecksums(context.Background()); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expect kernel checksum mismatch, got %v", err)
+	}
+	cfg.KernelImagePathOverride = "/custom/vmlinux"
+	if err := cfg.verifyChecksums(context.Background()); err != nil {
+		t.Fatalf("expect skipping the kernel of template, got %s", err)
+	}
+	cfg.KernelImagePathOverride = ""
+	if err := os.WriteFile(files[consts.KernelName], []byte(consts.KernelName), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the size changes, so the checksum is recomputed
+	rootfs := files[consts.RootfsName]
+	if err := os.WriteFile(rootfs, []byte("corrupted"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.verifyChecksums(context.Background()); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expect checksum mismatch, got %v", err)
+	}
+}
+
+func TestChecksumCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rootfs.ext4")
+	if err := os.WriteFile(path, []byte("before"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, cached, err := cachedChecksum(path)
+	if err != nil || cached {
+		t.Fatalf("expect computing checksum, got cached %v (err: %v)", cached, err)
+	}
+	if again, cached, err := cachedChecksum(path); err != nil || !cached || again != sum {
+		t.Fatalf("expect cached checksum %s, got %s (cached: %v, err: %v)", sum, again, cached, err)
+	}
+
+	// same size, but the mtime changes
+	if err := os.WriteFile(path, []byte("after!"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+	if again, cached, err := cachedChecksum(path); err != nil || cached || again == sum {
+		t.Fatalf("expect recomputing checksum, got %s (cached: %v, err: %v)", again, cached, err)
+	}
+}
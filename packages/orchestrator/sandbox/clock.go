@@ -0,0 +1,72 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
+)
+
+const (
+	DefaultClockSyncMaxAttempts = 30
+	DefaultClockSyncTimeout     = 60 * time.Second
+
+	// the interval between attempts doubles from clockSyncRetryInterval
+	// up to clockSyncMaxRetryInterval
+	clockSyncMaxRetryInterval = 10 * time.Second
+	// the timeout of each attempt, so that an unresponsive envd does not
+	// take the whole ClockSyncOptions.Timeout
+	clockSyncAttemptTimeout = 5 * time.Second
+)
+
+// Returned when the clock of guest is still not synced after all
+// attempts (or the timeout), e.g., envd is unreachable.
+var ErrClockSyncGaveUp = errors.New("gave up syncing clock")
+
+// The number of times EnsureClockSync gave up
+var clockSyncFailures metric.Int64Counter
+
+func init() {
+	var err error
+	clockSyncFailures, err = otel.Meter(constants.ServiceName).Int64Counter(
+		"clock_sync.failure",
+		metric.WithDescription("Number of times the clock of sandbox is not synced after all attempts"),
+	)
+	if err != nil {
+		panic(fmt.Errorf("create metric `clock_sync.failure` failed: %w", err))
+	}
+}
+
+// How to retry the /sync request to envd until the clock of guest is synced.
+type ClockSyncOptions struct {
+	// the max number of attempts, 0 means DefaultClockSyncMaxAttempts
+	MaxAttempts int
+	// the max duration of all attempts, 0 means DefaultClockSyncTimeout
+	Timeout time.Duration
+}
+
+func (o ClockSyncOptions) maxAttempts() int {
+	if o.MaxAttempts <= 0 {
+		return DefaultClockSyncMaxAttempts
+	}
+	return o.MaxAttempts
+}
+
+func (o ClockSyncOptions) timeout() time.Duration {
+	if o.Timeout <= 0 {
+		return DefaultClockSyncTimeout
+	}
+	return o.Timeout
+}
+
+// Randomize the interval into [interval/2, interval], so that the sandboxes
+// created (or resumed) together do not retry at the same time.
+func jitter(interval time.Duration) time.Duration {
+	half := interval / 2
+	return half + rand.N(half+1)
+}
@@ -0,0 +1,54 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnsureClockSyncGiveUp(t *testing.T) {
+	sbx, _ := newTestSandbox(t, false)
+	sbx.Config.ClockSync = ClockSyncOptions{MaxAttempts: 3}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- sbx.EnsureClockSync(context.Background()) }()
+	err := waitErr(t, errCh, "EnsureClockSync")
+	if !errors.Is(err, ErrClockSyncGaveUp) {
+		t.Fatalf("expect ErrClockSyncGaveUp, got %v", err)
+	}
+
+	sbx.Config.ClockSync = ClockSyncOptions{Timeout: 200 * time.Millisecond}
+	go func() { errCh <- sbx.EnsureClockSync(context.Background()) }()
+	err = waitErr(t, errCh, "EnsureClockSync")
+	if !errors.Is(err, ErrClockSyncGaveUp) {
+		t.Fatalf("expect ErrClockSyncGaveUp after timeout, got %v", err)
+	}
+}
+
+func TestEnsureClockSyncCanceledByStop(t *testing.T) {
+	sbx, _ := newTestSandbox(t, false)
+	sbx.bgCtx, sbx.bgCancel = context.WithCancel(context.Background())
+	// large enough to not give up during the test
+	sbx.Config.ClockSync = ClockSyncOptions{MaxAttempts: 1000, Timeout: time.Hour}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- sbx.EnsureClockSync(sbx.backgroundCtx()) }()
+	time.Sleep(200 * time.Millisecond)
+	if err := sbx.Stop(context.Background(), testTracer); err != nil {
+		t.Fatalf("stop failed: %s", err)
+	}
+	err := waitErr(t, errCh, "EnsureClockSync")
+	if errors.Is(err, ErrClockSyncGaveUp) || err == nil {
+		t.Fatalf("expect canceled by stop, got %v", err)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	interval := 100 * time.Millisecond
+	for range 100 {
+		if d := jitter(interval); d < interval/2 || d > interval {
+			t.Fatalf("jitter %s out of [%s, %s]", d, interval/2, interval)
+		}
+	}
+}
@@ -2,16 +2,20 @@ package sandbox
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
-	"syscall"
 	"time"
 
 	"github.com/KarpelesLab/reflink"
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -23,8 +27,9 @@ import (
 const (
 	InstancesDirName         = "instances"
 	InstancesSnapshotDirName = "instances-snapshot"
-
-	socketWaitTimeout = 2 * time.Second
+	consoleLogName           = "console.log"
+	fcLogName                = "fc.log"
+	fcMetricsName            = "fc-metrics.json"
 )
 
 type SandboxConfig struct {
@@ -34,40 +39,107 @@ type SandboxConfig struct {
 	SandboxID string
 	// (e.g., code-interpreter or code-interpreter/sub-cgroup )
 	CgroupName string
+	// the mountpoint of cgroup2, consts.CgroupfsPath if empty
+	CgroupfsPath string
+	// the subtree under CgroupName which the cgroup of sandbox is placed
+	// under, validated by ValidateCgroupSubpath (empty means directly under)
+	CgroupSubpath string
 	// The socket path for FC
 	SocketPath           string
 	HypervisorBinaryPath string
+	// optional, the (pre-compiled bpf) seccomp profile applied to vmm process
+	SeccompProfilePath string
 	// only needed for FC
 	EnableDiffSnapshot bool
 	MaxInstanceLength  int
 	// only used by FC
 	Metadata map[string]string
-}
-
-// waitForSocket waits for the given file to exist
-func waitForSocket(socketPath string, timeout time.Duration) error {
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
-
-	ticker := time.NewTicker(10 * time.Millisecond)
-
-	defer func() {
-		cancel()
-		ticker.Stop()
-	}()
-
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-ticker.C:
-			if _, err := os.Stat(socketPath); err != nil {
-				continue
-			}
-
-			// TODO: Send test HTTP request to make sure socket is available
-			return nil
-		}
-	}
+	// only valid when template enables config drive
+	CloudInitUserData string
+	// the address of nginx proxy written into prometheus target
+	PrometheusProxyAddr string
+	// empty means do not probe the prometheus target
+	PrometheusProbeAddr    string
+	PrometheusProbeTimeout time.Duration
+	// cancel the in-progress snapshot when deleting the sandbox,
+	// otherwise the delete waits for the snapshot to finish.
+	CancelSnapshotOnDelete bool
+	// where the instance snapshots are stored, empty means under the template dir
+	SnapshotRoot string
+	// the client to talk with envd, nil means use a default one
+	EnvdClient *http.Client
+	// the limits written into cpu.max and memory.max (in bytes) of the cgroup,
+	// empty (or 0) means derive from VCpuCount and MemoryMB.
+	CgroupCpuMax    string
+	CgroupMemoryMax int64
+	// the extra (scratch) disks, validated by ValidateExtraDisks
+	ExtraDisks []DiskSpec
+	// nil means allowing all egress traffic
+	EgressPolicy *network.EgressPolicy
+	// the host ports of them (if 0) are allocated when creating the sandbox
+	PortForwards []network.PortForward
+	// the defaults of processes spawned by envd (see ValidateProcessDefaults)
+	WorkingDir string
+	Env        map[string]string
+	// how to wait for the api socket of vmm
+	SocketWait utils.SocketWaitOptions
+	// how to retry the restore request of vmm
+	Restore hypervisor.RestoreOptions
+	// the max attempts of creating (and verifying) the snapshot,
+	// 0 means hypervisor.DefaultSnapshotAttempts
+	SnapshotAttempts int
+	// override the disk limits of template, validated by ValidateDiskRateLimitOverride
+	DiskRateLimitOverride hypervisor.DiskRateLimit
+	// how to retry the /sync request to envd after the vmm is started (or resumed)
+	ClockSync ClockSyncOptions
+	// the memory (in MiB) of guest after restored, 0 means the MemoryMB of
+	// template, validated by ValidateMemoryOverride
+	MemoryOverrideMB int64
+	// not empty to receive the vm by live migration (see ValidateMigrationURL)
+	// instead of restoring from the template
+	MigrationReceiverURL string
+	// boot the vm from the rootfs of template with its kernel args instead of
+	// restoring from the snapshot, which is much slower and only meant for
+	// debugging the templates failed to restore (see getFcConfig)
+	ColdBoot bool
+	// boot with this kernel image instead of the one of template, validated
+	// by ValidateKernelImagePathOverride (which requires ColdBoot)
+	KernelImagePathOverride string
+	// verify the rootfs and kernel against the checksums of template before booting
+	VerifyChecksums bool
+	// keep the instance dir (moved into FailedDir) and the network
+	// for post-mortem when failed to create the sandbox
+	KeepFilesOnError bool
+	// tee the output of vmm into ConsoleLogPath, which is rotated once exceeding
+	// ConsoleLogMaxSize (in bytes). When RetainConsoleLog, it is moved into
+	// RetainedConsoleLogPath instead of being removed along with the instance dir.
+	ConsoleLog        bool
+	ConsoleLogMaxSize int64
+	RetainConsoleLog  bool
+	// opened when starting the vmm, closed in CleanupFiles
+	consoleLog *utils.RotatingFile
+	// firecracker only, write its own logs (at FcLogLevel, empty means no
+	// logger) into FcLogPath, and its metrics into FcMetricsPath when FcMetrics.
+	// When RetainFcLogs, they are moved into RetainedFcLogsDir instead of
+	// being removed along with the instance dir.
+	FcLogLevel   string
+	FcMetrics    bool
+	RetainFcLogs bool
+	// the mac address of guest interface applied after restored, empty means
+	// keeping the one of template, unless UniqueGuestMAC which derives it
+	// from the network (see network.NetworkEnv.GuestMAC).
+	GuestMAC       string
+	UniqueGuestMAC bool
+	// where the copy of writable rootfs is taken from (when overlay),
+	// nil (or empty) means copying it on demand
+	OverlayPool *OverlayPool
+	// nil means the vmm runs as the user of orchestrator, otherwise the
+	// SocketPath should be in the chroot for firecracker (see JailerSocketPath).
+	Jailer *JailerOptions
+	// notified with the lifecycle events of sandbox (see Sandbox.Event), nil means
+	// no one is interested. It is called with the lock of sandbox held, so it
+	// should not block.
+	OnEvent func(*orchestrator.SandboxEvent)
 }
 
 // Different instance of same Env need has its own dir
@@ -84,15 +156,75 @@ func (cfg *SandboxConfig) InstanceWritableRootfsPath() string {
 	return filepath.Join(cfg.InstancePath(), consts.WritableFsName)
 }
 
+func (cfg *SandboxConfig) InstanceConfigDrivePath() string {
+	return filepath.Join(cfg.InstancePath(), consts.ConfigDriveName)
+}
+
+// Only valid when the template enables compress_memfile, where the memfile
+// is decompressed to when restoring.
+func (cfg *SandboxConfig) InstanceMemfilePath() string {
+	return filepath.Join(cfg.InstancePath(), consts.FcMemfileName)
+}
+
+func (cfg *SandboxConfig) ConsoleLogPath() string {
+	return filepath.Join(cfg.InstancePath(), consoleLogName)
+}
+
+func (cfg *SandboxConfig) RetainedConsoleLogPath() string {
+	return filepath.Join(cfg.DataRoot, constants.ConsoleLogsDirName, cfg.TemplateID, cfg.SandboxID+".log")
+}
+
+func (cfg *SandboxConfig) FcLogPath() string {
+	return filepath.Join(cfg.InstancePath(), fcLogName)
+}
+
+func (cfg *SandboxConfig) FcMetricsPath() string {
+	return filepath.Join(cfg.InstancePath(), fcMetricsName)
+}
+
+func (cfg *SandboxConfig) RetainedFcLogsDir() string {
+	return filepath.Join(cfg.DataRoot, constants.FcLogsDirName, cfg.TemplateID, cfg.SandboxID)
+}
+
+// The log and metrics files of firecracker configured for the sandbox.
+func (cfg *SandboxConfig) fcLogFiles() []string {
+	if cfg.VmmType != config.FIRECRACKER {
+		return nil
+	}
+	var files []string
+	if cfg.FcLogLevel != "" {
+		files = append(files, cfg.FcLogPath())
+	}
+	if cfg.FcMetrics {
+		files = append(files, cfg.FcMetricsPath())
+	}
+	return files
+}
+
+// The parent cgroup of all sandboxes (i.e., not including CgroupSubpath).
+func (cfg *SandboxConfig) cgroupParentPath() string {
+	cgroupfsPath := cfg.CgroupfsPath
+	if cgroupfsPath == "" {
+		cgroupfsPath = consts.CgroupfsPath
+	}
+	return filepath.Join(cgroupfsPath, cfg.CgroupName)
+}
+
 func (cfg *SandboxConfig) CgroupPath() string {
-	return filepath.Join(consts.CgroupfsPath, cfg.CgroupName, cfg.SandboxID)
+	return filepath.Join(cfg.cgroupParentPath(), cfg.CgroupSubpath, cfg.SandboxID)
 }
 
 func (cfg *SandboxConfig) PrometheusTargetPath() string {
 	return filepath.Join(cfg.DataRoot, constants.PrometheusTargetsDirName, cfg.TemplateID, cfg.SandboxID+".json")
 }
 
+// The snapshots are stored in ${SnapshotRoot}/${TemplateID}/${SandboxID} when
+// SnapshotRoot is set (so they can live on a dedicated storage), otherwise
+// in ${TemplateDir}/instances-snapshot/${SandboxID}.
 func (cfg *SandboxConfig) EnvInstanceCreateSnapshotPath() string {
+	if cfg.SnapshotRoot != "" {
+		return filepath.Join(cfg.SnapshotRoot, cfg.TemplateID, cfg.SandboxID)
+	}
 	return filepath.Join(cfg.TemplateDir(cfg.DataRoot), InstancesSnapshotDirName, cfg.SandboxID)
 }
 
@@ -105,8 +237,16 @@ func (cfg *SandboxConfig) EnsureFiles(ctx context.Context, tracer trace.Tracer)
 	)
 	defer childSpan.End()
 
+	if cfg.VerifyChecksums {
+		if err := cfg.verifyChecksums(childCtx); err != nil {
+			telemetry.ReportCriticalError(childCtx, err)
+
+			return err
+		}
+	}
+
 	// 1. InstancePath will bind mount into PrivateDir
-	// 2. Then HostKernelPath will bind mount into PrivateKernelPath
+	// 2. Then HostKernelImagePath will bind mount into PrivateKernelPath
 	//
 	// The PrivateKernelPath is within PrivateDir.
 	// To make sure that there is a mountpoint for 2, we create a file named
@@ -123,41 +263,82 @@ func (cfg *SandboxConfig) EnsureFiles(ctx context.Context, tracer trace.Tracer)
 	}
 	for _, dir := range []string{
 		filepath.Dir(cfg.PrometheusTargetPath()),
+		filepath.Dir(cfg.RegistryPath()),
 		cfg.InstancePath(),
-		cfg.CgroupPath(),
 	} {
 		if err := utils.CreateDirAllIfNotExists(dir, 0o755); err != nil {
 			return fmt.Errorf("error making dir %s: %w", dir, err)
 		}
 	}
+	if err := cfg.createCgroup(); err != nil {
+		return fmt.Errorf("error creating cgroup: %w", err)
+	}
+	// firecracker does not create them
+	for _, path := range cfg.fcLogFiles() {
+		if err := utils.CreateFileAndDirIfNotExists(path, 0o644, 0o755); err != nil {
+			return fmt.Errorf("error creating %s: %w", path, err)
+		}
+	}
+	skipped, err := cfg.setCgroupLimits()
+	if err != nil {
+		errMsg := fmt.Errorf("error setting cgroup limits: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+
+		return errMsg
+	}
+	if len(skipped) > 0 {
+		// report as error so that the hosts without the limits are visible
+		telemetry.ReportError(childCtx, fmt.Errorf("cgroup limits skipped as controllers %v are not enabled", skipped))
+	}
+	telemetry.ReportEvent(childCtx, "cgroup limits set",
+		attribute.String("cpu.max", cfg.cgroupCpuMax()),
+		attribute.String("memory.max", cfg.cgroupMemoryMax()),
+	)
 
 	if cfg.Overlay {
-		// 1. create reflink of writable rootfs file.
+		// 1. create reflink of writable rootfs file (or take one from pool).
 		// 2. create a hard link to base read-only rootfs file.
-		err := reflink.Auto(
-			cfg.HostWritableRootfsPath(cfg.DataRoot),
-			cfg.InstanceWritableRootfsPath(),
+		if cfg.OverlayPool != nil && cfg.OverlayPool.take(cfg, cfg.InstanceWritableRootfsPath()) {
+			telemetry.ReportEvent(childCtx, "writable image taken from pool")
+		} else {
+			err := reflink.Auto(
+				cfg.HostWritableRootfsPath(cfg.DataRoot),
+				cfg.InstanceWritableRootfsPath(),
+			)
+			if err != nil {
+				errMsg := fmt.Errorf("error creating writable reflinked rootfs: %w", err)
+				telemetry.ReportCriticalError(childCtx, errMsg)
+
+				return errMsg
+			}
+			telemetry.ReportEvent(childCtx, "reflink of writable image created")
+		}
+
+		// build a hard link to base rootfs
+		err := os.Link(
+			cfg.HostRootfsPath(cfg.DataRoot),
+			cfg.InstanceRootfsPath(),
 		)
 		if err != nil {
-			errMsg := fmt.Errorf("error creating writable reflinked rootfs: %w", err)
+			errMsg := fmt.Errorf("error linking base rootfs: %w", err)
 			telemetry.ReportCriticalError(childCtx, errMsg)
 
 			return errMsg
 		}
-		telemetry.ReportEvent(childCtx, "reflink of writable image created")
-
-		// build a hard link to base rootfs
-		err = os.Link(
+		telemetry.ReportEvent(childCtx, "hard-link of base image created")
+	} else if cfg.ImmutableRootfs {
+		// the rootfs is never written, so a hard link is enough
+		err := os.Link(
 			cfg.HostRootfsPath(cfg.DataRoot),
 			cfg.InstanceRootfsPath(),
 		)
 		if err != nil {
-			errMsg := fmt.Errorf("error linking base rootfs: %w", err)
+			errMsg := fmt.Errorf("error linking immutable rootfs: %w", err)
 			telemetry.ReportCriticalError(childCtx, errMsg)
 
 			return errMsg
 		}
-		telemetry.ReportEvent(childCtx, "hard-link of base image created")
+		telemetry.ReportEvent(childCtx, "hard-link of immutable rootfs created")
 	} else {
 		err := reflink.Auto(
 			cfg.HostRootfsPath(cfg.DataRoot),
@@ -172,6 +353,38 @@ func (cfg *SandboxConfig) EnsureFiles(ctx context.Context, tracer trace.Tracer)
 		telemetry.ReportEvent(childCtx, "reflink of base rootfs created")
 	}
 
+	if cfg.ConfigDrive {
+		// the metadata is also readable by the guest without mmds
+		// (i.e., cloud hypervisor), see ValidateGuestMetadata
+		var sandboxMetadata []byte
+		if len(cfg.Metadata) > 0 {
+			sandboxMetadata, _ = json.Marshal(cfg.Metadata)
+		}
+		// It will be bind mounted to PrivateConfigDrivePath, which is
+		// the config drive path recorded in snapshot.
+		err := utils.CreateConfigDrive(
+			childCtx,
+			cfg.InstanceConfigDrivePath(),
+			cfg.SandboxID,
+			[]byte(cfg.CloudInitUserData),
+			sandboxMetadata,
+		)
+		if err != nil {
+			errMsg := fmt.Errorf("error creating config drive: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+
+			return errMsg
+		}
+		telemetry.ReportEvent(childCtx, "config drive created")
+	}
+
+	if err := cfg.ensureExtraDisks(childCtx); err != nil {
+		errMsg := fmt.Errorf("error creating extra disks: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+
+		return errMsg
+	}
+
 	return nil
 }
 
@@ -191,6 +404,17 @@ func (cfg *SandboxConfig) CleanupFiles(
 	defer childSpan.End()
 	var finalErr error
 
+	if err := cfg.closeConsoleLog(keepInstanceDir); err != nil {
+		errMsg := fmt.Errorf("error closing console log: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+		finalErr = errors.Join(finalErr, errMsg)
+	}
+	if err := cfg.retainFcLogs(keepInstanceDir); err != nil {
+		errMsg := fmt.Errorf("error retaining fc logs: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+		finalErr = errors.Join(finalErr, errMsg)
+	}
+
 	if !keepInstanceDir {
 		err := os.RemoveAll(cfg.InstancePath())
 		if err != nil {
@@ -201,6 +425,19 @@ func (cfg *SandboxConfig) CleanupFiles(
 			// TODO: Check the socket?
 			telemetry.ReportEvent(childCtx, "removed all env instance files")
 		}
+	} else {
+		// the extra disks (and decompressed memfile) may be large,
+		// so remove them even if keeping the instance dir
+		if err := cfg.removeExtraDisks(); err != nil {
+			errMsg := fmt.Errorf("error removing extra disks: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			finalErr = errors.Join(finalErr, errMsg)
+		}
+		if err := os.Remove(cfg.InstanceMemfilePath()); err != nil && !os.IsNotExist(err) {
+			errMsg := fmt.Errorf("error removing decompressed memfile: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			finalErr = errors.Join(finalErr, errMsg)
+		}
 	}
 
 	// Remove socket
@@ -213,6 +450,12 @@ func (cfg *SandboxConfig) CleanupFiles(
 		telemetry.ReportEvent(childCtx, "removed socket")
 	}
 
+	if err := cfg.removeJail(); err != nil {
+		errMsg := fmt.Errorf("error removing chroot of jailer: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		finalErr = errors.Join(finalErr, errMsg)
+	}
+
 	err = os.Remove(cfg.PrometheusTargetPath())
 	if err != nil {
 		errMsg := fmt.Errorf("error prometheus target path: %w", err)
@@ -222,27 +465,77 @@ func (cfg *SandboxConfig) CleanupFiles(
 		telemetry.ReportEvent(childCtx, "removed prometheus target path")
 	}
 
-	// NOTE(huang-jl): maybe process has not been clean completely by kernel, so:
-	// (1) retry rm cgroup dir for 3 times
-	// (2) make remove cgroup at final step.
-	sleepTimes := [3]time.Duration{
-		200 * time.Millisecond,
-		500 * time.Millisecond,
-		1500 * time.Millisecond,
-	}
-	for _, sleepTime := range sleepTimes {
-		if err = syscall.Rmdir(cfg.CgroupPath()); err == nil {
-			break
-		}
-		time.Sleep(sleepTime)
+	err = os.Remove(cfg.RegistryPath())
+	if err != nil && !os.IsNotExist(err) {
+		errMsg := fmt.Errorf("error removing sandbox state: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		finalErr = errors.Join(finalErr, errMsg)
+	} else {
+		telemetry.ReportEvent(childCtx, "removed sandbox state")
 	}
+
+	// make remove cgroup at final step.
+	err = RemoveCgroup(cfg.CgroupPath())
 	if err != nil {
 		errMsg := fmt.Errorf("error remove cgroup path: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
 		finalErr = errors.Join(finalErr, errMsg)
 	} else {
 		telemetry.ReportEvent(childCtx, "removed cgroup path")
+		if err := cfg.removeCgroupSubtree(); err != nil {
+			errMsg := fmt.Errorf("error removing cgroup subtree: %w", err)
+			telemetry.ReportError(childCtx, errMsg)
+			finalErr = errors.Join(finalErr, errMsg)
+		}
 	}
 
 	return finalErr
 }
+
+// Move the logs and metrics of firecracker out of the instance dir if they
+// are retained and the instance dir is going to be removed.
+func (cfg *SandboxConfig) retainFcLogs(keepInstanceDir bool) error {
+	files := cfg.fcLogFiles()
+	if keepInstanceDir || !cfg.RetainFcLogs || len(files) == 0 {
+		return nil
+	}
+	dir := cfg.RetainedFcLogsDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	var finalErr error
+	for _, path := range files {
+		if err := os.Rename(path, filepath.Join(dir, filepath.Base(path))); err != nil && !os.IsNotExist(err) {
+			finalErr = errors.Join(finalErr, err)
+		}
+	}
+	return finalErr
+}
+
+// Close the console log (if opened), and move it (along with the rotated one)
+// out of the instance dir if it is retained and the instance dir is going to
+// be removed.
+func (cfg *SandboxConfig) closeConsoleLog(keepInstanceDir bool) error {
+	var finalErr error
+	if cfg.consoleLog != nil {
+		finalErr = cfg.consoleLog.Close()
+	}
+	if keepInstanceDir || !cfg.RetainConsoleLog {
+		return finalErr
+	}
+	src, dst := cfg.ConsoleLogPath(), cfg.RetainedConsoleLogPath()
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return errors.Join(finalErr, err)
+	}
+	// the rotated one of the previous sandbox with the same id is stale
+	if err := os.Remove(utils.RotatedPath(dst)); err != nil && !os.IsNotExist(err) {
+		finalErr = errors.Join(finalErr, err)
+	}
+	if err := os.Rename(src, dst); err != nil && !os.IsNotExist(err) {
+		finalErr = errors.Join(finalErr, err)
+	}
+	if err := os.Rename(utils.RotatedPath(src), utils.RotatedPath(dst)); err != nil && !os.IsNotExist(err) {
+		finalErr = errors.Join(finalErr, err)
+	}
+	return finalErr
+}
@@ -0,0 +1,90 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+func TestCloseConsoleLog(t *testing.T) {
+	for _, retain := range []bool{false, true} {
+		cfg := &SandboxConfig{
+			VMTemplate:       config.VMTemplate{TemplateID: "console"},
+			SandboxID:        "test-sandbox",
+			DataRoot:         t.TempDir(),
+			RetainConsoleLog: retain,
+		}
+		if err := os.MkdirAll(cfg.InstancePath(), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		consoleLog, err := utils.OpenRotatingFile(cfg.ConsoleLogPath(), 1<<20)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.consoleLog = consoleLog
+		if _, err := consoleLog.Write([]byte("Kernel panic\n")); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := cfg.closeConsoleLog(false); err != nil {
+			t.Fatalf("close console log (retain %v) failed: %s", retain, err)
+		}
+		if _, err := consoleLog.Write([]byte("more\n")); err == nil {
+			t.Fatalf("console log (retain %v) should be closed", retain)
+		}
+		b, err := os.ReadFile(cfg.RetainedConsoleLogPath())
+		if retain && string(b) != "Kernel panic\n" {
+			t.Fatalf("expect retained console log, got %q (%v)", b, err)
+		}
+		if !retain && !os.IsNotExist(err) {
+			t.Fatalf("console log should not be retained, got %v", err)
+		}
+	}
+}
+
+func TestRetainFcLogs(t *testing.T) {
+	cfg := &SandboxConfig{
+		VMTemplate:   config.VMTemplate{TemplateID: "fc", VmmType: config.FIRECRACKER},
+		SandboxID:    "test-sandbox",
+		DataRoot:     t.TempDir(),
+		FcLogLevel:   "Warning",
+		FcMetrics:    true,
+		RetainFcLogs: true,
+	}
+	for _, path := range cfg.fcLogFiles() {
+		if err := utils.CreateFileAndDirIfNotExists(path, 0o644, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(cfg.FcLogPath(), []byte("block device error\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// nothing is moved when keeping the instance dir
+	if err := cfg.retainFcLogs(true); err != nil {
+		t.Fatalf("retain fc logs failed: %s", err)
+	}
+	if _, err := os.Stat(cfg.FcLogPath()); err != nil {
+		t.Fatalf("fc log should be kept in the instance dir, stat err: %v", err)
+	}
+
+	if err := cfg.retainFcLogs(false); err != nil {
+		t.Fatalf("retain fc logs failed: %s", err)
+	}
+	b, err := os.ReadFile(filepath.Join(cfg.RetainedFcLogsDir(), fcLogName))
+	if string(b) != "block device error\n" {
+		t.Fatalf("expect retained fc log, got %q (%v)", b, err)
+	}
+	if _, err := os.Stat(filepath.Join(cfg.RetainedFcLogsDir(), fcMetricsName)); err != nil {
+		t.Fatalf("expect retained fc metrics, stat err: %v", err)
+	}
+
+	// cloud hypervisor has no such files
+	cfg.VmmType = config.CLOUDHYPERVISOR
+	if files := cfg.fcLogFiles(); len(files) != 0 {
+		t.Fatalf("expect no fc log files for cloud hypervisor, got %v", files)
+	}
+}
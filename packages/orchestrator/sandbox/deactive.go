@@ -60,6 +60,7 @@ func (s *Sandbox) Deactive(ctx context.Context, tracer trace.Tracer) error {
 	} else {
 		telemetry.ReportEvent(ctx, "reclaim succeed")
 	}
+	s.emit(orchestrator.SandboxEventType_EVENT_DEACTIVATED)
 	return nil
 }
 
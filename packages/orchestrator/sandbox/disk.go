@@ -0,0 +1,193 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/KarpelesLab/reflink"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+var ErrInvalidExtraDisk = errors.New("invalid extra disk")
+
+// The extra (scratch) disk attached to the sandbox.
+type DiskSpec struct {
+	SizeMB   int64 `json:"sizeMB"`
+	ReadOnly bool  `json:"readOnly,omitempty"`
+	// the file copied (reflink) as the content of disk,
+	// empty means create an empty ext4 filesystem.
+	BackingFile string `json:"backingFile,omitempty"`
+}
+
+// Validate the extra disks against the template, and fill the size
+// of those backed by a file (when not specified). The backing files
+// should be under backingDir, and are replaced by the resolved paths.
+func ValidateExtraDisks(t *config.VMTemplate, disks []DiskSpec, backingDir string) error {
+	if len(disks) == 0 {
+		return nil
+	}
+	if t.VmmType == config.FIRECRACKER && len(disks) > t.ExtraDiskSlots {
+		return fmt.Errorf("%w: template %s only has %d extra disk slots, requested %d",
+			ErrInvalidExtraDisk, t.TemplateID, t.ExtraDiskSlots, len(disks))
+	}
+	if t.VmmType == config.CLOUDHYPERVISOR && len(disks) > consts.MaxExtraDiskSlots {
+		return fmt.Errorf("%w: at most %d extra disks, requested %d",
+			ErrInvalidExtraDisk, consts.MaxExtraDiskSlots, len(disks))
+	}
+	for i := range disks {
+		disk := &disks[i]
+		if disk.ReadOnly && t.VmmType == config.FIRECRACKER {
+			return fmt.Errorf("%w: firecracker does not support read-only extra disk", ErrInvalidExtraDisk)
+		}
+		if disk.SizeMB < 0 {
+			return fmt.Errorf("%w: negative size %d MiB", ErrInvalidExtraDisk, disk.SizeMB)
+		}
+		if disk.BackingFile == "" {
+			if disk.SizeMB == 0 {
+				return fmt.Errorf("%w: size of disk %d is not specified", ErrInvalidExtraDisk, i)
+			}
+			continue
+		}
+		backingFile, err := resolveBackingFile(backingDir, disk.BackingFile)
+		if err != nil {
+			return err
+		}
+		disk.BackingFile = backingFile
+		info, err := os.Stat(disk.BackingFile)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidExtraDisk, err)
+		}
+		if !info.Mode().IsRegular() {
+			return fmt.Errorf("%w: backing file %s is not a regular file", ErrInvalidExtraDisk, disk.BackingFile)
+		}
+		backingSizeMB := (info.Size() + (1 << 20) - 1) >> 20
+		if disk.SizeMB == 0 {
+			disk.SizeMB = backingSizeMB
+		} else if disk.SizeMB < backingSizeMB {
+			return fmt.Errorf("%w: size %d MiB is less than backing file %s (%d MiB)",
+				ErrInvalidExtraDisk, disk.SizeMB, disk.BackingFile, backingSizeMB)
+		}
+	}
+	return nil
+}
+
+// Resolve the backing file (relative to backingDir if not absolute), the file
+// (after following symlinks) should be under backingDir, so that the callers
+// cannot read arbitrary files of the host.
+func resolveBackingFile(backingDir, file string) (string, error) {
+	if backingDir == "" {
+		return "", fmt.Errorf("%w: backing file is not allowed", ErrInvalidExtraDisk)
+	}
+	if slices.Contains(strings.Split(filepath.ToSlash(file), "/"), "..") {
+		return "", fmt.Errorf("%w: backing file %s should not contain ..", ErrInvalidExtraDisk, file)
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(backingDir, file)
+	}
+	resolvedDir, err := filepath.EvalSymlinks(backingDir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidExtraDisk, err)
+	}
+	resolved, err := filepath.EvalSymlinks(file)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidExtraDisk, err)
+	}
+	rel, err := filepath.Rel(resolvedDir, resolved)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%w: backing file %s is not under %s", ErrInvalidExtraDisk, file, backingDir)
+	}
+	return resolved, nil
+}
+
+// The total size of extra disks in MiB.
+func (cfg *SandboxConfig) ExtraDisksSizeMB() int64 {
+	var total int64
+	for _, disk := range cfg.ExtraDisks {
+		total += disk.SizeMB
+	}
+	return total
+}
+
+func (cfg *SandboxConfig) InstanceExtraDiskPath(i int) string {
+	return filepath.Join(cfg.InstancePath(), fmt.Sprintf(consts.ExtraDiskNameFormat, i))
+}
+
+// The number of extra disk files in instance dir, firecracker needs
+// a file for every slot recorded in snapshot.
+func (cfg *SandboxConfig) extraDiskFiles() int {
+	if cfg.VmmType == config.FIRECRACKER {
+		return cfg.ExtraDiskSlots
+	}
+	return len(cfg.ExtraDisks)
+}
+
+// Create the backing files of extra disks in instance dir, which
+// will be bind mounted to the private dir (i.e., PrivateExtraDiskPath).
+func (cfg *SandboxConfig) ensureExtraDisks(ctx context.Context) error {
+	for i := 0; i < cfg.extraDiskFiles(); i++ {
+		path := cfg.InstanceExtraDiskPath(i)
+		if i >= len(cfg.ExtraDisks) {
+			if err := utils.CreateExtraDiskPlaceholder(path); err != nil {
+				return err
+			}
+			continue
+		}
+		disk := cfg.ExtraDisks[i]
+		if disk.BackingFile == "" {
+			if err := utils.CreateScratchDisk(ctx, path, disk.SizeMB); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := reflink.Auto(disk.BackingFile, path); err != nil {
+			return fmt.Errorf("error copying backing file %s: %w", disk.BackingFile, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		// only the device is enlarged, the filesystem
+		// inside (if any) should be resized by the guest.
+		if size := disk.SizeMB << 20; info.Size() < size {
+			if err := os.Truncate(path, size); err != nil {
+				return fmt.Errorf("error enlarging extra disk: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (cfg *SandboxConfig) removeExtraDisks() error {
+	var finalErr error
+	for i := 0; i < cfg.extraDiskFiles(); i++ {
+		if err := os.Remove(cfg.InstanceExtraDiskPath(i)); err != nil && !os.IsNotExist(err) {
+			finalErr = errors.Join(finalErr, err)
+		}
+	}
+	return finalErr
+}
+
+// Attach the extra disks to the restored vm.
+func (vmm vmm) attachExtraDisks(ctx context.Context, cfg *SandboxConfig) error {
+	for i, disk := range cfg.ExtraDisks {
+		id := hypervisor.ExtraDiskID(i)
+		if err := vmm.AttachDisk(ctx, id, cfg.PrivateExtraDiskPath(cfg.DataRoot, i), disk.ReadOnly); err != nil {
+			return err
+		}
+	}
+	if len(cfg.ExtraDisks) > 0 {
+		telemetry.ReportEvent(ctx, "extra disks attached", attribute.Int("count", len(cfg.ExtraDisks)))
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+)
+
+var ErrInvalidDiskRateLimit = errors.New("invalid disk rate limit")
+
+// Validate the disk limits of sandbox which override the ones of template.
+//
+// The limits of template are kept in the snapshot, only firecracker can change
+// the limits of the restored drives, while cloud hypervisor can only apply them
+// when cold booting.
+func ValidateDiskRateLimitOverride(t *config.VMTemplate, override hypervisor.DiskRateLimit, coldBoot bool) error {
+	if override.BandwidthBps < 0 || override.Iops < 0 {
+		return fmt.Errorf("%w: the bandwidth and iops cannot be negative", ErrInvalidDiskRateLimit)
+	}
+	if override.IsZero() || coldBoot || t.VmmType == config.FIRECRACKER {
+		return nil
+	}
+	return fmt.Errorf("%w: %s cannot change the limits of disks restored from snapshot", ErrInvalidDiskRateLimit, t.VmmType)
+}
+
+// The disk limits of sandbox, i.e., the ones of template overridden by
+// DiskRateLimitOverride (the zero fields are not overridden).
+func (cfg *SandboxConfig) diskRateLimit() hypervisor.DiskRateLimit {
+	limit := hypervisor.DiskRateLimit{
+		BandwidthBps: cfg.DiskBandwidthBps,
+		Iops:         cfg.DiskIops,
+	}
+	if cfg.DiskRateLimitOverride.BandwidthBps != 0 {
+		limit.BandwidthBps = cfg.DiskRateLimitOverride.BandwidthBps
+	}
+	if cfg.DiskRateLimitOverride.Iops != 0 {
+		limit.Iops = cfg.DiskRateLimitOverride.Iops
+	}
+	return limit
+}
@@ -0,0 +1,89 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestValidateExtraDisks(t *testing.T) {
+	root := t.TempDir()
+	backingDir := filepath.Join(root, "backing")
+	if err := os.MkdirAll(filepath.Join(backingDir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// 1 MiB + 1 byte, i.e., 2 MiB after rounded up
+	if err := os.WriteFile(filepath.Join(backingDir, "sub", "data.img"), make([]byte, 1<<20+1), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(root, "secret")
+	if err := os.WriteFile(secret, []byte("secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(backingDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(backingDir, "sub", "data.img"), filepath.Join(backingDir, "link")); err != nil {
+		t.Fatal(err)
+	}
+	resolved := filepath.Join(backingDir, "sub", "data.img")
+
+	fc := &config.VMTemplate{TemplateID: "fc", VmmType: config.FIRECRACKER, ExtraDiskSlots: 2}
+	ch := &config.VMTemplate{TemplateID: "ch", VmmType: config.CLOUDHYPERVISOR}
+	testCases := []struct {
+		name       string
+		template   *config.VMTemplate
+		disks      []DiskSpec
+		backingDir string
+		// the expected disks after validated, nil means invalid
+		expected []DiskSpec
+	}{
+		{"no disk", fc, nil, "", []DiskSpec{}},
+		{"scratch", fc, []DiskSpec{{SizeMB: 16}, {SizeMB: 32}}, "", []DiskSpec{{SizeMB: 16}, {SizeMB: 32}}},
+		{"exceed slots", fc, []DiskSpec{{SizeMB: 1}, {SizeMB: 1}, {SizeMB: 1}}, "", nil},
+		{"exceed max disks", ch, make([]DiskSpec, consts.MaxExtraDiskSlots+1), "", nil},
+		{"fc read-only", fc, []DiskSpec{{SizeMB: 16, ReadOnly: true}}, "", nil},
+		{"ch read-only", ch, []DiskSpec{{SizeMB: 16, ReadOnly: true}}, "", []DiskSpec{{SizeMB: 16, ReadOnly: true}}},
+		{"negative size", fc, []DiskSpec{{SizeMB: -1}}, "", nil},
+		{"no size", fc, []DiskSpec{{}}, "", nil},
+		{"relative backing file", ch, []DiskSpec{{BackingFile: "sub/data.img"}}, backingDir, []DiskSpec{{SizeMB: 2, BackingFile: resolved}}},
+		{"absolute backing file", ch, []DiskSpec{{SizeMB: 8, BackingFile: resolved}}, backingDir, []DiskSpec{{SizeMB: 8, BackingFile: resolved}}},
+		{"symlink in backing dir", ch, []DiskSpec{{BackingFile: "link"}}, backingDir, []DiskSpec{{SizeMB: 2, BackingFile: resolved}}},
+		{"smaller than backing file", ch, []DiskSpec{{SizeMB: 1, BackingFile: "sub/data.img"}}, backingDir, nil},
+		{"backing file not allowed", ch, []DiskSpec{{BackingFile: resolved}}, "", nil},
+		{"backing file not exist", ch, []DiskSpec{{BackingFile: "missing.img"}}, backingDir, nil},
+		{"backing file is dir", ch, []DiskSpec{{BackingFile: "sub"}}, backingDir, nil},
+		{"backing file is backing dir", ch, []DiskSpec{{BackingFile: backingDir}}, backingDir, nil},
+		{"parent dir", ch, []DiskSpec{{BackingFile: "../secret"}}, backingDir, nil},
+		{"parent dir inside", ch, []DiskSpec{{BackingFile: "sub/../sub/data.img"}}, backingDir, nil},
+		{"absolute path outside", ch, []DiskSpec{{BackingFile: secret}}, backingDir, nil},
+		{"absolute path with parent dir", ch, []DiskSpec{{BackingFile: backingDir + "/../secret"}}, backingDir, nil},
+		{"symlink escape", ch, []DiskSpec{{BackingFile: "escape"}}, backingDir, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateExtraDisks(tc.template, tc.disks, tc.backingDir)
+			if tc.expected == nil {
+				if !errors.Is(err, ErrInvalidExtraDisk) {
+					t.Fatalf("expect invalid extra disk, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validate extra disks failed: %s", err)
+			}
+			if len(tc.disks) != len(tc.expected) {
+				t.Fatalf("expect %v, got %v", tc.expected, tc.disks)
+			}
+			for i := range tc.expected {
+				if tc.disks[i] != tc.expected[i] {
+					t.Fatalf("expect %v, got %v", tc.expected, tc.disks)
+				}
+			}
+		})
+	}
+}
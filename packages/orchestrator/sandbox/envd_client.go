@@ -0,0 +1,162 @@
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const envdClientTimeout = 10 * time.Second
+
+// Tuning of the transport used to talk with envd inside sandboxes.
+type EnvdClientConfig struct {
+	// 0 means use the default of net/http (i.e., 2)
+	MaxIdleConnsPerHost int
+	// 0 means idle connections are never closed by the client
+	IdleConnTimeout   time.Duration
+	DisableKeepAlives bool
+}
+
+// Create the http client shared by all sandboxes of an orchestrator.
+//
+// Each sandbox is a different host (i.e., its HostClonedIP),
+// so the total number of idle connections is not limited (the default of
+// net/http is 100), otherwise connections are kept closing and re-dialing
+// under high sandbox counts, which may exhaust the ephemeral ports.
+func NewEnvdClient(cfg EnvdClientConfig) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 0
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+	transport.DisableKeepAlives = cfg.DisableKeepAlives
+	return &http.Client{
+		Transport: transport,
+		Timeout:   envdClientTimeout,
+	}
+}
+
+// Used when the config of sandbox does not specify a client.
+var defaultEnvdClient = &http.Client{
+	Timeout: envdClientTimeout,
+}
+
+func (s *Sandbox) envdClient() *http.Client {
+	if s.Config.EnvdClient != nil {
+		return s.Config.EnvdClient
+	}
+	return defaultEnvdClient
+}
+
+// Ask envd to change the mac address of the guest interface.
+func postMAC(ctx context.Context, client *http.Client, address, iface, mac string) error {
+	body, err := json.Marshal(map[string]string{"iface": iface, "mac": mac})
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", address, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("envd failed to set mac address (status %d): %s", response.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if _, err := io.Copy(io.Discard, response.Body); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Ask envd to sync the clock of guest.
+func postSync(ctx context.Context, client *http.Client, address string) error {
+	request, err := http.NewRequestWithContext(ctx, "POST", address, nil)
+	if err != nil {
+		return err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("envd failed to sync clock (status %d): %s", response.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	// NOTE(huang-jl): After reading the body of response, the http client
+	// will reuse the connection
+	if _, err := io.Copy(io.Discard, response.Body); err != nil {
+		return err
+	}
+	return nil
+}
+
+// The result of a command run by envd inside the sandbox.
+type CommandResult struct {
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// Run cmd inside the sandbox by envd (as its default user) and wait for it to exit.
+// It is limited by the timeout of envd client, so only for the short-lived commands.
+func (s *Sandbox) RunCommand(ctx context.Context, cmd string) (*CommandResult, error) {
+	address := fmt.Sprintf("http://%s:%d", s.Net.HostClonedIP(), s.Config.EnvdServerPort())
+	return runCommand(ctx, s.envdClient(), address, cmd)
+}
+
+func runCommand(ctx context.Context, client *http.Client, address, cmd string) (*CommandResult, error) {
+	var created struct {
+		Pid int `json:"pid"`
+	}
+	if err := postJSON(ctx, client, address+"/process/create", map[string]string{"cmd": cmd}, &created); err != nil {
+		return nil, fmt.Errorf("create process failed: %w", err)
+	}
+	var result CommandResult
+	if err := postJSON(ctx, client, address+"/process/wait", map[string]int{"pid": created.Pid}, &result); err != nil {
+		return nil, fmt.Errorf("wait process %d failed: %w", created.Pid, err)
+	}
+	return &result, nil
+}
+
+// Post body (as json) to envd and decode the json response into out.
+func postJSON(ctx context.Context, client *http.Client, address string, body, out any) error {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, "POST", address, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(response.Body, 4096))
+		return fmt.Errorf("envd returned status %d: %s", response.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if err := json.NewDecoder(response.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response failed: %w", err)
+	}
+	return nil
+}
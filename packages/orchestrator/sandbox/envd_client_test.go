@@ -0,0 +1,133 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newSyncServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	var newConns atomic.Int64
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+	return srv, &newConns
+}
+
+func TestEnvdClientReuseConnection(t *testing.T) {
+	srv, newConns := newSyncServer(t)
+	client := NewEnvdClient(EnvdClientConfig{
+		MaxIdleConnsPerHost: 1,
+		IdleConnTimeout:     time.Minute,
+	})
+	for i := 0; i < 10; i++ {
+		if err := postSync(context.Background(), client, srv.URL+"/sync"); err != nil {
+			t.Fatalf("sync failed: %s", err)
+		}
+	}
+	if n := newConns.Load(); n != 1 {
+		t.Fatalf("expect 1 connection, got %d", n)
+	}
+}
+
+func TestEnvdClientDisableKeepAlives(t *testing.T) {
+	srv, newConns := newSyncServer(t)
+	client := NewEnvdClient(EnvdClientConfig{DisableKeepAlives: true})
+	for i := 0; i < 3; i++ {
+		if err := postSync(context.Background(), client, srv.URL+"/sync"); err != nil {
+			t.Fatalf("sync failed: %s", err)
+		}
+	}
+	if n := newConns.Load(); n != 3 {
+		t.Fatalf("expect 3 connections, got %d", n)
+	}
+}
+
+func TestSyncReportsFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "sync clock failed: exit status 1", http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	err := postSync(context.Background(), NewEnvdClient(EnvdClientConfig{}), srv.URL+"/sync")
+	if err == nil || !strings.Contains(err.Error(), "exit status 1") {
+		t.Fatalf("expect error from envd, got %v", err)
+	}
+}
+
+func TestPostMAC(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if got["mac"] == "bad" {
+			http.Error(w, "invalid mac address", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	if err := postMAC(context.Background(), http.DefaultClient, srv.URL+"/net/mac", "eth0", "02:fd:00:00:00:01"); err != nil {
+		t.Fatalf("post mac failed: %s", err)
+	}
+	if got["iface"] != "eth0" || got["mac"] != "02:fd:00:00:00:01" {
+		t.Fatalf("unexpected request: %v", got)
+	}
+	err := postMAC(context.Background(), http.DefaultClient, srv.URL+"/net/mac", "eth0", "bad")
+	if err == nil || !strings.Contains(err.Error(), "invalid mac address") {
+		t.Fatalf("expect the error reported by envd, got %v", err)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/process/create", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req["cmd"] == "bad" {
+			http.Error(w, "create process failed: invalid command", http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(`{"pid": 42}`))
+	})
+	mux.HandleFunc("/process/wait", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]int
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["pid"] != 42 {
+			http.Error(w, "process not found", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"stdout": "hello\n", "stderr": "", "exit_code": 3}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	result, err := runCommand(context.Background(), http.DefaultClient, srv.URL, "echo hello; exit 3")
+	if err != nil {
+		t.Fatalf("run command failed: %s", err)
+	}
+	if result.Stdout != "hello\n" || result.ExitCode != 3 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	_, err = runCommand(context.Background(), http.DefaultClient, srv.URL, "bad")
+	if err == nil || !strings.Contains(err.Error(), "invalid command") {
+		t.Fatalf("expect the error reported by envd, got %v", err)
+	}
+}
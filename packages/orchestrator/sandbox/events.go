@@ -0,0 +1,41 @@
+package sandbox
+
+import (
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// The event emitted when the sandbox transitions into the state, the states
+// not listed (e.g., INVALID) emit no event.
+var stateEvents = map[orchestrator.SandboxState]orchestrator.SandboxEventType{
+	orchestrator.SandboxState_RUNNING:      orchestrator.SandboxEventType_EVENT_RUNNING,
+	orchestrator.SandboxState_SNAPSHOTTING: orchestrator.SandboxEventType_EVENT_SNAPSHOTTING,
+	orchestrator.SandboxState_STOP:         orchestrator.SandboxEventType_EVENT_STOPPED,
+}
+
+// The event of the sandbox in its current state.
+func (s *Sandbox) Event(eventType orchestrator.SandboxEventType) *orchestrator.SandboxEvent {
+	return &orchestrator.SandboxEvent{
+		Type:       eventType,
+		SandboxID:  s.SandboxID(),
+		TemplateID: s.Config.TemplateID,
+		State:      s.State,
+		Time:       timestamppb.Now(),
+	}
+}
+
+// Notify SandboxConfig.OnEvent (if set), which should not block
+// as it is usually called with mu held.
+func (s *Sandbox) emit(eventType orchestrator.SandboxEventType) {
+	if s.Config.OnEvent != nil {
+		s.Config.OnEvent(s.Event(eventType))
+	}
+}
+
+// Should be called with mu held.
+func (s *Sandbox) setState(state orchestrator.SandboxState) {
+	s.State = state
+	if eventType, ok := stateEvents[state]; ok {
+		s.emit(eventType)
+	}
+}
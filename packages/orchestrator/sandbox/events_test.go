@@ -0,0 +1,41 @@
+package sandbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestSandboxEvents(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	var events []*orchestrator.SandboxEvent
+	sbx.Config.OnEvent = func(ev *orchestrator.SandboxEvent) {
+		events = append(events, ev)
+	}
+
+	close(h.release)
+	if err := waitErr(t, startSnapshot(t, sbx, false), "snapshot"); err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+	if err := sbx.Stop(context.Background(), testTracer); err != nil {
+		t.Fatalf("stop failed: %s", err)
+	}
+
+	expected := []struct {
+		eventType orchestrator.SandboxEventType
+		state     orchestrator.SandboxState
+	}{
+		{orchestrator.SandboxEventType_EVENT_SNAPSHOTTING, orchestrator.SandboxState_SNAPSHOTTING},
+		{orchestrator.SandboxEventType_EVENT_RUNNING, orchestrator.SandboxState_RUNNING},
+		{orchestrator.SandboxEventType_EVENT_STOPPED, orchestrator.SandboxState_STOP},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("expect %d events, got %v", len(expected), events)
+	}
+	for i, ev := range events {
+		if ev.Type != expected[i].eventType || ev.State != expected[i].state || ev.SandboxID != sbx.SandboxID() {
+			t.Fatalf("unexpected event %d: %v", i, ev)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The name of the file describing the failure in the dir of failed sandbox.
+const failedInfoName = "failed.json"
+
+// The sandbox failed to create, whose instance dir is kept for post-mortem
+// (see SandboxConfig.KeepFilesOnError).
+type FailedSandbox struct {
+	SandboxID  string `json:"sandboxID"`
+	TemplateID string `json:"templateID"`
+	// the network is kept as well, 0 means the sandbox has no network
+	NetworkIdx int       `json:"networkIdx"`
+	IPv6       bool      `json:"ipv6,omitempty"`
+	Error      string    `json:"error"`
+	FailedAt   time.Time `json:"failedAt"`
+	// where the instance dir is moved to
+	Dir string `json:"-"`
+}
+
+func FailedDir(dataRoot string) string {
+	return filepath.Join(dataRoot, constants.FailedSandboxDirName)
+}
+
+// Move the instance dir (if created) into FailedDir, along with a json
+// describing the cause, instead of removing it. The other files (e.g., socket
+// and cgroup) are still removed, so that the sandbox id can be used again.
+func (cfg *SandboxConfig) keepFailedFiles(
+	ctx context.Context,
+	tracer trace.Tracer,
+	net *network.SandboxNetwork,
+	cause error,
+) error {
+	childCtx, childSpan := tracer.Start(ctx, "keep-failed-files")
+	defer childSpan.End()
+
+	dir := filepath.Join(FailedDir(cfg.DataRoot), fmt.Sprintf("%s-%d", cfg.SandboxID, time.Now().UnixNano()))
+	if err := os.MkdirAll(FailedDir(cfg.DataRoot), 0o755); err != nil {
+		return fmt.Errorf("error creating failed dir: %w", err)
+	}
+	if err := os.Rename(cfg.InstancePath(), dir); err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("error moving instance dir to %s: %w", dir, err)
+		}
+		// failed before the instance dir is created
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			return fmt.Errorf("error creating %s: %w", dir, err)
+		}
+	}
+	info := FailedSandbox{
+		SandboxID:  cfg.SandboxID,
+		TemplateID: cfg.TemplateID,
+		NetworkIdx: net.NetworkIdx(),
+		IPv6:       net.IPv6Enabled(),
+		Error:      cause.Error(),
+		FailedAt:   time.Now(),
+	}
+	b, err := json.MarshalIndent(&info, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal failed sandbox failed: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, failedInfoName), b, 0o644); err != nil {
+		return fmt.Errorf("write failed sandbox (%s) failed: %w", dir, err)
+	}
+	telemetry.ReportEvent(childCtx, "kept files of failed sandbox", attribute.String("dir", dir))
+
+	// the instance dir has been moved
+	return cfg.CleanupFiles(childCtx, tracer, false)
+}
+
+// Read all failed sandboxes under dataRoot.
+func ListFailedSandboxes(dataRoot string) ([]*FailedSandbox, error) {
+	entries, err := os.ReadDir(FailedDir(dataRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var (
+		results  []*FailedSandbox
+		finalErr error
+	)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(FailedDir(dataRoot), entry.Name())
+		b, err := os.ReadFile(filepath.Join(dir, failedInfoName))
+		if err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("read %s failed: %w", dir, err))
+			continue
+		}
+		var info FailedSandbox
+		if err := json.Unmarshal(b, &info); err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("decode %s failed: %w", dir, err))
+			continue
+		}
+		info.Dir = dir
+		results = append(results, &info)
+	}
+	return results, finalErr
+}
@@ -0,0 +1,54 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+)
+
+func TestKeepFailedFiles(t *testing.T) {
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{TemplateID: "failed"},
+		SandboxID:  "test-sandbox",
+		DataRoot:   t.TempDir(),
+	}
+	if err := os.MkdirAll(cfg.InstancePath(), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.InstancePath(), "fc.log"), []byte("boom"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the removal of cgroup etc. may fail in the test environment
+	_ = cfg.keepFailedFiles(context.Background(), testTracer, &network.SandboxNetwork{}, errors.New("boom"))
+
+	if _, err := os.Stat(cfg.InstancePath()); !os.IsNotExist(err) {
+		t.Fatalf("instance dir should be moved, stat: %v", err)
+	}
+	failed, err := ListFailedSandboxes(cfg.DataRoot)
+	if err != nil {
+		t.Fatalf("list failed sandboxes failed: %s", err)
+	}
+	if len(failed) != 1 {
+		t.Fatalf("expect 1 failed sandbox, got %d", len(failed))
+	}
+	info := failed[0]
+	if info.SandboxID != cfg.SandboxID || info.TemplateID != cfg.TemplateID || info.Error != "boom" {
+		t.Fatalf("unexpected failed sandbox: %+v", info)
+	}
+	if b, err := os.ReadFile(filepath.Join(info.Dir, "fc.log")); err != nil || string(b) != "boom" {
+		t.Fatalf("the instance files should be kept, got %q (%v)", b, err)
+	}
+}
+
+func TestListFailedSandboxesEmpty(t *testing.T) {
+	failed, err := ListFailedSandboxes(t.TempDir())
+	if err != nil || len(failed) != 0 {
+		t.Fatalf("expect no failed sandbox, got %v (%v)", failed, err)
+	}
+}
@@ -0,0 +1,142 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	"github.com/KarpelesLab/reflink"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+const (
+	// the paths inside the chroot of jailer
+	jailedSocketPath  = "/run/firecracker.socket"
+	jailedSnapshotDir = "/snapshot"
+)
+
+// Run the vmm as an unprivileged user. Firecracker is run under its jailer
+// (i.e., chroot, uid/gid drop and a new mount ns), while cloud hypervisor is
+// run by setpriv (i.e., uid/gid drop only). Both rely on the builtin seccomp
+// filters of the hypervisor.
+type JailerOptions struct {
+	// the jailer binary of firecracker
+	BinaryPath string
+	// which should be able to access /dev/kvm (e.g., the kvm group)
+	UID int
+	GID int
+	// the chroot of sandbox is ${ChrootBaseDir}/firecracker/${SandboxID}/root
+	ChrootBaseDir string
+}
+
+// Whether the vmm runs inside the chroot of jailer.
+func (cfg *SandboxConfig) chrooted() bool {
+	return cfg.Jailer != nil && cfg.VmmType == config.FIRECRACKER
+}
+
+// The chroot created by jailer, whose layout is decided by jailer.
+func (cfg *SandboxConfig) JailerChrootDir() string {
+	return filepath.Join(
+		cfg.Jailer.ChrootBaseDir,
+		filepath.Base(cfg.HypervisorBinaryPath),
+		cfg.SandboxID,
+		"root",
+	)
+}
+
+// The path (on host) of the api socket of firecracker run by jailer.
+func (cfg *SandboxConfig) JailerSocketPath() string {
+	return cfg.jailedPath(jailedSocketPath)
+}
+
+// The path (on host) of a path inside the chroot of jailer.
+func (cfg *SandboxConfig) jailedPath(path string) string {
+	return filepath.Join(cfg.JailerChrootDir(), path)
+}
+
+// The bind mounts (src -> dst on host) making the files used by firecracker
+// visible inside the chroot at the same paths as outside (e.g., the ones
+// recorded in the snapshot), so that the paths need not to be translated.
+func (cfg *SandboxConfig) jailMounts() [][2]string {
+	return [][2]string{
+		{cfg.InstancePath(), cfg.jailedPath(cfg.PrivateDir(cfg.DataRoot))},
+		{cfg.HostKernelImagePath(), cfg.jailedPath(cfg.PrivateKernelPath(cfg.DataRoot))},
+		{cfg.TemplateImgDir(cfg.DataRoot), cfg.jailedPath(cfg.TemplateImgDir(cfg.DataRoot))},
+		// e.g., where the memfile is decompressed to
+		{cfg.InstancePath(), cfg.jailedPath(cfg.InstancePath())},
+	}
+}
+
+// Hand the files written by vmm to the unprivileged user, and create the
+// mount points (and the dirs written by firecracker) inside the chroot.
+func (cfg *SandboxConfig) prepareJail() error {
+	j := cfg.Jailer
+	if err := chownAll(cfg.InstancePath(), j.UID, j.GID); err != nil {
+		return fmt.Errorf("error chown instance dir: %w", err)
+	}
+	if !cfg.chrooted() {
+		return nil
+	}
+	for _, mount := range cfg.jailMounts() {
+		// the kernel is mounted onto a file in the (mounted) instance dir
+		if mount[0] == cfg.HostKernelImagePath() {
+			continue
+		}
+		if err := os.MkdirAll(mount[1], 0o755); err != nil {
+			return fmt.Errorf("error creating mount point in chroot: %w", err)
+		}
+	}
+	for _, dir := range []string{filepath.Dir(jailedSocketPath), jailedSnapshotDir} {
+		if err := os.MkdirAll(cfg.jailedPath(dir), 0o755); err != nil {
+			return fmt.Errorf("error creating %s in chroot: %w", dir, err)
+		}
+		if err := os.Chown(cfg.jailedPath(dir), j.UID, j.GID); err != nil {
+			return fmt.Errorf("error chown %s in chroot: %w", dir, err)
+		}
+	}
+	return nil
+}
+
+// Remove the chroot of jailer, the mounts inside are only visible in the
+// mount ns of vmm, so only the (empty) mount points are removed.
+func (cfg *SandboxConfig) removeJail() error {
+	if !cfg.chrooted() {
+		return nil
+	}
+	return os.RemoveAll(filepath.Dir(cfg.JailerChrootDir()))
+}
+
+func chownAll(root string, uid, gid int) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+}
+
+// Move the files of snapshot from src into dst, falling back to copy
+// when they are on different filesystems (e.g., snapshot_root).
+func moveSnapshotFiles(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	var finalErr error
+	for _, entry := range entries {
+		from, to := filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())
+		err := os.Rename(from, to)
+		if errors.Is(err, syscall.EXDEV) {
+			if err = reflink.Auto(from, to); err == nil {
+				err = os.Remove(from)
+			}
+		}
+		if err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("error moving %s: %w", from, err))
+		}
+	}
+	return finalErr
+}
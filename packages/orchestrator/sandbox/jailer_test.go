@@ -0,0 +1,78 @@
+package sandbox
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+)
+
+func TestJailerCmd(t *testing.T) {
+	// jailer needs the absolute path of firecracker
+	fcPath := filepath.Join(t.TempDir(), "firecracker")
+	if err := os.WriteFile(fcPath, nil, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &SandboxConfig{
+		VMTemplate:           config.VMTemplate{TemplateID: "jailer", VmmType: config.FIRECRACKER},
+		SandboxID:            "test-sandbox",
+		DataRoot:             t.TempDir(),
+		HypervisorBinaryPath: fcPath,
+		Jailer: &JailerOptions{
+			BinaryPath:    "jailer",
+			UID:           123,
+			GID:           456,
+			ChrootBaseDir: "/srv/jailer",
+		},
+	}
+	_, subnet, _ := net.ParseCIDR("10.168.0.0/16")
+	sbxNet := network.NewSandboxNetwork(network.NewNetworkEnv(1, subnet), cfg.SandboxID)
+	cmd, err := jailerCmd(cfg, &sbxNet)
+	if err != nil {
+		t.Fatalf("jailer cmd failed: %s", err)
+	}
+	for _, arg := range []string{
+		"--id 'test-sandbox'",
+		"--exec-file '" + fcPath + "'",
+		"--uid 123 --gid 456",
+		"--chroot-base-dir '/srv/jailer'",
+		"--netns '/var/run/netns/" + sbxNet.NetNsName() + "'",
+		"-- --api-sock " + jailedSocketPath,
+	} {
+		if !strings.Contains(cmd, arg) {
+			t.Fatalf("expect %q in %q", arg, cmd)
+		}
+	}
+	if expect := "/srv/jailer/firecracker/test-sandbox/root/run/firecracker.socket"; cfg.JailerSocketPath() != expect {
+		t.Fatalf("expect socket path %s, got %s", expect, cfg.JailerSocketPath())
+	}
+
+	cfg.ExtraHypervisorArgs = []string{"--api-sock=/tmp/other.sock"}
+	if _, err := jailerCmd(cfg, &sbxNet); err == nil {
+		t.Fatalf("expect error when overriding the api socket")
+	}
+}
+
+func TestMoveSnapshotFiles(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	for _, name := range []string{"memfile", "snapfile"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := moveSnapshotFiles(src, dst); err != nil {
+		t.Fatalf("move snapshot files failed: %s", err)
+	}
+	for _, name := range []string{"memfile", "snapfile"} {
+		if b, err := os.ReadFile(filepath.Join(dst, name)); err != nil || string(b) != name {
+			t.Fatalf("expect %s moved, got %q (%v)", name, b, err)
+		}
+		if _, err := os.Stat(filepath.Join(src, name)); !os.IsNotExist(err) {
+			t.Fatalf("expect %s removed from src, got %v", name, err)
+		}
+	}
+}
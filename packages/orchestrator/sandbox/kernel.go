@@ -0,0 +1,43 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var ErrInvalidKernelOverride = errors.New("invalid kernel image override")
+
+// Validate the kernel image which overrides the one of template.
+//
+// The kernel is restored along with the memory of snapshot, so the image is
+// only loaded (and the override only makes sense) when cold booting.
+func ValidateKernelImagePathOverride(path string, coldBoot bool) error {
+	if path == "" {
+		return nil
+	}
+	if !coldBoot {
+		return fmt.Errorf("%w: the kernel of snapshot cannot be changed, cold boot is required", ErrInvalidKernelOverride)
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%w: %s is not an absolute path", ErrInvalidKernelOverride, path)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidKernelOverride, err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%w: %s is not a regular file", ErrInvalidKernelOverride, path)
+	}
+	return nil
+}
+
+// The kernel image (on host) bind mounted into PrivateKernelPath, i.e.,
+// KernelImagePathOverride if it is set, otherwise the one of template.
+func (cfg *SandboxConfig) HostKernelImagePath() string {
+	if cfg.KernelImagePathOverride != "" {
+		return cfg.KernelImagePathOverride
+	}
+	return cfg.HostKernelPath(cfg.DataRoot)
+}
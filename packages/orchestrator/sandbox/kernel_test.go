@@ -0,0 +1,52 @@
+package sandbox
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+func TestValidateKernelImagePathOverride(t *testing.T) {
+	dir := t.TempDir()
+	kernel := filepath.Join(dir, "vmlinux")
+	if err := os.WriteFile(kernel, []byte("kernel"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateKernelImagePathOverride("", false); err != nil {
+		t.Fatalf("expect no override to be valid, got %s", err)
+	}
+	if err := ValidateKernelImagePathOverride(kernel, true); err != nil {
+		t.Fatalf("expect %s to be valid, got %s", kernel, err)
+	}
+	for _, tc := range []struct {
+		path     string
+		coldBoot bool
+	}{
+		{kernel, false},
+		{"vmlinux", true},
+		{filepath.Join(dir, "missing"), true},
+		{dir, true},
+	} {
+		if err := ValidateKernelImagePathOverride(tc.path, tc.coldBoot); !errors.Is(err, ErrInvalidKernelOverride) {
+			t.Fatalf("expect invalid override of %s (cold boot: %v), got %v", tc.path, tc.coldBoot, err)
+		}
+	}
+}
+
+func TestHostKernelImagePath(t *testing.T) {
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{TemplateID: "kernel", KernelVersion: "6.1"},
+		DataRoot:   "/data",
+	}
+	if path := cfg.HostKernelImagePath(); path != cfg.HostKernelPath(cfg.DataRoot) {
+		t.Fatalf("expect the kernel of template, got %s", path)
+	}
+	cfg.KernelImagePathOverride = "/custom/vmlinux"
+	if path := cfg.HostKernelImagePath(); path != "/custom/vmlinux" {
+		t.Fatalf("expect the overridden kernel, got %s", path)
+	}
+}
@@ -0,0 +1,51 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// The timeout of applying the mac address of guest when creating sandbox.
+const guestMACTimeout = 10 * time.Second
+
+// The mac address of guest in the config of hypervisor, which only takes
+// effect when booting (i.e., building template), as the restored vm keeps the
+// one recorded in the snapshot (see applyGuestMAC).
+func (cfg *SandboxConfig) guestMAC() string {
+	if cfg.GuestMAC != "" {
+		return cfg.GuestMAC
+	}
+	return consts.GuestMacAddress
+}
+
+// Ask envd to change the mac address of guest interface into Config.GuestMAC
+// (retrying until envd is reachable), then flush the neighbor entries of the
+// tap device, which still point to the mac of template.
+//
+// The ip of guest is unchanged, so the ip= boot args of template still hold.
+func (s *Sandbox) applyGuestMAC(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, guestMACTimeout)
+	defer cancel()
+	address := fmt.Sprintf("http://%s:%d/net/mac", s.Net.HostClonedIP(), s.Config.EnvdServerPort())
+	for {
+		err := postMAC(ctx, s.envdClient(), address, consts.GuestIfaceName, s.Config.GuestMAC)
+		if err == nil {
+			break
+		}
+		telemetry.ReportError(ctx, fmt.Errorf("error setting guest mac: %w", err))
+		select {
+		case <-time.After(clockSyncRetryInterval):
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+	if err := s.Net.FlushTapNeigh(); err != nil {
+		return fmt.Errorf("error flushing neighbors of tap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+var ErrInvalidMemoryOverride = errors.New("invalid memory override")
+
+// Validate the memory (in MiB) of sandbox which overrides the one of template.
+//
+// The snapshot always restores the boot memory of template, so only cloud
+// hypervisor can grow it (up to mem_hotplug_mb more) by virtio-mem after
+// restored. Shrinking is not supported, as the boot memory cannot be unplugged,
+// inflate the balloon instead.
+func ValidateMemoryOverride(t *config.VMTemplate, overrideMB int64) error {
+	if overrideMB == 0 || overrideMB == t.MemoryMB {
+		return nil
+	}
+	hostMB, err := utils.HostMemoryMB()
+	if err != nil {
+		return err
+	}
+	return validateMemoryOverride(t, overrideMB, hostMB)
+}
+
+func validateMemoryOverride(t *config.VMTemplate, overrideMB, hostMB int64) error {
+	if overrideMB == 0 || overrideMB == t.MemoryMB {
+		return nil
+	}
+	if t.VmmType != config.CLOUDHYPERVISOR {
+		return fmt.Errorf("%w: %s cannot resize memory of snapshot (%d MiB)", ErrInvalidMemoryOverride, t.VmmType, t.MemoryMB)
+	}
+	if overrideMB < t.MemoryMB {
+		return fmt.Errorf("%w: %d MiB is less than %d MiB of snapshot, use balloon instead", ErrInvalidMemoryOverride, overrideMB, t.MemoryMB)
+	}
+	if maxMB := t.MemoryMB + t.MemoryHotplugMB; overrideMB > maxMB {
+		return fmt.Errorf("%w: %d MiB exceeds %d MiB (mem_mb + mem_hotplug_mb) of template", ErrInvalidMemoryOverride, overrideMB, maxMB)
+	}
+	if size := t.HugePage().Bytes(); size != 0 && (overrideMB<<20)%size != 0 {
+		return fmt.Errorf("%w: %d MiB is not aligned to huge page %s", ErrInvalidMemoryOverride, overrideMB, t.HugePage())
+	}
+	if overrideMB > hostMB {
+		return fmt.Errorf("%w: %d MiB exceeds %d MiB of host", ErrInvalidMemoryOverride, overrideMB, hostMB)
+	}
+	return nil
+}
+
+// The memory (in MiB) of guest after restored, i.e., MemoryOverrideMB
+// if it is set, otherwise the MemoryMB of template.
+func (cfg *SandboxConfig) GuestMemoryMB() int64 {
+	if cfg.MemoryOverrideMB > 0 {
+		return cfg.MemoryOverrideMB
+	}
+	return cfg.MemoryMB
+}
+
+// Grow the memory of restored vm to MemoryOverrideMB, if any.
+func (vmm vmm) resizeMemory(ctx context.Context, cfg *SandboxConfig) error {
+	if cfg.GuestMemoryMB() == cfg.MemoryMB {
+		return nil
+	}
+	resizer, ok := vmm.Hypervisor.(hypervisor.MemoryResizer)
+	if !ok {
+		return fmt.Errorf("%w: %s cannot resize memory", ErrInvalidMemoryOverride, cfg.VmmType)
+	}
+	if err := resizer.ResizeMemory(ctx, cfg.GuestMemoryMB()); err != nil {
+		return fmt.Errorf("resize memory to %d MiB failed: %w", cfg.GuestMemoryMB(), err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package sandbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+func TestValidateMemoryOverride(t *testing.T) {
+	ch := &config.VMTemplate{VmmType: config.CLOUDHYPERVISOR, MemoryMB: 1024, MemoryHotplugMB: 1024}
+	fc := &config.VMTemplate{VmmType: config.FIRECRACKER, MemoryMB: 1024}
+	hugepage := &config.VMTemplate{VmmType: config.CLOUDHYPERVISOR, MemoryMB: 1024, MemoryHotplugMB: 1024, HugePageSize: config.HugePage2M}
+	testCases := []struct {
+		name       string
+		t          *config.VMTemplate
+		overrideMB int64
+		valid      bool
+	}{
+		{"not set", fc, 0, true},
+		{"same as template", fc, 1024, true},
+		{"firecracker", fc, 2048, false},
+		{"grow", ch, 1536, true},
+		{"grow to max", ch, 2048, true},
+		{"exceed hotplug", ch, 2049, false},
+		{"shrink", ch, 512, false},
+		{"unaligned huge page", hugepage, 1025, false},
+		{"aligned huge page", hugepage, 1026, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateMemoryOverride(tc.t, tc.overrideMB, 2048)
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidMemoryOverride) {
+				t.Fatalf("expect ErrInvalidMemoryOverride, got %v", err)
+			}
+		})
+	}
+
+	// the host check comes last
+	if err := validateMemoryOverride(ch, 2048, 1536); !errors.Is(err, ErrInvalidMemoryOverride) {
+		t.Fatalf("expect exceeding host to be invalid, got %v", err)
+	}
+}
+
+func TestGuestMemoryMB(t *testing.T) {
+	cfg := &SandboxConfig{VMTemplate: config.VMTemplate{MemoryMB: 1024}}
+	if cfg.GuestMemoryMB() != 1024 {
+		t.Fatalf("expect memory of template, got %d", cfg.GuestMemoryMB())
+	}
+	cfg.MemoryOverrideMB = 2048
+	if cfg.GuestMemoryMB() != 2048 {
+		t.Fatalf("expect overridden memory, got %d", cfg.GuestMemoryMB())
+	}
+}
@@ -0,0 +1,107 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+const (
+	MaxMetadataKeySize   = 256
+	MaxMetadataValueSize = 4096
+	// the total size of keys and values, as the metadata is passed into guest
+	// through mmds (see getFcConfig), whose data store is limited to 51200
+	// bytes by default in firecracker.
+	MaxMetadataSize = 32 << 10
+)
+
+var (
+	ErrInvalidMetadata = errors.New("invalid metadata")
+	// The metadata cannot be read by the guest, i.e., cloud hypervisor
+	// (which lacks mmds) without the config drive.
+	ErrGuestMetadataUnsupported = errors.New("metadata unsupported by guest")
+)
+
+// Validate the metadata specified when creating the sandbox.
+func ValidateMetadata(metadata map[string]string) error {
+	if err := validateMetadata(metadata); err != nil {
+		return err
+	}
+	return validateMetadataSize(metadata)
+}
+
+// The guest reads the metadata from mmds with firecracker (see getFcConfig),
+// otherwise from the config drive (see utils.ConfigDriveMetadataName), so
+// the metadata would be dropped silently without either.
+func (cfg *SandboxConfig) ValidateGuestMetadata() error {
+	if len(cfg.Metadata) == 0 || cfg.VmmType == config.FIRECRACKER || cfg.ConfigDrive {
+		return nil
+	}
+	return fmt.Errorf("%w: %s lacks mmds and template %s does not enable config drive", ErrGuestMetadataUnsupported, cfg.VmmType, cfg.TemplateID)
+}
+
+func validateMetadataSize(metadata map[string]string) error {
+	size := 0
+	for k, v := range metadata {
+		size += len(k) + len(v)
+	}
+	if size > MaxMetadataSize {
+		return fmt.Errorf("%w: total size %d exceeds %d bytes", ErrInvalidMetadata, size, MaxMetadataSize)
+	}
+	return nil
+}
+
+func validateMetadata(metadata map[string]string) error {
+	for k, v := range metadata {
+		if len(k) == 0 {
+			return fmt.Errorf("%w: key cannot be empty", ErrInvalidMetadata)
+		}
+		if len(k) > MaxMetadataKeySize {
+			return fmt.Errorf("%w: key %.32q... exceeds %d bytes", ErrInvalidMetadata, k, MaxMetadataKeySize)
+		}
+		if len(v) > MaxMetadataValueSize {
+			return fmt.Errorf("%w: value of key %q exceeds %d bytes", ErrInvalidMetadata, k, MaxMetadataValueSize)
+		}
+	}
+	return nil
+}
+
+// Metadata of the sandbox. The returned map should not be modified.
+func (s *Sandbox) Metadata() map[string]string {
+	s.metadataMu.RLock()
+	defer s.metadataMu.RUnlock()
+	return s.Config.Metadata
+}
+
+// SetMetadata merges metadata into the metadata of sandbox (or replaces it
+// when replace is true), and returns the updated metadata.
+//
+// It only changes the metadata seen by orchestrator, the one in mmds (i.e.,
+// read by guest) is still the one specified when creating the sandbox.
+//
+// The map is copied on write, so that readers (e.g., List)
+// can use the map returned by Metadata() without holding the lock.
+func (s *Sandbox) SetMetadata(metadata map[string]string, replace bool) (map[string]string, error) {
+	if err := validateMetadata(metadata); err != nil {
+		return nil, err
+	}
+	s.metadataMu.Lock()
+	defer s.metadataMu.Unlock()
+
+	updated := make(map[string]string, len(s.Config.Metadata)+len(metadata))
+	if !replace {
+		maps.Copy(updated, s.Config.Metadata)
+	}
+	maps.Copy(updated, metadata)
+	if err := validateMetadataSize(updated); err != nil {
+		return nil, err
+	}
+	// persist first, so the metadata is not lost after orchestrator restarts
+	if err := s.persist(updated); err != nil {
+		return nil, err
+	}
+	s.Config.Metadata = updated
+	return updated, nil
+}
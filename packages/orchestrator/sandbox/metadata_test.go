@@ -0,0 +1,113 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	testCases := []struct {
+		name     string
+		metadata map[string]string
+		valid    bool
+	}{
+		{"empty", nil, true},
+		{"valid", map[string]string{"owner": "test", "empty-value": ""}, true},
+		{"max size", map[string]string{strings.Repeat("k", MaxMetadataKeySize): strings.Repeat("v", MaxMetadataValueSize)}, true},
+		{"empty key", map[string]string{"": "value"}, false},
+		{"key too large", map[string]string{strings.Repeat("k", MaxMetadataKeySize+1): "value"}, false},
+		{"value too large", map[string]string{"key": strings.Repeat("v", MaxMetadataValueSize+1)}, false},
+		{"total too large", largeMetadata(MaxMetadataSize/MaxMetadataValueSize + 1), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateMetadata(tc.metadata)
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, ErrInvalidMetadata) {
+				t.Fatalf("expect invalid metadata, got %v", err)
+			}
+		})
+	}
+}
+
+// n entries whose values are of the max size
+func largeMetadata(n int) map[string]string {
+	metadata := make(map[string]string, n)
+	for i := range n {
+		metadata[fmt.Sprintf("key-%d", i)] = strings.Repeat("v", MaxMetadataValueSize)
+	}
+	return metadata
+}
+
+func TestSetMetadata(t *testing.T) {
+	sbx, _ := newTestSandbox(t, false)
+	sbx.Config.DataRoot = t.TempDir()
+	sbx.Config.Metadata = map[string]string{"owner": "test", "env": "dev"}
+	if err := os.MkdirAll(registryDir(sbx.Config.DataRoot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// the map returned before should not be modified
+	old := sbx.Metadata()
+
+	expectMetadata := func(expected map[string]string) {
+		t.Helper()
+		if !maps.Equal(sbx.Metadata(), expected) {
+			t.Fatalf("expect metadata %v, got %v", expected, sbx.Metadata())
+		}
+		states, err := ListPersistedSandboxes(sbx.Config.DataRoot)
+		if err != nil || len(states) != 1 {
+			t.Fatalf("expect 1 persisted sandbox, got %v (err: %v)", states, err)
+		}
+		if !maps.Equal(states[0].Metadata, expected) {
+			t.Fatalf("expect persisted metadata %v, got %v", expected, states[0].Metadata)
+		}
+	}
+
+	merged := map[string]string{"owner": "test", "env": "prod", "team": "infra"}
+	updated, err := sbx.SetMetadata(map[string]string{"env": "prod", "team": "infra"}, false)
+	if err != nil {
+		t.Fatalf("merge metadata failed: %s", err)
+	}
+	if !maps.Equal(updated, merged) {
+		t.Fatalf("expect returned metadata %v, got %v", merged, updated)
+	}
+	expectMetadata(merged)
+	if !maps.Equal(old, map[string]string{"owner": "test", "env": "dev"}) {
+		t.Fatalf("the old metadata should not be modified, got %v", old)
+	}
+
+	replaced := map[string]string{"owner": "other"}
+	if _, err := sbx.SetMetadata(replaced, true); err != nil {
+		t.Fatalf("replace metadata failed: %s", err)
+	}
+	expectMetadata(replaced)
+
+	// invalid metadata is rejected as a whole
+	if _, err := sbx.SetMetadata(map[string]string{"env": "prod", "": "empty"}, false); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expect invalid metadata, got %v", err)
+	}
+	expectMetadata(replaced)
+
+	// the merged metadata exceeds the total size
+	if _, err := sbx.SetMetadata(largeMetadata(MaxMetadataSize/MaxMetadataValueSize+1), false); !errors.Is(err, ErrInvalidMetadata) {
+		t.Fatalf("expect invalid metadata for the total size, got %v", err)
+	}
+	expectMetadata(replaced)
+
+	// not updated when failed to persist
+	if err := os.RemoveAll(registryDir(sbx.Config.DataRoot)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sbx.SetMetadata(map[string]string{"env": "prod"}, false); err == nil {
+		t.Fatal("expect error when failed to persist")
+	}
+	if !maps.Equal(sbx.Metadata(), replaced) {
+		t.Fatalf("metadata should not be updated, got %v", sbx.Metadata())
+	}
+}
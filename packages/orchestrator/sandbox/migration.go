@@ -0,0 +1,102 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const migrationURLScheme = "unix:"
+
+// Returned when migrating the sandbox whose hypervisor does not support
+// live migration (i.e., firecracker).
+var ErrMigrationUnsupported = errors.New("live migration is not supported by the hypervisor")
+
+var ErrInvalidMigrationURL = errors.New("invalid migration url")
+
+// The url should be unix:<absolute path>. As the vmm runs in the netns of
+// sandbox, it cannot listen on (or connect to) other hosts directly, the
+// caller should forward the unix socket between hosts (e.g., by socat).
+func ValidateMigrationURL(url string) error {
+	path, ok := strings.CutPrefix(url, migrationURLScheme)
+	if !ok {
+		return fmt.Errorf("%w: %q should start with %q", ErrInvalidMigrationURL, url, migrationURLScheme)
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("%w: %q should be an absolute path", ErrInvalidMigrationURL, path)
+	}
+	return nil
+}
+
+// Live migrate the running vm to the receiver listening on destinationURL
+// (see ValidateMigrationURL). The vm is stopped after migrated successfully,
+// otherwise it keeps running.
+//
+// Only the vm state (memory and devices) is migrated, the network state of
+// host (e.g., the connections to the sandbox) and the contents of disks
+// are the responsibility of caller.
+func (s *Sandbox) MigrateSend(ctx context.Context, tracer trace.Tracer, destinationURL string) error {
+	childCtx, childSpan := tracer.Start(ctx, "sandbox-migrate-send", trace.WithAttributes(
+		attribute.String("migration.destination_url", destinationURL),
+	))
+	defer childSpan.End()
+
+	migrator, ok := s.vmm.Hypervisor.(hypervisor.Migrator)
+	if !ok {
+		return ErrMigrationUnsupported
+	}
+	// wait for the snapshot in progress like Stop
+	if err := s.lockForSnapshot(childCtx); err != nil {
+		telemetry.ReportError(childCtx, fmt.Errorf("error during migrate: %w", err))
+		return err
+	}
+	defer s.mu.Unlock()
+	if s.stopping.Load() {
+		return ErrSandboxStopping
+	}
+	if s.State != orchestrator.SandboxState_RUNNING {
+		err := fmt.Errorf("%w: cannot migrate in state %s", InvalidSandboxState, s.State)
+		telemetry.ReportError(childCtx, err)
+		return err
+	}
+
+	s.setState(orchestrator.SandboxState_MIGRATING)
+	if err := migrator.SendMigration(childCtx, destinationURL); err != nil {
+		// cloud hypervisor resumes the vm when the migration fails
+		s.setState(orchestrator.SandboxState_RUNNING)
+		return err
+	}
+	s.stopping.Store(true)
+	s.setState(orchestrator.SandboxState_STOP)
+	// the vmm exits by itself after migrated, kill it in case it does not
+	if err := s.vmm.proc.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		telemetry.ReportError(childCtx, fmt.Errorf("error stopping vmm after migrated: %w", err))
+	}
+	telemetry.ReportEvent(childCtx, "sandbox migrated")
+	return nil
+}
+
+// Receive the vm from the sender instead of restoring from the template.
+func (vmm vmm) receiveMigration(ctx context.Context, tracer trace.Tracer, cfg *SandboxConfig) error {
+	childCtx, childSpan := tracer.Start(ctx, "receive-migration", trace.WithAttributes(
+		attribute.String("migration.receiver_url", cfg.MigrationReceiverURL),
+	))
+	defer childSpan.End()
+
+	migrator, ok := vmm.Hypervisor.(hypervisor.Migrator)
+	if !ok {
+		return ErrMigrationUnsupported
+	}
+	// the extra disks are part of the migrated vm config, so they are not
+	// attached again.
+	return migrator.ReceiveMigration(childCtx, cfg.MigrationReceiverURL)
+}
@@ -0,0 +1,117 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+// fakeMigrator is a fakeHypervisor supporting live migration.
+type fakeMigrator struct {
+	*fakeHypervisor
+	sendErr        error
+	destinationURL string
+}
+
+func (h *fakeMigrator) SendMigration(ctx context.Context, destinationURL string) error {
+	h.destinationURL = destinationURL
+	return h.sendErr
+}
+
+func (h *fakeMigrator) ReceiveMigration(ctx context.Context, receiverURL string) error {
+	return nil
+}
+
+func TestValidateMigrationURL(t *testing.T) {
+	testCases := []struct {
+		url   string
+		valid bool
+	}{
+		{"unix:/run/migration.sock", true},
+		{"unix:migration.sock", false},
+		{"unix:", false},
+		{"tcp:10.0.0.1:6000", false},
+		{"/run/migration.sock", false},
+		{"", false},
+	}
+	for _, tc := range testCases {
+		err := ValidateMigrationURL(tc.url)
+		if tc.valid && err != nil {
+			t.Fatalf("expect %q valid, got %s", tc.url, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidMigrationURL) {
+			t.Fatalf("expect %q invalid, got %v", tc.url, err)
+		}
+	}
+}
+
+func TestMigrateSendUnsupported(t *testing.T) {
+	sbx, _ := newTestSandbox(t, false)
+	err := sbx.MigrateSend(context.Background(), testTracer, "unix:/run/migration.sock")
+	if !errors.Is(err, ErrMigrationUnsupported) {
+		t.Fatalf("expect ErrMigrationUnsupported, got %v", err)
+	}
+	if sbx.State != orchestrator.SandboxState_RUNNING {
+		t.Fatalf("expect state RUNNING, got %s", sbx.State)
+	}
+}
+
+func TestMigrateSend(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	migrator := &fakeMigrator{fakeHypervisor: h, sendErr: errors.New("connection refused")}
+	sbx.vmm.Hypervisor = migrator
+
+	// the vm keeps running when the migration fails
+	if err := sbx.MigrateSend(context.Background(), testTracer, "unix:/run/migration.sock"); err == nil {
+		t.Fatal("expect migration to fail")
+	}
+	if sbx.State != orchestrator.SandboxState_RUNNING {
+		t.Fatalf("expect state RUNNING, got %s", sbx.State)
+	}
+
+	migrator.sendErr = nil
+	if err := sbx.MigrateSend(context.Background(), testTracer, "unix:/run/migration.sock"); err != nil {
+		t.Fatalf("migrate failed: %s", err)
+	}
+	if migrator.destinationURL != "unix:/run/migration.sock" {
+		t.Fatalf("unexpected destination url %q", migrator.destinationURL)
+	}
+	if sbx.State != orchestrator.SandboxState_STOP {
+		t.Fatalf("expect state STOP, got %s", sbx.State)
+	}
+	// the vmm is killed
+	waitCh := make(chan error, 1)
+	go func() { waitCh <- sbx.vmm.wait() }()
+	waitErr(t, waitCh, "vmm")
+
+	// and cannot be migrated (or snapshotted) again
+	err := sbx.MigrateSend(context.Background(), testTracer, "unix:/run/migration.sock")
+	if !errors.Is(err, ErrSandboxStopping) {
+		t.Fatalf("expect ErrSandboxStopping, got %v", err)
+	}
+	err = sbx.createSnapshot(context.Background(), testTracer, t.TempDir(), false, nil, nil)
+	if !errors.Is(err, ErrSandboxStopping) {
+		t.Fatalf("expect ErrSandboxStopping, got %v", err)
+	}
+}
+
+func TestMigrateSendWaitsForSnapshot(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	sbx.vmm.Hypervisor = &fakeMigrator{fakeHypervisor: h}
+	snapshotErr := startSnapshot(t, sbx, false)
+	<-h.snapshotStarted
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := sbx.MigrateSend(ctx, testTracer, "unix:/run/migration.sock")
+	if !errors.Is(err, ErrSnapshotInProgress) {
+		t.Fatalf("expect ErrSnapshotInProgress, got %v", err)
+	}
+	close(h.release)
+	if err := waitErr(t, snapshotErr, "snapshot"); err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+}
@@ -1,12 +1,16 @@
 package sandbox
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"slices"
 	"sync"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 	"github.com/vishvananda/netns"
@@ -14,6 +18,9 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
+// Returned when no more network can be allocated for sandboxes.
+var ErrNetworkExhausted = errors.New("network instance number exceed the upper bound")
+
 type SandboxNetworkState int
 
 const (
@@ -22,6 +29,18 @@ const (
 	free
 )
 
+func (s SandboxNetworkState) toProto() orchestrator.NetworkState {
+	switch s {
+	case invalid:
+		return orchestrator.NetworkState_NETWORK_INVALID
+	case using:
+		return orchestrator.NetworkState_NETWORK_USING
+	case free:
+		return orchestrator.NetworkState_NETWORK_FREE
+	}
+	return orchestrator.NetworkState_NETWORK_UNSPECIFY
+}
+
 type SandboxNetworkWrapper struct {
 	network.SandboxNetwork
 	state SandboxNetworkState
@@ -36,6 +55,15 @@ func (net *SandboxNetworkWrapper) SetState(state SandboxNetworkState) SandboxNet
 	return oldState
 }
 
+// Mark the network as used by sandboxID, the sandbox id is read
+// with the lock held (e.g., in ListNetworks).
+func (net *SandboxNetworkWrapper) use(sandboxID string) {
+	net.mu.Lock()
+	defer net.mu.Unlock()
+	net.state = using
+	net.SandboxID = sandboxID
+}
+
 func (net *SandboxNetworkWrapper) MakeFree(ctx context.Context, m *NetworkManager) error {
 	oldState := net.SetState(free)
 	switch oldState {
@@ -48,6 +76,21 @@ func (net *SandboxNetworkWrapper) MakeFree(ctx context.Context, m *NetworkManage
 			net.Cleanup(ctx)
 			return errMsg
 		}
+		// the egress policy is per sandbox, so should not be inherited by the next one
+		if err := net.RemoveEgressPolicy(); err != nil {
+			errMsg := fmt.Errorf("remove egress policy failed when recycling network: %w", err)
+			telemetry.ReportCriticalError(ctx, errMsg)
+			net.SetState(invalid)
+			net.Cleanup(ctx)
+			return errMsg
+		}
+		if err := net.RemovePortForwards(); err != nil {
+			errMsg := fmt.Errorf("remove port forwards failed when recycling network: %w", err)
+			telemetry.ReportCriticalError(ctx, errMsg)
+			net.SetState(invalid)
+			net.Cleanup(ctx)
+			return errMsg
+		}
 	case free:
 	case invalid:
 		return fmt.Errorf("invalid sandbox network")
@@ -66,12 +109,33 @@ type NetworkManager struct {
 	all        map[int]*SandboxNetworkWrapper
 	dns        *network.DNS
 	VethSubnet *net.IPNet // veth subnet, used to create new SandboxNetwork
+	IPv6Subnet *net.IPNet // nil means ipv6 is disabled
+	// Reclaim the netns/veth left by crashed process when creating network,
+	// instead of failing (and the index is unusable). The netns still
+	// containing processes (e.g., vmm of orphan sandbox) is never reclaimed.
+	ForceReclaim bool
+	// The mtu of the devices of new network envs, 0 means the default of kernel.
+	MTU int
+	// The owner of the tap devices of new network envs (see use_jailer).
+	// NOTE: the networks reused from previous orchestrator keep their owner.
+	TapUID int
+	TapGID int
+	// The host ports allocated to the port forwards without host port.
+	PortRange PortRange
+	// The prefix of the iptables chains holding the host rules (see network.SetupHostChains).
+	HostChainPrefix string
+	// How the host cloned ips are derived from the network idx, nil means the default.
+	ClonedIPs network.ClonedIPStrategy
+	// the host ports of port forwards -> network idx
+	ports    map[hostPort]int
+	nextPort uint16
+	// The number of warm networks prepared when orchestrator starts (see WarmNetworks).
+	PoolSize int
+	// serialize the warmups, so that they do not create more than requested
+	warmMu sync.Mutex
 }
 
-func NewNetworkManager(dns *network.DNS, vethSubnet *net.IPNet) *NetworkManager {
-	// TODO(huang-jl): add background task like create ns if there is few
-	// SandboxNetwork in the free array.
-
+func NewNetworkManager(dns *network.DNS, vethSubnet, ipv6Subnet *net.IPNet) *NetworkManager {
 	// start from 1
 	all := make(map[int]*SandboxNetworkWrapper)
 	return &NetworkManager{
@@ -79,9 +143,24 @@ func NewNetworkManager(dns *network.DNS, vethSubnet *net.IPNet) *NetworkManager
 		dns:        dns,
 		nextID:     1,
 		VethSubnet: vethSubnet,
+		IPv6Subnet: ipv6Subnet,
 	}
 }
 
+// NetworkEnv returns the network env of idx, ipv6 is enabled only when
+// requested (i.e., by the template) and the ipv6 subnet is configured.
+func (m *NetworkManager) NetworkEnv(idx int, ipv6 bool) network.NetworkEnv {
+	env := network.NewNetworkEnv(idx, m.VethSubnet).
+		WithMTU(m.MTU).
+		WithTapOwner(m.TapUID, m.TapGID).
+		WithHostChainPrefix(m.HostChainPrefix).
+		WithClonedIPs(m.ClonedIPs)
+	if ipv6 && m.IPv6Subnet != nil {
+		env = env.WithIPv6(m.IPv6Subnet)
+	}
+	return env
+}
+
 func (m *NetworkManager) Cleanup(ctx context.Context) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -104,6 +183,104 @@ func (m *NetworkManager) Cleanup(ctx context.Context) {
 	}
 }
 
+// ListNetworks returns the information of all network envs, sorted by network index.
+func (m *NetworkManager) ListNetworks() []*orchestrator.NetworkInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	infos := make([]*orchestrator.NetworkInfo, 0, len(m.all))
+	for _, net := range m.all {
+		net.mu.Lock()
+		info := &orchestrator.NetworkInfo{
+			NetworkIdx:   int64(net.NetworkIdx()),
+			State:        net.state.toProto(),
+			NetNsName:    net.NetNsName(),
+			VethIP:       net.VethIP().String(),
+			VpeerIP:      net.VpeerIP().String(),
+			HostClonedIP: net.HostClonedIP(),
+		}
+		if net.state == using {
+			info.SandboxID = net.SandboxID
+		}
+		net.mu.Unlock()
+		if net.IPv6Enabled() {
+			info.HostClonedIPv6 = net.HostClonedIPv6()
+		}
+		infos = append(infos, info)
+	}
+	slices.SortFunc(infos, func(a, b *orchestrator.NetworkInfo) int {
+		return cmp.Compare(a.NetworkIdx, b.NetworkIdx)
+	})
+	return infos
+}
+
+// The number of network envs available to new sandboxes, including the ones
+// can be reused and the ones have not been created yet.
+func (m *NetworkManager) FreeNetworks() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.free) + max(constants.MaxNetworkNumber-(m.nextID-1), 0)
+}
+
+// The number of networks created and not used by any sandbox.
+func (m *NetworkManager) WarmNetworkCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.free)
+}
+
+// The number of free networks whose ipv6 setting is the same as required.
+func (m *NetworkManager) warmNetworksLocked(ipv6 bool) int {
+	ipv6 = ipv6 && m.IPv6Subnet != nil
+	count := 0
+	for _, idx := range m.free {
+		if m.all[idx].IPv6Enabled() == ipv6 {
+			count++
+		}
+	}
+	return count
+}
+
+// Create networks until there are count free ones of the ipv6 setting, so
+// that the sandboxes created later can take them without setting up. It stops
+// when ctx is done or no more network can be created (i.e., MaxNetworkNumber).
+//
+// Return the number of networks created and the ones already free before.
+func (m *NetworkManager) WarmNetworks(ctx context.Context, tracer trace.Tracer, count int, ipv6 bool) (int, int, error) {
+	childCtx, childSpan := tracer.Start(ctx, "warm-sandbox-networks", trace.WithAttributes(
+		attribute.Int("count", count),
+		attribute.Bool("ipv6", ipv6),
+	))
+	defer childSpan.End()
+	m.warmMu.Lock()
+	defer m.warmMu.Unlock()
+
+	m.mu.Lock()
+	warm := m.warmNetworksLocked(ipv6)
+	m.mu.Unlock()
+	created := 0
+	for ; warm+created < count; created++ {
+		if err := childCtx.Err(); err != nil {
+			return created, warm, err
+		}
+		wrapper, err := m.createNetwork(childCtx, tracer, ipv6, free)
+		if errors.Is(err, ErrNetworkExhausted) {
+			telemetry.ReportEvent(childCtx, "stop warming sandbox networks as exhausted")
+			break
+		}
+		if err != nil {
+			return created, warm, err
+		}
+		m.mu.Lock()
+		m.free = append(m.free, wrapper.NetworkIdx())
+		m.mu.Unlock()
+	}
+	telemetry.ReportEvent(childCtx, "warmed sandbox networks",
+		attribute.Int("created", created),
+		attribute.Int("already_warm", warm),
+	)
+	return created, warm, nil
+}
+
 func (m *NetworkManager) DNS() *network.DNS {
 	return m.dns
 }
@@ -112,14 +289,12 @@ func (m *NetworkManager) DNS() *network.DNS {
 func newSandboxNetwork(
 	ctx context.Context,
 	tracer trace.Tracer,
-	idx int,
-	subnet *net.IPNet,
+	env network.NetworkEnv,
 ) (network.SandboxNetwork, error) {
 	childCtx, childSpan := tracer.Start(ctx, "create-sandbox-network", trace.WithAttributes(
-		attribute.Int("network_idx", idx),
+		attribute.Int("network_idx", env.NetworkIdx()),
 	))
 	defer childSpan.End()
-	env := network.NewNetworkEnv(idx, subnet)
 	net := network.NewSandboxNetwork(env, "")
 	// init network
 	if err := setupNetEnv(childCtx, tracer, &net); err != nil {
@@ -130,7 +305,7 @@ func newSandboxNetwork(
 	return net, nil
 }
 
-func (m *NetworkManager) insertUsingNetwork(net *SandboxNetworkWrapper) error {
+func (m *NetworkManager) insertNetwork(net *SandboxNetworkWrapper) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	if _, ok := m.all[net.NetworkIdx()]; ok {
@@ -153,6 +328,8 @@ func (m *NetworkManager) RecycleSandboxNetwork(ctx context.Context, net *network
 	}
 	wrapper := m.all[net.NetworkIdx()]
 	m.mu.Unlock()
+	// the network is not used by the sandbox anymore, even if failed
+	defer m.releasePorts(net.NetworkIdx())
 
 	if constants.Repurposable {
 		// make it into free queue
@@ -194,10 +371,43 @@ func (m *NetworkManager) RecycleSandboxNetwork(ctx context.Context, net *network
 	return nil
 }
 
+// Recycle the network kept for the failed sandbox (see SandboxConfig.KeepFilesOnError),
+// if it is still used by the sandbox, e.g., it is not after orchestrator restarts.
+func (m *NetworkManager) RecycleKeptNetwork(ctx context.Context, idx int, sandboxID string) error {
+	m.mu.Lock()
+	wrapper, ok := m.all[idx]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	wrapper.mu.Lock()
+	kept := wrapper.state == using && wrapper.SandboxID == sandboxID
+	wrapper.mu.Unlock()
+	if !kept {
+		return nil
+	}
+	return m.RecycleSandboxNetwork(ctx, &wrapper.SandboxNetwork)
+}
+
+// Take a free network whose ipv6 setting is the same as required.
+// Return false if there is no such network.
+func (m *NetworkManager) takeFreeLocked(ipv6 bool) (int, bool) {
+	ipv6 = ipv6 && m.IPv6Subnet != nil
+	for i, idx := range m.free {
+		if m.all[idx].IPv6Enabled() == ipv6 {
+			m.free = slices.Delete(m.free, i, i+1)
+			return idx, true
+		}
+	}
+	return 0, false
+}
+
+// Get a network for the sandbox, ipv6 is enabled (if configured) when ipv6 is true.
 func (m *NetworkManager) GetSandboxNetwork(
 	ctx context.Context,
 	tracer trace.Tracer,
 	sandboxID string,
+	ipv6 bool,
 ) (*network.SandboxNetwork, error) {
 	childCtx, childSpan := tracer.Start(ctx, "get-sandbox-network", trace.WithAttributes(
 		attribute.String("sandbox.id", sandboxID),
@@ -208,32 +418,15 @@ func (m *NetworkManager) GetSandboxNetwork(
 		wrapper *SandboxNetworkWrapper
 	)
 	m.mu.Lock()
-	if len(m.free) > 0 {
+	if idx, ok := m.takeFreeLocked(ipv6); ok {
 		// reuse if possible
-		idx := m.free[0]
-		m.free = m.free[1:]
 		wrapper = m.all[idx]
 		m.mu.Unlock()
 		telemetry.ReportEvent(childCtx, "reuse sandbox network", attribute.Int("idx", idx))
 	} else {
 		// create a new from scratch
-		idx := m.nextID
-		m.nextID += 1
 		m.mu.Unlock()
-		// TODO: A more resonsable judgement relies on subnet size
-		if idx > constants.MaxNetworkNumber {
-			return nil, fmt.Errorf("network instance number exceed the upper bound")
-		}
-		net, err := newSandboxNetwork(childCtx, tracer, idx, m.VethSubnet)
-		if err != nil {
-			return nil, err
-		}
-		telemetry.ReportEvent(childCtx, "create new sandbox network")
-		wrapper = &SandboxNetworkWrapper{
-			SandboxNetwork: net,
-			state:          using,
-		}
-		if err := m.insertUsingNetwork(wrapper); err != nil {
+		if wrapper, err = m.createNetwork(childCtx, tracer, ipv6, using); err != nil {
 			return nil, err
 		}
 	}
@@ -249,11 +442,159 @@ func (m *NetworkManager) GetSandboxNetwork(
 	}
 	telemetry.ReportEvent(childCtx, "create dns entry")
 
-	wrapper.SetState(using)
-	wrapper.SandboxID = sandboxID
+	wrapper.use(sandboxID)
 	return &wrapper.SandboxNetwork, nil
 }
 
+// Create a new network in state, which is inserted into m.all (but not m.free).
+func (m *NetworkManager) createNetwork(
+	ctx context.Context,
+	tracer trace.Tracer,
+	ipv6 bool,
+	state SandboxNetworkState,
+) (*SandboxNetworkWrapper, error) {
+	m.mu.Lock()
+	// TODO: A more resonsable judgement relies on subnet size
+	if m.nextID > constants.MaxNetworkNumber {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d", ErrNetworkExhausted, constants.MaxNetworkNumber)
+	}
+	idx := m.nextID
+	m.nextID += 1
+	m.mu.Unlock()
+	net, err := newSandboxNetwork(ctx, tracer, m.NetworkEnv(idx, ipv6))
+	if err != nil && m.ForceReclaim && network.IsLeftover(err) {
+		if err = m.reclaimNetwork(ctx, idx, err); err == nil {
+			net, err = newSandboxNetwork(ctx, tracer, m.NetworkEnv(idx, ipv6))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	telemetry.ReportEvent(ctx, "create new sandbox network", attribute.Int("network_idx", idx))
+	wrapper := &SandboxNetworkWrapper{
+		SandboxNetwork: net,
+		state:          state,
+	}
+	if err := m.insertNetwork(wrapper); err != nil {
+		return nil, err
+	}
+	return wrapper, nil
+}
+
+// Cleanup the network resources of idx left by crashed process, so that
+// the index can be used again.
+//
+// The network still used by an orphan sandbox (i.e., not persisted by previous
+// orchestrator) is not reclaimed, cause is returned in that case.
+func (m *NetworkManager) reclaimNetwork(ctx context.Context, idx int, cause error) error {
+	// whether the leaked network enabled ipv6 is unknown
+	net := network.NewSandboxNetwork(m.NetworkEnv(idx, true), "")
+	if err := net.Reclaim(); err != nil {
+		if errors.Is(err, network.ErrNetnsInUse) {
+			return fmt.Errorf("%w (%w)", cause, err)
+		}
+		// some of the resources may not exist, just retry creating
+		telemetry.ReportEvent(ctx, "reclaim sandbox network partially failed",
+			attribute.Int("network_idx", idx),
+			attribute.String("error", err.Error()),
+		)
+	}
+	// report as error so that the leaks are visible
+	telemetry.ReportError(ctx, fmt.Errorf("reclaimed leaked sandbox network: %w", cause),
+		attribute.Int("network_idx", idx),
+	)
+	if err := m.dns.RemoveAddress(net.HostClonedIP()); err != nil {
+		telemetry.ReportError(ctx, err, attribute.Int("network_idx", idx))
+	}
+	return nil
+}
+
+// Take over the network of sandbox created by previous orchestrator,
+// so that it can be recycled as other networks when the sandbox stops.
+func (m *NetworkManager) ReattachSandboxNetwork(
+	ctx context.Context,
+	tracer trace.Tracer,
+	idx int,
+	sandboxID string,
+	ipv6 bool,
+) (*network.SandboxNetwork, error) {
+	childCtx, childSpan := tracer.Start(ctx, "reattach-sandbox-network", trace.WithAttributes(
+		attribute.String("sandbox.id", sandboxID),
+		attribute.Int("network_idx", idx),
+	))
+	defer childSpan.End()
+
+	env := m.NetworkEnv(idx, ipv6)
+	if _, err := m.SearchNetwork(childCtx, tracer, env.NetNsName()); err != nil {
+		return nil, err
+	}
+	wrapper := &SandboxNetworkWrapper{
+		SandboxNetwork: network.NewExistingSandboxNetwork(env, sandboxID),
+		state:          using,
+	}
+	if err := m.insertNetwork(wrapper); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	if idx >= m.nextID {
+		m.nextID = idx + 1
+	}
+	m.mu.Unlock()
+
+	// the entry should still be there, add it again in case
+	// the /etc/hosts has been modified.
+	if err := m.CreateDNSEntry(wrapper.HostClonedIP(), sandboxID); err != nil {
+		errMsg := fmt.Errorf("create dns entry failed: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+	}
+	telemetry.ReportEvent(childCtx, "sandbox network reattached")
+	return &wrapper.SandboxNetwork, nil
+}
+
+// Rebuild the network of a running sandbox in place (see
+// [network.SandboxNetwork.Repair]), whose vmm process is pid. The vm is not
+// restarted and the network index (i.e., the addresses) is not changed.
+//
+// Return [ErrSandboxProcessGone] if the vmm process has exited.
+func (m *NetworkManager) RecreateSandboxNetwork(
+	ctx context.Context,
+	tracer trace.Tracer,
+	net *network.SandboxNetwork,
+	pid int,
+) error {
+	childCtx, childSpan := tracer.Start(ctx, "recreate-sandbox-network", trace.WithAttributes(
+		attribute.String("sandbox.id", net.SandboxID),
+		attribute.Int("network_idx", net.NetworkIdx()),
+	))
+	defer childSpan.End()
+
+	if err := checkSandboxProcess(pid, net.SandboxID); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return err
+	}
+	// the vmm is a descendant of pid (see NewVMM)
+	vmmPid, err := network.NetnsPidOfTree(pid)
+	if err != nil {
+		errMsg := fmt.Errorf("%w: %w", ErrSandboxProcessGone, err)
+		telemetry.ReportError(childCtx, errMsg)
+		return errMsg
+	}
+	if err := net.Repair(vmmPid); err != nil {
+		errMsg := fmt.Errorf("repair sandbox network failed: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(childCtx, "sandbox network repaired", attribute.Int("vmm_pid", vmmPid))
+
+	if err := m.CreateDNSEntry(net.HostClonedIP(), net.SandboxID); err != nil {
+		errMsg := fmt.Errorf("create dns entry failed: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+		return errMsg
+	}
+	return nil
+}
+
 func setupNetEnv(
 	ctx context.Context,
 	tracer trace.Tracer,
@@ -323,6 +664,8 @@ func (m *NetworkManager) SearchNetwork(ctx context.Context, tracer trace.Tracer,
 		return nil, errMsg
 	}
 	netNsHandle.Close()
+	// the cloned ip is not encoded in the netns name
+	*netEnv = netEnv.WithClonedIPs(m.ClonedIPs)
 	return netEnv, nil
 }
 
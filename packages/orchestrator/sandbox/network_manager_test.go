@@ -0,0 +1,205 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+)
+
+func newTestNetworkManager(t *testing.T, ipv6Subnet string) *NetworkManager {
+	t.Helper()
+	_, subnet, _ := net.ParseCIDR("10.168.0.0/16")
+	var subnet6 *net.IPNet
+	if ipv6Subnet != "" {
+		_, subnet6, _ = net.ParseCIDR(ipv6Subnet)
+	}
+	return NewNetworkManager(nil, subnet, subnet6)
+}
+
+// Add a free network (without setting it up) to the manager.
+func addFreeNetwork(m *NetworkManager, idx int, ipv6 bool) {
+	m.all[idx] = &SandboxNetworkWrapper{
+		SandboxNetwork: network.NewSandboxNetwork(m.NetworkEnv(idx, ipv6), ""),
+		state:          free,
+	}
+	m.free = append(m.free, idx)
+	m.nextID = max(m.nextID, idx+1)
+}
+
+func TestNetworkEnvIPv6(t *testing.T) {
+	disabled := newTestNetworkManager(t, "")
+	for _, ipv6 := range []bool{false, true} {
+		env := disabled.NetworkEnv(1, ipv6)
+		if env.IPv6Enabled() {
+			t.Fatalf("ipv6 should be disabled without ipv6 subnet (template ipv6: %v)", ipv6)
+		}
+	}
+
+	enabled := newTestNetworkManager(t, "fd00:1::/64")
+	env := enabled.NetworkEnv(1, false)
+	if env.IPv6Enabled() {
+		t.Fatal("ipv6 should be disabled when the template disables it")
+	}
+	env = enabled.NetworkEnv(1, true)
+	if !env.IPv6Enabled() || !enabled.IPv6Subnet.Contains(env.VethIPv6()) {
+		t.Fatalf("ipv6 should be enabled in %s, got veth %s", enabled.IPv6Subnet, env.VethIPv6())
+	}
+}
+
+func TestNetworkEnvClonedIPs(t *testing.T) {
+	m := newTestNetworkManager(t, "")
+	env := m.NetworkEnv(1, false)
+	if ip := env.HostClonedIP(); ip != "192.168.168.2" {
+		t.Fatalf("expect the default cloned ip, got %s", ip)
+	}
+	_, pool, _ := net.ParseCIDR("100.64.0.0/16")
+	strategy, err := network.NewSubnetClonedIPs(pool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.ClonedIPs = strategy
+	env = m.NetworkEnv(1, false)
+	if ip := env.HostClonedIP(); ip != "100.64.0.1" {
+		t.Fatalf("expect the cloned ip from %s, got %s", pool, ip)
+	}
+}
+
+func TestTakeFreeNetworkIPv6(t *testing.T) {
+	m := newTestNetworkManager(t, "fd00:1::/64")
+	addFreeNetwork(m, 1, false)
+	addFreeNetwork(m, 2, true)
+	addFreeNetwork(m, 3, false)
+
+	take := func(ipv6 bool) (int, bool) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.takeFreeLocked(ipv6)
+	}
+
+	if idx, ok := take(true); !ok || idx != 2 {
+		t.Fatalf("expect network 2 with ipv6, got %d (ok: %v)", idx, ok)
+	}
+	if _, ok := take(true); ok {
+		t.Fatal("expect no free network with ipv6")
+	}
+	if idx, ok := take(false); !ok || idx != 1 {
+		t.Fatalf("expect network 1 without ipv6, got %d (ok: %v)", idx, ok)
+	}
+	if !slices.Equal(m.free, []int{3}) {
+		t.Fatalf("unexpected free networks %v", m.free)
+	}
+
+	// ipv6 is not configured, so the template flag is ignored
+	m = newTestNetworkManager(t, "")
+	addFreeNetwork(m, 1, false)
+	if idx, ok := take(true); !ok || idx != 1 {
+		t.Fatalf("expect network 1, got %d (ok: %v)", idx, ok)
+	}
+}
+
+func TestListNetworksConcurrentUse(t *testing.T) {
+	m := newTestNetworkManager(t, "")
+	addFreeNetwork(m, 1, false)
+	wrapper := m.all[1]
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			wrapper.use("sandbox")
+			wrapper.SetState(free)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		infos := m.ListNetworks()
+		if len(infos) != 1 {
+			t.Fatalf("expect 1 network, got %v", infos)
+		}
+		if id := infos[0].SandboxID; id != "" && id != "sandbox" {
+			t.Fatalf("unexpected sandbox id %q", id)
+		}
+	}
+	<-done
+
+	wrapper.use("sandbox")
+	if infos := m.ListNetworks(); infos[0].SandboxID != "sandbox" {
+		t.Fatalf("expect the sandbox id of using network, got %v", infos[0])
+	}
+}
+
+func TestFreeNetworks(t *testing.T) {
+	m := newTestNetworkManager(t, "")
+	if n := m.FreeNetworks(); n != constants.MaxNetworkNumber {
+		t.Fatalf("expect %d free networks, got %d", constants.MaxNetworkNumber, n)
+	}
+
+	// 1 and 3 are free, 2 is being used
+	addFreeNetwork(m, 1, false)
+	addFreeNetwork(m, 3, false)
+	m.all[2] = &SandboxNetworkWrapper{state: using}
+	if n := m.FreeNetworks(); n != constants.MaxNetworkNumber-1 {
+		t.Fatalf("expect %d free networks, got %d", constants.MaxNetworkNumber-1, n)
+	}
+
+	m.mu.Lock()
+	m.takeFreeLocked(false)
+	m.mu.Unlock()
+	if n := m.FreeNetworks(); n != constants.MaxNetworkNumber-2 {
+		t.Fatalf("expect %d free networks, got %d", constants.MaxNetworkNumber-2, n)
+	}
+
+	// all of the networks have been created
+	m.nextID = constants.MaxNetworkNumber + 1
+	if n := m.FreeNetworks(); n != 1 {
+		t.Fatalf("expect 1 free network, got %d", n)
+	}
+}
+
+func TestWarmNetworks(t *testing.T) {
+	m := newTestNetworkManager(t, "fd00:1::/64")
+	addFreeNetwork(m, 1, false)
+	addFreeNetwork(m, 2, true)
+	addFreeNetwork(m, 3, false)
+
+	// enough warm networks, nothing is created
+	created, warm, err := m.WarmNetworks(context.Background(), testTracer, 2, false)
+	if err != nil || created != 0 || warm != 2 {
+		t.Fatalf("expect 0 created and 2 warm, got %d and %d (err %v)", created, warm, err)
+	}
+	if n := m.WarmNetworkCount(); n != 3 {
+		t.Fatalf("expect 3 warm networks, got %d", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	created, warm, err = m.WarmNetworks(ctx, testTracer, 2, true)
+	if !errors.Is(err, context.Canceled) || created != 0 || warm != 1 {
+		t.Fatalf("expect canceled with 1 warm, got %d and %d (err %v)", created, warm, err)
+	}
+
+	// stop without error when no more network can be created
+	m.nextID = constants.MaxNetworkNumber + 1
+	created, warm, err = m.WarmNetworks(context.Background(), testTracer, 10, false)
+	if err != nil || created != 0 || warm != 2 {
+		t.Fatalf("expect 0 created and 2 warm when exhausted, got %d and %d (err %v)", created, warm, err)
+	}
+	if m.nextID != constants.MaxNetworkNumber+1 {
+		t.Fatalf("next id should not exceed the max, got %d", m.nextID)
+	}
+}
+
+func TestRecreateSandboxNetworkProcessGone(t *testing.T) {
+	m := newTestNetworkManager(t, "")
+	sbxNet := network.NewSandboxNetwork(m.NetworkEnv(1, false), "sandbox")
+	// the pid is not the vmm of sandbox
+	err := m.RecreateSandboxNetwork(context.Background(), testTracer, &sbxNet, os.Getpid())
+	if !errors.Is(err, ErrSandboxProcessGone) {
+		t.Fatalf("expect sandbox process gone, got %v", err)
+	}
+}
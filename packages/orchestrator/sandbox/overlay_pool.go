@@ -0,0 +1,192 @@
+package sandbox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/KarpelesLab/reflink"
+	"go.uber.org/zap"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+// where the copies of writable rootfs are pooled, under the template dir so
+// that they are on the same filesystem as the instance dirs.
+const OverlayPoolDirName = "overlay-pool"
+
+// A pool of the copies of the writable rootfs of templates (with overlay), so
+// that EnsureFiles can rename one into the instance dir instead of copying it
+// on the hot path, which is slow when reflink is not supported.
+//
+// The copies are made from the writable rootfs of template instead of an empty
+// one, as it is mounted (with the content) in the snapshot. They are discarded
+// once the template changes.
+type OverlayPool struct {
+	// the number of copies kept for each template
+	size   int
+	logger *zap.Logger
+	refill chan *overlayPoolTemplate
+	done   chan struct{}
+	wg     sync.WaitGroup
+
+	mu        sync.Mutex
+	closed    bool
+	seq       int
+	templates map[string]*overlayPoolTemplate
+}
+
+type overlayPoolTemplate struct {
+	// the writable rootfs of template
+	src string
+	// where the copies are
+	dir string
+	// the src which the copies are made from, nil if there is no copy
+	srcInfo os.FileInfo
+	files   []string
+}
+
+func NewOverlayPool(size int, logger *zap.Logger) *OverlayPool {
+	p := &OverlayPool{
+		size:      size,
+		logger:    logger,
+		refill:    make(chan *overlayPoolTemplate, 16),
+		done:      make(chan struct{}),
+		templates: make(map[string]*overlayPoolTemplate),
+	}
+	p.wg.Add(1)
+	go p.run()
+	return p
+}
+
+func (p *OverlayPool) run() {
+	defer p.wg.Done()
+	for {
+		select {
+		case <-p.done:
+			return
+		case t := <-p.refill:
+			if err := p.fill(t); err != nil {
+				p.logger.Error("fill overlay pool failed", zap.Error(err), zap.String("rootfs", t.src))
+			}
+		}
+	}
+}
+
+// Copy the writable rootfs of t until there are size copies.
+func (p *OverlayPool) fill(t *overlayPoolTemplate) error {
+	for {
+		p.mu.Lock()
+		if p.closed || len(t.files) >= p.size {
+			p.mu.Unlock()
+			return nil
+		}
+		p.seq++
+		dst := filepath.Join(t.dir, fmt.Sprintf("%d.ext4", p.seq))
+		p.mu.Unlock()
+
+		info, err := os.Stat(t.src)
+		if err != nil {
+			return err
+		}
+		if err := utils.CreateDirAllIfNotExists(t.dir, 0o755); err != nil {
+			return err
+		}
+		if err := reflink.Auto(t.src, dst); err != nil {
+			os.Remove(dst)
+			return err
+		}
+
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			os.Remove(dst)
+			return nil
+		}
+		if t.srcInfo != nil && !sameSource(t.srcInfo, info) {
+			t.discard()
+		}
+		t.srcInfo = info
+		t.files = append(t.files, dst)
+		p.mu.Unlock()
+	}
+}
+
+func sameSource(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.Size() == b.Size() && a.ModTime().Equal(b.ModTime())
+}
+
+// Remove the copies of t, should be called with the lock of pool held.
+func (t *overlayPoolTemplate) discard() {
+	for _, file := range t.files {
+		os.Remove(file)
+	}
+	t.files, t.srcInfo = nil, nil
+}
+
+// Move a copy of the writable rootfs of cfg into dst, return false if there
+// is no copy (e.g., the first sandbox of the template), where the caller
+// should create it on demand. The pool is refilled in background.
+func (p *OverlayPool) take(cfg *SandboxConfig, dst string) bool {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return false
+	}
+	t, ok := p.templates[cfg.TemplateID]
+	if !ok {
+		t = &overlayPoolTemplate{
+			src: cfg.HostWritableRootfsPath(cfg.DataRoot),
+			dir: filepath.Join(cfg.TemplateDir(cfg.DataRoot), OverlayPoolDirName),
+		}
+		// left by the previous orchestrator
+		os.RemoveAll(t.dir)
+		p.templates[cfg.TemplateID] = t
+	}
+	var file string
+	if len(t.files) > 0 {
+		// the template may have been rebuilt
+		if info, err := os.Stat(t.src); err != nil || !sameSource(t.srcInfo, info) {
+			t.discard()
+		} else {
+			file = t.files[len(t.files)-1]
+			t.files = t.files[:len(t.files)-1]
+		}
+	}
+	p.mu.Unlock()
+
+	select {
+	case p.refill <- t:
+	default:
+		// the refill is busy, it will be requested by the next take
+	}
+	if file == "" {
+		return false
+	}
+	if err := os.Rename(file, dst); err != nil {
+		os.Remove(file)
+		return false
+	}
+	return true
+}
+
+// Stop refilling and remove all copies in the pool.
+func (p *OverlayPool) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	p.mu.Unlock()
+	close(p.done)
+	p.wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, t := range p.templates {
+		t.discard()
+		os.RemoveAll(t.dir)
+	}
+}
@@ -0,0 +1,80 @@
+package sandbox
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+func waitPoolFiles(t *testing.T, p *OverlayPool, templateID string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.Lock()
+		got := len(p.templates[templateID].files)
+		p.mu.Unlock()
+		if got >= n {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("overlay pool is not filled with %d copies", n)
+}
+
+func TestOverlayPool(t *testing.T) {
+	dataRoot := t.TempDir()
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{TemplateID: "default"},
+		DataRoot:   dataRoot,
+	}
+	src := cfg.HostWritableRootfsPath(dataRoot)
+	if err := os.MkdirAll(filepath.Dir(src), 0o755); err != nil {
+		t.Fatalf("create template dir failed: %s", err)
+	}
+	if err := os.WriteFile(src, []byte("v1"), 0o644); err != nil {
+		t.Fatalf("write writable rootfs failed: %s", err)
+	}
+
+	p := NewOverlayPool(2, zap.NewNop())
+	dst := filepath.Join(dataRoot, "writable.ext4")
+	if p.take(cfg, dst) {
+		t.Fatalf("expect the first take to miss")
+	}
+	waitPoolFiles(t, p, cfg.TemplateID, 2)
+	if !p.take(cfg, dst) {
+		t.Fatalf("expect the take to hit once filled")
+	}
+	if content, err := os.ReadFile(dst); err != nil || string(content) != "v1" {
+		t.Fatalf("expect the copy of v1, got %q (%v)", content, err)
+	}
+
+	// the template is rebuilt
+	waitPoolFiles(t, p, cfg.TemplateID, 2)
+	if err := os.WriteFile(src, []byte("v2-rebuilt"), 0o644); err != nil {
+		t.Fatalf("write writable rootfs failed: %s", err)
+	}
+	if p.take(cfg, dst) {
+		t.Fatalf("expect the stale copies to be discarded")
+	}
+	waitPoolFiles(t, p, cfg.TemplateID, 2)
+	if !p.take(cfg, dst) {
+		t.Fatalf("expect the take to hit once refilled")
+	}
+	if content, err := os.ReadFile(dst); err != nil || string(content) != "v2-rebuilt" {
+		t.Fatalf("expect the copy of v2-rebuilt, got %q (%v)", content, err)
+	}
+
+	p.Close()
+	poolDir := filepath.Join(cfg.TemplateDir(dataRoot), OverlayPoolDirName)
+	if _, err := os.Stat(poolDir); !os.IsNotExist(err) {
+		t.Fatalf("expect the pool dir to be removed after close, got %v", err)
+	}
+	if p.take(cfg, dst) {
+		t.Fatalf("expect the take to miss after close")
+	}
+}
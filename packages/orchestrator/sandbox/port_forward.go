@@ -0,0 +1,160 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+)
+
+const (
+	DefaultPortForwardMinPort = 30000
+	DefaultPortForwardMaxPort = 32767
+)
+
+// Returned when the host port of a port forward is used by another
+// sandbox, or there is no free port to allocate.
+var ErrHostPortUnavailable = errors.New("host port unavailable")
+
+type hostPort struct {
+	protocol string
+	port     uint16
+}
+
+// The range of host ports allocated to the port forwards without host port,
+// the zero value means [DefaultPortForwardMinPort, DefaultPortForwardMaxPort].
+type PortRange struct {
+	Min uint16
+	Max uint16
+}
+
+func (r PortRange) bounds() (uint16, uint16) {
+	if r.Min == 0 && r.Max == 0 {
+		return DefaultPortForwardMinPort, DefaultPortForwardMaxPort
+	}
+	return r.Min, r.Max
+}
+
+// Allocate the host ports of forwards to the network idx, the ones of host
+// port 0 are allocated from PortRange. Return the forwards with host ports.
+func (m *NetworkManager) allocatePorts(idx int, forwards []network.PortForward) ([]network.PortForward, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ports == nil {
+		m.ports = make(map[hostPort]int)
+	}
+	allocated := make([]network.PortForward, len(forwards))
+	var added []hostPort
+	release := func() {
+		for _, key := range added {
+			delete(m.ports, key)
+		}
+	}
+	// the specified ones first, so that they will not be taken by
+	// the allocated ones
+	for i, f := range forwards {
+		allocated[i] = f
+		if f.HostPort == 0 {
+			continue
+		}
+		key := hostPort{f.Proto(), f.HostPort}
+		if owner, ok := m.ports[key]; ok {
+			release()
+			return nil, fmt.Errorf("%w: %d/%s is used by network %d", ErrHostPortUnavailable, f.HostPort, f.Proto(), owner)
+		}
+		m.ports[key] = idx
+		added = append(added, key)
+	}
+	lo, hi := m.PortRange.bounds()
+	for i, f := range allocated {
+		if f.HostPort != 0 {
+			continue
+		}
+		port, ok := m.nextFreePortLocked(f.Proto(), lo, hi)
+		if !ok {
+			release()
+			return nil, fmt.Errorf("%w: no free %s port in [%d, %d]", ErrHostPortUnavailable, f.Proto(), lo, hi)
+		}
+		key := hostPort{f.Proto(), port}
+		m.ports[key] = idx
+		added = append(added, key)
+		allocated[i].HostPort = port
+	}
+	return allocated, nil
+}
+
+// Find a free port in [lo, hi] starting from the one after the last
+// allocated, so that the ports just released are not reused immediately.
+func (m *NetworkManager) nextFreePortLocked(protocol string, lo, hi uint16) (uint16, bool) {
+	size := int(hi) - int(lo) + 1
+	start := max(int(m.nextPort), int(lo))
+	for i := range size {
+		port := lo + uint16((start-int(lo)+i)%size)
+		if _, ok := m.ports[hostPort{protocol, port}]; !ok {
+			m.nextPort = port + 1
+			return port, true
+		}
+	}
+	return 0, false
+}
+
+// Take the host ports of forwards (e.g., when reattaching the sandbox),
+// which have been allocated by the previous orchestrator.
+func (m *NetworkManager) reservePorts(idx int, forwards []network.PortForward) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ports == nil {
+		m.ports = make(map[hostPort]int)
+	}
+	var err error
+	for _, f := range forwards {
+		key := hostPort{f.Proto(), f.HostPort}
+		if owner, ok := m.ports[key]; ok && owner != idx {
+			err = errors.Join(err, fmt.Errorf("%w: %d/%s is used by network %d", ErrHostPortUnavailable, f.HostPort, f.Proto(), owner))
+			continue
+		}
+		m.ports[key] = idx
+	}
+	return err
+}
+
+// Release the host ports allocated to the network idx.
+func (m *NetworkManager) releasePorts(idx int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, owner := range m.ports {
+		if owner == idx {
+			delete(m.ports, key)
+		}
+	}
+}
+
+// Allocate the host ports of forwards and install them on net, return
+// the forwards with the host ports allocated.
+func (m *NetworkManager) ForwardPorts(net *network.SandboxNetwork, forwards []network.PortForward) ([]network.PortForward, error) {
+	allocated, err := m.allocatePorts(net.NetworkIdx(), forwards)
+	if err != nil {
+		return nil, err
+	}
+	if err := net.ApplyPortForwards(allocated); err != nil {
+		m.releasePorts(net.NetworkIdx())
+		return nil, err
+	}
+	return allocated, nil
+}
+
+func portForwardsToProto(forwards []network.PortForward) []*orchestrator.PortForward {
+	if len(forwards) == 0 {
+		return nil
+	}
+	result := make([]*orchestrator.PortForward, 0, len(forwards))
+	for _, f := range forwards {
+		result = append(result, &orchestrator.PortForward{
+			HostPort:  uint32(f.HostPort),
+			GuestPort: uint32(f.GuestPort),
+			Protocol:  f.Proto(),
+		})
+	}
+	return result
+}
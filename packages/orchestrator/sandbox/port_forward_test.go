@@ -0,0 +1,67 @@
+package sandbox
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+)
+
+func TestAllocatePorts(t *testing.T) {
+	m := NewNetworkManager(nil, nil, nil)
+	m.PortRange = PortRange{Min: 40000, Max: 40002}
+
+	got, err := m.allocatePorts(1, []network.PortForward{
+		{GuestPort: 80},
+		{HostPort: 40000, GuestPort: 22},
+	})
+	if err != nil {
+		t.Fatalf("allocate ports failed: %s", err)
+	}
+	// the specified port is not allocated to others
+	if got[0].HostPort != 40001 || got[1].HostPort != 40000 {
+		t.Fatalf("unexpected allocated ports: %+v", got)
+	}
+
+	// the same port of another protocol is free
+	if _, err := m.allocatePorts(2, []network.PortForward{{HostPort: 40000, GuestPort: 53, Protocol: "udp"}}); err != nil {
+		t.Fatalf("allocate udp port failed: %s", err)
+	}
+	if _, err := m.allocatePorts(2, []network.PortForward{{HostPort: 40000, GuestPort: 22}}); !errors.Is(err, ErrHostPortUnavailable) {
+		t.Fatalf("expect ErrHostPortUnavailable for used port, got %v", err)
+	}
+
+	// exhausted, and the ones allocated in the failed call are released
+	if _, err := m.allocatePorts(2, []network.PortForward{{GuestPort: 80}, {GuestPort: 81}}); !errors.Is(err, ErrHostPortUnavailable) {
+		t.Fatalf("expect ErrHostPortUnavailable when exhausted, got %v", err)
+	}
+	got, err = m.allocatePorts(2, []network.PortForward{{GuestPort: 80}})
+	if err != nil || got[0].HostPort != 40002 {
+		t.Fatalf("expect 40002 allocated, got %+v (err %v)", got, err)
+	}
+
+	m.releasePorts(1)
+	got, err = m.allocatePorts(3, []network.PortForward{{GuestPort: 80}, {HostPort: 40001, GuestPort: 81}})
+	if err != nil || got[0].HostPort != 40000 {
+		t.Fatalf("expect released ports reused, got %+v (err %v)", got, err)
+	}
+}
+
+func TestReservePorts(t *testing.T) {
+	m := NewNetworkManager(nil, nil, nil)
+	forwards := []network.PortForward{{HostPort: 30000, GuestPort: 80}}
+	if err := m.reservePorts(1, forwards); err != nil {
+		t.Fatalf("reserve ports failed: %s", err)
+	}
+	// reserved again by the same network
+	if err := m.reservePorts(1, forwards); err != nil {
+		t.Fatalf("reserve ports again failed: %s", err)
+	}
+	if err := m.reservePorts(2, forwards); !errors.Is(err, ErrHostPortUnavailable) {
+		t.Fatalf("expect ErrHostPortUnavailable, got %v", err)
+	}
+	got, err := m.allocatePorts(2, []network.PortForward{{GuestPort: 80}})
+	if err != nil || got[0].HostPort != 30001 {
+		t.Fatalf("expect 30001 allocated, got %+v (err %v)", got, err)
+	}
+}
@@ -0,0 +1,33 @@
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+var ErrInvalidProcessDefaults = errors.New("invalid process defaults")
+
+// Validate the default working dir and env vars of processes spawned by envd.
+//
+// They are passed to envd through mmds, which is only supported by firecracker.
+func ValidateProcessDefaults(t *config.VMTemplate, workingDir string, env map[string]string) error {
+	if workingDir == "" && len(env) == 0 {
+		return nil
+	}
+	if t.VmmType != config.FIRECRACKER {
+		return fmt.Errorf("%w: only supported by firecracker", ErrInvalidProcessDefaults)
+	}
+	if workingDir != "" && !filepath.IsAbs(workingDir) {
+		return fmt.Errorf("%w: working dir %q should be absolute", ErrInvalidProcessDefaults, workingDir)
+	}
+	for k := range env {
+		if k == "" || strings.ContainsAny(k, "=\x00") {
+			return fmt.Errorf("%w: invalid env name %q", ErrInvalidProcessDefaults, k)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestRefreshPrometheusTarget(t *testing.T) {
+	sbx := &Sandbox{
+		Config: &SandboxConfig{
+			VMTemplate:          config.VMTemplate{TemplateID: "prometheus"},
+			SandboxID:           "test-sandbox",
+			DataRoot:            t.TempDir(),
+			PrometheusProxyAddr: "127.0.0.1:6666",
+		},
+		State: orchestrator.SandboxState_RUNNING,
+	}
+	path := sbx.Config.PrometheusTargetPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// e.g., partially written
+	if err := os.WriteFile(path, []byte(`[{"targets":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sbx.RefreshPrometheusTarget(context.Background(), testTracer); err != nil {
+		t.Fatalf("refresh prometheus target failed: %s", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targets []struct {
+		Targets []string          `json:"targets"`
+		Labels  map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(b, &targets); err != nil {
+		t.Fatalf("invalid prometheus target file: %s", err)
+	}
+	if len(targets) != 1 || targets[0].Targets[0] != "127.0.0.1:6666" || targets[0].Labels["id"] != "test-sandbox" {
+		t.Fatalf("unexpected prometheus target %s", b)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("expect tmp file removed, got %v", err)
+	}
+
+	sbx.State = orchestrator.SandboxState_STOP
+	if err := sbx.RefreshPrometheusTarget(context.Background(), testTracer); !errors.Is(err, InvalidSandboxState) {
+		t.Fatalf("expect invalid sandbox state, got %v", err)
+	}
+}
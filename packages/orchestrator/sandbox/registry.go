@@ -0,0 +1,256 @@
+package sandbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/shirou/gopsutil/v4/process"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrSandboxProcessGone = errors.New("sandbox process has gone")
+
+// The state of sandbox persisted on disk (under DataRoot), so that
+// orchestrator can reattach to the running sandboxes after restart.
+type PersistedSandbox struct {
+	SandboxID            string            `json:"sandboxID"`
+	TemplateID           string            `json:"templateID"`
+	Pid                  int               `json:"pid"`
+	NetworkIdx           int               `json:"networkIdx"`
+	IPv6                 bool              `json:"ipv6,omitempty"`
+	CgroupPath           string            `json:"cgroupPath"`
+	CgroupSubpath        string            `json:"cgroupSubpath,omitempty"`
+	HypervisorBinaryPath string            `json:"hypervisorBinaryPath"`
+	EnableDiffSnapshot   bool              `json:"enableDiffSnapshot"`
+	MaxInstanceLength    int               `json:"maxInstanceLength"`
+	Metadata             map[string]string `json:"metadata,omitempty"`
+	ExtraDisks           []DiskSpec        `json:"extraDisks,omitempty"`
+	MemoryOverrideMB     int64             `json:"memoryOverrideMB,omitempty"`
+	// with the host ports allocated
+	PortForwards []network.PortForward `json:"portForwards,omitempty"`
+	StartAt      time.Time             `json:"startAt"`
+}
+
+func registryDir(dataRoot string) string {
+	return filepath.Join(dataRoot, consts.SandboxRegistryDirName)
+}
+
+func (cfg *SandboxConfig) RegistryPath() string {
+	return filepath.Join(registryDir(cfg.DataRoot), cfg.SandboxID+".json")
+}
+
+// Persist the state of sandbox, should be called after the sandbox
+// has been created. The file will be removed in [SandboxConfig.CleanupFiles].
+func (s *Sandbox) Persist() error {
+	return s.persist(s.Metadata())
+}
+
+func (s *Sandbox) persist(metadata map[string]string) error {
+	state := PersistedSandbox{
+		SandboxID:            s.SandboxID(),
+		TemplateID:           s.Config.TemplateID,
+		Pid:                  s.vmm.proc.Pid,
+		NetworkIdx:           s.Net.NetworkIdx(),
+		IPv6:                 s.Net.IPv6Enabled(),
+		CgroupPath:           s.Config.CgroupPath(),
+		CgroupSubpath:        s.Config.CgroupSubpath,
+		HypervisorBinaryPath: s.Config.HypervisorBinaryPath,
+		EnableDiffSnapshot:   s.Config.EnableDiffSnapshot,
+		MaxInstanceLength:    s.Config.MaxInstanceLength,
+		Metadata:             metadata,
+		ExtraDisks:           s.Config.ExtraDisks,
+		MemoryOverrideMB:     s.Config.MemoryOverrideMB,
+		PortForwards:         s.Config.PortForwards,
+		StartAt:              s.StartAt,
+	}
+	b, err := json.Marshal(&state)
+	if err != nil {
+		return fmt.Errorf("marshal sandbox state failed: %w", err)
+	}
+	// write to a tmp file and then rename, so that we will not
+	// see a partial written file after crash.
+	path := s.Config.RegistryPath()
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0o644); err != nil {
+		return fmt.Errorf("write sandbox state (%s) failed: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("rename sandbox state (%s) failed: %w", path, err)
+	}
+	return nil
+}
+
+// Read all persisted sandbox states under dataRoot.
+func ListPersistedSandboxes(dataRoot string) ([]*PersistedSandbox, error) {
+	entries, err := os.ReadDir(registryDir(dataRoot))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var (
+		results  []*PersistedSandbox
+		finalErr error
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(registryDir(dataRoot), entry.Name())
+		b, err := os.ReadFile(path)
+		if err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("read %s failed: %w", path, err))
+			continue
+		}
+		var state PersistedSandbox
+		if err := json.Unmarshal(b, &state); err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("decode %s failed: %w", path, err))
+			continue
+		}
+		results = append(results, &state)
+	}
+	return results, finalErr
+}
+
+// Remove the persisted state of sandbox directly, only used when
+// the sandbox config cannot be rebuilt (e.g., template has been removed).
+func RemovePersistedSandbox(dataRoot, sandboxID string) error {
+	err := os.Remove(filepath.Join(registryDir(dataRoot), sandboxID+".json"))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Make sure the pid still belongs to the vmm process of the sandbox,
+// as the pid might have been reused after the vmm exited.
+func checkSandboxProcess(pid int, sandboxID string) error {
+	proc, err := process.NewProcess(int32(pid))
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSandboxProcessGone, err)
+	}
+	cmdline, err := proc.Cmdline()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSandboxProcessGone, err)
+	}
+	if !strings.HasPrefix(cmdline, "unshare") || !strings.Contains(cmdline, sandboxID) {
+		return fmt.Errorf("%w: pid %d has been reused (cmdline: %s)", ErrSandboxProcessGone, pid, cmdline)
+	}
+	return nil
+}
+
+// Rebuild the sandbox from its persisted state, reattaching to the still-running
+// vmm process and its network.
+//
+// Return [ErrSandboxProcessGone] if the vmm process has exited, in which case the
+// caller should cleanup the stale sandbox.
+func ReattachSandbox(
+	ctx context.Context,
+	tracer trace.Tracer,
+	config *SandboxConfig,
+	state *PersistedSandbox,
+	nm *NetworkManager,
+) (*Sandbox, error) {
+	childCtx, childSpan := tracer.Start(
+		ctx,
+		"sandbox-reattach",
+		trace.WithAttributes(attribute.String("sandbox.id", config.SandboxID)),
+	)
+	defer childSpan.End()
+
+	if err := checkSandboxProcess(state.Pid, state.SandboxID); err != nil {
+		return nil, err
+	}
+
+	net, err := nm.ReattachSandboxNetwork(childCtx, tracer, state.NetworkIdx, state.SandboxID, state.IPv6)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to reattach sandbox network: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+
+		return nil, errMsg
+	}
+
+	// the rules are still there (in host netns), only the allocation is lost
+	if err := nm.reservePorts(state.NetworkIdx, state.PortForwards); err != nil {
+		errMsg := fmt.Errorf("failed to reserve host ports of sandbox: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+	}
+
+	vmm, err := attachVmm(childCtx, tracer, config, net, state.Pid)
+	if err != nil {
+		errMsg := fmt.Errorf("failed to reattach vmm: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		// the network is still used by the vmm, so do not cleanup here
+		return nil, errMsg
+	}
+
+	bgCtx, bgCancel := context.WithCancel(context.Background())
+	return &Sandbox{
+		vmm:      vmm,
+		Config:   config,
+		Net:      net,
+		StartAt:  state.StartAt,
+		State:    orchestrator.SandboxState_RUNNING,
+		bgCtx:    bgCtx,
+		bgCancel: bgCancel,
+	}, nil
+}
+
+// Cleanup the network of stale sandbox (i.e., its vmm process has gone).
+// The files should be cleaned by [SandboxConfig.CleanupFiles].
+func CleanupStaleSandboxNetwork(ctx context.Context, state *PersistedSandbox, nm *NetworkManager) error {
+	var finalErr error
+	env := nm.NetworkEnv(state.NetworkIdx, state.IPv6)
+	net := network.NewExistingSandboxNetwork(env, state.SandboxID)
+	if err := net.Cleanup(ctx); err != nil {
+		finalErr = errors.Join(finalErr, err)
+	}
+	if err := nm.DeleteDNSEntry(state.SandboxID); err != nil {
+		finalErr = errors.Join(finalErr, err)
+	}
+	return finalErr
+}
+
+// Take over the orphan sandbox, i.e., whose vmm is still running but whose
+// state has not been persisted (e.g., the previous orchestrator crashed before
+// persisting). The state is rebuilt from the vmm process, so the metadata,
+// extra disks and port forwards are lost. The state is persisted once adopted.
+func AdoptSandbox(
+	ctx context.Context,
+	tracer trace.Tracer,
+	config *SandboxConfig,
+	state *PersistedSandbox,
+	nm *NetworkManager,
+) (*Sandbox, error) {
+	if err := checkSandboxProcess(state.Pid, state.SandboxID); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(config.InstancePath()); err != nil {
+		return nil, fmt.Errorf("instance dir of sandbox is not usable: %w", err)
+	}
+	if err := config.reattachCgroup(state.Pid); err != nil {
+		return nil, fmt.Errorf("failed to reattach cgroup: %w", err)
+	}
+	sbx, err := ReattachSandbox(ctx, tracer, config, state, nm)
+	if err != nil {
+		return nil, err
+	}
+	if err := sbx.Persist(); err != nil {
+		// the sandbox still works, but it will be an orphan again after restart
+		errMsg := fmt.Errorf("failed to persist adopted sandbox state: %w", err)
+		telemetry.ReportError(ctx, errMsg, attribute.String("sandbox.id", state.SandboxID))
+	}
+	return sbx, nil
+}
@@ -0,0 +1,141 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPersistRoundTrip(t *testing.T) {
+	sbx, _ := newTestSandbox(t, false)
+	dataRoot := t.TempDir()
+	sbx.Config.DataRoot = dataRoot
+	sbx.Config.TemplateID = "default"
+	sbx.Config.HypervisorBinaryPath = "/usr/bin/firecracker"
+	sbx.Config.MaxInstanceLength = 3
+	sbx.Config.Metadata = map[string]string{"owner": "test"}
+	sbx.Config.ExtraDisks = []DiskSpec{{SizeMB: 16}}
+	sbx.StartAt = time.Now().Truncate(time.Second)
+	if err := os.MkdirAll(registryDir(dataRoot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sbx.Persist(); err != nil {
+		t.Fatalf("persist sandbox failed: %s", err)
+	}
+	if _, err := os.Stat(sbx.Config.RegistryPath() + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("tmp file should be renamed, stat err: %v", err)
+	}
+	// a corrupted state should not prevent loading the others
+	if err := os.WriteFile(filepath.Join(registryDir(dataRoot), "broken.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	states, err := ListPersistedSandboxes(dataRoot)
+	if err == nil {
+		t.Fatalf("expect decode error of the corrupted state")
+	}
+	if len(states) != 1 {
+		t.Fatalf("expect 1 persisted sandbox, got %d", len(states))
+	}
+	state := states[0]
+	if state.SandboxID != sbx.SandboxID() || state.TemplateID != "default" ||
+		state.Pid != sbx.vmm.proc.Pid || state.HypervisorBinaryPath != "/usr/bin/firecracker" ||
+		state.MaxInstanceLength != 3 || !state.StartAt.Equal(sbx.StartAt) {
+		t.Fatalf("unexpected persisted sandbox %+v", state)
+	}
+	if !maps.Equal(state.Metadata, sbx.Config.Metadata) {
+		t.Fatalf("unexpected metadata %v", state.Metadata)
+	}
+	if !slices.Equal(state.ExtraDisks, sbx.Config.ExtraDisks) {
+		t.Fatalf("unexpected extra disks %v", state.ExtraDisks)
+	}
+
+	if err := RemovePersistedSandbox(dataRoot, sbx.SandboxID()); err != nil {
+		t.Fatalf("remove persisted sandbox failed: %s", err)
+	}
+	// removing twice is fine
+	if err := RemovePersistedSandbox(dataRoot, sbx.SandboxID()); err != nil {
+		t.Fatalf("remove persisted sandbox again failed: %s", err)
+	}
+}
+
+func TestListPersistedSandboxesNoDir(t *testing.T) {
+	states, err := ListPersistedSandboxes(t.TempDir())
+	if err != nil || len(states) != 0 {
+		t.Fatalf("expect nothing, got %v (err: %v)", states, err)
+	}
+}
+
+func TestCheckSandboxProcess(t *testing.T) {
+	const sandboxID = "test-sandbox"
+	start := func(cmd *exec.Cmd) int {
+		t.Helper()
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("spawn process failed: %s", err)
+		}
+		t.Cleanup(func() {
+			cmd.Process.Kill()
+			cmd.Wait()
+		})
+		return cmd.Process.Pid
+	}
+
+	// looks like the vmm process spawned by orchestrator
+	vmm := exec.Command("bash", "-c", "sleep 60; true", sandboxID)
+	vmm.Args[0] = "unshare"
+	if err := checkSandboxProcess(start(vmm), sandboxID); err != nil {
+		t.Fatalf("expect the vmm process alive, got %s", err)
+	}
+
+	// the pid is reused by another process
+	other := start(exec.Command("sleep", "60"))
+	if err := checkSandboxProcess(other, sandboxID); !errors.Is(err, ErrSandboxProcessGone) {
+		t.Fatalf("expect process gone for reused pid, got %v", err)
+	}
+
+	// the process has exited
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkSandboxProcess(exited.Process.Pid, sandboxID); !errors.Is(err, ErrSandboxProcessGone) {
+		t.Fatalf("expect process gone for exited pid, got %v", err)
+	}
+}
+
+func TestReattachSandboxProcessGone(t *testing.T) {
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	state := &PersistedSandbox{SandboxID: "test-sandbox", Pid: exited.Process.Pid}
+	// the network manager should not be touched for a stale sandbox
+	_, err := ReattachSandbox(context.Background(), testTracer, &SandboxConfig{SandboxID: state.SandboxID}, state, nil)
+	if !errors.Is(err, ErrSandboxProcessGone) {
+		t.Fatalf("expect process gone, got %v", err)
+	}
+}
+
+func TestAdoptSandboxProcessGone(t *testing.T) {
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	state := &PersistedSandbox{SandboxID: "test-sandbox", Pid: exited.Process.Pid}
+	cfg := &SandboxConfig{SandboxID: state.SandboxID, DataRoot: t.TempDir(), CgroupfsPath: t.TempDir()}
+	_, err := AdoptSandbox(context.Background(), testTracer, cfg, state, nil)
+	if !errors.Is(err, ErrSandboxProcessGone) {
+		t.Fatalf("expect process gone, got %v", err)
+	}
+	// nothing is created for the stale sandbox
+	if _, err := os.Stat(cfg.CgroupPath()); !os.IsNotExist(err) {
+		t.Fatalf("the cgroup should not be created, stat err: %v", err)
+	}
+}
@@ -9,28 +9,49 @@ import (
 	"net/http"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
 	waitSocketTimeout = 10 * time.Second
+
+	clockSyncRetryInterval = 100 * time.Millisecond
 )
 
 var InvalidSandboxState = errors.New("invalid sandbox state")
 
-// Default MaxIdleConns is 100.
-// Default IdleConnTimeout is 90 seconds.
-var httpClient = http.Client{
-	Timeout: 10 * time.Second,
+// Returned when snapshotting a sandbox which is being (or has been) stopped.
+var ErrSandboxStopping = errors.New("sandbox is stopping")
+
+// Returned when the snapshot in progress does not finish before
+// the concurrent snapshot of the same sandbox gives up waiting.
+var ErrSnapshotInProgress = errors.New("sandbox is snapshotting")
+
+// The number of failed probes of prometheus target (see probePrometheusTarget)
+var probeFailures metric.Int64Counter
+
+func init() {
+	var err error
+	probeFailures, err = otel.Meter(constants.ServiceName).Int64Counter(
+		"prometheus_target.probe_failure",
+		metric.WithDescription("Number of sandboxes whose metrics path cannot be fetched through the proxy"),
+	)
+	if err != nil {
+		panic(fmt.Errorf("create metric `probe_failure` failed: %w", err))
+	}
 }
 
 type Sandbox struct {
@@ -46,6 +67,24 @@ type Sandbox struct {
 	cleanRes  error
 
 	State orchestrator.SandboxState
+
+	// protects Config.Metadata, which can be modified by SetMetadata
+	metadataMu sync.RWMutex
+
+	// set once Stop() begins, checked by snapshot (without holding mu)
+	stopping atomic.Bool
+	// cancel the in-progress snapshot, nil if no snapshot is in progress
+	snapshotMu     sync.Mutex
+	snapshotCancel context.CancelFunc
+	// the memfile compression in background (see compressMemfile),
+	// the next snapshot waits for it as they may use the same dir.
+	compressing sync.WaitGroup
+	// serializes the writes of prometheus target file, which share the tmp file
+	targetMu sync.Mutex
+	// the context of background tasks (e.g., EnsureClockSync), canceled once
+	// the sandbox is stopped or cleaned up (e.g., the vmm exits by itself).
+	bgCtx    context.Context
+	bgCancel context.CancelFunc
 }
 
 func NewSandbox(
@@ -60,26 +99,63 @@ func NewSandbox(
 		trace.WithAttributes(attribute.String("sandbox.id", config.SandboxID)),
 	)
 	defer childSpan.End()
+	// the cleanup on error should not be interrupted, even if
+	// ctx is done (e.g., the create timeout is reached)
+	cleanupCtx := context.WithoutCancel(childCtx)
 
-	net, err := nm.GetSandboxNetwork(childCtx, tracer, config.SandboxID)
+	net, err := nm.GetSandboxNetwork(childCtx, tracer, config.SandboxID, config.IPv6)
 	if err != nil {
 		errMsg := fmt.Errorf("failed to get sandbox network: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
 
 		return nil, errMsg
 	}
+	if config.UniqueGuestMAC && config.GuestMAC == "" {
+		config.GuestMAC = net.GuestMAC()
+	}
 	defer func() {
-		if err != nil {
-			ntErr := net.Cleanup(childCtx)
+		if err != nil && config.KeepFilesOnError {
+			// the network is kept along with the files, until PurgeFailed
+			keepErr := config.keepFailedFiles(cleanupCtx, tracer, net, err)
+			if keepErr != nil {
+				errMsg := fmt.Errorf("error keeping files after failed sandbox start: %w", keepErr)
+				telemetry.ReportCriticalError(cleanupCtx, errMsg)
+			}
+		} else if err != nil {
+			// recycle (rather than only cleanup) it, so that its dns entry and
+			// index are released along with the ports
+			ntErr := nm.RecycleSandboxNetwork(cleanupCtx, net)
 			if ntErr != nil {
-				errMsg := fmt.Errorf("error cleanup network env after failed sandbox start: %w", ntErr)
-				telemetry.ReportError(childCtx, errMsg)
+				errMsg := fmt.Errorf("error recycling network env after failed sandbox start: %w", ntErr)
+				telemetry.ReportError(cleanupCtx, errMsg)
 			} else {
-				telemetry.ReportEvent(childCtx, "cleanup network env after failed sandbox start")
+				telemetry.ReportEvent(cleanupCtx, "recycled network env after failed sandbox start")
 			}
 		}
 	}()
 
+	if config.EgressPolicy != nil {
+		if err = net.ApplyEgressPolicy(config.EgressPolicy); err != nil {
+			errMsg := fmt.Errorf("failed to apply egress policy: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+
+			return nil, errMsg
+		}
+		telemetry.ReportEvent(childCtx, "applied egress policy")
+	}
+
+	if len(config.PortForwards) > 0 {
+		forwards, forwardErr := nm.ForwardPorts(net, config.PortForwards)
+		if err = forwardErr; err != nil {
+			errMsg := fmt.Errorf("failed to forward ports: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+
+			return nil, errMsg
+		}
+		config.PortForwards = forwards
+		telemetry.ReportEvent(childCtx, "forwarded ports")
+	}
+
 	err = config.EnsureFiles(childCtx, tracer)
 	if err != nil {
 		errMsg := fmt.Errorf("failed to create env for FC: %w", err)
@@ -89,13 +165,14 @@ func NewSandbox(
 	}
 
 	defer func() {
-		if err != nil {
-			cleanupErr := config.CleanupFiles(childCtx, tracer, false)
+		// otherwise, the files are kept (see keepFailedFiles above)
+		if err != nil && !config.KeepFilesOnError {
+			cleanupErr := config.CleanupFiles(cleanupCtx, tracer, false)
 			if cleanupErr != nil {
 				errMsg := fmt.Errorf("error deleting env after failed fc start: %w", cleanupErr)
-				telemetry.ReportCriticalError(childCtx, errMsg)
+				telemetry.ReportCriticalError(cleanupCtx, errMsg)
 			} else {
-				telemetry.ReportEvent(childCtx, "cleanup files since new sandbox failed")
+				telemetry.ReportEvent(cleanupCtx, "cleanup files since new sandbox failed")
 			}
 		}
 	}()
@@ -109,21 +186,41 @@ func NewSandbox(
 	if err != nil {
 		errMsg := fmt.Errorf("failed to create vmm: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
+		if vmm.proc != nil {
+			// the process should exit before its netns and files are cleaned up
+			vmm.stop(cleanupCtx, tracer)
+			vmm.wait()
+		}
 		return nil, errMsg
 	}
 
+	bgCtx, bgCancel := context.WithCancel(context.Background())
 	sbx := &Sandbox{
-		vmm:     vmm,
-		Config:  config,
-		Net:     net,
-		StartAt: time.Now(),
-		State:   orchestrator.SandboxState_RUNNING,
+		vmm:      vmm,
+		Config:   config,
+		Net:      net,
+		StartAt:  time.Now(),
+		State:    orchestrator.SandboxState_RUNNING,
+		bgCtx:    bgCtx,
+		bgCancel: bgCancel,
+	}
+
+	if config.GuestMAC != "" {
+		if err = sbx.applyGuestMAC(childCtx); err != nil {
+			errMsg := fmt.Errorf("failed to apply guest mac: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			bgCancel()
+			vmm.stop(cleanupCtx, tracer)
+			vmm.wait()
+			return nil, errMsg
+		}
+		telemetry.ReportEvent(childCtx, "guest mac applied", attribute.String("mac", config.GuestMAC))
 	}
 
 	telemetry.ReportEvent(childCtx, "ensuring clock sync")
 	go func() {
 		bgCtx, span := tracer.Start(
-			context.Background(),
+			sbx.backgroundCtx(),
 			"sandbox-bg-task",
 			trace.WithAttributes(
 				attribute.String("sandbox.id", sbx.SandboxID()),
@@ -132,7 +229,9 @@ func NewSandbox(
 		defer span.End()
 
 		clockErr := sbx.EnsureClockSync(bgCtx)
-		if clockErr != nil {
+		if errors.Is(clockErr, ErrClockSyncGaveUp) {
+			telemetry.ReportCriticalError(bgCtx, fmt.Errorf("failed to sync clock: %w", clockErr))
+		} else if clockErr != nil {
 			telemetry.ReportError(bgCtx, fmt.Errorf("failed to sync clock: %w", clockErr))
 		} else {
 			telemetry.ReportEvent(bgCtx, "clock synced")
@@ -141,54 +240,117 @@ func NewSandbox(
 			telemetry.ReportError(bgCtx, fmt.Errorf("failed to setup prometheus target: %w", err))
 		} else {
 			telemetry.ReportEvent(bgCtx, "prometheus target set")
+			if config.PrometheusProbeAddr != "" {
+				sbx.probePrometheusTarget(bgCtx, tracer)
+			}
 		}
 	}()
 
 	return sbx, nil
 }
 
+// The context of background tasks, which is done once the sandbox is stopped.
+func (s *Sandbox) backgroundCtx() context.Context {
+	if s.bgCtx == nil {
+		return context.Background()
+	}
+	return s.bgCtx
+}
+
+func (s *Sandbox) cancelBackground() {
+	if s.bgCancel != nil {
+		s.bgCancel()
+	}
+}
+
+// Sync the clock of guest until succeed, with jittered exponential backoff
+// between attempts. ErrClockSyncGaveUp is returned once exceeding the bounds
+// of Config.ClockSync, or ctx.Err() if ctx is done before that.
 func (s *Sandbox) EnsureClockSync(ctx context.Context) error {
-syncLoop:
-	for {
-		select {
-		case <-time.After(10 * time.Second):
-		case <-ctx.Done():
+	opts := s.Config.ClockSync
+	syncCtx, cancel := context.WithTimeout(ctx, opts.timeout())
+	defer cancel()
+	interval := clockSyncRetryInterval
+	for attempt := 1; ; attempt++ {
+		attemptCtx, attemptCancel := context.WithTimeout(syncCtx, clockSyncAttemptTimeout)
+		err := s.SyncClock(attemptCtx)
+		attemptCancel()
+		if err == nil {
+			return nil
+		}
+		telemetry.ReportError(ctx, fmt.Errorf("error syncing clock: %w", err), attribute.Int("attempt", attempt))
+		if s.stopping.Load() {
+			return ErrSandboxStopping
+		}
+		if ctx.Err() != nil {
 			return ctx.Err()
-		default:
-			err := s.syncClock(ctx)
-			if err != nil {
-				telemetry.ReportError(ctx, fmt.Errorf("error syncing clock: %w", err))
-				continue
-			}
-			break syncLoop
 		}
+		if attempt >= opts.maxAttempts() || syncCtx.Err() != nil {
+			clockSyncFailures.Add(ctx, 1)
+			return fmt.Errorf("%w after %d attempts: %w", ErrClockSyncGaveUp, attempt, err)
+		}
+		select {
+		case <-time.After(jitter(interval)):
+		case <-syncCtx.Done():
+		}
+		interval = min(interval*2, clockSyncMaxRetryInterval)
 	}
+}
 
-	return nil
+// Ask envd to sync the clock of guest once, return error if envd reports failure.
+func (s *Sandbox) SyncClock(ctx context.Context) error {
+	address := fmt.Sprintf("http://%s:%d/sync", s.Net.HostClonedIP(), s.Config.EnvdServerPort())
+	return postSync(ctx, s.envdClient(), address)
 }
 
-func (s *Sandbox) syncClock(ctx context.Context) error {
-	address := fmt.Sprintf("http://%s:%d/sync", s.Net.HostClonedIP(), consts.DefaultEnvdServerPort)
+// The guest clock drifts during pause, so re-sync it in background after resume.
+func (s *Sandbox) resyncClockAfterResume(tracer trace.Tracer) {
+	go func() {
+		ctx, span := tracer.Start(
+			s.backgroundCtx(),
+			"resync-clock",
+			trace.WithAttributes(attribute.String("sandbox.id", s.SandboxID())),
+		)
+		defer span.End()
+		if err := s.EnsureClockSync(ctx); err != nil {
+			telemetry.ReportError(ctx, fmt.Errorf("failed to re-sync clock after resume: %w", err))
+		} else {
+			telemetry.ReportEvent(ctx, "clock re-synced after resume")
+		}
+	}()
+}
 
-	request, err := http.NewRequestWithContext(ctx, "POST", address, nil)
+// The status of logs buffered by envd (i.e., not delivered to the log collector).
+type PendingLogsStatus struct {
+	PendingEntries int64  `json:"pending_entries"`
+	PendingBytes   int64  `json:"pending_bytes"`
+	DroppedEntries int64  `json:"dropped_entries"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+func (s *Sandbox) PendingLogs(ctx context.Context) (*PendingLogsStatus, error) {
+	address := fmt.Sprintf("http://%s:%d/logs/pending", s.Net.HostClonedIP(), s.Config.EnvdServerPort())
+
+	request, err := http.NewRequestWithContext(ctx, "GET", address, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	response, err := httpClient.Do(request)
+	response, err := s.envdClient().Do(request)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer response.Body.Close()
 
-	// NOTE(huang-jl): After reading the body of response, the http client
-	// will reuse the connection
-	if _, err := io.Copy(io.Discard, response.Body); err != nil {
-		return err
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("envd returned status %d", response.StatusCode)
 	}
 
-	defer response.Body.Close()
-
-	return nil
+	var status PendingLogsStatus
+	if err := json.NewDecoder(response.Body).Decode(&status); err != nil {
+		return nil, fmt.Errorf("decode pending logs status failed: %w", err)
+	}
+	return &status, nil
 }
 
 // Clean up the resource related to the sandbox (e.g., network, disk...).
@@ -213,6 +375,7 @@ func (s *Sandbox) cleanupAfterFCStop(
 	)
 	childCtx, childSpan := tracer.Start(ctx, "sandbox-delete")
 	defer childSpan.End()
+	s.cancelBackground()
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	keepInstanceDir := false
@@ -230,7 +393,7 @@ func (s *Sandbox) cleanupAfterFCStop(
 		// weird state, so we keep instance dir for debugging purpose
 		keepInstanceDir = true
 	}
-	s.State = orchestrator.SandboxState_CLEANNING
+	s.setState(orchestrator.SandboxState_CLEANNING)
 
 	// NOTE(huang-jl): we do not cleanup network here,
 	// we try to reuse the network instance.
@@ -255,14 +418,37 @@ func (s *Sandbox) cleanupAfterFCStop(
 	} else {
 		telemetry.ReportEvent(childCtx, "deleted sandbox files")
 	}
+	s.emit(orchestrator.SandboxEventType_EVENT_CLEANED)
 	return finalErr
 }
 
+// Stop the vm. If a snapshot is in progress, Stop waits for it to finish,
+// or cancels it when CancelSnapshotOnDelete is set.
 func (s *Sandbox) Stop(ctx context.Context, tracer trace.Tracer) error {
 	childCtx, childSpan := tracer.Start(ctx, "sandbox-stop")
 	defer childSpan.End()
+	// mark stopping before acquiring mu, so that the
+	// snapshot waiting for mu will be rejected.
+	s.stopping.Store(true)
+	s.cancelBackground()
+	if s.Config.CancelSnapshotOnDelete {
+		s.snapshotMu.Lock()
+		if s.snapshotCancel != nil {
+			s.snapshotCancel()
+			telemetry.ReportEvent(childCtx, "canceled in-progress snapshot")
+		}
+		s.snapshotMu.Unlock()
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	switch s.State {
+	case orchestrator.SandboxState_STOP, orchestrator.SandboxState_CLEANNING:
+		// e.g., the vm has been terminated by snapshot
+		telemetry.ReportEvent(childCtx, "sandbox already stopped",
+			attribute.String("state", s.State.String()),
+		)
+		return nil
+	}
 	// despite the state is weird, we still stop the VM
 	if s.State != orchestrator.SandboxState_RUNNING {
 		err := InvalidSandboxState
@@ -274,59 +460,191 @@ func (s *Sandbox) Stop(ctx context.Context, tracer trace.Tracer) error {
 	}
 	// mark the sandbox as KILLING (but the actual delete is in the
 	// wait-sandbox goroutine, see Create())
-	s.State = orchestrator.SandboxState_STOP
+	s.setState(orchestrator.SandboxState_STOP)
 	return s.vmm.stop(childCtx, tracer)
 }
 
-// create snaphot of the running vm
+func (s *Sandbox) snapshotInProgress() bool {
+	s.snapshotMu.Lock()
+	defer s.snapshotMu.Unlock()
+	return s.snapshotCancel != nil
+}
+
+// Acquire mu for snapshot. Like Stop, a concurrent snapshot waits for the one
+// in progress, ErrSnapshotInProgress is returned if ctx is done before that.
+func (s *Sandbox) lockForSnapshot(ctx context.Context) error {
+	if s.mu.TryLock() {
+		return nil
+	}
+	locked := make(chan struct{})
+	go func() {
+		s.mu.Lock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+		return nil
+	case <-ctx.Done():
+		// release mu once it is acquired
+		go func() {
+			<-locked
+			s.mu.Unlock()
+		}()
+		if s.snapshotInProgress() {
+			return fmt.Errorf("%w: %w", ErrSnapshotInProgress, ctx.Err())
+		}
+		return ctx.Err()
+	}
+}
+
+// create snaphot of the running vm, the SnapshotMeta is written along
+// with the snapshot files.
 //
+// @snapshotDir: where the snapshot is written, EnvInstanceCreateSnapshotPath() if empty
 // @terminate: true to kill the vm, false to resume the vm after generating snapshot
-func (s *Sandbox) CreateSnapshot(ctx context.Context, tracer trace.Tracer, terminate bool) error {
+func (s *Sandbox) CreateSnapshot(ctx context.Context, tracer trace.Tracer, snapshotDir string, terminate bool) error {
 	childCtx, childSpan := tracer.Start(ctx, "sandbox-create-snapshot")
 	defer childSpan.End()
-	s.mu.Lock()
+	if snapshotDir == "" {
+		snapshotDir = s.Config.EnvInstanceCreateSnapshotPath()
+	}
+	var afterResume func(ctx context.Context)
+	if s.Config.CompressMemfile {
+		afterResume = func(ctx context.Context) {
+			s.compressMemfile(ctx, tracer, snapshotDir, nil)
+		}
+	}
+	return s.createSnapshot(childCtx, tracer, snapshotDir, terminate, func(ctx context.Context) error {
+		// written while paused, so that it describes the vm in the snapshot
+		return s.writeSnapshotMeta(snapshotDir)
+	}, afterResume)
+}
+
+// Compress the memfile of snapshot in dir in background, so that the caller
+// does not wait for it. done (if not nil) is called with the result.
+//
+// Should be called with mu held, i.e., in afterResume of createSnapshot.
+func (s *Sandbox) compressMemfile(ctx context.Context, tracer trace.Tracer, dir string, done func(ctx context.Context, err error)) {
+	s.compressing.Add(1)
+	go func() {
+		defer s.compressing.Done()
+		childCtx, childSpan := tracer.Start(context.WithoutCancel(ctx), "sandbox-compress-memfile")
+		defer childSpan.End()
+		err := hypervisor.CompressMemfile(childCtx, dir)
+		if err != nil {
+			telemetry.ReportCriticalError(childCtx, fmt.Errorf("error compressing memfile of snapshot %s: %w", dir, err))
+		}
+		if done != nil {
+			done(childCtx, err)
+		}
+	}()
+}
+
+// @whilePaused: (optional) called after generating snapshot and before
+// resuming (or terminating) the vm, e.g., to copy the rootfs consistent with
+// the snapshot. The vm is still resumed (or terminated) if it returns error.
+//
+// @afterResume: (optional) called with mu held after the vm is resumed (or
+// terminated) successfully, e.g., to compress the memfile in background.
+func (s *Sandbox) createSnapshot(
+	ctx context.Context,
+	tracer trace.Tracer,
+	snapshotDir string,
+	terminate bool,
+	whilePaused func(ctx context.Context) error,
+	afterResume func(ctx context.Context),
+) error {
+	if err := s.lockForSnapshot(ctx); err != nil {
+		telemetry.ReportError(ctx, fmt.Errorf("error during create snapshot: %w", err),
+			attribute.String("sandbox.id", s.SandboxID()),
+		)
+		return err
+	}
 	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.snapshotMu.Lock()
+	s.snapshotCancel = cancel
+	s.snapshotMu.Unlock()
+	defer func() {
+		s.snapshotMu.Lock()
+		s.snapshotCancel = nil
+		s.snapshotMu.Unlock()
+	}()
+
+	// check after registering the cancel func, so that a concurrent Stop()
+	// either cancels the snapshot, or is observed here.
+	if s.stopping.Load() {
+		err := ErrSandboxStopping
+		telemetry.ReportError(ctx, fmt.Errorf("error during create snapshot: %w", err),
+			attribute.String("state", s.State.String()),
+			attribute.String("sandbox.id", s.SandboxID()),
+		)
+		return err
+	}
 	if s.State != orchestrator.SandboxState_RUNNING {
 		err := InvalidSandboxState
 		errMsg := fmt.Errorf("error during create snapshot: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg,
+		telemetry.ReportCriticalError(ctx, errMsg,
 			attribute.String("state", s.State.String()),
 			attribute.String("sandbox.id", s.SandboxID()),
 		)
 		return err
 	}
-	s.State = orchestrator.SandboxState_SNAPSHOTTING
-	snapshotDir := s.Config.EnvInstanceCreateSnapshotPath()
+	// the memfile of the last snapshot may be still being compressed
+	s.compressing.Wait()
+	s.setState(orchestrator.SandboxState_SNAPSHOTTING)
 	if err := utils.CreateDirAllIfNotExists(snapshotDir, 0o755); err != nil {
 		errMsg := fmt.Errorf("failed to create instance snapshot directory: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
+		telemetry.ReportCriticalError(ctx, errMsg)
 		return errMsg
 	}
-	if err := s.vmm.Pause(childCtx); err != nil {
-		s.State = orchestrator.SandboxState_INVALID
-		return err
+	if err := s.vmm.Pause(ctx); err != nil {
+		s.setState(orchestrator.SandboxState_INVALID)
+		return s.snapshotErr(ctx, err)
 	}
-	if err := s.vmm.Snapshot(childCtx, snapshotDir); err != nil {
-		s.State = orchestrator.SandboxState_INVALID
-		return err
+	if err := s.vmm.snapshot(ctx, tracer, snapshotDir, s.Config.SnapshotAttempts); err != nil {
+		s.setState(orchestrator.SandboxState_INVALID)
+		return s.snapshotErr(ctx, err)
+	}
+
+	var pausedErr error
+	if whilePaused != nil {
+		pausedErr = whilePaused(ctx)
 	}
 
 	if terminate {
-		if err := s.vmm.stop(childCtx, tracer); err != nil {
+		if err := s.vmm.stop(ctx, tracer); err != nil {
 			// no need to report error again
-			s.State = orchestrator.SandboxState_INVALID
-			return err
+			s.setState(orchestrator.SandboxState_INVALID)
+			return errors.Join(pausedErr, err)
 		}
-		s.State = orchestrator.SandboxState_STOP
+		s.stopping.Store(true)
+		s.cancelBackground()
+		s.setState(orchestrator.SandboxState_STOP)
 	} else {
 		// resume
-		if err := s.vmm.Resume(childCtx); err != nil {
-			s.State = orchestrator.SandboxState_INVALID
-			return err
+		if err := s.vmm.Resume(ctx); err != nil {
+			s.setState(orchestrator.SandboxState_INVALID)
+			return errors.Join(pausedErr, err)
 		}
-		s.State = orchestrator.SandboxState_RUNNING
+		s.setState(orchestrator.SandboxState_RUNNING)
+		s.resyncClockAfterResume(tracer)
 	}
-	return nil
+	if pausedErr == nil && afterResume != nil {
+		afterResume(ctx)
+	}
+	return pausedErr
+}
+
+// The snapshot canceled by Stop() is reported as ErrSandboxStopping,
+// the vm will be killed by Stop() so the INVALID state does not matter.
+func (s *Sandbox) snapshotErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil && s.stopping.Load() {
+		return fmt.Errorf("%w: snapshot canceled: %w", ErrSandboxStopping, err)
+	}
+	return err
 }
 
 // Wait for the sandbox process has been exited and also
@@ -371,26 +689,107 @@ func (s *Sandbox) setupPrometheusTarget(ctx context.Context, tracer trace.Tracer
 	}
 	config := []PrometheusTargetConfig{
 		{
-			Targets: []string{"host.docker.internal:6666"},
+			Targets: []string{s.Config.PrometheusProxyAddr},
 			Labels: map[string]string{
 				"id":               s.SandboxID(),
-				"__metrics_path__": fmt.Sprintf("/%s/%d/metrics", s.SandboxID(), consts.DefaultEnvdServerPort),
+				"__metrics_path__": s.prometheusMetricsPath(),
 			},
 		},
 	}
-	f, err := os.OpenFile(s.Config.PrometheusTargetPath(), os.O_CREATE|os.O_EXCL|os.O_RDWR, 0o666)
+	b, err := json.Marshal(config)
 	if err != nil {
-		return fmt.Errorf("open prometheus target file (%s) failed: %w", s.Config.PrometheusTargetPath(), err)
+		return fmt.Errorf("marshal prometheus target failed: %w", err)
 	}
-	defer f.Close()
-	if err := json.NewEncoder(f).Encode(config); err != nil {
-		return fmt.Errorf("write prometheus target file (%s) failed: %w", s.Config.PrometheusTargetPath(), err)
+	// write to a tmp file (not matched by the file_sd pattern) and then rename,
+	// so that prometheus never reads a partially written file.
+	s.targetMu.Lock()
+	defer s.targetMu.Unlock()
+	path := s.Config.PrometheusTargetPath()
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0o666); err != nil {
+		return fmt.Errorf("write prometheus target file (%s) failed: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename prometheus target file (%s) failed: %w", path, err)
 	}
 	return nil
 }
 
+// Rewrite the prometheus target file of a running sandbox, e.g., when
+// the write after create failed (see setupPrometheusTarget).
+func (s *Sandbox) RefreshPrometheusTarget(ctx context.Context, tracer trace.Tracer) error {
+	s.mu.Lock()
+	state := s.State
+	s.mu.Unlock()
+	// the target file is removed when cleaning up the stopped sandbox
+	if state == orchestrator.SandboxState_STOP || state == orchestrator.SandboxState_INVALID {
+		return fmt.Errorf("%w: %s", InvalidSandboxState, state)
+	}
+	return s.setupPrometheusTarget(ctx, tracer)
+}
+
+// Rebuild the network of the running sandbox in place, see
+// [NetworkManager.RecreateSandboxNetwork].
+func (s *Sandbox) RecreateNetwork(ctx context.Context, tracer trace.Tracer, nm *NetworkManager) error {
+	s.mu.Lock()
+	state := s.State
+	s.mu.Unlock()
+	// the network is being (or has been) released
+	if state == orchestrator.SandboxState_STOP || state == orchestrator.SandboxState_INVALID {
+		return fmt.Errorf("%w: %s", InvalidSandboxState, state)
+	}
+	return nm.RecreateSandboxNetwork(ctx, tracer, s.Net, int(s.getPid()))
+}
+
+func (s *Sandbox) prometheusMetricsPath() string {
+	return fmt.Sprintf("/%s/%d/metrics", s.SandboxID(), s.Config.EnvdServerPort())
+}
+
+// Fetch the metrics path of sandbox through the proxy once, so that
+// misconfigured proxy or unreachable guest can be detected at create time,
+// rather than silent scrape gaps in prometheus.
+func (s *Sandbox) probePrometheusTarget(ctx context.Context, tracer trace.Tracer) {
+	childCtx, childSpan := tracer.Start(ctx, "probe-prometheus-target")
+	defer childSpan.End()
+
+	err := func() error {
+		ctx, cancel := context.WithTimeout(childCtx, s.Config.PrometheusProbeTimeout)
+		defer cancel()
+		address := fmt.Sprintf("http://%s%s", s.Config.PrometheusProbeAddr, s.prometheusMetricsPath())
+		request, err := http.NewRequestWithContext(ctx, "GET", address, nil)
+		if err != nil {
+			return err
+		}
+		response, err := s.envdClient().Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if _, err := io.Copy(io.Discard, response.Body); err != nil {
+			return err
+		}
+		if response.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status code %d from %s", response.StatusCode, address)
+		}
+		return nil
+	}()
+	if err != nil {
+		probeFailures.Add(childCtx, 1)
+		telemetry.ReportError(childCtx, fmt.Errorf("prometheus target probe failed: %w", err))
+		return
+	}
+	telemetry.ReportEvent(childCtx, "prometheus target probed")
+}
+
 func (s *Sandbox) getPid() uint32 {
-	return uint32(s.vmm.cmd.Process.Pid)
+	return uint32(s.vmm.proc.Pid)
+}
+
+func (s *Sandbox) GetState() orchestrator.SandboxState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.State
 }
 
 func (s *Sandbox) GetSandboxInfo() orchestrator.SandboxInfo {
@@ -410,5 +809,7 @@ func (s *Sandbox) GetSandboxInfo() orchestrator.SandboxInfo {
 		EnableDiffSnapshots: &sbxDiffSnapshot,
 		StartTime:           timestamppb.New(s.StartAt),
 		State:               s.State,
+		Metadata:            s.Metadata(),
+		PortForwards:        portForwardsToProto(s.Config.PortForwards),
 	}
 }
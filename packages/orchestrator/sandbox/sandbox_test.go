@@ -0,0 +1,136 @@
+package sandbox
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+// The mountpoint of a cgroup2 filesystem, the test is skipped if none.
+func findCgroup2Mount(t *testing.T) string {
+	t.Helper()
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		t.Skipf("cannot read mounts: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && fields[2] == "cgroup2" {
+			return fields[1]
+		}
+	}
+	t.Skip("cgroup2 is not mounted")
+	return ""
+}
+
+// The pids of zombie children of the test process.
+func zombieChildren(t *testing.T) []string {
+	t.Helper()
+	stats, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var zombies []string
+	for _, path := range stats {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// exited in the meantime
+			continue
+		}
+		// pid (comm) state ppid ..., where comm may contain spaces
+		stat := string(data)
+		fields := strings.Fields(stat[strings.LastIndexByte(stat, ')')+1:])
+		if len(fields) >= 2 && fields[0] == "Z" && fields[1] == fmt.Sprint(os.Getpid()) {
+			zombies = append(zombies, filepath.Base(filepath.Dir(path)))
+		}
+	}
+	return zombies
+}
+
+// The create timeout is reached when waiting for the socket of a vmm which
+// never creates it, everything acquired so far should be released.
+func TestNewSandboxTimeout(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("root is required to start the vmm")
+	}
+	// recycling the network checks the port forward chain
+	if _, err := exec.LookPath("iptables"); err != nil {
+		t.Skip("iptables is not installed")
+	}
+	cgroupfs := findCgroup2Mount(t)
+
+	hostsPath := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(hostsPath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dns, err := network.NewDNSWithPath(hostsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nm := newTestNetworkManager(t, "")
+	nm.dns = dns
+	addFreeNetwork(nm, 1, false)
+
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{
+			TemplateID: "create-timeout",
+			VCpuCount:  1,
+			MemoryMB:   128,
+			VmmType:    config.FIRECRACKER,
+		},
+		DataRoot:             t.TempDir(),
+		SandboxID:            "create-timeout-sandbox",
+		CgroupfsPath:         cgroupfs,
+		CgroupName:           fmt.Sprintf("sandbox-test-%d", os.Getpid()),
+		SocketPath:           filepath.Join(t.TempDir(), "fc.sock"),
+		HypervisorBinaryPath: "/bin/true",
+		// the socket is never created, so the wait lasts until the ctx is done
+		SocketWait: utils.SocketWaitOptions{
+			Timeout:         time.Minute,
+			PollInterval:    10 * time.Millisecond,
+			MaxPollInterval: 50 * time.Millisecond,
+		},
+	}
+	t.Cleanup(func() { os.Remove(cfg.cgroupParentPath()) })
+	if err := os.MkdirAll(cfg.TemplateImgDir(cfg.DataRoot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cfg.HostRootfsPath(cfg.DataRoot), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := NewSandbox(ctx, testTracer, cfg, nm); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	wrapper := nm.all[1]
+	if wrapper == nil || wrapper.state != free || len(nm.free) != 1 || nm.free[0] != 1 {
+		t.Fatalf("network should be released, got %+v (free %v)", wrapper, nm.free)
+	}
+	if hosts, err := os.ReadFile(hostsPath); err != nil || strings.Contains(string(hosts), cfg.SandboxID) {
+		t.Fatalf("dns entry should be deleted, got %q (err %v)", hosts, err)
+	}
+	for _, path := range []string{cfg.InstancePath(), cfg.CgroupPath()} {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Fatalf("%s should be removed, got %v", path, err)
+		}
+	}
+	if zombies := zombieChildren(t); len(zombies) != 0 {
+		t.Fatalf("vmm should be reaped, got zombies %v", zombies)
+	}
+}
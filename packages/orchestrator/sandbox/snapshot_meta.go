@@ -0,0 +1,100 @@
+package sandbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+// The file written into the instance snapshot dir along with the snapshot.
+const SnapshotMetaName = "snapshot-meta.json"
+
+// What the instance snapshot is taken from, so that a template can be
+// reconstructed from the snapshot (i.e., the same as SnapshotAsTemplate)
+// without the sandbox.
+type SnapshotMeta struct {
+	SandboxID string `json:"sandboxID"`
+	// the template which the sandbox is created from
+	TemplateID    string         `json:"templateID"`
+	VmmType       config.VMMType `json:"vmmType"`
+	VCpuCount     int64          `json:"vcpu"`
+	MemoryMB      int64          `json:"memMB"`
+	KernelVersion string         `json:"kernelVersion"`
+	Overlay       bool           `json:"overlay"`
+	// the private dir recorded in the snapshot (see VMTemplate.SnapshotPrivateDir)
+	PrivateDir   string `json:"privateDir"`
+	DiffSnapshot bool   `json:"diffSnapshot"`
+	// the network configured inside the guest, which the restored vm expects
+	// from the netns (see network.NetworkEnv)
+	TapName  string `json:"tapName"`
+	GuestIP  string `json:"guestIP"`
+	GuestMAC string `json:"guestMAC"`
+	IPv6     bool   `json:"ipv6,omitempty"`
+	// the memory of guest (i.e., MemoryOverrideMB if set) differs from
+	// the template when not 0
+	MemoryOverrideMB int64 `json:"memoryOverrideMB,omitempty"`
+	// the kernel booted instead of the one of template (see KernelImagePathOverride)
+	KernelImagePath string    `json:"kernelImagePath,omitempty"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+func (s *Sandbox) snapshotMeta() SnapshotMeta {
+	cfg := s.Config
+	meta := SnapshotMeta{
+		SandboxID:        cfg.SandboxID,
+		TemplateID:       cfg.TemplateID,
+		VmmType:          cfg.VmmType,
+		VCpuCount:        cfg.VCpuCount,
+		MemoryMB:         cfg.MemoryMB,
+		KernelVersion:    cfg.KernelVersion,
+		Overlay:          cfg.Overlay,
+		PrivateDir:       cfg.PrivateDir(cfg.DataRoot),
+		DiffSnapshot:     cfg.EnableDiffSnapshot,
+		GuestMAC:         cfg.guestMAC(),
+		MemoryOverrideMB: cfg.MemoryOverrideMB,
+		KernelImagePath:  cfg.KernelImagePathOverride,
+		CreatedAt:        time.Now(),
+	}
+	if s.Net != nil {
+		meta.TapName = s.Net.TapName()
+		meta.GuestIP = s.Net.GuestIP()
+		meta.IPv6 = s.Net.IPv6Enabled()
+	}
+	return meta
+}
+
+// Write the SnapshotMeta of sandbox into dir, it is written to a tmp file
+// and then renamed, so that a partial written file is never seen.
+func (s *Sandbox) writeSnapshotMeta(dir string) error {
+	b, err := json.MarshalIndent(s.snapshotMeta(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal snapshot meta failed: %w", err)
+	}
+	path := filepath.Join(dir, SnapshotMetaName)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, b, 0o644); err != nil {
+		return fmt.Errorf("write snapshot meta (%s) failed: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("rename snapshot meta (%s) failed: %w", path, err)
+	}
+	return nil
+}
+
+// Read the SnapshotMeta written along with the instance snapshot in dir.
+func ReadSnapshotMeta(dir string) (*SnapshotMeta, error) {
+	b, err := os.ReadFile(filepath.Join(dir, SnapshotMetaName))
+	if err != nil {
+		return nil, err
+	}
+	var meta SnapshotMeta
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("unmarshal snapshot meta in %s failed: %w", dir, err)
+	}
+	return &meta, nil
+}
@@ -0,0 +1,299 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+var testTracer = noop.NewTracerProvider().Tracer("test")
+
+// fakeHypervisor blocks in Snapshot until release is closed (or ctx is canceled).
+type fakeHypervisor struct {
+	snapshotStarted chan struct{}
+	startedOnce     sync.Once
+	release         chan struct{}
+	balloonMiB      int64
+	// written as the memfile of snapshot, "memory" if nil
+	memfile []byte
+}
+
+func newFakeHypervisor() *fakeHypervisor {
+	return &fakeHypervisor{
+		snapshotStarted: make(chan struct{}),
+		release:         make(chan struct{}),
+	}
+}
+
+func (h *fakeHypervisor) Configure(ctx context.Context) error           { return nil }
+func (h *fakeHypervisor) Start(ctx context.Context) error               { return nil }
+func (h *fakeHypervisor) Pause(ctx context.Context) error               { return nil }
+func (h *fakeHypervisor) Resume(ctx context.Context) error              { return nil }
+func (h *fakeHypervisor) Restore(ctx context.Context, dir string) error { return nil }
+func (h *fakeHypervisor) Cleanup(ctx context.Context) error             { return nil }
+func (h *fakeHypervisor) SetBalloon(ctx context.Context, amountMiB int64) error {
+	h.balloonMiB = amountMiB
+	return nil
+}
+func (h *fakeHypervisor) Balloon(ctx context.Context) (int64, error) {
+	return h.balloonMiB, nil
+}
+func (h *fakeHypervisor) AttachDisk(ctx context.Context, id, path string, readOnly bool) error {
+	return nil
+}
+
+func (h *fakeHypervisor) Snapshot(ctx context.Context, dir string) error {
+	h.startedOnce.Do(func() { close(h.snapshotStarted) })
+	select {
+	case <-h.release:
+		memfile := h.memfile
+		if memfile == nil {
+			memfile = []byte("memory")
+		}
+		// the snapshot is verified after created, so the files cannot be empty
+		if err := os.WriteFile(filepath.Join(dir, consts.FcSnapfileName), []byte("state"), 0o644); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, consts.FcMemfileName), memfile, 0o644)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// envd is not reachable in tests
+type unreachableTransport struct{}
+
+func (unreachableTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("envd is unreachable")
+}
+
+func newTestSandbox(t *testing.T, cancelSnapshotOnDelete bool) (*Sandbox, *fakeHypervisor) {
+	cmd := exec.Command("sleep", "60")
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("spawn process failed: %s", err)
+	}
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+	h := newFakeHypervisor()
+	return &Sandbox{
+		vmm: vmm{Hypervisor: h, cmd: cmd, proc: cmd.Process},
+		Config: &SandboxConfig{
+			SandboxID:              "test-sandbox",
+			CancelSnapshotOnDelete: cancelSnapshotOnDelete,
+			EnvdClient:             &http.Client{Transport: unreachableTransport{}},
+		},
+		Net:   &network.SandboxNetwork{},
+		State: orchestrator.SandboxState_RUNNING,
+	}, h
+}
+
+func startSnapshot(t *testing.T, sbx *Sandbox, terminate bool) <-chan error {
+	dir := t.TempDir()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- sbx.createSnapshot(context.Background(), testTracer, dir, terminate, nil, nil)
+	}()
+	return errCh
+}
+
+func waitErr(t *testing.T, errCh <-chan error, name string) error {
+	select {
+	case err := <-errCh:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s does not return", name)
+		return nil
+	}
+}
+
+func TestSnapshotRejectedAfterStop(t *testing.T) {
+	sbx, _ := newTestSandbox(t, false)
+	if err := sbx.Stop(context.Background(), testTracer); err != nil {
+		t.Fatalf("stop failed: %s", err)
+	}
+	err := sbx.createSnapshot(context.Background(), testTracer, t.TempDir(), false, nil, nil)
+	if !errors.Is(err, ErrSandboxStopping) {
+		t.Fatalf("expect ErrSandboxStopping, got %v", err)
+	}
+	if sbx.State != orchestrator.SandboxState_STOP {
+		t.Fatalf("expect state STOP, got %s", sbx.State)
+	}
+}
+
+func TestDeleteWaitsForSnapshot(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	snapshotErr := startSnapshot(t, sbx, false)
+	<-h.snapshotStarted
+
+	stopErr := make(chan error, 1)
+	go func() {
+		stopErr <- sbx.Stop(context.Background(), testTracer)
+	}()
+	select {
+	case <-stopErr:
+		t.Fatal("stop returns before the snapshot finishes")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(h.release)
+	if err := waitErr(t, snapshotErr, "snapshot"); err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+	if err := waitErr(t, stopErr, "stop"); err != nil {
+		t.Fatalf("stop failed: %s", err)
+	}
+	if sbx.State != orchestrator.SandboxState_STOP {
+		t.Fatalf("expect state STOP, got %s", sbx.State)
+	}
+}
+
+func TestDeleteCancelsSnapshot(t *testing.T) {
+	sbx, h := newTestSandbox(t, true)
+	snapshotErr := startSnapshot(t, sbx, false)
+	<-h.snapshotStarted
+
+	if err := sbx.Stop(context.Background(), testTracer); err != nil {
+		t.Fatalf("stop failed: %s", err)
+	}
+	if err := waitErr(t, snapshotErr, "snapshot"); !errors.Is(err, ErrSandboxStopping) {
+		t.Fatalf("expect ErrSandboxStopping, got %v", err)
+	}
+	if sbx.State != orchestrator.SandboxState_STOP {
+		t.Fatalf("expect state STOP, got %s", sbx.State)
+	}
+}
+
+func TestDeleteAfterTerminatingSnapshot(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	close(h.release)
+	if err := waitErr(t, startSnapshot(t, sbx, true), "snapshot"); err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+	if err := sbx.Stop(context.Background(), testTracer); err != nil {
+		t.Fatalf("stop after terminating snapshot failed: %s", err)
+	}
+	err := sbx.createSnapshot(context.Background(), testTracer, t.TempDir(), false, nil, nil)
+	if !errors.Is(err, ErrSandboxStopping) {
+		t.Fatalf("expect ErrSandboxStopping, got %v", err)
+	}
+}
+
+func TestConcurrentSnapshotWaits(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	first := startSnapshot(t, sbx, false)
+	<-h.snapshotStarted
+
+	second := startSnapshot(t, sbx, false)
+	select {
+	case err := <-second:
+		t.Fatalf("the concurrent snapshot returns before the first one finishes: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	close(h.release)
+	if err := waitErr(t, first, "first snapshot"); err != nil {
+		t.Fatalf("first snapshot failed: %s", err)
+	}
+	if err := waitErr(t, second, "second snapshot"); err != nil {
+		t.Fatalf("second snapshot failed: %s", err)
+	}
+	if sbx.State != orchestrator.SandboxState_RUNNING {
+		t.Fatalf("expect state RUNNING, got %s", sbx.State)
+	}
+}
+
+func TestConcurrentSnapshotGiveUp(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	first := startSnapshot(t, sbx, false)
+	<-h.snapshotStarted
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := sbx.createSnapshot(ctx, testTracer, t.TempDir(), false, nil, nil)
+	if !errors.Is(err, ErrSnapshotInProgress) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect ErrSnapshotInProgress, got %v", err)
+	}
+
+	close(h.release)
+	if err := waitErr(t, first, "first snapshot"); err != nil {
+		t.Fatalf("first snapshot failed: %s", err)
+	}
+	// mu is released by the one gave up
+	if err := waitErr(t, startSnapshot(t, sbx, false), "snapshot"); err != nil {
+		t.Fatalf("snapshot after the concurrent one gave up failed: %s", err)
+	}
+}
+
+func TestCompressMemfileInBackground(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd is not installed")
+	}
+	sbx, h := newTestSandbox(t, false)
+	h.memfile = []byte("memory")
+	close(h.release)
+	dir := t.TempDir()
+
+	done := make(chan error, 1)
+	err := sbx.createSnapshot(context.Background(), testTracer, dir, false, nil, func(ctx context.Context) {
+		sbx.compressMemfile(ctx, testTracer, dir, func(ctx context.Context, err error) {
+			done <- err
+		})
+	})
+	if err != nil {
+		t.Fatalf("snapshot failed: %s", err)
+	}
+	if err := waitErr(t, done, "compression"); err != nil {
+		t.Fatalf("compress memfile failed: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, consts.FcCompressedMemfileName)); err != nil {
+		t.Fatalf("expect compressed memfile, got %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, consts.FcMemfileName)); !os.IsNotExist(err) {
+		t.Fatalf("expect the uncompressed memfile removed, stat err: %v", err)
+	}
+
+	// the next snapshot is not affected
+	if err := sbx.createSnapshot(context.Background(), testTracer, dir, false, nil, nil); err != nil {
+		t.Fatalf("snapshot after compression failed: %s", err)
+	}
+}
+
+func TestCreateSnapshotWritesMeta(t *testing.T) {
+	sbx, h := newTestSandbox(t, false)
+	sbx.Config.SnapshotRoot = t.TempDir()
+	sbx.Config.TemplateID = "test-template"
+	sbx.Config.VmmType = config.FIRECRACKER
+	sbx.Config.VCpuCount = 2
+	sbx.Config.MemoryMB = 512
+	close(h.release)
+	if err := sbx.CreateSnapshot(context.Background(), testTracer, "", true); err != nil {
+		t.Fatalf("create snapshot failed: %s", err)
+	}
+
+	dir := sbx.Config.EnvInstanceCreateSnapshotPath()
+	meta, err := ReadSnapshotMeta(dir)
+	if err != nil {
+		t.Fatalf("read snapshot meta failed: %s", err)
+	}
+	if meta.SandboxID != "test-sandbox" || meta.TemplateID != "test-template" ||
+		meta.VCpuCount != 2 || meta.MemoryMB != 512 || meta.GuestIP != consts.GuestNetIPAddr {
+		t.Fatalf("unexpected snapshot meta %+v", meta)
+	}
+	if _, err := os.Stat(filepath.Join(dir, SnapshotMetaName+".tmp")); !os.IsNotExist(err) {
+		t.Fatalf("the tmp file of snapshot meta should be removed, got %v", err)
+	}
+}
@@ -1,18 +1,13 @@
 package sandbox
 
 import (
-	"fmt"
-	"os"
-	"path/filepath"
-
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 )
 
-func GetSocketPath(sandboxID string) (string, error) {
-	filename := "vmm-" + sandboxID + ".socket"
-	if dir := os.TempDir(); utils.CheckDirExists(dir) {
-		return filepath.Join(dir, filename), nil
-	} else {
-		return "", fmt.Errorf("unable to find a location for vmm socket")
-	}
+// the prefix of the name of the api socket of vmm
+const SocketPrefix = "vmm-"
+
+// The api socket of vmm of the sandbox under dir (see socket_dir).
+func GetSocketPath(dir, sandboxID string) string {
+	return utils.SocketPath(dir, SocketPrefix, sandboxID)
 }
@@ -0,0 +1,174 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/KarpelesLab/reflink"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrTemplateExists    = errors.New("template already exists")
+	ErrInvalidTemplateID = errors.New("invalid template id")
+	// the memfile of diff snapshot only contains the dirty pages.
+	ErrDiffSnapshotTemplate = errors.New("cannot create template from sandbox with diff snapshot enabled")
+	// the kernel of template is derived from its kernel_version.
+	ErrKernelOverrideTemplate = errors.New("cannot create template from sandbox with kernel image overridden")
+)
+
+func validateTemplateID(templateID string) error {
+	if templateID == "" ||
+		templateID == "." ||
+		templateID == ".." ||
+		strings.ContainsAny(templateID, "/\\") {
+		return fmt.Errorf("%w: %q", ErrInvalidTemplateID, templateID)
+	}
+	return nil
+}
+
+// Snapshot the sandbox and register it as a new template (with the id of templateID),
+// which contains the snapshot files and a copy of the current rootfs.
+//
+// The snapshot records the disk and kernel paths under the private dir of the source
+// template, so the new template reuses that private dir (see [config.VMTemplate.SnapshotPrivateDir]),
+// which means the template dir of the source template should be kept.
+//
+// The template is published (i.e., template.toml is dumped) at last, and when it
+// enables compress_memfile, that happens in background after the memfile is compressed.
+//
+// @terminate: true to kill the vm, false to resume the vm after generating snapshot
+// @published: (optional) called once the template is published (or failed to)
+func (s *Sandbox) SnapshotAsTemplate(
+	ctx context.Context,
+	tracer trace.Tracer,
+	templateID string,
+	terminate bool,
+	published func(err error),
+) (*config.VMTemplate, error) {
+	childCtx, childSpan := tracer.Start(ctx, "sandbox-snapshot-as-template", trace.WithAttributes(
+		attribute.String("sandbox.id", s.SandboxID()),
+		attribute.String("template.id", templateID),
+	))
+	defer childSpan.End()
+
+	if err := validateTemplateID(templateID); err != nil {
+		return nil, err
+	}
+	if s.Config.EnableDiffSnapshot {
+		return nil, ErrDiffSnapshotTemplate
+	}
+	if s.Config.KernelImagePathOverride != "" {
+		return nil, ErrKernelOverrideTemplate
+	}
+
+	t := s.Config.VMTemplate
+	t.TemplateID = templateID
+	t.SnapshotPrivateDir = s.Config.PrivateDir(s.Config.DataRoot)
+	// the disks in snapshot are limited as the sandbox
+	limit := s.Config.diskRateLimit()
+	t.DiskBandwidthBps, t.DiskIops = limit.BandwidthBps, limit.Iops
+	// the snapshot is taken on this host rather than the one of source template
+	t.SnapshotCPU = nil
+	if cpu, err := config.ReadHostCPU(); err != nil {
+		telemetry.ReportError(childCtx, fmt.Errorf("read host cpu failed: %w", err))
+	} else {
+		t.SnapshotCPU = cpu
+	}
+
+	templateDir := t.TemplateDir(s.Config.DataRoot)
+	if err := utils.CreateDirAllIfNotExists(filepath.Dir(templateDir), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating templates dir: %w", err)
+	}
+	// os.Mkdir fails if the template exists, which also prevents concurrent
+	// creating of the same template.
+	if err := os.Mkdir(templateDir, 0o755); err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrTemplateExists, templateID)
+		}
+		return nil, fmt.Errorf("error creating template dir: %w", err)
+	}
+
+	// dump at last, so the template is not visible to Create() until it is complete
+	publish := func(ctx context.Context, err error) error {
+		// the rootfs differs from the source template, recompute the checksums
+		if err == nil && len(t.Checksums) > 0 {
+			err = t.ComputeChecksums(s.Config.DataRoot)
+		}
+		if err == nil {
+			err = t.Dump(s.Config.DataRoot)
+		}
+		if err != nil {
+			errMsg := fmt.Errorf("error creating template %s from sandbox: %w", templateID, err)
+			telemetry.ReportCriticalError(ctx, errMsg)
+			if rmErr := os.RemoveAll(templateDir); rmErr != nil {
+				telemetry.ReportError(ctx, fmt.Errorf("error removing template dir: %w", rmErr))
+			}
+			err = errMsg
+		} else {
+			telemetry.ReportEvent(ctx, "template created from sandbox")
+		}
+		if published != nil {
+			published(err)
+		}
+		return err
+	}
+	var afterResume func(ctx context.Context)
+	if t.CompressMemfile {
+		afterResume = func(ctx context.Context) {
+			s.compressMemfile(ctx, tracer, t.TemplateImgDir(s.Config.DataRoot), func(ctx context.Context, err error) {
+				publish(ctx, err)
+			})
+		}
+	}
+	err := s.createSnapshot(
+		childCtx,
+		tracer,
+		t.TemplateImgDir(s.Config.DataRoot),
+		terminate,
+		func(ctx context.Context) error {
+			// copy the rootfs while vm is paused, so it is consistent with the snapshot
+			return s.copyRootfsToTemplate(ctx, &t)
+		},
+		afterResume,
+	)
+	if err != nil || !t.CompressMemfile {
+		if err := publish(childCtx, err); err != nil {
+			return nil, err
+		}
+	}
+	return &t, nil
+}
+
+func (s *Sandbox) copyRootfsToTemplate(ctx context.Context, t *config.VMTemplate) error {
+	dataRoot := s.Config.DataRoot
+	if t.Overlay {
+		// the base rootfs is read-only, so a hard link is enough
+		if err := os.Link(s.Config.InstanceRootfsPath(), t.HostRootfsPath(dataRoot)); err != nil {
+			return fmt.Errorf("error linking base rootfs: %w", err)
+		}
+		if err := reflink.Auto(s.Config.InstanceWritableRootfsPath(), t.HostWritableRootfsPath(dataRoot)); err != nil {
+			return fmt.Errorf("error copying writable rootfs: %w", err)
+		}
+	} else if t.ImmutableRootfs {
+		if err := os.Link(s.Config.InstanceRootfsPath(), t.HostRootfsPath(dataRoot)); err != nil {
+			return fmt.Errorf("error linking immutable rootfs: %w", err)
+		}
+	} else {
+		if err := reflink.Auto(s.Config.InstanceRootfsPath(), t.HostRootfsPath(dataRoot)); err != nil {
+			return fmt.Errorf("error copying rootfs: %w", err)
+		}
+	}
+	telemetry.ReportEvent(ctx, "copied rootfs to template",
+		attribute.String("rootfs", t.HostRootfsPath(dataRoot)),
+	)
+	return nil
+}
@@ -2,6 +2,7 @@ package sandbox
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/ch"
@@ -26,9 +28,20 @@ import (
 
 type vmm struct {
 	hypervisor.Hypervisor
-	cmd *exec.Cmd
+	// nil for the vmm reattached after orchestrator restarts
+	// (as it is not the child of current orchestrator).
+	cmd  *exec.Cmd
+	proc *os.Process
+	// where the files of snapshot are staged (on host), not empty only if
+	// the vmm runs inside the chroot of jailer.
+	jailedSnapshotDir string
+	// not nil if the vmm runs as an unprivileged user
+	jailer *JailerOptions
 }
 
+// Start the vmm process and restore (or receive the migration of) the sandbox.
+// On error, the returned vmm may have a started process (i.e., proc is not nil),
+// which should be stopped and waited by the caller.
 func newVmm(
 	ctx context.Context,
 	tracer trace.Tracer,
@@ -45,6 +58,13 @@ func newVmm(
 		"fc-vmm",
 	)
 
+	if pageSize := cfg.HugePage().Bytes(); pageSize != 0 {
+		if err := utils.CheckFreeHugePages(pageSize, cfg.GuestMemoryMB()<<20); err != nil {
+			telemetry.ReportCriticalError(childCtx, err)
+			return vmm, err
+		}
+	}
+
 	// TODO: refactor this, use unshare + mount syscall directly
 	currentBinPath, err := os.Executable()
 	if err != nil {
@@ -65,23 +85,64 @@ func newVmm(
 	kernelMountCmd := fmt.Sprintf(
 		"%s %s %s && ",
 		bindMountBinPath,
-		cfg.HostKernelPath(cfg.DataRoot),
+		cfg.HostKernelImagePath(),
 		cfg.PrivateKernelPath(cfg.DataRoot),
 	)
 
 	inNetNSCmd := fmt.Sprintf("ip netns exec %s ", net.NetNsName())
+	// golang's SysProcAttr cannot carry a seccomp filter, so we install it
+	// with a small helper right before exec the hypervisor. In this way, the
+	// filter only covers the vmm (and its children), while the bind mount and
+	// netns setup above are not restricted.
+	var seccompCmd string
+	if cfg.SeccompProfilePath != "" {
+		seccompCmd = fmt.Sprintf(
+			"%s %s -- ",
+			utils.ShellQuote(filepath.Join(filepath.Dir(currentBinPath), "seccomp_exec")),
+			utils.ShellQuote(cfg.SeccompProfilePath),
+		)
+	}
 	var hypervisorCmd string
 	switch cfg.VmmType {
 	case config.FIRECRACKER:
-		hypervisorCmd = hypervisor.FirecrackerCmd(cfg.HypervisorBinaryPath, cfg.SocketPath)
+		hypervisorCmd, err = hypervisor.FirecrackerCmd(cfg.HypervisorBinaryPath, cfg.SocketPath, cfg.ExtraHypervisorArgs)
 	case config.CLOUDHYPERVISOR:
-		hypervisorCmd = hypervisor.CloudHypervisorCmd(cfg.HypervisorBinaryPath, cfg.SocketPath)
+		hypervisorCmd, err = hypervisor.CloudHypervisorCmd(cfg.HypervisorBinaryPath, cfg.SocketPath, cfg.ExtraHypervisorArgs)
 	default:
-		err := config.InvalidVmmType
+		err = config.InvalidVmmType
+	}
+	if err != nil {
 		telemetry.ReportCriticalError(childCtx, err)
 		return vmm, err
 	}
 
+	if cfg.Jailer != nil {
+		if err := cfg.prepareJail(); err != nil {
+			errMsg := fmt.Errorf("prepare jail failed: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return vmm, errMsg
+		}
+		vmm.jailer = cfg.Jailer
+	}
+	if cfg.chrooted() {
+		// the jailer joins the netns by itself
+		inNetNSCmd = ""
+		rootfsMountCmd, kernelMountCmd = "", ""
+		for _, mount := range cfg.jailMounts() {
+			rootfsMountCmd += fmt.Sprintf("%s %s %s && ", bindMountBinPath, mount[0], mount[1])
+		}
+		hypervisorCmd, err = jailerCmd(cfg, net)
+		if err != nil {
+			telemetry.ReportCriticalError(childCtx, err)
+			return vmm, err
+		}
+		vmm.jailedSnapshotDir = cfg.jailedPath(jailedSnapshotDir)
+	} else if cfg.Jailer != nil {
+		// drop the privilege before installing the seccomp filter,
+		// which may not allow setuid
+		seccompCmd = setprivCmd(cfg.Jailer) + seccompCmd
+	}
+
 	cmd := exec.Command(
 		"unshare",
 		"-pfm",
@@ -89,7 +150,7 @@ func newVmm(
 		"--",
 		"bash",
 		"-c",
-		rootfsMountCmd+kernelMountCmd+inNetNSCmd+hypervisorCmd,
+		rootfsMountCmd+kernelMountCmd+inNetNSCmd+seccompCmd+hypervisorCmd,
 	)
 	cmdStdoutReader, cmdStdoutWriter := io.Pipe()
 	cmdStderrReader, cmdStderrWriter := io.Pipe()
@@ -115,8 +176,21 @@ func newVmm(
 		cmd.SysProcAttr.UseCgroupFD = true
 	}
 
-	go utils.RedirectVmmOutput(vmmCtx, "vmm stdout", cmdStdoutReader)
-	go utils.RedirectVmmOutput(vmmCtx, "vmm stderr", cmdStderrReader)
+	// NOTE: the serial console of guest is part of the output only if the
+	// kernel args of template include console=ttyS0 (see kernel_debug_output).
+	var tee io.Writer
+	if cfg.ConsoleLog {
+		consoleLog, err := utils.OpenRotatingFile(cfg.ConsoleLogPath(), cfg.ConsoleLogMaxSize)
+		if err != nil {
+			errMsg := fmt.Errorf("open console log failed: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return vmm, errMsg
+		}
+		cfg.consoleLog = consoleLog
+		tee = consoleLog
+	}
+	go utils.RedirectVmmOutput(vmmCtx, "vmm stdout", cmdStdoutReader, tee)
+	go utils.RedirectVmmOutput(vmmCtx, "vmm stderr", cmdStderrReader, tee)
 
 	err = cmd.Start()
 	if err != nil {
@@ -126,6 +200,7 @@ func newVmm(
 	}
 	telemetry.ReportEvent(childCtx, "vm started")
 	vmm.cmd = cmd
+	vmm.proc = cmd.Process
 
 	if !constants.Repurposable {
 		// migrate to cgroup
@@ -135,48 +210,154 @@ func newVmm(
 		telemetry.ReportEvent(childCtx, "vm miragted to cgroup")
 	}
 
+	if err := vmm.connect(childCtx, tracer, cfg, net, childSpan.SpanContext().TraceID().String()); err != nil {
+		return vmm, err
+	}
+
+	if cfg.MigrationReceiverURL != "" {
+		if err := vmm.receiveMigration(childCtx, tracer, cfg); err != nil {
+			errMsg := fmt.Errorf("failed to receive migration: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return vmm, errMsg
+		}
+		telemetry.ReportEvent(childCtx, "vm migrated")
+		return vmm, nil
+	}
+
+	if cfg.ColdBoot {
+		if err := vmm.coldBoot(childCtx, tracer, cfg); err != nil {
+			errMsg := fmt.Errorf("failed to cold boot: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return vmm, errMsg
+		}
+		telemetry.ReportEvent(childCtx, "vm cold booted")
+		return vmm, nil
+	}
+
+	// restore
+	if err := vmm.restore(childCtx, tracer, cfg); err != nil {
+		errMsg := fmt.Errorf("failed to restore: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		return vmm, errMsg
+	}
+	telemetry.ReportEvent(childCtx, "vm restored")
+	return vmm, nil
+}
+
+// The command to run firecracker under its jailer, which chroots into
+// cfg.JailerChrootDir(), joins the netns of sandbox and drops to the uid/gid
+// before exec firecracker. The files used by firecracker are bind mounted
+// into the chroot beforehand (see jailMounts).
+func jailerCmd(cfg *SandboxConfig, net *network.SandboxNetwork) (string, error) {
+	// jailer requires the absolute path of firecracker
+	fcPath, err := exec.LookPath(cfg.HypervisorBinaryPath)
+	if err != nil {
+		return "", fmt.Errorf("error finding firecracker binary: %w", err)
+	}
+	if fcPath, err = filepath.Abs(fcPath); err != nil {
+		return "", fmt.Errorf("error finding firecracker binary: %w", err)
+	}
+	jailer := fmt.Sprintf(
+		"%s --id %s --exec-file %s --uid %d --gid %d --chroot-base-dir %s --netns %s --",
+		utils.ShellQuote(cfg.Jailer.BinaryPath),
+		utils.ShellQuote(cfg.SandboxID),
+		utils.ShellQuote(fcPath),
+		cfg.Jailer.UID,
+		cfg.Jailer.GID,
+		utils.ShellQuote(cfg.Jailer.ChrootBaseDir),
+		utils.ShellQuote(filepath.Join("/var/run/netns", net.NetNsName())),
+	)
+	// the args after "--" are passed to firecracker, whose
+	// api socket is relative to the chroot
+	return hypervisor.FirecrackerCmd(jailer, jailedSocketPath, cfg.ExtraHypervisorArgs)
+}
+
+// The prefix to drop the privilege (including the ambient caps) of the
+// command, e.g., cloud hypervisor.
+func setprivCmd(j *JailerOptions) string {
+	return fmt.Sprintf(
+		"setpriv --reuid %d --regid %d --clear-groups --inh-caps -all --bounding-set -all -- ",
+		j.UID, j.GID,
+	)
+}
+
+// connect to the api socket of the (already started) vmm process
+func (vmm *vmm) connect(
+	ctx context.Context,
+	tracer trace.Tracer,
+	cfg *SandboxConfig,
+	net *network.SandboxNetwork,
+	traceID string,
+) error {
 	switch cfg.VmmType {
 	case config.FIRECRACKER:
 		// Wait for the FC process to start so we can use FC API
-		client, err := firecracker.WaitForSocket(childCtx, tracer, cfg.SocketPath, consts.WaitTimeForHypervisorSocket)
+		client, err := firecracker.WaitForSocket(ctx, tracer, cfg.SocketPath, cfg.SocketWait)
 		if err != nil {
 			errMsg := fmt.Errorf("error waiting for vmm socket: %w", err)
 
-			return vmm, errMsg
+			return errMsg
 		}
-		telemetry.ReportEvent(childCtx, "vmm process created fc socket")
-		vmm.Hypervisor = hypervisor.NewFirecracker(
-			getFcConfig(cfg, net, childSpan.SpanContext().TraceID().String()),
-			client,
-		)
+		telemetry.ReportEvent(ctx, "vmm process created fc socket")
+		vmm.Hypervisor = hypervisor.NewFirecracker(getFcConfig(cfg, net, traceID), client)
 	case config.CLOUDHYPERVISOR:
-		client, err := ch.WaitForSocket(childCtx, tracer, cfg.SocketPath, consts.WaitTimeForHypervisorSocket)
+		client, err := ch.WaitForSocket(ctx, tracer, cfg.SocketPath, cfg.SocketWait)
 		if err != nil {
 			errMsg := fmt.Errorf("error waiting for vmm socket: %w", err)
 
-			return vmm, errMsg
+			return errMsg
 		}
-		telemetry.ReportEvent(childCtx, "vmm process created ch socket")
+		telemetry.ReportEvent(ctx, "vmm process created ch socket")
 		vmm.Hypervisor = hypervisor.NewCloudHypervisor(getChConfig(cfg), client)
 	default:
 		err := config.InvalidVmmType
-		telemetry.ReportCriticalError(childCtx, err)
-		return vmm, err
+		telemetry.ReportCriticalError(ctx, err)
+		return err
 	}
+	return nil
+}
 
-	// restore
-	if err := vmm.restore(childCtx, tracer, cfg); err != nil {
-		vmm.stop(childCtx, tracer)
-		errMsg := fmt.Errorf("failed to restore: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
+// Reattach to the vmm process started by previous orchestrator.
+// The vmm has already been restored, so we only connect to its api socket.
+func attachVmm(
+	ctx context.Context,
+	tracer trace.Tracer,
+	cfg *SandboxConfig,
+	net *network.SandboxNetwork,
+	pid int,
+) (vmm, error) {
+	var vmm vmm
+
+	childCtx, childSpan := tracer.Start(ctx, "attach-vmm", trace.WithAttributes(
+		attribute.Int("pid", pid),
+	))
+	defer childSpan.End()
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return vmm, fmt.Errorf("find vmm process %d failed: %w", pid, err)
 	}
-	telemetry.ReportEvent(childCtx, "vm restored")
+	vmm.proc = proc
+	vmm.jailer = cfg.Jailer
+	if cfg.chrooted() {
+		vmm.jailedSnapshotDir = cfg.jailedPath(jailedSnapshotDir)
+	}
+
+	if err := vmm.connect(childCtx, tracer, cfg, net, childSpan.SpanContext().TraceID().String()); err != nil {
+		return vmm, err
+	}
+	telemetry.ReportEvent(childCtx, "vm reattached")
 	return vmm, nil
 }
 
 func (vmm vmm) restore(ctx context.Context, tracer trace.Tracer, cfg *SandboxConfig) error {
-	childCtx, childSpan := tracer.Start(ctx, "restore-vm")
+	childCtx, childSpan := tracer.Start(ctx, "restore-vm", trace.WithAttributes(
+		attribute.Bool("restore.prefault", cfg.PrefaultMemory),
+		attribute.Bool("restore.compressed_memfile", cfg.CompressMemfile),
+		attribute.Int64("restore.memory_mb", cfg.GuestMemoryMB()),
+	))
 	defer childSpan.End()
+	start := time.Now()
 	if err := vmm.Restore(childCtx, cfg.TemplateImgDir(cfg.DataRoot)); err != nil {
 		return err
 	}
@@ -187,14 +368,38 @@ func (vmm vmm) restore(ctx context.Context, tracer trace.Tracer, cfg *SandboxCon
 			return err
 		}
 	}
+	if err := vmm.resizeMemory(childCtx, cfg); err != nil {
+		return err
+	}
+	if err := vmm.attachExtraDisks(childCtx, cfg); err != nil {
+		return err
+	}
+	childSpan.SetAttributes(attribute.Int64("restore.duration_ms", time.Since(start).Milliseconds()))
 	return nil
 }
 
+// Configure and boot the vm as the template manager does when building
+// the template, the disks have been configured by getFcConfig (or getChConfig).
+func (vmm vmm) coldBoot(ctx context.Context, tracer trace.Tracer, cfg *SandboxConfig) error {
+	childCtx, childSpan := tracer.Start(ctx, "cold-boot-vm")
+	defer childSpan.End()
+	if err := vmm.Configure(childCtx); err != nil {
+		return fmt.Errorf("configure vm failed: %w", err)
+	}
+	if err := vmm.Start(childCtx); err != nil {
+		return fmt.Errorf("start vm failed: %w", err)
+	}
+	if err := vmm.resizeMemory(childCtx, cfg); err != nil {
+		return err
+	}
+	return vmm.attachExtraDisks(childCtx, cfg)
+}
+
 func (vmm vmm) stop(ctx context.Context, tracer trace.Tracer) error {
 	childCtx, childSpan := tracer.Start(ctx, "stop-vmm")
 	defer childSpan.End()
 
-	err := vmm.cmd.Process.Kill()
+	err := vmm.proc.Kill()
 	if err != nil {
 		errMsg := fmt.Errorf("failed to send KILL to FC process: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
@@ -210,44 +415,66 @@ func (vmm vmm) stop(ctx context.Context, tracer trace.Tracer) error {
 // resouce related to vmm (e.g., the process id)
 func (vmm vmm) wait() error {
 	// close the vmm span
-	if vmm.cmd == nil {
-		return fmt.Errorf("fc has not started")
+	if vmm.cmd != nil {
+		return vmm.cmd.Wait()
 	}
-	return vmm.cmd.Wait()
+	if vmm.proc != nil {
+		return waitNonChildExit(vmm.proc.Pid)
+	}
+	return fmt.Errorf("fc has not started")
 }
 
-// create snaphot of the running vm
-//
-// @terminate: true to kill the vm, false to resume the vm after generating snapshot
-func (vmm vmm) snapshot(ctx context.Context, tracer trace.Tracer, dir string) error {
-	childCtx, childSpan := tracer.Start(ctx, "create-snapshot", trace.WithAttributes(
+// The reattached vmm is not our child, so we cannot wait4 it.
+// Instead, we poll on its pidfd, which becomes readable when the process exits.
+func waitNonChildExit(pid int) error {
+	pidfd, err := unix.PidfdOpen(pid, 0)
+	if err != nil {
+		if errors.Is(err, unix.ESRCH) {
+			// already exited
+			return nil
+		}
+		return fmt.Errorf("open pidfd of %d failed: %w", pid, err)
+	}
+	defer unix.Close(pidfd)
+	fds := []unix.PollFd{{Fd: int32(pidfd), Events: unix.POLLIN}}
+	for {
+		_, err := unix.Poll(fds, -1)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		return err
+	}
+}
+
+// Create the snapshot of the paused vm into dir (which should exist), and
+// verify it, retrying up to attempts times (see hypervisor.SnapshotVerified).
+func (vmm vmm) snapshot(ctx context.Context, tracer trace.Tracer, dir string, attempts int) error {
+	childCtx, childSpan := tracer.Start(ctx, "snapshot-vm", trace.WithAttributes(
 		attribute.String("instance.snapshot_dir", dir),
 	))
 	defer childSpan.End()
 
-	if err := utils.CreateDirAllIfNotExists(dir, 0o755); err != nil {
-		errMsg := fmt.Errorf("failed to create instance snapshot directory: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
-		return errMsg
-	}
-
-	{
-		ctx, span := tracer.Start(childCtx, "pause-vm")
-		err := vmm.Pause(ctx)
-		span.End()
-
-		if err != nil {
-			return err
+	// firecracker can only see the files inside its chroot, so the snapshot
+	// is staged in the chroot first and then moved into dir
+	snapshotDir, hostDir := dir, dir
+	if vmm.jailedSnapshotDir != "" {
+		snapshotDir, hostDir = jailedSnapshotDir, vmm.jailedSnapshotDir
+	} else if vmm.jailer != nil {
+		if err := os.Chown(dir, vmm.jailer.UID, vmm.jailer.GID); err != nil {
+			errMsg := fmt.Errorf("failed to chown instance snapshot directory: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return errMsg
 		}
-		telemetry.ReportEvent(childCtx, "vm paused")
 	}
 
-	{
-		ctx, span := tracer.Start(childCtx, "snapshot-vm")
-		err := vmm.Snapshot(ctx, dir)
-		span.End()
-		if err != nil {
-			return err
+	if _, err := hypervisor.SnapshotVerified(childCtx, vmm.Hypervisor, snapshotDir, hostDir, attempts); err != nil {
+		return err
+	}
+	if vmm.jailedSnapshotDir != "" {
+		if err := moveSnapshotFiles(vmm.jailedSnapshotDir, dir); err != nil {
+			errMsg := fmt.Errorf("failed to move snapshot out of chroot: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return errMsg
 		}
 	}
 	telemetry.ReportEvent(childCtx, "vm snapshot created")
@@ -270,7 +497,11 @@ func addProcToCgroup(cgroupPath string, pid int) error {
 
 func getFcConfig(cfg *SandboxConfig, net *network.SandboxNetwork, traceID string) *hypervisor.FcConfig {
 	logCollectorAddr := fmt.Sprintf("http://%s:%d", net.VethIP(), consts.DefaultLogCollectorPort)
-	return &hypervisor.FcConfig{
+	var memfileDecompressPath string
+	if cfg.CompressMemfile {
+		memfileDecompressPath = cfg.InstanceMemfilePath()
+	}
+	fcConfig := &hypervisor.FcConfig{
 		VcpuCount:       cfg.VCpuCount,
 		MemoryMB:        cfg.MemoryMB,
 		KernelImagePath: cfg.PrivateKernelPath(cfg.DataRoot),
@@ -285,20 +516,85 @@ func getFcConfig(cfg *SandboxConfig, net *network.SandboxNetwork, traceID string
 		WritableRootfsPath: "",
 		TapDevName:         consts.HostTapName,
 		GuestNetIfaceName:  consts.GuestIfaceName,
-		GuestNetMacAddr:    consts.GuestMacAddress,
-		EnableHugepage:     cfg.HugePages,
+		GuestNetMacAddr:    cfg.guestMAC(),
+		HugePageSize:       cfg.HugePage().Bytes(),
+		EnableBalloon:      cfg.Balloon,
+		PrefaultMemory:     cfg.PrefaultMemory,
+		// the instance dir has been created by EnsureFiles
+		MemfileDecompressPath: memfileDecompressPath,
+		Restore:               cfg.Restore,
+		LogLevel:              cfg.FcLogLevel,
 
 		MmdsData: &hypervisor.MmdsMetadata{
 			SandboxID: cfg.SandboxID,
 			EnvID:     cfg.TemplateID,
 			Address:   logCollectorAddr,
 			TraceID:   traceID,
+
+			WorkingDir: cfg.WorkingDir,
+			Env:        cfg.Env,
+			Custom:     cfg.Metadata,
 		},
 	}
+	// the instance dir is bind mounted at the private dir for firecracker
+	if cfg.FcLogLevel != "" {
+		fcConfig.LogPath = filepath.Join(cfg.PrivateDir(cfg.DataRoot), fcLogName)
+	}
+	if cfg.FcMetrics {
+		fcConfig.MetricsPath = filepath.Join(cfg.PrivateDir(cfg.DataRoot), fcMetricsName)
+	}
+	if cfg.ColdBoot {
+		boot := coldBootOf(cfg)
+		fcConfig.KernelBootCmd = boot.kernelArgs
+		fcConfig.EnableOverlayFS = cfg.Overlay
+		fcConfig.ReadOnlyRootfs = cfg.ImmutableRootfs
+		fcConfig.RootfsPath = boot.rootfsPath
+		fcConfig.WritableRootfsPath = boot.writableRootfsPath
+		fcConfig.ConfigDrivePath = boot.configDrivePath
+		// only the slots used by the sandbox, whose disks have been created
+		for i := range cfg.ExtraDisks {
+			fcConfig.ExtraDiskPaths = append(fcConfig.ExtraDiskPaths, cfg.PrivateExtraDiskPath(cfg.DataRoot, i))
+		}
+		fcConfig.DiskRateLimit = cfg.diskRateLimit()
+		fcConfig.CpuTemplate = cfg.CpuTemplate
+	} else if !cfg.DiskRateLimitOverride.IsZero() {
+		// the drives of snapshot, whose limits are patched after restored
+		fcConfig.DiskRateLimit = cfg.diskRateLimit()
+		fcConfig.EnableOverlayFS = cfg.Overlay
+		if cfg.ConfigDrive {
+			fcConfig.ConfigDrivePath = cfg.PrivateConfigDrivePath(cfg.DataRoot)
+		}
+		for i := 0; i < cfg.ExtraDiskSlots; i++ {
+			fcConfig.ExtraDiskPaths = append(fcConfig.ExtraDiskPaths, cfg.PrivateExtraDiskPath(cfg.DataRoot, i))
+		}
+	}
+	return fcConfig
+}
+
+// What is needed to boot the vm without snapshot, which is the same as
+// building the template (i.e., the private paths are bind mounted from the
+// instance dir, see EnsureFiles).
+type coldBootConfig struct {
+	kernelArgs         string
+	rootfsPath         string
+	writableRootfsPath string
+	configDrivePath    string
+}
+
+func coldBootOf(cfg *SandboxConfig) coldBootConfig {
+	boot := coldBootConfig{
+		kernelArgs:         cfg.KernelArgs(cfg.ConsoleLog),
+		rootfsPath:         cfg.PrivateRootfsPath(cfg.DataRoot),
+		writableRootfsPath: cfg.PrivateWritableRootfsPath(cfg.DataRoot),
+	}
+	if cfg.ConfigDrive {
+		boot.configDrivePath = cfg.PrivateConfigDrivePath(cfg.DataRoot)
+	}
+	return boot
 }
 
 func getChConfig(cfg *SandboxConfig) *hypervisor.ChConfig {
-	return &hypervisor.ChConfig{
+	chConfig := &hypervisor.ChConfig{
 		VcpuCount:       cfg.VCpuCount,
 		MemoryMB:        cfg.MemoryMB,
 		KernelImagePath: cfg.PrivateKernelPath(cfg.DataRoot),
@@ -309,7 +605,22 @@ func getChConfig(cfg *SandboxConfig) *hypervisor.ChConfig {
 		// do not need for restore
 		WritableRootfsPath: "",
 		TapDevName:         consts.HostTapName,
-		GuestNetMacAddr:    consts.GuestMacAddress,
-		EnableHugepage:     cfg.HugePages,
+		GuestNetMacAddr:    cfg.guestMAC(),
+		HugePageSize:       cfg.HugePage().Bytes(),
+		EnableBalloon:      cfg.Balloon,
+		PrefaultMemory:     cfg.PrefaultMemory,
+		Restore:            cfg.Restore,
+		// for the disks added after restored (and all disks when cold booting)
+		DiskRateLimit: cfg.diskRateLimit(),
+	}
+	if cfg.ColdBoot {
+		boot := coldBootOf(cfg)
+		chConfig.MemoryHotplugMB = cfg.MemoryHotplugMB
+		chConfig.KernelBootCmd = boot.kernelArgs
+		chConfig.ReadOnlyRootfs = cfg.ImmutableRootfs
+		chConfig.RootfsPath = boot.rootfsPath
+		chConfig.WritableRootfsPath = boot.writableRootfsPath
+		chConfig.ConfigDrivePath = boot.configDrivePath
 	}
+	return chConfig
 }
@@ -0,0 +1,109 @@
+package sandbox
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+)
+
+func TestColdBootConfig(t *testing.T) {
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{
+			TemplateID:      "cold-boot",
+			VmmType:         config.FIRECRACKER,
+			Overlay:         true,
+			ConfigDrive:     true,
+			ExtraDiskSlots:  2,
+			MemoryHotplugMB: 1024,
+		},
+		SandboxID:  "test-sandbox",
+		DataRoot:   t.TempDir(),
+		ExtraDisks: []DiskSpec{{SizeMB: 64}},
+	}
+	_, subnet, _ := net.ParseCIDR("10.168.0.0/16")
+	sbxNet := network.NewSandboxNetwork(network.NewNetworkEnv(1, subnet), cfg.SandboxID)
+
+	// nothing but the snapshot is needed for restoring
+	fcConfig := getFcConfig(cfg, &sbxNet, "")
+	if fcConfig.KernelBootCmd != "" || fcConfig.RootfsPath != "" || len(fcConfig.ExtraDiskPaths) != 0 {
+		t.Fatalf("unexpected boot config for restoring: %+v", fcConfig)
+	}
+
+	cfg.ColdBoot = true
+	fcConfig = getFcConfig(cfg, &sbxNet, "")
+	if fcConfig.KernelBootCmd != cfg.KernelArgs(false) || !fcConfig.EnableOverlayFS {
+		t.Fatalf("unexpected kernel args %q (overlay: %v)", fcConfig.KernelBootCmd, fcConfig.EnableOverlayFS)
+	}
+	if fcConfig.RootfsPath != cfg.PrivateRootfsPath(cfg.DataRoot) ||
+		fcConfig.WritableRootfsPath != cfg.PrivateWritableRootfsPath(cfg.DataRoot) ||
+		fcConfig.ConfigDrivePath != cfg.PrivateConfigDrivePath(cfg.DataRoot) {
+		t.Fatalf("expect the private paths of template, got %+v", fcConfig)
+	}
+	// only the slots used by the sandbox
+	if len(fcConfig.ExtraDiskPaths) != 1 || fcConfig.ExtraDiskPaths[0] != cfg.PrivateExtraDiskPath(cfg.DataRoot, 0) {
+		t.Fatalf("unexpected extra disks %v", fcConfig.ExtraDiskPaths)
+	}
+
+	cfg.VmmType = config.CLOUDHYPERVISOR
+	cfg.ConsoleLog = true
+	chConfig := getChConfig(cfg)
+	if chConfig.KernelBootCmd != cfg.KernelArgs(true) || chConfig.MemoryHotplugMB != 1024 {
+		t.Fatalf("unexpected kernel args %q (hotplug: %d)", chConfig.KernelBootCmd, chConfig.MemoryHotplugMB)
+	}
+	if chConfig.RootfsPath != cfg.PrivateRootfsPath(cfg.DataRoot) {
+		t.Fatalf("expect the private rootfs, got %s", chConfig.RootfsPath)
+	}
+}
+
+func TestDiskRateLimitConfig(t *testing.T) {
+	cfg := &SandboxConfig{
+		VMTemplate: config.VMTemplate{
+			TemplateID:       "limited",
+			VmmType:          config.FIRECRACKER,
+			Overlay:          true,
+			ExtraDiskSlots:   2,
+			DiskBandwidthBps: 100 << 20,
+			DiskIops:         1000,
+		},
+		SandboxID: "test-sandbox",
+		DataRoot:  t.TempDir(),
+	}
+	_, subnet, _ := net.ParseCIDR("10.168.0.0/16")
+	sbxNet := network.NewSandboxNetwork(network.NewNetworkEnv(1, subnet), cfg.SandboxID)
+
+	// the drives restored from snapshot keep the limits of template
+	fcConfig := getFcConfig(cfg, &sbxNet, "")
+	if !fcConfig.DiskRateLimit.IsZero() {
+		t.Fatalf("expect the limits in snapshot kept, got %+v", fcConfig.DiskRateLimit)
+	}
+
+	cfg.DiskRateLimitOverride = hypervisor.DiskRateLimit{Iops: 500}
+	fcConfig = getFcConfig(cfg, &sbxNet, "")
+	expected := hypervisor.DiskRateLimit{BandwidthBps: 100 << 20, Iops: 500}
+	if fcConfig.DiskRateLimit != expected {
+		t.Fatalf("expect limits %+v, got %+v", expected, fcConfig.DiskRateLimit)
+	}
+	// all drives of snapshot are patched
+	if !fcConfig.EnableOverlayFS || len(fcConfig.ExtraDiskPaths) != 2 {
+		t.Fatalf("expect the drives of snapshot, got %+v", fcConfig)
+	}
+
+	cfg.VmmType = config.CLOUDHYPERVISOR
+	if err := ValidateDiskRateLimitOverride(&cfg.VMTemplate, cfg.DiskRateLimitOverride, false); !errors.Is(err, ErrInvalidDiskRateLimit) {
+		t.Fatalf("expect invalid override of cloud hypervisor, got %v", err)
+	}
+	if err := ValidateDiskRateLimitOverride(&cfg.VMTemplate, cfg.DiskRateLimitOverride, true); err != nil {
+		t.Fatalf("expect valid override when cold booting, got %v", err)
+	}
+	if chConfig := getChConfig(cfg); chConfig.DiskRateLimit != expected {
+		t.Fatalf("expect limits %+v, got %+v", expected, chConfig.DiskRateLimit)
+	}
+	cfg.VmmType = config.FIRECRACKER
+	if err := ValidateDiskRateLimitOverride(&cfg.VMTemplate, hypervisor.DiskRateLimit{Iops: -1}, false); !errors.Is(err, ErrInvalidDiskRateLimit) {
+		t.Fatalf("expect invalid negative iops, got %v", err)
+	}
+}
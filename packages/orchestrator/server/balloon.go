@@ -0,0 +1,57 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+func (s *server) InflateBalloon(ctx context.Context, req *orchestrator.SandboxBalloonRequest) (*orchestrator.SandboxBalloonResponse, error) {
+	return s.setBalloon(ctx, req, true)
+}
+
+func (s *server) DeflateBalloon(ctx context.Context, req *orchestrator.SandboxBalloonRequest) (*orchestrator.SandboxBalloonResponse, error) {
+	return s.setBalloon(ctx, req, false)
+}
+
+func (s *server) setBalloon(ctx context.Context, req *orchestrator.SandboxBalloonRequest, inflate bool) (*orchestrator.SandboxBalloonResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-set-balloon", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	if err := sbx.SetBalloon(childCtx, s.tracer, req.SizeMiB, inflate); err != nil {
+		errMsg := fmt.Errorf("set balloon of sandbox %s failed: %w", sbx.SandboxID(), err)
+		telemetry.ReportError(childCtx, errMsg)
+		code := codes.Internal
+		switch {
+		case errors.Is(err, sandbox.ErrBalloonDisabled):
+			code = codes.FailedPrecondition
+		case errors.Is(err, sandbox.ErrInvalidBalloonSize):
+			code = codes.InvalidArgument
+		case errors.Is(err, sandbox.InvalidSandboxState):
+			code = codes.FailedPrecondition
+		}
+		return nil, status.New(code, errMsg.Error()).Err()
+	}
+
+	return &orchestrator.SandboxBalloonResponse{
+		SizeMiB: req.SizeMiB,
+	}, nil
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// The upper bound of count in a single CreateBatch request.
+const maxCreateBatchCount = 1024
+
+var (
+	errBatchAborted    = errors.New("aborted as other sandbox in the batch failed")
+	errBatchRolledBack = errors.New("rolled back as other sandbox in the batch failed")
+)
+
+func (s *server) CreateBatch(ctx context.Context, req *orchestrator.SandboxCreateBatchRequest) (*orchestrator.SandboxCreateBatchResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-create-batch", trace.WithAttributes(
+		attribute.String("env.id", req.TemplateID),
+		attribute.Int64("count", req.Count),
+		attribute.Bool("atomic", req.Atomic),
+	))
+	defer childSpan.End()
+
+	if req.Count <= 0 || req.Count > maxCreateBatchCount {
+		return nil, status.Errorf(codes.InvalidArgument, "count should be in [1, %d]", maxCreateBatchCount)
+	}
+	if req.SandboxIDPrefix == "" {
+		return nil, status.New(codes.InvalidArgument, "sandbox id prefix cannot be empty").Err()
+	}
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	if err := s.templates.Ensure(childCtx, s.tracer, req.TemplateID); err != nil {
+		return nil, status.New(codes.Unavailable, err.Error()).Err()
+	}
+	// parse the template only once for the whole batch
+	t, err := loadTemplate(s.cfg.DataRoot, req.TemplateID)
+	if err == nil {
+		err = t.ValidateMemfile(s.cfg.DataRoot)
+	}
+	if err != nil {
+		return nil, status.New(codes.InvalidArgument, fmt.Sprintf("cannot load template: %s", err.Error())).Err()
+	}
+
+	configs := make([]*sandbox.SandboxConfig, req.Count)
+	sandboxIDs := make([]string, req.Count)
+	for i := range configs {
+		sandboxID := fmt.Sprintf("%s-%d", req.SandboxIDPrefix, i)
+		sbxCfg, err := s.newSandboxConfig(childCtx, &orchestrator.SandboxCreateRequest{
+			TemplateID:           req.TemplateID,
+			SandboxID:            sandboxID,
+			MaxInstanceLength:    req.MaxInstanceLength,
+			EnableDiffSnapshots:  req.EnableDiffSnapshots,
+			Metadata:             req.Metadata,
+			HypervisorBinaryPath: req.HypervisorBinaryPath,
+		}, t)
+		if err != nil {
+			return nil, status.New(codes.InvalidArgument, fmt.Sprintf("cannot create sandbox config: %s", err.Error())).Err()
+		}
+		// all sandboxes of the batch share the same hypervisor (and template)
+		if i == 0 {
+			if err := checkHypervisor(sbxCfg); err != nil {
+				telemetry.ReportError(childCtx, err)
+				return nil, status.New(codes.FailedPrecondition, err.Error()).Err()
+			}
+			if err := s.checkSnapshotCompatibility(sbxCfg); err != nil {
+				telemetry.ReportError(childCtx, err)
+				return nil, status.New(codes.FailedPrecondition, err.Error()).Err()
+			}
+		}
+		configs[i] = sbxCfg
+		sandboxIDs[i] = sandboxID
+	}
+	// reserve the ids of the whole batch, so that they will not be created by others meanwhile
+	release, err := s.reserveSandboxIDs(sandboxIDs...)
+	if err != nil {
+		return nil, status.New(codes.AlreadyExists, err.Error()).Err()
+	}
+	defer release()
+
+	sbxs, errs := runBatch(childCtx, configs, req.Atomic, s.cfg.CreateBatchConcurrency, s.startSandbox)
+	if req.Atomic && slices.ContainsFunc(errs, func(err error) bool { return err != nil }) {
+		rollbackBatch(childCtx, sbxs, errs, func(ctx context.Context, sbx *sandbox.Sandbox) error {
+			return sbx.Stop(ctx, s.tracer)
+		})
+	}
+
+	items := make([]*orchestrator.SandboxCreateBatchItem, req.Count)
+	for i, sbxCfg := range configs {
+		item := &orchestrator.SandboxCreateBatchItem{SandboxID: sbxCfg.SandboxID}
+		if errs[i] != nil {
+			item.Error = errs[i].Error()
+		} else {
+			info := sbxs[i].GetSandboxInfo()
+			item.Info = &info
+		}
+		items[i] = item
+	}
+	return &orchestrator.SandboxCreateBatchResponse{Items: items}, nil
+}
+
+// Start the sandboxes of configs, at most concurrency of them at the same time.
+// When isAtomic, the ones not started yet are aborted after any of them failed.
+func runBatch(
+	ctx context.Context,
+	configs []*sandbox.SandboxConfig,
+	isAtomic bool,
+	concurrency int,
+	start func(context.Context, *sandbox.SandboxConfig) (*sandbox.Sandbox, error),
+) ([]*sandbox.Sandbox, []error) {
+	var (
+		wg      sync.WaitGroup
+		failed  atomic.Bool
+		sbxs    = make([]*sandbox.Sandbox, len(configs))
+		errs    = make([]error, len(configs))
+		limiter = make(chan struct{}, concurrency)
+	)
+	for i, sbxCfg := range configs {
+		wg.Add(1)
+		limiter <- struct{}{}
+		go func() {
+			defer func() {
+				<-limiter
+				wg.Done()
+			}()
+			if isAtomic && failed.Load() {
+				errs[i] = errBatchAborted
+				return
+			}
+			sbxs[i], errs[i] = start(ctx, sbxCfg)
+			if errs[i] != nil {
+				failed.Store(true)
+			}
+		}()
+	}
+	wg.Wait()
+	return sbxs, errs
+}
+
+// Stop the successfully created sandboxes in the batch, their resources will
+// be cleaned up by [server.waitSandbox] as the deleted ones.
+func rollbackBatch(
+	ctx context.Context,
+	sbxs []*sandbox.Sandbox,
+	errs []error,
+	stop func(context.Context, *sandbox.Sandbox) error,
+) {
+	for i, sbx := range sbxs {
+		if sbx == nil {
+			continue
+		}
+		if err := stop(ctx, sbx); err != nil {
+			errMsg := fmt.Errorf("stop sandbox when rolling back batch failed: %w", err)
+			telemetry.ReportCriticalError(ctx, errMsg, attribute.String("sandbox.id", sbx.SandboxID()))
+			errs[i] = errors.Join(errBatchRolledBack, errMsg)
+			continue
+		}
+		errs[i] = errBatchRolledBack
+	}
+}
@@ -0,0 +1,196 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+)
+
+func newTestBatchConfigs(count int) []*sandbox.SandboxConfig {
+	configs := make([]*sandbox.SandboxConfig, count)
+	for i := range configs {
+		configs[i] = &sandbox.SandboxConfig{SandboxID: fmt.Sprintf("batch-%d", i)}
+	}
+	return configs
+}
+
+func TestRunBatchConcurrency(t *testing.T) {
+	const concurrency = 3
+	var running, maxRunning atomic.Int32
+	start := func(ctx context.Context, cfg *sandbox.SandboxConfig) (*sandbox.Sandbox, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := maxRunning.Load()
+			if n <= old || maxRunning.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return &sandbox.Sandbox{Config: cfg}, nil
+	}
+
+	sbxs, errs := runBatch(context.Background(), newTestBatchConfigs(10), true, concurrency, start)
+	for i := range sbxs {
+		if errs[i] != nil || sbxs[i] == nil {
+			t.Fatalf("expect sandbox %d created, got %v", i, errs[i])
+		}
+	}
+	if n := maxRunning.Load(); n > concurrency {
+		t.Fatalf("expect at most %d sandboxes created concurrently, got %d", concurrency, n)
+	}
+}
+
+func TestRunBatchAtomicRollback(t *testing.T) {
+	errStart := errors.New("start failed")
+	start := func(ctx context.Context, cfg *sandbox.SandboxConfig) (*sandbox.Sandbox, error) {
+		if cfg.SandboxID == "batch-1" {
+			return nil, errStart
+		}
+		return &sandbox.Sandbox{Config: cfg}, nil
+	}
+	// one by one, so the ones after the failed one are aborted
+	sbxs, errs := runBatch(context.Background(), newTestBatchConfigs(4), true, 1, start)
+	if errs[0] != nil || !errors.Is(errs[1], errStart) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	for _, err := range errs[2:] {
+		if !errors.Is(err, errBatchAborted) {
+			t.Fatalf("expect aborted, got %v", errs)
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		stopped []string
+	)
+	errStop := errors.New("stop failed")
+	stop := func(ctx context.Context, sbx *sandbox.Sandbox) error {
+		mu.Lock()
+		defer mu.Unlock()
+		stopped = append(stopped, sbx.SandboxID())
+		if sbx.SandboxID() == "batch-2" {
+			return errStop
+		}
+		return nil
+	}
+	// pretend batch-2 has been created before aborted
+	sbxs[2], errs[2] = &sandbox.Sandbox{Config: &sandbox.SandboxConfig{SandboxID: "batch-2"}}, nil
+	rollbackBatch(context.Background(), sbxs, errs, stop)
+
+	if !slices.Equal(stopped, []string{"batch-0", "batch-2"}) {
+		t.Fatalf("expect the created sandboxes stopped, got %v", stopped)
+	}
+	if !errors.Is(errs[0], errBatchRolledBack) {
+		t.Fatalf("expect rolled back, got %v", errs[0])
+	}
+	if !errors.Is(errs[1], errStart) || !errors.Is(errs[3], errBatchAborted) {
+		t.Fatalf("the errors of not created sandboxes should be kept, got %v", errs)
+	}
+	if !errors.Is(errs[2], errBatchRolledBack) || !errors.Is(errs[2], errStop) {
+		t.Fatalf("expect rolled back with stop error, got %v", errs[2])
+	}
+}
+
+func TestRunBatchNonAtomic(t *testing.T) {
+	errStart := errors.New("start failed")
+	start := func(ctx context.Context, cfg *sandbox.SandboxConfig) (*sandbox.Sandbox, error) {
+		if cfg.SandboxID == "batch-0" {
+			return nil, errStart
+		}
+		return &sandbox.Sandbox{Config: cfg}, nil
+	}
+	sbxs, errs := runBatch(context.Background(), newTestBatchConfigs(3), false, 1, start)
+	if !errors.Is(errs[0], errStart) || errs[1] != nil || errs[2] != nil || sbxs[1] == nil || sbxs[2] == nil {
+		t.Fatalf("expect the others created, got %v", errs)
+	}
+}
+
+func TestReserveSandboxIDs(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	s.sandboxes = map[string]*sandbox.Sandbox{
+		"running": {Config: &sandbox.SandboxConfig{SandboxID: "running"}},
+	}
+
+	if _, err := s.reserveSandboxIDs("a", "running"); !errors.Is(err, ErrSandboxExists) {
+		t.Fatalf("expect sandbox exists, got %v", err)
+	}
+	if _, err := s.reserveSandboxIDs("a", "b", "a"); !errors.Is(err, ErrSandboxExists) {
+		t.Fatalf("expect duplicated id rejected, got %v", err)
+	}
+	// nothing is reserved by the failed ones
+	release, err := s.reserveSandboxIDs("a", "b")
+	if err != nil {
+		t.Fatalf("reserve failed: %s", err)
+	}
+
+	// only one of the concurrent reservations succeeds
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.reserveSandboxIDs("c"); err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := succeeded.Load(); n != 1 {
+		t.Fatalf("expect only 1 reservation succeeded, got %d", n)
+	}
+
+	release()
+	if _, err := s.reserveSandboxIDs("a", "b"); err != nil {
+		t.Fatalf("expect reserved again after released, got %s", err)
+	}
+}
+
+func TestReserveNewSandboxID(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		ids = make(map[string]struct{})
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id, _ := s.reserveNewSandboxID()
+				mu.Lock()
+				ids[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(ids) != 1000 {
+		t.Fatalf("expect 1000 unique ids, got %d", len(ids))
+	}
+
+	id, release := s.reserveNewSandboxID()
+	// it should be recognized in the cmdline of orphan sandbox
+	match := sandboxIDRegExp.FindStringSubmatch("/" + sandbox.InstancesDirName + "/" + id + "/")
+	if match == nil || match[1] != id {
+		t.Fatalf("generated id %q is not a valid sandbox id", id)
+	}
+	if _, err := s.reserveSandboxIDs(id); !errors.Is(err, ErrSandboxExists) {
+		t.Fatalf("expect the generated id reserved, got %v", err)
+	}
+	release()
+	if _, err := s.reserveSandboxIDs(id); err != nil {
+		t.Fatalf("expect reserved again after released, got %s", err)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// The cgroup of sandbox is created before the vmm joins it, so the young
+// cgroups are not treated as stale (they may belong to the creating sandboxes).
+const staleCgroupGracePeriod = time.Minute
+
+// Scan the cgroups of sandboxes, return those have no live process
+// and no matching sandbox maintained by orchestrator.
+func (s *server) listStaleCgroups() ([]*orchestrator.StaleCgroup, error) {
+	parent := s.cfg.cgroupParentPath()
+	entries, err := os.ReadDir(parent)
+	if err != nil {
+		return nil, fmt.Errorf("read cgroup dir %s failed: %w", parent, err)
+	}
+	var stale []*orchestrator.StaleCgroup
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		sandboxID := entry.Name()
+		if _, ok := s.GetSandbox(sandboxID); ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < staleCgroupGracePeriod {
+			continue
+		}
+		path := filepath.Join(parent, sandboxID)
+		procs, err := os.ReadFile(filepath.Join(path, "cgroup.procs"))
+		if err != nil || len(strings.TrimSpace(string(procs))) > 0 {
+			continue
+		}
+		stale = append(stale, &orchestrator.StaleCgroup{
+			SandboxID: sandboxID,
+			Path:      path,
+		})
+	}
+	return stale, nil
+}
+
+func (s *server) ListStaleCgroups(ctx context.Context, _ *empty.Empty) (*orchestrator.HostManageListStaleCgroupsResponse, error) {
+	stale, err := s.listStaleCgroups()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &orchestrator.HostManageListStaleCgroupsResponse{Cgroups: stale}, nil
+}
+
+func (s *server) ReapCgroups(ctx context.Context, req *orchestrator.HostManageReapCgroupsRequest) (*orchestrator.HostManageReapCgroupsResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-reap-cgroups")
+	defer childSpan.End()
+
+	stale, err := s.listStaleCgroups()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	staleMap := make(map[string]*orchestrator.StaleCgroup, len(stale))
+	for _, cg := range stale {
+		staleMap[cg.SandboxID] = cg
+	}
+
+	resp := &orchestrator.HostManageReapCgroupsResponse{
+		Failed: make(map[string]string),
+	}
+	var targets []*orchestrator.StaleCgroup
+	if req.All {
+		targets = stale
+	} else {
+		for _, sandboxID := range req.SandboxIDs {
+			cg, ok := staleMap[sandboxID]
+			if !ok {
+				resp.Failed[sandboxID] = "not a stale cgroup"
+				continue
+			}
+			targets = append(targets, cg)
+		}
+	}
+
+	for _, cg := range targets {
+		if err := sandbox.RemoveCgroup(cg.Path); err != nil {
+			errMsg := fmt.Errorf("remove stale cgroup %s failed: %w", cg.Path, err)
+			telemetry.ReportError(childCtx, errMsg, attribute.String("sandbox.id", cg.SandboxID))
+			resp.Failed[cg.SandboxID] = errMsg.Error()
+			continue
+		}
+		telemetry.ReportEvent(childCtx, "reaped stale cgroup", attribute.String("sandbox.id", cg.SandboxID))
+		resp.Reaped = append(resp.Reaped, cg.SandboxID)
+	}
+	return resp, nil
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// Returned when compacting the snapshot in the dirs of a sandbox maintained
+// by orchestrator, which may be overwritten by its next snapshot.
+var ErrSnapshotInUse = errors.New("snapshot is used by sandbox")
+
+func (s *server) CompactSnapshot(ctx context.Context, req *orchestrator.HostManageCompactSnapshotRequest) (*empty.Empty, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-compact-snapshot", trace.WithAttributes(
+		attribute.String("snapshot.base_dir", req.BaseDir),
+		attribute.StringSlice("snapshot.diff_dirs", req.DiffDirs),
+		attribute.String("snapshot.output_dir", req.OutputDir),
+	))
+	defer childSpan.End()
+
+	if len(req.DiffDirs) == 0 {
+		return nil, status.New(codes.InvalidArgument, "diffDirs cannot be empty").Err()
+	}
+	// the dirs are read and written as root, so they are restricted
+	// as the destination dir of Snapshot (see resolveSnapshotDir)
+	dirs := append([]string{req.BaseDir, req.OutputDir}, req.DiffDirs...)
+	for i, dir := range dirs {
+		resolved, err := resolveSnapshotDir(dir, s.cfg.snapshotDestinationRoots())
+		if err != nil {
+			telemetry.ReportError(childCtx, err)
+			return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+		}
+		dirs[i] = resolved
+	}
+	if err := s.checkSnapshotNotLive(dirs); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.FailedPrecondition, err.Error()).Err()
+	}
+
+	baseDir, outputDir, diffDirs := dirs[0], dirs[1], dirs[2:]
+	if err := hypervisor.CompactFcSnapshot(childCtx, baseDir, diffDirs, outputDir); err != nil {
+		code := codes.Internal
+		switch {
+		case errors.Is(err, fs.ErrExist):
+			code = codes.AlreadyExists
+		case errors.Is(err, fs.ErrNotExist), errors.Is(err, hypervisor.ErrSnapshotChainMismatch):
+			code = codes.InvalidArgument
+		}
+		return nil, status.New(code, err.Error()).Err()
+	}
+	return &empty.Empty{}, nil
+}
+
+// The snapshot dir and instance dir of the sandboxes maintained by orchestrator
+// are live, i.e., they are written by the next snapshot or the running vmm.
+func (s *server) checkSnapshotNotLive(dirs []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sbx := range s.sandboxes {
+		for _, live := range []string{sbx.Config.EnvInstanceCreateSnapshotPath(), sbx.Config.InstancePath()} {
+			// the dirs are resolved (see resolveSnapshotDir)
+			if resolved, err := resolveExistingPrefix(live); err == nil {
+				live = resolved
+			}
+			for _, dir := range dirs {
+				if isUnderDir(dir, live) {
+					return fmt.Errorf("%w: %s is under %s of sandbox %s", ErrSnapshotInUse, dir, live, sbx.SandboxID())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Whether path is dir or in dir.
+func isUnderDir(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
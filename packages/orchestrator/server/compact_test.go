@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestCompactSnapshotRejectDirs(t *testing.T) {
+	dataRoot := t.TempDir()
+	s := newTestServer(dataRoot)
+	sbxCfg := &sandbox.SandboxConfig{
+		VMTemplate:   config.VMTemplate{TemplateID: "default"},
+		SandboxID:    "sandbox",
+		DataRoot:     dataRoot,
+		SnapshotRoot: filepath.Join(dataRoot, "snapshots"),
+	}
+	s.sandboxes = map[string]*sandbox.Sandbox{"sandbox": {Config: sbxCfg}}
+	base, diff, output := filepath.Join(dataRoot, "base"), filepath.Join(dataRoot, "diff"), filepath.Join(dataRoot, "output")
+	escape := filepath.Join(dataRoot, "escape")
+	if err := os.Symlink("/etc", escape); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name string
+		req  *orchestrator.HostManageCompactSnapshotRequest
+		code codes.Code
+	}{
+		{
+			name: "no diff",
+			req:  &orchestrator.HostManageCompactSnapshotRequest{BaseDir: base, OutputDir: output},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "relative path",
+			req:  &orchestrator.HostManageCompactSnapshotRequest{BaseDir: "base", DiffDirs: []string{diff}, OutputDir: output},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "outside of roots",
+			req:  &orchestrator.HostManageCompactSnapshotRequest{BaseDir: base, DiffDirs: []string{diff}, OutputDir: "/etc/output"},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "dot dot",
+			req:  &orchestrator.HostManageCompactSnapshotRequest{BaseDir: dataRoot + "/../base", DiffDirs: []string{diff}, OutputDir: output},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "symlink escape",
+			req:  &orchestrator.HostManageCompactSnapshotRequest{BaseDir: base, DiffDirs: []string{filepath.Join(escape, "diff")}, OutputDir: output},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "live snapshot dir",
+			req: &orchestrator.HostManageCompactSnapshotRequest{
+				BaseDir:   base,
+				DiffDirs:  []string{sbxCfg.EnvInstanceCreateSnapshotPath()},
+				OutputDir: output,
+			},
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "output in instance dir",
+			req: &orchestrator.HostManageCompactSnapshotRequest{
+				BaseDir:   base,
+				DiffDirs:  []string{diff},
+				OutputDir: filepath.Join(sbxCfg.InstancePath(), "compacted"),
+			},
+			code: codes.FailedPrecondition,
+		},
+		{
+			name: "missing base",
+			req:  &orchestrator.HostManageCompactSnapshotRequest{BaseDir: base, DiffDirs: []string{diff}, OutputDir: output},
+			code: codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.CompactSnapshot(context.Background(), tc.req)
+			if status.Code(err) != tc.code {
+				t.Fatalf("expect %s, got %v", tc.code, err)
+			}
+		})
+	}
+}
+
+func TestIsUnderDir(t *testing.T) {
+	testCases := []struct {
+		path, dir string
+		expect    bool
+	}{
+		{"/a/b", "/a/b", true},
+		{"/a/b/c", "/a/b", true},
+		{"/a/bc", "/a/b", false},
+		{"/a", "/a/b", false},
+	}
+	for _, tc := range testCases {
+		if got := isUnderDir(tc.path, tc.dir); got != tc.expect {
+			t.Fatalf("isUnderDir(%s, %s) = %v, expect %v", tc.path, tc.dir, got, tc.expect)
+		}
+	}
+}
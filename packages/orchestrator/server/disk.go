@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+var ErrExtraDiskQuotaExceeded = errors.New("extra disk quota exceeded")
+
+// The total size (in MiB) of extra disks of the maintained sandboxes.
+func (s *server) extraDiskUsageMB() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var total int64
+	for _, sbx := range s.sandboxes {
+		total += sbx.Config.ExtraDisksSizeMB()
+	}
+	return total
+}
+
+// Reserve sizeMB of the extra disk quota for a creating sandbox, the returned
+// function releases the reservation, which should be called once the sandbox
+// is inserted (or failed to create).
+func (s *server) reserveExtraDisks(sizeMB int64) (func(), error) {
+	if sizeMB == 0 {
+		return func() {}, nil
+	}
+	s.diskQuotaMu.Lock()
+	defer s.diskQuotaMu.Unlock()
+	used := s.pendingDiskMB + s.extraDiskUsageMB()
+	if used+sizeMB > s.cfg.ExtraDiskQuotaMB {
+		return nil, fmt.Errorf("%w: request %d MiB, used %d MiB, quota %d MiB",
+			ErrExtraDiskQuotaExceeded, sizeMB, used, s.cfg.ExtraDiskQuotaMB)
+	}
+	s.pendingDiskMB += sizeMB
+	return func() {
+		s.diskQuotaMu.Lock()
+		defer s.diskQuotaMu.Unlock()
+		s.pendingDiskMB -= sizeMB
+	}, nil
+}
+
+// Fail early if the free disk space of data_root is less than the estimated
+// need of the sandbox (see config.VMTemplate.InstanceDiskSize), its extra disks
+// and the headroom.
+func (s *server) ensureDiskSpace(ctx context.Context, sbxCfg *sandbox.SandboxConfig) error {
+	if s.cfg.DiskHeadroom < 0 {
+		return nil
+	}
+	need := sbxCfg.InstanceDiskSize() + sbxCfg.ExtraDisksSizeMB()<<20 + s.cfg.DiskHeadroom
+	telemetry.ReportEvent(ctx, "estimated disk space",
+		attribute.Int64("disk.instance_mb", sbxCfg.InstanceDiskSize()>>20),
+		attribute.Int64("disk.extra_disks_mb", sbxCfg.ExtraDisksSizeMB()),
+		attribute.Int64("disk.headroom_mb", s.cfg.DiskHeadroom>>20),
+	)
+	return utils.EnsureFreeDiskSpace(sbxCfg.DataRoot, need)
+}
@@ -0,0 +1,46 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+var errHostDraining = errors.New("host draining")
+
+// Fail with UNAVAILABLE when the host is draining, which is checked
+// before creating any new sandbox (i.e., not for the existing ones).
+func (s *server) checkDraining() error {
+	if s.draining.Load() {
+		return statusError(codes.Unavailable, errHostDraining)
+	}
+	return nil
+}
+
+// The sandboxes on the host, including the ones being created.
+func (s *server) sandboxCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sandboxes) + len(s.creating)
+}
+
+func (s *server) Drain(ctx context.Context, _ *empty.Empty) (*orchestrator.HostManageDrainResponse, error) {
+	if !s.draining.Swap(true) {
+		telemetry.ReportEvent(ctx, "host starts draining")
+	}
+	return &orchestrator.HostManageDrainResponse{
+		Sandboxes: int64(s.sandboxCount()),
+	}, nil
+}
+
+func (s *server) Undrain(ctx context.Context, _ *empty.Empty) (*empty.Empty, error) {
+	if s.draining.Swap(false) {
+		telemetry.ReportEvent(ctx, "host stops draining")
+	}
+	return &empty.Empty{}, nil
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestDrain(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	s.sandboxes = map[string]*sandbox.Sandbox{"sandbox": {}}
+	s.creating = map[string]struct{}{"creating": {}}
+
+	resp, err := s.Drain(context.Background(), &empty.Empty{})
+	if err != nil || resp.Sandboxes != 2 {
+		t.Fatalf("expect 2 sandboxes left, got %v (err %v)", resp, err)
+	}
+
+	_, err = s.Create(context.Background(), &orchestrator.SandboxCreateRequest{TemplateID: "fc", SandboxID: "new"})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expect create unavailable when draining, got %v", err)
+	}
+	_, err = s.CreateBatch(context.Background(), &orchestrator.SandboxCreateBatchRequest{
+		TemplateID:      "fc",
+		Count:           1,
+		SandboxIDPrefix: "batch",
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expect create batch unavailable when draining, got %v", err)
+	}
+	_, err = s.MigrateReceive(context.Background(), &orchestrator.SandboxMigrateReceiveRequest{
+		Sandbox:     &orchestrator.SandboxCreateRequest{TemplateID: "fc", SandboxID: "new"},
+		ReceiverUrl: "unix:/run/migration.sock",
+	})
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("expect migrate receive unavailable when draining, got %v", err)
+	}
+
+	if _, err := s.Undrain(context.Background(), &empty.Empty{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.checkDraining(); err != nil {
+		t.Fatalf("expect new sandboxes accepted after undrain, got %v", err)
+	}
+}
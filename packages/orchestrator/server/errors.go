@@ -0,0 +1,71 @@
+package server
+
+import (
+	"errors"
+	"syscall"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+// Classify err into the ErrorReason exposed to clients, and the code which
+// overrides the default one of the rpc. Return ERROR_UNSPECIFY if unknown.
+func errorReason(err error) (orchestrator.ErrorReason, codes.Code) {
+	switch {
+	case errors.Is(err, sandbox.ErrNetworkExhausted):
+		return orchestrator.ErrorReason_ERROR_NETWORK_EXHAUSTED, codes.ResourceExhausted
+	case errors.Is(err, ErrTemplateNotFound):
+		return orchestrator.ErrorReason_ERROR_TEMPLATE_NOT_FOUND, codes.NotFound
+	case errors.Is(err, sandbox.ErrSnapshotInProgress):
+		return orchestrator.ErrorReason_ERROR_SNAPSHOT_IN_PROGRESS, codes.Aborted
+	case errors.Is(err, sandbox.InvalidSandboxState):
+		return orchestrator.ErrorReason_ERROR_INVALID_STATE, codes.FailedPrecondition
+	// e.g., failed to fork the vmm process or mmap the memory
+	// of snapshot when restoring.
+	case errors.Is(err, syscall.ENOMEM):
+		return orchestrator.ErrorReason_ERROR_HOST_OOM, codes.ResourceExhausted
+	case errors.Is(err, ErrExtraDiskQuotaExceeded):
+		return orchestrator.ErrorReason_ERROR_EXTRA_DISK_QUOTA_EXCEEDED, codes.ResourceExhausted
+	case errors.Is(err, utils.ErrInsufficientDiskSpace):
+		return orchestrator.ErrorReason_ERROR_HOST_DISK_FULL, codes.ResourceExhausted
+	case errors.Is(err, ErrTooManyCreates):
+		return orchestrator.ErrorReason_ERROR_TOO_MANY_CREATES, codes.ResourceExhausted
+	}
+	return orchestrator.ErrorReason_ERROR_UNSPECIFY, codes.Unknown
+}
+
+func isRetryable(reason orchestrator.ErrorReason) bool {
+	switch reason {
+	case orchestrator.ErrorReason_ERROR_NETWORK_EXHAUSTED,
+		orchestrator.ErrorReason_ERROR_SNAPSHOT_IN_PROGRESS,
+		orchestrator.ErrorReason_ERROR_HOST_OOM,
+		orchestrator.ErrorReason_ERROR_EXTRA_DISK_QUOTA_EXCEEDED,
+		orchestrator.ErrorReason_ERROR_HOST_DISK_FULL,
+		orchestrator.ErrorReason_ERROR_TOO_MANY_CREATES:
+		return true
+	}
+	return false
+}
+
+// Return the grpc status error of err, with ErrorDetail attached if the
+// reason of err is known (see errorReason). In that case, code is
+// overridden by the one of reason.
+func statusError(code codes.Code, err error) error {
+	reason, reasonCode := errorReason(err)
+	if reason == orchestrator.ErrorReason_ERROR_UNSPECIFY {
+		return status.New(code, err.Error()).Err()
+	}
+	st := status.New(reasonCode, err.Error())
+	detailed, detailErr := st.WithDetails(&orchestrator.ErrorDetail{
+		Reason:    reason,
+		Retryable: isRetryable(reason),
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
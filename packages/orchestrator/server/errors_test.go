@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+func TestStatusError(t *testing.T) {
+	testCases := []struct {
+		err       error
+		code      codes.Code
+		reason    orchestrator.ErrorReason
+		retryable bool
+	}{
+		{fmt.Errorf("get network: %w", sandbox.ErrNetworkExhausted), codes.ResourceExhausted, orchestrator.ErrorReason_ERROR_NETWORK_EXHAUSTED, true},
+		{fmt.Errorf("%w: test", ErrTemplateNotFound), codes.NotFound, orchestrator.ErrorReason_ERROR_TEMPLATE_NOT_FOUND, false},
+		{fmt.Errorf("%w: %w", sandbox.ErrSnapshotInProgress, errors.New("timeout")), codes.Aborted, orchestrator.ErrorReason_ERROR_SNAPSHOT_IN_PROGRESS, true},
+		{sandbox.InvalidSandboxState, codes.FailedPrecondition, orchestrator.ErrorReason_ERROR_INVALID_STATE, false},
+		{fmt.Errorf("fork vmm: %w", syscall.ENOMEM), codes.ResourceExhausted, orchestrator.ErrorReason_ERROR_HOST_OOM, true},
+		{fmt.Errorf("%w: request 1 MiB", ErrExtraDiskQuotaExceeded), codes.ResourceExhausted, orchestrator.ErrorReason_ERROR_EXTRA_DISK_QUOTA_EXCEEDED, true},
+		{fmt.Errorf("%w on /data: need 2048 MiB, free 1024 MiB", utils.ErrInsufficientDiskSpace), codes.ResourceExhausted, orchestrator.ErrorReason_ERROR_HOST_DISK_FULL, true},
+		{fmt.Errorf("%w: 4 sandboxes are being created", ErrTooManyCreates), codes.ResourceExhausted, orchestrator.ErrorReason_ERROR_TOO_MANY_CREATES, true},
+		// unknown errors keep the default code without detail
+		{errors.New("unknown"), codes.Internal, orchestrator.ErrorReason_ERROR_UNSPECIFY, false},
+	}
+	for _, tc := range testCases {
+		reason, _ := errorReason(tc.err)
+		if reason != tc.reason {
+			t.Fatalf("expect reason %s of %q, got %s", tc.reason, tc.err, reason)
+		}
+		if retryable := isRetryable(reason); retryable != tc.retryable {
+			t.Fatalf("expect retryable %v of %s, got %v", tc.retryable, reason, retryable)
+		}
+
+		st := status.Convert(statusError(codes.Internal, tc.err))
+		if st.Code() != tc.code || st.Message() != tc.err.Error() {
+			t.Fatalf("unexpected status %v of %q", st, tc.err)
+		}
+		details := st.Details()
+		if tc.reason == orchestrator.ErrorReason_ERROR_UNSPECIFY {
+			if len(details) != 0 {
+				t.Fatalf("expect no detail of %q, got %v", tc.err, details)
+			}
+			continue
+		}
+		if len(details) != 1 {
+			t.Fatalf("expect 1 detail of %q, got %v", tc.err, details)
+		}
+		detail, ok := details[0].(*orchestrator.ErrorDetail)
+		if !ok || detail.Reason != tc.reason || detail.Retryable != tc.retryable {
+			t.Fatalf("unexpected detail %v of %q", details[0], tc.err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+// The max number of events buffered for each WatchEvents stream, the later
+// events are dropped when the buffer is full (i.e., the client is too slow).
+const eventBufferSize = 256
+
+type eventSubscriber struct {
+	req    *orchestrator.SandboxWatchEventsRequest
+	events chan *orchestrator.SandboxEvent
+	// the number of events dropped since the last one sent, protected by eventHub.mu
+	dropped int64
+}
+
+func (sub *eventSubscriber) match(ev *orchestrator.SandboxEvent) bool {
+	if sub.req.SandboxID != nil && *sub.req.SandboxID != ev.SandboxID {
+		return false
+	}
+	if sub.req.TemplateID != nil && *sub.req.TemplateID != ev.TemplateID {
+		return false
+	}
+	return true
+}
+
+// Fan out the lifecycle events of sandboxes to the WatchEvents streams.
+// The zero value is ready to use.
+type eventHub struct {
+	mu          sync.Mutex
+	subscribers map[*eventSubscriber]struct{}
+}
+
+// The returned function should be called once the subscriber is not used.
+func (h *eventHub) subscribe(req *orchestrator.SandboxWatchEventsRequest, bufferSize int) (*eventSubscriber, func()) {
+	sub := &eventSubscriber{
+		req:    req,
+		events: make(chan *orchestrator.SandboxEvent, bufferSize),
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subscribers == nil {
+		h.subscribers = make(map[*eventSubscriber]struct{})
+	}
+	h.subscribers[sub] = struct{}{}
+	return sub, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, sub)
+	}
+}
+
+// Never blocks, see sandbox.SandboxConfig.OnEvent.
+func (h *eventHub) publish(ev *orchestrator.SandboxEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.match(ev) {
+			continue
+		}
+		e := proto.Clone(ev).(*orchestrator.SandboxEvent)
+		e.Dropped = sub.dropped
+		select {
+		case sub.events <- e:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+func (s *server) WatchEvents(req *orchestrator.SandboxWatchEventsRequest, stream grpc.ServerStreamingServer[orchestrator.SandboxEvent]) error {
+	sub, unsubscribe := s.events.subscribe(req, eventBufferSize)
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case ev := <-sub.events:
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
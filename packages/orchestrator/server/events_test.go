@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func newTestEvent(eventType orchestrator.SandboxEventType, sandboxID, templateID string) *orchestrator.SandboxEvent {
+	return &orchestrator.SandboxEvent{Type: eventType, SandboxID: sandboxID, TemplateID: templateID}
+}
+
+func TestEventHubFilter(t *testing.T) {
+	var hub eventHub
+	sandboxID, templateID := "a", "t1"
+	all, unsubscribeAll := hub.subscribe(&orchestrator.SandboxWatchEventsRequest{}, 8)
+	defer unsubscribeAll()
+	bySandbox, unsubscribeSandbox := hub.subscribe(&orchestrator.SandboxWatchEventsRequest{SandboxID: &sandboxID}, 8)
+	defer unsubscribeSandbox()
+	byTemplate, unsubscribeTemplate := hub.subscribe(&orchestrator.SandboxWatchEventsRequest{TemplateID: &templateID}, 8)
+
+	hub.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_CREATED, "a", "t1"))
+	hub.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_CREATED, "b", "t1"))
+	hub.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_CREATED, "c", "t2"))
+	unsubscribeTemplate()
+	hub.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_STOPPED, "b", "t1"))
+
+	testCases := []struct {
+		name     string
+		sub      *eventSubscriber
+		expected []string
+	}{
+		{"all", all, []string{"a", "b", "c", "b"}},
+		{"sandbox", bySandbox, []string{"a"}},
+		{"template", byTemplate, []string{"a", "b"}},
+	}
+	for _, tc := range testCases {
+		var received []string
+		for len(tc.sub.events) > 0 {
+			received = append(received, (<-tc.sub.events).SandboxID)
+		}
+		if len(received) != len(tc.expected) {
+			t.Fatalf("%s: expect events of %v, got %v", tc.name, tc.expected, received)
+		}
+		for i := range received {
+			if received[i] != tc.expected[i] {
+				t.Fatalf("%s: expect events of %v, got %v", tc.name, tc.expected, received)
+			}
+		}
+	}
+}
+
+func TestEventHubDropsForSlowSubscriber(t *testing.T) {
+	var hub eventHub
+	sub, unsubscribe := hub.subscribe(&orchestrator.SandboxWatchEventsRequest{}, 2)
+	defer unsubscribe()
+	for i := 0; i < 5; i++ {
+		hub.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_RUNNING, "a", "t"))
+	}
+	for i := 0; i < 2; i++ {
+		if ev := <-sub.events; ev.Dropped != 0 {
+			t.Fatalf("expect no dropped events before the buffered ones, got %d", ev.Dropped)
+		}
+	}
+	hub.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_STOPPED, "a", "t"))
+	ev := <-sub.events
+	if ev.Type != orchestrator.SandboxEventType_EVENT_STOPPED || ev.Dropped != 3 {
+		t.Fatalf("expect stopped event after 3 dropped ones, got %v", ev)
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	lis := bufconn.Listen(1 << 20)
+	grpcSrv := grpc.NewServer()
+	orchestrator.RegisterSandboxServer(grpcSrv, s)
+	go grpcSrv.Serve(lis)
+	defer grpcSrv.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	sandboxID := "a"
+	stream, err := orchestrator.NewSandboxClient(conn).WatchEvents(ctx, &orchestrator.SandboxWatchEventsRequest{SandboxID: &sandboxID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the subscription is registered asynchronously
+	for {
+		s.events.mu.Lock()
+		subscribed := len(s.events.subscribers) > 0
+		s.events.mu.Unlock()
+		if subscribed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.events.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_CREATED, "b", "t"))
+	s.events.publish(newTestEvent(orchestrator.SandboxEventType_EVENT_CREATED, "a", "t"))
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ev.SandboxID != "a" || ev.Type != orchestrator.SandboxEventType_EVENT_CREATED {
+		t.Fatalf("unexpected event %v", ev)
+	}
+
+	// the subscriber is removed once the client cancels
+	cancel()
+	for {
+		s.events.mu.Lock()
+		subscribed := len(s.events.subscribers) > 0
+		s.events.mu.Unlock()
+		if !subscribed {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
@@ -25,6 +28,7 @@ import (
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 )
@@ -36,22 +40,156 @@ var (
 
 var SandboxNotFound = errors.New("sandbox not found")
 
-func newSandboxConfig(req *orchestrator.SandboxCreateRequest, cfg *OrchestratorConfig) (*sandbox.SandboxConfig, error) {
+var ErrSandboxExists = errors.New("sandbox already exists")
+
+// The sandbox is in a transient state (e.g., snapshotting), the request
+// can be retried once it is back to running.
+var ErrSandboxBusy = errors.New("sandbox is busy")
+
+var ErrTemplateNotFound = errors.New("template not found")
+
+var ErrHypervisorUnavailable = errors.New("hypervisor unavailable")
+
+func loadTemplate(dataRoot, templateID string) (*config.VMTemplate, error) {
 	var t config.VMTemplate
 	templateFilePath := filepath.Join(
-		cfg.DataRoot,
+		dataRoot,
 		consts.TemplateDirName,
-		req.TemplateID,
+		templateID,
 		consts.TemplateFileName,
 	)
 	if _, err := toml.DecodeFile(templateFilePath, &t); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, templateID)
+		}
 		return nil, fmt.Errorf("cannot decode template file %s: %w", templateFilePath, err)
 	}
-	// Assemble socket path
-	socketPath, sockErr := sandbox.GetSocketPath(req.SandboxID)
-	if sockErr != nil {
-		return nil, fmt.Errorf("error getting socket path: %w", sockErr)
+	return &t, nil
+}
+
+func newSandboxConfig(req *orchestrator.SandboxCreateRequest, cfg *OrchestratorConfig) (*sandbox.SandboxConfig, error) {
+	t, err := loadTemplate(cfg.DataRoot, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.ValidateMemfile(cfg.DataRoot); err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", req.TemplateID, err)
 	}
+	return newSandboxConfigFromTemplate(req, t, cfg)
+}
+
+// Convert the egress policy in request, nil means no policy.
+// The dns servers of template are always allowed.
+func newEgressPolicy(p *orchestrator.EgressPolicy, dns []string) (*network.EgressPolicy, error) {
+	if p == nil {
+		return nil, nil
+	}
+	convert := func(rules []*orchestrator.EgressRule) ([]network.EgressRule, error) {
+		var result []network.EgressRule
+		for _, rule := range rules {
+			if rule.Port > math.MaxUint16 {
+				return nil, fmt.Errorf("%w: invalid port %d", network.ErrInvalidEgressPolicy, rule.Port)
+			}
+			result = append(result, network.EgressRule{
+				CIDR:     rule.Cidr,
+				Protocol: rule.Protocol,
+				Port:     uint16(rule.Port),
+			})
+		}
+		return result, nil
+	}
+	allow, err := convert(p.Allow)
+	if err != nil {
+		return nil, err
+	}
+	deny, err := convert(p.Deny)
+	if err != nil {
+		return nil, err
+	}
+	policy := &network.EgressPolicy{Allow: allow, Deny: deny, DNS: dns}
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func newPortForwards(forwards []*orchestrator.PortForward) ([]network.PortForward, error) {
+	var result []network.PortForward
+	for _, f := range forwards {
+		if f.HostPort > math.MaxUint16 || f.GuestPort > math.MaxUint16 {
+			return nil, fmt.Errorf("%w: invalid port %d->%d", network.ErrInvalidPortForward, f.HostPort, f.GuestPort)
+		}
+		result = append(result, network.PortForward{
+			HostPort:  uint16(f.HostPort),
+			GuestPort: uint16(f.GuestPort),
+			Protocol:  f.Protocol,
+		})
+	}
+	if err := network.ValidatePortForwards(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// The template should be the one specified by req.TemplateID.
+func newSandboxConfigFromTemplate(
+	req *orchestrator.SandboxCreateRequest,
+	t *config.VMTemplate,
+	cfg *OrchestratorConfig,
+) (*sandbox.SandboxConfig, error) {
+	if req.CloudInitUserData != nil && !t.ConfigDrive {
+		return nil, fmt.Errorf("template %s does not enable config drive", req.TemplateID)
+	}
+	if req.CgroupCpuMax != nil {
+		if err := sandbox.ValidateCgroupCpuMax(*req.CgroupCpuMax); err != nil {
+			return nil, err
+		}
+	}
+	if req.GetCgroupMemoryMax() < 0 {
+		return nil, fmt.Errorf("%w: negative memory.max %d", sandbox.ErrInvalidCgroupLimit, req.GetCgroupMemoryMax())
+	}
+	if err := sandbox.ValidateCgroupSubpath(req.GetCgroupSubpath()); err != nil {
+		return nil, err
+	}
+	var extraDisks []sandbox.DiskSpec
+	for _, disk := range req.ExtraDisks {
+		extraDisks = append(extraDisks, sandbox.DiskSpec{
+			SizeMB:      disk.SizeMB,
+			ReadOnly:    disk.ReadOnly,
+			BackingFile: disk.BackingFile,
+		})
+	}
+	if err := sandbox.ValidateExtraDisks(t, extraDisks, cfg.ExtraDiskBackingDir); err != nil {
+		return nil, err
+	}
+	if err := sandbox.ValidateMetadata(req.Metadata); err != nil {
+		return nil, err
+	}
+	if err := sandbox.ValidateProcessDefaults(t, req.GetWorkingDir(), req.Env); err != nil {
+		return nil, err
+	}
+	if err := sandbox.ValidateMemoryOverride(t, req.GetMemoryOverrideMB()); err != nil {
+		return nil, err
+	}
+	diskRateLimit := hypervisor.DiskRateLimit{
+		BandwidthBps: req.GetDiskBandwidthBps(),
+		Iops:         req.GetDiskIops(),
+	}
+	if err := sandbox.ValidateDiskRateLimitOverride(t, diskRateLimit, req.GetColdBoot()); err != nil {
+		return nil, err
+	}
+	if err := sandbox.ValidateKernelImagePathOverride(req.GetKernelImagePathOverride(), req.GetColdBoot()); err != nil {
+		return nil, err
+	}
+	egressPolicy, err := newEgressPolicy(req.EgressPolicy, t.GuestDNSServers())
+	if err != nil {
+		return nil, err
+	}
+	portForwards, err := newPortForwards(req.PortForwards)
+	if err != nil {
+		return nil, err
+	}
+	socketPath := sandbox.GetSocketPath(cfg.SocketDir, req.SandboxID)
 
 	var hypervisorPath string
 	if req.HypervisorBinaryPath == nil || len(*req.HypervisorBinaryPath) == 0 {
@@ -65,34 +203,108 @@ func newSandboxConfig(req *orchestrator.SandboxCreateRequest, cfg *OrchestratorC
 		hypervisorPath = *req.HypervisorBinaryPath
 	}
 
-	return &sandbox.SandboxConfig{
-		VMTemplate:           t,
-		DataRoot:             cfg.DataRoot,
-		SandboxID:            req.SandboxID,
-		CgroupName:           cfg.CgroupName,
-		SocketPath:           socketPath,
-		HypervisorBinaryPath: hypervisorPath,
-		EnableDiffSnapshot:   req.EnableDiffSnapshots,
-		MaxInstanceLength:    int(req.MaxInstanceLength),
-		Metadata:             req.Metadata,
-	}, nil
+	if req.GuestMac != nil {
+		if err := network.ValidateMAC(*req.GuestMac); err != nil {
+			return nil, err
+		}
+	}
+	consoleLog := cfg.ConsoleLog
+	if req.ConsoleLog != nil {
+		consoleLog = *req.ConsoleLog
+	}
+
+	sbxCfg := &sandbox.SandboxConfig{
+		VMTemplate:              *t,
+		DataRoot:                cfg.DataRoot,
+		SandboxID:               req.SandboxID,
+		CgroupName:              cfg.CgroupName,
+		CgroupfsPath:            cfg.CgroupfsPath,
+		CgroupSubpath:           req.GetCgroupSubpath(),
+		SocketPath:              socketPath,
+		HypervisorBinaryPath:    hypervisorPath,
+		SeccompProfilePath:      cfg.SeccompProfile,
+		EnableDiffSnapshot:      req.EnableDiffSnapshots,
+		MaxInstanceLength:       int(req.MaxInstanceLength),
+		Metadata:                req.Metadata,
+		CloudInitUserData:       req.GetCloudInitUserData(),
+		PrometheusProxyAddr:     cfg.PrometheusProxyAddr,
+		PrometheusProbeAddr:     cfg.PrometheusProbeAddr,
+		PrometheusProbeTimeout:  time.Duration(cfg.PrometheusProbeTimeoutMs) * time.Millisecond,
+		CancelSnapshotOnDelete:  cfg.CancelSnapshotOnDelete,
+		SnapshotRoot:            cfg.SnapshotRoot,
+		CgroupCpuMax:            req.GetCgroupCpuMax(),
+		CgroupMemoryMax:         req.GetCgroupMemoryMax(),
+		ExtraDisks:              extraDisks,
+		SocketWait:              cfg.SocketWait,
+		Restore:                 cfg.restoreOptions(),
+		SnapshotAttempts:        cfg.SnapshotAttempts,
+		ClockSync:               cfg.clockSyncOptions(),
+		MemoryOverrideMB:        req.GetMemoryOverrideMB(),
+		DiskRateLimitOverride:   diskRateLimit,
+		WorkingDir:              req.GetWorkingDir(),
+		Env:                     req.Env,
+		EgressPolicy:            egressPolicy,
+		PortForwards:            portForwards,
+		VerifyChecksums:         cfg.VerifyChecksums,
+		KeepFilesOnError:        cfg.KeepFilesOnError,
+		ConsoleLog:              consoleLog,
+		ConsoleLogMaxSize:       cfg.ConsoleLogMaxSizeMB << 20,
+		RetainConsoleLog:        cfg.RetainConsoleLog,
+		FcLogLevel:              cfg.FcLogLevel,
+		FcMetrics:               cfg.FcMetrics,
+		RetainFcLogs:            cfg.RetainFcLogs,
+		Jailer:                  cfg.jailerOptions(),
+		GuestMAC:                req.GetGuestMac(),
+		UniqueGuestMAC:          cfg.UniqueGuestMAC,
+		ColdBoot:                req.GetColdBoot(),
+		KernelImagePathOverride: req.GetKernelImagePathOverride(),
+	}
+	if sbxCfg.Jailer != nil && t.VmmType == config.FIRECRACKER {
+		sbxCfg.SocketPath = sbxCfg.JailerSocketPath()
+	}
+	return sbxCfg, nil
 }
 
 func (s *server) NewSandboxConfig(
 	ctx context.Context,
 	req *orchestrator.SandboxCreateRequest,
 ) (*sandbox.SandboxConfig, error) {
-	_, span := s.tracer.Start(ctx, "new-sandbox-config")
+	return s.newSandboxConfig(ctx, req, nil)
+}
+
+// Same as NewSandboxConfig, but use the parsed template if it is not nil.
+func (s *server) newSandboxConfig(
+	ctx context.Context,
+	req *orchestrator.SandboxCreateRequest,
+	t *config.VMTemplate,
+) (*sandbox.SandboxConfig, error) {
+	childCtx, span := s.tracer.Start(ctx, "new-sandbox-config")
 	defer span.End()
-	sbxCfg, err := newSandboxConfig(req, s.cfg)
+	if t == nil {
+		if err := s.templates.Ensure(childCtx, s.tracer, req.TemplateID); err != nil {
+			return nil, err
+		}
+	}
+	var (
+		sbxCfg *sandbox.SandboxConfig
+		err    error
+	)
+	if t == nil {
+		sbxCfg, err = newSandboxConfig(req, s.cfg)
+	} else {
+		sbxCfg, err = newSandboxConfigFromTemplate(req, t, s.cfg)
+	}
 	if err != nil {
 		return nil, err
 	}
+	sbxCfg.EnvdClient = s.envdClient
+	sbxCfg.OverlayPool = s.overlayPool
+	sbxCfg.OnEvent = s.events.publish
 	span.SetAttributes(
 		attribute.String("instance.env_instance_path", sbxCfg.InstancePath()),
 		attribute.String("instance.private_dir", sbxCfg.PrivateDir(sbxCfg.DataRoot)),
 		attribute.String("instance.template_dir", sbxCfg.TemplateDir(sbxCfg.DataRoot)),
-		attribute.String("instance.kernel.host_path", sbxCfg.HostKernelPath(sbxCfg.DataRoot)),
+		attribute.String("instance.kernel.host_path", sbxCfg.HostKernelImagePath()),
 		attribute.String("instance.hypervisor.path", sbxCfg.HypervisorBinaryPath),
 		attribute.String("instance.cgroup.path", sbxCfg.CgroupPath()),
 	)
@@ -100,6 +312,23 @@ func (s *server) NewSandboxConfig(
 	return sbxCfg, nil
 }
 
+// Fail early if the hypervisor binary of sbxCfg cannot be found, e.g., only
+// firecracker is installed but the template uses cloud-hypervisor, the
+// template is built for another arch, or the guest cannot read the metadata
+// (see ValidateGuestMetadata).
+func checkHypervisor(sbxCfg *sandbox.SandboxConfig) error {
+	if err := sbxCfg.ValidateHostArch(); err != nil {
+		return err
+	}
+	if err := sbxCfg.ValidateGuestMetadata(); err != nil {
+		return err
+	}
+	if _, err := exec.LookPath(sbxCfg.HypervisorBinaryPath); err != nil {
+		return fmt.Errorf("%w: %s binary %s not found", ErrHypervisorUnavailable, sbxCfg.VmmType, sbxCfg.HypervisorBinaryPath)
+	}
+	return nil
+}
+
 func (s *server) Create(ctx context.Context, req *orchestrator.SandboxCreateRequest) (*orchestrator.SandboxCreateResponse, error) {
 	childCtx, childSpan := s.tracer.Start(ctx, "grpc-create", trace.WithAttributes(
 		attribute.String("env.id", req.TemplateID),
@@ -107,73 +336,212 @@ func (s *server) Create(ctx context.Context, req *orchestrator.SandboxCreateRequ
 	))
 	defer childSpan.End()
 
+	var release func()
+	if req.SandboxID == "" {
+		req.SandboxID, release = s.reserveNewSandboxID()
+		defer release()
+		childSpan.SetAttributes(attribute.String("sandbox.id", req.SandboxID))
+	} else {
+		// the client may retry after a slow but successful create
+		existing, err := s.existingSandbox(req)
+		if errors.Is(err, ErrSandboxBusy) {
+			return nil, status.New(codes.Unavailable, err.Error()).Err()
+		} else if err != nil {
+			return nil, status.New(codes.AlreadyExists, err.Error()).Err()
+		}
+		if existing != nil {
+			telemetry.ReportEvent(childCtx, "sandbox already created")
+			sbxInfo := existing.GetSandboxInfo()
+			return &orchestrator.SandboxCreateResponse{
+				Info: &sbxInfo,
+			}, nil
+		}
+	}
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+
+	if timeout := s.cfg.createTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		childCtx, cancel = context.WithTimeout(childCtx, timeout)
+		defer cancel()
+	}
+
 	sbxCfg, err := s.NewSandboxConfig(childCtx, req)
 	if err != nil {
-		return nil, status.New(codes.InvalidArgument, fmt.Sprintf("cannot create sandbox config: %s", err.Error())).Err()
+		if timeoutErr := createTimeoutError(childCtx, err); timeoutErr != nil {
+			return nil, timeoutErr
+		}
+		return nil, statusError(codes.InvalidArgument, fmt.Errorf("cannot create sandbox config: %w", err))
+	}
+	if err := checkHypervisor(sbxCfg); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.FailedPrecondition, err.Error()).Err()
+	}
+	if err := s.checkSnapshotCompatibility(sbxCfg); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.FailedPrecondition, err.Error()).Err()
+	}
+
+	if release == nil {
+		release, err = s.reserveSandboxIDs(sbxCfg.SandboxID)
+		if err != nil {
+			return nil, status.New(codes.AlreadyExists, err.Error()).Err()
+		}
+		defer release()
+	}
+
+	sbx, err := s.startSandbox(childCtx, sbxCfg)
+	if err != nil {
+		if timeoutErr := createTimeoutError(childCtx, err); timeoutErr != nil {
+			return nil, timeoutErr
+		}
+		code := codes.Internal
+		if errors.Is(err, sandbox.ErrHostPortUnavailable) {
+			code = codes.FailedPrecondition
+		}
+		return nil, statusError(code, err)
+	}
+
+	sbxInfo := sbx.GetSandboxInfo()
+	return &orchestrator.SandboxCreateResponse{
+		Info: &sbxInfo,
+	}, nil
+}
+
+// Return the DeadlineExceeded status if creating the sandbox failed because
+// ctx (i.e., with the create timeout) has reached its deadline, or nil otherwise.
+// The sandbox has been cleaned up by startSandbox (see sandbox.NewSandbox) then.
+func createTimeoutError(ctx context.Context, err error) error {
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil
+	}
+	return status.New(codes.DeadlineExceeded, fmt.Sprintf("create sandbox timed out: %s", err)).Err()
+}
+
+// Return the sandbox of req.SandboxID if it is running with the template of
+// req, which makes Create idempotent. Return nil if there is no such sandbox,
+// ErrSandboxBusy if it is snapshotting or migrating (i.e., may be running
+// again later), or ErrSandboxExists if it is of another template or not
+// running anymore.
+//
+// The sandbox being created is not returned, whose Create will fail
+// with ErrSandboxExists when reserving the id.
+func (s *server) existingSandbox(req *orchestrator.SandboxCreateRequest) (*sandbox.Sandbox, error) {
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		return nil, nil
+	}
+	if sbx.Config.TemplateID != req.TemplateID {
+		return nil, fmt.Errorf("%w: %s with template %s", ErrSandboxExists, req.SandboxID, sbx.Config.TemplateID)
+	}
+	switch state := sbx.GetState(); state {
+	case orchestrator.SandboxState_RUNNING:
+	case orchestrator.SandboxState_SNAPSHOTTING, orchestrator.SandboxState_MIGRATING:
+		return nil, fmt.Errorf("%w: %s in state %s", ErrSandboxBusy, req.SandboxID, state)
+	default:
+		return nil, fmt.Errorf("%w: %s in state %s", ErrSandboxExists, req.SandboxID, state)
+	}
+	return sbx, nil
+}
+
+// Create the sandbox and start maintaining it (i.e., persist, wait and insert it).
+func (s *server) startSandbox(ctx context.Context, sbxCfg *sandbox.SandboxConfig) (*sandbox.Sandbox, error) {
+	releaseSlot, err := s.createLimiter.acquire(ctx)
+	if err != nil {
+		telemetry.ReportError(ctx, err)
+		return nil, err
+	}
+	defer releaseSlot()
+
+	// released after the sandbox is inserted (then counted by extraDiskUsageMB)
+	release, err := s.reserveExtraDisks(sbxCfg.ExtraDisksSizeMB())
+	if err != nil {
+		telemetry.ReportError(ctx, err)
+		return nil, err
+	}
+	defer release()
+
+	if err := s.ensureDiskSpace(ctx, sbxCfg); err != nil {
+		telemetry.ReportError(ctx, err)
+		return nil, err
 	}
 
 	// TODO(huang-jl): support attach metadata to sandbox
-	sbx, err := sandbox.NewSandbox(childCtx, s.tracer, sbxCfg, s.netManager)
+	sbx, err := sandbox.NewSandbox(ctx, s.tracer, sbxCfg, s.netManager)
 	if err != nil {
 		errMsg := fmt.Errorf("failed to create sandbox: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
+		telemetry.ReportCriticalError(ctx, errMsg)
 
-		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		return nil, errMsg
 	}
 
-	go func() {
-		waitCtx, waitSpan := s.tracer.Start(
-			context.Background(),
-			"wait-sandbox",
-			trace.WithAttributes(
-				attribute.String("sandbox.id", sbx.SandboxID()),
-			),
-		)
-		defer waitSpan.End()
-		defer telemetry.ReportEvent(waitCtx, "sandbox waited for stopping")
-		defer s.metric.DelSandbox(waitCtx, sbx)
-		defer s.DelSandbox(req.SandboxID)
+	// persist before waiting, so the state file will not be left
+	// if the sandbox exits (and cleanup) immediately.
+	if err := sbx.Persist(); err != nil {
+		// the sandbox still works, but cannot be reattached after orchestrator restarts
+		errMsg := fmt.Errorf("failed to persist sandbox state: %w", err)
+		telemetry.ReportError(ctx, errMsg)
+	}
 
-		// TODO(huang-jl) put idx backed to network manager?
-		defer sbx.CleanupAfterFCStop(waitCtx, s.tracer)
+	go s.waitSandbox(sbx)
 
-		err := sbx.Wait()
-		if err != nil {
-			if exiterr, ok := err.(*exec.ExitError); ok {
-				// NOTE(huang-jl) Since we use `kill` to stop the FC process
-				// the Wait() must return error, we do not report it as error here
-				status := exiterr.Sys().(syscall.WaitStatus)
-				if status.Signaled() && status.Signal() == syscall.SIGKILL {
-					telemetry.ReportEvent(waitCtx, "sandbox waited due to sigkill")
-				} else {
-					errMsg := fmt.Errorf("sandbox waited get non-sigkill signal: %w", err)
-					telemetry.ReportError(waitCtx, errMsg)
-				}
-			} else {
-				errMsg := fmt.Errorf("failed to wait for Sandbox: %w", err)
-				telemetry.ReportCriticalError(waitCtx, errMsg)
-			}
-		}
+	s.InsertSandbox(sbx)
+	s.metric.AddSandbox(ctx, sbx)
+	s.events.publish(sbx.Event(orchestrator.SandboxEventType_EVENT_CREATED))
+
+	return sbx, nil
+}
+
+// Wait for the sandbox to stop, then cleanup its resources.
+// Should be started in a new goroutine after sandbox is created (or reattached).
+func (s *server) waitSandbox(sbx *sandbox.Sandbox) {
+	waitCtx, waitSpan := s.tracer.Start(
+		context.Background(),
+		"wait-sandbox",
+		trace.WithAttributes(
+			attribute.String("sandbox.id", sbx.SandboxID()),
+		),
+	)
+	defer waitSpan.End()
+	defer telemetry.ReportEvent(waitCtx, "sandbox waited for stopping")
+	defer s.metric.DelSandbox(waitCtx, sbx)
+	defer s.DelSandbox(sbx.SandboxID())
 
-		// TODO(huang-jl): do not sleep
-		// Wait before removing all resources (see defers above)
-		time.Sleep(1 * time.Second)
+	// TODO(huang-jl) put idx backed to network manager?
+	defer sbx.CleanupAfterFCStop(waitCtx, s.tracer)
 
-		// after wait, we assue the vmm process has already been killed and cleaned
-		// so we can reuse the sandbox network
-		if err := s.netManager.RecycleSandboxNetwork(ctx, sbx.Net); err != nil {
-			errMsg := fmt.Errorf("recycle sandbox network failed: %w", err)
-			telemetry.ReportError(ctx, errMsg)
+	err := sbx.Wait()
+	if err != nil {
+		if exiterr, ok := err.(*exec.ExitError); ok {
+			// NOTE(huang-jl) Since we use `kill` to stop the FC process
+			// the Wait() must return error, we do not report it as error here
+			status := exiterr.Sys().(syscall.WaitStatus)
+			if status.Signaled() && status.Signal() == syscall.SIGKILL {
+				telemetry.ReportEvent(waitCtx, "sandbox waited due to sigkill")
+			} else {
+				errMsg := fmt.Errorf("sandbox waited get non-sigkill signal: %w", err)
+				telemetry.ReportError(waitCtx, errMsg)
+			}
+		} else {
+			errMsg := fmt.Errorf("failed to wait for Sandbox: %w", err)
+			telemetry.ReportCriticalError(waitCtx, errMsg)
 		}
-	}()
+	}
 
-	s.InsertSandbox(sbx)
-	s.metric.AddSandbox(childCtx, sbx)
+	// the vmm process is reaped, but it might not have been torn down by the
+	// kernel (e.g., the threads are still exiting), so wait until the network
+	// is not used before reusing it
+	if err := sbx.Net.WaitUnused(waitCtx, s.cfg.networkReleaseTimeout()); err != nil {
+		errMsg := fmt.Errorf("sandbox network is not released, skip recycling: %w", err)
+		telemetry.ReportCriticalError(waitCtx, errMsg)
+		return
+	}
 
-	sbxInfo := sbx.GetSandboxInfo()
-	return &orchestrator.SandboxCreateResponse{
-		Info: &sbxInfo,
-	}, nil
+	if err := s.netManager.RecycleSandboxNetwork(waitCtx, sbx.Net); err != nil {
+		errMsg := fmt.Errorf("recycle sandbox network failed: %w", err)
+		telemetry.ReportError(waitCtx, errMsg)
+	}
 }
 
 func (s *server) List(ctx context.Context, req *orchestrator.SandboxListRequest) (*orchestrator.SandboxListResponse, error) {
@@ -188,7 +556,7 @@ func (s *server) List(ctx context.Context, req *orchestrator.SandboxListRequest)
 	if req.Orphan {
 		result, err = s.listOrphan(childCtx)
 	} else {
-		result, err = s.list(childCtx, req.Running)
+		result, err = s.list(childCtx, req)
 	}
 	if err != nil {
 		return nil, status.New(codes.Internal, err.Error()).Err()
@@ -198,9 +566,18 @@ func (s *server) List(ctx context.Context, req *orchestrator.SandboxListRequest)
 
 var (
 	sandboxIDRegExp = regexp.MustCompile(fmt.Sprintf(`/%s/([0-9a-zA-Z-]+)`, sandbox.InstancesDirName))
-	netNsNameRegExp = regexp.MustCompile(`ip netns exec ([0-9a-zA-Z-]+)`)
+	// the vmm joins the netns by "ip netns exec", or the jailer joins it by
+	// "--netns /var/run/netns/<name>" (quoted) when chrooted
+	netNsNameRegExp = regexp.MustCompile(`(?:ip netns exec |--netns '?/var/run/netns/)([0-9a-zA-Z-]+)`)
 )
 
+// Whether the cmdline is of the (unshare) process running the vmm of sandbox.
+func isSandboxCmdline(cmdline string) bool {
+	return strings.HasPrefix(cmdline, "unshare") &&
+		(strings.Contains(cmdline, constants.FcBinaryName) || strings.Contains(cmdline, constants.ChBinaryName)) &&
+		netNsNameRegExp.MatchString(cmdline)
+}
+
 func (s *server) getSandboxInfoFromProc(ctx context.Context, proc *process.Process) *orchestrator.SandboxInfo {
 	cmdline, err := proc.Cmdline()
 	if err != nil {
@@ -258,20 +635,17 @@ func (s *server) listOrphan(ctx context.Context) (*orchestrator.SandboxListRespo
 			// TODO(huang-jl): return error or just continue?
 			continue
 		}
-		if !strings.HasPrefix(cmdline, "unshare") {
-			continue
-		}
-		if !strings.Contains(cmdline, constants.FcBinaryName) &&
-			!strings.Contains(cmdline, constants.ChBinaryName) {
-			continue
-		}
-		if !strings.Contains(cmdline, "ip netns exec") {
+		if !isSandboxCmdline(cmdline) {
 			continue
 		}
 		info := s.getSandboxInfoFromProc(ctx, process)
 		if info == nil {
 			continue
 		}
+		// e.g., reattached from previous orchestrator, so it is not orphan
+		if _, ok := s.GetSandbox(info.SandboxID); ok {
+			continue
+		}
 		results = append(results, info)
 	}
 	return &orchestrator.SandboxListResponse{
@@ -279,15 +653,32 @@ func (s *server) listOrphan(ctx context.Context) (*orchestrator.SandboxListRespo
 	}, nil
 }
 
+// Whether the sandbox matches all filters (i.e., running, templateID
+// and metadataSelector) in the list request.
+func matchListRequest(sbx *sandbox.Sandbox, req *orchestrator.SandboxListRequest) bool {
+	if req.Running && sbx.State != orchestrator.SandboxState_RUNNING {
+		return false
+	}
+	if req.TemplateID != nil && *req.TemplateID != sbx.Config.TemplateID {
+		return false
+	}
+	for k, v := range req.MetadataSelector {
+		if val, ok := sbx.Metadata()[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
 // This function will only list the sandboxes maintained by current orchestrator.
 // To list orphan (e.g., sandboxes created by previous crashed orchestrator, see `listOrphan`)
 //
-// @running: only list sandboxes whose state = running
-func (s *server) list(_ context.Context, running bool) (*orchestrator.SandboxListResponse, error) {
+// Only sandboxes match all filters in the req will be returned.
+func (s *server) list(_ context.Context, req *orchestrator.SandboxListRequest) (*orchestrator.SandboxListResponse, error) {
 	s.mu.Lock()
 	results := make([]*orchestrator.SandboxInfo, 0, len(s.sandboxes))
 	for _, sbx := range s.sandboxes {
-		if running && sbx.State != orchestrator.SandboxState_RUNNING {
+		if !matchListRequest(sbx, req) {
 			continue
 		}
 		sbxInfo := sbx.GetSandboxInfo()
@@ -320,7 +711,7 @@ func (s *server) Delete(ctx context.Context, req *orchestrator.SandboxDeleteRequ
 		errMsg := fmt.Errorf("sandbox stop failed: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
 
-		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		return nil, statusError(codes.Internal, errMsg)
 	}
 	// TODO(huang-jl): do we need wait until clean?
 
@@ -345,7 +736,7 @@ func (s *server) Deactive(ctx context.Context, req *orchestrator.SandboxDeactiva
 	if err != nil {
 		errMsg := fmt.Errorf("get prev host memory consumption for sandbox %s failed: %w", sbx.SandboxID(), err)
 		telemetry.ReportError(childCtx, errMsg)
-		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		return nil, statusError(codes.Internal, errMsg)
 	}
 	telemetry.ReportEvent(childCtx, "get prev host memory consumption",
 		attribute.Int64("memory.consumption", prevConsumption),
@@ -355,7 +746,7 @@ func (s *server) Deactive(ctx context.Context, req *orchestrator.SandboxDeactiva
 	start := time.Now()
 	if err := sbx.Deactive(childCtx, s.tracer); err != nil {
 		errMsg := fmt.Errorf("deactive sandbox failed: %w", err)
-		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		return nil, statusError(codes.Internal, errMsg)
 	}
 	s.metric.RecordDeactiveDuration(childCtx, sbx, time.Since(start))
 
@@ -364,7 +755,7 @@ func (s *server) Deactive(ctx context.Context, req *orchestrator.SandboxDeactiva
 	if err != nil {
 		errMsg := fmt.Errorf("get curr host memory consumption for sandbox %s failed: %w", sbx.SandboxID(), err)
 		telemetry.ReportError(childCtx, errMsg)
-		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		return nil, statusError(codes.Internal, errMsg)
 	}
 	telemetry.ReportEvent(childCtx, "get current host memory consumption",
 		attribute.Int64("memory.consumption", currConsumption),
@@ -395,6 +786,115 @@ func (s *server) Search(ctx context.Context, req *orchestrator.SandboxSearchRequ
 	}, nil
 }
 
+func (s *server) PendingLogs(ctx context.Context, req *orchestrator.SandboxPendingLogsRequest) (*orchestrator.SandboxPendingLogsResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-pending-logs", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	logsStatus, err := sbx.PendingLogs(childCtx)
+	if err != nil {
+		errMsg := fmt.Errorf("query pending logs of sandbox %s failed: %w", sbx.SandboxID(), err)
+		telemetry.ReportError(childCtx, errMsg)
+
+		return nil, status.New(codes.Unavailable, errMsg.Error()).Err()
+	}
+
+	return &orchestrator.SandboxPendingLogsResponse{
+		PendingEntries: logsStatus.PendingEntries,
+		PendingBytes:   logsStatus.PendingBytes,
+		DroppedEntries: logsStatus.DroppedEntries,
+		LastError:      logsStatus.LastError,
+	}, nil
+}
+
+func (s *server) SyncClock(ctx context.Context, req *orchestrator.SandboxSyncClockRequest) (*empty.Empty, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-sync-clock", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	if err := sbx.SyncClock(childCtx); err != nil {
+		errMsg := fmt.Errorf("sync clock of sandbox %s failed: %w", sbx.SandboxID(), err)
+		telemetry.ReportError(childCtx, errMsg)
+
+		return nil, status.New(codes.Unavailable, errMsg.Error()).Err()
+	}
+
+	return &empty.Empty{}, nil
+}
+
+func (s *server) RecreateNetwork(ctx context.Context, req *orchestrator.SandboxRecreateNetworkRequest) (*empty.Empty, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-recreate-network", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	if err := sbx.RecreateNetwork(childCtx, s.tracer, s.netManager); err != nil {
+		errMsg := fmt.Errorf("recreate network of sandbox %s failed: %w", sbx.SandboxID(), err)
+		telemetry.ReportError(childCtx, errMsg)
+
+		code := codes.Internal
+		if errors.Is(err, sandbox.ErrSandboxProcessGone) || errors.Is(err, network.ErrTapGone) {
+			// the sandbox has to be deleted (and created again)
+			code = codes.FailedPrecondition
+		}
+		return nil, statusError(code, errMsg)
+	}
+	telemetry.ReportEvent(childCtx, "network recreated")
+
+	return &empty.Empty{}, nil
+}
+
+func (s *server) RefreshPrometheusTarget(ctx context.Context, req *orchestrator.SandboxRefreshPrometheusTargetRequest) (*empty.Empty, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-refresh-prometheus-target", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	if err := sbx.RefreshPrometheusTarget(childCtx, s.tracer); err != nil {
+		errMsg := fmt.Errorf("refresh prometheus target of sandbox %s failed: %w", sbx.SandboxID(), err)
+		telemetry.ReportError(childCtx, errMsg)
+
+		return nil, statusError(codes.Internal, errMsg)
+	}
+	telemetry.ReportEvent(childCtx, "prometheus target refreshed")
+
+	return &empty.Empty{}, nil
+}
+
 func (s *server) Purge(ctx context.Context, req *orchestrator.SandboxPurgeRequest) (*empty.Empty, error) {
 	childCtx, childSpan := s.tracer.Start(ctx, "grpc-purge", trace.WithAttributes(
 		attribute.Bool("purge-all", req.PurgeAll),
@@ -437,6 +937,140 @@ func (s *server) Purge(ctx context.Context, req *orchestrator.SandboxPurgeReques
 	}
 }
 
+func (s *server) PurgeFailed(ctx context.Context, req *orchestrator.SandboxPurgeFailedRequest) (*orchestrator.SandboxPurgeFailedResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-purge-failed", trace.WithAttributes(
+		attribute.StringSlice("sandbox-ids", req.SandboxIDs),
+	))
+	defer childSpan.End()
+
+	// the unreadable ones are skipped (and reported)
+	failed, finalErr := sandbox.ListFailedSandboxes(s.cfg.DataRoot)
+	if finalErr != nil {
+		telemetry.ReportError(childCtx, finalErr)
+	}
+	var purged []string
+	for _, info := range failed {
+		if len(req.SandboxIDs) > 0 && !slices.Contains(req.SandboxIDs, info.SandboxID) {
+			continue
+		}
+		if info.NetworkIdx > 0 {
+			if err := s.netManager.RecycleKeptNetwork(childCtx, info.NetworkIdx, info.SandboxID); err != nil {
+				errMsg := fmt.Errorf("recycle network of failed sandbox %s failed: %w", info.SandboxID, err)
+				telemetry.ReportError(childCtx, errMsg)
+				finalErr = errors.Join(finalErr, errMsg)
+				continue
+			}
+		}
+		if err := os.RemoveAll(info.Dir); err != nil {
+			errMsg := fmt.Errorf("remove files of failed sandbox %s failed: %w", info.SandboxID, err)
+			telemetry.ReportError(childCtx, errMsg)
+			finalErr = errors.Join(finalErr, errMsg)
+			continue
+		}
+		purged = append(purged, info.SandboxID)
+	}
+	telemetry.ReportEvent(childCtx, "purged failed sandboxes", attribute.StringSlice("sandbox-ids", purged))
+	if finalErr != nil {
+		return nil, status.Error(codes.Internal, finalErr.Error())
+	}
+	return &orchestrator.SandboxPurgeFailedResponse{SandboxIDs: purged}, nil
+}
+
+func (s *server) AdoptOrphans(ctx context.Context, req *orchestrator.SandboxAdoptOrphansRequest) (*orchestrator.SandboxAdoptOrphansResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-adopt-orphans", trace.WithAttributes(
+		attribute.StringSlice("sandbox-ids", req.SandboxIDs),
+	))
+	defer childSpan.End()
+
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	orphans, err := s.listOrphan(childCtx)
+	if err != nil {
+		return nil, status.New(codes.Internal, err.Error()).Err()
+	}
+	resp := &orchestrator.SandboxAdoptOrphansResponse{}
+	found := make(map[string]bool)
+	for _, info := range orphans.Sandboxes {
+		if len(req.SandboxIDs) > 0 && !slices.Contains(req.SandboxIDs, info.SandboxID) {
+			continue
+		}
+		found[info.SandboxID] = true
+		sbx, err := s.adoptOrphan(childCtx, info)
+		if err != nil {
+			// the sandbox is left as orphan, which can still be purged
+			errMsg := fmt.Errorf("adopt orphan sandbox failed: %w", err)
+			telemetry.ReportError(childCtx, errMsg, attribute.String("sandbox.id", info.SandboxID))
+			resp.Skipped = append(resp.Skipped, &orchestrator.SandboxAdoptOrphanSkipped{
+				SandboxID: info.SandboxID,
+				Reason:    err.Error(),
+			})
+			continue
+		}
+		sbxInfo := sbx.GetSandboxInfo()
+		resp.Adopted = append(resp.Adopted, &sbxInfo)
+	}
+	for _, sandboxID := range req.SandboxIDs {
+		if !found[sandboxID] {
+			resp.Skipped = append(resp.Skipped, &orchestrator.SandboxAdoptOrphanSkipped{
+				SandboxID: sandboxID,
+				Reason:    "orphan sandbox not found",
+			})
+		}
+	}
+	telemetry.ReportEvent(childCtx, "adopted orphan sandboxes",
+		attribute.Int("adopted", len(resp.Adopted)),
+		attribute.Int("skipped", len(resp.Skipped)),
+	)
+	return resp, nil
+}
+
+// Rebuild the orphan sandbox as reattachSandboxes, while the state is
+// derived from the vmm process (see getSandboxInfoFromProc) instead of
+// the persisted one. The sandbox is managed by this orchestrator once adopted.
+func (s *server) adoptOrphan(ctx context.Context, info *orchestrator.SandboxInfo) (*sandbox.Sandbox, error) {
+	release, err := s.reserveSandboxIDs(info.SandboxID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	sbxCfg, err := s.NewSandboxConfig(ctx, &orchestrator.SandboxCreateRequest{
+		TemplateID:    info.GetTemplateID(),
+		SandboxID:     info.SandboxID,
+		CgroupSubpath: sandbox.ProcessCgroupSubpath(int(info.GetPid()), s.cfg.CgroupName, info.SandboxID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create sandbox config: %w", err)
+	}
+	startAt := time.Now()
+	if proc, err := process.NewProcess(int32(info.GetPid())); err == nil {
+		if createTime, err := proc.CreateTime(); err == nil {
+			startAt = time.UnixMilli(createTime)
+		}
+	}
+	state := &sandbox.PersistedSandbox{
+		SandboxID:            info.SandboxID,
+		TemplateID:           sbxCfg.TemplateID,
+		Pid:                  int(info.GetPid()),
+		NetworkIdx:           int(info.GetNetworkIdx()),
+		IPv6:                 sbxCfg.IPv6,
+		CgroupPath:           sbxCfg.CgroupPath(),
+		CgroupSubpath:        sbxCfg.CgroupSubpath,
+		HypervisorBinaryPath: sbxCfg.HypervisorBinaryPath,
+		StartAt:              startAt,
+	}
+	sbx, err := sandbox.AdoptSandbox(ctx, s.tracer, sbxCfg, state, s.netManager)
+	if err != nil {
+		return nil, err
+	}
+	go s.waitSandbox(sbx)
+	s.InsertSandbox(sbx)
+	s.metric.AddSandbox(ctx, sbx)
+	telemetry.ReportEvent(ctx, "adopted orphan sandbox", attribute.String("sandbox.id", info.SandboxID))
+	return sbx, nil
+}
+
 func (s *server) Snapshot(ctx context.Context, req *orchestrator.SandboxSnapshotRequest) (*orchestrator.SandboxSnapshotResponse, error) {
 	childCtx, childSpan := s.tracer.Start(ctx, "grpc-snapshot", trace.WithAttributes(
 		attribute.String("sandbox.id", req.SandboxID),
@@ -452,20 +1086,107 @@ func (s *server) Snapshot(ctx context.Context, req *orchestrator.SandboxSnapshot
 		return nil, status.New(codes.NotFound, err.Error()).Err()
 	}
 
-	if err := sbx.CreateSnapshot(childCtx, s.tracer, req.Delete); err != nil {
+	snapshotDir := sbx.Config.EnvInstanceCreateSnapshotPath()
+	if req.DestinationDir != "" {
+		dir, err := resolveSnapshotDir(req.DestinationDir, s.cfg.snapshotDestinationRoots())
+		if err != nil {
+			telemetry.ReportError(childCtx, err)
+			return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+		}
+		snapshotDir = dir
+	}
+
+	if err := sbx.CreateSnapshot(childCtx, s.tracer, snapshotDir, req.Delete); err != nil {
 		errMsg := fmt.Errorf("create snapshot failed: %w", err)
 		telemetry.ReportError(childCtx, errMsg)
 
-		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		code := codes.Internal
+		if errors.Is(err, sandbox.ErrSandboxStopping) {
+			code = codes.Aborted
+		}
+		return nil, statusError(code, errMsg)
 	}
 
 	return &orchestrator.SandboxSnapshotResponse{
-		Path: sbx.Config.EnvInstanceCreateSnapshotPath(),
+		Path: snapshotDir,
+	}, nil
+}
+
+func (s *server) SnapshotAsTemplate(ctx context.Context, req *orchestrator.SandboxSnapshotAsTemplateRequest) (*orchestrator.SandboxSnapshotAsTemplateResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-snapshot-as-template", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+		attribute.String("template.id", req.TemplateID),
+	))
+	defer childSpan.End()
+
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	t, err := sbx.SnapshotAsTemplate(childCtx, s.tracer, req.TemplateID, req.Delete, func(err error) {
+		if err == nil {
+			s.templateList.invalidate()
+		}
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("snapshot as template failed: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+
+		code := codes.Internal
+		switch {
+		case errors.Is(err, sandbox.ErrTemplateExists):
+			code = codes.AlreadyExists
+		case errors.Is(err, sandbox.ErrInvalidTemplateID),
+			errors.Is(err, sandbox.ErrDiffSnapshotTemplate),
+			errors.Is(err, sandbox.ErrKernelOverrideTemplate):
+			code = codes.InvalidArgument
+		case errors.Is(err, sandbox.ErrSandboxStopping):
+			code = codes.Aborted
+		}
+		return nil, statusError(code, errMsg)
+	}
+
+	return &orchestrator.SandboxSnapshotAsTemplateResponse{
+		TemplateID: t.TemplateID,
+		Path:       t.TemplateDir(s.cfg.DataRoot),
 	}, nil
 }
 
+func (s *server) SetMetadata(ctx context.Context, req *orchestrator.SandboxSetMetadataRequest) (*orchestrator.SandboxSetMetadataResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-set-metadata", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	metadata, err := sbx.SetMetadata(req.Metadata, req.Replace)
+	if err != nil {
+		errMsg := fmt.Errorf("set metadata failed: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+
+		code := codes.Internal
+		if errors.Is(err, sandbox.ErrInvalidMetadata) {
+			code = codes.InvalidArgument
+		}
+		return nil, status.New(code, errMsg.Error()).Err()
+	}
+
+	return &orchestrator.SandboxSetMetadataResponse{Metadata: metadata}, nil
+}
+
 func (s *server) RecreateCgroup(ctx context.Context, _ *empty.Empty) (*empty.Empty, error) {
-	cgroupParentPath := filepath.Join(consts.CgroupfsPath, s.cfg.CgroupName)
+	cgroupParentPath := s.cfg.cgroupParentPath()
 	// first remove, and then recreate
 	if err := os.Remove(cgroupParentPath); err != nil {
 		return nil, status.Errorf(codes.Internal, "remove cgroup failed: %s", err.Error())
@@ -476,25 +1197,48 @@ func (s *server) RecreateCgroup(ctx context.Context, _ *empty.Empty) (*empty.Emp
 	return &empty.Empty{}, nil
 }
 
+func (s *server) ListNetworks(ctx context.Context, _ *empty.Empty) (*orchestrator.HostManageListNetworksResponse, error) {
+	return &orchestrator.HostManageListNetworksResponse{
+		Networks: s.netManager.ListNetworks(),
+	}, nil
+}
+
+func (s *server) WarmNetworks(ctx context.Context, req *orchestrator.HostManageWarmNetworksRequest) (*orchestrator.HostManageWarmNetworksResponse, error) {
+	if req.Count < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "count cannot be negative: %d", req.Count)
+	}
+	count := int(req.Count)
+	if count == 0 {
+		count = s.netManager.PoolSize
+	}
+	created, warm, err := s.netManager.WarmNetworks(ctx, s.tracer, count, req.Ipv6)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, status.FromContextError(ctxErr).Err()
+		}
+		return nil, statusError(codes.Internal, fmt.Errorf("warm networks failed after %d created: %w", created, err))
+	}
+	return &orchestrator.HostManageWarmNetworksResponse{
+		Created:         int64(created),
+		AlreadyWarm:     int64(warm),
+		WarmNetworks:    int64(s.netManager.WarmNetworkCount()),
+		NetworkPoolSize: int64(s.netManager.PoolSize),
+	}, nil
+}
+
 func (s *server) CleanNetworkEnv(ctx context.Context, req *orchestrator.HostManageCleanNetworkEnvRequest) (*empty.Empty, error) {
 	var finalErr error
 	for _, networkIdx := range req.GetNetworkIDs() {
-		netEnv := network.NewNetworkEnv(int(networkIdx), s.netManager.VethSubnet)
+		// whether the network enabled ipv6 is unknown, try deleting the ipv6 ones as well
+		netEnv := s.netManager.NetworkEnv(int(networkIdx), true)
 		// sandbox id is useless here
 		net := network.NewSandboxNetwork(netEnv, "")
-		if err := net.DeleteNetns(); err != nil {
-			finalErr = errors.Join(finalErr, err)
-		}
-		if err := net.DeleteHostVethDev(); err != nil {
-			finalErr = errors.Join(finalErr, err)
-		}
-		if err := net.DeleteHostIptables(); err != nil {
+		if err := net.Reclaim(); err != nil {
 			finalErr = errors.Join(finalErr, err)
 		}
-		if err := net.DeleteHostRoute(); err != nil {
+		if err := s.netManager.DNS().RemoveAddress(net.HostClonedIP()); err != nil {
 			finalErr = errors.Join(finalErr, err)
 		}
-		s.netManager.DNS().RemoveAddress(net.HostClonedIP())
 	}
 	if finalErr != nil {
 		return nil, status.Error(codes.Internal, finalErr.Error())
@@ -0,0 +1,257 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNewSandboxConfigCloudInit(t *testing.T) {
+	cfg := &OrchestratorConfig{DataRoot: t.TempDir()}
+	userData := "#cloud-config\n"
+	req := &orchestrator.SandboxCreateRequest{
+		TemplateID:        "default",
+		SandboxID:         "sandbox",
+		CloudInitUserData: &userData,
+	}
+
+	tmpl := newTestTemplate("default")
+	if _, err := newSandboxConfigFromTemplate(req, &tmpl, cfg); err == nil || !strings.Contains(err.Error(), "config drive") {
+		t.Fatalf("expect error when config drive is not enabled, got %v", err)
+	}
+
+	tmpl.ConfigDrive = true
+	sbxCfg, err := newSandboxConfigFromTemplate(req, &tmpl, cfg)
+	if err != nil {
+		t.Fatalf("new sandbox config failed: %s", err)
+	}
+	if sbxCfg.CloudInitUserData != userData {
+		t.Fatalf("unexpected user data %q", sbxCfg.CloudInitUserData)
+	}
+}
+
+func TestCheckHypervisor(t *testing.T) {
+	sbxCfg := &sandbox.SandboxConfig{
+		VMTemplate:           config.VMTemplate{VmmType: config.CLOUDHYPERVISOR, TargetArch: config.TargetArch(runtime.GOARCH)},
+		HypervisorBinaryPath: "/not-exist/cloud-hypervisor",
+	}
+	err := checkHypervisor(sbxCfg)
+	if !errors.Is(err, ErrHypervisorUnavailable) || !strings.Contains(err.Error(), sbxCfg.HypervisorBinaryPath) {
+		t.Fatalf("expect hypervisor unavailable naming the binary, got %v", err)
+	}
+
+	// the test binary itself is an executable
+	sbxCfg.HypervisorBinaryPath = os.Args[0]
+	if err := checkHypervisor(sbxCfg); err != nil {
+		t.Fatalf("check hypervisor failed: %s", err)
+	}
+
+	// cloud hypervisor lacks mmds, so the metadata needs the config drive
+	sbxCfg.Metadata = map[string]string{"job": "1"}
+	if err := checkHypervisor(sbxCfg); !errors.Is(err, sandbox.ErrGuestMetadataUnsupported) {
+		t.Fatalf("expect metadata unsupported, got %v", err)
+	}
+	sbxCfg.ConfigDrive = true
+	if err := checkHypervisor(sbxCfg); err != nil {
+		t.Fatalf("check hypervisor with config drive failed: %s", err)
+	}
+
+	sbxCfg.TargetArch = config.ArchARM64
+	if runtime.GOARCH == "arm64" {
+		sbxCfg.TargetArch = config.ArchAMD64
+	}
+	if err := checkHypervisor(sbxCfg); !errors.Is(err, config.ErrArchMismatch) {
+		t.Fatalf("expect arch mismatch, got %v", err)
+	}
+}
+
+func TestExistingSandbox(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	newSandbox := func(id, templateID string, state orchestrator.SandboxState) *sandbox.Sandbox {
+		return &sandbox.Sandbox{
+			Config: &sandbox.SandboxConfig{
+				VMTemplate: config.VMTemplate{TemplateID: templateID},
+				SandboxID:  id,
+			},
+			State: state,
+		}
+	}
+	s.sandboxes = map[string]*sandbox.Sandbox{
+		"running":  newSandbox("running", "default", orchestrator.SandboxState_RUNNING),
+		"stopped":  newSandbox("stopped", "default", orchestrator.SandboxState_STOP),
+		"invalid":  newSandbox("invalid", "default", orchestrator.SandboxState_INVALID),
+		"snapshot": newSandbox("snapshot", "default", orchestrator.SandboxState_SNAPSHOTTING),
+		"migrate":  newSandbox("migrate", "default", orchestrator.SandboxState_MIGRATING),
+	}
+
+	sbx, err := s.existingSandbox(&orchestrator.SandboxCreateRequest{SandboxID: "new", TemplateID: "default"})
+	if sbx != nil || err != nil {
+		t.Fatalf("expect no existing sandbox, got %v, %v", sbx, err)
+	}
+	sbx, err = s.existingSandbox(&orchestrator.SandboxCreateRequest{SandboxID: "running", TemplateID: "default"})
+	if err != nil || sbx != s.sandboxes["running"] {
+		t.Fatalf("expect the running sandbox returned, got %v, %v", sbx, err)
+	}
+	for _, req := range []*orchestrator.SandboxCreateRequest{
+		{SandboxID: "running", TemplateID: "other"},
+		{SandboxID: "stopped", TemplateID: "default"},
+		{SandboxID: "invalid", TemplateID: "default"},
+		{SandboxID: "snapshot", TemplateID: "other"},
+	} {
+		if sbx, err := s.existingSandbox(req); sbx != nil || !errors.Is(err, ErrSandboxExists) {
+			t.Fatalf("expect sandbox exists for %s (template %s), got %v, %v", req.SandboxID, req.TemplateID, sbx, err)
+		}
+	}
+	// may be running again, so the client can retry
+	for _, id := range []string{"snapshot", "migrate"} {
+		req := &orchestrator.SandboxCreateRequest{SandboxID: id, TemplateID: "default"}
+		if sbx, err := s.existingSandbox(req); sbx != nil || !errors.Is(err, ErrSandboxBusy) {
+			t.Fatalf("expect sandbox busy for %s, got %v, %v", id, sbx, err)
+		}
+		if _, err := s.Create(context.Background(), req); status.Code(err) != codes.Unavailable {
+			t.Fatalf("expect unavailable for %s, got %v", id, err)
+		}
+	}
+}
+
+func TestSetMetadataErrors(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	s.sandboxes = map[string]*sandbox.Sandbox{
+		"sandbox": {Config: &sandbox.SandboxConfig{SandboxID: "sandbox"}},
+	}
+
+	_, err := s.SetMetadata(context.Background(), &orchestrator.SandboxSetMetadataRequest{
+		SandboxID: "not-exist",
+		Metadata:  map[string]string{"owner": "test"},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expect not found, got %v", err)
+	}
+
+	_, err = s.SetMetadata(context.Background(), &orchestrator.SandboxSetMetadataRequest{
+		SandboxID: "sandbox",
+		Metadata:  map[string]string{"": "empty key"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expect invalid argument, got %v", err)
+	}
+}
+
+func TestAdoptOrphansSkipped(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	resp, err := s.AdoptOrphans(context.Background(), &orchestrator.SandboxAdoptOrphansRequest{
+		SandboxIDs: []string{"not-exist"},
+	})
+	if err != nil {
+		t.Fatalf("adopt orphans failed: %s", err)
+	}
+	if len(resp.Adopted) != 0 || len(resp.Skipped) != 1 || resp.Skipped[0].SandboxID != "not-exist" {
+		t.Fatalf("expect the sandbox skipped, got %v", resp)
+	}
+
+	// being created (or adopted) by another request
+	release, err := s.reserveSandboxIDs("creating")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+	pid := uint32(os.Getpid())
+	_, err = s.adoptOrphan(context.Background(), &orchestrator.SandboxInfo{SandboxID: "creating", Pid: &pid})
+	if !errors.Is(err, ErrSandboxExists) {
+		t.Fatalf("expect sandbox exists, got %v", err)
+	}
+}
+
+func TestSandboxCmdline(t *testing.T) {
+	instanceDir := "/data/default/" + sandbox.InstancesDirName + "/sbx-1"
+	netNsName := "sandbox-net-10-168-0-0-16-3"
+	testCases := []struct {
+		name    string
+		cmdline string
+	}{
+		{
+			"netns exec",
+			"unshare -pfm --kill-child -- bash -c mount --bind " + instanceDir + " /private && " +
+				"ip netns exec " + netNsName + " firecracker --api-sock " + instanceDir + "/firecracker.socket",
+		},
+		{
+			"jailer",
+			"unshare -pfm --kill-child -- bash -c mount --bind " + instanceDir + " /srv/jailer/firecracker/sbx-1/root/private && " +
+				"'jailer' --id 'sbx-1' --exec-file '/usr/bin/firecracker' --uid 123 --gid 456 --chroot-base-dir '/srv/jailer' " +
+				"--netns '/var/run/netns/" + netNsName + "' -- --api-sock /run/firecracker.socket",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if !isSandboxCmdline(tc.cmdline) {
+				t.Fatalf("expect %q recognized as sandbox", tc.cmdline)
+			}
+			if match := netNsNameRegExp.FindStringSubmatch(tc.cmdline); match == nil || match[1] != netNsName {
+				t.Fatalf("expect netns %s, got %v", netNsName, match)
+			}
+			if match := sandboxIDRegExp.FindStringSubmatch(tc.cmdline); match == nil || match[1] != "sbx-1" {
+				t.Fatalf("expect sandbox sbx-1, got %v", match)
+			}
+		})
+	}
+	if isSandboxCmdline("unshare -pfm -- bash -c firecracker --api-sock /tmp/fc.socket") {
+		t.Fatal("expect the vmm outside of netns not recognized as sandbox")
+	}
+}
+
+// The timeout inside NewSandbox is covered by TestNewSandboxTimeout of
+// package sandbox, here it is reached before the sandbox is started.
+func TestCreateTimeoutWaitingSlot(t *testing.T) {
+	dataRoot := t.TempDir()
+	writeTestTemplate(t, dataRoot, newTestTemplate("fc"))
+	s := newTestServer(dataRoot)
+	s.templates = &templateSource{}
+	s.cfg.CreateTimeoutMs = 50
+	s.cfg.FCBinaryPath = os.Args[0]
+	// the only slot is taken, so the create waits until timeout
+	s.createLimiter = newCreateLimiter(1, false, nil)
+	releaseSlot, err := s.createLimiter.acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := &orchestrator.SandboxCreateRequest{TemplateID: "fc", SandboxID: "sandbox"}
+	if _, err := s.Create(context.Background(), req); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+
+	if _, ok := s.GetSandbox("sandbox"); ok {
+		t.Fatal("the timed out sandbox should not be inserted")
+	}
+	if len(s.creating) != 0 {
+		t.Fatalf("expect the sandbox id released, got %v", s.creating)
+	}
+	releaseSlot()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if release, err := s.createLimiter.acquire(ctx); err != nil {
+		t.Fatalf("expect the create slot released, got %v", err)
+	} else {
+		release()
+	}
+
+	// only the deadline is reported as timeout
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := createTimeoutError(canceled, errors.New("failed")); err != nil {
+		t.Fatalf("expect no timeout when canceled, got %v", err)
+	}
+	if err := createTimeoutError(context.Background(), errors.New("failed")); err != nil {
+		t.Fatalf("expect no timeout without deadline, got %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/golang/protobuf/ptypes/empty"
+	"golang.org/x/sys/unix"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// The interval to refresh the status of standard grpc health checking service.
+const healthCheckInterval = 10 * time.Second
+
+func (s *server) Health(ctx context.Context, _ *empty.Empty) (*orchestrator.HostManageHealthResponse, error) {
+	resp := s.checkHealth()
+	s.setServingStatus(resp.Healthy)
+	return resp, nil
+}
+
+func (s *server) checkHealth() *orchestrator.HostManageHealthResponse {
+	s.mu.Lock()
+	sandboxes := len(s.sandboxes)
+	s.mu.Unlock()
+
+	resp := &orchestrator.HostManageHealthResponse{
+		Sandboxes:       int64(sandboxes),
+		FreeNetworks:    int64(s.netManager.FreeNetworks()),
+		WarmNetworks:    int64(s.netManager.WarmNetworkCount()),
+		NetworkPoolSize: int64(s.netManager.PoolSize),
+		Draining:        s.draining.Load(),
+	}
+
+	cgroupPath := s.cfg.cgroupParentPath()
+	if err := unix.Access(cgroupPath, unix.W_OK); err != nil {
+		resp.Problems = append(resp.Problems, fmt.Sprintf("cgroup %s is not writable: %s", cgroupPath, err))
+	} else {
+		resp.CgroupWritable = true
+	}
+	if _, err := exec.LookPath(s.cfg.FCBinaryPath); err == nil {
+		resp.FcBinaryExists = true
+	}
+	if _, err := exec.LookPath(s.cfg.CHBinaryPath); err == nil {
+		resp.ChBinaryExists = true
+	}
+	if !resp.FcBinaryExists && !resp.ChBinaryExists {
+		resp.Problems = append(resp.Problems, "neither firecracker nor cloud-hypervisor binary found")
+	}
+
+	resp.Healthy = len(resp.Problems) == 0
+	return resp
+}
+
+func (s *server) setServingStatus(healthy bool) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if !healthy {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	// empty service name means the overall status of the server
+	for _, service := range []string{
+		"",
+		orchestrator.Sandbox_ServiceDesc.ServiceName,
+		orchestrator.HostManage_ServiceDesc.ServiceName,
+	} {
+		s.health.SetServingStatus(service, status)
+	}
+}
+
+// Refresh the status of standard grpc health checking service periodically,
+// until the health server is shutdown.
+func (s *server) watchHealth(stop <-chan struct{}) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		s.setServingStatus(s.checkHealth().Healthy)
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
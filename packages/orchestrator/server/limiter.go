@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Returned when max_concurrent_creates is reached and reject_creates_over_limit.
+var ErrTooManyCreates = errors.New("too many concurrent creates")
+
+// Bound the number of sandboxes being created (i.e., in startSandbox) at the
+// same time, as each of them does heavy work (e.g., copy the rootfs, restore
+// the snapshot), which saturates the host and times out all of them if too many.
+type createLimiter struct {
+	// nil means unlimited
+	slots chan struct{}
+	// fail immediately instead of waiting for a slot
+	reject bool
+	// the number of sandboxes being created, nil means not recorded
+	inflight metric.Int64UpDownCounter
+}
+
+func newCreateLimiter(max int, reject bool, inflight metric.Int64UpDownCounter) *createLimiter {
+	l := &createLimiter{reject: reject, inflight: inflight}
+	if max > 0 {
+		l.slots = make(chan struct{}, max)
+	}
+	return l
+}
+
+// Wait for a slot until ctx is done (or fail immediately when reject),
+// the returned function releases the slot. It is a no-op on nil.
+func (l *createLimiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return func() {}, nil
+	}
+	if l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		default:
+			if l.reject {
+				return nil, fmt.Errorf("%w: %d sandboxes are being created", ErrTooManyCreates, cap(l.slots))
+			}
+			select {
+			case l.slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil, fmt.Errorf("wait for create slot failed: %w", ctx.Err())
+			}
+		}
+	}
+	if l.inflight != nil {
+		l.inflight.Add(ctx, 1)
+	}
+	return func() {
+		if l.inflight != nil {
+			l.inflight.Add(context.Background(), -1)
+		}
+		if l.slots != nil {
+			<-l.slots
+		}
+	}, nil
+}
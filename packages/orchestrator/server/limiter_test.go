@@ -0,0 +1,59 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCreateLimiter(t *testing.T) {
+	l := newCreateLimiter(1, false, nil)
+	release, err := l.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire failed: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect waiting until deadline, got %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		if release, err := l.acquire(context.Background()); err == nil {
+			release()
+		}
+		close(acquired)
+	}()
+	release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatalf("expect the waiting one acquires after released")
+	}
+
+	reject := newCreateLimiter(1, true, nil)
+	release, err = reject.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire failed: %s", err)
+	}
+	if _, err := reject.acquire(context.Background()); !errors.Is(err, ErrTooManyCreates) {
+		t.Fatalf("expect ErrTooManyCreates, got %v", err)
+	}
+	release()
+	if release, err = reject.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire after released failed: %s", err)
+	}
+	release()
+
+	// unlimited
+	for _, l := range []*createLimiter{nil, newCreateLimiter(0, true, nil)} {
+		for range 10 {
+			if _, err := l.acquire(context.Background()); err != nil {
+				t.Fatalf("expect unlimited, got %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// Only the memory.current of cgroups is read (see [sandbox.Sandbox.HostMemConsumption]),
+// without locking the sandboxes, so that it neither waits for nor blocks
+// the concurrent snapshot or deactivate.
+func (s *server) MemoryConsumption(ctx context.Context, req *orchestrator.SandboxMemoryConsumptionRequest) (*orchestrator.SandboxMemoryConsumptionResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-memory-consumption", trace.WithAttributes(
+		attribute.StringSlice("sandbox.ids", req.SandboxIDs),
+	))
+	defer childSpan.End()
+
+	all := len(req.SandboxIDs) == 0
+	var sandboxes []*sandbox.Sandbox
+	if all {
+		s.mu.Lock()
+		for _, sbx := range s.sandboxes {
+			sandboxes = append(sandboxes, sbx)
+		}
+		s.mu.Unlock()
+	} else {
+		for _, sandboxID := range req.SandboxIDs {
+			sbx, ok := s.GetSandbox(sandboxID)
+			if !ok {
+				err := fmt.Errorf("%w: %s", SandboxNotFound, sandboxID)
+				telemetry.ReportError(childCtx, err)
+
+				return nil, status.New(codes.NotFound, err.Error()).Err()
+			}
+			sandboxes = append(sandboxes, sbx)
+		}
+	}
+
+	result := make([]*orchestrator.SandboxMemoryConsumption, 0, len(sandboxes))
+	for _, sbx := range sandboxes {
+		consumption, err := sbx.HostMemConsumption()
+		if err != nil {
+			errMsg := fmt.Errorf("get host memory consumption for sandbox %s failed: %w", sbx.SandboxID(), err)
+			telemetry.ReportError(childCtx, errMsg)
+			if all {
+				// e.g., the sandbox is deleted after listed
+				continue
+			}
+			return nil, statusError(codes.Internal, errMsg)
+		}
+		result = append(result, &orchestrator.SandboxMemoryConsumption{
+			SandboxID:       sbx.SandboxID(),
+			HostMemoryBytes: consumption,
+		})
+	}
+	if all {
+		slices.SortFunc(result, func(a, b *orchestrator.SandboxMemoryConsumption) int {
+			return strings.Compare(a.SandboxID, b.SandboxID)
+		})
+	}
+	return &orchestrator.SandboxMemoryConsumptionResponse{Sandboxes: result}, nil
+}
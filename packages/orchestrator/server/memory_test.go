@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestMemoryConsumption(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	// the cgroup of sandbox does not exist
+	s.sandboxes = map[string]*sandbox.Sandbox{
+		"sandbox": {Config: &sandbox.SandboxConfig{SandboxID: "sandbox", CgroupName: "not-exist"}},
+	}
+
+	_, err := s.MemoryConsumption(context.Background(), &orchestrator.SandboxMemoryConsumptionRequest{
+		SandboxIDs: []string{"sandbox", "missing"},
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expect NotFound for the missing sandbox, got %v", err)
+	}
+
+	_, err = s.MemoryConsumption(context.Background(), &orchestrator.SandboxMemoryConsumptionRequest{
+		SandboxIDs: []string{"sandbox"},
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("expect Internal when the cgroup is missing, got %v", err)
+	}
+
+	// the sandboxes which cannot be measured are skipped
+	resp, err := s.MemoryConsumption(context.Background(), &orchestrator.SandboxMemoryConsumptionRequest{})
+	if err != nil {
+		t.Fatalf("memory consumption of all sandboxes failed: %s", err)
+	}
+	if len(resp.Sandboxes) != 0 {
+		t.Fatalf("expect no sandbox measured, got %v", resp.Sandboxes)
+	}
+}
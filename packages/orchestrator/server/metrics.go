@@ -42,6 +42,8 @@ func init() {
 
 type serverMetric struct {
 	total metric.Int64UpDownCounter
+	// The number of sandboxes being created
+	creating metric.Int64UpDownCounter
 	// The time spent on deactiving a sandbox
 	deactiveDur metric.Float64Histogram
 	// The memory save on deactiving a sandbox
@@ -58,6 +60,14 @@ func newServerMetric() (*serverMetric, error) {
 		return nil, fmt.Errorf("create metric `total` failed: %w", err)
 	}
 
+	creating, err := meter.Int64UpDownCounter(
+		"sandbox.creating",
+		metric.WithDescription("Number of sandboxes being created (excluding those waiting for max_concurrent_creates)"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create metric `creating` failed: %w", err)
+	}
+
 	deactiveDur, err := meter.Float64Histogram(
 		"deactive.duration",
 		metric.WithDescription("The duration of deactiving a sandbox (in milliseconds)"),
@@ -77,6 +87,7 @@ func newServerMetric() (*serverMetric, error) {
 	}
 	return &serverMetric{
 		total:       total,
+		creating:    creating,
 		deactiveDur: deactiveDur,
 		deactiveMem: deactiveMem,
 	}, nil
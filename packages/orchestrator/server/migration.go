@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+func (s *server) MigrateSend(ctx context.Context, req *orchestrator.SandboxMigrateSendRequest) (*empty.Empty, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-migrate-send", trace.WithAttributes(
+		attribute.String("sandbox.id", req.SandboxID),
+	))
+	defer childSpan.End()
+
+	if err := sandbox.ValidateMigrationURL(req.DestinationUrl); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+	}
+	sbx, ok := s.GetSandbox(req.SandboxID)
+	if !ok {
+		err := SandboxNotFound
+		telemetry.ReportError(childCtx, err)
+
+		return nil, status.New(codes.NotFound, err.Error()).Err()
+	}
+
+	// the sandbox is deleted (by waitSandbox) once the vmm exits
+	if err := sbx.MigrateSend(childCtx, s.tracer, req.DestinationUrl); err != nil {
+		errMsg := fmt.Errorf("migrate sandbox %s failed: %w", sbx.SandboxID(), err)
+		telemetry.ReportError(childCtx, errMsg)
+		code := codes.Internal
+		switch {
+		case errors.Is(err, sandbox.ErrMigrationUnsupported):
+			code = codes.Unimplemented
+		case errors.Is(err, sandbox.ErrSandboxStopping):
+			code = codes.FailedPrecondition
+		}
+		return nil, statusError(code, errMsg)
+	}
+	return &empty.Empty{}, nil
+}
+
+func (s *server) MigrateReceive(ctx context.Context, req *orchestrator.SandboxMigrateReceiveRequest) (*orchestrator.SandboxCreateResponse, error) {
+	if req.Sandbox == nil {
+		return nil, status.New(codes.InvalidArgument, "sandbox is required").Err()
+	}
+	if req.Sandbox.GetColdBoot() {
+		return nil, status.New(codes.InvalidArgument, "cannot cold boot the sandbox received by migration").Err()
+	}
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-migrate-receive", trace.WithAttributes(
+		attribute.String("env.id", req.Sandbox.TemplateID),
+		attribute.String("sandbox.id", req.Sandbox.SandboxID),
+	))
+	defer childSpan.End()
+
+	if err := s.checkDraining(); err != nil {
+		return nil, err
+	}
+	if err := sandbox.ValidateMigrationURL(req.ReceiverUrl); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.InvalidArgument, err.Error()).Err()
+	}
+	sbxCfg, err := s.NewSandboxConfig(childCtx, req.Sandbox)
+	if err != nil {
+		return nil, statusError(codes.InvalidArgument, fmt.Errorf("cannot create sandbox config: %w", err))
+	}
+	if sbxCfg.VmmType != config.CLOUDHYPERVISOR {
+		err := fmt.Errorf("%w: template %s uses %s", sandbox.ErrMigrationUnsupported, sbxCfg.TemplateID, sbxCfg.VmmType)
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.Unimplemented, err.Error()).Err()
+	}
+	if err := checkHypervisor(sbxCfg); err != nil {
+		telemetry.ReportError(childCtx, err)
+		return nil, status.New(codes.FailedPrecondition, err.Error()).Err()
+	}
+	// the memory of received vm is the one of source, so memoryOverrideMB
+	// should be the same as the source (it is only used for accounting)
+	sbxCfg.MigrationReceiverURL = req.ReceiverUrl
+
+	release, err := s.reserveSandboxIDs(sbxCfg.SandboxID)
+	if err != nil {
+		return nil, status.New(codes.AlreadyExists, err.Error()).Err()
+	}
+	defer release()
+
+	sbx, err := s.startSandbox(childCtx, sbxCfg)
+	if err != nil {
+		return nil, statusError(codes.Internal, err)
+	}
+
+	sbxInfo := sbx.GetSandboxInfo()
+	return &orchestrator.SandboxCreateResponse{
+		Info: &sbxInfo,
+	}, nil
+}
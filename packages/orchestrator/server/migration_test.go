@@ -0,0 +1,55 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestMigrateErrors(t *testing.T) {
+	dataRoot := t.TempDir()
+	writeTestTemplate(t, dataRoot, newTestTemplate("fc"))
+	s := newTestServer(dataRoot)
+	s.templates = &templateSource{}
+
+	_, err := s.MigrateSend(context.Background(), &orchestrator.SandboxMigrateSendRequest{
+		SandboxID:      "not-exist",
+		DestinationUrl: "unix:/run/migration.sock",
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expect not found, got %v", err)
+	}
+	_, err = s.MigrateSend(context.Background(), &orchestrator.SandboxMigrateSendRequest{
+		SandboxID:      "not-exist",
+		DestinationUrl: "tcp:10.0.0.1:6000",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expect invalid argument, got %v", err)
+	}
+
+	_, err = s.MigrateReceive(context.Background(), &orchestrator.SandboxMigrateReceiveRequest{
+		ReceiverUrl: "unix:/run/migration.sock",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expect invalid argument, got %v", err)
+	}
+	_, err = s.MigrateReceive(context.Background(), &orchestrator.SandboxMigrateReceiveRequest{
+		Sandbox:     &orchestrator.SandboxCreateRequest{TemplateID: "ch", SandboxID: "sandbox", ColdBoot: true},
+		ReceiverUrl: "unix:/run/migration.sock",
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expect invalid argument for cold boot, got %v", err)
+	}
+	// firecracker does not support live migration
+	_, err = s.MigrateReceive(context.Background(), &orchestrator.SandboxMigrateReceiveRequest{
+		Sandbox:     &orchestrator.SandboxCreateRequest{TemplateID: "fc", SandboxID: "sandbox"},
+		ReceiverUrl: "unix:/run/migration.sock",
+	})
+	if status.Code(err) != codes.Unimplemented {
+		t.Fatalf("expect unimplemented, got %v", err)
+	}
+}
@@ -2,17 +2,22 @@ package server
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+	"go.uber.org/zap"
 )
 
 type OrchestratorConfig struct {
@@ -20,10 +25,160 @@ type OrchestratorConfig struct {
 	Host       config.IP    `toml:"host"`
 	Subnet     config.IPNet `toml:"subnet"`
 	CgroupName string       `toml:"cgroup_name"`
+	// the mountpoint of cgroup2 (i.e., cgroup v2 unified hierarchy), which
+	// the cgroup of sandboxes (i.e., cgroup_name) is created under.
+	CgroupfsPath string `toml:"cgroupfs_path"`
+	// ULA subnet (e.g., fd00:1::/64) used for the ipv6 addresses of sandbox network,
+	// empty means ipv6 is disabled.
+	IPv6Subnet config.IPNet `toml:"ipv6_subnet"`
+	// ipv4 subnet (e.g., 100.64.0.0/16) which the host cloned ips (i.e., the
+	// private ips of sandboxes seen from host) are allocated from, it should
+	// hold max network number of addresses and not overlap with subnet.
+	// Empty means 192.168.168.0 ~ 192.168.255.255.
+	ClonedIPSubnet config.IPNet `toml:"cloned_ip_subnet"`
+	// the prefix of the iptables chains (e.g., CI-FORWARD) holding the host
+	// rules of sandboxes, which are jumped from the built-in chains, so that
+	// the host firewall is not clobbered. Use a distinct one for each
+	// orchestrator on the same host.
+	IptablesChainPrefix string `toml:"iptables_chain_prefix"`
+	// path to a pre-compiled bpf seccomp profile applied to the vmm process,
+	// empty means no extra seccomp filter (besides the one inside vmm)
+	SeccompProfile string `toml:"seccomp_profile"`
+	// address of the nginx proxy (as seen by prometheus) written into
+	// the prometheus target of sandbox (see scripts/nginx.conf)
+	PrometheusProxyAddr string `toml:"prometheus_proxy_addr"`
+	// address of the same proxy as seen by orchestrator, used to probe the
+	// metrics path once after the target is set, empty means do not probe.
+	PrometheusProbeAddr      string `toml:"prometheus_probe_addr"`
+	PrometheusProbeTimeoutMs int    `toml:"prometheus_probe_timeout_ms"`
+	// cancel the in-progress snapshot when deleting a sandbox,
+	// by default the delete waits for the snapshot to finish.
+	CancelSnapshotOnDelete bool `toml:"cancel_snapshot_on_delete"`
+	// where the instance snapshots are stored (e.g., a dedicated storage volume),
+	// empty means under the dir of template.
+	SnapshotRoot string `toml:"snapshot_root"`
+	// the extra dirs (besides data_root and snapshot_root) under which
+	// the destination dir of the Snapshot rpc (and the dirs of the
+	// CompactSnapshot rpc) can be.
+	SnapshotDestinationRoots []string `toml:"snapshot_destination_roots"`
+	// tuning of the http transport used to talk with envd (e.g., /sync),
+	// 0 means the default of net/http.
+	EnvdMaxIdleConnsPerHost int  `toml:"envd_max_idle_conns_per_host"`
+	EnvdIdleConnTimeoutMs   int  `toml:"envd_idle_conn_timeout_ms"`
+	EnvdDisableKeepAlives   bool `toml:"envd_disable_keep_alives"`
+	// where the templates are published in multi-host deployments, e.g.,
+	// https://example.com/templates or s3://bucket/templates. The templates are
+	// fetched into data_root when first used. Empty means only use the local ones.
+	TemplateSource string `toml:"template_source"`
+	// the max number of sandboxes created concurrently in a CreateBatch request
+	CreateBatchConcurrency int `toml:"create_batch_concurrency"`
+	// the max number of sandboxes being created (including the ones of CreateBatch
+	// and MigrateReceive) at the same time on this host, 0 means unlimited. The
+	// ones beyond it wait for a slot until the request is canceled, or fail with
+	// ResourceExhausted immediately when reject_creates_over_limit.
+	MaxConcurrentCreates   int  `toml:"max_concurrent_creates"`
+	RejectCreatesOverLimit bool `toml:"reject_creates_over_limit"`
+	// the number of copies of the writable rootfs kept for each template with
+	// overlay, which are made in background so that creating the sandbox does
+	// not wait for the copy (e.g., reflink is not supported), 0 means disabled.
+	OverlayPoolSize int `toml:"overlay_pool_size"`
+	// the number of network envs (without ipv6) created in background when
+	// orchestrator starts, so that the sandboxes created later do not wait for
+	// setting up the network, 0 means disabled. More can be created on demand
+	// by the WarmNetworks rpc.
+	NetworkPoolSize int `toml:"network_pool_size"`
+	// the max total size (in MiB) of extra disks of all sandboxes on this host
+	ExtraDiskQuotaMB int64 `toml:"extra_disk_quota_mb"`
+	// the dir of the files which can be used as the backing file of extra disks,
+	// empty means backing files are not allowed.
+	ExtraDiskBackingDir string `toml:"extra_disk_backing_dir"`
+	// verify the rootfs and kernel against the checksums recorded in template
+	// before booting each sandbox, the results are cached until the files change.
+	VerifyChecksums bool `toml:"verify_checksums"`
+	// the max retries of the restore request to vmm on transient errors (0 means
+	// the default, i.e., 3), and the timeout of the request including all
+	// retries (0 means no timeout).
+	RestoreRetries   int `toml:"restore_retries"`
+	RestoreTimeoutMs int `toml:"restore_timeout_ms"`
+	// the max attempts of creating the snapshot of sandbox, which is verified
+	// after created (0 means the default, i.e., 3).
+	SnapshotAttempts int `toml:"snapshot_attempts"`
+	// the max attempts of the /sync request to envd after the sandbox is
+	// started (or resumed), and the max duration of all attempts, 0 means
+	// the defaults (30 attempts and 60s). The clock is left unsynced after that.
+	ClockSyncMaxAttempts int `toml:"clock_sync_max_attempts"`
+	ClockSyncTimeoutMs   int `toml:"clock_sync_timeout_ms"`
+	// the max time to wait for the vmm stopped to be torn down (i.e., no process
+	// left in the netns) before its network is recycled, 0 means the default (10s).
+	// The network is not recycled if it is still used after that, which is
+	// reclaimed when the orchestrator restarts with force_reclaim_network.
+	NetworkReleaseTimeoutMs int `toml:"network_release_timeout_ms"`
+	// the max duration of the Create rpc (from loading the template to the
+	// sandbox running), 0 means no timeout. The partially created sandbox
+	// is cleaned up before DeadlineExceeded is returned.
+	CreateTimeoutMs int `toml:"create_timeout_ms"`
+	// the range of host ports allocated to the port forwards of sandboxes
+	// whose host port is not specified, 0 means the defaults (30000 and 32767).
+	PortForwardMinPort int `toml:"port_forward_min_port"`
+	PortForwardMaxPort int `toml:"port_forward_max_port"`
+	// keep the instance dir (and network) of the sandbox failed to create for
+	// post-mortem, instead of removing it. The instance dir is moved into
+	// ${data_root}/failed, which can be cleaned by PurgeFailed.
+	KeepFilesOnError bool `toml:"keep_files_on_error"`
+	// tee the output of vmm (e.g., the serial console of guest) into console.log
+	// under the instance dir by default, which can be overridden by each sandbox.
+	// The log is rotated once exceeding console_log_max_size_mb. When
+	// retain_console_log, it is moved into ${data_root}/console-logs after the
+	// sandbox is removed, instead of being removed along with the instance dir.
+	ConsoleLog          bool  `toml:"console_log"`
+	ConsoleLogMaxSizeMB int64 `toml:"console_log_max_size_mb"`
+	RetainConsoleLog    bool  `toml:"retain_console_log"`
+	// configure the logger (at fc_log_level, e.g., "Warning", empty means no
+	// logger) and the metrics of firecracker, which are written into fc.log and
+	// fc-metrics.json under the instance dir. When retain_fc_logs, they are moved
+	// into ${data_root}/fc-logs after the sandbox is removed, instead of being
+	// removed along with the instance dir.
+	FcLogLevel   string `toml:"fc_log_level"`
+	FcMetrics    bool   `toml:"fc_metrics"`
+	RetainFcLogs bool   `toml:"retain_fc_logs"`
+	// give each sandbox a distinct mac address derived from its network,
+	// instead of the one of template (which is shared by all its sandboxes).
+	// It is applied by envd after restored, which can be overridden by each sandbox.
+	UniqueGuestMAC bool `toml:"unique_guest_mac"`
+	// run the vmm as jailer_uid:jailer_gid (which should be able to access
+	// /dev/kvm, e.g., the kvm group) instead of root. Firecracker is run under
+	// its jailer (chroot in jailer_chroot_base_dir), while cloud hypervisor is
+	// run by setpriv. Both rely on the builtin seccomp filters of the hypervisor,
+	// so seccomp_profile is not allowed.
+	UseJailer        bool   `toml:"use_jailer"`
+	JailerBinaryPath string `toml:"jailer_binary_path"`
+	JailerUID        int    `toml:"jailer_uid"`
+	JailerGID        int    `toml:"jailer_gid"`
+	// it should be on the same filesystem as data_root (and snapshot_root),
+	// so that the snapshots are moved out of the chroot instead of copied.
+	JailerChrootBaseDir string `toml:"jailer_chroot_base_dir"`
+	// the template of the probe sandbox created by the SelfTest rpc when the
+	// request does not specify one, which should be small and quick to boot.
+	SelfTestTemplateID string `toml:"self_test_template"`
+	// check the snapshots of all templates against the cpu of this host on
+	// startup (reporting the incompatible ones), and reject creating sandboxes
+	// from a template whose snapshot is taken on an incompatible cpu, instead of
+	// failing in the vmm when restoring it.
+	CheckSnapshotCompatibility bool `toml:"check_snapshot_compatibility"`
 
-	DataRoot     string `toml:"-"`
-	FCBinaryPath string `toml:"-"`
-	CHBinaryPath string `toml:"-"`
+	DataRoot            string `toml:"-"`
+	FCBinaryPath        string `toml:"-"`
+	CHBinaryPath        string `toml:"-"`
+	ForceReclaimNetwork bool   `toml:"-"`
+	// how to wait for the api socket of vmm
+	SocketWait utils.SocketWaitOptions `toml:"-"`
+	// the free disk space (in bytes) kept when creating sandboxes,
+	// negative means the check is disabled.
+	DiskHeadroom int64 `toml:"-"`
+	// the mtu of the devices of sandbox networks
+	MTU int `toml:"-"`
+	// where the api sockets of vmm are
+	SocketDir string `toml:"-"`
 }
 
 func (cfg *OrchestratorConfig) Validate() error {
@@ -40,9 +195,129 @@ func (cfg *OrchestratorConfig) Validate() error {
 	if !fcExists && !chExists {
 		return fmt.Errorf("neither firecracker nor cloud-hypervisor binary found")
 	}
+	if cfg.SnapshotRoot != "" && !filepath.IsAbs(cfg.SnapshotRoot) {
+		return fmt.Errorf("snapshot_root %s should be an absolute path", cfg.SnapshotRoot)
+	}
+	for _, root := range cfg.SnapshotDestinationRoots {
+		if !filepath.IsAbs(root) {
+			return fmt.Errorf("snapshot_destination_roots %s should be an absolute path", root)
+		}
+	}
+	if cfg.IPv6Subnet.IPNet != nil {
+		ones, bits := cfg.IPv6Subnet.Mask.Size()
+		if cfg.IPv6Subnet.IP.To4() != nil || bits != 128 {
+			return fmt.Errorf("ipv6_subnet %s is not an ipv6 subnet", cfg.IPv6Subnet)
+		}
+		if !cfg.IPv6Subnet.IP.IsPrivate() {
+			return fmt.Errorf("ipv6_subnet %s is not a unique local address (fc00::/7)", cfg.IPv6Subnet)
+		}
+		// the addresses of sandbox network are allocated from the lower 64 bits
+		if ones > 64 {
+			return fmt.Errorf("prefix length of ipv6_subnet %s should be at most 64", cfg.IPv6Subnet)
+		}
+	}
+	if _, err := cfg.clonedIPStrategy(); err != nil {
+		return fmt.Errorf("cloned_ip_subnet: %w", err)
+	}
+	if cfg.FcLogLevel != "" {
+		if err := hypervisor.ValidateFcLogLevel(cfg.FcLogLevel); err != nil {
+			return fmt.Errorf("fc_log_level: %w", err)
+		}
+	}
+	if cfg.EnvdMaxIdleConnsPerHost < 0 || cfg.EnvdIdleConnTimeoutMs < 0 {
+		return fmt.Errorf("envd_max_idle_conns_per_host and envd_idle_conn_timeout_ms cannot be negative")
+	}
+	if _, err := parseTemplateSource(cfg.TemplateSource); err != nil {
+		return err
+	}
+	if cfg.RestoreRetries < 0 || cfg.RestoreTimeoutMs < 0 {
+		return fmt.Errorf("restore_retries and restore_timeout_ms cannot be negative")
+	}
+	if cfg.SnapshotAttempts < 0 {
+		return fmt.Errorf("snapshot_attempts cannot be negative")
+	}
+	if cfg.ClockSyncMaxAttempts < 0 || cfg.ClockSyncTimeoutMs < 0 {
+		return fmt.Errorf("clock_sync_max_attempts and clock_sync_timeout_ms cannot be negative")
+	}
+	if cfg.NetworkReleaseTimeoutMs < 0 {
+		return fmt.Errorf("network_release_timeout_ms cannot be negative")
+	}
+	if cfg.CreateTimeoutMs < 0 {
+		return fmt.Errorf("create_timeout_ms cannot be negative")
+	}
+	if cfg.PortForwardMinPort <= 0 || cfg.PortForwardMaxPort > math.MaxUint16 || cfg.PortForwardMinPort > cfg.PortForwardMaxPort {
+		return fmt.Errorf("port_forward_min_port and port_forward_max_port should be a valid port range, got [%d, %d]",
+			cfg.PortForwardMinPort, cfg.PortForwardMaxPort)
+	}
+	if err := network.ValidateMTU(cfg.MTU); err != nil {
+		return err
+	}
+	if err := utils.ValidateSocketDir(cfg.SocketDir, sandbox.SocketPrefix); err != nil {
+		return err
+	}
+	if cfg.CreateBatchConcurrency < 0 {
+		return fmt.Errorf("create_batch_concurrency cannot be negative")
+	}
+	if cfg.MaxConcurrentCreates < 0 {
+		return fmt.Errorf("max_concurrent_creates cannot be negative")
+	}
+	if cfg.OverlayPoolSize < 0 {
+		return fmt.Errorf("overlay_pool_size cannot be negative")
+	}
+	if err := network.ValidateHostChainPrefix(cfg.IptablesChainPrefix); err != nil {
+		return fmt.Errorf("iptables_chain_prefix: %w", err)
+	}
+	if cfg.NetworkPoolSize < 0 || cfg.NetworkPoolSize > constants.MaxNetworkNumber {
+		return fmt.Errorf("network_pool_size should be in [0, %d]", constants.MaxNetworkNumber)
+	}
+	if cfg.ExtraDiskQuotaMB < 0 {
+		return fmt.Errorf("extra_disk_quota_mb cannot be negative")
+	}
+	if cfg.ConsoleLogMaxSizeMB < 0 {
+		return fmt.Errorf("console_log_max_size_mb cannot be negative")
+	}
+	if cfg.UseJailer {
+		if cfg.JailerUID <= 0 || cfg.JailerGID <= 0 {
+			return fmt.Errorf("jailer_uid and jailer_gid should be positive when use_jailer")
+		}
+		if cfg.SeccompProfile != "" {
+			return fmt.Errorf("seccomp_profile cannot be used along with use_jailer")
+		}
+		if !filepath.IsAbs(cfg.JailerChrootBaseDir) {
+			return fmt.Errorf("jailer_chroot_base_dir %s should be an absolute path", cfg.JailerChrootBaseDir)
+		}
+	}
+	if cfg.ExtraDiskBackingDir != "" && !filepath.IsAbs(cfg.ExtraDiskBackingDir) {
+		return fmt.Errorf("extra_disk_backing_dir %s should be an absolute path", cfg.ExtraDiskBackingDir)
+	}
+	if cfg.SeccompProfile != "" {
+		info, err := os.Stat(cfg.SeccompProfile)
+		if err != nil {
+			return fmt.Errorf("stat seccomp_profile failed: %w", err)
+		}
+		// the profile is an array of struct sock_filter (8 bytes each)
+		if info.Size() == 0 || info.Size()%8 != 0 {
+			return fmt.Errorf("seccomp_profile %s is not a valid bpf program", cfg.SeccompProfile)
+		}
+	}
 	return nil
 }
 
+// The strategy deriving the host cloned ips, nil means the default one.
+func (cfg *OrchestratorConfig) clonedIPStrategy() (network.ClonedIPStrategy, error) {
+	if cfg.ClonedIPSubnet.IPNet == nil {
+		return nil, nil
+	}
+	strategy, err := network.NewSubnetClonedIPs(cfg.ClonedIPSubnet.IPNet)
+	if err != nil {
+		return nil, err
+	}
+	if err := network.ValidateClonedIPStrategy(strategy, constants.MaxNetworkNumber, cfg.Subnet.IPNet); err != nil {
+		return nil, err
+	}
+	return strategy, nil
+}
+
 func (cfg *OrchestratorConfig) setDefaultVal() {
 	if cfg.Port == 0 {
 		cfg.Port = consts.DefaultOrchestratorPort
@@ -56,47 +331,113 @@ func (cfg *OrchestratorConfig) setDefaultVal() {
 			Mask: net.CIDRMask(16, 32),
 		}
 	}
+	if cfg.PrometheusProxyAddr == "" {
+		cfg.PrometheusProxyAddr = constants.DefaultPrometheusProxyAddr
+	}
+	if cfg.PrometheusProbeTimeoutMs == 0 {
+		cfg.PrometheusProbeTimeoutMs = 2000
+	}
+	if cfg.EnvdIdleConnTimeoutMs == 0 {
+		cfg.EnvdIdleConnTimeoutMs = 90000
+	}
+	if cfg.PortForwardMinPort == 0 {
+		cfg.PortForwardMinPort = sandbox.DefaultPortForwardMinPort
+	}
+	if cfg.PortForwardMaxPort == 0 {
+		cfg.PortForwardMaxPort = sandbox.DefaultPortForwardMaxPort
+	}
+	if cfg.NetworkReleaseTimeoutMs == 0 {
+		cfg.NetworkReleaseTimeoutMs = 10000
+	}
+	if cfg.CreateBatchConcurrency == 0 {
+		cfg.CreateBatchConcurrency = 4
+	}
+	if cfg.ExtraDiskQuotaMB == 0 {
+		cfg.ExtraDiskQuotaMB = 20 << 10
+	}
+	if cfg.ConsoleLogMaxSizeMB == 0 {
+		cfg.ConsoleLogMaxSizeMB = 16
+	}
 	if cfg.CgroupName == "" {
 		cfg.CgroupName = consts.DefaultCgroupName
 	}
+	if cfg.CgroupfsPath == "" {
+		cfg.CgroupfsPath = consts.CgroupfsPath
+	}
+	if cfg.IptablesChainPrefix == "" {
+		cfg.IptablesChainPrefix = network.DefaultHostChainPrefix
+	}
 	if cfg.FCBinaryPath == "" {
 		cfg.FCBinaryPath = constants.FcBinaryName
 	}
 	if cfg.CHBinaryPath == "" {
 		cfg.CHBinaryPath = constants.ChBinaryName
 	}
+	if cfg.JailerBinaryPath == "" {
+		cfg.JailerBinaryPath = constants.JailerBinaryName
+	}
+	if cfg.JailerChrootBaseDir == "" {
+		cfg.JailerChrootBaseDir = constants.DefaultJailerChrootBaseDir
+	}
 }
 
-func createSandboxCgroup(path string) error {
-	if err := utils.CreateDirAllIfNotExists(path, 0o755); err != nil {
-		return err
+// nil if use_jailer is disabled.
+func (cfg *OrchestratorConfig) jailerOptions() *sandbox.JailerOptions {
+	if !cfg.UseJailer {
+		return nil
 	}
-	// enable all controllers in controllers into subtree_control
-	b, err := os.ReadFile(filepath.Join(path, "cgroup.controllers"))
-	if err != nil {
-		panic(fmt.Errorf("read cgroup.controllers in %s failed: %w", path, err))
-	}
-	controllers := strings.Fields(string(b))
-	for idx, c := range controllers {
-		controllers[idx] = "+" + c
+	return &sandbox.JailerOptions{
+		BinaryPath:    cfg.JailerBinaryPath,
+		UID:           cfg.JailerUID,
+		GID:           cfg.JailerGID,
+		ChrootBaseDir: cfg.JailerChrootBaseDir,
 	}
-	f, err := os.OpenFile(filepath.Join(path, "cgroup.subtree_control"), os.O_WRONLY, 0)
-	if err != nil {
-		return fmt.Errorf("open cgroup.subtree_control in %s failed: %w", path, err)
+}
+
+func (cfg *OrchestratorConfig) envdClientConfig() sandbox.EnvdClientConfig {
+	return sandbox.EnvdClientConfig{
+		MaxIdleConnsPerHost: cfg.EnvdMaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(cfg.EnvdIdleConnTimeoutMs) * time.Millisecond,
+		DisableKeepAlives:   cfg.EnvdDisableKeepAlives,
 	}
-	defer f.Close()
-	enableRequest := strings.Join(controllers, " ")
-	if _, err := f.WriteString(enableRequest); err != nil {
-		return fmt.Errorf("write %s to cgroup.subtree_control in %s failed: %w", enableRequest, path, err)
+}
+
+func createSandboxCgroup(path string) error {
+	if err := utils.CreateDirAllIfNotExists(path, 0o755); err != nil {
+		return err
 	}
-	return nil
+	return sandbox.EnableCgroupControllers(path)
+}
+
+// The parent cgroup of all sandboxes.
+func (cfg *OrchestratorConfig) cgroupParentPath() string {
+	return filepath.Join(cfg.CgroupfsPath, cfg.CgroupName)
 }
 
-func (cfg *OrchestratorConfig) initialize() error {
-	path := filepath.Join(consts.CgroupfsPath, cfg.CgroupName)
-	if err := createSandboxCgroup(path); err != nil {
+func (cfg *OrchestratorConfig) initialize(logger *zap.Logger) error {
+	// check before creating the cgroup, otherwise it fails on v1 hosts
+	// with some confusing error (e.g., no cgroup.controllers)
+	if err := utils.CheckCgroupV2(cfg.CgroupfsPath); err != nil {
+		return fmt.Errorf("orchestrator cannot use cgroupfs_path %s: %w", cfg.CgroupfsPath, err)
+	}
+	if err := createSandboxCgroup(cfg.cgroupParentPath()); err != nil {
 		return err
 	}
+	// Validate only requires one of the vmm, the sandboxes of the other
+	// vmm type will be rejected when creating (see checkHypervisor).
+	for _, vmm := range []struct {
+		vmmType config.VMMType
+		path    string
+	}{
+		{config.FIRECRACKER, cfg.FCBinaryPath},
+		{config.CLOUDHYPERVISOR, cfg.CHBinaryPath},
+	} {
+		if _, err := exec.LookPath(vmm.path); err != nil {
+			logger.Warn("vmm is unavailable", zap.String("vmm_type", string(vmm.vmmType)), zap.String("binary", vmm.path))
+		} else {
+			logger.Info("vmm is available", zap.String("vmm_type", string(vmm.vmmType)), zap.String("binary", vmm.path))
+		}
+	}
 	return nil
 }
 
@@ -130,10 +471,49 @@ func ParseConfig(configFile string) (*OrchestratorConfig, error) {
 	cfg.DataRoot = globalConfig.CommonConfig.DataRoot
 	cfg.FCBinaryPath = globalConfig.CommonConfig.FCBinaryPath
 	cfg.CHBinaryPath = globalConfig.CommonConfig.CHBinaryPath
+	cfg.ForceReclaimNetwork = globalConfig.CommonConfig.ForceReclaim()
+	cfg.SocketWait = globalConfig.CommonConfig.SocketWaitOptions()
+	cfg.DiskHeadroom = globalConfig.CommonConfig.DiskHeadroom()
+	cfg.MTU = globalConfig.CommonConfig.MTU
+	cfg.SocketDir = globalConfig.CommonConfig.VMMSocketDir()
 
 	cfg.setDefaultVal()
+	if cfg.MTU == 0 {
+		if cfg.MTU, err = network.HostDefaultMTU(); err != nil {
+			return nil, err
+		}
+	}
 	if err = cfg.Validate(); err != nil {
 		return nil, err
 	}
 	return &cfg, nil
 }
+
+func (cfg *OrchestratorConfig) restoreOptions() hypervisor.RestoreOptions {
+	return hypervisor.RestoreOptions{
+		Retries: cfg.RestoreRetries,
+		Timeout: time.Duration(cfg.RestoreTimeoutMs) * time.Millisecond,
+	}
+}
+
+func (cfg *OrchestratorConfig) clockSyncOptions() sandbox.ClockSyncOptions {
+	return sandbox.ClockSyncOptions{
+		MaxAttempts: cfg.ClockSyncMaxAttempts,
+		Timeout:     time.Duration(cfg.ClockSyncTimeoutMs) * time.Millisecond,
+	}
+}
+
+func (cfg *OrchestratorConfig) networkReleaseTimeout() time.Duration {
+	return time.Duration(cfg.NetworkReleaseTimeoutMs) * time.Millisecond
+}
+
+func (cfg *OrchestratorConfig) createTimeout() time.Duration {
+	return time.Duration(cfg.CreateTimeoutMs) * time.Millisecond
+}
+
+func (cfg *OrchestratorConfig) portForwardRange() sandbox.PortRange {
+	return sandbox.PortRange{
+		Min: uint16(cfg.PortForwardMinPort),
+		Max: uint16(cfg.PortForwardMaxPort),
+	}
+}
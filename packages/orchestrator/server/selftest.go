@@ -0,0 +1,126 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// The phases of SelfTest, in the order they are run.
+const (
+	selfTestPhaseCreate    = "create"
+	selfTestPhaseSyncClock = "sync_clock"
+	selfTestPhaseCommand   = "command"
+	selfTestPhaseDelete    = "delete"
+)
+
+// The metadata set on the probe sandbox, so that it can be told apart
+// from the others (e.g., by List) while the self test is running.
+const selfTestMetadataKey = "self-test"
+
+var errNoSelfTestTemplate = status.New(
+	codes.FailedPrecondition,
+	"template of probe sandbox is not specified (neither in request nor self_test_template)",
+).Err()
+
+// SelfTest creates a probe sandbox by the same path as Create, waits for
+// its clock synced, optionally runs a command inside it, and deletes it by
+// the same path as Delete. The failure of any phase is reported in the
+// response (i.e., passed is false) instead of as the error of rpc.
+func (s *server) SelfTest(ctx context.Context, req *orchestrator.HostManageSelfTestRequest) (*orchestrator.HostManageSelfTestResponse, error) {
+	templateID := req.GetTemplateID()
+	if templateID == "" {
+		templateID = s.cfg.SelfTestTemplateID
+	}
+	if templateID == "" {
+		return nil, errNoSelfTestTemplate
+	}
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-self-test", trace.WithAttributes(
+		attribute.String("env.id", templateID),
+	))
+	defer childSpan.End()
+
+	resp := &orchestrator.HostManageSelfTestResponse{}
+	runPhase := func(name string, fn func() error) bool {
+		start := time.Now()
+		err := fn()
+		phase := &orchestrator.SelfTestPhase{
+			Name:       name,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			// the message of status, without the code
+			phase.Error = status.Convert(err).Message()
+			telemetry.ReportError(childCtx, fmt.Errorf("self test phase %s failed: %w", name, err))
+		}
+		resp.Phases = append(resp.Phases, phase)
+		return err == nil
+	}
+
+	passed := runPhase(selfTestPhaseCreate, func() error {
+		createResp, err := s.Create(childCtx, &orchestrator.SandboxCreateRequest{
+			TemplateID:        templateID,
+			MaxInstanceLength: 1,
+			Metadata:          map[string]string{selfTestMetadataKey: "true"},
+		})
+		if err != nil {
+			return err
+		}
+		resp.SandboxID = createResp.GetInfo().GetSandboxID()
+		childSpan.SetAttributes(attribute.String("sandbox.id", resp.SandboxID))
+		return nil
+	})
+	if resp.SandboxID == "" {
+		return resp, nil
+	}
+
+	if passed {
+		passed = runPhase(selfTestPhaseSyncClock, func() error {
+			sbx, ok := s.GetSandbox(resp.SandboxID)
+			if !ok {
+				return fmt.Errorf("sandbox %s exited after created", resp.SandboxID)
+			}
+			return sbx.EnsureClockSync(childCtx)
+		})
+	}
+	if passed && req.GetCommand() != "" {
+		passed = runPhase(selfTestPhaseCommand, func() error {
+			sbx, ok := s.GetSandbox(resp.SandboxID)
+			if !ok {
+				return fmt.Errorf("sandbox %s exited before running command", resp.SandboxID)
+			}
+			result, err := sbx.RunCommand(childCtx, req.GetCommand())
+			if err != nil {
+				return err
+			}
+			resp.ExitCode = int32(result.ExitCode)
+			resp.Stdout = result.Stdout
+			resp.Stderr = result.Stderr
+			if result.ExitCode != 0 {
+				return fmt.Errorf("command exited with code %d", result.ExitCode)
+			}
+			return nil
+		})
+	}
+
+	// always delete the probe sandbox, even if ctx is canceled
+	deleted := runPhase(selfTestPhaseDelete, func() error {
+		_, err := s.Delete(context.WithoutCancel(childCtx), &orchestrator.SandboxDeleteRequest{
+			SandboxID: resp.SandboxID,
+		})
+		return err
+	})
+	resp.Passed = passed && deleted
+	if resp.Passed {
+		telemetry.ReportEvent(childCtx, "self test passed")
+	}
+	return resp, nil
+}
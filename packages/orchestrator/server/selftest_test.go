@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestSelfTestNoTemplate(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	_, err := s.SelfTest(context.Background(), &orchestrator.HostManageSelfTestRequest{})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expect FailedPrecondition without template, got %v", err)
+	}
+}
+
+func TestSelfTestCreateFailed(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	s.cfg.SelfTestTemplateID = "probe"
+	s.draining.Store(true)
+
+	resp, err := s.SelfTest(context.Background(), &orchestrator.HostManageSelfTestRequest{Command: "true"})
+	if err != nil {
+		t.Fatalf("the failure of phase should not be the error of rpc, got %v", err)
+	}
+	if resp.Passed || resp.SandboxID != "" {
+		t.Fatalf("expect failed without probe sandbox, got %v", resp)
+	}
+	// nothing to delete since the probe sandbox is not created
+	if len(resp.Phases) != 1 || resp.Phases[0].Name != selfTestPhaseCreate || resp.Phases[0].Error == "" {
+		t.Fatalf("expect only the failed create phase, got %v", resp.Phases)
+	}
+}
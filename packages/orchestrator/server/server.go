@@ -4,12 +4,18 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"net/http"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/constants"
 	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
@@ -23,8 +29,13 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
+// The prefix of the sandbox ids generated for the Create requests without one.
+const generatedSandboxIDPrefix = "sbx"
+
 // server manages sandboxes as provides grpc implmentations
 //
 // As one machine contains at most thousand of sandboxes,
@@ -33,12 +44,41 @@ import (
 type server struct {
 	orchestrator.UnsafeSandboxServer
 	orchestrator.UnsafeHostManageServer
-	mu         sync.Mutex
-	sandboxes  map[string]*sandbox.Sandbox
-	netManager *sandbox.NetworkManager
-	tracer     trace.Tracer
-	metric     *serverMetric
-	cfg        *OrchestratorConfig
+	mu        sync.Mutex
+	sandboxes map[string]*sandbox.Sandbox
+	// the ids of sandboxes being created, protected by mu
+	creating map[string]struct{}
+	// the counter of the generated sandbox ids, protected by mu
+	sandboxIDSeq uint64
+	netManager   *sandbox.NetworkManager
+	tracer       trace.Tracer
+	metric       *serverMetric
+	cfg          *OrchestratorConfig
+	// shared by all sandboxes to talk with envd
+	envdClient *http.Client
+	templates  *templateSource
+	// the result of ListTemplates
+	templateList templateListCache
+	// the subscribers of WatchEvents
+	events eventHub
+	// bound the sandboxes being created, nil means unlimited
+	createLimiter *createLimiter
+	// nil means the writable rootfs is copied on demand
+	overlayPool *sandbox.OverlayPool
+	// the size (in MiB) of extra disks reserved by the creating sandboxes
+	diskQuotaMu   sync.Mutex
+	pendingDiskMB int64
+	// standard grpc health checking service
+	health     *health.Server
+	healthStop chan struct{}
+	// cancel the warmup of network pool and wait for it to exit
+	warmCancel context.CancelFunc
+	warmDone   chan struct{}
+	// reject the new sandboxes (see Drain)
+	draining atomic.Bool
+	// the cpu of this host to check the snapshots of templates against,
+	// nil means check_snapshot_compatibility is disabled
+	hostCPU *config.HostCPU
 }
 
 // the second returned value is a cleanup function
@@ -52,10 +92,14 @@ func NewSandboxGrpcServer(logger *zap.Logger, cfg *OrchestratorConfig) (*grpc.Se
 			grpc_zap.UnaryServerInterceptor(logger),
 			recovery.UnaryServerInterceptor(),
 		),
+		grpc.ChainStreamInterceptor(
+			grpc_zap.StreamServerInterceptor(logger),
+			recovery.StreamServerInterceptor(),
+		),
 	)
 
 	logger.Info("Initializing orchestrator server")
-	if err := cfg.initialize(); err != nil {
+	if err := cfg.initialize(logger); err != nil {
 		return nil, nil, fmt.Errorf("initialize orchestrator config failed: %w", err)
 	}
 
@@ -64,6 +108,11 @@ func NewSandboxGrpcServer(logger *zap.Logger, cfg *OrchestratorConfig) (*grpc.Se
 		return nil, nil, fmt.Errorf("new dns failed: %w", err)
 	}
 
+	templates, err := newTemplateSource(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("new template source failed: %w", err)
+	}
+
 	metric, err := newServerMetric()
 	if err != nil {
 		return nil, nil, fmt.Errorf("new server metric failed: %w", err)
@@ -71,17 +120,79 @@ func NewSandboxGrpcServer(logger *zap.Logger, cfg *OrchestratorConfig) (*grpc.Se
 
 	s := server{
 		sandboxes:  make(map[string]*sandbox.Sandbox),
-		netManager: sandbox.NewNetworkManager(dns, cfg.Subnet.IPNet),
+		creating:   make(map[string]struct{}),
+		netManager: sandbox.NewNetworkManager(dns, cfg.Subnet.IPNet, cfg.IPv6Subnet.IPNet),
 		tracer:     otel.Tracer(constants.ServiceName),
 		metric:     metric,
 		cfg:        cfg,
+		envdClient: sandbox.NewEnvdClient(cfg.envdClientConfig()),
+		templates:  templates,
+		createLimiter: newCreateLimiter(
+			cfg.MaxConcurrentCreates,
+			cfg.RejectCreatesOverLimit,
+			metric.creating,
+		),
+		health:     health.NewServer(),
+		healthStop: make(chan struct{}),
+	}
+
+	if cfg.OverlayPoolSize > 0 {
+		s.overlayPool = sandbox.NewOverlayPool(cfg.OverlayPoolSize, logger)
 	}
 
+	s.netManager.ForceReclaim = cfg.ForceReclaimNetwork
+	s.netManager.MTU = cfg.MTU
+	s.netManager.PortRange = cfg.portForwardRange()
+	s.netManager.PoolSize = cfg.NetworkPoolSize
+	s.netManager.HostChainPrefix = cfg.IptablesChainPrefix
+	// validated along with the config
+	if s.netManager.ClonedIPs, err = cfg.clonedIPStrategy(); err != nil {
+		return nil, nil, err
+	}
+	if cfg.UseJailer {
+		s.netManager.TapUID, s.netManager.TapGID = cfg.JailerUID, cfg.JailerGID
+	}
+
+	// before reattaching, the rules of the reattached sandboxes are kept
+	if err := network.SetupHostChains(cfg.IptablesChainPrefix, cfg.IPv6Subnet.IPNet != nil); err != nil {
+		return nil, nil, fmt.Errorf("setup iptables chains failed: %w", err)
+	}
+	if cfg.CheckSnapshotCompatibility {
+		if s.hostCPU, err = config.ReadHostCPU(); err != nil {
+			return nil, nil, fmt.Errorf("read host cpu failed: %w", err)
+		}
+		s.reportIncompatibleTemplates(logger)
+	}
+	reattached := s.reattachSandboxes(context.Background())
+	logger.Info("Reattached sandboxes from previous orchestrator", zap.Int("count", reattached))
+	s.warmNetworkPool(logger)
+
 	orchestrator.RegisterSandboxServer(grpcSrv, &s)
 	orchestrator.RegisterHostManageServer(grpcSrv, &s)
+	healthpb.RegisterHealthServer(grpcSrv, s.health)
+	go s.watchHealth(s.healthStop)
 	return grpcSrv, func() { s.shutdown() }, nil
 }
 
+// Create the networks of network_pool_size in background, the sandboxes
+// can be created meanwhile.
+func (s *server) warmNetworkPool(logger *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.warmCancel, s.warmDone = cancel, make(chan struct{})
+	go func() {
+		defer close(s.warmDone)
+		if s.netManager.PoolSize == 0 {
+			return
+		}
+		created, warm, err := s.netManager.WarmNetworks(ctx, s.tracer, s.netManager.PoolSize, false)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("warm network pool failed", zap.Error(err), zap.Int("created", created))
+			return
+		}
+		logger.Info("Warmed network pool", zap.Int("created", created), zap.Int("already_warm", warm))
+	}()
+}
+
 // Returned bool indicate whether sandbox already exists before insert
 func (s *server) InsertSandbox(sbx *sandbox.Sandbox) bool {
 	s.mu.Lock()
@@ -92,6 +203,59 @@ func (s *server) InsertSandbox(sbx *sandbox.Sandbox) bool {
 	return ok
 }
 
+// Reserve the ids for the sandboxes to be created, so that the same id will not
+// be created concurrently. The returned function releases the reservation, which
+// should be called after the sandboxes are inserted (or failed to create).
+func (s *server) reserveSandboxIDs(sandboxIDs ...string) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, id := range sandboxIDs {
+		_, exists := s.sandboxes[id]
+		_, creating := s.creating[id]
+		if exists || creating || slices.Contains(sandboxIDs[:i], id) {
+			return nil, fmt.Errorf("%w: %s", ErrSandboxExists, id)
+		}
+	}
+	for _, id := range sandboxIDs {
+		s.creating[id] = struct{}{}
+	}
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, id := range sandboxIDs {
+			delete(s.creating, id)
+		}
+	}, nil
+}
+
+// Generate a sandbox id which is not used by any sandbox (including the ones
+// being created) and reserve it as reserveSandboxIDs.
+func (s *server) reserveNewSandboxID() (string, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		s.sandboxIDSeq++
+		id := newSandboxID(s.sandboxIDSeq)
+		_, exists := s.sandboxes[id]
+		_, creating := s.creating[id]
+		if exists || creating {
+			continue
+		}
+		s.creating[id] = struct{}{}
+		return id, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			delete(s.creating, id)
+		}
+	}
+}
+
+// The monotonic counter makes the ids unique in this orchestrator, while
+// the random suffix makes them unique across restarts (and hosts).
+func newSandboxID(seq uint64) string {
+	return fmt.Sprintf("%s-%s-%08x", generatedSandboxIDPrefix, strconv.FormatUint(seq, 36), rand.Uint32())
+}
+
 // Returned bool indicate whether find the sandbox
 func (s *server) GetSandbox(sandboxID string) (*sandbox.Sandbox, bool) {
 	s.mu.Lock()
@@ -109,9 +273,82 @@ func (s *server) DelSandbox(sandboxID string) bool {
 	return ok
 }
 
+// Rebuild the sandboxes from the state persisted by previous orchestrator
+// (see [sandbox.Sandbox.Persist]), instead of treating them as orphans.
+// The stale ones (i.e., whose vmm process has gone) will be cleaned up.
+//
+// Return the number of reattached sandboxes.
+func (s *server) reattachSandboxes(ctx context.Context) int {
+	childCtx, childSpan := s.tracer.Start(ctx, "reattach-sandboxes")
+	defer childSpan.End()
+
+	states, err := sandbox.ListPersistedSandboxes(s.cfg.DataRoot)
+	if err != nil {
+		// still reattach the sandboxes that are successfully decoded
+		errMsg := fmt.Errorf("list persisted sandboxes failed: %w", err)
+		telemetry.ReportError(childCtx, errMsg)
+	}
+	reattached := 0
+	for _, state := range states {
+		sbxCfg, err := s.NewSandboxConfig(childCtx, &orchestrator.SandboxCreateRequest{
+			TemplateID:           state.TemplateID,
+			SandboxID:            state.SandboxID,
+			MaxInstanceLength:    int64(state.MaxInstanceLength),
+			EnableDiffSnapshots:  state.EnableDiffSnapshot,
+			Metadata:             state.Metadata,
+			HypervisorBinaryPath: &state.HypervisorBinaryPath,
+			CgroupSubpath:        state.CgroupSubpath,
+		})
+		if err != nil {
+			// it can still be purged as orphan
+			errMsg := fmt.Errorf("rebuild config of persisted sandbox failed: %w", err)
+			telemetry.ReportError(childCtx, errMsg, attribute.String("sandbox.id", state.SandboxID))
+			continue
+		}
+		// the backing files may have gone, so do not validate again
+		sbxCfg.ExtraDisks = state.ExtraDisks
+		sbxCfg.PortForwards = state.PortForwards
+		sbxCfg.MemoryOverrideMB = state.MemoryOverrideMB
+		sbx, err := sandbox.ReattachSandbox(childCtx, s.tracer, sbxCfg, state, s.netManager)
+		if err != nil {
+			if errors.Is(err, sandbox.ErrSandboxProcessGone) {
+				telemetry.ReportEvent(childCtx, "found stale sandbox", attribute.String("sandbox.id", state.SandboxID))
+				s.cleanupStaleSandbox(childCtx, sbxCfg, state)
+			} else {
+				errMsg := fmt.Errorf("reattach sandbox failed: %w", err)
+				telemetry.ReportError(childCtx, errMsg, attribute.String("sandbox.id", state.SandboxID))
+			}
+			continue
+		}
+		go s.waitSandbox(sbx)
+		s.InsertSandbox(sbx)
+		s.metric.AddSandbox(childCtx, sbx)
+		telemetry.ReportEvent(childCtx, "reattached sandbox", attribute.String("sandbox.id", state.SandboxID))
+		reattached++
+	}
+	return reattached
+}
+
+func (s *server) cleanupStaleSandbox(ctx context.Context, sbxCfg *sandbox.SandboxConfig, state *sandbox.PersistedSandbox) {
+	if err := sandbox.CleanupStaleSandboxNetwork(ctx, state, s.netManager); err != nil {
+		errMsg := fmt.Errorf("cleanup network of stale sandbox failed: %w", err)
+		telemetry.ReportError(ctx, errMsg, attribute.String("sandbox.id", state.SandboxID))
+	}
+	// this also removes the persisted state
+	if err := sbxCfg.CleanupFiles(ctx, s.tracer, false); err != nil {
+		errMsg := fmt.Errorf("cleanup files of stale sandbox failed: %w", err)
+		telemetry.ReportError(ctx, errMsg, attribute.String("sandbox.id", state.SandboxID))
+	}
+}
+
 func (s *server) shutdown() {
 	ctx, span := s.tracer.Start(context.Background(), "server-shutdown")
 	defer span.End()
+	s.health.Shutdown()
+	close(s.healthStop)
+	// the networks being created should be cleaned up below
+	s.warmCancel()
+	<-s.warmDone
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for _, sbx := range s.sandboxes {
@@ -126,6 +363,12 @@ func (s *server) shutdown() {
 	}
 
 	s.netManager.Cleanup(ctx)
+	if err := network.DeleteHostChains(s.cfg.IptablesChainPrefix, s.cfg.IPv6Subnet.IPNet != nil); err != nil {
+		telemetry.ReportError(ctx, fmt.Errorf("delete iptables chains failed: %w", err))
+	}
+	if s.overlayPool != nil {
+		s.overlayPool.Close()
+	}
 }
 
 var envIDRegex *regexp.Regexp = regexp.MustCompile(fmt.Sprintf(`/([\w-]+)/%s/`, sandbox.InstancesDirName))
@@ -146,10 +389,7 @@ func getOrphanProcess(sandboxID string) (*process.Process, error) {
 			// TODO(huang-jl): return error or just continue?
 			continue
 		}
-		if strings.HasPrefix(cmdline, "unshare") &&
-			strings.Contains(cmdline, "ip netns exec") &&
-			(strings.Contains(cmdline, constants.FcBinaryName) || strings.Contains(cmdline, constants.ChBinaryName)) &&
-			strings.Contains(cmdline, sandboxID) {
+		if isSandboxCmdline(cmdline) && strings.Contains(cmdline, sandboxID) {
 			if res != nil {
 				return nil, fmt.Errorf("find more than one process match sandbox id %s", sandboxID)
 			}
@@ -179,8 +419,9 @@ func parseEnvIdFromOrphanProcess(proc *process.Process) (string, error) {
 
 func (s *server) purgeOne(ctx context.Context, sandboxInfo *orchestrator.SandboxInfo) error {
 	var (
-		finalErr  error
-		sandboxID = sandboxInfo.SandboxID
+		finalErr      error
+		sandboxID     = sandboxInfo.SandboxID
+		cgroupSubpath string
 	)
 	// Similar to (*Sandbox).cleanupAfterFCStop()
 	// 1. kill process
@@ -200,6 +441,8 @@ func (s *server) purgeOne(ctx context.Context, sandboxInfo *orchestrator.Sandbox
 			return
 		}
 		telemetry.ReportEvent(ctx, "get env id of orphan process", attribute.String("sandbox-id", sandboxID))
+		// the process leaves its cgroup after killed
+		cgroupSubpath = sandbox.ProcessCgroupSubpath(int(proc.Pid), s.cfg.CgroupName, sandboxID)
 		if err = proc.Kill(); err != nil {
 			err = fmt.Errorf("error when killing sandbox process [pid: %d]: %w", proc.Pid, err)
 			telemetry.ReportError(ctx, err, attribute.String("sandbox.id", sandboxID))
@@ -214,7 +457,8 @@ func (s *server) purgeOne(ctx context.Context, sandboxInfo *orchestrator.Sandbox
 	err = func() error {
 		var finalErr error
 		// TODO: use a more resaonable way to get subnet info
-		netEnv := network.NewNetworkEnv(int(*sandboxInfo.NetworkIdx), s.netManager.VethSubnet)
+		// whether the network enabled ipv6 is unknown, try deleting the ipv6 ones as well
+		netEnv := s.netManager.NetworkEnv(int(*sandboxInfo.NetworkIdx), true)
 		sbxNetwork := network.NewSandboxNetwork(netEnv, sandboxID)
 		if err := sbxNetwork.DeleteNetns(); err != nil {
 			telemetry.ReportError(ctx, err)
@@ -248,9 +492,10 @@ func (s *server) purgeOne(ctx context.Context, sandboxInfo *orchestrator.Sandbox
 	// we only need EnvInstancePath, SocketPath, CgroupPath and PrometheusTargetPath
 	err = func() error {
 		env, err := s.NewSandboxConfig(ctx, &orchestrator.SandboxCreateRequest{
-			// only this two field is enough to purge
-			SandboxID:  sandboxID,
-			TemplateID: envID,
+			// only these fields are enough to purge
+			SandboxID:     sandboxID,
+			TemplateID:    envID,
+			CgroupSubpath: cgroupSubpath,
 		})
 		if err != nil {
 			return fmt.Errorf("new sandbox failed: %w", err)
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var ErrInvalidSnapshotDir = errors.New("invalid snapshot dir")
+
+// The dirs under which the destination dir of Snapshot (and the dirs of
+// CompactSnapshot) can be.
+func (cfg *OrchestratorConfig) snapshotDestinationRoots() []string {
+	roots := []string{cfg.DataRoot}
+	if cfg.SnapshotRoot != "" {
+		roots = append(roots, cfg.SnapshotRoot)
+	}
+	return append(roots, cfg.SnapshotDestinationRoots...)
+}
+
+// Resolve the destination dir of snapshot, which (after following the symlinks
+// of its existing part) should be under one of roots, so that the callers
+// cannot write arbitrary locations of the host.
+func resolveSnapshotDir(dir string, roots []string) (string, error) {
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("%w: %s should be an absolute path", ErrInvalidSnapshotDir, dir)
+	}
+	if slices.Contains(strings.Split(filepath.ToSlash(dir), "/"), "..") {
+		return "", fmt.Errorf("%w: %s should not contain ..", ErrInvalidSnapshotDir, dir)
+	}
+	resolved, err := resolveExistingPrefix(filepath.Clean(dir))
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidSnapshotDir, err)
+	}
+	for _, root := range roots {
+		resolvedRoot, err := filepath.EvalSymlinks(root)
+		if err != nil {
+			// the root does not exist (yet), so nothing can be under it
+			continue
+		}
+		if resolved != resolvedRoot && isUnderDir(resolved, resolvedRoot) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s is not under %s", ErrInvalidSnapshotDir, dir, strings.Join(roots, ", "))
+}
+
+// Follow the symlinks of the longest existing prefix of path,
+// the rest of path (to be created) is joined as is.
+func resolveExistingPrefix(path string) (string, error) {
+	existing, rest := path, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		rest = filepath.Join(filepath.Base(existing), rest)
+		existing = filepath.Dir(existing)
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(resolved, rest), nil
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// Fail with config.ErrSnapshotIncompatible if the snapshot of template of
+// sbxCfg cannot be restored on this host. It is only checked when
+// check_snapshot_compatibility is enabled (i.e., hostCPU is read on startup).
+//
+// The template is already loaded when creating, so the result is computed
+// from it directly rather than cached, which never goes stale when the
+// template is rebuilt.
+func (s *server) checkSnapshotCompatibility(sbxCfg *sandbox.SandboxConfig) error {
+	if s.hostCPU == nil || sbxCfg.ColdBoot {
+		return nil
+	}
+	if err := sbxCfg.VMTemplate.CheckSnapshotCompatibility(s.hostCPU); err != nil {
+		return fmt.Errorf("template %s: %w", sbxCfg.TemplateID, err)
+	}
+	return nil
+}
+
+// Report the templates whose snapshot cannot be restored on this host, which
+// is run once on startup when check_snapshot_compatibility is enabled.
+func (s *server) reportIncompatibleTemplates(logger *zap.Logger) {
+	templates, err := s.scanTemplates(context.Background())
+	if err != nil {
+		logger.Error("scan templates for snapshot compatibility failed", zap.Error(err))
+		return
+	}
+	for _, info := range templates {
+		compat := snapshotCompatibility(s.cfg.DataRoot, info.TemplateID, s.hostCPU)
+		if !compat.Compatible {
+			logger.Warn("template snapshot is incompatible with host cpu",
+				zap.String("template_id", info.TemplateID),
+				zap.Strings("problems", compat.Problems),
+			)
+		}
+	}
+}
+
+func snapshotCompatibility(dataRoot, templateID string, host *config.HostCPU) *orchestrator.SnapshotCompatibility {
+	compat := &orchestrator.SnapshotCompatibility{TemplateID: templateID}
+	t, err := loadTemplate(dataRoot, templateID)
+	if err != nil {
+		compat.Problems = []string{err.Error()}
+		return compat
+	}
+	if t.SnapshotCPU != nil {
+		compat.SnapshotCPU = t.SnapshotCPU.String()
+		compat.Problems = t.SnapshotIncompatibilities(host)
+	}
+	compat.Compatible = len(compat.Problems) == 0
+	return compat
+}
+
+func (s *server) CheckSnapshotCompatibility(ctx context.Context, req *orchestrator.HostManageCheckSnapshotCompatibilityRequest) (*orchestrator.HostManageCheckSnapshotCompatibilityResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-check-snapshot-compatibility")
+	defer childSpan.End()
+
+	host := s.hostCPU
+	if host == nil {
+		var err error
+		if host, err = config.ReadHostCPU(); err != nil {
+			errMsg := fmt.Errorf("read host cpu failed: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		}
+	}
+
+	templateIDs := req.GetTemplateIDs()
+	if len(templateIDs) == 0 {
+		templates, err := s.scanTemplates(childCtx)
+		if err != nil {
+			errMsg := fmt.Errorf("scan templates failed: %w", err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+			return nil, status.New(codes.Internal, errMsg.Error()).Err()
+		}
+		for _, info := range templates {
+			templateIDs = append(templateIDs, info.TemplateID)
+		}
+	}
+
+	resp := &orchestrator.HostManageCheckSnapshotCompatibilityResponse{HostCPU: host.String()}
+	incompatible := 0
+	for _, templateID := range templateIDs {
+		compat := snapshotCompatibility(s.cfg.DataRoot, templateID, host)
+		if !compat.Compatible {
+			incompatible++
+		}
+		resp.Templates = append(resp.Templates, compat)
+	}
+	telemetry.ReportEvent(childCtx, "checked snapshot compatibility",
+		attribute.Int("templates", len(resp.Templates)),
+		attribute.Int("incompatible", incompatible),
+	)
+	return resp, nil
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/orchestrator/sandbox"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+)
+
+func TestCheckSnapshotCompatibility(t *testing.T) {
+	dataRoot := t.TempDir()
+	host := &config.HostCPU{Vendor: "GenuineIntel", Flags: []string{"avx2", "fpu"}}
+
+	writeTestTemplate(t, dataRoot, newTestTemplate("unknown"))
+	same := newTestTemplate("same")
+	same.SnapshotCPU = &config.HostCPU{Vendor: "GenuineIntel", ModelName: "Xeon", Flags: []string{"fpu"}}
+	writeTestTemplate(t, dataRoot, same)
+	newer := newTestTemplate("newer")
+	newer.SnapshotCPU = &config.HostCPU{Vendor: "GenuineIntel", Flags: []string{"avx512f", "fpu"}}
+	writeTestTemplate(t, dataRoot, newer)
+
+	s := newTestServer(dataRoot)
+	s.hostCPU = host
+	resp, err := s.CheckSnapshotCompatibility(context.Background(), &orchestrator.HostManageCheckSnapshotCompatibilityRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]bool{"newer": false, "same": true, "unknown": true}
+	if len(resp.Templates) != len(expected) {
+		t.Fatalf("expect %d templates, got %v", len(expected), resp.Templates)
+	}
+	for _, compat := range resp.Templates {
+		if compat.Compatible != expected[compat.TemplateID] {
+			t.Fatalf("unexpected compatibility of %s: %v", compat.TemplateID, compat)
+		}
+	}
+
+	resp, err = s.CheckSnapshotCompatibility(context.Background(), &orchestrator.HostManageCheckSnapshotCompatibilityRequest{
+		TemplateIDs: []string{"same", "missing"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Templates) != 2 || resp.Templates[0].SnapshotCPU != "GenuineIntel Xeon" ||
+		resp.Templates[1].Compatible || len(resp.Templates[1].Problems) != 1 {
+		t.Fatalf("unexpected templates %v", resp.Templates)
+	}
+
+	// the create from the incompatible template fast fails
+	sbxCfg := &sandbox.SandboxConfig{VMTemplate: newer}
+	if err := s.checkSnapshotCompatibility(sbxCfg); !errors.Is(err, config.ErrSnapshotIncompatible) {
+		t.Fatalf("expect snapshot incompatible, got %v", err)
+	}
+	sbxCfg.ColdBoot = true
+	if err := s.checkSnapshotCompatibility(sbxCfg); err != nil {
+		t.Fatalf("cold boot does not restore the snapshot, got %v", err)
+	}
+	sbxCfg.ColdBoot = false
+	s.hostCPU = nil
+	if err := s.checkSnapshotCompatibility(sbxCfg); err != nil {
+		t.Fatalf("not checked when disabled, got %v", err)
+	}
+}
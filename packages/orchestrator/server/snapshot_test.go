@@ -0,0 +1,64 @@
+package server
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSnapshotDir(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "staging"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(root, "staging"), filepath.Join(outside, "link")); err != nil {
+		t.Fatal(err)
+	}
+	// dangling, the target would be created outside of root
+	if err := os.Symlink(filepath.Join(outside, "new"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		dir      string
+		expected string
+	}{
+		{dir: filepath.Join(root, "staging"), expected: filepath.Join(resolvedRoot, "staging")},
+		{dir: filepath.Join(root, "staging/a/b"), expected: filepath.Join(resolvedRoot, "staging/a/b")},
+		{dir: filepath.Join(root, "new/"), expected: filepath.Join(resolvedRoot, "new")},
+		// links to the dir under root
+		{dir: filepath.Join(outside, "link/a"), expected: filepath.Join(resolvedRoot, "staging/a")},
+	} {
+		dir, err := resolveSnapshotDir(tc.dir, []string{"/not-exist", root})
+		if err != nil || dir != tc.expected {
+			t.Fatalf("expect %s resolved to %s, got %s (err %v)", tc.dir, tc.expected, dir, err)
+		}
+	}
+
+	for _, dir := range []string{
+		"relative/dir",
+		root,
+		// not joined, as filepath.Join cleans the .. away
+		root + "/../etc",
+		root + "/staging/../new",
+		filepath.Join(outside, "a"),
+		// escapes root by symlink
+		filepath.Join(root, "escape"),
+		filepath.Join(root, "escape/a"),
+		filepath.Join(root, "dangling"),
+		filepath.Join(root, "dangling/a"),
+	} {
+		if _, err := resolveSnapshotDir(dir, []string{root}); !errors.Is(err, ErrInvalidSnapshotDir) {
+			t.Fatalf("expect %s rejected, got %v", dir, err)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// How long the result of ListTemplates is cached.
+const templateListTTL = 5 * time.Second
+
+type templateListCache struct {
+	mu        sync.Mutex
+	templates []*orchestrator.TemplateInfo
+	expireAt  time.Time
+}
+
+// Invalidate the cache, e.g., when a template is created by orchestrator.
+func (c *templateListCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.templates = nil
+	c.expireAt = time.Time{}
+}
+
+func (s *server) ListTemplates(ctx context.Context, _ *empty.Empty) (*orchestrator.HostManageListTemplatesResponse, error) {
+	childCtx, childSpan := s.tracer.Start(ctx, "grpc-list-templates")
+	defer childSpan.End()
+
+	s.templateList.mu.Lock()
+	defer s.templateList.mu.Unlock()
+	if time.Now().Before(s.templateList.expireAt) {
+		return &orchestrator.HostManageListTemplatesResponse{Templates: s.templateList.templates}, nil
+	}
+
+	templates, err := s.scanTemplates(childCtx)
+	if err != nil {
+		errMsg := fmt.Errorf("scan templates failed: %w", err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		return nil, status.New(codes.Internal, errMsg.Error()).Err()
+	}
+	s.templateList.templates = templates
+	s.templateList.expireAt = time.Now().Add(templateListTTL)
+	return &orchestrator.HostManageListTemplatesResponse{Templates: templates}, nil
+}
+
+// Scan the templates under DataRoot, the dirs without a valid template file
+// (e.g., being created) are skipped.
+func (s *server) scanTemplates(ctx context.Context) ([]*orchestrator.TemplateInfo, error) {
+	entries, err := os.ReadDir(filepath.Join(s.cfg.DataRoot, consts.TemplateDirName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var templates []*orchestrator.TemplateInfo
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		t, err := loadTemplate(s.cfg.DataRoot, entry.Name())
+		if errors.Is(err, ErrTemplateNotFound) {
+			continue
+		}
+		if err == nil {
+			err = t.Validate()
+		}
+		if err == nil {
+			err = t.ValidateMemfile(s.cfg.DataRoot)
+		}
+		if err != nil {
+			telemetry.ReportError(ctx, fmt.Errorf("skip invalid template %s: %w", entry.Name(), err))
+			continue
+		}
+
+		info := &orchestrator.TemplateInfo{
+			TemplateID:    entry.Name(),
+			VmmType:       string(t.VmmType),
+			VcpuCount:     t.VCpuCount,
+			MemoryMB:      t.MemoryMB,
+			DiskSizeMB:    t.DiskSizeMB,
+			KernelVersion: t.KernelVersion,
+		}
+		imgDir := t.TemplateImgDir(s.cfg.DataRoot)
+		for _, file := range t.ImageFiles() {
+			if _, err := os.Stat(filepath.Join(imgDir, file)); err != nil {
+				info.MissingFiles = append(info.MissingFiles, file)
+			}
+		}
+		info.ImagesPresent = len(info.MissingFiles) == 0
+		templates = append(templates, info)
+	}
+	slices.SortFunc(templates, func(a, b *orchestrator.TemplateInfo) int {
+		return strings.Compare(a.TemplateID, b.TemplateID)
+	})
+	return templates, nil
+}
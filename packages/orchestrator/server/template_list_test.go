@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/grpc/orchestrator"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Create a template under dataRoot with all of its image files except missing.
+func writeTestTemplate(t *testing.T, dataRoot string, tmpl config.VMTemplate, missing ...string) {
+	t.Helper()
+	if err := os.MkdirAll(tmpl.TemplateImgDir(dataRoot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpl.Dump(dataRoot); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range tmpl.ImageFiles() {
+		if slices.Contains(missing, file) {
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(tmpl.TemplateImgDir(dataRoot), file), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func newTestTemplate(id string) config.VMTemplate {
+	return config.VMTemplate{
+		TemplateID:    id,
+		VCpuCount:     2,
+		MemoryMB:      512,
+		DiskSizeMB:    1024,
+		KernelVersion: consts.DefaultKernelVersion,
+		VmmType:       config.FIRECRACKER,
+	}
+}
+
+func newTestServer(dataRoot string) *server {
+	return &server{
+		tracer:   noop.NewTracerProvider().Tracer("test"),
+		cfg:      &OrchestratorConfig{DataRoot: dataRoot},
+		creating: make(map[string]struct{}),
+	}
+}
+
+func TestScanTemplates(t *testing.T) {
+	dataRoot := t.TempDir()
+
+	writeTestTemplate(t, dataRoot, newTestTemplate("fc"))
+	writeTestTemplate(t, dataRoot, newTestTemplate("fc-missing"), consts.FcMemfileName)
+	compressed := newTestTemplate("fc-zstd")
+	compressed.CompressMemfile = true
+	writeTestTemplate(t, dataRoot, compressed)
+	ch := newTestTemplate("ch")
+	ch.VmmType = config.CLOUDHYPERVISOR
+	ch.Overlay = true
+	writeTestTemplate(t, dataRoot, ch, consts.WritableFsName)
+	// being created, i.e., the template file is not dumped yet
+	if err := os.MkdirAll(filepath.Join(dataRoot, consts.TemplateDirName, "building"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// invalid template
+	invalid := newTestTemplate("invalid")
+	invalid.VCpuCount = 0
+	writeTestTemplate(t, dataRoot, invalid)
+
+	s := newTestServer(dataRoot)
+	templates, err := s.scanTemplates(context.Background())
+	if err != nil {
+		t.Fatalf("scan templates failed: %s", err)
+	}
+
+	expected := []struct {
+		id      string
+		vmmType config.VMMType
+		missing []string
+	}{
+		{"ch", config.CLOUDHYPERVISOR, []string{consts.WritableFsName}},
+		{"fc", config.FIRECRACKER, nil},
+		{"fc-missing", config.FIRECRACKER, []string{consts.FcMemfileName}},
+		{"fc-zstd", config.FIRECRACKER, nil},
+	}
+	if len(templates) != len(expected) {
+		t.Fatalf("expect %d templates, got %v", len(expected), templates)
+	}
+	for i, e := range expected {
+		info := templates[i]
+		if info.TemplateID != e.id {
+			t.Fatalf("expect template %s at %d, got %s", e.id, i, info.TemplateID)
+		}
+		if info.VmmType != string(e.vmmType) {
+			t.Errorf("%s: expect vmm type %s, got %s", e.id, e.vmmType, info.VmmType)
+		}
+		if info.VcpuCount != 2 || info.MemoryMB != 512 || info.DiskSizeMB != 1024 {
+			t.Errorf("%s: unexpected resources %v", e.id, info)
+		}
+		if info.KernelVersion != consts.DefaultKernelVersion {
+			t.Errorf("%s: unexpected kernel version %s", e.id, info.KernelVersion)
+		}
+		if !slices.Equal(info.MissingFiles, e.missing) {
+			t.Errorf("%s: expect missing files %v, got %v", e.id, e.missing, info.MissingFiles)
+		}
+		if info.ImagesPresent != (len(e.missing) == 0) {
+			t.Errorf("%s: unexpected images present %v", e.id, info.ImagesPresent)
+		}
+	}
+}
+
+func TestScanTemplatesNoTemplateDir(t *testing.T) {
+	s := newTestServer(t.TempDir())
+	templates, err := s.scanTemplates(context.Background())
+	if err != nil {
+		t.Fatalf("scan templates failed: %s", err)
+	}
+	if len(templates) != 0 {
+		t.Fatalf("expect no templates, got %v", templates)
+	}
+}
+
+func TestListTemplatesCache(t *testing.T) {
+	dataRoot := t.TempDir()
+	writeTestTemplate(t, dataRoot, newTestTemplate("first"))
+	s := newTestServer(dataRoot)
+
+	list := func() []*orchestrator.TemplateInfo {
+		t.Helper()
+		resp, err := s.ListTemplates(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("list templates failed: %s", err)
+		}
+		return resp.Templates
+	}
+
+	if templates := list(); len(templates) != 1 {
+		t.Fatalf("expect 1 template, got %v", templates)
+	}
+
+	// served from the cache before expired
+	writeTestTemplate(t, dataRoot, newTestTemplate("second"))
+	if templates := list(); len(templates) != 1 {
+		t.Fatalf("expect the cached result, got %v", templates)
+	}
+
+	s.templateList.invalidate()
+	if templates := list(); len(templates) != 2 {
+		t.Fatalf("expect 2 templates after invalidate, got %v", templates)
+	}
+
+	// rescan after expired
+	writeTestTemplate(t, dataRoot, newTestTemplate("third"))
+	s.templateList.mu.Lock()
+	s.templateList.expireAt = s.templateList.expireAt.Add(-templateListTTL)
+	s.templateList.mu.Unlock()
+	if templates := list(); len(templates) != 3 {
+		t.Fatalf("expect 3 templates after expired, got %v", templates)
+	}
+}
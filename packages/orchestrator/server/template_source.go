@@ -0,0 +1,361 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+// The file published along with each remote template, in the format of
+// `sha256sum` (i.e., "$HEX  $PATH" per line), where $PATH is relative to
+// the template dir. It should contain template.toml and all the image files.
+const templateChecksumFileName = "checksums.sha256"
+
+// Parse the template_source, return nil url for the local filesystem.
+//
+// The s3 url (s3://bucket/prefix) is fetched through the virtual-hosted style
+// https endpoint, so only public readable buckets are supported.
+func parseTemplateSource(source string) (*url.URL, error) {
+	if source == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template_source %s: %w", source, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "s3":
+		if u.Host == "" {
+			return nil, fmt.Errorf("template_source %s does not specify the bucket", source)
+		}
+		u = &url.URL{
+			Scheme: "https",
+			Host:   u.Host + ".s3.amazonaws.com",
+			Path:   u.Path,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported scheme of template_source %s", source)
+	}
+	return u, nil
+}
+
+// Fetch the templates published at a remote url into the template dir
+// under data root (i.e., ${data_root}/templates/${template_id}) before boot.
+//
+// The remote template has the same layout as the local one, i.e.,
+// ${source}/${template_id}/template.toml and ${source}/${template_id}/image/*.
+// The template.toml (along with the checksums) is fetched when the template is
+// used at first, while the image files are fetched lazily, i.e., only the ones
+// needed by the template and missing locally are fetched before boot. The fetched
+// files are cached, and a template built locally (i.e., without the checksums) is
+// used directly.
+type templateSource struct {
+	base     *url.URL // nil means the local filesystem
+	dataRoot string
+	client   *http.Client
+
+	mu sync.Mutex
+	// serialize the fetching of the same template, removed once
+	// no one is fetching the template
+	locks map[string]*templateLock
+}
+
+type templateLock struct {
+	sync.Mutex
+	refs int
+}
+
+func newTemplateSource(cfg *OrchestratorConfig) (*templateSource, error) {
+	base, err := parseTemplateSource(cfg.TemplateSource)
+	if err != nil {
+		return nil, err
+	}
+	return &templateSource{
+		base:     base,
+		dataRoot: cfg.DataRoot,
+		// the image files may be large, so do not set timeout
+		client: &http.Client{},
+		locks:  make(map[string]*templateLock),
+	}, nil
+}
+
+func (ts *templateSource) lock(templateID string) func() {
+	ts.mu.Lock()
+	l, ok := ts.locks[templateID]
+	if !ok {
+		l = &templateLock{}
+		ts.locks[templateID] = l
+	}
+	l.refs++
+	ts.mu.Unlock()
+	l.Lock()
+	return func() {
+		l.Unlock()
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(ts.locks, templateID)
+		}
+	}
+}
+
+func (ts *templateSource) templateDir(templateID string) string {
+	return filepath.Join(ts.dataRoot, consts.TemplateDirName, templateID)
+}
+
+// Make sure the template and its image files exist locally, fetch them from the remote source if needed.
+func (ts *templateSource) Ensure(ctx context.Context, tracer trace.Tracer, templateID string) error {
+	if ts.base == nil {
+		return nil
+	}
+	if templateID == "" || templateID != filepath.Base(templateID) || templateID == ".." {
+		return fmt.Errorf("invalid template id %q", templateID)
+	}
+	if missing, err := ts.missingImageFiles(templateID); err == nil && len(missing) == 0 {
+		return nil
+	}
+
+	unlock := ts.lock(templateID)
+	defer unlock()
+
+	childCtx, childSpan := tracer.Start(ctx, "fetch-remote-template", trace.WithAttributes(
+		attribute.String("env.id", templateID),
+	))
+	defer childSpan.End()
+
+	if err := ts.fetch(childCtx, templateID); err != nil {
+		errMsg := fmt.Errorf("fetch template %s from %s failed: %w", templateID, ts.base, err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(childCtx, "remote template fetched")
+	return nil
+}
+
+// Return the image files (relative to the template dir) needed by the fetched template
+// but missing locally, nothing is needed for the template built locally (i.e., without
+// the checksums). Return ErrTemplateNotFound if the template has not been fetched.
+func (ts *templateSource) missingImageFiles(templateID string) ([]string, error) {
+	t, err := loadTemplate(ts.dataRoot, templateID)
+	if err != nil {
+		return nil, err
+	}
+	templateDir := ts.templateDir(templateID)
+	if _, err := os.Stat(filepath.Join(templateDir, templateChecksumFileName)); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var missing []string
+	for _, file := range t.ImageFiles() {
+		rel, err := filepath.Rel(t.TemplateDir(ts.dataRoot), filepath.Join(t.TemplateImgDir(ts.dataRoot), file))
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(filepath.Join(templateDir, rel)); err != nil {
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+			missing = append(missing, filepath.ToSlash(rel))
+		}
+	}
+	return missing, nil
+}
+
+func (ts *templateSource) templateURL(templateID, file string) string {
+	u := *ts.base
+	u.Path = path.Join(u.Path, templateID, file)
+	return u.String()
+}
+
+// Fetch template.toml if it does not exist, and then the missing image files.
+// Should be called with the lock of template held.
+func (ts *templateSource) fetch(ctx context.Context, templateID string) error {
+	templateDir := ts.templateDir(templateID)
+	missing, err := ts.missingImageFiles(templateID)
+	if errors.Is(err, ErrTemplateNotFound) {
+		if err := ts.fetchTemplateFile(ctx, templateID); err != nil {
+			return err
+		}
+		telemetry.ReportEvent(ctx, "remote template file fetched", attribute.String("file", consts.TemplateFileName))
+		missing, err = ts.missingImageFiles(templateID)
+	}
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	f, err := os.Open(filepath.Join(templateDir, templateChecksumFileName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	checksums, err := parseChecksums(f)
+	if err != nil {
+		return err
+	}
+	for _, file := range missing {
+		sum, ok := checksums[file]
+		if !ok {
+			return fmt.Errorf("%s is not listed in %s", file, templateChecksumFileName)
+		}
+		if err := ts.download(ctx, ts.templateURL(templateID, file), filepath.Join(templateDir, file), sum); err != nil {
+			return err
+		}
+		telemetry.ReportEvent(ctx, "remote template file fetched", attribute.String("file", file))
+	}
+	return nil
+}
+
+// Fetch the checksums and then template.toml, the checksums are kept locally
+// to verify the image files fetched later.
+func (ts *templateSource) fetchTemplateFile(ctx context.Context, templateID string) error {
+	templateDir := ts.templateDir(templateID)
+	checksums, err := ts.fetchChecksums(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if _, ok := checksums[consts.TemplateFileName]; !ok {
+		return fmt.Errorf("%s is not listed in %s", consts.TemplateFileName, templateChecksumFileName)
+	}
+	if err := os.MkdirAll(templateDir, 0o755); err != nil {
+		return err
+	}
+	// sorted, so the same checksums are always written in the same way
+	lines := make([]string, 0, len(checksums))
+	for file, sum := range checksums {
+		lines = append(lines, fmt.Sprintf("%s  %s\n", sum, file))
+	}
+	slices.Sort(lines)
+	checksumPath := filepath.Join(templateDir, templateChecksumFileName)
+	if err := os.WriteFile(checksumPath+".tmp", []byte(strings.Join(lines, "")), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(checksumPath+".tmp", checksumPath); err != nil {
+		return err
+	}
+	// template.toml is written at last, so that a template is treated as
+	// fetched only when its checksums have been written.
+	return ts.download(
+		ctx,
+		ts.templateURL(templateID, consts.TemplateFileName),
+		filepath.Join(templateDir, consts.TemplateFileName),
+		checksums[consts.TemplateFileName],
+	)
+}
+
+// Return the map from relative path to hex encoded sha256.
+func (ts *templateSource) fetchChecksums(ctx context.Context, templateID string) (map[string]string, error) {
+	body, err := ts.get(ctx, ts.templateURL(templateID, templateChecksumFileName))
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return parseChecksums(body)
+}
+
+// Parse the checksums in the format of `sha256sum`, return the map
+// from relative path to hex encoded sha256.
+func parseChecksums(r io.Reader) (map[string]string, error) {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid line in %s: %q", templateChecksumFileName, line)
+		}
+		// sha256sum marks the binary mode with a leading '*'
+		sum, file := fields[0], path.Clean(strings.TrimPrefix(fields[1], "*"))
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid sha256 of %s: %s", file, sum)
+		}
+		// do not write outside of the template dir
+		if path.IsAbs(file) || file == ".." || strings.HasPrefix(file, "../") {
+			return nil, fmt.Errorf("invalid path in %s: %s", templateChecksumFileName, file)
+		}
+		checksums[file] = strings.ToLower(sum)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s failed: %w", templateChecksumFileName, err)
+	}
+	return checksums, nil
+}
+
+func (ts *templateSource) get(ctx context.Context, url string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := ts.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, url)
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d from %s", response.StatusCode, url)
+	}
+	return response.Body, nil
+}
+
+// Download url into dst (through a temporary file), and verify its sha256.
+func (ts *templateSource) download(ctx context.Context, url, dst, sum string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	body, err := ts.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	tmpPath := dst + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), body); err != nil {
+		return fmt.Errorf("download %s failed: %w", url, err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != sum {
+		return fmt.Errorf("checksum mismatch of %s: expect %s, got %s", url, sum, actual)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, dst)
+}
@@ -0,0 +1,326 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Serve the files (relative to the source root) and record the requested paths.
+type fakeTemplateServer struct {
+	mu        sync.Mutex
+	files     map[string][]byte
+	requested []string
+}
+
+func (f *fakeTemplateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p := strings.TrimPrefix(r.URL.Path, "/")
+	f.requested = append(f.requested, p)
+	data, ok := f.files[p]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(data)
+}
+
+func (f *fakeTemplateServer) takeRequested() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	requested := f.requested
+	f.requested = nil
+	slices.Sort(requested)
+	return requested
+}
+
+func newTestTemplateSource(t *testing.T, srv *httptest.Server, dataRoot string) *templateSource {
+	t.Helper()
+	base, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &templateSource{
+		base:     base,
+		dataRoot: dataRoot,
+		client:   srv.Client(),
+		locks:    make(map[string]*templateLock),
+	}
+}
+
+func TestParseTemplateSource(t *testing.T) {
+	testCases := []struct {
+		source   string
+		expected string
+		invalid  bool
+	}{
+		{"", "", false},
+		{"https://example.com/templates", "https://example.com/templates", false},
+		{"http://127.0.0.1:8080", "http://127.0.0.1:8080", false},
+		{"s3://bucket/prefix", "https://bucket.s3.amazonaws.com/prefix", false},
+		{"s3:///prefix", "", true},
+		{"ftp://example.com", "", true},
+		{"/data/templates", "", true},
+	}
+	for _, tc := range testCases {
+		u, err := parseTemplateSource(tc.source)
+		if tc.invalid {
+			if err == nil {
+				t.Fatalf("expect error for %q, got %v", tc.source, u)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("parse %q failed: %s", tc.source, err)
+		}
+		got := ""
+		if u != nil {
+			got = u.String()
+		}
+		if got != tc.expected {
+			t.Fatalf("expect %q for %q, got %q", tc.expected, tc.source, got)
+		}
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sum := sha256Hex([]byte("test"))
+	testCases := []struct {
+		name     string
+		content  string
+		expected map[string]string
+		invalid  bool
+	}{
+		{"text mode", sum + "  template.toml\n\n" + sum + "  image/rootfs.ext4\n", map[string]string{
+			"template.toml":     sum,
+			"image/rootfs.ext4": sum,
+		}, false},
+		{"binary mode", sum + " *image/./memfile\n", map[string]string{"image/memfile": sum}, false},
+		{"uppercase", strings.ToUpper(sum) + "  template.toml\n", map[string]string{"template.toml": sum}, false},
+		{"bad hex", strings.Repeat("z", 64) + "  template.toml\n", nil, true},
+		{"short sum", sum[:32] + "  template.toml\n", nil, true},
+		{"wrong fields", sum + "  template.toml extra\n", nil, true},
+		{"parent dir", sum + "  ../x\n", nil, true},
+		{"escape through subdir", sum + "  image/../../x\n", nil, true},
+		{"absolute path", sum + "  /etc/passwd\n", nil, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			checksums, err := parseChecksums(strings.NewReader(tc.content))
+			if tc.invalid {
+				if err == nil {
+					t.Fatalf("expect error, got %v", checksums)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse checksums failed: %s", err)
+			}
+			if len(checksums) != len(tc.expected) {
+				t.Fatalf("expect %v, got %v", tc.expected, checksums)
+			}
+			for file, sum := range tc.expected {
+				if checksums[file] != sum {
+					t.Fatalf("expect %v, got %v", tc.expected, checksums)
+				}
+			}
+		})
+	}
+}
+
+func TestFetchChecksumsRejectTraversal(t *testing.T) {
+	fake := &fakeTemplateServer{files: map[string][]byte{
+		"evil/" + templateChecksumFileName: []byte(sha256Hex(nil) + "  ../../etc/cron.d/evil\n"),
+	}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	dataRoot := t.TempDir()
+	ts := newTestTemplateSource(t, srv, dataRoot)
+
+	if _, err := ts.fetchChecksums(context.Background(), "evil"); err == nil {
+		t.Fatal("expect the path traversal rejected")
+	}
+	if _, err := ts.fetchChecksums(context.Background(), "missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expect template not found, got %v", err)
+	}
+}
+
+func TestDownloadChecksumMismatch(t *testing.T) {
+	fake := &fakeTemplateServer{files: map[string][]byte{"file": []byte("content")}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	dir := t.TempDir()
+	ts := newTestTemplateSource(t, srv, dir)
+
+	dst := filepath.Join(dir, "sub", "file")
+	err := ts.download(context.Background(), srv.URL+"/file", dst, sha256Hex([]byte("other")))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expect checksum mismatch, got %v", err)
+	}
+	for _, p := range []string{dst, dst + ".tmp"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("%s should not exist, stat err: %v", p, err)
+		}
+	}
+
+	if err := ts.download(context.Background(), srv.URL+"/file", dst, sha256Hex([]byte("content"))); err != nil {
+		t.Fatalf("download failed: %s", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "content" {
+		t.Fatalf("unexpected downloaded file %q (err: %v)", data, err)
+	}
+}
+
+func TestTemplateSourceEnsure(t *testing.T) {
+	const templateID = "remote"
+	// build the remote template from a local one
+	remoteRoot := t.TempDir()
+	tmpl := newTestTemplate(templateID)
+	writeTestTemplate(t, remoteRoot, tmpl)
+	files := make(map[string][]byte)
+	var checksums bytes.Buffer
+	publish := func(rel string, data []byte) {
+		files[templateID+"/"+rel] = data
+		fmt.Fprintf(&checksums, "%s  %s\n", sha256Hex(data), rel)
+	}
+	toml, err := os.ReadFile(tmpl.TemplateFilePath(remoteRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publish(consts.TemplateFileName, toml)
+	for _, file := range tmpl.ImageFiles() {
+		publish("image/"+file, []byte("image "+file))
+	}
+	// published but not needed by the template
+	publish("image/"+consts.FcCompressedMemfileName, []byte("unused"))
+	files[templateID+"/"+templateChecksumFileName] = checksums.Bytes()
+
+	fake := &fakeTemplateServer{files: files}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	dataRoot := t.TempDir()
+	ts := newTestTemplateSource(t, srv, dataRoot)
+	tracer := noop.NewTracerProvider().Tracer("test")
+	ctx := context.Background()
+
+	if err := ts.Ensure(ctx, tracer, templateID); err != nil {
+		t.Fatalf("ensure template failed: %s", err)
+	}
+	expected := []string{templateID + "/" + templateChecksumFileName, templateID + "/" + consts.TemplateFileName}
+	for _, file := range tmpl.ImageFiles() {
+		expected = append(expected, templateID+"/image/"+file)
+	}
+	slices.Sort(expected)
+	if requested := fake.takeRequested(); !slices.Equal(requested, expected) {
+		t.Fatalf("expect only the needed files fetched %v, got %v", expected, requested)
+	}
+	for _, file := range tmpl.ImageFiles() {
+		data, err := os.ReadFile(filepath.Join(tmpl.TemplateImgDir(dataRoot), file))
+		if err != nil || string(data) != "image "+file {
+			t.Fatalf("unexpected image file %s: %q (err: %v)", file, data, err)
+		}
+	}
+	if len(ts.locks) != 0 {
+		t.Fatalf("expect the template locks pruned, got %v", ts.locks)
+	}
+
+	// cached
+	if err := ts.Ensure(ctx, tracer, templateID); err != nil {
+		t.Fatalf("ensure template again failed: %s", err)
+	}
+	if requested := fake.takeRequested(); len(requested) != 0 {
+		t.Fatalf("expect nothing fetched for the cached template, got %v", requested)
+	}
+
+	// only the missing image file is fetched
+	if err := os.Remove(filepath.Join(tmpl.TemplateImgDir(dataRoot), consts.FcMemfileName)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Ensure(ctx, tracer, templateID); err != nil {
+		t.Fatalf("ensure template with missing image failed: %s", err)
+	}
+	if requested := fake.takeRequested(); !slices.Equal(requested, []string{templateID + "/image/" + consts.FcMemfileName}) {
+		t.Fatalf("expect only the missing image fetched, got %v", requested)
+	}
+
+	// the template built locally is used directly
+	writeTestTemplate(t, dataRoot, newTestTemplate("local"), consts.FcMemfileName)
+	if err := ts.Ensure(ctx, tracer, "local"); err != nil {
+		t.Fatalf("ensure local template failed: %s", err)
+	}
+	if requested := fake.takeRequested(); len(requested) != 0 {
+		t.Fatalf("expect nothing fetched for the local template, got %v", requested)
+	}
+
+	if err := ts.Ensure(ctx, tracer, "missing"); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expect template not found, got %v", err)
+	}
+	if err := ts.Ensure(ctx, tracer, ".."); err == nil {
+		t.Fatal("expect invalid template id")
+	}
+}
+
+func TestTemplateSourceConcurrentEnsure(t *testing.T) {
+	const templateID = "remote"
+	tmpl := newTestTemplate(templateID)
+	remoteRoot := t.TempDir()
+	writeTestTemplate(t, remoteRoot, tmpl)
+	toml, err := os.ReadFile(tmpl.TemplateFilePath(remoteRoot))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{templateID + "/" + consts.TemplateFileName: toml}
+	checksums := sha256Hex(toml) + "  " + consts.TemplateFileName + "\n"
+	for _, file := range tmpl.ImageFiles() {
+		files[templateID+"/image/"+file] = nil
+		checksums += sha256Hex(nil) + "  image/" + file + "\n"
+	}
+	files[templateID+"/"+templateChecksumFileName] = []byte(checksums)
+
+	fake := &fakeTemplateServer{files: files}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	ts := newTestTemplateSource(t, srv, t.TempDir())
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = ts.Ensure(context.Background(), noop.NewTracerProvider().Tracer("test"), templateID)
+		}(i)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		t.Fatalf("concurrent ensure failed: %s", err)
+	}
+	// each file is fetched only once
+	if requested := fake.takeRequested(); len(requested) != len(files) {
+		t.Fatalf("expect %d files fetched, got %v", len(files), requested)
+	}
+	if len(ts.locks) != 0 {
+		t.Fatalf("expect the template locks pruned, got %v", ts.locks)
+	}
+}
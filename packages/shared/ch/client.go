@@ -5,10 +5,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"os"
-	"time"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -33,37 +32,23 @@ func NewCloudHypervisorAPI(socketPath string) (*ClientWithResponses, error) {
 	return NewClientWithResponses("http://localhost/api/v1", WithHTTPClient(&httpClient))
 }
 
+// Wait for cloud hypervisor socket to be prepared.
+// When opts.Probe is set, it also pings the vmm until succeed.
 func WaitForSocket(ctx context.Context,
 	tracer trace.Tracer,
 	socketPath string,
-	timeout time.Duration,
+	opts utils.SocketWaitOptions,
 ) (*ClientWithResponses, error) {
 	childCtx, childSpan := tracer.Start(ctx, "wait-for-ch-socket")
-	childCtx, cancel := context.WithTimeout(childCtx, timeout)
-
-	fileStateTicker := time.NewTicker(10 * time.Millisecond)
+	childCtx, cancel := context.WithTimeout(childCtx, opts.Timeout)
 	defer func() {
 		cancel()
-		fileStateTicker.Stop()
 		childSpan.End()
 	}()
 
-	retryTimes := 0
-checkSocketCreation:
-	for {
-		select {
-		case <-childCtx.Done():
-			return nil, childCtx.Err()
-		case <-fileStateTicker.C:
-			if _, err := os.Stat(socketPath); err != nil {
-				if os.IsNotExist(err) {
-					retryTimes += 1
-					continue
-				}
-				return nil, err
-			}
-			break checkSocketCreation
-		}
+	retryTimes, err := utils.WaitForSocketFile(childCtx, socketPath, opts)
+	if err != nil {
+		return nil, err
 	}
 	telemetry.ReportEvent(childCtx, "ch socket created", attribute.Int("retry_times", retryTimes))
 
@@ -71,40 +56,31 @@ checkSocketCreation:
 	if err != nil {
 		return nil, err
 	}
-	// TODO(huang-jl): use time.After when using golang 1.23 and above?
-	retryTimes = 0
-	interval := 50 * time.Millisecond
-	reqTimer := time.NewTimer(interval)
-	defer reqTimer.Stop()
-	for {
+	if !opts.Probe {
+		return chClient, nil
+	}
+	retryTimes, err = utils.PollWithBackoff(childCtx, opts, func() (bool, error) {
 		res, err := chClient.GetVmmPingWithResponse(childCtx)
 		if err != nil {
 			errMsg := fmt.Errorf("ch client ping error: err %v", err)
 			telemetry.ReportError(childCtx, errMsg)
-		} else {
-			// assert err == nil
-			if res.JSON200 != nil {
-				telemetry.ReportEvent(
-					childCtx,
-					"ch client ping vmm succeed",
-					attribute.String("ch_version", res.JSON200.Version),
-					attribute.Int("retry_times", retryTimes),
-				)
-				return chClient, nil
-			} else {
-				errMsg := fmt.Errorf("ch client ping error: err %v, status code = %d", err, res.StatusCode())
-				telemetry.ReportError(childCtx, errMsg)
-			}
+			return false, nil
 		}
-		reqTimer.Reset(interval)
-		select {
-		case <-childCtx.Done():
-			return nil, childCtx.Err()
-		case <-reqTimer.C:
-			if interval < time.Second {
-				interval *= 2
-			}
+		if res.JSON200 == nil {
+			errMsg := fmt.Errorf("ch client ping error: status code = %d", res.StatusCode())
+			telemetry.ReportError(childCtx, errMsg)
+			return false, nil
 		}
-		retryTimes += 1
+		telemetry.ReportEvent(
+			childCtx,
+			"ch client ping vmm succeed",
+			attribute.String("ch_version", res.JSON200.Version),
+		)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	telemetry.ReportEvent(childCtx, "ch api ready", attribute.Int("retry_times", retryTimes))
+	return chClient, nil
 }
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// The cpu architecture of the guest, named after GOARCH.
+type TargetArch string
+
+const (
+	ArchAMD64 TargetArch = "amd64"
+	ArchARM64 TargetArch = "arm64"
+)
+
+var (
+	InvalidTargetArch = errors.New("invalid target arch")
+	// The template cannot be booted on the host of another architecture.
+	ErrArchMismatch = errors.New("template arch mismatches host")
+)
+
+func (a *TargetArch) UnmarshalText(text []byte) error {
+	arch := TargetArch(text)
+	switch arch {
+	case "", ArchAMD64, ArchARM64:
+		*a = arch
+		return nil
+	default:
+		return fmt.Errorf("%w %s", InvalidTargetArch, text)
+	}
+}
+
+// The effective arch of the template, the templates built before
+// target_arch is introduced are amd64.
+func (t *VMTemplate) Arch() TargetArch {
+	if t.TargetArch == "" {
+		return ArchAMD64
+	}
+	return t.TargetArch
+}
+
+// The platform of the docker image the rootfs is built from.
+func (t *VMTemplate) DockerPlatform() string {
+	return "linux/" + string(t.Arch())
+}
+
+// Fail if the template cannot be booted on this host (i.e., the arch of the
+// running binary).
+func (t *VMTemplate) ValidateHostArch() error {
+	if host := TargetArch(runtime.GOARCH); t.Arch() != host {
+		return fmt.Errorf("%w: template is built for %s, but the host is %s", ErrArchMismatch, t.Arch(), host)
+	}
+	return nil
+}
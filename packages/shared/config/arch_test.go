@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestTargetArch(t *testing.T) {
+	var tmpl VMTemplate
+	if _, err := toml.Decode(`target_arch = "arm64"`, &tmpl); err != nil {
+		t.Fatalf("decode target_arch failed: %s", err)
+	}
+	if tmpl.Arch() != ArchARM64 || tmpl.DockerPlatform() != "linux/arm64" {
+		t.Fatalf("expect arm64, got %s (%s)", tmpl.Arch(), tmpl.DockerPlatform())
+	}
+	var arch TargetArch
+	if err := arch.UnmarshalText([]byte("riscv64")); !errors.Is(err, InvalidTargetArch) {
+		t.Fatalf("expect InvalidTargetArch, got %v", err)
+	}
+
+	tmpl = VMTemplate{KernelVersion: "vmlinux-6.1"}
+	if tmpl.Arch() != ArchAMD64 {
+		t.Fatalf("expect amd64 by default, got %s", tmpl.Arch())
+	}
+	if expect := filepath.Join("/data", "kernels", "vmlinux-6.1", "vmlinux"); tmpl.HostKernelPath("/data") != expect {
+		t.Fatalf("expect %s, got %s", expect, tmpl.HostKernelPath("/data"))
+	}
+	tmpl.TargetArch = ArchARM64
+	if expect := filepath.Join("/data", "kernels", "vmlinux-6.1", "arm64", "vmlinux"); tmpl.HostKernelPath("/data") != expect {
+		t.Fatalf("expect %s, got %s", expect, tmpl.HostKernelPath("/data"))
+	}
+}
+
+func TestValidateHostArch(t *testing.T) {
+	tmpl := VMTemplate{TargetArch: TargetArch(runtime.GOARCH)}
+	if err := tmpl.ValidateHostArch(); err != nil {
+		t.Fatalf("expect the host arch to match, got %s", err)
+	}
+	tmpl.TargetArch = ArchARM64
+	if runtime.GOARCH == "arm64" {
+		tmpl.TargetArch = ArchAMD64
+	}
+	if err := tmpl.ValidateHostArch(); !errors.Is(err, ErrArchMismatch) {
+		t.Fatalf("expect ErrArchMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+var ErrSnapshotIncompatible = errors.New("snapshot incompatible with host cpu")
+
+// The cpu of the host where the snapshot of template is taken. The vmm
+// refuses to restore the snapshot on a host whose cpu lacks the features
+// exposed to the guest (e.g., of another vendor or an older generation).
+type HostCPU struct {
+	Vendor    string `toml:"vendor"`
+	ModelName string `toml:"model_name"`
+	Microcode string `toml:"microcode,omitempty"`
+	// sorted
+	Flags []string `toml:"flags"`
+}
+
+func (c *HostCPU) String() string {
+	return strings.TrimSpace(c.Vendor + " " + c.ModelName)
+}
+
+// Read the cpu of this host from /proc/cpuinfo.
+func ReadHostCPU() (*HostCPU, error) {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseCPUInfo(f)
+}
+
+// Parse the first processor in the content of /proc/cpuinfo, the keys
+// differ between x86 (e.g., flags) and arm64 (e.g., Features).
+func parseCPUInfo(r io.Reader) (*HostCPU, error) {
+	var cpu HostCPU
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// the end of the first processor
+		if strings.TrimSpace(line) == "" && cpu.Flags != nil {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "vendor_id", "CPU implementer":
+			cpu.Vendor = value
+		case "model name", "CPU part":
+			cpu.ModelName = value
+		case "microcode", "CPU revision":
+			cpu.Microcode = value
+		case "flags", "Features":
+			cpu.Flags = strings.Fields(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read cpuinfo failed: %w", err)
+	}
+	if cpu.Flags == nil {
+		return nil, fmt.Errorf("no cpu flags found in cpuinfo")
+	}
+	slices.Sort(cpu.Flags)
+	cpu.Flags = slices.Compact(cpu.Flags)
+	return &cpu, nil
+}
+
+// The flags in /proc/cpuinfo never exposed to the guest by kvm: the ones
+// synthesized by the kernel (e.g., the mitigations and tsc properties), of
+// the hardware virtualization, and of the power or performance monitoring
+// of host. They differ between hosts (or kernels, e.g., hypervisor when the
+// host is a vm itself) able to restore the same snapshot, so not compared.
+var hostOnlyCPUFlags = map[string]struct{}{
+	// synthesized by the kernel
+	"aperfmperf": {}, "arch_perfmon": {}, "art": {}, "bts": {}, "constant_tsc": {},
+	"cpuid": {}, "cpuid_fault": {}, "eagerfpu": {}, "hypervisor": {}, "nonstop_tsc": {},
+	"nonstop_tsc_s3": {}, "nopl": {}, "pebs": {}, "rep_good": {}, "tsc_known_freq": {},
+	"tsc_reliable": {}, "up": {}, "xtopology": {}, "amd_dcm": {}, "acc_power": {},
+	// the mitigations, which depend on the microcode and kernel
+	"flush_l1d": {}, "ibpb": {}, "ibrs": {}, "ibrs_enhanced": {}, "invpcid_single": {},
+	"kaiser": {}, "md_clear": {}, "pti": {}, "retpoline": {}, "retpoline_amd": {},
+	"rsb_ctxsw": {}, "ssbd": {}, "stibp": {}, "tsx_ctrl": {}, "use_ibpb": {}, "use_ibrs_fw": {},
+	"virt_ssbd": {}, "l1tf_pteinv": {}, "arch_capabilities": {}, "zen": {},
+	// hardware virtualization
+	"vmx": {}, "svm": {}, "ept": {}, "ept_ad": {}, "vpid": {}, "flexpriority": {},
+	"tpr_shadow": {}, "vnmi": {}, "npt": {}, "lbrv": {}, "svm_lock": {}, "nrip_save": {},
+	"tsc_scale": {}, "vmcb_clean": {}, "flushbyasid": {}, "decodeassists": {},
+	"pausefilter": {}, "pfthreshold": {}, "avic": {}, "v_vmsave_vmload": {}, "vgif": {},
+	"v_spec_ctrl": {}, "smx": {},
+	// power management and monitoring of host
+	"acpi": {}, "cpb": {}, "dtes64": {}, "dtherm": {}, "ds_cpl": {}, "est": {}, "epb": {},
+	"hw_pstate": {}, "hwp": {}, "hwp_act_window": {}, "hwp_epp": {}, "hwp_notify": {},
+	"hwp_pkg_req": {}, "ibs": {}, "ida": {}, "intel_pt": {}, "pbe": {}, "pdcm": {},
+	"pln": {}, "proc_feedback": {}, "pts": {}, "tm": {}, "tm2": {}, "xtpr": {},
+	"cat_l2": {}, "cat_l3": {}, "cdp_l2": {}, "cdp_l3": {}, "cqm": {}, "cqm_llc": {},
+	"cqm_mbm_local": {}, "cqm_mbm_total": {}, "cqm_occup_llc": {}, "mba": {}, "rdt_a": {},
+}
+
+// Why the snapshot taken on c cannot be restored on host, empty means compatible.
+// A different microcode alone is not a problem, as the features are the same.
+// Only the features visible to the guest are compared (see hostOnlyCPUFlags).
+func (c *HostCPU) Incompatibilities(host *HostCPU) []string {
+	var problems []string
+	if c.Vendor != host.Vendor {
+		problems = append(problems, fmt.Sprintf("cpu vendor %q differs from %q of this host", c.Vendor, host.Vendor))
+	}
+	var missing []string
+	for _, flag := range c.Flags {
+		if _, hostOnly := hostOnlyCPUFlags[flag]; hostOnly {
+			continue
+		}
+		if _, found := slices.BinarySearch(host.Flags, flag); !found {
+			missing = append(missing, flag)
+		}
+	}
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("cpu features missing on this host: %s", strings.Join(missing, " ")))
+	}
+	return problems
+}
+
+// Fail with ErrSnapshotIncompatible if the snapshot of template cannot be
+// restored on host. The templates without SnapshotCPU (e.g., built before
+// it is recorded) are assumed to be compatible.
+func (t *VMTemplate) CheckSnapshotCompatibility(host *HostCPU) error {
+	if problems := t.SnapshotIncompatibilities(host); len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrSnapshotIncompatible, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// Why the snapshot of template cannot be restored on host, empty means compatible
+// (or unknown, i.e., SnapshotCPU is not recorded). With a cpu template, the guest
+// only sees the features of the template rather than the ones of SnapshotCPU,
+// so the features missing on host are not counted (firecracker still fails to
+// restore if host cannot provide the ones of the template).
+func (t *VMTemplate) SnapshotIncompatibilities(host *HostCPU) []string {
+	if t.SnapshotCPU == nil || host == nil {
+		return nil
+	}
+	snapshot := *t.SnapshotCPU
+	if t.HasCpuTemplate() {
+		snapshot.Flags = nil
+	}
+	return snapshot.Incompatibilities(host)
+}
+
+// The static cpu templates supported by firecracker on each architecture.
+var fcCpuTemplates = map[TargetArch][]string{
+	ArchAMD64: {"C3", "T2", "T2S", "T2CL", "T2A"},
+	ArchARM64: {"V1N1"},
+}
+
+// Whether a cpu template is applied, "None" is the same as not set.
+func (t *VMTemplate) HasCpuTemplate() bool {
+	return t.CpuTemplate != "" && t.CpuTemplate != "None"
+}
+
+func (t *VMTemplate) validateCpuTemplate() error {
+	if !t.HasCpuTemplate() {
+		return nil
+	}
+	if t.VmmType != FIRECRACKER {
+		return fmt.Errorf("%w: only supported by %s", InvalidCpuTemplate, FIRECRACKER)
+	}
+	if supported := fcCpuTemplates[t.Arch()]; !slices.Contains(supported, t.CpuTemplate) {
+		return fmt.Errorf("%w: %q should be one of %v on %s", InvalidCpuTemplate, t.CpuTemplate, supported, t.Arch())
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const x86CPUInfo = `processor	: 0
+vendor_id	: GenuineIntel
+model name	: Intel(R) Xeon(R) Gold 6330 CPU @ 2.00GHz
+microcode	: 0xd000389
+flags		: fpu vme sse avx2 avx512f
+
+processor	: 1
+vendor_id	: GenuineIntel
+model name	: Intel(R) Xeon(R) Gold 6330 CPU @ 2.00GHz
+flags		: fpu vme sse avx2 avx512f
+`
+
+const arm64CPUInfo = `processor	: 0
+BogoMIPS	: 243.75
+Features	: fp asimd evtstrm aes
+CPU implementer	: 0x41
+CPU part	: 0xd0c
+CPU revision	: 1
+`
+
+func TestParseCPUInfo(t *testing.T) {
+	cpu, err := parseCPUInfo(strings.NewReader(x86CPUInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu.Vendor != "GenuineIntel" || cpu.Microcode != "0xd000389" ||
+		!slices.Equal(cpu.Flags, []string{"avx2", "avx512f", "fpu", "sse", "vme"}) {
+		t.Fatalf("unexpected cpu %+v", cpu)
+	}
+
+	cpu, err = parseCPUInfo(strings.NewReader(arm64CPUInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cpu.Vendor != "0x41" || cpu.ModelName != "0xd0c" ||
+		!slices.Equal(cpu.Flags, []string{"aes", "asimd", "evtstrm", "fp"}) {
+		t.Fatalf("unexpected cpu %+v", cpu)
+	}
+
+	if _, err := parseCPUInfo(strings.NewReader("processor	: 0\n")); err == nil {
+		t.Fatal("expect error without cpu flags")
+	}
+}
+
+func TestCheckSnapshotCompatibility(t *testing.T) {
+	host := &HostCPU{Vendor: "GenuineIntel", Flags: []string{"avx2", "fpu", "sse"}}
+
+	tmpl := &VMTemplate{}
+	if err := tmpl.CheckSnapshotCompatibility(host); err != nil {
+		t.Fatalf("template without snapshot cpu should be compatible, got %v", err)
+	}
+	tmpl.SnapshotCPU = &HostCPU{Vendor: "GenuineIntel", Microcode: "0x1", Flags: []string{"fpu", "sse"}}
+	if err := tmpl.CheckSnapshotCompatibility(host); err != nil {
+		t.Fatalf("expect compatible, got %v", err)
+	}
+
+	// the flags of host not visible to the guest are ignored
+	tmpl.SnapshotCPU.Flags = []string{"constant_tsc", "fpu", "hypervisor", "ibpb", "md_clear", "pti", "sse", "vmx"}
+	if err := tmpl.CheckSnapshotCompatibility(host); err != nil {
+		t.Fatalf("expect compatible without host-only flags, got %v", err)
+	}
+
+	tmpl.SnapshotCPU = &HostCPU{Vendor: "AuthenticAMD", Flags: []string{"avx512f", "fpu", "sse4a"}}
+	problems := tmpl.SnapshotCPU.Incompatibilities(host)
+	if len(problems) != 2 || !strings.Contains(problems[1], "avx512f sse4a") {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+	if err := tmpl.CheckSnapshotCompatibility(host); !errors.Is(err, ErrSnapshotIncompatible) {
+		t.Fatalf("expect snapshot incompatible, got %v", err)
+	}
+
+	// the features of host are masked by the cpu template
+	tmpl.SnapshotCPU.Vendor = host.Vendor
+	tmpl.CpuTemplate = "T2"
+	if err := tmpl.CheckSnapshotCompatibility(host); err != nil {
+		t.Fatalf("expect compatible with cpu template, got %v", err)
+	}
+	tmpl.SnapshotCPU.Vendor = "AuthenticAMD"
+	if problems := tmpl.SnapshotIncompatibilities(host); len(problems) != 1 {
+		t.Fatalf("expect the vendor differs, got %v", problems)
+	}
+}
+
+func TestValidateCpuTemplate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		vmmType  VMMType
+		arch     TargetArch
+		template string
+		valid    bool
+	}{
+		{"none", CLOUDHYPERVISOR, "", "", true},
+		{"explicit none", CLOUDHYPERVISOR, "", "None", true},
+		{"amd64", FIRECRACKER, "", "T2", true},
+		{"arm64", FIRECRACKER, ArchARM64, "V1N1", true},
+		{"wrong arch", FIRECRACKER, ArchARM64, "C3", false},
+		{"unknown", FIRECRACKER, ArchAMD64, "M5", false},
+		{"cloud-hypervisor", CLOUDHYPERVISOR, ArchAMD64, "T2", false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := VMTemplate{VmmType: tc.vmmType, TargetArch: tc.arch, CpuTemplate: tc.template}
+			err := tmpl.validateCpuTemplate()
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, InvalidCpuTemplate) {
+				t.Fatalf("expect invalid cpu template, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+// The kernel args to boot the vm from the rootfs of template, i.e., when
+// building the snapshot of template or cold booting a sandbox. The guest
+// network is the one expected by the netns of sandbox (see network.NetworkEnv).
+// debug prints the kernel output to the serial console.
+func (t *VMTemplate) KernelArgs(debug bool) string {
+	var kernelArgs []string
+	if t.VmmType == CLOUDHYPERVISOR {
+		kernelArgs = t.chKernelArgs(debug)
+	} else {
+		kernelArgs = t.fcKernelArgs(debug)
+	}
+	return strings.Join(kernelArgs, " ")
+}
+
+// client-ip,server-ip,gateway-ip,netmask,hostname,device,autoconf,dns0-ip
+func (t *VMTemplate) ipKernelArg(hostname string) string {
+	return fmt.Sprintf("ip=%s::%s:%s:%s:%s:off:%s",
+		consts.GuestNetIPAddr,
+		consts.HostTapIPAddress,
+		consts.GuestNetIPMaskLong,
+		hostname,
+		consts.GuestIfaceName,
+		t.GuestDNSServers()[0],
+	)
+}
+
+// The kernel args only meaningful on x86, i.e., reboot by the keyboard
+// controller, and skip probing the legacy pci and i8042 (firecracker only).
+func (t *VMTemplate) x86KernelArgs() []string {
+	if t.Arch() != ArchAMD64 {
+		return nil
+	}
+	if t.VmmType == FIRECRACKER {
+		return []string{"reboot=k", "pci=off", "i8042.nokbd i8042.noaux"}
+	}
+	return []string{"reboot=k"}
+}
+
+func (t *VMTemplate) fcKernelArgs(debug bool) []string {
+	kernelArgs := []string{
+		"panic=1",
+		"nomodules",
+		"random.trust_cpu=on",
+		t.ipKernelArg("fc-instance"),
+	}
+	kernelArgs = append(kernelArgs, t.x86KernelArgs()...)
+
+	if !t.IPv6 {
+		kernelArgs = append(kernelArgs, "ipv6.disable=1")
+	}
+
+	if debug {
+		kernelArgs = append(kernelArgs, "loglevel=6 console=ttyS0")
+	} else {
+		kernelArgs = append(kernelArgs, "loglevel=1 quiet")
+	}
+
+	if t.Overlay {
+		kernelArgs = append(kernelArgs, "overlay_root=vdb init="+consts.GuestOverlayInitPath)
+	} else if t.ImmutableRootfs {
+		// firecracker appends "ro" for the read-only root device
+		kernelArgs = append(kernelArgs, "init="+consts.GuestImmutableInitPath)
+	}
+	return kernelArgs
+}
+
+func (t *VMTemplate) chKernelArgs(debug bool) []string {
+	kernelArgs := []string{
+		"nomodules",
+		"random.trust_cpu=on",
+		t.ipKernelArg("ch-instance"),
+	}
+	kernelArgs = append(kernelArgs, t.x86KernelArgs()...)
+	if !t.IPv6 {
+		kernelArgs = append(kernelArgs, "ipv6.disable=1")
+	}
+	if debug {
+		kernelArgs = append(kernelArgs, "loglevel=6 console=hvc0")
+	} else {
+		kernelArgs = append(kernelArgs, "loglevel=1 quiet panic=1")
+	}
+	if t.Overlay {
+		kernelArgs = append(kernelArgs,
+			"root=/dev/pmem0 ro rootflags=dax=always",
+			"overlay_root=vda init="+consts.GuestOverlayInitPath,
+			// "overlay_root=pmem1 overlay_root_flags=dax=always init="+consts.GuestOverlayInitPath,
+		)
+	} else if t.ImmutableRootfs {
+		kernelArgs = append(kernelArgs,
+			"root=/dev/pmem0 ro rootflags=dax=always",
+			"init="+consts.GuestImmutableInitPath,
+		)
+	} else {
+		kernelArgs = append(kernelArgs, "root=/dev/pmem0 rw rootflags=dax=always")
+	}
+	return kernelArgs
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestKernelArgs(t *testing.T) {
+	cases := []struct {
+		name     string
+		tmpl     VMTemplate
+		debug    bool
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "firecracker overlay",
+			tmpl:     VMTemplate{VmmType: FIRECRACKER, Overlay: true},
+			contains: []string{"panic=1", "pci=off", "ipv6.disable=1", "loglevel=1 quiet", "overlay_root=vdb init=" + consts.GuestOverlayInitPath},
+			excludes: []string{"console=ttyS0"},
+		},
+		{
+			name:     "firecracker debug with ipv6",
+			tmpl:     VMTemplate{VmmType: FIRECRACKER, IPv6: true},
+			debug:    true,
+			contains: []string{"console=ttyS0"},
+			excludes: []string{"ipv6.disable=1", "init="},
+		},
+		{
+			name:     "cloud hypervisor immutable",
+			tmpl:     VMTemplate{VmmType: CLOUDHYPERVISOR, ImmutableRootfs: true},
+			contains: []string{"root=/dev/pmem0 ro", "init=" + consts.GuestImmutableInitPath, "reboot=k"},
+			excludes: []string{"pci=off", "overlay_root="},
+		},
+		{
+			name:     "cloud hypervisor arm64",
+			tmpl:     VMTemplate{VmmType: CLOUDHYPERVISOR, TargetArch: ArchARM64},
+			contains: []string{"root=/dev/pmem0 rw"},
+			excludes: []string{"reboot=k"},
+		},
+	}
+	for _, c := range cases {
+		args := c.tmpl.KernelArgs(c.debug)
+		if !strings.Contains(args, "ip="+consts.GuestNetIPAddr) {
+			t.Fatalf("%s: expect the guest ip in %q", c.name, args)
+		}
+		for _, s := range c.contains {
+			if !strings.Contains(args, s) {
+				t.Fatalf("%s: expect %q in %q", c.name, s, args)
+			}
+		}
+		for _, s := range c.excludes {
+			if strings.Contains(args, s) {
+				t.Fatalf("%s: unexpected %q in %q", c.name, s, args)
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultReadinessProbeInterval = 500 * time.Millisecond
+	DefaultReadinessProbeTimeout  = 2 * time.Minute
+)
+
+var InvalidReadinessProbe = errors.New("invalid readiness probe")
+
+// How the template manager knows the guest (and its start command) is ready
+// to be snapshotted, which is polled after the vm starts until it succeeds.
+// Exactly one of HTTPPath and FilePath should be set.
+type ReadinessProbe struct {
+	// GET http://<guest>:<http_port><http_path> until it returns 2xx.
+	HTTPPath string `toml:"http_path,omitempty"`
+	// optional (default: the port of envd)
+	HTTPPort int64 `toml:"http_port,omitempty"`
+
+	// Absolute path of the file in guest, which is created by the start
+	// command once it is ready, checked through envd.
+	FilePath string `toml:"file_path,omitempty"`
+
+	// optional (default: 500)
+	IntervalMs int `toml:"interval_ms,omitempty"`
+	// The build fails if still not ready after it.
+	// optional (default: 120000)
+	TimeoutMs int `toml:"timeout_ms,omitempty"`
+}
+
+func (p *ReadinessProbe) Validate() error {
+	if (p.HTTPPath == "") == (p.FilePath == "") {
+		return fmt.Errorf("%w: exactly one of http_path and file_path should be set", InvalidReadinessProbe)
+	}
+	if p.HTTPPath != "" && !strings.HasPrefix(p.HTTPPath, "/") {
+		return fmt.Errorf("%w: http_path %q should start with /", InvalidReadinessProbe, p.HTTPPath)
+	}
+	if p.HTTPPort < 0 || p.HTTPPort > 65535 {
+		return fmt.Errorf("%w: http_port %d should be in [0, 65535]", InvalidReadinessProbe, p.HTTPPort)
+	}
+	if p.FilePath != "" && !filepath.IsAbs(p.FilePath) {
+		return fmt.Errorf("%w: file_path %q should be absolute", InvalidReadinessProbe, p.FilePath)
+	}
+	if p.IntervalMs < 0 || p.TimeoutMs < 0 {
+		return fmt.Errorf("%w: interval_ms and timeout_ms cannot be negative", InvalidReadinessProbe)
+	}
+	return nil
+}
+
+func (p *ReadinessProbe) Interval() time.Duration {
+	if p.IntervalMs == 0 {
+		return DefaultReadinessProbeInterval
+	}
+	return time.Duration(p.IntervalMs) * time.Millisecond
+}
+
+func (p *ReadinessProbe) Timeout() time.Duration {
+	if p.TimeoutMs == 0 {
+		return DefaultReadinessProbeTimeout
+	}
+	return time.Duration(p.TimeoutMs) * time.Millisecond
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateReadinessProbe(t *testing.T) {
+	testCases := []struct {
+		name  string
+		probe ReadinessProbe
+		valid bool
+	}{
+		{"http", ReadinessProbe{HTTPPath: "/health", HTTPPort: 8080}, true},
+		{"file", ReadinessProbe{FilePath: "/tmp/ready", IntervalMs: 100, TimeoutMs: 1000}, true},
+		{"none", ReadinessProbe{}, false},
+		{"both", ReadinessProbe{HTTPPath: "/health", FilePath: "/tmp/ready"}, false},
+		{"relative http path", ReadinessProbe{HTTPPath: "health"}, false},
+		{"invalid port", ReadinessProbe{HTTPPath: "/health", HTTPPort: 65536}, false},
+		{"relative file path", ReadinessProbe{FilePath: "tmp/ready"}, false},
+		{"negative timeout", ReadinessProbe{FilePath: "/tmp/ready", TimeoutMs: -1}, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.probe.Validate()
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, InvalidReadinessProbe) {
+				t.Fatalf("expect InvalidReadinessProbe, got %v", err)
+			}
+		})
+	}
+
+	probe := ReadinessProbe{FilePath: "/tmp/ready"}
+	if probe.Interval() != DefaultReadinessProbeInterval || probe.Timeout() != DefaultReadinessProbeTimeout {
+		t.Fatalf("expect the default interval and timeout, got %s and %s", probe.Interval(), probe.Timeout())
+	}
+}
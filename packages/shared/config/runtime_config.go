@@ -5,6 +5,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 )
@@ -39,6 +40,71 @@ type CommonConfig struct {
 	FCBinaryPath string `toml:"fc_binary_path"`
 	CHBinaryPath string `toml:"ch_binary_path"`
 	DataRoot     string `toml:"data_root"`
+	// Reclaim the netns/veth left by a crashed process when allocating the network,
+	// instead of failing. The netns still containing processes is never reclaimed.
+	// Nil means true.
+	ForceReclaimNetwork *bool `toml:"force_reclaim_network"`
+	// How to wait for the api socket of vmm after it is started, 0 means
+	// the default (see utils.DefaultSocketWaitOptions). The socket is polled
+	// with exponential backoff from the poll interval to the max one.
+	SocketWaitTimeoutMs     int `toml:"socket_wait_timeout_ms"`
+	SocketPollIntervalMs    int `toml:"socket_poll_interval_ms"`
+	SocketMaxPollIntervalMs int `toml:"socket_max_poll_interval_ms"`
+	// Probe the api (e.g., GET /version) after the socket is created. Nil means true.
+	SocketProbe *bool `toml:"socket_probe"`
+	// The free disk space (in MiB) of data_root kept besides the estimated need
+	// when building templates or creating sandboxes, 0 means the default
+	// (see DefaultDiskHeadroomMB) and negative disables the check.
+	DiskHeadroomMB int64 `toml:"disk_headroom_mb"`
+	// The mtu of the tap, veth and vpeer devices of sandbox networks,
+	// 0 means the mtu of the host default gateway interface.
+	MTU int `toml:"mtu"`
+	// The dir of the api sockets of vmm, empty means DefaultSocketDir. It should
+	// be short enough for the socket paths to fit in sun_path of unix socket.
+	SocketDir string `toml:"socket_dir"`
+}
+
+const DefaultSocketDir = "/tmp"
+
+func (c *CommonConfig) VMMSocketDir() string {
+	if c.SocketDir == "" {
+		return DefaultSocketDir
+	}
+	return filepath.Clean(c.SocketDir)
+}
+
+const DefaultDiskHeadroomMB = 1024
+
+// The disk headroom in bytes, negative means the check is disabled.
+func (c *CommonConfig) DiskHeadroom() int64 {
+	switch {
+	case c.DiskHeadroomMB == 0:
+		return DefaultDiskHeadroomMB << 20
+	case c.DiskHeadroomMB < 0:
+		return -1
+	}
+	return c.DiskHeadroomMB << 20
+}
+
+func (c *CommonConfig) ForceReclaim() bool {
+	return c.ForceReclaimNetwork == nil || *c.ForceReclaimNetwork
+}
+
+func (c *CommonConfig) SocketWaitOptions() utils.SocketWaitOptions {
+	opts := utils.DefaultSocketWaitOptions()
+	if c.SocketWaitTimeoutMs > 0 {
+		opts.Timeout = time.Duration(c.SocketWaitTimeoutMs) * time.Millisecond
+	}
+	if c.SocketPollIntervalMs > 0 {
+		opts.PollInterval = time.Duration(c.SocketPollIntervalMs) * time.Millisecond
+	}
+	if c.SocketMaxPollIntervalMs > 0 {
+		opts.MaxPollInterval = time.Duration(c.SocketMaxPollIntervalMs) * time.Millisecond
+	}
+	if c.SocketProbe != nil {
+		opts.Probe = *c.SocketProbe
+	}
+	return opts
 }
 
 func GetConfigFilePath() (configFile string, err error) {
@@ -5,9 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"os"
 	"path/filepath"
 
+	"github.com/BurntSushi/toml"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 )
 
 type VMMType string
@@ -23,6 +27,15 @@ var (
 	InvalidDiskSize     = errors.New("invalid disk size")
 	InvalidKernelVer    = errors.New("invalid kernel version")
 	InvalidVmmType      = errors.New("invalid vmm type")
+	InvalidGuestDNS     = errors.New("invalid guest dns server")
+	InvalidHugePageSize = errors.New("invalid huge page size")
+	InvalidExtraDisks   = errors.New("invalid extra disk slots")
+	InvalidCompression  = errors.New("invalid memfile compression")
+	InvalidMemHotplug   = errors.New("invalid memory hotplug size")
+	InvalidImmutable    = errors.New("invalid immutable rootfs")
+	InvalidEnvdPort     = errors.New("invalid envd port")
+	InvalidDiskLimit    = errors.New("invalid disk rate limit")
+	InvalidCpuTemplate  = errors.New("invalid cpu template")
 	ErrVMMTypeUnmarshal = errors.New("invalid value for VMMType when unmashal")
 )
 
@@ -79,6 +92,13 @@ type VMTemplate struct {
 	// required
 	MemoryMB int64 `toml:"mem_mb"`
 
+	// The amount of RAM memory (in MiB) can be hotplugged (by virtio-mem) to
+	// the VM after restored, i.e., the upper bound of the memory override of
+	// sandboxes is mem_mb + mem_hotplug_mb. It should be a multiple of 128.
+	// Only supported by cloud-hypervisor.
+	// optional (default: 0)
+	MemoryHotplugMB int64 `toml:"mem_hotplug_mb,omitempty"`
+
 	// The amount of free disk to allocate to the VM, in MiB.
 	// required
 	DiskSizeMB int64 `toml:"disk_mb"`
@@ -98,15 +118,118 @@ type VMTemplate struct {
 	// Use local docker image (i.e., do not pull from remote docker registry)
 	NoPull bool `toml:"no_pull"`
 
+	// Deprecated: use HugePageSize instead, true is the same as "2M".
 	HugePages bool `toml:"huge_pages,omitempty"`
 
+	// Size of huge pages backing the guest memory: "none", "2M" or "1G".
+	// 1G is only supported by cloud-hypervisor.
+	// optional (default: none)
+	HugePageSize HugePageSize `toml:"huge_page_size,omitempty"`
+
 	// Create two block device for VM. One is read-only lower dir,
 	// the other is writable upper dir.
 	// Set this to false (by default) will create one read-write block device.
 	Overlay bool `toml:"overlay"`
 
+	// Mount the rootfs read-only without the writable block device of overlay,
+	// the guest writes into the tmpfs mounted over the writable dirs (e.g.,
+	// /etc, /var, /home and /tmp), which is lost when the sandbox is deleted.
+	// Cannot be used with overlay.
+	// optional (default: false)
+	ImmutableRootfs bool `toml:"immutable_rootfs,omitempty"`
+
 	VmmType VMMType `toml:"vmm_type"`
 
+	// The cpu architecture of the guest: "amd64" or "arm64", which decides the
+	// platform of docker image, the kernel and the kernel boot args. The template
+	// can only be booted on the host of the same architecture.
+	// optional (default: amd64)
+	TargetArch TargetArch `toml:"target_arch,omitempty"`
+
+	// The static cpu template of firecracker (e.g., "T2" or "C3" on amd64 and
+	// "V1N1" on arm64), which masks the cpu features exposed to the guest to a
+	// baseline, so that the snapshot can be restored on the hosts of different
+	// cpu models. It is applied when building and kept in the snapshot.
+	// optional (default: none, i.e., the features of the host are exposed)
+	CpuTemplate string `toml:"cpu_template,omitempty"`
+
+	// Attach an extra read-only block device as cloud-init config drive.
+	// The content of the drive can be specified per sandbox when creating.
+	ConfigDrive bool `toml:"config_drive"`
+
+	// The number of extra (scratch) disk slots attached to the vm when building,
+	// each sandbox can attach up to this number of extra disks when creating.
+	// Only needed by firecracker, which cannot hotplug block devices after
+	// restoring, cloud-hypervisor hotplugs the extra disks instead.
+	// optional (default: 0)
+	ExtraDiskSlots int `toml:"extra_disk_slots,omitempty"`
+
+	// Limit the disk io of each drive of the vm (in bytes and operations per
+	// second, both read and write are counted, as neither vmm limits them
+	// separately), so that one sandbox cannot saturate the io of host. The
+	// limits are part of the snapshot, and can be overridden when creating.
+	// optional (default: 0, i.e., unlimited)
+	DiskBandwidthBps int64 `toml:"disk_bandwidth_bps,omitempty"`
+	DiskIops         int64 `toml:"disk_iops,omitempty"`
+
+	// Attach a memory balloon device (deflated after boot), so that the memory
+	// of a running sandbox can be reclaimed by inflating the balloon.
+	Balloon bool `toml:"balloon"`
+
+	// Populate the guest memory from the snapshot memfile before resuming,
+	// instead of faulting it lazily, which trades the restore time for
+	// the latency of the first requests.
+	// optional (default: false)
+	PrefaultMemory bool `toml:"prefault_memory"`
+
+	// Compress the memfile of snapshot (with zstd) to save the disk space, it is
+	// decompressed into the instance dir of each sandbox when restoring, which
+	// costs the restore time. The snapshots (or templates) created from the
+	// sandbox are also compressed. Only supported by firecracker.
+	// optional (default: false)
+	CompressMemfile bool `toml:"compress_memfile"`
+
+	// Extra args appended to the command line of hypervisor (e.g., `--log-file`
+	// of cloud-hypervisor), the api socket arg cannot be specified.
+	// optional
+	ExtraHypervisorArgs []string `toml:"extra_hypervisor_args,omitempty"`
+
+	// DNS servers of the guest, the first one (should be ipv4) is passed by the
+	// kernel boot arg and all of them are written into /etc/resolv.conf.
+	// optional (default: 8.8.8.8)
+	GuestDNS []string `toml:"guest_dns,omitempty"`
+
+	// Enable ipv6 inside the guest and configure its ipv6 address,
+	// which takes effect only when ipv6_subnet of orchestrator is set.
+	IPv6 bool `toml:"ipv6"`
+
+	// The port envd listens on inside the guest, for the custom envd builds.
+	// optional (default: consts.DefaultEnvdServerPort)
+	EnvdPort int64 `toml:"envd_port,omitempty"`
+
+	// The private dir recorded in the snapshot (i.e., the disk and kernel paths seen
+	// by the vmm), only set for the template created from a sandbox snapshot, which
+	// inherits the private dir of its source template.
+	SnapshotPrivateDir string `toml:"snapshot_private_dir,omitempty"`
+
+	// Hex encoded sha256 of the rootfs and kernel (see [VMTemplate.ChecksumFiles]),
+	// keyed by the file name, recorded when building and verified before booting
+	// the sandbox if verify_checksums of orchestrator is enabled.
+	// optional
+	Checksums map[string]string `toml:"checksums,omitempty"`
+
+	// The cpu of the host where the snapshot is taken, recorded when building
+	// (or creating the template from a sandbox), see CheckSnapshotCompatibility.
+	// optional
+	SnapshotCPU *HostCPU `toml:"snapshot_cpu,omitempty"`
+
+	// Path (on host) to an extra bash script run inside the container when
+	// building the rootfs, e.g., to install packages or create users.
+	// It runs after all the mandatory setup (systemd, envd, etc.) and
+	// a non-zero exit fails the build.
+	// optional
+	ProvisionScriptPath string `toml:"provision_script_path,omitempty"`
+
 	// Command to run when building the env.
 	// optional (default: empty)
 	StartCmd struct {
@@ -114,6 +237,42 @@ type VMTemplate struct {
 		EnvFilePath string `toml:"envfile_path"`
 		WorkingDir  string `toml:"working_dir"`
 	} `toml:"start_cmd"`
+
+	// How to know the start command is ready before snapshotting the vm
+	// when building, instead of waiting for a fixed time.
+	// optional
+	ReadinessProbe *ReadinessProbe `toml:"readiness_probe,omitempty"`
+}
+
+type HugePageSize string
+
+const (
+	HugePageNone HugePageSize = "none"
+	HugePage2M   HugePageSize = "2M"
+	HugePage1G   HugePageSize = "1G"
+)
+
+func (s *HugePageSize) UnmarshalText(text []byte) error {
+	size := HugePageSize(text)
+	switch size {
+	case "", HugePageNone, HugePage2M, HugePage1G:
+		*s = size
+		return nil
+	default:
+		return fmt.Errorf("%w %s", InvalidHugePageSize, text)
+	}
+}
+
+// The size of huge page in bytes, 0 means huge page is not used.
+func (s HugePageSize) Bytes() int64 {
+	switch s {
+	case HugePage2M:
+		return 2 << 20
+	case HugePage1G:
+		return 1 << 30
+	default:
+		return 0
+	}
 }
 
 // Path to the directory where the env is stored.
@@ -143,6 +302,9 @@ func (t *VMTemplate) HostWritableRootfsPath(dataRoot string) string {
 // Thus, there can be multiple instance of tmpRunningPath (each in a
 // seperate mount ns).
 func (t *VMTemplate) PrivateDir(dataRoot string) string {
+	if t.SnapshotPrivateDir != "" {
+		return t.SnapshotPrivateDir
+	}
 	return filepath.Join(t.TemplateDir(dataRoot), "run")
 }
 
@@ -154,8 +316,65 @@ func (t *VMTemplate) PrivateWritableRootfsPath(dataRoot string) string {
 	return filepath.Join(t.PrivateDir(dataRoot), consts.WritableFsName)
 }
 
-// The dir on the host where should keep the kernel vmlinux
+// Only valid when enable config drive.
+func (t *VMTemplate) PrivateConfigDrivePath(dataRoot string) string {
+	return filepath.Join(t.PrivateDir(dataRoot), consts.ConfigDriveName)
+}
+
+// The path of the i-th extra disk slot recorded in the snapshot.
+func (t *VMTemplate) PrivateExtraDiskPath(dataRoot string, i int) string {
+	return filepath.Join(t.PrivateDir(dataRoot), fmt.Sprintf(consts.ExtraDiskNameFormat, i))
+}
+
+// Whether the rootfs is attached read-only, i.e., with overlay or immutable rootfs.
+func (t *VMTemplate) ReadOnlyRootfs() bool {
+	return t.Overlay || t.ImmutableRootfs
+}
+
+// The files (relative to [VMTemplate.TemplateImgDir]) needed to restore from the template.
+func (t *VMTemplate) ImageFiles() []string {
+	files := []string{consts.RootfsName}
+	if t.Overlay {
+		files = append(files, consts.WritableFsName)
+	}
+	switch t.VmmType {
+	case FIRECRACKER:
+		files = append(files, consts.FcSnapfileName)
+		if t.CompressMemfile {
+			files = append(files, consts.FcCompressedMemfileName)
+		} else {
+			files = append(files, consts.FcMemfileName)
+		}
+	case CLOUDHYPERVISOR:
+		files = append(files, consts.ChSnapshotFiles[:]...)
+	}
+	return files
+}
+
+// The estimated disk space (in bytes) taken by a sandbox of the template, i.e.,
+// its rootfs (reflinked, but can be fully written by the guest) and the memfile
+// decompressed into the instance dir (if compressed).
+func (t *VMTemplate) InstanceDiskSize() int64 {
+	size := t.RootfsSize
+	if t.Overlay {
+		// the read-only rootfs is hard linked, only the writable one grows
+		size = t.DiskSizeMB << 20
+	} else if t.ImmutableRootfs {
+		// the read-only rootfs is hard linked, the writes are in memory
+		size = 0
+	}
+	if t.CompressMemfile {
+		size += t.MemoryMB << 20
+	}
+	return size
+}
+
+// The dir on the host where should keep the kernel vmlinux, the kernels of
+// other than amd64 are kept in the sub dir named after the arch.
 func (t *VMTemplate) HostKernelPath(dataRoot string) string {
+	if t.Arch() != ArchAMD64 {
+		return filepath.Join(dataRoot, consts.KernelDirName, t.KernelVersion, string(t.Arch()), consts.KernelName)
+	}
 	return filepath.Join(dataRoot, consts.KernelDirName, t.KernelVersion, consts.KernelName)
 }
 
@@ -163,12 +382,60 @@ func (t *VMTemplate) PrivateKernelPath(dataRoot string) string {
 	return filepath.Join(t.PrivateDir(dataRoot), consts.KernelName)
 }
 
+// The files whose checksums are recorded in [VMTemplate.Checksums],
+// return the map from the file name to its path on host.
+func (t *VMTemplate) ChecksumFiles(dataRoot string) map[string]string {
+	files := map[string]string{
+		consts.RootfsName: t.HostRootfsPath(dataRoot),
+		consts.KernelName: t.HostKernelPath(dataRoot),
+	}
+	if t.Overlay {
+		files[consts.WritableFsName] = t.HostWritableRootfsPath(dataRoot)
+	}
+	return files
+}
+
+// Compute the checksums of [VMTemplate.ChecksumFiles] into [VMTemplate.Checksums].
+func (t *VMTemplate) ComputeChecksums(dataRoot string) error {
+	checksums := make(map[string]string)
+	for name, path := range t.ChecksumFiles(dataRoot) {
+		sum, err := utils.SHA256File(path)
+		if err != nil {
+			return fmt.Errorf("error computing checksum of %s: %w", name, err)
+		}
+		checksums[name] = sum
+	}
+	t.Checksums = checksums
+	return nil
+}
+
 // The path of the template configuration file.
 // It is located in [VMTemplate.TemplateDir]
 func (t *VMTemplate) TemplateFilePath(dataRoot string) string {
 	return filepath.Join(t.TemplateDir(dataRoot), consts.TemplateFileName)
 }
 
+// Dump the template to [VMTemplate.TemplateFilePath].
+//
+// The template is written to a tmp file and then renamed, so that the
+// template will never be seen partially written (e.g., after crash).
+func (t *VMTemplate) Dump(dataRoot string) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(*t); err != nil {
+		return fmt.Errorf("error encode template: %w", err)
+	}
+	path := t.TemplateFilePath(dataRoot)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing template file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error renaming template file: %w", err)
+	}
+	return nil
+}
+
 func (t *VMTemplate) Validate() error {
 	if t.VCpuCount == 0 {
 		return InvalidVcpuCount
@@ -191,5 +458,143 @@ func (t *VMTemplate) Validate() error {
 	default:
 		return InvalidVmmType
 	}
+
+	switch t.Arch() {
+	case ArchAMD64, ArchARM64:
+	default:
+		return fmt.Errorf("%w %s", InvalidTargetArch, t.TargetArch)
+	}
+
+	if err := t.validateHugePage(); err != nil {
+		return err
+	}
+
+	if err := t.validateCpuTemplate(); err != nil {
+		return err
+	}
+
+	if t.ImmutableRootfs && t.Overlay {
+		return fmt.Errorf("%w: cannot be used with overlay", InvalidImmutable)
+	}
+
+	if t.ExtraDiskSlots < 0 || t.ExtraDiskSlots > consts.MaxExtraDiskSlots {
+		return fmt.Errorf("%w: %d should be in [0, %d]", InvalidExtraDisks, t.ExtraDiskSlots, consts.MaxExtraDiskSlots)
+	}
+	if t.ExtraDiskSlots > 0 && t.VmmType != FIRECRACKER {
+		return fmt.Errorf("%w: only firecracker needs extra disk slots", InvalidExtraDisks)
+	}
+	if t.CompressMemfile && t.VmmType != FIRECRACKER {
+		return fmt.Errorf("%w: only supported by firecracker", InvalidCompression)
+	}
+	if err := t.validateMemHotplug(); err != nil {
+		return err
+	}
+	if t.DiskBandwidthBps < 0 || t.DiskIops < 0 {
+		return fmt.Errorf("%w: disk_bandwidth_bps and disk_iops cannot be negative", InvalidDiskLimit)
+	}
+	if t.EnvdPort < 0 || t.EnvdPort > 65535 {
+		return fmt.Errorf("%w: %d should be in [1, 65535]", InvalidEnvdPort, t.EnvdPort)
+	}
+	if t.ReadinessProbe != nil {
+		if err := t.ReadinessProbe.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for i, server := range t.GuestDNS {
+		ip := net.ParseIP(server)
+		if ip == nil {
+			return fmt.Errorf("%w: %q", InvalidGuestDNS, server)
+		}
+		// the dns0 of kernel ip= boot arg only accepts ipv4
+		if i == 0 && ip.To4() == nil {
+			return fmt.Errorf("%w: the first one should be ipv4, got %s", InvalidGuestDNS, server)
+		}
+	}
+	return nil
+}
+
+func (t *VMTemplate) validateMemHotplug() error {
+	if t.MemoryHotplugMB == 0 {
+		return nil
+	}
+	if t.VmmType != CLOUDHYPERVISOR {
+		return fmt.Errorf("%w: only supported by %s", InvalidMemHotplug, CLOUDHYPERVISOR)
+	}
+	// the alignment required by virtio-mem of cloud hypervisor
+	if t.MemoryHotplugMB < 0 || t.MemoryHotplugMB%128 != 0 {
+		return fmt.Errorf("%w: %d MiB is not a positive multiple of 128 MiB", InvalidMemHotplug, t.MemoryHotplugMB)
+	}
+	if size := t.HugePage().Bytes(); size != 0 && (t.MemoryHotplugMB<<20)%size != 0 {
+		return fmt.Errorf("%w: %d MiB is not a multiple of %s", InvalidMemHotplug, t.MemoryHotplugMB, t.HugePage())
+	}
+	return nil
+}
+
+// Check the memfile in the image dir matches compress_memfile, e.g., the
+// template built without compression cannot be restored with it enabled.
+func (t *VMTemplate) ValidateMemfile(dataRoot string) error {
+	if t.VmmType != FIRECRACKER {
+		return nil
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(t.TemplateImgDir(dataRoot), name))
+		return err == nil
+	}
+	compressed, uncompressed := exists(consts.FcCompressedMemfileName), exists(consts.FcMemfileName)
+	if t.CompressMemfile && !compressed && uncompressed {
+		return fmt.Errorf("%w: compress_memfile is enabled, but the memfile is not compressed", InvalidCompression)
+	}
+	if !t.CompressMemfile && compressed && !uncompressed {
+		return fmt.Errorf("%w: the memfile is compressed, but compress_memfile is not enabled", InvalidCompression)
+	}
+	return nil
+}
+
+// The port of envd in guest, fallback to [consts.DefaultEnvdServerPort] when not set.
+func (t *VMTemplate) EnvdServerPort() int64 {
+	if t.EnvdPort == 0 {
+		return consts.DefaultEnvdServerPort
+	}
+	return t.EnvdPort
+}
+
+// The DNS servers of guest, fallback to [consts.DefaultGuestDNS] when not set.
+func (t *VMTemplate) GuestDNSServers() []string {
+	if len(t.GuestDNS) == 0 {
+		return []string{consts.DefaultGuestDNS}
+	}
+	return t.GuestDNS
+}
+
+// The effective huge page size of the template. The deprecated HugePages
+// flag is respected only when HugePageSize is not set.
+func (t *VMTemplate) HugePage() HugePageSize {
+	if t.HugePageSize == "" {
+		if t.HugePages {
+			return HugePage2M
+		}
+		return HugePageNone
+	}
+	return t.HugePageSize
+}
+
+func (t *VMTemplate) validateHugePage() error {
+	size := t.HugePage()
+	switch size {
+	case HugePageNone:
+		return nil
+	case HugePage2M:
+	case HugePage1G:
+		// firecracker only supports 2M huge pages
+		if t.VmmType != CLOUDHYPERVISOR {
+			return fmt.Errorf("%w: %s is only supported by %s", InvalidHugePageSize, size, CLOUDHYPERVISOR)
+		}
+	default:
+		return fmt.Errorf("%w: %s", InvalidHugePageSize, size)
+	}
+	if (t.MemoryMB<<20)%size.Bytes() != 0 {
+		return fmt.Errorf("%w: memory (%d MiB) is not a multiple of %s", InvalidHugePageSize, t.MemoryMB, size)
+	}
 	return nil
 }
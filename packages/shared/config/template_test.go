@@ -0,0 +1,246 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestTemplateDump(t *testing.T) {
+	dataRoot := t.TempDir()
+	tmpl := VMTemplate{
+		TemplateID:    "test",
+		VCpuCount:     2,
+		MemoryMB:      512,
+		DiskSizeMB:    1024,
+		KernelVersion: consts.DefaultKernelVersion,
+		VmmType:       FIRECRACKER,
+		GuestDNS:      []string{"1.1.1.1"},
+	}
+	if err := os.MkdirAll(tmpl.TemplateDir(dataRoot), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// an older (longer) template is replaced as a whole
+	if err := os.WriteFile(tmpl.TemplateFilePath(dataRoot), []byte("# stale\n"+string(make([]byte, 4096))), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tmpl.Dump(dataRoot); err != nil {
+		t.Fatalf("dump template failed: %s", err)
+	}
+	if _, err := os.Stat(tmpl.TemplateFilePath(dataRoot) + ".tmp"); !os.IsNotExist(err) {
+		t.Fatalf("tmp file should be renamed, stat err: %v", err)
+	}
+
+	var loaded VMTemplate
+	if _, err := toml.DecodeFile(tmpl.TemplateFilePath(dataRoot), &loaded); err != nil {
+		t.Fatalf("decode dumped template failed: %s", err)
+	}
+	if loaded.TemplateID != tmpl.TemplateID || loaded.VCpuCount != tmpl.VCpuCount ||
+		loaded.MemoryMB != tmpl.MemoryMB || loaded.VmmType != tmpl.VmmType ||
+		len(loaded.GuestDNS) != 1 || loaded.GuestDNS[0] != "1.1.1.1" {
+		t.Fatalf("unexpected dumped template %+v", loaded)
+	}
+}
+
+func TestTemplateDumpNoDir(t *testing.T) {
+	tmpl := VMTemplate{TemplateID: "test"}
+	if err := tmpl.Dump(t.TempDir()); err == nil {
+		t.Fatal("expect error when the template dir does not exist")
+	}
+}
+
+func TestHugePageSizeUnmarshalText(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected HugePageSize
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"none", HugePageNone, false},
+		{"2M", HugePage2M, false},
+		{"1G", HugePage1G, false},
+		{"2m", "", true},
+		{"4K", "", true},
+		{"1GB", "", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			var size HugePageSize
+			err := size.UnmarshalText([]byte(tc.input))
+			if tc.wantErr {
+				if !errors.Is(err, InvalidHugePageSize) {
+					t.Fatalf("expect invalid huge page size, got %v", err)
+				}
+				return
+			}
+			if err != nil || size != tc.expected {
+				t.Fatalf("expect %q, got %q (err: %v)", tc.expected, size, err)
+			}
+		})
+	}
+
+	var tmpl VMTemplate
+	if _, err := toml.Decode(`huge_page_size = "1G"`, &tmpl); err != nil || tmpl.HugePageSize != HugePage1G {
+		t.Fatalf("expect 1G decoded from toml, got %q (err: %v)", tmpl.HugePageSize, err)
+	}
+	if _, err := toml.Decode(`huge_page_size = "3M"`, &tmpl); err == nil {
+		t.Fatalf("expect invalid huge page size decoded from toml, got %v", err)
+	}
+}
+
+func TestValidateHugePage(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vmmType   VMMType
+		hugePages bool
+		size      HugePageSize
+		memoryMB  int64
+		expected  HugePageSize
+		wantErr   bool
+	}{
+		{"disabled", FIRECRACKER, false, "", 511, HugePageNone, false},
+		{"none", CLOUDHYPERVISOR, false, HugePageNone, 511, HugePageNone, false},
+		{"deprecated huge pages", FIRECRACKER, true, "", 512, HugePage2M, false},
+		{"size overrides huge pages", FIRECRACKER, true, HugePageNone, 511, HugePageNone, false},
+		{"2M firecracker", FIRECRACKER, false, HugePage2M, 512, HugePage2M, false},
+		{"2M cloud-hypervisor", CLOUDHYPERVISOR, false, HugePage2M, 512, HugePage2M, false},
+		{"2M unaligned memory", FIRECRACKER, false, HugePage2M, 511, HugePage2M, true},
+		{"1G cloud-hypervisor", CLOUDHYPERVISOR, false, HugePage1G, 2048, HugePage1G, false},
+		{"1G unaligned memory", CLOUDHYPERVISOR, false, HugePage1G, 1536, HugePage1G, true},
+		{"1G firecracker", FIRECRACKER, false, HugePage1G, 2048, HugePage1G, true},
+		{"unknown size", CLOUDHYPERVISOR, false, "4K", 512, "4K", true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := VMTemplate{
+				VmmType:      tc.vmmType,
+				MemoryMB:     tc.memoryMB,
+				HugePages:    tc.hugePages,
+				HugePageSize: tc.size,
+			}
+			if size := tmpl.HugePage(); size != tc.expected {
+				t.Fatalf("expect huge page %q, got %q", tc.expected, size)
+			}
+			err := tmpl.validateHugePage()
+			if tc.wantErr && !errors.Is(err, InvalidHugePageSize) {
+				t.Fatalf("expect invalid huge page size, got %v", err)
+			}
+			if !tc.wantErr && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateMemHotplug(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vmmType   VMMType
+		hotplugMB int64
+		size      HugePageSize
+		valid     bool
+	}{
+		{"disabled", FIRECRACKER, 0, "", true},
+		{"cloud-hypervisor", CLOUDHYPERVISOR, 1024, "", true},
+		{"firecracker", FIRECRACKER, 1024, "", false},
+		{"unaligned", CLOUDHYPERVISOR, 100, "", false},
+		{"negative", CLOUDHYPERVISOR, -128, "", false},
+		{"1G unaligned", CLOUDHYPERVISOR, 512, HugePage1G, false},
+		{"1G aligned", CLOUDHYPERVISOR, 2048, HugePage1G, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl := VMTemplate{VmmType: tc.vmmType, MemoryHotplugMB: tc.hotplugMB, HugePageSize: tc.size}
+			err := tmpl.validateMemHotplug()
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, InvalidMemHotplug) {
+				t.Fatalf("expect invalid memory hotplug, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateMemfile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		vmmType  VMMType
+		compress bool
+		files    []string
+		valid    bool
+	}{
+		{"uncompressed", FIRECRACKER, false, []string{consts.FcMemfileName}, true},
+		{"compressed", FIRECRACKER, true, []string{consts.FcCompressedMemfileName}, true},
+		{"built uncompressed", FIRECRACKER, true, []string{consts.FcMemfileName}, false},
+		{"built compressed", FIRECRACKER, false, []string{consts.FcCompressedMemfileName}, false},
+		// e.g., a template being fetched, the missing files are reported elsewhere
+		{"missing", FIRECRACKER, true, nil, true},
+		{"cloud hypervisor", CLOUDHYPERVISOR, false, []string{consts.FcCompressedMemfileName}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dataRoot := t.TempDir()
+			tmpl := VMTemplate{TemplateID: "test", VmmType: tc.vmmType, CompressMemfile: tc.compress}
+			if err := os.MkdirAll(tmpl.TemplateImgDir(dataRoot), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range tc.files {
+				if err := os.WriteFile(filepath.Join(tmpl.TemplateImgDir(dataRoot), file), nil, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := tmpl.ValidateMemfile(dataRoot)
+			if tc.valid && err != nil {
+				t.Fatalf("expect valid, got %s", err)
+			}
+			if !tc.valid && !errors.Is(err, InvalidCompression) {
+				t.Fatalf("expect invalid compression, got %v", err)
+			}
+		})
+	}
+}
+
+func TestInstanceDiskSize(t *testing.T) {
+	testCases := []struct {
+		name      string
+		overlay   bool
+		immutable bool
+		compress  bool
+		expected  int64
+	}{
+		{"rootfs", false, false, false, 3000 << 20},
+		{"overlay", true, false, false, 1024 << 20},
+		{"immutable", false, true, false, 0},
+		{"compressed memfile", false, false, true, (3000 + 512) << 20},
+	}
+	for _, tc := range testCases {
+		tmpl := VMTemplate{
+			MemoryMB:        512,
+			DiskSizeMB:      1024,
+			RootfsSize:      3000 << 20,
+			Overlay:         tc.overlay,
+			ImmutableRootfs: tc.immutable,
+			CompressMemfile: tc.compress,
+		}
+		if size := tmpl.InstanceDiskSize(); size != tc.expected {
+			t.Fatalf("%s: expect %d, got %d", tc.name, tc.expected, size)
+		}
+	}
+}
+
+func TestEnvdServerPort(t *testing.T) {
+	tmpl := VMTemplate{}
+	if port := tmpl.EnvdServerPort(); port != consts.DefaultEnvdServerPort {
+		t.Fatalf("expect the default envd port %d, got %d", consts.DefaultEnvdServerPort, port)
+	}
+	tmpl.EnvdPort = 50000
+	if port := tmpl.EnvdServerPort(); port != 50000 {
+		t.Fatalf("expect envd port 50000, got %d", port)
+	}
+}
@@ -3,4 +3,6 @@ package consts
 const (
 	FcSnapfileName = "snapfile"
 	FcMemfileName  = "memfile"
+	// the memfile compressed by zstd (see hypervisor.CompressMemfile)
+	FcCompressedMemfileName = "memfile.zst"
 )
@@ -10,7 +10,16 @@ const (
 	GuestMacAddress    = "02:FC:00:00:00:05"
 	GuestNetIPMaskLong = "255.255.255.252"
 	GuestIfaceName     = "eth0"
+	DefaultGuestDNS    = "8.8.8.8"
 
 	VethMask  int = 30
 	VPeerName     = "veth0"
+
+	// Only used when ipv6 is enabled, the (ULA) addresses are the same
+	// for all sandboxes, like the ipv4 ones above.
+	HostTapIPv6Address = "fd00:fc::1"
+	GuestNetIPv6Addr   = "fd00:fc::2"
+	GuestNetIPv6Mask   = 64
+
+	VethIPv6Mask int = 126
 )
@@ -5,4 +5,8 @@ const (
 
 	CgroupfsPath      = "/sys/fs/cgroup"
 	DefaultCgroupName = "code-interpreter"
+
+	// the dir under data root contains the persisted state of sandboxes (one
+	// json file per sandbox), also used by log-collector to find the live ones
+	SandboxRegistryDirName = "sandboxes"
 )
@@ -7,10 +7,20 @@ const (
 	KernelDirName   = "kernels"
 
 	GuestEnvdPath = "/usr/bin/envd"
+	// The init (in guest) mounting the writable rootfs as the upper dir of overlayfs
+	GuestOverlayInitPath = "/sbin/overlay-init"
+	// The init (in guest) mounting the writable dirs when immutable rootfs
+	GuestImmutableInitPath = "/sbin/immutable-init"
 
 	DefaultKernelVersion = "6.1.134"
 
 	RootfsName       = "rootfs.ext4"          // the base image
 	WritableFsName   = "writable-rootfs.ext4" // an empty writable image
+	ConfigDriveName  = "config-drive.img"     // the cloud-init config drive
 	TemplateFileName = "template.toml"
+
+	// The i-th extra (scratch) disk of sandbox, see ExtraDiskSlots of template.
+	ExtraDiskNameFormat = "extra-disk-%d.img"
+	// The max number of extra disk slots of a template.
+	MaxExtraDiskSlots = 8
 )
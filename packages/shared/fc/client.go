@@ -6,12 +6,11 @@ import (
 	"context"
 	"net"
 	"net/http"
-	"os"
-	"time"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/fc/client"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/fc/client/operations"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/go-openapi/strfmt"
 	"go.opentelemetry.io/otel/attribute"
@@ -71,68 +70,47 @@ func NewFirecrackerAPI(socketPath string) *client.FirecrackerAPI {
 }
 
 // Wait for firecracker socket to be prepared.
-// This function will send get version request to the socket until succeed.
+// When opts.Probe is set, it also sends get version request to the
+// socket until succeed, so that the api is ready to accept requests.
 func WaitForSocket(
 	ctx context.Context,
 	tracer trace.Tracer,
 	socketPath string,
-	timeout time.Duration,
+	opts utils.SocketWaitOptions,
 ) (*client.FirecrackerAPI, error) {
 	childCtx, childSpan := tracer.Start(ctx, "wait-for-fc-socket")
-	childCtx, cancel := context.WithTimeout(childCtx, timeout)
-
-	fileStateTicker := time.NewTicker(10 * time.Millisecond)
+	childCtx, cancel := context.WithTimeout(childCtx, opts.Timeout)
 	defer func() {
 		cancel()
-		fileStateTicker.Stop()
 		childSpan.End()
 	}()
 
-	retryTimes := 0
-checkSocketCreation:
-	for {
-		select {
-		case <-childCtx.Done():
-			return nil, childCtx.Err()
-		case <-fileStateTicker.C:
-			if _, err := os.Stat(socketPath); err != nil {
-				if os.IsNotExist(err) {
-					retryTimes += 1
-					continue
-				}
-				return nil, err
-			}
-			break checkSocketCreation
-		}
+	retryTimes, err := utils.WaitForSocketFile(childCtx, socketPath, opts)
+	if err != nil {
+		return nil, err
 	}
 	telemetry.ReportEvent(childCtx, "fc socket created", attribute.Int("retry_times", retryTimes))
 
 	fcClient := NewFirecrackerAPI(socketPath)
+	if !opts.Probe {
+		return fcClient, nil
+	}
 	param := operations.NewGetFirecrackerVersionParams().WithContext(childCtx)
-	// TODO(huang-jl): use time.After when using golang 1.23 and above?
-	retryTimes = 0
-	interval := 50 * time.Millisecond
-	reqTimer := time.NewTimer(interval)
-	defer reqTimer.Stop()
-	for {
-		if res, err := fcClient.Operations.GetFirecrackerVersion(param); err == nil {
-			telemetry.ReportEvent(
-				childCtx,
-				"fc client get version succeed",
-				attribute.String("fc_version", *res.Payload.FirecrackerVersion),
-				attribute.Int("retry_times", retryTimes),
-			)
-			return fcClient, nil
-		}
-		reqTimer.Reset(interval)
-		select {
-		case <-childCtx.Done():
-			return nil, childCtx.Err()
-		case <-reqTimer.C:
-			if interval < time.Second {
-				interval *= 2
-			}
+	retryTimes, err = utils.PollWithBackoff(childCtx, opts, func() (bool, error) {
+		res, err := fcClient.Operations.GetFirecrackerVersion(param)
+		if err != nil {
+			return false, nil
 		}
-		retryTimes += 1
+		telemetry.ReportEvent(
+			childCtx,
+			"fc client get version succeed",
+			attribute.String("fc_version", *res.Payload.FirecrackerVersion),
+		)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	telemetry.ReportEvent(childCtx, "fc api ready", attribute.Int("retry_times", retryTimes))
+	return fcClient, nil
 }
@@ -32,6 +32,7 @@ const (
 	SandboxState_CLEANNING    SandboxState = 4
 	SandboxState_SNAPSHOTTING SandboxState = 5
 	SandboxState_ORPHAN       SandboxState = 6
+	SandboxState_MIGRATING    SandboxState = 7
 )
 
 // Enum value maps for SandboxState.
@@ -44,6 +45,7 @@ var (
 		4: "CLEANNING",
 		5: "SNAPSHOTTING",
 		6: "ORPHAN",
+		7: "MIGRATING",
 	}
 	SandboxState_value = map[string]int32{
 		"UNSPECIFY":    0,
@@ -53,6 +55,7 @@ var (
 		"CLEANNING":    4,
 		"SNAPSHOTTING": 5,
 		"ORPHAN":       6,
+		"MIGRATING":    7,
 	}
 )
 
@@ -83,6 +86,254 @@ func (SandboxState) EnumDescriptor() ([]byte, []int) {
 	return file_orchestrator_proto_rawDescGZIP(), []int{0}
 }
 
+// The reason of a failed rpc, attached to the grpc status as ErrorDetail
+// (i.e., status.WithDetails), so that clients can decide whether to retry.
+type ErrorReason int32
+
+const (
+	ErrorReason_ERROR_UNSPECIFY ErrorReason = 0
+	// no more network can be allocated for sandboxes, retry later
+	ErrorReason_ERROR_NETWORK_EXHAUSTED ErrorReason = 1
+	// the template does not exist (locally or in the template_source)
+	ErrorReason_ERROR_TEMPLATE_NOT_FOUND ErrorReason = 2
+	// the sandbox is being snapshotted, retry later
+	ErrorReason_ERROR_SNAPSHOT_IN_PROGRESS ErrorReason = 3
+	// the operation is not allowed in the current state of sandbox
+	ErrorReason_ERROR_INVALID_STATE ErrorReason = 4
+	// the host is out of memory, retry later (or on other hosts)
+	ErrorReason_ERROR_HOST_OOM ErrorReason = 5
+	// the extra disk quota of the host is exceeded, retry later (or on other hosts)
+	ErrorReason_ERROR_EXTRA_DISK_QUOTA_EXCEEDED ErrorReason = 6
+	// the free disk space of the host is not enough, retry later (or on other hosts)
+	ErrorReason_ERROR_HOST_DISK_FULL ErrorReason = 7
+	// the max concurrent creates of the host is reached, retry later (or on other hosts)
+	ErrorReason_ERROR_TOO_MANY_CREATES ErrorReason = 8
+)
+
+// Enum value maps for ErrorReason.
+var (
+	ErrorReason_name = map[int32]string{
+		0: "ERROR_UNSPECIFY",
+		1: "ERROR_NETWORK_EXHAUSTED",
+		2: "ERROR_TEMPLATE_NOT_FOUND",
+		3: "ERROR_SNAPSHOT_IN_PROGRESS",
+		4: "ERROR_INVALID_STATE",
+		5: "ERROR_HOST_OOM",
+		6: "ERROR_EXTRA_DISK_QUOTA_EXCEEDED",
+		7: "ERROR_HOST_DISK_FULL",
+		8: "ERROR_TOO_MANY_CREATES",
+	}
+	ErrorReason_value = map[string]int32{
+		"ERROR_UNSPECIFY":                 0,
+		"ERROR_NETWORK_EXHAUSTED":         1,
+		"ERROR_TEMPLATE_NOT_FOUND":        2,
+		"ERROR_SNAPSHOT_IN_PROGRESS":      3,
+		"ERROR_INVALID_STATE":             4,
+		"ERROR_HOST_OOM":                  5,
+		"ERROR_EXTRA_DISK_QUOTA_EXCEEDED": 6,
+		"ERROR_HOST_DISK_FULL":            7,
+		"ERROR_TOO_MANY_CREATES":          8,
+	}
+)
+
+func (x ErrorReason) Enum() *ErrorReason {
+	p := new(ErrorReason)
+	*p = x
+	return p
+}
+
+func (x ErrorReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_orchestrator_proto_enumTypes[1].Descriptor()
+}
+
+func (ErrorReason) Type() protoreflect.EnumType {
+	return &file_orchestrator_proto_enumTypes[1]
+}
+
+func (x ErrorReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorReason.Descriptor instead.
+func (ErrorReason) EnumDescriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{1}
+}
+
+type SandboxEventType int32
+
+const (
+	SandboxEventType_EVENT_UNSPECIFY SandboxEventType = 0
+	// the sandbox is created and running
+	SandboxEventType_EVENT_CREATED SandboxEventType = 1
+	// the sandbox is running again (e.g., resumed after snapshot)
+	SandboxEventType_EVENT_RUNNING      SandboxEventType = 2
+	SandboxEventType_EVENT_SNAPSHOTTING SandboxEventType = 3
+	// the vm is being (or has been) stopped
+	SandboxEventType_EVENT_STOPPED SandboxEventType = 4
+	// the resources of the stopped sandbox are cleaned up, i.e., the last event of sandbox
+	SandboxEventType_EVENT_CLEANED     SandboxEventType = 5
+	SandboxEventType_EVENT_DEACTIVATED SandboxEventType = 6
+)
+
+// Enum value maps for SandboxEventType.
+var (
+	SandboxEventType_name = map[int32]string{
+		0: "EVENT_UNSPECIFY",
+		1: "EVENT_CREATED",
+		2: "EVENT_RUNNING",
+		3: "EVENT_SNAPSHOTTING",
+		4: "EVENT_STOPPED",
+		5: "EVENT_CLEANED",
+		6: "EVENT_DEACTIVATED",
+	}
+	SandboxEventType_value = map[string]int32{
+		"EVENT_UNSPECIFY":    0,
+		"EVENT_CREATED":      1,
+		"EVENT_RUNNING":      2,
+		"EVENT_SNAPSHOTTING": 3,
+		"EVENT_STOPPED":      4,
+		"EVENT_CLEANED":      5,
+		"EVENT_DEACTIVATED":  6,
+	}
+)
+
+func (x SandboxEventType) Enum() *SandboxEventType {
+	p := new(SandboxEventType)
+	*p = x
+	return p
+}
+
+func (x SandboxEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SandboxEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_orchestrator_proto_enumTypes[2].Descriptor()
+}
+
+func (SandboxEventType) Type() protoreflect.EnumType {
+	return &file_orchestrator_proto_enumTypes[2]
+}
+
+func (x SandboxEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SandboxEventType.Descriptor instead.
+func (SandboxEventType) EnumDescriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{2}
+}
+
+type NetworkState int32
+
+const (
+	NetworkState_NETWORK_UNSPECIFY NetworkState = 0
+	NetworkState_NETWORK_INVALID   NetworkState = 1
+	NetworkState_NETWORK_USING     NetworkState = 2
+	NetworkState_NETWORK_FREE      NetworkState = 3
+)
+
+// Enum value maps for NetworkState.
+var (
+	NetworkState_name = map[int32]string{
+		0: "NETWORK_UNSPECIFY",
+		1: "NETWORK_INVALID",
+		2: "NETWORK_USING",
+		3: "NETWORK_FREE",
+	}
+	NetworkState_value = map[string]int32{
+		"NETWORK_UNSPECIFY": 0,
+		"NETWORK_INVALID":   1,
+		"NETWORK_USING":     2,
+		"NETWORK_FREE":      3,
+	}
+)
+
+func (x NetworkState) Enum() *NetworkState {
+	p := new(NetworkState)
+	*p = x
+	return p
+}
+
+func (x NetworkState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NetworkState) Descriptor() protoreflect.EnumDescriptor {
+	return file_orchestrator_proto_enumTypes[3].Descriptor()
+}
+
+func (NetworkState) Type() protoreflect.EnumType {
+	return &file_orchestrator_proto_enumTypes[3]
+}
+
+func (x NetworkState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NetworkState.Descriptor instead.
+func (NetworkState) EnumDescriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{3}
+}
+
+type ErrorDetail struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reason ErrorReason `protobuf:"varint,1,opt,name=reason,proto3,enum=ErrorReason" json:"reason,omitempty"`
+	// whether retrying the same request later may succeed
+	Retryable bool `protobuf:"varint,2,opt,name=retryable,proto3" json:"retryable,omitempty"`
+}
+
+func (x *ErrorDetail) Reset() {
+	*x = ErrorDetail{}
+	mi := &file_orchestrator_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorDetail) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorDetail) ProtoMessage() {}
+
+func (x *ErrorDetail) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorDetail.ProtoReflect.Descriptor instead.
+func (*ErrorDetail) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ErrorDetail) GetReason() ErrorReason {
+	if x != nil {
+		return x.Reason
+	}
+	return ErrorReason_ERROR_UNSPECIFY
+}
+
+func (x *ErrorDetail) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
 // Information returned by List() or Search()
 type SandboxInfo struct {
 	state         protoimpl.MessageState
@@ -100,11 +351,13 @@ type SandboxInfo struct {
 	EnableDiffSnapshots *bool                  `protobuf:"varint,8,opt,name=enableDiffSnapshots,proto3,oneof" json:"enableDiffSnapshots,omitempty"`
 	State               SandboxState           `protobuf:"varint,9,opt,name=state,proto3,enum=SandboxState" json:"state,omitempty"`
 	Metadata            map[string]string      `protobuf:"bytes,10,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// The port forwards of the sandbox, with the host ports allocated.
+	PortForwards []*PortForward `protobuf:"bytes,11,rep,name=portForwards,proto3" json:"portForwards,omitempty"`
 }
 
 func (x *SandboxInfo) Reset() {
 	*x = SandboxInfo{}
-	mi := &file_orchestrator_proto_msgTypes[0]
+	mi := &file_orchestrator_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -116,7 +369,7 @@ func (x *SandboxInfo) String() string {
 func (*SandboxInfo) ProtoMessage() {}
 
 func (x *SandboxInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[0]
+	mi := &file_orchestrator_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -129,7 +382,7 @@ func (x *SandboxInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SandboxInfo.ProtoReflect.Descriptor instead.
 func (*SandboxInfo) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{0}
+	return file_orchestrator_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *SandboxInfo) GetSandboxID() string {
@@ -202,6 +455,13 @@ func (x *SandboxInfo) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *SandboxInfo) GetPortForwards() []*PortForward {
+	if x != nil {
+		return x.PortForwards
+	}
+	return nil
+}
+
 // ================= Create ================= //
 // Data required for creating a new sandbox.
 type SandboxCreateRequest struct {
@@ -211,16 +471,79 @@ type SandboxCreateRequest struct {
 
 	TemplateID string `protobuf:"bytes,1,opt,name=templateID,proto3" json:"templateID,omitempty"`
 	// Maximum length of the instance in Hours
-	MaxInstanceLength    int64             `protobuf:"varint,3,opt,name=maxInstanceLength,proto3" json:"maxInstanceLength,omitempty"`
-	SandboxID            string            `protobuf:"bytes,4,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
-	EnableDiffSnapshots  bool              `protobuf:"varint,5,opt,name=enableDiffSnapshots,proto3" json:"enableDiffSnapshots,omitempty"`
+	MaxInstanceLength int64 `protobuf:"varint,3,opt,name=maxInstanceLength,proto3" json:"maxInstanceLength,omitempty"`
+	// empty means generated by orchestrator (returned in the info of response),
+	// which is unique among the sandboxes of this host.
+	SandboxID           string `protobuf:"bytes,4,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	EnableDiffSnapshots bool   `protobuf:"varint,5,opt,name=enableDiffSnapshots,proto3" json:"enableDiffSnapshots,omitempty"`
+	// Also readable by the guest from mmds (under "custom") with firecracker, and
+	// from sandbox-metadata.json of the config drive if the template enables it.
+	// Cloud hypervisor lacks mmds, so the config drive is required.
 	Metadata             map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	HypervisorBinaryPath *string           `protobuf:"bytes,7,opt,name=hypervisorBinaryPath,proto3,oneof" json:"hypervisorBinaryPath,omitempty"`
+	// The cloud-init user-data exposed to the guest through the config drive.
+	// Only valid when the template enables config_drive.
+	CloudInitUserData *string `protobuf:"bytes,8,opt,name=cloudInitUserData,proto3,oneof" json:"cloudInitUserData,omitempty"`
+	// The limit written into cpu.max of the sandbox cgroup (e.g., "200000 100000"
+	// for 2 cores), by default it is derived from the vcpu count of template.
+	CgroupCpuMax *string `protobuf:"bytes,9,opt,name=cgroupCpuMax,proto3,oneof" json:"cgroupCpuMax,omitempty"`
+	// The limit (in bytes) written into memory.max of the sandbox cgroup,
+	// by default it is derived from the memory size of template.
+	CgroupMemoryMax *int64 `protobuf:"varint,10,opt,name=cgroupMemoryMax,proto3,oneof" json:"cgroupMemoryMax,omitempty"`
+	// The extra (scratch) disks attached to the sandbox, the guest sees them
+	// after the disks of template (rootfs, writable rootfs and config drive)
+	// in the same order. Firecracker requires the template to reserve enough
+	// extra_disk_slots and does not support read-only extra disks.
+	ExtraDisks []*DiskSpec `protobuf:"bytes,11,rep,name=extraDisks,proto3" json:"extraDisks,omitempty"`
+	// The default working dir and env vars of processes spawned by envd (through
+	// /process/create), exposed to the guest through mmds, so only firecracker
+	// is supported. The env vars (and working dir) of each process take
+	// precedence over these, which take precedence over the ones baked into
+	// the template (i.e., inherited from envd). The default working dir is the
+	// home dir of user if not specified.
+	WorkingDir *string           `protobuf:"bytes,12,opt,name=workingDir,proto3,oneof" json:"workingDir,omitempty"`
+	Env        map[string]string `protobuf:"bytes,13,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Restrict the outbound destinations of the sandbox, all are allowed by default.
+	EgressPolicy *EgressPolicy `protobuf:"bytes,14,opt,name=egressPolicy,proto3,oneof" json:"egressPolicy,omitempty"`
+	// Tee the output of vmm (including the serial console of guest) into
+	// console.log under the instance dir, by default it follows console_log
+	// of orchestrator.
+	ConsoleLog *bool `protobuf:"varint,15,opt,name=consoleLog,proto3,oneof" json:"consoleLog,omitempty"`
+	// The mac address (e.g., 02:fd:00:00:00:01) of the guest interface, which is
+	// applied by envd after restored. By default the sandbox keeps the one of
+	// template, unless unique_guest_mac of orchestrator is enabled.
+	GuestMac *string `protobuf:"bytes,16,opt,name=guestMac,proto3,oneof" json:"guestMac,omitempty"`
+	// The memory (in MiB) of the sandbox, by default it is the one of template.
+	// Only cloud hypervisor supports a larger one (up to mem_mb + mem_hotplug_mb
+	// of template), which is hotplugged after restored.
+	MemoryOverrideMB *int64 `protobuf:"varint,17,opt,name=memoryOverrideMB,proto3,oneof" json:"memoryOverrideMB,omitempty"`
+	// Forward the ports of host to the ports of guest (through the private ip
+	// of sandbox), e.g., to reach a service in guest from outside the host.
+	PortForwards []*PortForward `protobuf:"bytes,18,rep,name=portForwards,proto3" json:"portForwards,omitempty"`
+	// Boot the rootfs of template with its kernel args instead of restoring
+	// the snapshot, e.g., to debug a template which fails to restore. It is
+	// much slower, and the guest state (e.g., the processes started by the
+	// start cmd) is not the one in the snapshot.
+	ColdBoot bool `protobuf:"varint,19,opt,name=coldBoot,proto3" json:"coldBoot,omitempty"`
+	// place the cgroup of sandbox under this subtree of the cgroup of orchestrator
+	// (e.g., "team-a" or "team-a/project-b", for the accounting per tenant),
+	// empty means directly under it. The subtree is created if not exists, and
+	// the cgroups created for it are removed once no sandbox is under them.
+	CgroupSubpath string `protobuf:"bytes,20,opt,name=cgroupSubpath,proto3" json:"cgroupSubpath,omitempty"`
+	// Limit the disk io of each drive (in bytes and operations per second),
+	// which overrides the one of template, 0 means the one of template.
+	// Only firecracker can change the limits of the drives restored from snapshot.
+	DiskBandwidthBps int64 `protobuf:"varint,21,opt,name=diskBandwidthBps,proto3" json:"diskBandwidthBps,omitempty"`
+	DiskIops         int64 `protobuf:"varint,22,opt,name=diskIops,proto3" json:"diskIops,omitempty"`
+	// Boot with this kernel image (an absolute path on host) instead of the one
+	// of template, e.g., to try a new kernel on some sandboxes. It requires
+	// coldBoot, as the kernel is restored along with the snapshot.
+	KernelImagePathOverride string `protobuf:"bytes,23,opt,name=kernelImagePathOverride,proto3" json:"kernelImagePathOverride,omitempty"`
 }
 
 func (x *SandboxCreateRequest) Reset() {
 	*x = SandboxCreateRequest{}
-	mi := &file_orchestrator_proto_msgTypes[1]
+	mi := &file_orchestrator_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -232,7 +555,7 @@ func (x *SandboxCreateRequest) String() string {
 func (*SandboxCreateRequest) ProtoMessage() {}
 
 func (x *SandboxCreateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[1]
+	mi := &file_orchestrator_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -245,7 +568,7 @@ func (x *SandboxCreateRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SandboxCreateRequest.ProtoReflect.Descriptor instead.
 func (*SandboxCreateRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{1}
+	return file_orchestrator_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *SandboxCreateRequest) GetTemplateID() string {
@@ -290,30 +613,146 @@ func (x *SandboxCreateRequest) GetHypervisorBinaryPath() string {
 	return ""
 }
 
-// Data about the sandbox.
-type SandboxCreateResponse struct {
+func (x *SandboxCreateRequest) GetCloudInitUserData() string {
+	if x != nil && x.CloudInitUserData != nil {
+		return *x.CloudInitUserData
+	}
+	return ""
+}
+
+func (x *SandboxCreateRequest) GetCgroupCpuMax() string {
+	if x != nil && x.CgroupCpuMax != nil {
+		return *x.CgroupCpuMax
+	}
+	return ""
+}
+
+func (x *SandboxCreateRequest) GetCgroupMemoryMax() int64 {
+	if x != nil && x.CgroupMemoryMax != nil {
+		return *x.CgroupMemoryMax
+	}
+	return 0
+}
+
+func (x *SandboxCreateRequest) GetExtraDisks() []*DiskSpec {
+	if x != nil {
+		return x.ExtraDisks
+	}
+	return nil
+}
+
+func (x *SandboxCreateRequest) GetWorkingDir() string {
+	if x != nil && x.WorkingDir != nil {
+		return *x.WorkingDir
+	}
+	return ""
+}
+
+func (x *SandboxCreateRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+func (x *SandboxCreateRequest) GetEgressPolicy() *EgressPolicy {
+	if x != nil {
+		return x.EgressPolicy
+	}
+	return nil
+}
+
+func (x *SandboxCreateRequest) GetConsoleLog() bool {
+	if x != nil && x.ConsoleLog != nil {
+		return *x.ConsoleLog
+	}
+	return false
+}
+
+func (x *SandboxCreateRequest) GetGuestMac() string {
+	if x != nil && x.GuestMac != nil {
+		return *x.GuestMac
+	}
+	return ""
+}
+
+func (x *SandboxCreateRequest) GetMemoryOverrideMB() int64 {
+	if x != nil && x.MemoryOverrideMB != nil {
+		return *x.MemoryOverrideMB
+	}
+	return 0
+}
+
+func (x *SandboxCreateRequest) GetPortForwards() []*PortForward {
+	if x != nil {
+		return x.PortForwards
+	}
+	return nil
+}
+
+func (x *SandboxCreateRequest) GetColdBoot() bool {
+	if x != nil {
+		return x.ColdBoot
+	}
+	return false
+}
+
+func (x *SandboxCreateRequest) GetCgroupSubpath() string {
+	if x != nil {
+		return x.CgroupSubpath
+	}
+	return ""
+}
+
+func (x *SandboxCreateRequest) GetDiskBandwidthBps() int64 {
+	if x != nil {
+		return x.DiskBandwidthBps
+	}
+	return 0
+}
+
+func (x *SandboxCreateRequest) GetDiskIops() int64 {
+	if x != nil {
+		return x.DiskIops
+	}
+	return 0
+}
+
+func (x *SandboxCreateRequest) GetKernelImagePathOverride() string {
+	if x != nil {
+		return x.KernelImagePathOverride
+	}
+	return ""
+}
+
+// The deny rules are matched first, then if allow is not empty, only the
+// destinations matched by allow are reachable, otherwise all the destinations
+// not denied are reachable. The dns servers of template (port 53) are always
+// reachable.
+type EgressPolicy struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Info *SandboxInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	Allow []*EgressRule `protobuf:"bytes,1,rep,name=allow,proto3" json:"allow,omitempty"`
+	Deny  []*EgressRule `protobuf:"bytes,2,rep,name=deny,proto3" json:"deny,omitempty"`
 }
 
-func (x *SandboxCreateResponse) Reset() {
-	*x = SandboxCreateResponse{}
-	mi := &file_orchestrator_proto_msgTypes[2]
+func (x *EgressPolicy) Reset() {
+	*x = EgressPolicy{}
+	mi := &file_orchestrator_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxCreateResponse) String() string {
+func (x *EgressPolicy) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxCreateResponse) ProtoMessage() {}
+func (*EgressPolicy) ProtoMessage() {}
 
-func (x *SandboxCreateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[2]
+func (x *EgressPolicy) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -324,47 +763,53 @@ func (x *SandboxCreateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxCreateResponse.ProtoReflect.Descriptor instead.
-func (*SandboxCreateResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{2}
+// Deprecated: Use EgressPolicy.ProtoReflect.Descriptor instead.
+func (*EgressPolicy) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *SandboxCreateResponse) GetInfo() *SandboxInfo {
+func (x *EgressPolicy) GetAllow() []*EgressRule {
 	if x != nil {
-		return x.Info
+		return x.Allow
 	}
 	return nil
 }
 
-// ================= List ================= //
-type SandboxListRequest struct {
+func (x *EgressPolicy) GetDeny() []*EgressRule {
+	if x != nil {
+		return x.Deny
+	}
+	return nil
+}
+
+type EgressRule struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// List only orphan sandbox (which not maintained by orchestrator currently)
-	// e.g., previous orchestrator has crashed and left some sandbox.
-	// These sandboxes will only contains the Sandbox and privateIP field.
-	Orphan bool `protobuf:"varint,1,opt,name=orphan,proto3" json:"orphan,omitempty"`
-	// List only running sandboxes.
-	Running bool `protobuf:"varint,2,opt,name=running,proto3" json:"running,omitempty"`
+	// ip or cidr (ipv4 or ipv6) of destination
+	Cidr string `protobuf:"bytes,1,opt,name=cidr,proto3" json:"cidr,omitempty"`
+	// "tcp" or "udp", empty means all protocols
+	Protocol string `protobuf:"bytes,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	// destination port, 0 means all ports (protocol is required if not 0)
+	Port uint32 `protobuf:"varint,3,opt,name=port,proto3" json:"port,omitempty"`
 }
 
-func (x *SandboxListRequest) Reset() {
-	*x = SandboxListRequest{}
-	mi := &file_orchestrator_proto_msgTypes[3]
+func (x *EgressRule) Reset() {
+	*x = EgressRule{}
+	mi := &file_orchestrator_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxListRequest) String() string {
+func (x *EgressRule) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxListRequest) ProtoMessage() {}
+func (*EgressRule) ProtoMessage() {}
 
-func (x *SandboxListRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[3]
+func (x *EgressRule) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -375,49 +820,2882 @@ func (x *SandboxListRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxListRequest.ProtoReflect.Descriptor instead.
-func (*SandboxListRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use EgressRule.ProtoReflect.Descriptor instead.
+func (*EgressRule) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EgressRule) GetCidr() string {
+	if x != nil {
+		return x.Cidr
+	}
+	return ""
+}
+
+func (x *EgressRule) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *EgressRule) GetPort() uint32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+type DiskSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Size of the disk in MiB, it can be 0 when backingFile is specified
+	// (i.e., the size of backing file).
+	SizeMB   int64 `protobuf:"varint,1,opt,name=sizeMB,proto3" json:"sizeMB,omitempty"`
+	ReadOnly bool  `protobuf:"varint,2,opt,name=readOnly,proto3" json:"readOnly,omitempty"`
+	// The (host) file whose copy is used as the content of disk, otherwise
+	// an empty ext4 filesystem is created. It should be under the
+	// extra_disk_backing_dir of orchestrator (a relative path is relative to it).
+	BackingFile string `protobuf:"bytes,3,opt,name=backingFile,proto3" json:"backingFile,omitempty"`
+}
+
+func (x *DiskSpec) Reset() {
+	*x = DiskSpec{}
+	mi := &file_orchestrator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiskSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiskSpec) ProtoMessage() {}
+
+func (x *DiskSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiskSpec.ProtoReflect.Descriptor instead.
+func (*DiskSpec) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DiskSpec) GetSizeMB() int64 {
+	if x != nil {
+		return x.SizeMB
+	}
+	return 0
+}
+
+func (x *DiskSpec) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *DiskSpec) GetBackingFile() string {
+	if x != nil {
+		return x.BackingFile
+	}
+	return ""
+}
+
+type PortForward struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// The port on host, 0 means allocating one from the range
+	// (port_forward_min_port to port_forward_max_port) of orchestrator.
+	HostPort  uint32 `protobuf:"varint,1,opt,name=hostPort,proto3" json:"hostPort,omitempty"`
+	GuestPort uint32 `protobuf:"varint,2,opt,name=guestPort,proto3" json:"guestPort,omitempty"`
+	// "tcp" or "udp", empty means "tcp"
+	Protocol string `protobuf:"bytes,3,opt,name=protocol,proto3" json:"protocol,omitempty"`
+}
+
+func (x *PortForward) Reset() {
+	*x = PortForward{}
+	mi := &file_orchestrator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PortForward) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PortForward) ProtoMessage() {}
+
+func (x *PortForward) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PortForward.ProtoReflect.Descriptor instead.
+func (*PortForward) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PortForward) GetHostPort() uint32 {
+	if x != nil {
+		return x.HostPort
+	}
+	return 0
+}
+
+func (x *PortForward) GetGuestPort() uint32 {
+	if x != nil {
+		return x.GuestPort
+	}
+	return 0
+}
+
+func (x *PortForward) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+// Data about the sandbox.
+type SandboxCreateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Info *SandboxInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+}
+
+func (x *SandboxCreateResponse) Reset() {
+	*x = SandboxCreateResponse{}
+	mi := &file_orchestrator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxCreateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxCreateResponse) ProtoMessage() {}
+
+func (x *SandboxCreateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxCreateResponse.ProtoReflect.Descriptor instead.
+func (*SandboxCreateResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SandboxCreateResponse) GetInfo() *SandboxInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+// ================= CreateBatch ================= //
+// Create multiple sandboxes from the same template.
+type SandboxCreateBatchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TemplateID string `protobuf:"bytes,1,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	// The number of sandboxes to create.
+	Count int64 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	// The id of i-th sandbox is `${sandboxIDPrefix}-${i}`.
+	SandboxIDPrefix string `protobuf:"bytes,3,opt,name=sandboxIDPrefix,proto3" json:"sandboxIDPrefix,omitempty"`
+	// Maximum length of the instance in Hours
+	MaxInstanceLength   int64 `protobuf:"varint,4,opt,name=maxInstanceLength,proto3" json:"maxInstanceLength,omitempty"`
+	EnableDiffSnapshots bool  `protobuf:"varint,5,opt,name=enableDiffSnapshots,proto3" json:"enableDiffSnapshots,omitempty"`
+	// The metadata attached to every sandbox.
+	Metadata             map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	HypervisorBinaryPath *string           `protobuf:"bytes,7,opt,name=hypervisorBinaryPath,proto3,oneof" json:"hypervisorBinaryPath,omitempty"`
+	// All-or-nothing: when any sandbox fails to create, the successfully
+	// created ones will be deleted.
+	Atomic bool `protobuf:"varint,8,opt,name=atomic,proto3" json:"atomic,omitempty"`
+}
+
+func (x *SandboxCreateBatchRequest) Reset() {
+	*x = SandboxCreateBatchRequest{}
+	mi := &file_orchestrator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxCreateBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxCreateBatchRequest) ProtoMessage() {}
+
+func (x *SandboxCreateBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxCreateBatchRequest.ProtoReflect.Descriptor instead.
+func (*SandboxCreateBatchRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SandboxCreateBatchRequest) GetTemplateID() string {
+	if x != nil {
+		return x.TemplateID
+	}
+	return ""
+}
+
+func (x *SandboxCreateBatchRequest) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *SandboxCreateBatchRequest) GetSandboxIDPrefix() string {
+	if x != nil {
+		return x.SandboxIDPrefix
+	}
+	return ""
+}
+
+func (x *SandboxCreateBatchRequest) GetMaxInstanceLength() int64 {
+	if x != nil {
+		return x.MaxInstanceLength
+	}
+	return 0
+}
+
+func (x *SandboxCreateBatchRequest) GetEnableDiffSnapshots() bool {
+	if x != nil {
+		return x.EnableDiffSnapshots
+	}
+	return false
+}
+
+func (x *SandboxCreateBatchRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SandboxCreateBatchRequest) GetHypervisorBinaryPath() string {
+	if x != nil && x.HypervisorBinaryPath != nil {
+		return *x.HypervisorBinaryPath
+	}
+	return ""
+}
+
+func (x *SandboxCreateBatchRequest) GetAtomic() bool {
+	if x != nil {
+		return x.Atomic
+	}
+	return false
+}
+
+type SandboxCreateBatchItem struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// Only set when the sandbox is created (and not rolled back).
+	Info *SandboxInfo `protobuf:"bytes,2,opt,name=info,proto3,oneof" json:"info,omitempty"`
+	// Empty means success.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *SandboxCreateBatchItem) Reset() {
+	*x = SandboxCreateBatchItem{}
+	mi := &file_orchestrator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxCreateBatchItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxCreateBatchItem) ProtoMessage() {}
+
+func (x *SandboxCreateBatchItem) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxCreateBatchItem.ProtoReflect.Descriptor instead.
+func (*SandboxCreateBatchItem) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SandboxCreateBatchItem) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxCreateBatchItem) GetInfo() *SandboxInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *SandboxCreateBatchItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SandboxCreateBatchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Items []*SandboxCreateBatchItem `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (x *SandboxCreateBatchResponse) Reset() {
+	*x = SandboxCreateBatchResponse{}
+	mi := &file_orchestrator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxCreateBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxCreateBatchResponse) ProtoMessage() {}
+
+func (x *SandboxCreateBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxCreateBatchResponse.ProtoReflect.Descriptor instead.
+func (*SandboxCreateBatchResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SandboxCreateBatchResponse) GetItems() []*SandboxCreateBatchItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// ================= List ================= //
+type SandboxListRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// List only orphan sandbox (which not maintained by orchestrator currently)
+	// e.g., previous orchestrator has crashed and left some sandbox.
+	// These sandboxes will only contains the Sandbox and privateIP field.
+	Orphan bool `protobuf:"varint,1,opt,name=orphan,proto3" json:"orphan,omitempty"`
+	// List only running sandboxes.
+	Running bool `protobuf:"varint,2,opt,name=running,proto3" json:"running,omitempty"`
+	// List only sandboxes created from this template.
+	TemplateID *string `protobuf:"bytes,3,opt,name=templateID,proto3,oneof" json:"templateID,omitempty"`
+	// List only sandboxes whose metadata contains all of these key/value pairs.
+	// Note that all filters (including running) must match.
+	MetadataSelector map[string]string `protobuf:"bytes,4,rep,name=metadataSelector,proto3" json:"metadataSelector,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *SandboxListRequest) Reset() {
+	*x = SandboxListRequest{}
+	mi := &file_orchestrator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxListRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxListRequest) ProtoMessage() {}
+
+func (x *SandboxListRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxListRequest.ProtoReflect.Descriptor instead.
+func (*SandboxListRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SandboxListRequest) GetOrphan() bool {
+	if x != nil {
+		return x.Orphan
+	}
+	return false
+}
+
+func (x *SandboxListRequest) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *SandboxListRequest) GetTemplateID() string {
+	if x != nil && x.TemplateID != nil {
+		return *x.TemplateID
+	}
+	return ""
+}
+
+func (x *SandboxListRequest) GetMetadataSelector() map[string]string {
+	if x != nil {
+		return x.MetadataSelector
+	}
+	return nil
+}
+
+// Data returned after listing all the sandboxes.
+type SandboxListResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sandboxes []*SandboxInfo `protobuf:"bytes,1,rep,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+}
+
+func (x *SandboxListResponse) Reset() {
+	*x = SandboxListResponse{}
+	mi := &file_orchestrator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxListResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxListResponse) ProtoMessage() {}
+
+func (x *SandboxListResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxListResponse.ProtoReflect.Descriptor instead.
+func (*SandboxListResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SandboxListResponse) GetSandboxes() []*SandboxInfo {
+	if x != nil {
+		return x.Sandboxes
+	}
+	return nil
+}
+
+// ================= Delete ================= //
+type SandboxDeleteRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxDeleteRequest) Reset() {
+	*x = SandboxDeleteRequest{}
+	mi := &file_orchestrator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxDeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxDeleteRequest) ProtoMessage() {}
+
+func (x *SandboxDeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxDeleteRequest.ProtoReflect.Descriptor instead.
+func (*SandboxDeleteRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SandboxDeleteRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+// ================= Deactivate ================= //
+type SandboxDeactivateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxDeactivateRequest) Reset() {
+	*x = SandboxDeactivateRequest{}
+	mi := &file_orchestrator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxDeactivateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxDeactivateRequest) ProtoMessage() {}
+
+func (x *SandboxDeactivateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxDeactivateRequest.ProtoReflect.Descriptor instead.
+func (*SandboxDeactivateRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SandboxDeactivateRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+// ================= Search ================= //
+type SandboxSearchRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxSearchRequest) Reset() {
+	*x = SandboxSearchRequest{}
+	mi := &file_orchestrator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSearchRequest) ProtoMessage() {}
+
+func (x *SandboxSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSearchRequest.ProtoReflect.Descriptor instead.
+func (*SandboxSearchRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SandboxSearchRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+type SandboxSearchResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sandbox *SandboxInfo `protobuf:"bytes,1,opt,name=sandbox,proto3,oneof" json:"sandbox,omitempty"`
+}
+
+func (x *SandboxSearchResponse) Reset() {
+	*x = SandboxSearchResponse{}
+	mi := &file_orchestrator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSearchResponse) ProtoMessage() {}
+
+func (x *SandboxSearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSearchResponse.ProtoReflect.Descriptor instead.
+func (*SandboxSearchResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *SandboxSearchResponse) GetSandbox() *SandboxInfo {
+	if x != nil {
+		return x.Sandbox
+	}
+	return nil
+}
+
+// ================= Snapshot ================= //
+type SandboxSnapshotRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// Whether to delete the sandbox after snapshotting.
+	Delete bool `protobuf:"varint,2,opt,name=delete,proto3" json:"delete,omitempty"`
+	// (optional) the dir to write the snapshot into (created if not exists),
+	// which should be under data_root, snapshot_root or one of
+	// snapshot_destination_roots. Default to the instance snapshot dir.
+	DestinationDir string `protobuf:"bytes,3,opt,name=destinationDir,proto3" json:"destinationDir,omitempty"`
+}
+
+func (x *SandboxSnapshotRequest) Reset() {
+	*x = SandboxSnapshotRequest{}
+	mi := &file_orchestrator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSnapshotRequest) ProtoMessage() {}
+
+func (x *SandboxSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*SandboxSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SandboxSnapshotRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxSnapshotRequest) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+func (x *SandboxSnapshotRequest) GetDestinationDir() string {
+	if x != nil {
+		return x.DestinationDir
+	}
+	return ""
+}
+
+type SandboxSnapshotResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the path where contains the snapshot files, along with snapshot-meta.json
+	// describing the sandbox (e.g., the source template, vcpu and memory).
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *SandboxSnapshotResponse) Reset() {
+	*x = SandboxSnapshotResponse{}
+	mi := &file_orchestrator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSnapshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSnapshotResponse) ProtoMessage() {}
+
+func (x *SandboxSnapshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSnapshotResponse.ProtoReflect.Descriptor instead.
+func (*SandboxSnapshotResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SandboxSnapshotResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// ================= SnapshotAsTemplate ================= //
+type SandboxSnapshotAsTemplateRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// The id of the new template, must not exist.
+	TemplateID string `protobuf:"bytes,2,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	// Whether to delete the sandbox after snapshotting.
+	Delete bool `protobuf:"varint,3,opt,name=delete,proto3" json:"delete,omitempty"`
+}
+
+func (x *SandboxSnapshotAsTemplateRequest) Reset() {
+	*x = SandboxSnapshotAsTemplateRequest{}
+	mi := &file_orchestrator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSnapshotAsTemplateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSnapshotAsTemplateRequest) ProtoMessage() {}
+
+func (x *SandboxSnapshotAsTemplateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSnapshotAsTemplateRequest.ProtoReflect.Descriptor instead.
+func (*SandboxSnapshotAsTemplateRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SandboxSnapshotAsTemplateRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxSnapshotAsTemplateRequest) GetTemplateID() string {
+	if x != nil {
+		return x.TemplateID
+	}
+	return ""
+}
+
+func (x *SandboxSnapshotAsTemplateRequest) GetDelete() bool {
+	if x != nil {
+		return x.Delete
+	}
+	return false
+}
+
+type SandboxSnapshotAsTemplateResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TemplateID string `protobuf:"bytes,1,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	// the template dir of the new template.
+	Path string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *SandboxSnapshotAsTemplateResponse) Reset() {
+	*x = SandboxSnapshotAsTemplateResponse{}
+	mi := &file_orchestrator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSnapshotAsTemplateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSnapshotAsTemplateResponse) ProtoMessage() {}
+
+func (x *SandboxSnapshotAsTemplateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSnapshotAsTemplateResponse.ProtoReflect.Descriptor instead.
+func (*SandboxSnapshotAsTemplateResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SandboxSnapshotAsTemplateResponse) GetTemplateID() string {
+	if x != nil {
+		return x.TemplateID
+	}
+	return ""
+}
+
+func (x *SandboxSnapshotAsTemplateResponse) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// ================= PendingLogs ================= //
+type SandboxPendingLogsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxPendingLogsRequest) Reset() {
+	*x = SandboxPendingLogsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxPendingLogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxPendingLogsRequest) ProtoMessage() {}
+
+func (x *SandboxPendingLogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxPendingLogsRequest.ProtoReflect.Descriptor instead.
+func (*SandboxPendingLogsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *SandboxPendingLogsRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+// The logs buffered in guest (by envd) which have not been
+// delivered to the log collector.
+type SandboxPendingLogsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PendingEntries int64 `protobuf:"varint,1,opt,name=pendingEntries,proto3" json:"pendingEntries,omitempty"`
+	PendingBytes   int64 `protobuf:"varint,2,opt,name=pendingBytes,proto3" json:"pendingBytes,omitempty"`
+	// The logs discarded as the guest buffer is full.
+	DroppedEntries int64 `protobuf:"varint,3,opt,name=droppedEntries,proto3" json:"droppedEntries,omitempty"`
+	// The last error when delivering logs, empty if the last delivery succeeded.
+	LastError string `protobuf:"bytes,4,opt,name=lastError,proto3" json:"lastError,omitempty"`
+}
+
+func (x *SandboxPendingLogsResponse) Reset() {
+	*x = SandboxPendingLogsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxPendingLogsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxPendingLogsResponse) ProtoMessage() {}
+
+func (x *SandboxPendingLogsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxPendingLogsResponse.ProtoReflect.Descriptor instead.
+func (*SandboxPendingLogsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SandboxPendingLogsResponse) GetPendingEntries() int64 {
+	if x != nil {
+		return x.PendingEntries
+	}
+	return 0
+}
+
+func (x *SandboxPendingLogsResponse) GetPendingBytes() int64 {
+	if x != nil {
+		return x.PendingBytes
+	}
+	return 0
+}
+
+func (x *SandboxPendingLogsResponse) GetDroppedEntries() int64 {
+	if x != nil {
+		return x.DroppedEntries
+	}
+	return 0
+}
+
+func (x *SandboxPendingLogsResponse) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+// ================= SetMetadata ================= //
+type SandboxSetMetadataRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string            `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	Metadata  map[string]string `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// replace the whole metadata, by default the metadata is merged
+	// into the existing one.
+	Replace bool `protobuf:"varint,3,opt,name=replace,proto3" json:"replace,omitempty"`
+}
+
+func (x *SandboxSetMetadataRequest) Reset() {
+	*x = SandboxSetMetadataRequest{}
+	mi := &file_orchestrator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSetMetadataRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSetMetadataRequest) ProtoMessage() {}
+
+func (x *SandboxSetMetadataRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSetMetadataRequest.ProtoReflect.Descriptor instead.
+func (*SandboxSetMetadataRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SandboxSetMetadataRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxSetMetadataRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *SandboxSetMetadataRequest) GetReplace() bool {
+	if x != nil {
+		return x.Replace
+	}
+	return false
+}
+
+type SandboxSetMetadataResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Metadata map[string]string `protobuf:"bytes,1,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *SandboxSetMetadataResponse) Reset() {
+	*x = SandboxSetMetadataResponse{}
+	mi := &file_orchestrator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSetMetadataResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSetMetadataResponse) ProtoMessage() {}
+
+func (x *SandboxSetMetadataResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSetMetadataResponse.ProtoReflect.Descriptor instead.
+func (*SandboxSetMetadataResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SandboxSetMetadataResponse) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+// ================= SyncClock ================= //
+type SandboxSyncClockRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxSyncClockRequest) Reset() {
+	*x = SandboxSyncClockRequest{}
+	mi := &file_orchestrator_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxSyncClockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxSyncClockRequest) ProtoMessage() {}
+
+func (x *SandboxSyncClockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxSyncClockRequest.ProtoReflect.Descriptor instead.
+func (*SandboxSyncClockRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SandboxSyncClockRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+// ================= RefreshPrometheusTarget ================= //
+type SandboxRefreshPrometheusTargetRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxRefreshPrometheusTargetRequest) Reset() {
+	*x = SandboxRefreshPrometheusTargetRequest{}
+	mi := &file_orchestrator_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxRefreshPrometheusTargetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxRefreshPrometheusTargetRequest) ProtoMessage() {}
+
+func (x *SandboxRefreshPrometheusTargetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxRefreshPrometheusTargetRequest.ProtoReflect.Descriptor instead.
+func (*SandboxRefreshPrometheusTargetRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SandboxRefreshPrometheusTargetRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+// ================= RecreateNetwork ================= //
+type SandboxRecreateNetworkRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+}
+
+func (x *SandboxRecreateNetworkRequest) Reset() {
+	*x = SandboxRecreateNetworkRequest{}
+	mi := &file_orchestrator_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxRecreateNetworkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxRecreateNetworkRequest) ProtoMessage() {}
+
+func (x *SandboxRecreateNetworkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxRecreateNetworkRequest.ProtoReflect.Descriptor instead.
+func (*SandboxRecreateNetworkRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SandboxRecreateNetworkRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+// ================= Balloon ================= //
+type SandboxBalloonRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// the target size of the balloon in MiB, must be less than the memory of sandbox
+	SizeMiB int64 `protobuf:"varint,2,opt,name=sizeMiB,proto3" json:"sizeMiB,omitempty"`
+}
+
+func (x *SandboxBalloonRequest) Reset() {
+	*x = SandboxBalloonRequest{}
+	mi := &file_orchestrator_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxBalloonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxBalloonRequest) ProtoMessage() {}
+
+func (x *SandboxBalloonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxBalloonRequest.ProtoReflect.Descriptor instead.
+func (*SandboxBalloonRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *SandboxBalloonRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxBalloonRequest) GetSizeMiB() int64 {
+	if x != nil {
+		return x.SizeMiB
+	}
+	return 0
+}
+
+type SandboxBalloonResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the target size of the balloon in MiB after set
+	SizeMiB int64 `protobuf:"varint,2,opt,name=sizeMiB,proto3" json:"sizeMiB,omitempty"`
+}
+
+func (x *SandboxBalloonResponse) Reset() {
+	*x = SandboxBalloonResponse{}
+	mi := &file_orchestrator_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxBalloonResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxBalloonResponse) ProtoMessage() {}
+
+func (x *SandboxBalloonResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxBalloonResponse.ProtoReflect.Descriptor instead.
+func (*SandboxBalloonResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SandboxBalloonResponse) GetSizeMiB() int64 {
+	if x != nil {
+		return x.SizeMiB
+	}
+	return 0
+}
+
+// ================= MemoryConsumption ================= //
+type SandboxMemoryConsumptionRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the sandboxes to measure, empty means all sandboxes
+	SandboxIDs []string `protobuf:"bytes,1,rep,name=sandboxIDs,proto3" json:"sandboxIDs,omitempty"`
+}
+
+func (x *SandboxMemoryConsumptionRequest) Reset() {
+	*x = SandboxMemoryConsumptionRequest{}
+	mi := &file_orchestrator_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxMemoryConsumptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxMemoryConsumptionRequest) ProtoMessage() {}
+
+func (x *SandboxMemoryConsumptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxMemoryConsumptionRequest.ProtoReflect.Descriptor instead.
+func (*SandboxMemoryConsumptionRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *SandboxMemoryConsumptionRequest) GetSandboxIDs() []string {
+	if x != nil {
+		return x.SandboxIDs
+	}
+	return nil
+}
+
+type SandboxMemoryConsumption struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// the memory charged to the cgroup of sandbox on host (i.e., memory.current)
+	HostMemoryBytes int64 `protobuf:"varint,2,opt,name=hostMemoryBytes,proto3" json:"hostMemoryBytes,omitempty"`
+}
+
+func (x *SandboxMemoryConsumption) Reset() {
+	*x = SandboxMemoryConsumption{}
+	mi := &file_orchestrator_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxMemoryConsumption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxMemoryConsumption) ProtoMessage() {}
+
+func (x *SandboxMemoryConsumption) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxMemoryConsumption.ProtoReflect.Descriptor instead.
+func (*SandboxMemoryConsumption) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SandboxMemoryConsumption) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxMemoryConsumption) GetHostMemoryBytes() int64 {
+	if x != nil {
+		return x.HostMemoryBytes
+	}
+	return 0
+}
+
+type SandboxMemoryConsumptionResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Sandboxes []*SandboxMemoryConsumption `protobuf:"bytes,1,rep,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+}
+
+func (x *SandboxMemoryConsumptionResponse) Reset() {
+	*x = SandboxMemoryConsumptionResponse{}
+	mi := &file_orchestrator_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxMemoryConsumptionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxMemoryConsumptionResponse) ProtoMessage() {}
+
+func (x *SandboxMemoryConsumptionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxMemoryConsumptionResponse.ProtoReflect.Descriptor instead.
+func (*SandboxMemoryConsumptionResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SandboxMemoryConsumptionResponse) GetSandboxes() []*SandboxMemoryConsumption {
+	if x != nil {
+		return x.Sandboxes
+	}
+	return nil
+}
+
+// ================= Purge ================= //
+// See note of rpc Purge below
+type SandboxPurgeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// purge all orphan sandboxes, when specify this option
+	// the sandboxIDs will be omitted.
+	PurgeAll   bool     `protobuf:"varint,1,opt,name=purgeAll,proto3" json:"purgeAll,omitempty"`
+	SandboxIDs []string `protobuf:"bytes,2,rep,name=SandboxIDs,proto3" json:"SandboxIDs,omitempty"`
+}
+
+func (x *SandboxPurgeRequest) Reset() {
+	*x = SandboxPurgeRequest{}
+	mi := &file_orchestrator_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxPurgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxPurgeRequest) ProtoMessage() {}
+
+func (x *SandboxPurgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxPurgeRequest.ProtoReflect.Descriptor instead.
+func (*SandboxPurgeRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *SandboxPurgeRequest) GetPurgeAll() bool {
+	if x != nil {
+		return x.PurgeAll
+	}
+	return false
+}
+
+func (x *SandboxPurgeRequest) GetSandboxIDs() []string {
+	if x != nil {
+		return x.SandboxIDs
+	}
+	return nil
+}
+
+// ================= PurgeFailed ================= //
+type SandboxPurgeFailedRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// purge the failed sandboxes with these ids, empty means all of them
+	SandboxIDs []string `protobuf:"bytes,1,rep,name=sandboxIDs,proto3" json:"sandboxIDs,omitempty"`
+}
+
+func (x *SandboxPurgeFailedRequest) Reset() {
+	*x = SandboxPurgeFailedRequest{}
+	mi := &file_orchestrator_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxPurgeFailedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxPurgeFailedRequest) ProtoMessage() {}
+
+func (x *SandboxPurgeFailedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxPurgeFailedRequest.ProtoReflect.Descriptor instead.
+func (*SandboxPurgeFailedRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *SandboxPurgeFailedRequest) GetSandboxIDs() []string {
+	if x != nil {
+		return x.SandboxIDs
+	}
+	return nil
+}
+
+type SandboxPurgeFailedResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the failed sandboxes purged
+	SandboxIDs []string `protobuf:"bytes,1,rep,name=sandboxIDs,proto3" json:"sandboxIDs,omitempty"`
+}
+
+func (x *SandboxPurgeFailedResponse) Reset() {
+	*x = SandboxPurgeFailedResponse{}
+	mi := &file_orchestrator_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxPurgeFailedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxPurgeFailedResponse) ProtoMessage() {}
+
+func (x *SandboxPurgeFailedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxPurgeFailedResponse.ProtoReflect.Descriptor instead.
+func (*SandboxPurgeFailedResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *SandboxPurgeFailedResponse) GetSandboxIDs() []string {
+	if x != nil {
+		return x.SandboxIDs
+	}
+	return nil
+}
+
+type SandboxAdoptOrphansRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// adopt the orphan sandboxes with these ids, empty means all of them
+	SandboxIDs []string `protobuf:"bytes,1,rep,name=sandboxIDs,proto3" json:"sandboxIDs,omitempty"`
+}
+
+func (x *SandboxAdoptOrphansRequest) Reset() {
+	*x = SandboxAdoptOrphansRequest{}
+	mi := &file_orchestrator_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxAdoptOrphansRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxAdoptOrphansRequest) ProtoMessage() {}
+
+func (x *SandboxAdoptOrphansRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxAdoptOrphansRequest.ProtoReflect.Descriptor instead.
+func (*SandboxAdoptOrphansRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *SandboxAdoptOrphansRequest) GetSandboxIDs() []string {
+	if x != nil {
+		return x.SandboxIDs
+	}
+	return nil
+}
+
+type SandboxAdoptOrphanSkipped struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// why the orphan cannot be adopted (it is left as orphan)
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *SandboxAdoptOrphanSkipped) Reset() {
+	*x = SandboxAdoptOrphanSkipped{}
+	mi := &file_orchestrator_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxAdoptOrphanSkipped) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxAdoptOrphanSkipped) ProtoMessage() {}
+
+func (x *SandboxAdoptOrphanSkipped) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxAdoptOrphanSkipped.ProtoReflect.Descriptor instead.
+func (*SandboxAdoptOrphanSkipped) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SandboxAdoptOrphanSkipped) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxAdoptOrphanSkipped) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type SandboxAdoptOrphansResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Adopted []*SandboxInfo               `protobuf:"bytes,1,rep,name=adopted,proto3" json:"adopted,omitempty"`
+	Skipped []*SandboxAdoptOrphanSkipped `protobuf:"bytes,2,rep,name=skipped,proto3" json:"skipped,omitempty"`
+}
+
+func (x *SandboxAdoptOrphansResponse) Reset() {
+	*x = SandboxAdoptOrphansResponse{}
+	mi := &file_orchestrator_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxAdoptOrphansResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxAdoptOrphansResponse) ProtoMessage() {}
+
+func (x *SandboxAdoptOrphansResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxAdoptOrphansResponse.ProtoReflect.Descriptor instead.
+func (*SandboxAdoptOrphansResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SandboxAdoptOrphansResponse) GetAdopted() []*SandboxInfo {
+	if x != nil {
+		return x.Adopted
+	}
+	return nil
+}
+
+func (x *SandboxAdoptOrphansResponse) GetSkipped() []*SandboxAdoptOrphanSkipped {
+	if x != nil {
+		return x.Skipped
+	}
+	return nil
+}
+
+// Live migration (cloud hypervisor only), see rpc MigrateSend below.
+type SandboxMigrateSendRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// where the receiver listens, i.e., unix:/path/to/socket
+	DestinationUrl string `protobuf:"bytes,2,opt,name=destinationUrl,proto3" json:"destinationUrl,omitempty"`
+}
+
+func (x *SandboxMigrateSendRequest) Reset() {
+	*x = SandboxMigrateSendRequest{}
+	mi := &file_orchestrator_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxMigrateSendRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxMigrateSendRequest) ProtoMessage() {}
+
+func (x *SandboxMigrateSendRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxMigrateSendRequest.ProtoReflect.Descriptor instead.
+func (*SandboxMigrateSendRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *SandboxMigrateSendRequest) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxMigrateSendRequest) GetDestinationUrl() string {
+	if x != nil {
+		return x.DestinationUrl
+	}
+	return ""
+}
+
+type SandboxMigrateReceiveRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the sandbox to receive, which should be the same as the one creating the
+	// sandbox on the source host (e.g., sandboxID, templateID and extraDisks)
+	Sandbox *SandboxCreateRequest `protobuf:"bytes,1,opt,name=sandbox,proto3" json:"sandbox,omitempty"`
+	// where the vmm listens for the migration, i.e., unix:/path/to/socket
+	ReceiverUrl string `protobuf:"bytes,2,opt,name=receiverUrl,proto3" json:"receiverUrl,omitempty"`
+}
+
+func (x *SandboxMigrateReceiveRequest) Reset() {
+	*x = SandboxMigrateReceiveRequest{}
+	mi := &file_orchestrator_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxMigrateReceiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxMigrateReceiveRequest) ProtoMessage() {}
+
+func (x *SandboxMigrateReceiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxMigrateReceiveRequest.ProtoReflect.Descriptor instead.
+func (*SandboxMigrateReceiveRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *SandboxMigrateReceiveRequest) GetSandbox() *SandboxCreateRequest {
+	if x != nil {
+		return x.Sandbox
+	}
+	return nil
+}
+
+func (x *SandboxMigrateReceiveRequest) GetReceiverUrl() string {
+	if x != nil {
+		return x.ReceiverUrl
+	}
+	return ""
+}
+
+// Note that all filters must match.
+type SandboxWatchEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Watch only the events of this sandbox.
+	SandboxID *string `protobuf:"bytes,1,opt,name=sandboxID,proto3,oneof" json:"sandboxID,omitempty"`
+	// Watch only the events of sandboxes created from this template.
+	TemplateID *string `protobuf:"bytes,2,opt,name=templateID,proto3,oneof" json:"templateID,omitempty"`
+}
+
+func (x *SandboxWatchEventsRequest) Reset() {
+	*x = SandboxWatchEventsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxWatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxWatchEventsRequest) ProtoMessage() {}
+
+func (x *SandboxWatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxWatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*SandboxWatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SandboxWatchEventsRequest) GetSandboxID() string {
+	if x != nil && x.SandboxID != nil {
+		return *x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxWatchEventsRequest) GetTemplateID() string {
+	if x != nil && x.TemplateID != nil {
+		return *x.TemplateID
+	}
+	return ""
+}
+
+type SandboxEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Type       SandboxEventType `protobuf:"varint,1,opt,name=type,proto3,enum=SandboxEventType" json:"type,omitempty"`
+	SandboxID  string           `protobuf:"bytes,2,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	TemplateID string           `protobuf:"bytes,3,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	// the state of sandbox when the event happens
+	State SandboxState           `protobuf:"varint,4,opt,name=state,proto3,enum=SandboxState" json:"state,omitempty"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=time,proto3" json:"time,omitempty"`
+	// The number of events (of this stream) dropped before this one, as the
+	// client does not receive them in time.
+	Dropped int64 `protobuf:"varint,6,opt,name=dropped,proto3" json:"dropped,omitempty"`
+}
+
+func (x *SandboxEvent) Reset() {
+	*x = SandboxEvent{}
+	mi := &file_orchestrator_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SandboxEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SandboxEvent) ProtoMessage() {}
+
+func (x *SandboxEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SandboxEvent.ProtoReflect.Descriptor instead.
+func (*SandboxEvent) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *SandboxEvent) GetType() SandboxEventType {
+	if x != nil {
+		return x.Type
+	}
+	return SandboxEventType_EVENT_UNSPECIFY
+}
+
+func (x *SandboxEvent) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *SandboxEvent) GetTemplateID() string {
+	if x != nil {
+		return x.TemplateID
+	}
+	return ""
+}
+
+func (x *SandboxEvent) GetState() SandboxState {
+	if x != nil {
+		return x.State
+	}
+	return SandboxState_UNSPECIFY
+}
+
+func (x *SandboxEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *SandboxEvent) GetDropped() int64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type HostManageCleanNetworkEnvRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NetworkIDs []int64 `protobuf:"varint,1,rep,packed,name=NetworkIDs,proto3" json:"NetworkIDs,omitempty"`
+}
+
+func (x *HostManageCleanNetworkEnvRequest) Reset() {
+	*x = HostManageCleanNetworkEnvRequest{}
+	mi := &file_orchestrator_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageCleanNetworkEnvRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageCleanNetworkEnvRequest) ProtoMessage() {}
+
+func (x *HostManageCleanNetworkEnvRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageCleanNetworkEnvRequest.ProtoReflect.Descriptor instead.
+func (*HostManageCleanNetworkEnvRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *HostManageCleanNetworkEnvRequest) GetNetworkIDs() []int64 {
+	if x != nil {
+		return x.NetworkIDs
+	}
+	return nil
+}
+
+// Information of a network env returned by ListNetworks()
+type NetworkInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	NetworkIdx int64        `protobuf:"varint,1,opt,name=networkIdx,proto3" json:"networkIdx,omitempty"`
+	State      NetworkState `protobuf:"varint,2,opt,name=state,proto3,enum=NetworkState" json:"state,omitempty"`
+	// empty if the network is not used by any sandbox
+	SandboxID    string `protobuf:"bytes,3,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	NetNsName    string `protobuf:"bytes,4,opt,name=netNsName,proto3" json:"netNsName,omitempty"`
+	VethIP       string `protobuf:"bytes,5,opt,name=vethIP,proto3" json:"vethIP,omitempty"`
+	VpeerIP      string `protobuf:"bytes,6,opt,name=vpeerIP,proto3" json:"vpeerIP,omitempty"`
+	HostClonedIP string `protobuf:"bytes,7,opt,name=hostClonedIP,proto3" json:"hostClonedIP,omitempty"`
+	// empty if ipv6 is disabled
+	HostClonedIPv6 string `protobuf:"bytes,8,opt,name=hostClonedIPv6,proto3" json:"hostClonedIPv6,omitempty"`
+}
+
+func (x *NetworkInfo) Reset() {
+	*x = NetworkInfo{}
+	mi := &file_orchestrator_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkInfo) ProtoMessage() {}
+
+func (x *NetworkInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkInfo.ProtoReflect.Descriptor instead.
+func (*NetworkInfo) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *NetworkInfo) GetNetworkIdx() int64 {
+	if x != nil {
+		return x.NetworkIdx
+	}
+	return 0
+}
+
+func (x *NetworkInfo) GetState() NetworkState {
+	if x != nil {
+		return x.State
+	}
+	return NetworkState_NETWORK_UNSPECIFY
+}
+
+func (x *NetworkInfo) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *NetworkInfo) GetNetNsName() string {
+	if x != nil {
+		return x.NetNsName
+	}
+	return ""
+}
+
+func (x *NetworkInfo) GetVethIP() string {
+	if x != nil {
+		return x.VethIP
+	}
+	return ""
+}
+
+func (x *NetworkInfo) GetVpeerIP() string {
+	if x != nil {
+		return x.VpeerIP
+	}
+	return ""
+}
+
+func (x *NetworkInfo) GetHostClonedIP() string {
+	if x != nil {
+		return x.HostClonedIP
+	}
+	return ""
+}
+
+func (x *NetworkInfo) GetHostClonedIPv6() string {
+	if x != nil {
+		return x.HostClonedIPv6
+	}
+	return ""
+}
+
+type HostManageListNetworksResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Networks []*NetworkInfo `protobuf:"bytes,1,rep,name=networks,proto3" json:"networks,omitempty"`
+}
+
+func (x *HostManageListNetworksResponse) Reset() {
+	*x = HostManageListNetworksResponse{}
+	mi := &file_orchestrator_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageListNetworksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageListNetworksResponse) ProtoMessage() {}
+
+func (x *HostManageListNetworksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageListNetworksResponse.ProtoReflect.Descriptor instead.
+func (*HostManageListNetworksResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *HostManageListNetworksResponse) GetNetworks() []*NetworkInfo {
+	if x != nil {
+		return x.Networks
+	}
+	return nil
+}
+
+type HostManageHealthResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// false if any problem is found (see problems)
+	Healthy   bool  `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Sandboxes int64 `protobuf:"varint,2,opt,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+	// the network envs available to new sandboxes (reusable or not created yet)
+	FreeNetworks   int64    `protobuf:"varint,3,opt,name=freeNetworks,proto3" json:"freeNetworks,omitempty"`
+	CgroupWritable bool     `protobuf:"varint,4,opt,name=cgroupWritable,proto3" json:"cgroupWritable,omitempty"`
+	FcBinaryExists bool     `protobuf:"varint,5,opt,name=fcBinaryExists,proto3" json:"fcBinaryExists,omitempty"`
+	ChBinaryExists bool     `protobuf:"varint,6,opt,name=chBinaryExists,proto3" json:"chBinaryExists,omitempty"`
+	Problems       []string `protobuf:"bytes,7,rep,name=problems,proto3" json:"problems,omitempty"`
+	// the network envs created and not used by any sandbox, i.e., the ones
+	// can be taken by new sandboxes without setting up
+	WarmNetworks int64 `protobuf:"varint,8,opt,name=warmNetworks,proto3" json:"warmNetworks,omitempty"`
+	// the number of warm network envs to prepare when orchestrator starts
+	NetworkPoolSize int64 `protobuf:"varint,9,opt,name=networkPoolSize,proto3" json:"networkPoolSize,omitempty"`
+	// new sandboxes are rejected when draining (see HostManage.Drain)
+	Draining bool `protobuf:"varint,10,opt,name=draining,proto3" json:"draining,omitempty"`
+}
+
+func (x *HostManageHealthResponse) Reset() {
+	*x = HostManageHealthResponse{}
+	mi := &file_orchestrator_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageHealthResponse) ProtoMessage() {}
+
+func (x *HostManageHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageHealthResponse.ProtoReflect.Descriptor instead.
+func (*HostManageHealthResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *HostManageHealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *HostManageHealthResponse) GetSandboxes() int64 {
+	if x != nil {
+		return x.Sandboxes
+	}
+	return 0
+}
+
+func (x *HostManageHealthResponse) GetFreeNetworks() int64 {
+	if x != nil {
+		return x.FreeNetworks
+	}
+	return 0
+}
+
+func (x *HostManageHealthResponse) GetCgroupWritable() bool {
+	if x != nil {
+		return x.CgroupWritable
+	}
+	return false
+}
+
+func (x *HostManageHealthResponse) GetFcBinaryExists() bool {
+	if x != nil {
+		return x.FcBinaryExists
+	}
+	return false
+}
+
+func (x *HostManageHealthResponse) GetChBinaryExists() bool {
+	if x != nil {
+		return x.ChBinaryExists
+	}
+	return false
+}
+
+func (x *HostManageHealthResponse) GetProblems() []string {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+func (x *HostManageHealthResponse) GetWarmNetworks() int64 {
+	if x != nil {
+		return x.WarmNetworks
+	}
+	return 0
+}
+
+func (x *HostManageHealthResponse) GetNetworkPoolSize() int64 {
+	if x != nil {
+		return x.NetworkPoolSize
+	}
+	return 0
+}
+
+func (x *HostManageHealthResponse) GetDraining() bool {
+	if x != nil {
+		return x.Draining
+	}
+	return false
+}
+
+type HostManageDrainResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the sandboxes left on the host, the host is empty when it is 0
+	Sandboxes int64 `protobuf:"varint,1,opt,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+}
+
+func (x *HostManageDrainResponse) Reset() {
+	*x = HostManageDrainResponse{}
+	mi := &file_orchestrator_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageDrainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageDrainResponse) ProtoMessage() {}
+
+func (x *HostManageDrainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageDrainResponse.ProtoReflect.Descriptor instead.
+func (*HostManageDrainResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *HostManageDrainResponse) GetSandboxes() int64 {
+	if x != nil {
+		return x.Sandboxes
+	}
+	return 0
+}
+
+// The cgroup of sandbox which contains no process and is not maintained
+// by orchestrator (e.g., left by crashed sandbox).
+type StaleCgroup struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	Path      string `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+}
+
+func (x *StaleCgroup) Reset() {
+	*x = StaleCgroup{}
+	mi := &file_orchestrator_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StaleCgroup) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StaleCgroup) ProtoMessage() {}
+
+func (x *StaleCgroup) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StaleCgroup.ProtoReflect.Descriptor instead.
+func (*StaleCgroup) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *StaleCgroup) GetSandboxID() string {
+	if x != nil {
+		return x.SandboxID
+	}
+	return ""
+}
+
+func (x *StaleCgroup) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+type HostManageListStaleCgroupsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Cgroups []*StaleCgroup `protobuf:"bytes,1,rep,name=cgroups,proto3" json:"cgroups,omitempty"`
+}
+
+func (x *HostManageListStaleCgroupsResponse) Reset() {
+	*x = HostManageListStaleCgroupsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageListStaleCgroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageListStaleCgroupsResponse) ProtoMessage() {}
+
+func (x *HostManageListStaleCgroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageListStaleCgroupsResponse.ProtoReflect.Descriptor instead.
+func (*HostManageListStaleCgroupsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *HostManageListStaleCgroupsResponse) GetCgroups() []*StaleCgroup {
+	if x != nil {
+		return x.Cgroups
+	}
+	return nil
+}
+
+type HostManageReapCgroupsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// reap all stale cgroups, when specify this option
+	// the sandboxIDs will be omitted.
+	All        bool     `protobuf:"varint,1,opt,name=all,proto3" json:"all,omitempty"`
+	SandboxIDs []string `protobuf:"bytes,2,rep,name=sandboxIDs,proto3" json:"sandboxIDs,omitempty"`
+}
+
+func (x *HostManageReapCgroupsRequest) Reset() {
+	*x = HostManageReapCgroupsRequest{}
+	mi := &file_orchestrator_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageReapCgroupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageReapCgroupsRequest) ProtoMessage() {}
+
+func (x *HostManageReapCgroupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageReapCgroupsRequest.ProtoReflect.Descriptor instead.
+func (*HostManageReapCgroupsRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *HostManageReapCgroupsRequest) GetAll() bool {
+	if x != nil {
+		return x.All
+	}
+	return false
+}
+
+func (x *HostManageReapCgroupsRequest) GetSandboxIDs() []string {
+	if x != nil {
+		return x.SandboxIDs
+	}
+	return nil
+}
+
+type HostManageReapCgroupsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Reaped []string `protobuf:"bytes,1,rep,name=reaped,proto3" json:"reaped,omitempty"`
+	// sandbox id -> error
+	Failed map[string]string `protobuf:"bytes,2,rep,name=failed,proto3" json:"failed,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (x *HostManageReapCgroupsResponse) Reset() {
+	*x = HostManageReapCgroupsResponse{}
+	mi := &file_orchestrator_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageReapCgroupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageReapCgroupsResponse) ProtoMessage() {}
+
+func (x *HostManageReapCgroupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostManageReapCgroupsResponse.ProtoReflect.Descriptor instead.
+func (*HostManageReapCgroupsResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *HostManageReapCgroupsResponse) GetReaped() []string {
+	if x != nil {
+		return x.Reaped
+	}
+	return nil
+}
+
+func (x *HostManageReapCgroupsResponse) GetFailed() map[string]string {
+	if x != nil {
+		return x.Failed
+	}
+	return nil
+}
+
+type TemplateInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TemplateID    string `protobuf:"bytes,1,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	VmmType       string `protobuf:"bytes,2,opt,name=vmmType,proto3" json:"vmmType,omitempty"`
+	VcpuCount     int64  `protobuf:"varint,3,opt,name=vcpuCount,proto3" json:"vcpuCount,omitempty"`
+	MemoryMB      int64  `protobuf:"varint,4,opt,name=memoryMB,proto3" json:"memoryMB,omitempty"`
+	DiskSizeMB    int64  `protobuf:"varint,5,opt,name=diskSizeMB,proto3" json:"diskSizeMB,omitempty"`
+	KernelVersion string `protobuf:"bytes,6,opt,name=kernelVersion,proto3" json:"kernelVersion,omitempty"`
+	// whether all the image files (i.e., rootfs and snapshot) needed to
+	// restore from the template exist
+	ImagesPresent bool `protobuf:"varint,7,opt,name=imagesPresent,proto3" json:"imagesPresent,omitempty"`
+	// the missing image files, relative to the image dir of template
+	MissingFiles []string `protobuf:"bytes,8,rep,name=missingFiles,proto3" json:"missingFiles,omitempty"`
+}
+
+func (x *TemplateInfo) Reset() {
+	*x = TemplateInfo{}
+	mi := &file_orchestrator_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TemplateInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TemplateInfo) ProtoMessage() {}
+
+func (x *TemplateInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TemplateInfo.ProtoReflect.Descriptor instead.
+func (*TemplateInfo) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *TemplateInfo) GetTemplateID() string {
+	if x != nil {
+		return x.TemplateID
+	}
+	return ""
+}
+
+func (x *TemplateInfo) GetVmmType() string {
+	if x != nil {
+		return x.VmmType
+	}
+	return ""
+}
+
+func (x *TemplateInfo) GetVcpuCount() int64 {
+	if x != nil {
+		return x.VcpuCount
+	}
+	return 0
+}
+
+func (x *TemplateInfo) GetMemoryMB() int64 {
+	if x != nil {
+		return x.MemoryMB
+	}
+	return 0
+}
+
+func (x *TemplateInfo) GetDiskSizeMB() int64 {
+	if x != nil {
+		return x.DiskSizeMB
+	}
+	return 0
+}
+
+func (x *TemplateInfo) GetKernelVersion() string {
+	if x != nil {
+		return x.KernelVersion
+	}
+	return ""
+}
+
+func (x *TemplateInfo) GetImagesPresent() bool {
+	if x != nil {
+		return x.ImagesPresent
+	}
+	return false
+}
+
+func (x *TemplateInfo) GetMissingFiles() []string {
+	if x != nil {
+		return x.MissingFiles
+	}
+	return nil
+}
+
+type HostManageListTemplatesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Templates []*TemplateInfo `protobuf:"bytes,1,rep,name=templates,proto3" json:"templates,omitempty"`
+}
+
+func (x *HostManageListTemplatesResponse) Reset() {
+	*x = HostManageListTemplatesResponse{}
+	mi := &file_orchestrator_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostManageListTemplatesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostManageListTemplatesResponse) ProtoMessage() {}
+
+func (x *HostManageListTemplatesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func (x *SandboxListRequest) GetOrphan() bool {
-	if x != nil {
-		return x.Orphan
-	}
-	return false
+// Deprecated: Use HostManageListTemplatesResponse.ProtoReflect.Descriptor instead.
+func (*HostManageListTemplatesResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{53}
 }
 
-func (x *SandboxListRequest) GetRunning() bool {
+func (x *HostManageListTemplatesResponse) GetTemplates() []*TemplateInfo {
 	if x != nil {
-		return x.Running
+		return x.Templates
 	}
-	return false
+	return nil
 }
 
-// Data returned after listing all the sandboxes.
-type SandboxListResponse struct {
+type HostManageCompactSnapshotRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sandboxes []*SandboxInfo `protobuf:"bytes,1,rep,name=sandboxes,proto3" json:"sandboxes,omitempty"`
+	// the dir of the full snapshot which the chain is based on
+	BaseDir string `protobuf:"bytes,1,opt,name=baseDir,proto3" json:"baseDir,omitempty"`
+	// the dirs of the diff snapshots, from the oldest to the newest
+	DiffDirs []string `protobuf:"bytes,2,rep,name=diffDirs,proto3" json:"diffDirs,omitempty"`
+	// the dir where the consolidated full snapshot is written to
+	OutputDir string `protobuf:"bytes,3,opt,name=outputDir,proto3" json:"outputDir,omitempty"`
 }
 
-func (x *SandboxListResponse) Reset() {
-	*x = SandboxListResponse{}
-	mi := &file_orchestrator_proto_msgTypes[4]
+func (x *HostManageCompactSnapshotRequest) Reset() {
+	*x = HostManageCompactSnapshotRequest{}
+	mi := &file_orchestrator_proto_msgTypes[54]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxListResponse) String() string {
+func (x *HostManageCompactSnapshotRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxListResponse) ProtoMessage() {}
+func (*HostManageCompactSnapshotRequest) ProtoMessage() {}
 
-func (x *SandboxListResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[4]
+func (x *HostManageCompactSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[54]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -428,42 +3706,58 @@ func (x *SandboxListResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxListResponse.ProtoReflect.Descriptor instead.
-func (*SandboxListResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use HostManageCompactSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*HostManageCompactSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{54}
 }
 
-func (x *SandboxListResponse) GetSandboxes() []*SandboxInfo {
+func (x *HostManageCompactSnapshotRequest) GetBaseDir() string {
 	if x != nil {
-		return x.Sandboxes
+		return x.BaseDir
+	}
+	return ""
+}
+
+func (x *HostManageCompactSnapshotRequest) GetDiffDirs() []string {
+	if x != nil {
+		return x.DiffDirs
 	}
 	return nil
 }
 
-// ================= Delete ================= //
-type SandboxDeleteRequest struct {
+func (x *HostManageCompactSnapshotRequest) GetOutputDir() string {
+	if x != nil {
+		return x.OutputDir
+	}
+	return ""
+}
+
+type HostManageWarmNetworksRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// the number of warm network envs wanted, 0 means the network_pool_size
+	Count int64 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	// whether the network envs are for the templates enabling ipv6
+	Ipv6 bool `protobuf:"varint,2,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
 }
 
-func (x *SandboxDeleteRequest) Reset() {
-	*x = SandboxDeleteRequest{}
-	mi := &file_orchestrator_proto_msgTypes[5]
+func (x *HostManageWarmNetworksRequest) Reset() {
+	*x = HostManageWarmNetworksRequest{}
+	mi := &file_orchestrator_proto_msgTypes[55]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxDeleteRequest) String() string {
+func (x *HostManageWarmNetworksRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxDeleteRequest) ProtoMessage() {}
+func (*HostManageWarmNetworksRequest) ProtoMessage() {}
 
-func (x *SandboxDeleteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[5]
+func (x *HostManageWarmNetworksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[55]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -474,42 +3768,54 @@ func (x *SandboxDeleteRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxDeleteRequest.ProtoReflect.Descriptor instead.
-func (*SandboxDeleteRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use HostManageWarmNetworksRequest.ProtoReflect.Descriptor instead.
+func (*HostManageWarmNetworksRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{55}
 }
 
-func (x *SandboxDeleteRequest) GetSandboxID() string {
+func (x *HostManageWarmNetworksRequest) GetCount() int64 {
 	if x != nil {
-		return x.SandboxID
+		return x.Count
 	}
-	return ""
+	return 0
 }
 
-// ================= Deactivate ================= //
-type SandboxDeactivateRequest struct {
+func (x *HostManageWarmNetworksRequest) GetIpv6() bool {
+	if x != nil {
+		return x.Ipv6
+	}
+	return false
+}
+
+type HostManageWarmNetworksResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// the network envs created by this request
+	Created int64 `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`
+	// the warm network envs (of the same ipv6 setting) before this request
+	AlreadyWarm int64 `protobuf:"varint,2,opt,name=alreadyWarm,proto3" json:"alreadyWarm,omitempty"`
+	// the same as HostManageHealthResponse
+	WarmNetworks    int64 `protobuf:"varint,3,opt,name=warmNetworks,proto3" json:"warmNetworks,omitempty"`
+	NetworkPoolSize int64 `protobuf:"varint,4,opt,name=networkPoolSize,proto3" json:"networkPoolSize,omitempty"`
 }
 
-func (x *SandboxDeactivateRequest) Reset() {
-	*x = SandboxDeactivateRequest{}
-	mi := &file_orchestrator_proto_msgTypes[6]
+func (x *HostManageWarmNetworksResponse) Reset() {
+	*x = HostManageWarmNetworksResponse{}
+	mi := &file_orchestrator_proto_msgTypes[56]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxDeactivateRequest) String() string {
+func (x *HostManageWarmNetworksResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxDeactivateRequest) ProtoMessage() {}
+func (*HostManageWarmNetworksResponse) ProtoMessage() {}
 
-func (x *SandboxDeactivateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[6]
+func (x *HostManageWarmNetworksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[56]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -520,42 +3826,65 @@ func (x *SandboxDeactivateRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxDeactivateRequest.ProtoReflect.Descriptor instead.
-func (*SandboxDeactivateRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use HostManageWarmNetworksResponse.ProtoReflect.Descriptor instead.
+func (*HostManageWarmNetworksResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{56}
 }
 
-func (x *SandboxDeactivateRequest) GetSandboxID() string {
+func (x *HostManageWarmNetworksResponse) GetCreated() int64 {
 	if x != nil {
-		return x.SandboxID
+		return x.Created
 	}
-	return ""
+	return 0
 }
 
-// ================= Search ================= //
-type SandboxSearchRequest struct {
+func (x *HostManageWarmNetworksResponse) GetAlreadyWarm() int64 {
+	if x != nil {
+		return x.AlreadyWarm
+	}
+	return 0
+}
+
+func (x *HostManageWarmNetworksResponse) GetWarmNetworks() int64 {
+	if x != nil {
+		return x.WarmNetworks
+	}
+	return 0
+}
+
+func (x *HostManageWarmNetworksResponse) GetNetworkPoolSize() int64 {
+	if x != nil {
+		return x.NetworkPoolSize
+	}
+	return 0
+}
+
+type HostManageSelfTestRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	// the template of probe sandbox, empty means self_test_template of orchestrator
+	TemplateID string `protobuf:"bytes,1,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	// run the command (by envd, as its default user) inside the probe sandbox, empty means skipping it
+	Command string `protobuf:"bytes,2,opt,name=command,proto3" json:"command,omitempty"`
 }
 
-func (x *SandboxSearchRequest) Reset() {
-	*x = SandboxSearchRequest{}
-	mi := &file_orchestrator_proto_msgTypes[7]
+func (x *HostManageSelfTestRequest) Reset() {
+	*x = HostManageSelfTestRequest{}
+	mi := &file_orchestrator_proto_msgTypes[57]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxSearchRequest) String() string {
+func (x *HostManageSelfTestRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxSearchRequest) ProtoMessage() {}
+func (*HostManageSelfTestRequest) ProtoMessage() {}
 
-func (x *SandboxSearchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[7]
+func (x *HostManageSelfTestRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[57]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -566,41 +3895,52 @@ func (x *SandboxSearchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxSearchRequest.ProtoReflect.Descriptor instead.
-func (*SandboxSearchRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use HostManageSelfTestRequest.ProtoReflect.Descriptor instead.
+func (*HostManageSelfTestRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{57}
 }
 
-func (x *SandboxSearchRequest) GetSandboxID() string {
+func (x *HostManageSelfTestRequest) GetTemplateID() string {
 	if x != nil {
-		return x.SandboxID
+		return x.TemplateID
 	}
 	return ""
 }
 
-type SandboxSearchResponse struct {
+func (x *HostManageSelfTestRequest) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+type SelfTestPhase struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Sandbox *SandboxInfo `protobuf:"bytes,1,opt,name=sandbox,proto3,oneof" json:"sandbox,omitempty"`
+	// one of create, sync_clock, command and delete
+	Name       string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DurationMs int64  `protobuf:"varint,2,opt,name=durationMs,proto3" json:"durationMs,omitempty"`
+	// empty means the phase succeeded
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 }
 
-func (x *SandboxSearchResponse) Reset() {
-	*x = SandboxSearchResponse{}
-	mi := &file_orchestrator_proto_msgTypes[8]
+func (x *SelfTestPhase) Reset() {
+	*x = SelfTestPhase{}
+	mi := &file_orchestrator_proto_msgTypes[58]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxSearchResponse) String() string {
+func (x *SelfTestPhase) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxSearchResponse) ProtoMessage() {}
+func (*SelfTestPhase) ProtoMessage() {}
 
-func (x *SandboxSearchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[8]
+func (x *SelfTestPhase) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[58]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -611,44 +3951,63 @@ func (x *SandboxSearchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxSearchResponse.ProtoReflect.Descriptor instead.
-func (*SandboxSearchResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SelfTestPhase.ProtoReflect.Descriptor instead.
+func (*SelfTestPhase) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{58}
 }
 
-func (x *SandboxSearchResponse) GetSandbox() *SandboxInfo {
+func (x *SelfTestPhase) GetName() string {
 	if x != nil {
-		return x.Sandbox
+		return x.Name
 	}
-	return nil
+	return ""
 }
 
-// ================= Snapshot ================= //
-type SandboxSnapshotRequest struct {
+func (x *SelfTestPhase) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *SelfTestPhase) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type HostManageSelfTestResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	SandboxID string `protobuf:"bytes,1,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
-	// Whether to delete the sandbox after snapshotting.
-	Delete bool `protobuf:"varint,2,opt,name=delete,proto3" json:"delete,omitempty"`
+	// whether all phases succeeded, the phases after the failed one are skipped
+	// (except delete, which is always tried once the probe sandbox is created)
+	Passed    bool             `protobuf:"varint,1,opt,name=passed,proto3" json:"passed,omitempty"`
+	SandboxID string           `protobuf:"bytes,2,opt,name=sandboxID,proto3" json:"sandboxID,omitempty"`
+	Phases    []*SelfTestPhase `protobuf:"bytes,3,rep,name=phases,proto3" json:"phases,omitempty"`
+	// the result of command, only valid when the command phase is run
+	ExitCode int32  `protobuf:"varint,4,opt,name=exitCode,proto3" json:"exitCode,omitempty"`
+	Stdout   string `protobuf:"bytes,5,opt,name=stdout,proto3" json:"stdout,omitempty"`
+	Stderr   string `protobuf:"bytes,6,opt,name=stderr,proto3" json:"stderr,omitempty"`
 }
 
-func (x *SandboxSnapshotRequest) Reset() {
-	*x = SandboxSnapshotRequest{}
-	mi := &file_orchestrator_proto_msgTypes[9]
+func (x *HostManageSelfTestResponse) Reset() {
+	*x = HostManageSelfTestResponse{}
+	mi := &file_orchestrator_proto_msgTypes[59]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxSnapshotRequest) String() string {
+func (x *HostManageSelfTestResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxSnapshotRequest) ProtoMessage() {}
+func (*HostManageSelfTestResponse) ProtoMessage() {}
 
-func (x *SandboxSnapshotRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[9]
+func (x *HostManageSelfTestResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[59]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -659,49 +4018,77 @@ func (x *SandboxSnapshotRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxSnapshotRequest.ProtoReflect.Descriptor instead.
-func (*SandboxSnapshotRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use HostManageSelfTestResponse.ProtoReflect.Descriptor instead.
+func (*HostManageSelfTestResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{59}
 }
 
-func (x *SandboxSnapshotRequest) GetSandboxID() string {
+func (x *HostManageSelfTestResponse) GetPassed() bool {
+	if x != nil {
+		return x.Passed
+	}
+	return false
+}
+
+func (x *HostManageSelfTestResponse) GetSandboxID() string {
 	if x != nil {
 		return x.SandboxID
 	}
 	return ""
 }
 
-func (x *SandboxSnapshotRequest) GetDelete() bool {
+func (x *HostManageSelfTestResponse) GetPhases() []*SelfTestPhase {
 	if x != nil {
-		return x.Delete
+		return x.Phases
 	}
-	return false
+	return nil
 }
 
-type SandboxSnapshotResponse struct {
+func (x *HostManageSelfTestResponse) GetExitCode() int32 {
+	if x != nil {
+		return x.ExitCode
+	}
+	return 0
+}
+
+func (x *HostManageSelfTestResponse) GetStdout() string {
+	if x != nil {
+		return x.Stdout
+	}
+	return ""
+}
+
+func (x *HostManageSelfTestResponse) GetStderr() string {
+	if x != nil {
+		return x.Stderr
+	}
+	return ""
+}
+
+type HostManageCheckSnapshotCompatibilityRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// the path where contains the snapshot files.
-	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// empty means all of the templates on host
+	TemplateIDs []string `protobuf:"bytes,1,rep,name=templateIDs,proto3" json:"templateIDs,omitempty"`
 }
 
-func (x *SandboxSnapshotResponse) Reset() {
-	*x = SandboxSnapshotResponse{}
-	mi := &file_orchestrator_proto_msgTypes[10]
+func (x *HostManageCheckSnapshotCompatibilityRequest) Reset() {
+	*x = HostManageCheckSnapshotCompatibilityRequest{}
+	mi := &file_orchestrator_proto_msgTypes[60]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxSnapshotResponse) String() string {
+func (x *HostManageCheckSnapshotCompatibilityRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxSnapshotResponse) ProtoMessage() {}
+func (*HostManageCheckSnapshotCompatibilityRequest) ProtoMessage() {}
 
-func (x *SandboxSnapshotResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[10]
+func (x *HostManageCheckSnapshotCompatibilityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[60]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -712,46 +4099,47 @@ func (x *SandboxSnapshotResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxSnapshotResponse.ProtoReflect.Descriptor instead.
-func (*SandboxSnapshotResponse) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use HostManageCheckSnapshotCompatibilityRequest.ProtoReflect.Descriptor instead.
+func (*HostManageCheckSnapshotCompatibilityRequest) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{60}
 }
 
-func (x *SandboxSnapshotResponse) GetPath() string {
+func (x *HostManageCheckSnapshotCompatibilityRequest) GetTemplateIDs() []string {
 	if x != nil {
-		return x.Path
+		return x.TemplateIDs
 	}
-	return ""
+	return nil
 }
 
-// ================= Purge ================= //
-// See note of rpc Purge below
-type SandboxPurgeRequest struct {
+type SnapshotCompatibility struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// purge all orphan sandboxes, when specify this option
-	// the sandboxIDs will be omitted.
-	PurgeAll   bool     `protobuf:"varint,1,opt,name=purgeAll,proto3" json:"purgeAll,omitempty"`
-	SandboxIDs []string `protobuf:"bytes,2,rep,name=SandboxIDs,proto3" json:"SandboxIDs,omitempty"`
+	TemplateID string `protobuf:"bytes,1,opt,name=templateID,proto3" json:"templateID,omitempty"`
+	Compatible bool   `protobuf:"varint,2,opt,name=compatible,proto3" json:"compatible,omitempty"`
+	// why the snapshot cannot be restored on host, or the template cannot be loaded
+	Problems []string `protobuf:"bytes,3,rep,name=problems,proto3" json:"problems,omitempty"`
+	// the cpu of host where the snapshot is taken, empty means unknown
+	// (the template is assumed to be compatible)
+	SnapshotCPU string `protobuf:"bytes,4,opt,name=snapshotCPU,proto3" json:"snapshotCPU,omitempty"`
 }
 
-func (x *SandboxPurgeRequest) Reset() {
-	*x = SandboxPurgeRequest{}
-	mi := &file_orchestrator_proto_msgTypes[11]
+func (x *SnapshotCompatibility) Reset() {
+	*x = SnapshotCompatibility{}
+	mi := &file_orchestrator_proto_msgTypes[61]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SandboxPurgeRequest) String() string {
+func (x *SnapshotCompatibility) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SandboxPurgeRequest) ProtoMessage() {}
+func (*SnapshotCompatibility) ProtoMessage() {}
 
-func (x *SandboxPurgeRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[11]
+func (x *SnapshotCompatibility) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[61]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -762,48 +4150,63 @@ func (x *SandboxPurgeRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SandboxPurgeRequest.ProtoReflect.Descriptor instead.
-func (*SandboxPurgeRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use SnapshotCompatibility.ProtoReflect.Descriptor instead.
+func (*SnapshotCompatibility) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{61}
 }
 
-func (x *SandboxPurgeRequest) GetPurgeAll() bool {
+func (x *SnapshotCompatibility) GetTemplateID() string {
 	if x != nil {
-		return x.PurgeAll
+		return x.TemplateID
+	}
+	return ""
+}
+
+func (x *SnapshotCompatibility) GetCompatible() bool {
+	if x != nil {
+		return x.Compatible
 	}
 	return false
 }
 
-func (x *SandboxPurgeRequest) GetSandboxIDs() []string {
+func (x *SnapshotCompatibility) GetProblems() []string {
 	if x != nil {
-		return x.SandboxIDs
+		return x.Problems
 	}
 	return nil
 }
 
-type HostManageCleanNetworkEnvRequest struct {
+func (x *SnapshotCompatibility) GetSnapshotCPU() string {
+	if x != nil {
+		return x.SnapshotCPU
+	}
+	return ""
+}
+
+type HostManageCheckSnapshotCompatibilityResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	NetworkIDs []int64 `protobuf:"varint,1,rep,packed,name=NetworkIDs,proto3" json:"NetworkIDs,omitempty"`
+	HostCPU   string                   `protobuf:"bytes,1,opt,name=hostCPU,proto3" json:"hostCPU,omitempty"`
+	Templates []*SnapshotCompatibility `protobuf:"bytes,2,rep,name=templates,proto3" json:"templates,omitempty"`
 }
 
-func (x *HostManageCleanNetworkEnvRequest) Reset() {
-	*x = HostManageCleanNetworkEnvRequest{}
-	mi := &file_orchestrator_proto_msgTypes[12]
+func (x *HostManageCheckSnapshotCompatibilityResponse) Reset() {
+	*x = HostManageCheckSnapshotCompatibilityResponse{}
+	mi := &file_orchestrator_proto_msgTypes[62]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HostManageCleanNetworkEnvRequest) String() string {
+func (x *HostManageCheckSnapshotCompatibilityResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HostManageCleanNetworkEnvRequest) ProtoMessage() {}
+func (*HostManageCheckSnapshotCompatibilityResponse) ProtoMessage() {}
 
-func (x *HostManageCleanNetworkEnvRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_orchestrator_proto_msgTypes[12]
+func (x *HostManageCheckSnapshotCompatibilityResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_orchestrator_proto_msgTypes[62]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -814,14 +4217,21 @@ func (x *HostManageCleanNetworkEnvRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HostManageCleanNetworkEnvRequest.ProtoReflect.Descriptor instead.
-func (*HostManageCleanNetworkEnvRequest) Descriptor() ([]byte, []int) {
-	return file_orchestrator_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use HostManageCheckSnapshotCompatibilityResponse.ProtoReflect.Descriptor instead.
+func (*HostManageCheckSnapshotCompatibilityResponse) Descriptor() ([]byte, []int) {
+	return file_orchestrator_proto_rawDescGZIP(), []int{62}
 }
 
-func (x *HostManageCleanNetworkEnvRequest) GetNetworkIDs() []int64 {
+func (x *HostManageCheckSnapshotCompatibilityResponse) GetHostCPU() string {
 	if x != nil {
-		return x.NetworkIDs
+		return x.HostCPU
+	}
+	return ""
+}
+
+func (x *HostManageCheckSnapshotCompatibilityResponse) GetTemplates() []*SnapshotCompatibility {
+	if x != nil {
+		return x.Templates
 	}
 	return nil
 }
@@ -834,163 +4244,778 @@ var file_orchestrator_proto_rawDesc = []byte{
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
 	0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0xd6, 0x04, 0x0a, 0x0b, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e,
-	0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44,
-	0x12, 0x23, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
-	0x49, 0x44, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x56,
-	0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0d,
-	0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01,
-	0x12, 0x15, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x02, 0x52,
-	0x03, 0x70, 0x69, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x6e, 0x65, 0x74, 0x77, 0x6f,
-	0x72, 0x6b, 0x49, 0x64, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x0a, 0x6e,
-	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x88, 0x01, 0x01, 0x12, 0x21, 0x0a, 0x09,
-	0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x49, 0x50, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x48,
-	0x04, 0x52, 0x09, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x49, 0x50, 0x88, 0x01, 0x01, 0x12,
-	0x3d, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x07, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x48, 0x05,
-	0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x88, 0x01, 0x01, 0x12, 0x35,
-	0x0a, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70,
-	0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x48, 0x06, 0x52, 0x13, 0x65,
-	0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
-	0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x09,
-	0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x74,
-	0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x36, 0x0a, 0x08, 0x6d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x53,
-	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e,
-	0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42,
-	0x0d, 0x0a, 0x0b, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x42, 0x10,
-	0x0a, 0x0e, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
-	0x42, 0x06, 0x0a, 0x04, 0x5f, 0x70, 0x69, 0x64, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x6e, 0x65, 0x74,
-	0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x70, 0x72, 0x69, 0x76,
-	0x61, 0x74, 0x65, 0x49, 0x50, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54,
-	0x69, 0x6d, 0x65, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69,
-	0x66, 0x66, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x22, 0x84, 0x03, 0x0a, 0x14,
-	0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
-	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61,
-	0x74, 0x65, 0x49, 0x44, 0x12, 0x2c, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x73, 0x74, 0x61,
-	0x6e, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
-	0x11, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67,
-	0x74, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44,
-	0x12, 0x30, 0x0a, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e,
-	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13, 0x65,
-	0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
-	0x74, 0x73, 0x12, 0x3f, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61,
-	0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x12, 0x37, 0x0a, 0x14, 0x68, 0x79, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f,
-	0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74, 0x68, 0x18, 0x07, 0x20, 0x01, 0x28,
-	0x09, 0x48, 0x00, 0x52, 0x14, 0x68, 0x79, 0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x42,
-	0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74, 0x68, 0x88, 0x01, 0x01, 0x1a, 0x3b, 0x0a, 0x0d,
-	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
-	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x68, 0x79,
-	0x70, 0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61,
-	0x74, 0x68, 0x22, 0x39, 0x0a, 0x15, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x69,
-	0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x61, 0x6e, 0x64,
-	0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0x46, 0x0a,
-	0x12, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x70, 0x68, 0x61, 0x6e, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x08, 0x52, 0x06, 0x6f, 0x72, 0x70, 0x68, 0x61, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x72,
-	0x75, 0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75,
-	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x22, 0x41, 0x0a, 0x13, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
-	0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x09,
-	0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
-	0x0c, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x73,
-	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x22, 0x34, 0x0a, 0x14, 0x53, 0x61, 0x6e, 0x64,
-	0x62, 0x6f, 0x78, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x38,
-	0x0a, 0x18, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76,
+	0x74, 0x6f, 0x22, 0x51, 0x0a, 0x0b, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x44, 0x65, 0x74, 0x61, 0x69,
+	0x6c, 0x12, 0x24, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x0c, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52,
+	0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x74, 0x72, 0x79,
+	0x61, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x74, 0x72,
+	0x79, 0x61, 0x62, 0x6c, 0x65, 0x22, 0x88, 0x05, 0x0a, 0x0b, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x49, 0x44, 0x12, 0x23, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49,
+	0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x49, 0x44, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0d, 0x6b, 0x65, 0x72, 0x6e,
+	0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48,
+	0x01, 0x52, 0x0d, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x88, 0x01, 0x01, 0x12, 0x15, 0x0a, 0x03, 0x70, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d,
+	0x48, 0x02, 0x52, 0x03, 0x70, 0x69, 0x64, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x6e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03,
+	0x52, 0x0a, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x88, 0x01, 0x01, 0x12,
+	0x21, 0x0a, 0x09, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x49, 0x50, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x04, 0x52, 0x09, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x49, 0x50, 0x88,
+	0x01, 0x01, 0x12, 0x3d, 0x0a, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x48, 0x05, 0x52, 0x09, 0x73, 0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x88, 0x01,
+	0x01, 0x12, 0x35, 0x0a, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x48, 0x06,
+	0x52, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x73, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x74,
+	0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12, 0x36, 0x0a,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x0a, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x2e, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x30, 0x0a, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x50, 0x6f,
+	0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x52, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x46,
+	0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x73, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x49, 0x44, 0x42, 0x10, 0x0a, 0x0e, 0x5f, 0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x56, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x42, 0x06, 0x0a, 0x04, 0x5f, 0x70, 0x69, 0x64, 0x42, 0x0d, 0x0a,
+	0x0b, 0x5f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x42, 0x0c, 0x0a, 0x0a,
+	0x5f, 0x70, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x49, 0x50, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x73,
+	0x74, 0x61, 0x72, 0x74, 0x54, 0x69, 0x6d, 0x65, 0x42, 0x16, 0x0a, 0x14, 0x5f, 0x65, 0x6e, 0x61,
+	0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73,
+	0x22, 0xfa, 0x09, 0x0a, 0x14, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d,
+	0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74,
+	0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x12, 0x2c, 0x0a, 0x11, 0x6d, 0x61, 0x78,
+	0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x11, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63,
+	0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x49, 0x44, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x30, 0x0a, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44,
+	0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x12, 0x3f, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x53, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x37, 0x0a, 0x14, 0x68, 0x79, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74, 0x68,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x14, 0x68, 0x79, 0x70, 0x65, 0x72, 0x76,
+	0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74, 0x68, 0x88, 0x01,
+	0x01, 0x12, 0x31, 0x0a, 0x11, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x49, 0x6e, 0x69, 0x74, 0x55, 0x73,
+	0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x11,
+	0x63, 0x6c, 0x6f, 0x75, 0x64, 0x49, 0x6e, 0x69, 0x74, 0x55, 0x73, 0x65, 0x72, 0x44, 0x61, 0x74,
+	0x61, 0x88, 0x01, 0x01, 0x12, 0x27, 0x0a, 0x0c, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x43, 0x70,
+	0x75, 0x4d, 0x61, 0x78, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x0c, 0x63, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x43, 0x70, 0x75, 0x4d, 0x61, 0x78, 0x88, 0x01, 0x01, 0x12, 0x2d, 0x0a,
+	0x0f, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x61, 0x78,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x03, 0x48, 0x03, 0x52, 0x0f, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x61, 0x78, 0x88, 0x01, 0x01, 0x12, 0x29, 0x0a, 0x0a,
+	0x65, 0x78, 0x74, 0x72, 0x61, 0x44, 0x69, 0x73, 0x6b, 0x73, 0x18, 0x0b, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x09, 0x2e, 0x44, 0x69, 0x73, 0x6b, 0x53, 0x70, 0x65, 0x63, 0x52, 0x0a, 0x65, 0x78, 0x74,
+	0x72, 0x61, 0x44, 0x69, 0x73, 0x6b, 0x73, 0x12, 0x23, 0x0a, 0x0a, 0x77, 0x6f, 0x72, 0x6b, 0x69,
+	0x6e, 0x67, 0x44, 0x69, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x48, 0x04, 0x52, 0x0a, 0x77,
+	0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x69, 0x72, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a, 0x03,
+	0x65, 0x6e, 0x76, 0x18, 0x0d, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x53, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x2e, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x03, 0x65, 0x6e, 0x76, 0x12, 0x36,
+	0x0a, 0x0c, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x0e,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x48, 0x05, 0x52, 0x0c, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x88, 0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x73, 0x6f, 0x6c,
+	0x65, 0x4c, 0x6f, 0x67, 0x18, 0x0f, 0x20, 0x01, 0x28, 0x08, 0x48, 0x06, 0x52, 0x0a, 0x63, 0x6f,
+	0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x4c, 0x6f, 0x67, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x67,
+	0x75, 0x65, 0x73, 0x74, 0x4d, 0x61, 0x63, 0x18, 0x10, 0x20, 0x01, 0x28, 0x09, 0x48, 0x07, 0x52,
+	0x08, 0x67, 0x75, 0x65, 0x73, 0x74, 0x4d, 0x61, 0x63, 0x88, 0x01, 0x01, 0x12, 0x2f, 0x0a, 0x10,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x4d, 0x42,
+	0x18, 0x11, 0x20, 0x01, 0x28, 0x03, 0x48, 0x08, 0x52, 0x10, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79,
+	0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x4d, 0x42, 0x88, 0x01, 0x01, 0x12, 0x30, 0x0a,
+	0x0c, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x73, 0x18, 0x12, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72,
+	0x64, 0x52, 0x0c, 0x70, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72, 0x77, 0x61, 0x72, 0x64, 0x73, 0x12,
+	0x1a, 0x0a, 0x08, 0x63, 0x6f, 0x6c, 0x64, 0x42, 0x6f, 0x6f, 0x74, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x08, 0x63, 0x6f, 0x6c, 0x64, 0x42, 0x6f, 0x6f, 0x74, 0x12, 0x24, 0x0a, 0x0d, 0x63,
+	0x67, 0x72, 0x6f, 0x75, 0x70, 0x53, 0x75, 0x62, 0x70, 0x61, 0x74, 0x68, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0d, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x53, 0x75, 0x62, 0x70, 0x61, 0x74,
+	0x68, 0x12, 0x2a, 0x0a, 0x10, 0x64, 0x69, 0x73, 0x6b, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64,
+	0x74, 0x68, 0x42, 0x70, 0x73, 0x18, 0x15, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10, 0x64, 0x69, 0x73,
+	0x6b, 0x42, 0x61, 0x6e, 0x64, 0x77, 0x69, 0x64, 0x74, 0x68, 0x42, 0x70, 0x73, 0x12, 0x1a, 0x0a,
+	0x08, 0x64, 0x69, 0x73, 0x6b, 0x49, 0x6f, 0x70, 0x73, 0x18, 0x16, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x08, 0x64, 0x69, 0x73, 0x6b, 0x49, 0x6f, 0x70, 0x73, 0x12, 0x38, 0x0a, 0x17, 0x6b, 0x65, 0x72,
+	0x6e, 0x65, 0x6c, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x50, 0x61, 0x74, 0x68, 0x4f, 0x76, 0x65, 0x72,
+	0x72, 0x69, 0x64, 0x65, 0x18, 0x17, 0x20, 0x01, 0x28, 0x09, 0x52, 0x17, 0x6b, 0x65, 0x72, 0x6e,
+	0x65, 0x6c, 0x49, 0x6d, 0x61, 0x67, 0x65, 0x50, 0x61, 0x74, 0x68, 0x4f, 0x76, 0x65, 0x72, 0x72,
+	0x69, 0x64, 0x65, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01,
+	0x1a, 0x36, 0x0a, 0x08, 0x45, 0x6e, 0x76, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x68, 0x79, 0x70,
+	0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74,
+	0x68, 0x42, 0x14, 0x0a, 0x12, 0x5f, 0x63, 0x6c, 0x6f, 0x75, 0x64, 0x49, 0x6e, 0x69, 0x74, 0x55,
+	0x73, 0x65, 0x72, 0x44, 0x61, 0x74, 0x61, 0x42, 0x0f, 0x0a, 0x0d, 0x5f, 0x63, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x43, 0x70, 0x75, 0x4d, 0x61, 0x78, 0x42, 0x12, 0x0a, 0x10, 0x5f, 0x63, 0x67, 0x72,
+	0x6f, 0x75, 0x70, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x61, 0x78, 0x42, 0x0d, 0x0a, 0x0b,
+	0x5f, 0x77, 0x6f, 0x72, 0x6b, 0x69, 0x6e, 0x67, 0x44, 0x69, 0x72, 0x42, 0x0f, 0x0a, 0x0d, 0x5f,
+	0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x42, 0x0d, 0x0a, 0x0b,
+	0x5f, 0x63, 0x6f, 0x6e, 0x73, 0x6f, 0x6c, 0x65, 0x4c, 0x6f, 0x67, 0x42, 0x0b, 0x0a, 0x09, 0x5f,
+	0x67, 0x75, 0x65, 0x73, 0x74, 0x4d, 0x61, 0x63, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x6d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x4d, 0x42, 0x22, 0x52, 0x0a,
+	0x0c, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x21, 0x0a,
+	0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b, 0x2e, 0x45,
+	0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x05, 0x61, 0x6c, 0x6c, 0x6f, 0x77,
+	0x12, 0x1f, 0x0a, 0x04, 0x64, 0x65, 0x6e, 0x79, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0b,
+	0x2e, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x75, 0x6c, 0x65, 0x52, 0x04, 0x64, 0x65, 0x6e,
+	0x79, 0x22, 0x50, 0x0a, 0x0a, 0x45, 0x67, 0x72, 0x65, 0x73, 0x73, 0x52, 0x75, 0x6c, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x63, 0x69, 0x64, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63,
+	0x69, 0x64, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12,
+	0x12, 0x0a, 0x04, 0x70, 0x6f, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x70,
+	0x6f, 0x72, 0x74, 0x22, 0x60, 0x0a, 0x08, 0x44, 0x69, 0x73, 0x6b, 0x53, 0x70, 0x65, 0x63, 0x12,
+	0x16, 0x0a, 0x06, 0x73, 0x69, 0x7a, 0x65, 0x4d, 0x42, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x06, 0x73, 0x69, 0x7a, 0x65, 0x4d, 0x42, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f,
+	0x6e, 0x6c, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x61, 0x64, 0x4f,
+	0x6e, 0x6c, 0x79, 0x12, 0x20, 0x0a, 0x0b, 0x62, 0x61, 0x63, 0x6b, 0x69, 0x6e, 0x67, 0x46, 0x69,
+	0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x62, 0x61, 0x63, 0x6b, 0x69, 0x6e,
+	0x67, 0x46, 0x69, 0x6c, 0x65, 0x22, 0x63, 0x0a, 0x0b, 0x50, 0x6f, 0x72, 0x74, 0x46, 0x6f, 0x72,
+	0x77, 0x61, 0x72, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x68, 0x6f, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74,
+	0x12, 0x1c, 0x0a, 0x09, 0x67, 0x75, 0x65, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x09, 0x67, 0x75, 0x65, 0x73, 0x74, 0x50, 0x6f, 0x72, 0x74, 0x12, 0x1a,
+	0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x22, 0x39, 0x0a, 0x15, 0x53, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x20, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x52,
+	0x04, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0xc8, 0x03, 0x0a, 0x19, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x49, 0x44, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x28, 0x0a, 0x0f, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x50, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0f, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x50, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x12, 0x2c, 0x0a, 0x11, 0x6d, 0x61, 0x78, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e,
+	0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x11,
+	0x6d, 0x61, 0x78, 0x49, 0x6e, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65, 0x4c, 0x65, 0x6e, 0x67, 0x74,
+	0x68, 0x12, 0x30, 0x0a, 0x13, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x13,
+	0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x44, 0x69, 0x66, 0x66, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x73, 0x12, 0x44, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x37, 0x0a, 0x14, 0x68, 0x79, 0x70,
+	0x65, 0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74,
+	0x68, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x14, 0x68, 0x79, 0x70, 0x65, 0x72,
+	0x76, 0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74, 0x68, 0x88,
+	0x01, 0x01, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x74, 0x6f, 0x6d, 0x69, 0x63, 0x18, 0x08, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x61, 0x74, 0x6f, 0x6d, 0x69, 0x63, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65,
+	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x17, 0x0a, 0x15, 0x5f, 0x68, 0x79, 0x70, 0x65,
+	0x72, 0x76, 0x69, 0x73, 0x6f, 0x72, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x50, 0x61, 0x74, 0x68,
+	0x22, 0x7c, 0x0a, 0x16, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x49, 0x74, 0x65, 0x6d, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x25, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x49, 0x6e, 0x66, 0x6f, 0x48, 0x00, 0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x88, 0x01, 0x01, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x69, 0x6e, 0x66, 0x6f, 0x22, 0x4b,
+	0x0a, 0x1a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42,
+	0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2d, 0x0a, 0x05,
+	0x69, 0x74, 0x65, 0x6d, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x53, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x49, 0x74, 0x65, 0x6d, 0x52, 0x05, 0x69, 0x74, 0x65, 0x6d, 0x73, 0x22, 0x96, 0x02, 0x0a, 0x12,
+	0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x72, 0x70, 0x68, 0x61, 0x6e, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x06, 0x6f, 0x72, 0x70, 0x68, 0x61, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x75,
+	0x6e, 0x6e, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x75, 0x6e,
+	0x6e, 0x69, 0x6e, 0x67, 0x12, 0x23, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
+	0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70,
+	0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x88, 0x01, 0x01, 0x12, 0x55, 0x0a, 0x10, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c, 0x69, 0x73,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x10,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x6f, 0x72,
+	0x1a, 0x43, 0x0a, 0x15, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x53, 0x65, 0x6c, 0x65,
+	0x63, 0x74, 0x6f, 0x72, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x49, 0x44, 0x22, 0x41, 0x0a, 0x13, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c,
+	0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2a, 0x0a, 0x09, 0x73,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x73, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x22, 0x34, 0x0a, 0x14, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x38, 0x0a,
+	0x18, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x34, 0x0a, 0x14, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x50, 0x0a,
+	0x15, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x07, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x49, 0x6e, 0x66, 0x6f, 0x48, 0x00, 0x52, 0x07, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x22,
+	0x76, 0x0a, 0x16, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12,
+	0x26, 0x0a, 0x0e, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69,
+	0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x44, 0x69, 0x72, 0x22, 0x2d, 0x0a, 0x17, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x78, 0x0a, 0x20, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x41, 0x73, 0x54, 0x65, 0x6d, 0x70, 0x6c,
 	0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61,
 	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
-	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x34, 0x0a, 0x14, 0x53, 0x61, 0x6e, 0x64,
-	0x62, 0x6f, 0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x50,
-	0x0a, 0x15, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x07, 0x73, 0x61, 0x6e, 0x64, 0x62,
-	0x6f, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
-	0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x48, 0x00, 0x52, 0x07, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
-	0x78, 0x88, 0x01, 0x01, 0x42, 0x0a, 0x0a, 0x08, 0x5f, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
-	0x22, 0x4e, 0x0a, 0x16, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73,
-	0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70,
+	0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x22, 0x57, 0x0a, 0x21, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x41, 0x73, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x39, 0x0a, 0x19, 0x53, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x49, 0x44, 0x22, 0xae, 0x01, 0x0a, 0x1a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x45, 0x6e,
+	0x74, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x70, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x70,
+	0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x70, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x26, 0x0a, 0x0e, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x69, 0x65,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64,
+	0x45, 0x6e, 0x74, 0x72, 0x69, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6c, 0x61, 0x73, 0x74, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6c, 0x61, 0x73, 0x74,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xd6, 0x01, 0x0a, 0x19, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49,
+	0x44, 0x12, 0x44, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x74,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x2e,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x70, 0x6c, 0x61,
+	0x63, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x72, 0x65, 0x70, 0x6c, 0x61, 0x63,
+	0x65, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xa0,
+	0x01, 0x0a, 0x1a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x45, 0x0a,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x29, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61,
+	0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
+	0x01, 0x22, 0x37, 0x0a, 0x17, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x79, 0x6e, 0x63,
+	0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x22, 0x45, 0x0a, 0x25, 0x53, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x52, 0x65, 0x66, 0x72, 0x65, 0x73, 0x68, 0x50, 0x72, 0x6f, 0x6d,
+	0x65, 0x74, 0x68, 0x65, 0x75, 0x73, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49,
+	0x44, 0x22, 0x3d, 0x0a, 0x1d, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x52, 0x65, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44,
+	0x22, 0x4f, 0x0a, 0x15, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x42, 0x61, 0x6c, 0x6c, 0x6f,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x4d,
+	0x69, 0x42, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x69, 0x7a, 0x65, 0x4d, 0x69,
+	0x42, 0x22, 0x48, 0x0a, 0x16, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x42, 0x61, 0x6c, 0x6c,
+	0x6f, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73,
+	0x69, 0x7a, 0x65, 0x4d, 0x69, 0x42, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x73, 0x69,
+	0x7a, 0x65, 0x4d, 0x69, 0x42, 0x4a, 0x04, 0x08, 0x01, 0x10, 0x02, 0x52, 0x0e, 0x72, 0x65, 0x63,
+	0x6c, 0x61, 0x69, 0x6d, 0x65, 0x64, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x41, 0x0a, 0x1f, 0x53,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73,
+	0x75, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e,
+	0x0a, 0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x22, 0x62,
+	0x0a, 0x18, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43,
+	0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61,
 	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73,
-	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x65, 0x6c, 0x65,
-	0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x64, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x22, 0x2d, 0x0a, 0x17, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73,
-	0x68, 0x6f, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x70,
-	0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x28, 0x0a, 0x0f, 0x68, 0x6f, 0x73, 0x74,
+	0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0f, 0x68, 0x6f, 0x73, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x22, 0x5b, 0x0a, 0x20, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d, 0x65, 0x6d,
+	0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x53, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x22,
 	0x51, 0x0a, 0x13, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x50, 0x75, 0x72, 0x67, 0x65, 0x52,
 	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x75, 0x72, 0x67, 0x65, 0x41,
 	0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x70, 0x75, 0x72, 0x67, 0x65, 0x41,
 	0x6c, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73,
 	0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49,
-	0x44, 0x73, 0x22, 0x42, 0x0a, 0x20, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
-	0x43, 0x6c, 0x65, 0x61, 0x6e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x45, 0x6e, 0x76, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72,
-	0x6b, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x03, 0x52, 0x0a, 0x4e, 0x65, 0x74, 0x77,
-	0x6f, 0x72, 0x6b, 0x49, 0x44, 0x73, 0x2a, 0x6e, 0x0a, 0x0c, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
-	0x78, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43,
-	0x49, 0x46, 0x59, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44,
-	0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12,
-	0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f, 0x50, 0x10, 0x03, 0x12, 0x0d, 0x0a, 0x09, 0x43, 0x4c, 0x45,
-	0x41, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x04, 0x12, 0x10, 0x0a, 0x0c, 0x53, 0x4e, 0x41, 0x50,
-	0x53, 0x48, 0x4f, 0x54, 0x54, 0x49, 0x4e, 0x47, 0x10, 0x05, 0x12, 0x0a, 0x0a, 0x06, 0x4f, 0x52,
-	0x50, 0x48, 0x41, 0x4e, 0x10, 0x06, 0x32, 0x9c, 0x03, 0x0a, 0x07, 0x53, 0x61, 0x6e, 0x64, 0x62,
-	0x6f, 0x78, 0x12, 0x37, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x15, 0x2e, 0x53,
-	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65,
-	0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x04, 0x4c,
-	0x69, 0x73, 0x74, 0x12, 0x13, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c, 0x69, 0x73,
-	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x14, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
-	0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37,
-	0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x12, 0x15, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
-	0x6f, 0x78, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
-	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3d, 0x0a, 0x08, 0x44, 0x65, 0x61, 0x63, 0x74,
-	0x69, 0x76, 0x65, 0x12, 0x19, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x61,
-	0x63, 0x74, 0x69, 0x76, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
+	0x44, 0x73, 0x22, 0x3b, 0x0a, 0x19, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x50, 0x75, 0x72,
+	0x67, 0x65, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x1e, 0x0a, 0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x22,
+	0x3c, 0x0a, 0x1a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x50, 0x75, 0x72, 0x67, 0x65, 0x46,
+	0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a,
+	0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x22, 0x3c, 0x0a,
+	0x1a, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72, 0x70,
+	0x68, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x73,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x0a, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x22, 0x51, 0x0a, 0x19, 0x53,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72, 0x70, 0x68, 0x61,
+	0x6e, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0x7b,
+	0x0a, 0x1b, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72,
+	0x70, 0x68, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x26, 0x0a,
+	0x07, 0x61, 0x64, 0x6f, 0x70, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c,
+	0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x07, 0x61, 0x64,
+	0x6f, 0x70, 0x74, 0x65, 0x64, 0x12, 0x34, 0x0a, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72, 0x70, 0x68, 0x61, 0x6e, 0x53, 0x6b, 0x69, 0x70, 0x70,
+	0x65, 0x64, 0x52, 0x07, 0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x22, 0x61, 0x0a, 0x19, 0x53,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x53, 0x65, 0x6e,
+	0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x26, 0x0a, 0x0e, 0x64, 0x65, 0x73, 0x74, 0x69, 0x6e,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x64, 0x65, 0x73, 0x74, 0x69, 0x6e, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x55, 0x72, 0x6c, 0x22, 0x71,
+	0x0a, 0x1c, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65,
+	0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x2f,
+	0x0a, 0x07, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x15, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x52, 0x07, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x12,
+	0x20, 0x0a, 0x0b, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x55, 0x72, 0x6c, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72, 0x55, 0x72,
+	0x6c, 0x22, 0x80, 0x01, 0x0a, 0x19, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x57, 0x61, 0x74,
+	0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x21, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x48, 0x00, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x88,
+	0x01, 0x01, 0x12, 0x23, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x01, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x49, 0x44, 0x88, 0x01, 0x01, 0x42, 0x0c, 0x0a, 0x0a, 0x5f, 0x73, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x49, 0x44, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x49, 0x44, 0x22, 0xe2, 0x01, 0x0a, 0x0c, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x12, 0x23, 0x0a, 0x05, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x53, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x2e, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x12,
+	0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x22, 0x42, 0x0a, 0x20, 0x48, 0x6f, 0x73,
+	0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x4e, 0x65, 0x74, 0x77,
+	0x6f, 0x72, 0x6b, 0x45, 0x6e, 0x76, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a,
+	0x0a, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x44, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x03, 0x52, 0x0a, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x44, 0x73, 0x22, 0x8c, 0x02,
+	0x0a, 0x0b, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x12, 0x1e, 0x0a,
+	0x0a, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0a, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x64, 0x78, 0x12, 0x23, 0x0a,
+	0x05, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x0d, 0x2e, 0x4e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61,
+	0x74, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44,
+	0x12, 0x1c, 0x0a, 0x09, 0x6e, 0x65, 0x74, 0x4e, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x65, 0x74, 0x4e, 0x73, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x76, 0x65, 0x74, 0x68, 0x49, 0x50, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x76, 0x65, 0x74, 0x68, 0x49, 0x50, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x70, 0x65, 0x65, 0x72, 0x49,
+	0x50, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x70, 0x65, 0x65, 0x72, 0x49, 0x50,
+	0x12, 0x22, 0x0a, 0x0c, 0x68, 0x6f, 0x73, 0x74, 0x43, 0x6c, 0x6f, 0x6e, 0x65, 0x64, 0x49, 0x50,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x68, 0x6f, 0x73, 0x74, 0x43, 0x6c, 0x6f, 0x6e,
+	0x65, 0x64, 0x49, 0x50, 0x12, 0x26, 0x0a, 0x0e, 0x68, 0x6f, 0x73, 0x74, 0x43, 0x6c, 0x6f, 0x6e,
+	0x65, 0x64, 0x49, 0x50, 0x76, 0x36, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x68, 0x6f,
+	0x73, 0x74, 0x43, 0x6c, 0x6f, 0x6e, 0x65, 0x64, 0x49, 0x50, 0x76, 0x36, 0x22, 0x4a, 0x0a, 0x1e,
+	0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x4e, 0x65,
+	0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x28,
+	0x0a, 0x08, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0c, 0x2e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x08,
+	0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x22, 0xf4, 0x02, 0x0a, 0x18, 0x48, 0x6f, 0x73,
+	0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12,
+	0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x12, 0x22, 0x0a,
+	0x0c, 0x66, 0x72, 0x65, 0x65, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x0c, 0x66, 0x72, 0x65, 0x65, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b,
+	0x73, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x57, 0x72, 0x69, 0x74, 0x61,
+	0x62, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x63, 0x67, 0x72, 0x6f, 0x75,
+	0x70, 0x57, 0x72, 0x69, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x26, 0x0a, 0x0e, 0x66, 0x63, 0x42,
+	0x69, 0x6e, 0x61, 0x72, 0x79, 0x45, 0x78, 0x69, 0x73, 0x74, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0e, 0x66, 0x63, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x45, 0x78, 0x69, 0x73, 0x74,
+	0x73, 0x12, 0x26, 0x0a, 0x0e, 0x63, 0x68, 0x42, 0x69, 0x6e, 0x61, 0x72, 0x79, 0x45, 0x78, 0x69,
+	0x73, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0e, 0x63, 0x68, 0x42, 0x69, 0x6e,
+	0x61, 0x72, 0x79, 0x45, 0x78, 0x69, 0x73, 0x74, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x6f,
+	0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x70, 0x72, 0x6f,
+	0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x77, 0x61, 0x72, 0x6d, 0x4e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x77, 0x61, 0x72,
+	0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x6e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x50, 0x6f, 0x6f, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x18, 0x09, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x6f, 0x6f, 0x6c, 0x53,
+	0x69, 0x7a, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x18,
+	0x0a, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x64, 0x72, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x22,
+	0x37, 0x0a, 0x17, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x44, 0x72, 0x61,
+	0x69, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x73,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x65, 0x73, 0x22, 0x3f, 0x0a, 0x0b, 0x53, 0x74, 0x61, 0x6c,
+	0x65, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x22, 0x4c, 0x0a, 0x22, 0x48, 0x6f, 0x73,
+	0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74, 0x61, 0x6c, 0x65,
+	0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x26, 0x0a, 0x07, 0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
+	0x32, 0x0c, 0x2e, 0x53, 0x74, 0x61, 0x6c, 0x65, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x07,
+	0x63, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x22, 0x50, 0x0a, 0x1c, 0x48, 0x6f, 0x73, 0x74, 0x4d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61, 0x70, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x61, 0x6c, 0x6c, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x61, 0x6c, 0x6c, 0x12, 0x1e, 0x0a, 0x0a, 0x73, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x73,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x73, 0x22, 0xb6, 0x01, 0x0a, 0x1d, 0x48, 0x6f,
+	0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61, 0x70, 0x43, 0x67, 0x72, 0x6f,
+	0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72,
+	0x65, 0x61, 0x70, 0x65, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61,
+	0x70, 0x65, 0x64, 0x12, 0x42, 0x0a, 0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x2a, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x52, 0x65, 0x61, 0x70, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x06, 0x66, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x1a, 0x39, 0x0a, 0x0b, 0x46, 0x61, 0x69, 0x6c, 0x65,
+	0x64, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0x92, 0x02, 0x0a, 0x0c, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49,
+	0x6e, 0x66, 0x6f, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49,
+	0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x6d, 0x6d, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x6d, 0x6d, 0x54, 0x79, 0x70, 0x65, 0x12, 0x1c, 0x0a,
+	0x09, 0x76, 0x63, 0x70, 0x75, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x09, 0x76, 0x63, 0x70, 0x75, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x42, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x4d, 0x42, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x69, 0x73, 0x6b, 0x53,
+	0x69, 0x7a, 0x65, 0x4d, 0x42, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x64, 0x69, 0x73,
+	0x6b, 0x53, 0x69, 0x7a, 0x65, 0x4d, 0x42, 0x12, 0x24, 0x0a, 0x0d, 0x6b, 0x65, 0x72, 0x6e, 0x65,
+	0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d,
+	0x6b, 0x65, 0x72, 0x6e, 0x65, 0x6c, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x24, 0x0a,
+	0x0d, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x50, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x18, 0x07,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x69, 0x6d, 0x61, 0x67, 0x65, 0x73, 0x50, 0x72, 0x65, 0x73,
+	0x65, 0x6e, 0x74, 0x12, 0x22, 0x0a, 0x0c, 0x6d, 0x69, 0x73, 0x73, 0x69, 0x6e, 0x67, 0x46, 0x69,
+	0x6c, 0x65, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0c, 0x6d, 0x69, 0x73, 0x73, 0x69,
+	0x6e, 0x67, 0x46, 0x69, 0x6c, 0x65, 0x73, 0x22, 0x4e, 0x0a, 0x1f, 0x48, 0x6f, 0x73, 0x74, 0x4d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x2b, 0x0a, 0x09, 0x74, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0d, 0x2e,
+	0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x6e, 0x66, 0x6f, 0x52, 0x09, 0x74, 0x65,
+	0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x22, 0x76, 0x0a, 0x20, 0x48, 0x6f, 0x73, 0x74, 0x4d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x62,
+	0x61, 0x73, 0x65, 0x44, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x62, 0x61,
+	0x73, 0x65, 0x44, 0x69, 0x72, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x69, 0x66, 0x66, 0x44, 0x69, 0x72,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x08, 0x64, 0x69, 0x66, 0x66, 0x44, 0x69, 0x72,
+	0x73, 0x12, 0x1c, 0x0a, 0x09, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x44, 0x69, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x44, 0x69, 0x72, 0x22,
+	0x49, 0x0a, 0x1d, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x57, 0x61, 0x72,
+	0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x70, 0x76, 0x36, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x04, 0x69, 0x70, 0x76, 0x36, 0x22, 0xaa, 0x01, 0x0a, 0x1e, 0x48,
+	0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x57, 0x61, 0x72, 0x6d, 0x4e, 0x65, 0x74,
+	0x77, 0x6f, 0x72, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x61, 0x6c, 0x72, 0x65, 0x61,
+	0x64, 0x79, 0x57, 0x61, 0x72, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x61, 0x6c,
+	0x72, 0x65, 0x61, 0x64, 0x79, 0x57, 0x61, 0x72, 0x6d, 0x12, 0x22, 0x0a, 0x0c, 0x77, 0x61, 0x72,
+	0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x0c, 0x77, 0x61, 0x72, 0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x12, 0x28, 0x0a,
+	0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x50, 0x6f, 0x6f, 0x6c, 0x53, 0x69, 0x7a, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0f, 0x6e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x50,
+	0x6f, 0x6f, 0x6c, 0x53, 0x69, 0x7a, 0x65, 0x22, 0x55, 0x0a, 0x19, 0x48, 0x6f, 0x73, 0x74, 0x4d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x1e, 0x0a, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
+	0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x49, 0x44, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x22, 0x59,
+	0x0a, 0x0d, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d,
+	0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x4d, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0xc6, 0x01, 0x0a, 0x1a, 0x48, 0x6f,
+	0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x73, 0x73,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x70, 0x61, 0x73, 0x73, 0x65, 0x64,
+	0x12, 0x1c, 0x0a, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x49, 0x44, 0x12, 0x26,
+	0x0a, 0x06, 0x70, 0x68, 0x61, 0x73, 0x65, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0e,
+	0x2e, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x50, 0x68, 0x61, 0x73, 0x65, 0x52, 0x06,
+	0x70, 0x68, 0x61, 0x73, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f,
+	0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x65, 0x78, 0x69, 0x74, 0x43, 0x6f,
+	0x64, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x64, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74,
+	0x64, 0x65, 0x72, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x74, 0x64, 0x65,
+	0x72, 0x72, 0x22, 0x4f, 0x0a, 0x2b, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d,
+	0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x20, 0x0a, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0b, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
+	0x49, 0x44, 0x73, 0x22, 0x95, 0x01, 0x0a, 0x15, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
+	0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x12, 0x1e, 0x0a,
+	0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0a, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x49, 0x44, 0x12, 0x1e, 0x0a,
+	0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0a, 0x63, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x6c, 0x65, 0x12, 0x1a, 0x0a,
+	0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52,
+	0x08, 0x70, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x73, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x50, 0x55, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x50, 0x55, 0x22, 0x7e, 0x0a, 0x2c, 0x48,
+	0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x68,
+	0x6f, 0x73, 0x74, 0x43, 0x50, 0x55, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x68, 0x6f,
+	0x73, 0x74, 0x43, 0x50, 0x55, 0x12, 0x34, 0x0a, 0x09, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79,
+	0x52, 0x09, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x2a, 0x7d, 0x0a, 0x0c, 0x53,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x0d, 0x0a, 0x09, 0x55,
+	0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x59, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x49, 0x4e,
+	0x56, 0x41, 0x4c, 0x49, 0x44, 0x10, 0x01, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49,
+	0x4e, 0x47, 0x10, 0x02, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x54, 0x4f, 0x50, 0x10, 0x03, 0x12, 0x0d,
+	0x0a, 0x09, 0x43, 0x4c, 0x45, 0x41, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x04, 0x12, 0x10, 0x0a,
+	0x0c, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x54, 0x49, 0x4e, 0x47, 0x10, 0x05, 0x12,
+	0x0a, 0x0a, 0x06, 0x4f, 0x52, 0x50, 0x48, 0x41, 0x4e, 0x10, 0x06, 0x12, 0x0d, 0x0a, 0x09, 0x4d,
+	0x49, 0x47, 0x52, 0x41, 0x54, 0x49, 0x4e, 0x47, 0x10, 0x07, 0x2a, 0x85, 0x02, 0x0a, 0x0b, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x59, 0x10, 0x00, 0x12,
+	0x1b, 0x0a, 0x17, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b,
+	0x5f, 0x45, 0x58, 0x48, 0x41, 0x55, 0x53, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x1c, 0x0a, 0x18,
+	0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x54, 0x45, 0x4d, 0x50, 0x4c, 0x41, 0x54, 0x45, 0x5f, 0x4e,
+	0x4f, 0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x02, 0x12, 0x1e, 0x0a, 0x1a, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53, 0x48, 0x4f, 0x54, 0x5f, 0x49, 0x4e, 0x5f,
+	0x50, 0x52, 0x4f, 0x47, 0x52, 0x45, 0x53, 0x53, 0x10, 0x03, 0x12, 0x17, 0x0a, 0x13, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54,
+	0x45, 0x10, 0x04, 0x12, 0x12, 0x0a, 0x0e, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x48, 0x4f, 0x53,
+	0x54, 0x5f, 0x4f, 0x4f, 0x4d, 0x10, 0x05, 0x12, 0x23, 0x0a, 0x1f, 0x45, 0x52, 0x52, 0x4f, 0x52,
+	0x5f, 0x45, 0x58, 0x54, 0x52, 0x41, 0x5f, 0x44, 0x49, 0x53, 0x4b, 0x5f, 0x51, 0x55, 0x4f, 0x54,
+	0x41, 0x5f, 0x45, 0x58, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x06, 0x12, 0x18, 0x0a, 0x14,
+	0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x48, 0x4f, 0x53, 0x54, 0x5f, 0x44, 0x49, 0x53, 0x4b, 0x5f,
+	0x46, 0x55, 0x4c, 0x4c, 0x10, 0x07, 0x12, 0x1a, 0x0a, 0x16, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f,
+	0x54, 0x4f, 0x4f, 0x5f, 0x4d, 0x41, 0x4e, 0x59, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x53,
+	0x10, 0x08, 0x2a, 0xa2, 0x01, 0x0a, 0x10, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x56, 0x45, 0x4e, 0x54,
+	0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x59, 0x10, 0x00, 0x12, 0x11, 0x0a, 0x0d,
+	0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12,
+	0x11, 0x0a, 0x0d, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47,
+	0x10, 0x02, 0x12, 0x16, 0x0a, 0x12, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x53, 0x4e, 0x41, 0x50,
+	0x53, 0x48, 0x4f, 0x54, 0x54, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x12, 0x11, 0x0a, 0x0d, 0x45, 0x56,
+	0x45, 0x4e, 0x54, 0x5f, 0x53, 0x54, 0x4f, 0x50, 0x50, 0x45, 0x44, 0x10, 0x04, 0x12, 0x11, 0x0a,
+	0x0d, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x43, 0x4c, 0x45, 0x41, 0x4e, 0x45, 0x44, 0x10, 0x05,
+	0x12, 0x15, 0x0a, 0x11, 0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x44, 0x45, 0x41, 0x43, 0x54, 0x49,
+	0x56, 0x41, 0x54, 0x45, 0x44, 0x10, 0x06, 0x2a, 0x5f, 0x0a, 0x0c, 0x4e, 0x65, 0x74, 0x77, 0x6f,
+	0x72, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12, 0x15, 0x0a, 0x11, 0x4e, 0x45, 0x54, 0x57, 0x4f,
+	0x52, 0x4b, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x59, 0x10, 0x00, 0x12, 0x13,
+	0x0a, 0x0f, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b, 0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49,
+	0x44, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52, 0x4b, 0x5f, 0x55,
+	0x53, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x10, 0x0a, 0x0c, 0x4e, 0x45, 0x54, 0x57, 0x4f, 0x52,
+	0x4b, 0x5f, 0x46, 0x52, 0x45, 0x45, 0x10, 0x03, 0x32, 0xf1, 0x0b, 0x0a, 0x07, 0x53, 0x61, 0x6e,
+	0x64, 0x62, 0x6f, 0x78, 0x12, 0x37, 0x0a, 0x06, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x12, 0x15,
+	0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a,
+	0x0b, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x12, 0x1a, 0x2e, 0x53,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x31, 0x0a, 0x04, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x13, 0x2e,
+	0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x14, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4c, 0x69, 0x73, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x12, 0x15, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74,
+	0x79, 0x12, 0x3d, 0x0a, 0x08, 0x44, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65, 0x12, 0x19, 0x2e,
+	0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x44, 0x65, 0x61, 0x63, 0x74, 0x69, 0x76, 0x61, 0x74,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x12, 0x58, 0x0a, 0x11, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d,
+	0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x20, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43, 0x6f, 0x6e, 0x73, 0x75, 0x6d, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x08, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x17, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x18, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x5b, 0x0a, 0x12, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x41, 0x73, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12,
+	0x21, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f,
+	0x74, 0x41, 0x73, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x22, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x41, 0x73, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x12, 0x15, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x35, 0x0a, 0x05, 0x50, 0x75, 0x72, 0x67, 0x65, 0x12, 0x14, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x50, 0x75, 0x72, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16,
 	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x3d, 0x0a, 0x08, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
-	0x6f, 0x74, 0x12, 0x17, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70,
-	0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x53, 0x61,
-	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x73,
-	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x37, 0x0a, 0x06, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x12,
-	0x15, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
-	0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35,
-	0x0a, 0x05, 0x50, 0x75, 0x72, 0x67, 0x65, 0x12, 0x14, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f,
-	0x78, 0x50, 0x75, 0x72, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x46, 0x0a, 0x0b, 0x50, 0x75, 0x72, 0x67, 0x65, 0x46,
+	0x61, 0x69, 0x6c, 0x65, 0x64, 0x12, 0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x50,
+	0x75, 0x72, 0x67, 0x65, 0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1b, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x50, 0x75, 0x72, 0x67, 0x65,
+	0x46, 0x61, 0x69, 0x6c, 0x65, 0x64, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49,
+	0x0a, 0x0c, 0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72, 0x70, 0x68, 0x61, 0x6e, 0x73, 0x12, 0x1b,
+	0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72, 0x70,
+	0x68, 0x61, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x53, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x41, 0x64, 0x6f, 0x70, 0x74, 0x4f, 0x72, 0x70, 0x68, 0x61, 0x6e,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x46, 0x0a, 0x0b, 0x50, 0x65, 0x6e,
+	0x64, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x73, 0x12, 0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x50, 0x65, 0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x50, 0x65,
+	0x6e, 0x64, 0x69, 0x6e, 0x67, 0x4c, 0x6f, 0x67, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x46, 0x0a, 0x0b, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61,
+	0x12, 0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x53,
+	0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x53, 0x65, 0x74, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3d, 0x0a, 0x09, 0x53, 0x79, 0x6e,
+	0x63, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x18, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x53, 0x79, 0x6e, 0x63, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x59, 0x0a, 0x17, 0x52, 0x65, 0x66, 0x72,
+	0x65, 0x73, 0x68, 0x50, 0x72, 0x6f, 0x6d, 0x65, 0x74, 0x68, 0x65, 0x75, 0x73, 0x54, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x12, 0x26, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x52, 0x65, 0x66,
+	0x72, 0x65, 0x73, 0x68, 0x50, 0x72, 0x6f, 0x6d, 0x65, 0x74, 0x68, 0x65, 0x75, 0x73, 0x54, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x12, 0x49, 0x0a, 0x0f, 0x52, 0x65, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e,
+	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x12, 0x1e, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78,
+	0x52, 0x65, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x41,
+	0x0a, 0x0e, 0x49, 0x6e, 0x66, 0x6c, 0x61, 0x74, 0x65, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x6f, 0x6e,
+	0x12, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x41, 0x0a, 0x0e, 0x44, 0x65, 0x66, 0x6c, 0x61, 0x74, 0x65, 0x42, 0x61, 0x6c, 0x6c,
+	0x6f, 0x6f, 0x6e, 0x12, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x42, 0x61, 0x6c,
+	0x6c, 0x6f, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e, 0x53, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x42, 0x61, 0x6c, 0x6c, 0x6f, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x41, 0x0a, 0x0b, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x53,
+	0x65, 0x6e, 0x64, 0x12, 0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x4d, 0x69, 0x67,
+	0x72, 0x61, 0x74, 0x65, 0x53, 0x65, 0x6e, 0x64, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x47, 0x0a, 0x0e, 0x4d, 0x69, 0x67, 0x72, 0x61,
+	0x74, 0x65, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x12, 0x1d, 0x2e, 0x53, 0x61, 0x6e, 0x64,
+	0x62, 0x6f, 0x78, 0x4d, 0x69, 0x67, 0x72, 0x61, 0x74, 0x65, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62,
+	0x6f, 0x78, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x3a, 0x0a, 0x0b, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12,
+	0x1a, 0x2e, 0x53, 0x61, 0x6e, 0x64, 0x62, 0x6f, 0x78, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76,
+	0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x0d, 0x2e, 0x53, 0x61,
+	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x32, 0xe1, 0x07, 0x0a,
+	0x0a, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x12, 0x40, 0x0a, 0x0e, 0x52,
+	0x65, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x16, 0x2e,
 	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
-	0x45, 0x6d, 0x70, 0x74, 0x79, 0x32, 0x9c, 0x01, 0x0a, 0x0a, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61,
-	0x6e, 0x61, 0x67, 0x65, 0x12, 0x40, 0x0a, 0x0e, 0x52, 0x65, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65,
-	0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16,
+	0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4c, 0x0a,
+	0x0f, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x45, 0x6e, 0x76,
+	0x12, 0x21, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x6c, 0x65,
+	0x61, 0x6e, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x45, 0x6e, 0x76, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x47, 0x0a, 0x0c, 0x4c,
+	0x69, 0x73, 0x74, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x1a, 0x1f, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x4c, 0x69, 0x73, 0x74, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12, 0x16,
 	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
-	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4c, 0x0a, 0x0f, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x4e,
-	0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x45, 0x6e, 0x76, 0x12, 0x21, 0x2e, 0x48, 0x6f, 0x73, 0x74,
-	0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x6c, 0x65, 0x61, 0x6e, 0x4e, 0x65, 0x74, 0x77, 0x6f,
-	0x72, 0x6b, 0x45, 0x6e, 0x76, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
-	0x6d, 0x70, 0x74, 0x79, 0x42, 0x59, 0x5a, 0x57, 0x68, 0x74, 0x74, 0x70, 0x73, 0x3a, 0x2f, 0x2f,
-	0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x58, 0x2d, 0x63, 0x6f, 0x64,
-	0x65, 0x2d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x73, 0x61,
-	0x6e, 0x64, 0x62, 0x6f, 0x78, 0x2d, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x61,
-	0x63, 0x6b, 0x61, 0x67, 0x65, 0x73, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2f, 0x67, 0x72,
-	0x70, 0x63, 0x2f, 0x6f, 0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x62,
-	0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x19, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e,
+	0x61, 0x67, 0x65, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x4f, 0x0a, 0x10, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74, 0x61, 0x6c, 0x65, 0x43, 0x67,
+	0x72, 0x6f, 0x75, 0x70, 0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x23, 0x2e,
+	0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x53, 0x74,
+	0x61, 0x6c, 0x65, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0b, 0x52, 0x65, 0x61, 0x70, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70,
+	0x73, 0x12, 0x1d, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x52, 0x65,
+	0x61, 0x70, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x1e, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x52, 0x65, 0x61,
+	0x70, 0x43, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x49, 0x0a, 0x0d, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
+	0x73, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x20, 0x2e, 0x48, 0x6f, 0x73, 0x74,
+	0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61,
+	0x74, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x4c, 0x0a, 0x0f, 0x43,
+	0x6f, 0x6d, 0x70, 0x61, 0x63, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x12, 0x21,
+	0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6d, 0x70, 0x61,
+	0x63, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x4f, 0x0a, 0x0c, 0x57, 0x61, 0x72,
+	0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72, 0x6b, 0x73, 0x12, 0x1e, 0x2e, 0x48, 0x6f, 0x73, 0x74,
+	0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x57, 0x61, 0x72, 0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x48, 0x6f, 0x73, 0x74,
+	0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x57, 0x61, 0x72, 0x6d, 0x4e, 0x65, 0x74, 0x77, 0x6f, 0x72,
+	0x6b, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x05, 0x44, 0x72,
+	0x61, 0x69, 0x6e, 0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x18, 0x2e, 0x48, 0x6f,
+	0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x44, 0x72, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x39, 0x0a, 0x07, 0x55, 0x6e, 0x64, 0x72, 0x61, 0x69, 0x6e,
+	0x12, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
+	0x12, 0x43, 0x0a, 0x08, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x12, 0x1a, 0x2e, 0x48,
+	0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73,
+	0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d,
+	0x61, 0x6e, 0x61, 0x67, 0x65, 0x53, 0x65, 0x6c, 0x66, 0x54, 0x65, 0x73, 0x74, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x79, 0x0a, 0x1a, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c,
+	0x69, 0x74, 0x79, 0x12, 0x2c, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65,
+	0x43, 0x68, 0x65, 0x63, 0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d,
+	0x70, 0x61, 0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x2d, 0x2e, 0x48, 0x6f, 0x73, 0x74, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x43, 0x68,
+	0x65, 0x63, 0x6b, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x61,
+	0x74, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x42, 0x59, 0x5a, 0x57, 0x68, 0x74, 0x74, 0x70, 0x73, 0x3a, 0x2f, 0x2f, 0x67, 0x69, 0x74, 0x68,
+	0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x58, 0x2d, 0x63, 0x6f, 0x64, 0x65, 0x2d, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x70, 0x72, 0x65, 0x74, 0x65, 0x72, 0x2f, 0x73, 0x61, 0x6e, 0x64, 0x62, 0x6f,
+	0x78, 0x2d, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2f, 0x70, 0x61, 0x63, 0x6b, 0x61, 0x67,
+	0x65, 0x73, 0x2f, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x6f,
+	0x72, 0x63, 0x68, 0x65, 0x73, 0x74, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x62, 0x06, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1005,59 +5030,198 @@ func file_orchestrator_proto_rawDescGZIP() []byte {
 	return file_orchestrator_proto_rawDescData
 }
 
-var file_orchestrator_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_orchestrator_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_orchestrator_proto_msgTypes = make([]protoimpl.MessageInfo, 71)
 var file_orchestrator_proto_goTypes = []any{
-	(SandboxState)(0),                        // 0: SandboxState
-	(*SandboxInfo)(nil),                      // 1: SandboxInfo
-	(*SandboxCreateRequest)(nil),             // 2: SandboxCreateRequest
-	(*SandboxCreateResponse)(nil),            // 3: SandboxCreateResponse
-	(*SandboxListRequest)(nil),               // 4: SandboxListRequest
-	(*SandboxListResponse)(nil),              // 5: SandboxListResponse
-	(*SandboxDeleteRequest)(nil),             // 6: SandboxDeleteRequest
-	(*SandboxDeactivateRequest)(nil),         // 7: SandboxDeactivateRequest
-	(*SandboxSearchRequest)(nil),             // 8: SandboxSearchRequest
-	(*SandboxSearchResponse)(nil),            // 9: SandboxSearchResponse
-	(*SandboxSnapshotRequest)(nil),           // 10: SandboxSnapshotRequest
-	(*SandboxSnapshotResponse)(nil),          // 11: SandboxSnapshotResponse
-	(*SandboxPurgeRequest)(nil),              // 12: SandboxPurgeRequest
-	(*HostManageCleanNetworkEnvRequest)(nil), // 13: HostManageCleanNetworkEnvRequest
-	nil,                                      // 14: SandboxInfo.MetadataEntry
-	nil,                                      // 15: SandboxCreateRequest.MetadataEntry
-	(*timestamppb.Timestamp)(nil),            // 16: google.protobuf.Timestamp
-	(*emptypb.Empty)(nil),                    // 17: google.protobuf.Empty
+	(SandboxState)(0),                                    // 0: SandboxState
+	(ErrorReason)(0),                                     // 1: ErrorReason
+	(SandboxEventType)(0),                                // 2: SandboxEventType
+	(NetworkState)(0),                                    // 3: NetworkState
+	(*ErrorDetail)(nil),                                  // 4: ErrorDetail
+	(*SandboxInfo)(nil),                                  // 5: SandboxInfo
+	(*SandboxCreateRequest)(nil),                         // 6: SandboxCreateRequest
+	(*EgressPolicy)(nil),                                 // 7: EgressPolicy
+	(*EgressRule)(nil),                                   // 8: EgressRule
+	(*DiskSpec)(nil),                                     // 9: DiskSpec
+	(*PortForward)(nil),                                  // 10: PortForward
+	(*SandboxCreateResponse)(nil),                        // 11: SandboxCreateResponse
+	(*SandboxCreateBatchRequest)(nil),                    // 12: SandboxCreateBatchRequest
+	(*SandboxCreateBatchItem)(nil),                       // 13: SandboxCreateBatchItem
+	(*SandboxCreateBatchResponse)(nil),                   // 14: SandboxCreateBatchResponse
+	(*SandboxListRequest)(nil),                           // 15: SandboxListRequest
+	(*SandboxListResponse)(nil),                          // 16: SandboxListResponse
+	(*SandboxDeleteRequest)(nil),                         // 17: SandboxDeleteRequest
+	(*SandboxDeactivateRequest)(nil),                     // 18: SandboxDeactivateRequest
+	(*SandboxSearchRequest)(nil),                         // 19: SandboxSearchRequest
+	(*SandboxSearchResponse)(nil),                        // 20: SandboxSearchResponse
+	(*SandboxSnapshotRequest)(nil),                       // 21: SandboxSnapshotRequest
+	(*SandboxSnapshotResponse)(nil),                      // 22: SandboxSnapshotResponse
+	(*SandboxSnapshotAsTemplateRequest)(nil),             // 23: SandboxSnapshotAsTemplateRequest
+	(*SandboxSnapshotAsTemplateResponse)(nil),            // 24: SandboxSnapshotAsTemplateResponse
+	(*SandboxPendingLogsRequest)(nil),                    // 25: SandboxPendingLogsRequest
+	(*SandboxPendingLogsResponse)(nil),                   // 26: SandboxPendingLogsResponse
+	(*SandboxSetMetadataRequest)(nil),                    // 27: SandboxSetMetadataRequest
+	(*SandboxSetMetadataResponse)(nil),                   // 28: SandboxSetMetadataResponse
+	(*SandboxSyncClockRequest)(nil),                      // 29: SandboxSyncClockRequest
+	(*SandboxRefreshPrometheusTargetRequest)(nil),        // 30: SandboxRefreshPrometheusTargetRequest
+	(*SandboxRecreateNetworkRequest)(nil),                // 31: SandboxRecreateNetworkRequest
+	(*SandboxBalloonRequest)(nil),                        // 32: SandboxBalloonRequest
+	(*SandboxBalloonResponse)(nil),                       // 33: SandboxBalloonResponse
+	(*SandboxMemoryConsumptionRequest)(nil),              // 34: SandboxMemoryConsumptionRequest
+	(*SandboxMemoryConsumption)(nil),                     // 35: SandboxMemoryConsumption
+	(*SandboxMemoryConsumptionResponse)(nil),             // 36: SandboxMemoryConsumptionResponse
+	(*SandboxPurgeRequest)(nil),                          // 37: SandboxPurgeRequest
+	(*SandboxPurgeFailedRequest)(nil),                    // 38: SandboxPurgeFailedRequest
+	(*SandboxPurgeFailedResponse)(nil),                   // 39: SandboxPurgeFailedResponse
+	(*SandboxAdoptOrphansRequest)(nil),                   // 40: SandboxAdoptOrphansRequest
+	(*SandboxAdoptOrphanSkipped)(nil),                    // 41: SandboxAdoptOrphanSkipped
+	(*SandboxAdoptOrphansResponse)(nil),                  // 42: SandboxAdoptOrphansResponse
+	(*SandboxMigrateSendRequest)(nil),                    // 43: SandboxMigrateSendRequest
+	(*SandboxMigrateReceiveRequest)(nil),                 // 44: SandboxMigrateReceiveRequest
+	(*SandboxWatchEventsRequest)(nil),                    // 45: SandboxWatchEventsRequest
+	(*SandboxEvent)(nil),                                 // 46: SandboxEvent
+	(*HostManageCleanNetworkEnvRequest)(nil),             // 47: HostManageCleanNetworkEnvRequest
+	(*NetworkInfo)(nil),                                  // 48: NetworkInfo
+	(*HostManageListNetworksResponse)(nil),               // 49: HostManageListNetworksResponse
+	(*HostManageHealthResponse)(nil),                     // 50: HostManageHealthResponse
+	(*HostManageDrainResponse)(nil),                      // 51: HostManageDrainResponse
+	(*StaleCgroup)(nil),                                  // 52: StaleCgroup
+	(*HostManageListStaleCgroupsResponse)(nil),           // 53: HostManageListStaleCgroupsResponse
+	(*HostManageReapCgroupsRequest)(nil),                 // 54: HostManageReapCgroupsRequest
+	(*HostManageReapCgroupsResponse)(nil),                // 55: HostManageReapCgroupsResponse
+	(*TemplateInfo)(nil),                                 // 56: TemplateInfo
+	(*HostManageListTemplatesResponse)(nil),              // 57: HostManageListTemplatesResponse
+	(*HostManageCompactSnapshotRequest)(nil),             // 58: HostManageCompactSnapshotRequest
+	(*HostManageWarmNetworksRequest)(nil),                // 59: HostManageWarmNetworksRequest
+	(*HostManageWarmNetworksResponse)(nil),               // 60: HostManageWarmNetworksResponse
+	(*HostManageSelfTestRequest)(nil),                    // 61: HostManageSelfTestRequest
+	(*SelfTestPhase)(nil),                                // 62: SelfTestPhase
+	(*HostManageSelfTestResponse)(nil),                   // 63: HostManageSelfTestResponse
+	(*HostManageCheckSnapshotCompatibilityRequest)(nil),  // 64: HostManageCheckSnapshotCompatibilityRequest
+	(*SnapshotCompatibility)(nil),                        // 65: SnapshotCompatibility
+	(*HostManageCheckSnapshotCompatibilityResponse)(nil), // 66: HostManageCheckSnapshotCompatibilityResponse
+	nil,                           // 67: SandboxInfo.MetadataEntry
+	nil,                           // 68: SandboxCreateRequest.MetadataEntry
+	nil,                           // 69: SandboxCreateRequest.EnvEntry
+	nil,                           // 70: SandboxCreateBatchRequest.MetadataEntry
+	nil,                           // 71: SandboxListRequest.MetadataSelectorEntry
+	nil,                           // 72: SandboxSetMetadataRequest.MetadataEntry
+	nil,                           // 73: SandboxSetMetadataResponse.MetadataEntry
+	nil,                           // 74: HostManageReapCgroupsResponse.FailedEntry
+	(*timestamppb.Timestamp)(nil), // 75: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 76: google.protobuf.Empty
 }
 var file_orchestrator_proto_depIdxs = []int32{
-	16, // 0: SandboxInfo.startTime:type_name -> google.protobuf.Timestamp
-	0,  // 1: SandboxInfo.state:type_name -> SandboxState
-	14, // 2: SandboxInfo.metadata:type_name -> SandboxInfo.MetadataEntry
-	15, // 3: SandboxCreateRequest.metadata:type_name -> SandboxCreateRequest.MetadataEntry
-	1,  // 4: SandboxCreateResponse.info:type_name -> SandboxInfo
-	1,  // 5: SandboxListResponse.sandboxes:type_name -> SandboxInfo
-	1,  // 6: SandboxSearchResponse.sandbox:type_name -> SandboxInfo
-	2,  // 7: Sandbox.Create:input_type -> SandboxCreateRequest
-	4,  // 8: Sandbox.List:input_type -> SandboxListRequest
-	6,  // 9: Sandbox.Delete:input_type -> SandboxDeleteRequest
-	7,  // 10: Sandbox.Deactive:input_type -> SandboxDeactivateRequest
-	10, // 11: Sandbox.Snapshot:input_type -> SandboxSnapshotRequest
-	8,  // 12: Sandbox.Search:input_type -> SandboxSearchRequest
-	12, // 13: Sandbox.Purge:input_type -> SandboxPurgeRequest
-	17, // 14: HostManage.RecreateCgroup:input_type -> google.protobuf.Empty
-	13, // 15: HostManage.CleanNetworkEnv:input_type -> HostManageCleanNetworkEnvRequest
-	3,  // 16: Sandbox.Create:output_type -> SandboxCreateResponse
-	5,  // 17: Sandbox.List:output_type -> SandboxListResponse
-	17, // 18: Sandbox.Delete:output_type -> google.protobuf.Empty
-	17, // 19: Sandbox.Deactive:output_type -> google.protobuf.Empty
-	11, // 20: Sandbox.Snapshot:output_type -> SandboxSnapshotResponse
-	9,  // 21: Sandbox.Search:output_type -> SandboxSearchResponse
-	17, // 22: Sandbox.Purge:output_type -> google.protobuf.Empty
-	17, // 23: HostManage.RecreateCgroup:output_type -> google.protobuf.Empty
-	17, // 24: HostManage.CleanNetworkEnv:output_type -> google.protobuf.Empty
-	16, // [16:25] is the sub-list for method output_type
-	7,  // [7:16] is the sub-list for method input_type
-	7,  // [7:7] is the sub-list for extension type_name
-	7,  // [7:7] is the sub-list for extension extendee
-	0,  // [0:7] is the sub-list for field type_name
+	1,  // 0: ErrorDetail.reason:type_name -> ErrorReason
+	75, // 1: SandboxInfo.startTime:type_name -> google.protobuf.Timestamp
+	0,  // 2: SandboxInfo.state:type_name -> SandboxState
+	67, // 3: SandboxInfo.metadata:type_name -> SandboxInfo.MetadataEntry
+	10, // 4: SandboxInfo.portForwards:type_name -> PortForward
+	68, // 5: SandboxCreateRequest.metadata:type_name -> SandboxCreateRequest.MetadataEntry
+	9,  // 6: SandboxCreateRequest.extraDisks:type_name -> DiskSpec
+	69, // 7: SandboxCreateRequest.env:type_name -> SandboxCreateRequest.EnvEntry
+	7,  // 8: SandboxCreateRequest.egressPolicy:type_name -> EgressPolicy
+	10, // 9: SandboxCreateRequest.portForwards:type_name -> PortForward
+	8,  // 10: EgressPolicy.allow:type_name -> EgressRule
+	8,  // 11: EgressPolicy.deny:type_name -> EgressRule
+	5,  // 12: SandboxCreateResponse.info:type_name -> SandboxInfo
+	70, // 13: SandboxCreateBatchRequest.metadata:type_name -> SandboxCreateBatchRequest.MetadataEntry
+	5,  // 14: SandboxCreateBatchItem.info:type_name -> SandboxInfo
+	13, // 15: SandboxCreateBatchResponse.items:type_name -> SandboxCreateBatchItem
+	71, // 16: SandboxListRequest.metadataSelector:type_name -> SandboxListRequest.MetadataSelectorEntry
+	5,  // 17: SandboxListResponse.sandboxes:type_name -> SandboxInfo
+	5,  // 18: SandboxSearchResponse.sandbox:type_name -> SandboxInfo
+	72, // 19: SandboxSetMetadataRequest.metadata:type_name -> SandboxSetMetadataRequest.MetadataEntry
+	73, // 20: SandboxSetMetadataResponse.metadata:type_name -> SandboxSetMetadataResponse.MetadataEntry
+	35, // 21: SandboxMemoryConsumptionResponse.sandboxes:type_name -> SandboxMemoryConsumption
+	5,  // 22: SandboxAdoptOrphansResponse.adopted:type_name -> SandboxInfo
+	41, // 23: SandboxAdoptOrphansResponse.skipped:type_name -> SandboxAdoptOrphanSkipped
+	6,  // 24: SandboxMigrateReceiveRequest.sandbox:type_name -> SandboxCreateRequest
+	2,  // 25: SandboxEvent.type:type_name -> SandboxEventType
+	0,  // 26: SandboxEvent.state:type_name -> SandboxState
+	75, // 27: SandboxEvent.time:type_name -> google.protobuf.Timestamp
+	3,  // 28: NetworkInfo.state:type_name -> NetworkState
+	48, // 29: HostManageListNetworksResponse.networks:type_name -> NetworkInfo
+	52, // 30: HostManageListStaleCgroupsResponse.cgroups:type_name -> StaleCgroup
+	74, // 31: HostManageReapCgroupsResponse.failed:type_name -> HostManageReapCgroupsResponse.FailedEntry
+	56, // 32: HostManageListTemplatesResponse.templates:type_name -> TemplateInfo
+	62, // 33: HostManageSelfTestResponse.phases:type_name -> SelfTestPhase
+	65, // 34: HostManageCheckSnapshotCompatibilityResponse.templates:type_name -> SnapshotCompatibility
+	6,  // 35: Sandbox.Create:input_type -> SandboxCreateRequest
+	12, // 36: Sandbox.CreateBatch:input_type -> SandboxCreateBatchRequest
+	15, // 37: Sandbox.List:input_type -> SandboxListRequest
+	17, // 38: Sandbox.Delete:input_type -> SandboxDeleteRequest
+	18, // 39: Sandbox.Deactive:input_type -> SandboxDeactivateRequest
+	34, // 40: Sandbox.MemoryConsumption:input_type -> SandboxMemoryConsumptionRequest
+	21, // 41: Sandbox.Snapshot:input_type -> SandboxSnapshotRequest
+	23, // 42: Sandbox.SnapshotAsTemplate:input_type -> SandboxSnapshotAsTemplateRequest
+	19, // 43: Sandbox.Search:input_type -> SandboxSearchRequest
+	37, // 44: Sandbox.Purge:input_type -> SandboxPurgeRequest
+	38, // 45: Sandbox.PurgeFailed:input_type -> SandboxPurgeFailedRequest
+	40, // 46: Sandbox.AdoptOrphans:input_type -> SandboxAdoptOrphansRequest
+	25, // 47: Sandbox.PendingLogs:input_type -> SandboxPendingLogsRequest
+	27, // 48: Sandbox.SetMetadata:input_type -> SandboxSetMetadataRequest
+	29, // 49: Sandbox.SyncClock:input_type -> SandboxSyncClockRequest
+	30, // 50: Sandbox.RefreshPrometheusTarget:input_type -> SandboxRefreshPrometheusTargetRequest
+	31, // 51: Sandbox.RecreateNetwork:input_type -> SandboxRecreateNetworkRequest
+	32, // 52: Sandbox.InflateBalloon:input_type -> SandboxBalloonRequest
+	32, // 53: Sandbox.DeflateBalloon:input_type -> SandboxBalloonRequest
+	43, // 54: Sandbox.MigrateSend:input_type -> SandboxMigrateSendRequest
+	44, // 55: Sandbox.MigrateReceive:input_type -> SandboxMigrateReceiveRequest
+	45, // 56: Sandbox.WatchEvents:input_type -> SandboxWatchEventsRequest
+	76, // 57: HostManage.RecreateCgroup:input_type -> google.protobuf.Empty
+	47, // 58: HostManage.CleanNetworkEnv:input_type -> HostManageCleanNetworkEnvRequest
+	76, // 59: HostManage.ListNetworks:input_type -> google.protobuf.Empty
+	76, // 60: HostManage.Health:input_type -> google.protobuf.Empty
+	76, // 61: HostManage.ListStaleCgroups:input_type -> google.protobuf.Empty
+	54, // 62: HostManage.ReapCgroups:input_type -> HostManageReapCgroupsRequest
+	76, // 63: HostManage.ListTemplates:input_type -> google.protobuf.Empty
+	58, // 64: HostManage.CompactSnapshot:input_type -> HostManageCompactSnapshotRequest
+	59, // 65: HostManage.WarmNetworks:input_type -> HostManageWarmNetworksRequest
+	76, // 66: HostManage.Drain:input_type -> google.protobuf.Empty
+	76, // 67: HostManage.Undrain:input_type -> google.protobuf.Empty
+	61, // 68: HostManage.SelfTest:input_type -> HostManageSelfTestRequest
+	64, // 69: HostManage.CheckSnapshotCompatibility:input_type -> HostManageCheckSnapshotCompatibilityRequest
+	11, // 70: Sandbox.Create:output_type -> SandboxCreateResponse
+	14, // 71: Sandbox.CreateBatch:output_type -> SandboxCreateBatchResponse
+	16, // 72: Sandbox.List:output_type -> SandboxListResponse
+	76, // 73: Sandbox.Delete:output_type -> google.protobuf.Empty
+	76, // 74: Sandbox.Deactive:output_type -> google.protobuf.Empty
+	36, // 75: Sandbox.MemoryConsumption:output_type -> SandboxMemoryConsumptionResponse
+	22, // 76: Sandbox.Snapshot:output_type -> SandboxSnapshotResponse
+	24, // 77: Sandbox.SnapshotAsTemplate:output_type -> SandboxSnapshotAsTemplateResponse
+	20, // 78: Sandbox.Search:output_type -> SandboxSearchResponse
+	76, // 79: Sandbox.Purge:output_type -> google.protobuf.Empty
+	39, // 80: Sandbox.PurgeFailed:output_type -> SandboxPurgeFailedResponse
+	42, // 81: Sandbox.AdoptOrphans:output_type -> SandboxAdoptOrphansResponse
+	26, // 82: Sandbox.PendingLogs:output_type -> SandboxPendingLogsResponse
+	28, // 83: Sandbox.SetMetadata:output_type -> SandboxSetMetadataResponse
+	76, // 84: Sandbox.SyncClock:output_type -> google.protobuf.Empty
+	76, // 85: Sandbox.RefreshPrometheusTarget:output_type -> google.protobuf.Empty
+	76, // 86: Sandbox.RecreateNetwork:output_type -> google.protobuf.Empty
+	33, // 87: Sandbox.InflateBalloon:output_type -> SandboxBalloonResponse
+	33, // 88: Sandbox.DeflateBalloon:output_type -> SandboxBalloonResponse
+	76, // 89: Sandbox.MigrateSend:output_type -> google.protobuf.Empty
+	11, // 90: Sandbox.MigrateReceive:output_type -> SandboxCreateResponse
+	46, // 91: Sandbox.WatchEvents:output_type -> SandboxEvent
+	76, // 92: HostManage.RecreateCgroup:output_type -> google.protobuf.Empty
+	76, // 93: HostManage.CleanNetworkEnv:output_type -> google.protobuf.Empty
+	49, // 94: HostManage.ListNetworks:output_type -> HostManageListNetworksResponse
+	50, // 95: HostManage.Health:output_type -> HostManageHealthResponse
+	53, // 96: HostManage.ListStaleCgroups:output_type -> HostManageListStaleCgroupsResponse
+	55, // 97: HostManage.ReapCgroups:output_type -> HostManageReapCgroupsResponse
+	57, // 98: HostManage.ListTemplates:output_type -> HostManageListTemplatesResponse
+	76, // 99: HostManage.CompactSnapshot:output_type -> google.protobuf.Empty
+	60, // 100: HostManage.WarmNetworks:output_type -> HostManageWarmNetworksResponse
+	51, // 101: HostManage.Drain:output_type -> HostManageDrainResponse
+	76, // 102: HostManage.Undrain:output_type -> google.protobuf.Empty
+	63, // 103: HostManage.SelfTest:output_type -> HostManageSelfTestResponse
+	66, // 104: HostManage.CheckSnapshotCompatibility:output_type -> HostManageCheckSnapshotCompatibilityResponse
+	70, // [70:105] is the sub-list for method output_type
+	35, // [35:70] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
 }
 
 func init() { file_orchestrator_proto_init() }
@@ -1065,16 +5229,20 @@ func file_orchestrator_proto_init() {
 	if File_orchestrator_proto != nil {
 		return
 	}
-	file_orchestrator_proto_msgTypes[0].OneofWrappers = []any{}
 	file_orchestrator_proto_msgTypes[1].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[2].OneofWrappers = []any{}
 	file_orchestrator_proto_msgTypes[8].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[9].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[11].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[16].OneofWrappers = []any{}
+	file_orchestrator_proto_msgTypes[41].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_orchestrator_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   15,
+			NumEnums:      4,
+			NumMessages:   71,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
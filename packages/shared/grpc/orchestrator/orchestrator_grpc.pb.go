@@ -20,13 +20,28 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Sandbox_Create_FullMethodName   = "/Sandbox/Create"
-	Sandbox_List_FullMethodName     = "/Sandbox/List"
-	Sandbox_Delete_FullMethodName   = "/Sandbox/Delete"
-	Sandbox_Deactive_FullMethodName = "/Sandbox/Deactive"
-	Sandbox_Snapshot_FullMethodName = "/Sandbox/Snapshot"
-	Sandbox_Search_FullMethodName   = "/Sandbox/Search"
-	Sandbox_Purge_FullMethodName    = "/Sandbox/Purge"
+	Sandbox_Create_FullMethodName                  = "/Sandbox/Create"
+	Sandbox_CreateBatch_FullMethodName             = "/Sandbox/CreateBatch"
+	Sandbox_List_FullMethodName                    = "/Sandbox/List"
+	Sandbox_Delete_FullMethodName                  = "/Sandbox/Delete"
+	Sandbox_Deactive_FullMethodName                = "/Sandbox/Deactive"
+	Sandbox_MemoryConsumption_FullMethodName       = "/Sandbox/MemoryConsumption"
+	Sandbox_Snapshot_FullMethodName                = "/Sandbox/Snapshot"
+	Sandbox_SnapshotAsTemplate_FullMethodName      = "/Sandbox/SnapshotAsTemplate"
+	Sandbox_Search_FullMethodName                  = "/Sandbox/Search"
+	Sandbox_Purge_FullMethodName                   = "/Sandbox/Purge"
+	Sandbox_PurgeFailed_FullMethodName             = "/Sandbox/PurgeFailed"
+	Sandbox_AdoptOrphans_FullMethodName            = "/Sandbox/AdoptOrphans"
+	Sandbox_PendingLogs_FullMethodName             = "/Sandbox/PendingLogs"
+	Sandbox_SetMetadata_FullMethodName             = "/Sandbox/SetMetadata"
+	Sandbox_SyncClock_FullMethodName               = "/Sandbox/SyncClock"
+	Sandbox_RefreshPrometheusTarget_FullMethodName = "/Sandbox/RefreshPrometheusTarget"
+	Sandbox_RecreateNetwork_FullMethodName         = "/Sandbox/RecreateNetwork"
+	Sandbox_InflateBalloon_FullMethodName          = "/Sandbox/InflateBalloon"
+	Sandbox_DeflateBalloon_FullMethodName          = "/Sandbox/DeflateBalloon"
+	Sandbox_MigrateSend_FullMethodName             = "/Sandbox/MigrateSend"
+	Sandbox_MigrateReceive_FullMethodName          = "/Sandbox/MigrateReceive"
+	Sandbox_WatchEvents_FullMethodName             = "/Sandbox/WatchEvents"
 )
 
 // SandboxClient is the client API for Sandbox service.
@@ -36,21 +51,74 @@ const (
 // Interface exported by the server.
 type SandboxClient interface {
 	// Create is a gRPC service that creates a new sandbox.
+	// It returns the existing sandbox if the same id is running with the same
+	// template (e.g., retried by client), UNAVAILABLE if it is snapshotting or
+	// migrating, or ALREADY_EXISTS otherwise.
 	Create(ctx context.Context, in *SandboxCreateRequest, opts ...grpc.CallOption) (*SandboxCreateResponse, error)
+	// Create multiple sandboxes from the same template concurrently.
+	CreateBatch(ctx context.Context, in *SandboxCreateBatchRequest, opts ...grpc.CallOption) (*SandboxCreateBatchResponse, error)
 	// List is a gRPC service that returns a list of all the sandboxes.
 	List(ctx context.Context, in *SandboxListRequest, opts ...grpc.CallOption) (*SandboxListResponse, error)
 	// Delete is a gRPC service that kills a sandbox.
 	Delete(ctx context.Context, in *SandboxDeleteRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	// Invoke memory reclaim for a sandbox **on host**.
 	Deactive(ctx context.Context, in *SandboxDeactivateRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	// Snapshot a sandbox with id
+	// Get the current memory consumption (on host) of sandboxes without
+	// deactivating them, which is read only.
+	MemoryConsumption(ctx context.Context, in *SandboxMemoryConsumptionRequest, opts ...grpc.CallOption) (*SandboxMemoryConsumptionResponse, error)
+	// Snapshot a sandbox with id, the memfile is compressed in background
+	// when the template enables compress_memfile.
 	Snapshot(ctx context.Context, in *SandboxSnapshotRequest, opts ...grpc.CallOption) (*SandboxSnapshotResponse, error)
+	// Snapshot a sandbox and register the snapshot (with the current rootfs)
+	// as a new template, so that later Create() with the new template id
+	// will start from the state of this sandbox. When the template enables
+	// compress_memfile, it returns once the vm is resumed (or deleted), and the
+	// template can be used after its memfile is compressed in background.
+	SnapshotAsTemplate(ctx context.Context, in *SandboxSnapshotAsTemplateRequest, opts ...grpc.CallOption) (*SandboxSnapshotAsTemplateResponse, error)
 	// search a sandbox with id
 	Search(ctx context.Context, in *SandboxSearchRequest, opts ...grpc.CallOption) (*SandboxSearchResponse, error)
 	// Purge will be invoked in rare case. typically when orchestrator crashes
 	// and forget to cleanup the sandbox. So the client can call this method
 	// to purge the orphan sandbox manually
 	Purge(ctx context.Context, in *SandboxPurgeRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Remove the files and network kept for the sandboxes failed to create
+	// (see keep_files_on_error of orchestrator config).
+	PurgeFailed(ctx context.Context, in *SandboxPurgeFailedRequest, opts ...grpc.CallOption) (*SandboxPurgeFailedResponse, error)
+	// Manage the orphan sandboxes (see List with orphan) as the ones created by
+	// this orchestrator (e.g., to Delete or Snapshot them) instead of purging.
+	// The metadata, extra disks and port forwards of orphans are not recovered.
+	AdoptOrphans(ctx context.Context, in *SandboxAdoptOrphansRequest, opts ...grpc.CallOption) (*SandboxAdoptOrphansResponse, error)
+	// Query whether the guest has buffered logs not delivered to the log collector.
+	PendingLogs(ctx context.Context, in *SandboxPendingLogsRequest, opts ...grpc.CallOption) (*SandboxPendingLogsResponse, error)
+	// Merge (or replace) the metadata of a running sandbox.
+	SetMetadata(ctx context.Context, in *SandboxSetMetadataRequest, opts ...grpc.CallOption) (*SandboxSetMetadataResponse, error)
+	// Sync the guest clock with the host on demand (it is synced automatically
+	// after boot and after resume).
+	SyncClock(ctx context.Context, in *SandboxSyncClockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Rewrite the prometheus target file of a sandbox (e.g., when the write
+	// after create failed), so that it can be scraped again.
+	RefreshPrometheusTarget(ctx context.Context, in *SandboxRefreshPrometheusTargetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Rebuild the network of a running sandbox in place (e.g., after its netns
+	// or veth was deleted externally) without restarting the vm. The network
+	// index (i.e., the addresses) is not changed.
+	RecreateNetwork(ctx context.Context, in *SandboxRecreateNetworkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Inflate the balloon of a sandbox to the target size to reclaim its memory,
+	// the template should be built with balloon enabled.
+	InflateBalloon(ctx context.Context, in *SandboxBalloonRequest, opts ...grpc.CallOption) (*SandboxBalloonResponse, error)
+	// Deflate the balloon of a sandbox to the target size to give back memory.
+	DeflateBalloon(ctx context.Context, in *SandboxBalloonRequest, opts ...grpc.CallOption) (*SandboxBalloonResponse, error)
+	// Live migrate a running sandbox to the receiver (see MigrateReceive) on
+	// another host, the sandbox is deleted after migrated successfully. Only
+	// supported by cloud hypervisor. The caller is responsible for forwarding the
+	// unix socket between hosts, and for the network state (e.g., connections to
+	// the sandbox) and disk contents, which are not migrated.
+	MigrateSend(ctx context.Context, in *SandboxMigrateSendRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Create a sandbox receiving the vm from MigrateSend, it returns once the
+	// migration completes (and the vm is running).
+	MigrateReceive(ctx context.Context, in *SandboxMigrateReceiveRequest, opts ...grpc.CallOption) (*SandboxCreateResponse, error)
+	// Watch the lifecycle events of sandboxes (since the call), the events are
+	// dropped if the client does not receive them in time (see SandboxEvent.dropped).
+	WatchEvents(ctx context.Context, in *SandboxWatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SandboxEvent], error)
 }
 
 type sandboxClient struct {
@@ -71,6 +139,16 @@ func (c *sandboxClient) Create(ctx context.Context, in *SandboxCreateRequest, op
 	return out, nil
 }
 
+func (c *sandboxClient) CreateBatch(ctx context.Context, in *SandboxCreateBatchRequest, opts ...grpc.CallOption) (*SandboxCreateBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxCreateBatchResponse)
+	err := c.cc.Invoke(ctx, Sandbox_CreateBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *sandboxClient) List(ctx context.Context, in *SandboxListRequest, opts ...grpc.CallOption) (*SandboxListResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SandboxListResponse)
@@ -101,6 +179,16 @@ func (c *sandboxClient) Deactive(ctx context.Context, in *SandboxDeactivateReque
 	return out, nil
 }
 
+func (c *sandboxClient) MemoryConsumption(ctx context.Context, in *SandboxMemoryConsumptionRequest, opts ...grpc.CallOption) (*SandboxMemoryConsumptionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxMemoryConsumptionResponse)
+	err := c.cc.Invoke(ctx, Sandbox_MemoryConsumption_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *sandboxClient) Snapshot(ctx context.Context, in *SandboxSnapshotRequest, opts ...grpc.CallOption) (*SandboxSnapshotResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SandboxSnapshotResponse)
@@ -111,6 +199,16 @@ func (c *sandboxClient) Snapshot(ctx context.Context, in *SandboxSnapshotRequest
 	return out, nil
 }
 
+func (c *sandboxClient) SnapshotAsTemplate(ctx context.Context, in *SandboxSnapshotAsTemplateRequest, opts ...grpc.CallOption) (*SandboxSnapshotAsTemplateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxSnapshotAsTemplateResponse)
+	err := c.cc.Invoke(ctx, Sandbox_SnapshotAsTemplate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *sandboxClient) Search(ctx context.Context, in *SandboxSearchRequest, opts ...grpc.CallOption) (*SandboxSearchResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SandboxSearchResponse)
@@ -131,6 +229,135 @@ func (c *sandboxClient) Purge(ctx context.Context, in *SandboxPurgeRequest, opts
 	return out, nil
 }
 
+func (c *sandboxClient) PurgeFailed(ctx context.Context, in *SandboxPurgeFailedRequest, opts ...grpc.CallOption) (*SandboxPurgeFailedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxPurgeFailedResponse)
+	err := c.cc.Invoke(ctx, Sandbox_PurgeFailed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) AdoptOrphans(ctx context.Context, in *SandboxAdoptOrphansRequest, opts ...grpc.CallOption) (*SandboxAdoptOrphansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxAdoptOrphansResponse)
+	err := c.cc.Invoke(ctx, Sandbox_AdoptOrphans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) PendingLogs(ctx context.Context, in *SandboxPendingLogsRequest, opts ...grpc.CallOption) (*SandboxPendingLogsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxPendingLogsResponse)
+	err := c.cc.Invoke(ctx, Sandbox_PendingLogs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) SetMetadata(ctx context.Context, in *SandboxSetMetadataRequest, opts ...grpc.CallOption) (*SandboxSetMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxSetMetadataResponse)
+	err := c.cc.Invoke(ctx, Sandbox_SetMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) SyncClock(ctx context.Context, in *SandboxSyncClockRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Sandbox_SyncClock_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) RefreshPrometheusTarget(ctx context.Context, in *SandboxRefreshPrometheusTargetRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Sandbox_RefreshPrometheusTarget_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) RecreateNetwork(ctx context.Context, in *SandboxRecreateNetworkRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Sandbox_RecreateNetwork_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) InflateBalloon(ctx context.Context, in *SandboxBalloonRequest, opts ...grpc.CallOption) (*SandboxBalloonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxBalloonResponse)
+	err := c.cc.Invoke(ctx, Sandbox_InflateBalloon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) DeflateBalloon(ctx context.Context, in *SandboxBalloonRequest, opts ...grpc.CallOption) (*SandboxBalloonResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxBalloonResponse)
+	err := c.cc.Invoke(ctx, Sandbox_DeflateBalloon_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) MigrateSend(ctx context.Context, in *SandboxMigrateSendRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Sandbox_MigrateSend_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) MigrateReceive(ctx context.Context, in *SandboxMigrateReceiveRequest, opts ...grpc.CallOption) (*SandboxCreateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SandboxCreateResponse)
+	err := c.cc.Invoke(ctx, Sandbox_MigrateReceive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sandboxClient) WatchEvents(ctx context.Context, in *SandboxWatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SandboxEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Sandbox_ServiceDesc.Streams[0], Sandbox_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SandboxWatchEventsRequest, SandboxEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sandbox_WatchEventsClient = grpc.ServerStreamingClient[SandboxEvent]
+
 // SandboxServer is the server API for Sandbox service.
 // All implementations must embed UnimplementedSandboxServer
 // for forward compatibility.
@@ -138,21 +365,74 @@ func (c *sandboxClient) Purge(ctx context.Context, in *SandboxPurgeRequest, opts
 // Interface exported by the server.
 type SandboxServer interface {
 	// Create is a gRPC service that creates a new sandbox.
+	// It returns the existing sandbox if the same id is running with the same
+	// template (e.g., retried by client), UNAVAILABLE if it is snapshotting or
+	// migrating, or ALREADY_EXISTS otherwise.
 	Create(context.Context, *SandboxCreateRequest) (*SandboxCreateResponse, error)
+	// Create multiple sandboxes from the same template concurrently.
+	CreateBatch(context.Context, *SandboxCreateBatchRequest) (*SandboxCreateBatchResponse, error)
 	// List is a gRPC service that returns a list of all the sandboxes.
 	List(context.Context, *SandboxListRequest) (*SandboxListResponse, error)
 	// Delete is a gRPC service that kills a sandbox.
 	Delete(context.Context, *SandboxDeleteRequest) (*emptypb.Empty, error)
 	// Invoke memory reclaim for a sandbox **on host**.
 	Deactive(context.Context, *SandboxDeactivateRequest) (*emptypb.Empty, error)
-	// Snapshot a sandbox with id
+	// Get the current memory consumption (on host) of sandboxes without
+	// deactivating them, which is read only.
+	MemoryConsumption(context.Context, *SandboxMemoryConsumptionRequest) (*SandboxMemoryConsumptionResponse, error)
+	// Snapshot a sandbox with id, the memfile is compressed in background
+	// when the template enables compress_memfile.
 	Snapshot(context.Context, *SandboxSnapshotRequest) (*SandboxSnapshotResponse, error)
+	// Snapshot a sandbox and register the snapshot (with the current rootfs)
+	// as a new template, so that later Create() with the new template id
+	// will start from the state of this sandbox. When the template enables
+	// compress_memfile, it returns once the vm is resumed (or deleted), and the
+	// template can be used after its memfile is compressed in background.
+	SnapshotAsTemplate(context.Context, *SandboxSnapshotAsTemplateRequest) (*SandboxSnapshotAsTemplateResponse, error)
 	// search a sandbox with id
 	Search(context.Context, *SandboxSearchRequest) (*SandboxSearchResponse, error)
 	// Purge will be invoked in rare case. typically when orchestrator crashes
 	// and forget to cleanup the sandbox. So the client can call this method
 	// to purge the orphan sandbox manually
 	Purge(context.Context, *SandboxPurgeRequest) (*emptypb.Empty, error)
+	// Remove the files and network kept for the sandboxes failed to create
+	// (see keep_files_on_error of orchestrator config).
+	PurgeFailed(context.Context, *SandboxPurgeFailedRequest) (*SandboxPurgeFailedResponse, error)
+	// Manage the orphan sandboxes (see List with orphan) as the ones created by
+	// this orchestrator (e.g., to Delete or Snapshot them) instead of purging.
+	// The metadata, extra disks and port forwards of orphans are not recovered.
+	AdoptOrphans(context.Context, *SandboxAdoptOrphansRequest) (*SandboxAdoptOrphansResponse, error)
+	// Query whether the guest has buffered logs not delivered to the log collector.
+	PendingLogs(context.Context, *SandboxPendingLogsRequest) (*SandboxPendingLogsResponse, error)
+	// Merge (or replace) the metadata of a running sandbox.
+	SetMetadata(context.Context, *SandboxSetMetadataRequest) (*SandboxSetMetadataResponse, error)
+	// Sync the guest clock with the host on demand (it is synced automatically
+	// after boot and after resume).
+	SyncClock(context.Context, *SandboxSyncClockRequest) (*emptypb.Empty, error)
+	// Rewrite the prometheus target file of a sandbox (e.g., when the write
+	// after create failed), so that it can be scraped again.
+	RefreshPrometheusTarget(context.Context, *SandboxRefreshPrometheusTargetRequest) (*emptypb.Empty, error)
+	// Rebuild the network of a running sandbox in place (e.g., after its netns
+	// or veth was deleted externally) without restarting the vm. The network
+	// index (i.e., the addresses) is not changed.
+	RecreateNetwork(context.Context, *SandboxRecreateNetworkRequest) (*emptypb.Empty, error)
+	// Inflate the balloon of a sandbox to the target size to reclaim its memory,
+	// the template should be built with balloon enabled.
+	InflateBalloon(context.Context, *SandboxBalloonRequest) (*SandboxBalloonResponse, error)
+	// Deflate the balloon of a sandbox to the target size to give back memory.
+	DeflateBalloon(context.Context, *SandboxBalloonRequest) (*SandboxBalloonResponse, error)
+	// Live migrate a running sandbox to the receiver (see MigrateReceive) on
+	// another host, the sandbox is deleted after migrated successfully. Only
+	// supported by cloud hypervisor. The caller is responsible for forwarding the
+	// unix socket between hosts, and for the network state (e.g., connections to
+	// the sandbox) and disk contents, which are not migrated.
+	MigrateSend(context.Context, *SandboxMigrateSendRequest) (*emptypb.Empty, error)
+	// Create a sandbox receiving the vm from MigrateSend, it returns once the
+	// migration completes (and the vm is running).
+	MigrateReceive(context.Context, *SandboxMigrateReceiveRequest) (*SandboxCreateResponse, error)
+	// Watch the lifecycle events of sandboxes (since the call), the events are
+	// dropped if the client does not receive them in time (see SandboxEvent.dropped).
+	WatchEvents(*SandboxWatchEventsRequest, grpc.ServerStreamingServer[SandboxEvent]) error
 	mustEmbedUnimplementedSandboxServer()
 }
 
@@ -166,6 +446,9 @@ type UnimplementedSandboxServer struct{}
 func (UnimplementedSandboxServer) Create(context.Context, *SandboxCreateRequest) (*SandboxCreateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Create not implemented")
 }
+func (UnimplementedSandboxServer) CreateBatch(context.Context, *SandboxCreateBatchRequest) (*SandboxCreateBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateBatch not implemented")
+}
 func (UnimplementedSandboxServer) List(context.Context, *SandboxListRequest) (*SandboxListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method List not implemented")
 }
@@ -175,15 +458,57 @@ func (UnimplementedSandboxServer) Delete(context.Context, *SandboxDeleteRequest)
 func (UnimplementedSandboxServer) Deactive(context.Context, *SandboxDeactivateRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Deactive not implemented")
 }
+func (UnimplementedSandboxServer) MemoryConsumption(context.Context, *SandboxMemoryConsumptionRequest) (*SandboxMemoryConsumptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MemoryConsumption not implemented")
+}
 func (UnimplementedSandboxServer) Snapshot(context.Context, *SandboxSnapshotRequest) (*SandboxSnapshotResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
 }
+func (UnimplementedSandboxServer) SnapshotAsTemplate(context.Context, *SandboxSnapshotAsTemplateRequest) (*SandboxSnapshotAsTemplateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SnapshotAsTemplate not implemented")
+}
 func (UnimplementedSandboxServer) Search(context.Context, *SandboxSearchRequest) (*SandboxSearchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
 }
 func (UnimplementedSandboxServer) Purge(context.Context, *SandboxPurgeRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
 }
+func (UnimplementedSandboxServer) PurgeFailed(context.Context, *SandboxPurgeFailedRequest) (*SandboxPurgeFailedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeFailed not implemented")
+}
+func (UnimplementedSandboxServer) AdoptOrphans(context.Context, *SandboxAdoptOrphansRequest) (*SandboxAdoptOrphansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AdoptOrphans not implemented")
+}
+func (UnimplementedSandboxServer) PendingLogs(context.Context, *SandboxPendingLogsRequest) (*SandboxPendingLogsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PendingLogs not implemented")
+}
+func (UnimplementedSandboxServer) SetMetadata(context.Context, *SandboxSetMetadataRequest) (*SandboxSetMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMetadata not implemented")
+}
+func (UnimplementedSandboxServer) SyncClock(context.Context, *SandboxSyncClockRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncClock not implemented")
+}
+func (UnimplementedSandboxServer) RefreshPrometheusTarget(context.Context, *SandboxRefreshPrometheusTargetRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshPrometheusTarget not implemented")
+}
+func (UnimplementedSandboxServer) RecreateNetwork(context.Context, *SandboxRecreateNetworkRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecreateNetwork not implemented")
+}
+func (UnimplementedSandboxServer) InflateBalloon(context.Context, *SandboxBalloonRequest) (*SandboxBalloonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InflateBalloon not implemented")
+}
+func (UnimplementedSandboxServer) DeflateBalloon(context.Context, *SandboxBalloonRequest) (*SandboxBalloonResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeflateBalloon not implemented")
+}
+func (UnimplementedSandboxServer) MigrateSend(context.Context, *SandboxMigrateSendRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateSend not implemented")
+}
+func (UnimplementedSandboxServer) MigrateReceive(context.Context, *SandboxMigrateReceiveRequest) (*SandboxCreateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MigrateReceive not implemented")
+}
+func (UnimplementedSandboxServer) WatchEvents(*SandboxWatchEventsRequest, grpc.ServerStreamingServer[SandboxEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
 func (UnimplementedSandboxServer) mustEmbedUnimplementedSandboxServer() {}
 func (UnimplementedSandboxServer) testEmbeddedByValue()                 {}
 
@@ -223,6 +548,24 @@ func _Sandbox_Create_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sandbox_CreateBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxCreateBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).CreateBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_CreateBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).CreateBatch(ctx, req.(*SandboxCreateBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Sandbox_List_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SandboxListRequest)
 	if err := dec(in); err != nil {
@@ -277,6 +620,24 @@ func _Sandbox_Deactive_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sandbox_MemoryConsumption_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxMemoryConsumptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).MemoryConsumption(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_MemoryConsumption_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).MemoryConsumption(ctx, req.(*SandboxMemoryConsumptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Sandbox_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SandboxSnapshotRequest)
 	if err := dec(in); err != nil {
@@ -295,6 +656,24 @@ func _Sandbox_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Sandbox_SnapshotAsTemplate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxSnapshotAsTemplateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).SnapshotAsTemplate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_SnapshotAsTemplate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).SnapshotAsTemplate(ctx, req.(*SandboxSnapshotAsTemplateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Sandbox_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SandboxSearchRequest)
 	if err := dec(in); err != nil {
@@ -331,93 +710,553 @@ func _Sandbox_Purge_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
-// Sandbox_ServiceDesc is the grpc.ServiceDesc for Sandbox service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var Sandbox_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "Sandbox",
-	HandlerType: (*SandboxServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Create",
-			Handler:    _Sandbox_Create_Handler,
-		},
-		{
-			MethodName: "List",
-			Handler:    _Sandbox_List_Handler,
-		},
-		{
-			MethodName: "Delete",
-			Handler:    _Sandbox_Delete_Handler,
-		},
-		{
-			MethodName: "Deactive",
-			Handler:    _Sandbox_Deactive_Handler,
-		},
-		{
-			MethodName: "Snapshot",
-			Handler:    _Sandbox_Snapshot_Handler,
-		},
-		{
-			MethodName: "Search",
-			Handler:    _Sandbox_Search_Handler,
-		},
-		{
-			MethodName: "Purge",
-			Handler:    _Sandbox_Purge_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "orchestrator.proto",
-}
-
-const (
-	HostManage_RecreateCgroup_FullMethodName  = "/HostManage/RecreateCgroup"
-	HostManage_CleanNetworkEnv_FullMethodName = "/HostManage/CleanNetworkEnv"
-)
-
-// HostManageClient is the client API for HostManage service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
-type HostManageClient interface {
-	RecreateCgroup(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
-	CleanNetworkEnv(ctx context.Context, in *HostManageCleanNetworkEnvRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
-}
-
-type hostManageClient struct {
-	cc grpc.ClientConnInterface
-}
-
-func NewHostManageClient(cc grpc.ClientConnInterface) HostManageClient {
-	return &hostManageClient{cc}
-}
-
-func (c *hostManageClient) RecreateCgroup(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
-	err := c.cc.Invoke(ctx, HostManage_RecreateCgroup_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _Sandbox_PurgeFailed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxPurgeFailedRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
+	if interceptor == nil {
+		return srv.(SandboxServer).PurgeFailed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_PurgeFailed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).PurgeFailed(ctx, req.(*SandboxPurgeFailedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
 }
 
-func (c *hostManageClient) CleanNetworkEnv(ctx context.Context, in *HostManageCleanNetworkEnvRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
-	err := c.cc.Invoke(ctx, HostManage_CleanNetworkEnv_FullMethodName, in, out, cOpts...)
-	if err != nil {
+func _Sandbox_AdoptOrphans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxAdoptOrphansRequest)
+	if err := dec(in); err != nil {
 		return nil, err
 	}
-	return out, nil
-}
-
-// HostManageServer is the server API for HostManage service.
-// All implementations must embed UnimplementedHostManageServer
-// for forward compatibility.
-type HostManageServer interface {
-	RecreateCgroup(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	if interceptor == nil {
+		return srv.(SandboxServer).AdoptOrphans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_AdoptOrphans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).AdoptOrphans(ctx, req.(*SandboxAdoptOrphansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_PendingLogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxPendingLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).PendingLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_PendingLogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).PendingLogs(ctx, req.(*SandboxPendingLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_SetMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxSetMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).SetMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_SetMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).SetMetadata(ctx, req.(*SandboxSetMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_SyncClock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxSyncClockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).SyncClock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_SyncClock_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).SyncClock(ctx, req.(*SandboxSyncClockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_RefreshPrometheusTarget_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxRefreshPrometheusTargetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).RefreshPrometheusTarget(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_RefreshPrometheusTarget_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).RefreshPrometheusTarget(ctx, req.(*SandboxRefreshPrometheusTargetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_RecreateNetwork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxRecreateNetworkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).RecreateNetwork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_RecreateNetwork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).RecreateNetwork(ctx, req.(*SandboxRecreateNetworkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_InflateBalloon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxBalloonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).InflateBalloon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_InflateBalloon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).InflateBalloon(ctx, req.(*SandboxBalloonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_DeflateBalloon_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxBalloonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).DeflateBalloon(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_DeflateBalloon_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).DeflateBalloon(ctx, req.(*SandboxBalloonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_MigrateSend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxMigrateSendRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).MigrateSend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_MigrateSend_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).MigrateSend(ctx, req.(*SandboxMigrateSendRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_MigrateReceive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SandboxMigrateReceiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SandboxServer).MigrateReceive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Sandbox_MigrateReceive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SandboxServer).MigrateReceive(ctx, req.(*SandboxMigrateReceiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Sandbox_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SandboxWatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SandboxServer).WatchEvents(m, &grpc.GenericServerStream[SandboxWatchEventsRequest, SandboxEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Sandbox_WatchEventsServer = grpc.ServerStreamingServer[SandboxEvent]
+
+// Sandbox_ServiceDesc is the grpc.ServiceDesc for Sandbox service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Sandbox_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "Sandbox",
+	HandlerType: (*SandboxServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Create",
+			Handler:    _Sandbox_Create_Handler,
+		},
+		{
+			MethodName: "CreateBatch",
+			Handler:    _Sandbox_CreateBatch_Handler,
+		},
+		{
+			MethodName: "List",
+			Handler:    _Sandbox_List_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _Sandbox_Delete_Handler,
+		},
+		{
+			MethodName: "Deactive",
+			Handler:    _Sandbox_Deactive_Handler,
+		},
+		{
+			MethodName: "MemoryConsumption",
+			Handler:    _Sandbox_MemoryConsumption_Handler,
+		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _Sandbox_Snapshot_Handler,
+		},
+		{
+			MethodName: "SnapshotAsTemplate",
+			Handler:    _Sandbox_SnapshotAsTemplate_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _Sandbox_Search_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _Sandbox_Purge_Handler,
+		},
+		{
+			MethodName: "PurgeFailed",
+			Handler:    _Sandbox_PurgeFailed_Handler,
+		},
+		{
+			MethodName: "AdoptOrphans",
+			Handler:    _Sandbox_AdoptOrphans_Handler,
+		},
+		{
+			MethodName: "PendingLogs",
+			Handler:    _Sandbox_PendingLogs_Handler,
+		},
+		{
+			MethodName: "SetMetadata",
+			Handler:    _Sandbox_SetMetadata_Handler,
+		},
+		{
+			MethodName: "SyncClock",
+			Handler:    _Sandbox_SyncClock_Handler,
+		},
+		{
+			MethodName: "RefreshPrometheusTarget",
+			Handler:    _Sandbox_RefreshPrometheusTarget_Handler,
+		},
+		{
+			MethodName: "RecreateNetwork",
+			Handler:    _Sandbox_RecreateNetwork_Handler,
+		},
+		{
+			MethodName: "InflateBalloon",
+			Handler:    _Sandbox_InflateBalloon_Handler,
+		},
+		{
+			MethodName: "DeflateBalloon",
+			Handler:    _Sandbox_DeflateBalloon_Handler,
+		},
+		{
+			MethodName: "MigrateSend",
+			Handler:    _Sandbox_MigrateSend_Handler,
+		},
+		{
+			MethodName: "MigrateReceive",
+			Handler:    _Sandbox_MigrateReceive_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _Sandbox_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "orchestrator.proto",
+}
+
+const (
+	HostManage_RecreateCgroup_FullMethodName             = "/HostManage/RecreateCgroup"
+	HostManage_CleanNetworkEnv_FullMethodName            = "/HostManage/CleanNetworkEnv"
+	HostManage_ListNetworks_FullMethodName               = "/HostManage/ListNetworks"
+	HostManage_Health_FullMethodName                     = "/HostManage/Health"
+	HostManage_ListStaleCgroups_FullMethodName           = "/HostManage/ListStaleCgroups"
+	HostManage_ReapCgroups_FullMethodName                = "/HostManage/ReapCgroups"
+	HostManage_ListTemplates_FullMethodName              = "/HostManage/ListTemplates"
+	HostManage_CompactSnapshot_FullMethodName            = "/HostManage/CompactSnapshot"
+	HostManage_WarmNetworks_FullMethodName               = "/HostManage/WarmNetworks"
+	HostManage_Drain_FullMethodName                      = "/HostManage/Drain"
+	HostManage_Undrain_FullMethodName                    = "/HostManage/Undrain"
+	HostManage_SelfTest_FullMethodName                   = "/HostManage/SelfTest"
+	HostManage_CheckSnapshotCompatibility_FullMethodName = "/HostManage/CheckSnapshotCompatibility"
+)
+
+// HostManageClient is the client API for HostManage service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HostManageClient interface {
+	RecreateCgroup(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	CleanNetworkEnv(ctx context.Context, in *HostManageCleanNetworkEnvRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Dump the allocation table of network envs maintained by orchestrator.
+	ListNetworks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageListNetworksResponse, error)
+	// Report whether the orchestrator is able to serve sandboxes, the same status
+	// is also served by the standard grpc health checking service.
+	Health(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageHealthResponse, error)
+	// List the cgroups of sandboxes which have no live process and are not
+	// maintained by orchestrator. It complements Purge, which focuses on the
+	// orphan vm processes.
+	ListStaleCgroups(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageListStaleCgroupsResponse, error)
+	// Remove the stale cgroups (see ListStaleCgroups).
+	ReapCgroups(ctx context.Context, in *HostManageReapCgroupsRequest, opts ...grpc.CallOption) (*HostManageReapCgroupsResponse, error)
+	// List the templates on the disk of host (i.e., not including those can be
+	// fetched from the template_source), the result is cached for a few seconds.
+	ListTemplates(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageListTemplatesResponse, error)
+	// Merge a chain of firecracker diff snapshots (i.e., a full snapshot and the
+	// diff snapshots on top of it) into a full snapshot.
+	CompactSnapshot(ctx context.Context, in *HostManageCompactSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Create the network envs (until there are count warm ones) so that the
+	// sandboxes created later do not need to set up their networks, e.g., to
+	// prepare the host before a burst. It stops early when canceled.
+	WarmNetworks(ctx context.Context, in *HostManageWarmNetworksRequest, opts ...grpc.CallOption) (*HostManageWarmNetworksResponse, error)
+	// Reject the new sandboxes (i.e., Create, CreateBatch and MigrateReceive fail
+	// with UNAVAILABLE) to take the host out of rotation, while the existing ones
+	// keep working. Return the sandboxes left on the host.
+	Drain(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageDrainResponse, error)
+	// Accept the new sandboxes again (see Drain).
+	Undrain(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Verify the host can run sandboxes end-to-end, i.e., create a probe sandbox,
+	// sync its clock (by envd), optionally run a command, then delete it.
+	// Return the duration of each phase, the failure of phase is not an error of rpc.
+	SelfTest(ctx context.Context, in *HostManageSelfTestRequest, opts ...grpc.CallOption) (*HostManageSelfTestResponse, error)
+	// Report whether the snapshots of templates can be restored on this host,
+	// i.e., the cpu of host where the snapshot is taken is compatible with this one.
+	CheckSnapshotCompatibility(ctx context.Context, in *HostManageCheckSnapshotCompatibilityRequest, opts ...grpc.CallOption) (*HostManageCheckSnapshotCompatibilityResponse, error)
+}
+
+type hostManageClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHostManageClient(cc grpc.ClientConnInterface) HostManageClient {
+	return &hostManageClient{cc}
+}
+
+func (c *hostManageClient) RecreateCgroup(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, HostManage_RecreateCgroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) CleanNetworkEnv(ctx context.Context, in *HostManageCleanNetworkEnvRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, HostManage_CleanNetworkEnv_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) ListNetworks(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageListNetworksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageListNetworksResponse)
+	err := c.cc.Invoke(ctx, HostManage_ListNetworks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) Health(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageHealthResponse)
+	err := c.cc.Invoke(ctx, HostManage_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) ListStaleCgroups(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageListStaleCgroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageListStaleCgroupsResponse)
+	err := c.cc.Invoke(ctx, HostManage_ListStaleCgroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) ReapCgroups(ctx context.Context, in *HostManageReapCgroupsRequest, opts ...grpc.CallOption) (*HostManageReapCgroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageReapCgroupsResponse)
+	err := c.cc.Invoke(ctx, HostManage_ReapCgroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) ListTemplates(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageListTemplatesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageListTemplatesResponse)
+	err := c.cc.Invoke(ctx, HostManage_ListTemplates_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) CompactSnapshot(ctx context.Context, in *HostManageCompactSnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, HostManage_CompactSnapshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) WarmNetworks(ctx context.Context, in *HostManageWarmNetworksRequest, opts ...grpc.CallOption) (*HostManageWarmNetworksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageWarmNetworksResponse)
+	err := c.cc.Invoke(ctx, HostManage_WarmNetworks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) Drain(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*HostManageDrainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageDrainResponse)
+	err := c.cc.Invoke(ctx, HostManage_Drain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) Undrain(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, HostManage_Undrain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) SelfTest(ctx context.Context, in *HostManageSelfTestRequest, opts ...grpc.CallOption) (*HostManageSelfTestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageSelfTestResponse)
+	err := c.cc.Invoke(ctx, HostManage_SelfTest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostManageClient) CheckSnapshotCompatibility(ctx context.Context, in *HostManageCheckSnapshotCompatibilityRequest, opts ...grpc.CallOption) (*HostManageCheckSnapshotCompatibilityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HostManageCheckSnapshotCompatibilityResponse)
+	err := c.cc.Invoke(ctx, HostManage_CheckSnapshotCompatibility_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HostManageServer is the server API for HostManage service.
+// All implementations must embed UnimplementedHostManageServer
+// for forward compatibility.
+type HostManageServer interface {
+	RecreateCgroup(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
 	CleanNetworkEnv(context.Context, *HostManageCleanNetworkEnvRequest) (*emptypb.Empty, error)
+	// Dump the allocation table of network envs maintained by orchestrator.
+	ListNetworks(context.Context, *emptypb.Empty) (*HostManageListNetworksResponse, error)
+	// Report whether the orchestrator is able to serve sandboxes, the same status
+	// is also served by the standard grpc health checking service.
+	Health(context.Context, *emptypb.Empty) (*HostManageHealthResponse, error)
+	// List the cgroups of sandboxes which have no live process and are not
+	// maintained by orchestrator. It complements Purge, which focuses on the
+	// orphan vm processes.
+	ListStaleCgroups(context.Context, *emptypb.Empty) (*HostManageListStaleCgroupsResponse, error)
+	// Remove the stale cgroups (see ListStaleCgroups).
+	ReapCgroups(context.Context, *HostManageReapCgroupsRequest) (*HostManageReapCgroupsResponse, error)
+	// List the templates on the disk of host (i.e., not including those can be
+	// fetched from the template_source), the result is cached for a few seconds.
+	ListTemplates(context.Context, *emptypb.Empty) (*HostManageListTemplatesResponse, error)
+	// Merge a chain of firecracker diff snapshots (i.e., a full snapshot and the
+	// diff snapshots on top of it) into a full snapshot.
+	CompactSnapshot(context.Context, *HostManageCompactSnapshotRequest) (*emptypb.Empty, error)
+	// Create the network envs (until there are count warm ones) so that the
+	// sandboxes created later do not need to set up their networks, e.g., to
+	// prepare the host before a burst. It stops early when canceled.
+	WarmNetworks(context.Context, *HostManageWarmNetworksRequest) (*HostManageWarmNetworksResponse, error)
+	// Reject the new sandboxes (i.e., Create, CreateBatch and MigrateReceive fail
+	// with UNAVAILABLE) to take the host out of rotation, while the existing ones
+	// keep working. Return the sandboxes left on the host.
+	Drain(context.Context, *emptypb.Empty) (*HostManageDrainResponse, error)
+	// Accept the new sandboxes again (see Drain).
+	Undrain(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	// Verify the host can run sandboxes end-to-end, i.e., create a probe sandbox,
+	// sync its clock (by envd), optionally run a command, then delete it.
+	// Return the duration of each phase, the failure of phase is not an error of rpc.
+	SelfTest(context.Context, *HostManageSelfTestRequest) (*HostManageSelfTestResponse, error)
+	// Report whether the snapshots of templates can be restored on this host,
+	// i.e., the cpu of host where the snapshot is taken is compatible with this one.
+	CheckSnapshotCompatibility(context.Context, *HostManageCheckSnapshotCompatibilityRequest) (*HostManageCheckSnapshotCompatibilityResponse, error)
 	mustEmbedUnimplementedHostManageServer()
 }
 
@@ -434,6 +1273,39 @@ func (UnimplementedHostManageServer) RecreateCgroup(context.Context, *emptypb.Em
 func (UnimplementedHostManageServer) CleanNetworkEnv(context.Context, *HostManageCleanNetworkEnvRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CleanNetworkEnv not implemented")
 }
+func (UnimplementedHostManageServer) ListNetworks(context.Context, *emptypb.Empty) (*HostManageListNetworksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNetworks not implemented")
+}
+func (UnimplementedHostManageServer) Health(context.Context, *emptypb.Empty) (*HostManageHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedHostManageServer) ListStaleCgroups(context.Context, *emptypb.Empty) (*HostManageListStaleCgroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListStaleCgroups not implemented")
+}
+func (UnimplementedHostManageServer) ReapCgroups(context.Context, *HostManageReapCgroupsRequest) (*HostManageReapCgroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReapCgroups not implemented")
+}
+func (UnimplementedHostManageServer) ListTemplates(context.Context, *emptypb.Empty) (*HostManageListTemplatesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTemplates not implemented")
+}
+func (UnimplementedHostManageServer) CompactSnapshot(context.Context, *HostManageCompactSnapshotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompactSnapshot not implemented")
+}
+func (UnimplementedHostManageServer) WarmNetworks(context.Context, *HostManageWarmNetworksRequest) (*HostManageWarmNetworksResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WarmNetworks not implemented")
+}
+func (UnimplementedHostManageServer) Drain(context.Context, *emptypb.Empty) (*HostManageDrainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Drain not implemented")
+}
+func (UnimplementedHostManageServer) Undrain(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Undrain not implemented")
+}
+func (UnimplementedHostManageServer) SelfTest(context.Context, *HostManageSelfTestRequest) (*HostManageSelfTestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SelfTest not implemented")
+}
+func (UnimplementedHostManageServer) CheckSnapshotCompatibility(context.Context, *HostManageCheckSnapshotCompatibilityRequest) (*HostManageCheckSnapshotCompatibilityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckSnapshotCompatibility not implemented")
+}
 func (UnimplementedHostManageServer) mustEmbedUnimplementedHostManageServer() {}
 func (UnimplementedHostManageServer) testEmbeddedByValue()                    {}
 
@@ -491,6 +1363,204 @@ func _HostManage_CleanNetworkEnv_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HostManage_ListNetworks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).ListNetworks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_ListNetworks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).ListNetworks(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).Health(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_ListStaleCgroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).ListStaleCgroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_ListStaleCgroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).ListStaleCgroups(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_ReapCgroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HostManageReapCgroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).ReapCgroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_ReapCgroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).ReapCgroups(ctx, req.(*HostManageReapCgroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_ListTemplates_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).ListTemplates(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_ListTemplates_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).ListTemplates(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_CompactSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HostManageCompactSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).CompactSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_CompactSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).CompactSnapshot(ctx, req.(*HostManageCompactSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_WarmNetworks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HostManageWarmNetworksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).WarmNetworks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_WarmNetworks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).WarmNetworks(ctx, req.(*HostManageWarmNetworksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_Drain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).Drain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_Drain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).Drain(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_Undrain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).Undrain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_Undrain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).Undrain(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_SelfTest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HostManageSelfTestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).SelfTest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_SelfTest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).SelfTest(ctx, req.(*HostManageSelfTestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostManage_CheckSnapshotCompatibility_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HostManageCheckSnapshotCompatibilityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostManageServer).CheckSnapshotCompatibility(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostManage_CheckSnapshotCompatibility_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostManageServer).CheckSnapshotCompatibility(ctx, req.(*HostManageCheckSnapshotCompatibilityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // HostManage_ServiceDesc is the grpc.ServiceDesc for HostManage service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -506,6 +1576,50 @@ var HostManage_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CleanNetworkEnv",
 			Handler:    _HostManage_CleanNetworkEnv_Handler,
 		},
+		{
+			MethodName: "ListNetworks",
+			Handler:    _HostManage_ListNetworks_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _HostManage_Health_Handler,
+		},
+		{
+			MethodName: "ListStaleCgroups",
+			Handler:    _HostManage_ListStaleCgroups_Handler,
+		},
+		{
+			MethodName: "ReapCgroups",
+			Handler:    _HostManage_ReapCgroups_Handler,
+		},
+		{
+			MethodName: "ListTemplates",
+			Handler:    _HostManage_ListTemplates_Handler,
+		},
+		{
+			MethodName: "CompactSnapshot",
+			Handler:    _HostManage_CompactSnapshot_Handler,
+		},
+		{
+			MethodName: "WarmNetworks",
+			Handler:    _HostManage_WarmNetworks_Handler,
+		},
+		{
+			MethodName: "Drain",
+			Handler:    _HostManage_Drain_Handler,
+		},
+		{
+			MethodName: "Undrain",
+			Handler:    _HostManage_Undrain_Handler,
+		},
+		{
+			MethodName: "SelfTest",
+			Handler:    _HostManage_SelfTest_Handler,
+		},
+		{
+			MethodName: "CheckSnapshotCompatibility",
+			Handler:    _HostManage_CheckSnapshotCompatibility_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "orchestrator.proto",
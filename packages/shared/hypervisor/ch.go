@@ -3,6 +3,7 @@ package hypervisor
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/ch"
@@ -12,20 +13,38 @@ import (
 )
 
 var (
-	_ Hypervisor = (*CloudHypervisor)(nil)
+	_ Hypervisor    = (*CloudHypervisor)(nil)
+	_ Migrator      = (*CloudHypervisor)(nil)
+	_ MemoryResizer = (*CloudHypervisor)(nil)
 )
 
 type ChConfig struct {
-	VcpuCount          int64
-	MemoryMB           int64
-	KernelImagePath    string
-	KernelBootCmd      string
-	EnableOverlayFS    bool
+	VcpuCount int64
+	MemoryMB  int64
+	// the memory can be hotplugged (by virtio-mem) after boot, 0 means disabled
+	MemoryHotplugMB int64
+	KernelImagePath string
+	KernelBootCmd   string
+	EnableOverlayFS bool
+	// attach the rootfs read-only without the writable one (see immutable_rootfs of template)
+	ReadOnlyRootfs     bool
 	RootfsPath         string
 	WritableRootfsPath string
 	TapDevName         string
 	GuestNetMacAddr    string
-	EnableHugepage     bool
+	// size of huge page in bytes, 0 means do not use huge page
+	HugePageSize int64
+	// empty means do not attach config drive
+	ConfigDrivePath string
+	// attach a balloon device (initially deflated) for reclaiming memory
+	EnableBalloon bool
+	// populate the guest memory before resuming from snapshot
+	PrefaultMemory bool
+	Restore        RestoreOptions
+	// limit the io of each disk (the rootfs is attached as pmem, which is not
+	// limited). The disks restored from snapshot keep the limits in it, so
+	// it only applies to the disks added after restoring.
+	DiskRateLimit DiskRateLimit
 }
 
 func init() {
@@ -40,8 +59,8 @@ type CloudHypervisor struct {
 	client *ch.ClientWithResponses
 }
 
-func CloudHypervisorCmd(binaryPath, socketPath string) string {
-	return binaryPath + " --api-socket " + socketPath + " -v"
+func CloudHypervisorCmd(binaryPath, socketPath string, extraArgs []string) (string, error) {
+	return appendExtraArgs(binaryPath+" --api-socket "+socketPath+" -v", "--api-socket", extraArgs)
 }
 
 func NewCloudHypervisor(config *ChConfig, client *ch.ClientWithResponses) *CloudHypervisor {
@@ -57,9 +76,9 @@ func (vmm *CloudHypervisor) Configure(ctx context.Context) error {
 	var pmemConfigs []ch.PmemConfig
 	{
 		id := "rootfs"
-		// when enable overlayfs, we discard writes, as rootfs must be read-only
+		// when enable overlayfs (or read-only rootfs), we discard writes, as rootfs must be read-only
 		// when disable overlayfs, we keep writes, as rootfs is writable
-		discardWrites := vmm.config.EnableOverlayFS
+		discardWrites := vmm.config.EnableOverlayFS || vmm.config.ReadOnlyRootfs
 		pmemConfigs = append(pmemConfigs, ch.PmemConfig{
 			DiscardWrites: &discardWrites,
 			File:          vmm.config.RootfsPath,
@@ -72,9 +91,10 @@ func (vmm *CloudHypervisor) Configure(ctx context.Context) error {
 		// discardWrites := false
 		readonly := false
 		diskConfigs = append(diskConfigs, ch.DiskConfig{
-			Id:       &id,
-			Path:     vmm.config.WritableRootfsPath,
-			Readonly: &readonly,
+			Id:                &id,
+			Path:              vmm.config.WritableRootfsPath,
+			Readonly:          &readonly,
+			RateLimiterConfig: vmm.config.DiskRateLimit.chRateLimiter(),
 		})
 		// pmemConfigs = append(pmemConfigs, ch.PmemConfig{
 		// 	DiscardWrites: &discardWrites,
@@ -83,6 +103,17 @@ func (vmm *CloudHypervisor) Configure(ctx context.Context) error {
 		// })
 	}
 
+	if vmm.config.ConfigDrivePath != "" {
+		id := "config"
+		readonly := true
+		diskConfigs = append(diskConfigs, ch.DiskConfig{
+			Id:                &id,
+			Path:              vmm.config.ConfigDrivePath,
+			Readonly:          &readonly,
+			RateLimiterConfig: vmm.config.DiskRateLimit.chRateLimiter(),
+		})
+	}
+
 	netConfigs := []ch.NetConfig{
 		{
 			Mac: &vmm.config.GuestNetMacAddr,
@@ -90,17 +121,29 @@ func (vmm *CloudHypervisor) Configure(ctx context.Context) error {
 		},
 	}
 
+	hugepages := vmm.config.HugePageSize != 0
+	memoryConfig := &ch.MemoryConfig{
+		Size:      vmm.config.MemoryMB * 1024 * 1024,
+		Hugepages: &hugepages,
+	}
+	if hugepages {
+		memoryConfig.HugepageSize = &vmm.config.HugePageSize
+	}
+	if vmm.config.MemoryHotplugMB > 0 {
+		hotplugMethod := "VirtioMem"
+		hotplugSize := vmm.config.MemoryHotplugMB * 1024 * 1024
+		memoryConfig.HotplugMethod = &hotplugMethod
+		memoryConfig.HotplugSize = &hotplugSize
+	}
+
 	vmConfig := ch.VmConfig{
 		Cpus: &ch.CpusConfig{
 			BootVcpus: int(vmm.config.VcpuCount),
 			MaxVcpus:  int(vmm.config.VcpuCount),
 		},
-		Memory: &ch.MemoryConfig{
-			Size:      vmm.config.MemoryMB * 1024 * 1024,
-			Hugepages: &vmm.config.EnableHugepage,
-		},
-		Disks: &diskConfigs,
-		Net:   &netConfigs,
+		Memory: memoryConfig,
+		Disks:  &diskConfigs,
+		Net:    &netConfigs,
 		Payload: ch.PayloadConfig{
 			Cmdline: &vmm.config.KernelBootCmd,
 			Kernel:  &vmm.config.KernelImagePath,
@@ -114,6 +157,14 @@ func (vmm *CloudHypervisor) Configure(ctx context.Context) error {
 		},
 	}
 
+	if vmm.config.EnableBalloon {
+		deflateOnOom := true
+		vmConfig.Balloon = &ch.BalloonConfig{
+			DeflateOnOom: &deflateOnOom,
+			Size:         0,
+		}
+	}
+
 	telemetry.ReportEvent(ctx, "configure ch boot source", attribute.String("boot_cmd", vmm.config.KernelBootCmd))
 	resp, err := vmm.client.CreateVMWithResponse(ctx, vmConfig)
 	if err != nil {
@@ -187,6 +238,84 @@ func (vmm *CloudHypervisor) Resume(ctx context.Context) error {
 	return nil
 }
 
+func (vmm *CloudHypervisor) SetBalloon(ctx context.Context, amountMiB int64) error {
+	desiredBalloon := amountMiB * 1024 * 1024
+	resp, err := vmm.client.PutVmResizeWithResponse(ctx, ch.VmResize{
+		DesiredBalloon: &desiredBalloon,
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("error set cloud hypervisor balloon: %w", err)
+		telemetry.ReportCriticalError(ctx, errMsg)
+
+		return errMsg
+	}
+	if !isRequestSucceed(resp.StatusCode()) {
+		errMsg := fmt.Errorf("error set cloud hypervisor balloon: %s %s", resp.Status(), string(resp.Body))
+		telemetry.ReportCriticalError(ctx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "set ch balloon", attribute.Int64("balloon.amount_mib", amountMiB))
+	return nil
+}
+
+// The hotplugged memory is onlined by the guest kernel (with virtio-mem
+// driver), it is recorded in the snapshot created later.
+func (vmm *CloudHypervisor) ResizeMemory(ctx context.Context, memoryMB int64) error {
+	desiredRam := memoryMB * 1024 * 1024
+	resp, err := vmm.client.PutVmResizeWithResponse(ctx, ch.VmResize{
+		DesiredRam: &desiredRam,
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("error resize cloud hypervisor memory: %w", err)
+		telemetry.ReportCriticalError(ctx, errMsg)
+
+		return errMsg
+	}
+	if !isRequestSucceed(resp.StatusCode()) {
+		errMsg := fmt.Errorf("error resize cloud hypervisor memory: %s %s", resp.Status(), string(resp.Body))
+		telemetry.ReportCriticalError(ctx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "resized ch memory", attribute.Int64("memory_mb", memoryMB))
+	return nil
+}
+
+func (vmm *CloudHypervisor) Balloon(ctx context.Context) (int64, error) {
+	resp, err := vmm.client.GetVmInfoWithResponse(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error get cloud hypervisor vm info: %w", err)
+	}
+	if !isRequestSucceed(resp.StatusCode()) || resp.JSON200 == nil {
+		return 0, fmt.Errorf("error get cloud hypervisor vm info: %s %s", resp.Status(), string(resp.Body))
+	}
+	if resp.JSON200.Config.Balloon == nil {
+		return 0, fmt.Errorf("cloud hypervisor balloon is not configured")
+	}
+	return resp.JSON200.Config.Balloon.Size / 1024 / 1024, nil
+}
+
+func (vmm *CloudHypervisor) AttachDisk(ctx context.Context, id, path string, readOnly bool) error {
+	resp, err := vmm.client.PutVmAddDiskWithResponse(ctx, ch.DiskConfig{
+		Id:                &id,
+		Path:              path,
+		Readonly:          &readOnly,
+		RateLimiterConfig: vmm.config.DiskRateLimit.chRateLimiter(),
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("error add cloud hypervisor disk %s: %w", id, err)
+		telemetry.ReportCriticalError(ctx, errMsg)
+
+		return errMsg
+	}
+	if !isRequestSucceed(resp.StatusCode()) {
+		errMsg := fmt.Errorf("error add cloud hypervisor disk %s: %s %s", id, resp.Status(), string(resp.Body))
+		telemetry.ReportCriticalError(ctx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "added ch disk", attribute.String("disk.id", id))
+	return nil
+}
+
 func (vmm *CloudHypervisor) Snapshot(ctx context.Context, dir string) error {
 	dest := "file://" + dir
 	req := ch.VmSnapshotConfig{
@@ -211,18 +340,68 @@ func (vmm *CloudHypervisor) Snapshot(ctx context.Context, dir string) error {
 func (vmm *CloudHypervisor) Restore(ctx context.Context, dir string) error {
 	req := ch.RestoreConfig{
 		SourceUrl: "file://" + dir,
+		Prefault:  &vmm.config.PrefaultMemory,
 	}
-	resp, err := vmm.client.PutVmRestoreWithResponse(ctx, req)
+	restoreCtx, cancel := vmm.config.Restore.withTimeout(ctx)
+	defer cancel()
+	retryTimes, err := utils.RetryHttpRequest(restoreCtx, func() error {
+		resp, err := vmm.client.PutVmRestoreWithResponse(restoreCtx, req)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode() >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %s %s", utils.ErrTransientResponse, resp.Status(), string(resp.Body))
+		}
+		if !isRequestSucceed(resp.StatusCode()) {
+			return fmt.Errorf("%s %s", resp.Status(), string(resp.Body))
+		}
+		return nil
+	}, vmm.config.Restore.retries())
 	if err != nil {
 		errMsg := fmt.Errorf("error restore cloud hypervisor vm: %w", err)
+		telemetry.ReportCriticalError(ctx, errMsg, attribute.Int("retry_times", retryTimes))
+
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "ch snapshot restored", attribute.Int("retry_times", retryTimes))
+	return nil
+}
+
+func (vmm *CloudHypervisor) SendMigration(ctx context.Context, destinationURL string) error {
+	resp, err := vmm.client.PutVmSendMigrationWithResponse(ctx, ch.SendMigrationData{
+		DestinationUrl: destinationURL,
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("error send cloud hypervisor migration: %w", err)
 		telemetry.ReportCriticalError(ctx, errMsg)
 
 		return errMsg
 	}
 	if !isRequestSucceed(resp.StatusCode()) {
-		errMsg := fmt.Errorf("error snapshot cloud hypervisor vm: %s %s", resp.Status(), string(resp.Body))
+		errMsg := fmt.Errorf("error send cloud hypervisor migration: %s %s", resp.Status(), string(resp.Body))
+		telemetry.ReportCriticalError(ctx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "sent ch migration", attribute.String("migration.destination_url", destinationURL))
+	return nil
+}
+
+// The vm is resumed by cloud hypervisor once the migration completes.
+func (vmm *CloudHypervisor) ReceiveMigration(ctx context.Context, receiverURL string) error {
+	resp, err := vmm.client.PutVmReceiveMigrationWithResponse(ctx, ch.ReceiveMigrationData{
+		ReceiverUrl: receiverURL,
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("error receive cloud hypervisor migration: %w", err)
+		telemetry.ReportCriticalError(ctx, errMsg)
+
+		return errMsg
+	}
+	if !isRequestSucceed(resp.StatusCode()) {
+		errMsg := fmt.Errorf("error receive cloud hypervisor migration: %s %s", resp.Status(), string(resp.Body))
 		telemetry.ReportCriticalError(ctx, errMsg)
 		return errMsg
 	}
+	telemetry.ReportEvent(ctx, "received ch migration", attribute.String("migration.receiver_url", receiverURL))
 	return nil
 }
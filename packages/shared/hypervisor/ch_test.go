@@ -0,0 +1,59 @@
+package hypervisor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/ch"
+)
+
+// Serve the restore request of cloud hypervisor with the status codes in order,
+// the last one is repeated.
+func newRestoreServer(t *testing.T, statusCodes ...int) (*CloudHypervisor, *atomic.Int32) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/vm.restore" {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		i := int(requests.Add(1)) - 1
+		w.WriteHeader(statusCodes[min(i, len(statusCodes)-1)])
+	}))
+	t.Cleanup(srv.Close)
+	client, err := ch.NewClientWithResponses(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCloudHypervisor(&ChConfig{}, client), &requests
+}
+
+func TestChRestoreRetry(t *testing.T) {
+	testCases := []struct {
+		name        string
+		statusCodes []int
+		opts        RestoreOptions
+		expectErr   bool
+		requests    int32
+	}{
+		{"succeed", []int{http.StatusNoContent}, RestoreOptions{}, false, 1},
+		{"retry 5xx", []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusNoContent}, RestoreOptions{}, false, 3},
+		{"no retry on 4xx", []int{http.StatusBadRequest}, RestoreOptions{}, true, 1},
+		{"max retries", []int{http.StatusInternalServerError}, RestoreOptions{Retries: 2}, true, 3},
+		{"timeout", []int{http.StatusInternalServerError}, RestoreOptions{Retries: 100, Timeout: 120 * time.Millisecond}, true, 0},
+	}
+	for _, tc := range testCases {
+		vmm, requests := newRestoreServer(t, tc.statusCodes...)
+		vmm.config.Restore = tc.opts
+		err := vmm.Restore(context.Background(), t.TempDir())
+		if (err != nil) != tc.expectErr {
+			t.Fatalf("%s: expect error %v, got %v", tc.name, tc.expectErr, err)
+		}
+		// the number of requests before timeout depends on the timing
+		if tc.requests > 0 && requests.Load() != tc.requests {
+			t.Fatalf("%s: expect %d requests, got %d", tc.name, tc.requests, requests.Load())
+		}
+	}
+}
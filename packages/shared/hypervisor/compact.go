@@ -0,0 +1,182 @@
+package hypervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sys/unix"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+// Returned when the snapshots of a chain are not of the same vm
+// (i.e., their memfiles have different sizes).
+var ErrSnapshotChainMismatch = errors.New("snapshots of the chain do not match")
+
+// CompactFcSnapshot merges a chain of firecracker snapshots, i.e., the full
+// snapshot in baseDir and the diff snapshots in diffDirs (from the oldest to
+// the newest), into a full snapshot in dstDir.
+//
+// The diff snapshot is created by PUT /snapshot/create with snapshot_type
+// "Diff" (it requires track_dirty_pages of PUT /machine-config, see
+// configMachine), where firecracker only writes the pages dirtied since the
+// previous snapshot into the memfile and leaves the others as holes. As
+// PUT /snapshot/load expects a memfile containing all the pages, the memfiles
+// of the chain are layered in order: the data regions of each one overwrite
+// the same offsets of the merged memfile, which is what
+// `snapshot-editor edit-memory rebase` of firecracker does. The snapfile of the
+// newest diff snapshot describes the vm state of the merged memory, so it is
+// copied as is. The result is loaded by PUT /snapshot/load like any other full
+// snapshot (see Restore).
+//
+// The merge is done offline instead of loading the chain into a firecracker and
+// creating a Full snapshot, as loading requires the tap device and drives
+// recorded in the snapfile to exist on the host.
+//
+// The files of the chain should not be written during merging, i.e., they
+// should not be the live snapshot dir of a running sandbox (which is overwritten
+// by its next snapshot). And they should be on a filesystem reporting the holes
+// (SEEK_HOLE), otherwise the holes of diff memfiles are taken as zeroed pages.
+// Existing snapshot in dstDir is never overwritten.
+func CompactFcSnapshot(ctx context.Context, baseDir string, diffDirs []string, dstDir string) error {
+	if len(diffDirs) == 0 {
+		return fmt.Errorf("no diff snapshot to compact onto %s", baseDir)
+	}
+	dirs := append([]string{baseDir}, diffDirs...)
+	for _, dir := range dirs {
+		if filepath.Clean(dir) == filepath.Clean(dstDir) {
+			return fmt.Errorf("output dir %s cannot be one of the snapshots to compact", dstDir)
+		}
+		if !utils.CheckFileExists(filepath.Join(dir, consts.FcMemfileName)) &&
+			utils.CheckFileExists(filepath.Join(dir, consts.FcCompressedMemfileName)) {
+			return fmt.Errorf("memfile of snapshot %s is compressed, decompress it before compacting", dir)
+		}
+	}
+	dstMemfile := filepath.Join(dstDir, consts.FcMemfileName)
+	dstSnapfile := filepath.Join(dstDir, consts.FcSnapfileName)
+	for _, path := range []string{dstMemfile, dstSnapfile} {
+		if utils.CheckFileExists(path) {
+			return fmt.Errorf("%s: %w", path, fs.ErrExist)
+		}
+	}
+	if err := utils.CreateDirAllIfNotExists(dstDir, 0o755); err != nil {
+		return fmt.Errorf("error creating output dir %s: %w", dstDir, err)
+	}
+
+	start := time.Now()
+	// written to temporary files first, so that a failed merge does not
+	// leave a partial snapshot in dstDir
+	tmpMemfile, tmpSnapfile := dstMemfile+".tmp", dstSnapfile+".tmp"
+	err := mergeMemfiles(ctx, dirs, tmpMemfile)
+	if err == nil {
+		err = copyFile(filepath.Join(diffDirs[len(diffDirs)-1], consts.FcSnapfileName), tmpSnapfile)
+	}
+	if err == nil {
+		err = os.Rename(tmpSnapfile, dstSnapfile)
+	}
+	if err == nil {
+		err = os.Rename(tmpMemfile, dstMemfile)
+	}
+	if err != nil {
+		os.Remove(tmpMemfile)
+		os.Remove(tmpSnapfile)
+		os.Remove(dstSnapfile)
+		errMsg := fmt.Errorf("error compacting fc snapshot %s: %w", baseDir, err)
+		telemetry.ReportCriticalError(ctx, errMsg)
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "fc snapshot compacted",
+		attribute.Int("compact.diff_count", len(diffDirs)),
+		attribute.Int64("compact.duration_ms", time.Since(start).Milliseconds()),
+	)
+	return nil
+}
+
+// Layer the memfiles of dirs in order into dst, which is kept sparse.
+func mergeMemfiles(ctx context.Context, dirs []string, dst string) error {
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	var size int64 = -1
+	for _, dir := range dirs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		src, err := os.Open(filepath.Join(dir, consts.FcMemfileName))
+		if err != nil {
+			return err
+		}
+		info, err := src.Stat()
+		if err == nil && size == -1 {
+			size = info.Size()
+			err = out.Truncate(size)
+		} else if err == nil && info.Size() != size {
+			err = fmt.Errorf("%w: memfile of %s has size %d, expect %d", ErrSnapshotChainMismatch, dir, info.Size(), size)
+		}
+		if err == nil {
+			err = copyDataRegions(out, src, size)
+		}
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return out.Sync()
+}
+
+// Copy the data regions (i.e., skipping the holes) of src to the same
+// offsets of dst.
+func copyDataRegions(dst, src *os.File, size int64) error {
+	var off int64
+	for off < size {
+		data, err := src.Seek(off, unix.SEEK_DATA)
+		if errors.Is(err, unix.ENXIO) {
+			// no data after off
+			return nil
+		} else if err != nil {
+			return err
+		}
+		hole, err := src.Seek(data, unix.SEEK_HOLE)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(io.NewOffsetWriter(dst, data), io.NewSectionReader(src, data, hole-data)); err != nil {
+			return err
+		}
+		off = hole
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,93 @@
+package hypervisor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+const testPageSize = 4096
+
+// Write a snapshot into dir whose memfile has size pages, and only the pages
+// in dirty (page index -> content) are written, the others are holes.
+func writeTestSnapshot(t *testing.T, dir string, pages int, dirty map[int]byte, snapfile string) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(dir, consts.FcMemfileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := f.Truncate(int64(pages * testPageSize)); err != nil {
+		t.Fatal(err)
+	}
+	for i, b := range dirty {
+		if _, err := f.WriteAt(bytes.Repeat([]byte{b}, testPageSize), int64(i*testPageSize)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, consts.FcSnapfileName), []byte(snapfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCompactFcSnapshot(t *testing.T) {
+	root := t.TempDir()
+	base, diff1, diff2 := filepath.Join(root, "base"), filepath.Join(root, "diff1"), filepath.Join(root, "diff2")
+	writeTestSnapshot(t, base, 4, map[int]byte{0: 'a', 1: 'a', 2: 'a'}, "base")
+	writeTestSnapshot(t, diff1, 4, map[int]byte{1: 'b'}, "diff1")
+	writeTestSnapshot(t, diff2, 4, map[int]byte{1: 'c', 3: 'c'}, "diff2")
+
+	dst := filepath.Join(root, "compacted")
+	if err := CompactFcSnapshot(context.Background(), base, []string{diff1, diff2}, dst); err != nil {
+		t.Fatalf("compact snapshot failed: %s", err)
+	}
+	memfile, err := os.ReadFile(filepath.Join(dst, consts.FcMemfileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expect := range []byte{'a', 'c', 'a', 'c'} {
+		if page := memfile[i*testPageSize : (i+1)*testPageSize]; !bytes.Equal(page, bytes.Repeat([]byte{expect}, testPageSize)) {
+			t.Fatalf("unexpected content of page %d: %q...", i, page[:8])
+		}
+	}
+	snapfile, err := os.ReadFile(filepath.Join(dst, consts.FcSnapfileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(snapfile) != "diff2" {
+		t.Fatalf("expect snapfile of the newest diff, got %q", snapfile)
+	}
+
+	// never overwrite the existing snapshot
+	if err := CompactFcSnapshot(context.Background(), base, []string{diff1}, dst); !errors.Is(err, fs.ErrExist) {
+		t.Fatalf("expect exist error, got %v", err)
+	}
+}
+
+func TestCompactFcSnapshotMismatch(t *testing.T) {
+	root := t.TempDir()
+	base, diff := filepath.Join(root, "base"), filepath.Join(root, "diff")
+	writeTestSnapshot(t, base, 4, map[int]byte{0: 'a'}, "base")
+	writeTestSnapshot(t, diff, 2, map[int]byte{1: 'b'}, "diff")
+
+	dst := filepath.Join(root, "compacted")
+	err := CompactFcSnapshot(context.Background(), base, []string{diff}, dst)
+	if !errors.Is(err, ErrSnapshotChainMismatch) {
+		t.Fatalf("expect chain mismatch, got %v", err)
+	}
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("expect no file left in output dir, got %d", len(entries))
+	}
+}
@@ -2,9 +2,11 @@ package hypervisor
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -21,6 +23,25 @@ var (
 	_ Hypervisor = (*Firecracker)(nil)
 )
 
+var ErrInvalidFcLogLevel = errors.New("invalid firecracker log level")
+
+// The levels of firecracker logger.
+var fcLogLevels = []string{
+	models.LoggerLevelError,
+	models.LoggerLevelWarning,
+	models.LoggerLevelInfo,
+	models.LoggerLevelDebug,
+	models.LoggerLevelTrace,
+	models.LoggerLevelOff,
+}
+
+func ValidateFcLogLevel(level string) error {
+	if !slices.Contains(fcLogLevels, level) {
+		return fmt.Errorf("%w: %q should be one of %v", ErrInvalidFcLogLevel, level, fcLogLevels)
+	}
+	return nil
+}
+
 type FcConfig struct {
 	VcpuCount          int64
 	MemoryMB           int64
@@ -28,12 +49,40 @@ type FcConfig struct {
 	EnableDiffSnapshot bool
 	KernelBootCmd      string
 	EnableOverlayFS    bool
+	// attach the rootfs read-only without the writable one (see immutable_rootfs of template)
+	ReadOnlyRootfs     bool
 	RootfsPath         string
 	WritableRootfsPath string
 	TapDevName         string
 	GuestNetIfaceName  string
 	GuestNetMacAddr    string
-	EnableHugepage     bool
+	// size of huge page in bytes, 0 means do not use huge page
+	HugePageSize int64
+	// the static cpu template (see cpu_template of template), empty means none.
+	// It is only applied when booting, the restored vm keeps the one of snapshot.
+	CpuTemplate string
+	// empty means do not attach config drive
+	ConfigDrivePath string
+	// the placeholders of extra disk slots, id of the i-th one is
+	// ExtraDiskID(i), their backing files are replaced when restoring
+	ExtraDiskPaths []string
+	// attach a balloon device (initially deflated) for reclaiming memory
+	EnableBalloon bool
+	// load the memfile into page cache before restoring from snapshot
+	PrefaultMemory bool
+	// if not empty, the memfile of snapshot is compressed (see CompressMemfile),
+	// and it is decompressed to this path when restoring
+	MemfileDecompressPath string
+	Restore               RestoreOptions
+	// where firecracker writes its own logs (at LogLevel, see ValidateFcLogLevel)
+	// and metrics, empty means not configured. The files should exist before,
+	// as firecracker does not create them.
+	LogPath     string
+	LogLevel    string
+	MetricsPath string
+	// limit the io of each drive, which is applied when configuring the vm. When
+	// restoring, the drives keep the limits in snapshot unless it is not zero.
+	DiskRateLimit DiskRateLimit
 
 	MmdsData *MmdsMetadata
 }
@@ -53,10 +102,16 @@ type MmdsMetadata struct {
 	Address   string `json:"address"`
 	TraceID   string `json:"traceID,omitempty"`
 	TeamID    string `json:"teamID,omitempty"`
+	// the defaults of processes spawned by envd
+	WorkingDir string            `json:"workingDir,omitempty"`
+	Env        map[string]string `json:"env,omitempty"`
+	// the metadata specified when creating the sandbox, for the processes in
+	// guest (e.g., the job id). Not available with cloud hypervisor (no mmds).
+	Custom map[string]string `json:"custom,omitempty"`
 }
 
-func FirecrackerCmd(binaryPath, socketPath string) string {
-	return binaryPath + " --api-sock " + socketPath
+func FirecrackerCmd(binaryPath, socketPath string, extraArgs []string) (string, error) {
+	return appendExtraArgs(binaryPath+" --api-sock "+socketPath, "--api-sock", extraArgs)
 }
 
 type Firecracker struct {
@@ -68,6 +123,39 @@ func NewFirecracker(config *FcConfig, client *client.FirecrackerAPI) *Firecracke
 	return &Firecracker{config, client}
 }
 
+// Configure the logger and metrics of firecracker (if set), which should be
+// the first request, so that the errors of the following ones are logged.
+func (fc *Firecracker) configLogging(ctx context.Context) error {
+	if fc.config.LogPath != "" {
+		showLevel := true
+		loggerParams := operations.PutLoggerParams{
+			Context: ctx,
+			Body: &models.Logger{
+				LogPath:   fc.config.LogPath,
+				Level:     &fc.config.LogLevel,
+				ShowLevel: &showLevel,
+			},
+		}
+		if _, err := fc.client.Operations.PutLogger(&loggerParams); err != nil {
+			return fmt.Errorf("error setting fc logger: %w", err)
+		}
+		telemetry.ReportEvent(ctx, "set fc logger", attribute.String("log_path", fc.config.LogPath))
+	}
+	if fc.config.MetricsPath != "" {
+		metricsParams := operations.PutMetricsParams{
+			Context: ctx,
+			Body: &models.Metrics{
+				MetricsPath: &fc.config.MetricsPath,
+			},
+		}
+		if _, err := fc.client.Operations.PutMetrics(&metricsParams); err != nil {
+			return fmt.Errorf("error setting fc metrics: %w", err)
+		}
+		telemetry.ReportEvent(ctx, "set fc metrics", attribute.String("metrics_path", fc.config.MetricsPath))
+	}
+	return nil
+}
+
 func (fc *Firecracker) configBootSource(ctx context.Context) error {
 	bootSourceConfig := operations.PutGuestBootSourceParams{
 		Context: ctx,
@@ -83,9 +171,11 @@ func (fc *Firecracker) configBootSource(ctx context.Context) error {
 	return err
 }
 
-func (fc *Firecracker) configBlkDrivers(ctx context.Context) error {
+// The drives of vm, all of them are limited by DiskRateLimit.
+func (fc *Firecracker) blkDriverConfigs(ctx context.Context) []operations.PutGuestDriveByIDParams {
 	var blkDriverConfigs []operations.PutGuestDriveByIDParams
 	ioEngine := "Async"
+	rateLimiter := fc.config.DiskRateLimit.fcRateLimiter()
 
 	// first prepare the base rootfs
 	{
@@ -98,8 +188,9 @@ func (fc *Firecracker) configBlkDrivers(ctx context.Context) error {
 				DriveID:      &driverId,
 				PathOnHost:   fc.config.RootfsPath,
 				IsRootDevice: &isRootDevice,
-				IsReadOnly:   fc.config.EnableOverlayFS,
+				IsReadOnly:   fc.config.EnableOverlayFS || fc.config.ReadOnlyRootfs,
 				IoEngine:     &ioEngine,
+				RateLimiter:  rateLimiter,
 			},
 		})
 	}
@@ -116,17 +207,78 @@ func (fc *Firecracker) configBlkDrivers(ctx context.Context) error {
 				IsRootDevice: &isRootDevice,
 				IsReadOnly:   false,
 				IoEngine:     &ioEngine,
+				RateLimiter:  rateLimiter,
 			},
 		},
 		)
 	}
 
-	for _, config := range blkDriverConfigs {
+	if fc.config.ConfigDrivePath != "" {
+		driverId := "config"
+		isRootDevice := false
+		blkDriverConfigs = append(blkDriverConfigs, operations.PutGuestDriveByIDParams{
+			Context: ctx,
+			DriveID: driverId,
+			Body: &models.Drive{
+				DriveID:      &driverId,
+				PathOnHost:   fc.config.ConfigDrivePath,
+				IsRootDevice: &isRootDevice,
+				IsReadOnly:   true,
+				IoEngine:     &ioEngine,
+				RateLimiter:  rateLimiter,
+			},
+		})
+	}
+
+	for i, path := range fc.config.ExtraDiskPaths {
+		driverId := ExtraDiskID(i)
+		isRootDevice := false
+		blkDriverConfigs = append(blkDriverConfigs, operations.PutGuestDriveByIDParams{
+			Context: ctx,
+			DriveID: driverId,
+			Body: &models.Drive{
+				DriveID:      &driverId,
+				PathOnHost:   path,
+				IsRootDevice: &isRootDevice,
+				IsReadOnly:   false,
+				IoEngine:     &ioEngine,
+				RateLimiter:  rateLimiter,
+			},
+		})
+	}
+
+	return blkDriverConfigs
+}
+
+func (fc *Firecracker) configBlkDrivers(ctx context.Context) error {
+	for _, config := range fc.blkDriverConfigs(ctx) {
 		if _, err := fc.client.Operations.PutGuestDriveByID(&config); err != nil {
 			return err
 		}
 	}
+	return nil
+}
 
+// Override the rate limiter of drives restored from the snapshot by DiskRateLimit.
+func (fc *Firecracker) patchDiskRateLimit(ctx context.Context) error {
+	rateLimiter := fc.config.DiskRateLimit.fcRateLimiter()
+	for _, config := range fc.blkDriverConfigs(ctx) {
+		params := operations.PatchGuestDriveByIDParams{
+			Context: ctx,
+			DriveID: config.DriveID,
+			Body: &models.PartialDrive{
+				DriveID:     config.Body.DriveID,
+				RateLimiter: rateLimiter,
+			},
+		}
+		if _, err := fc.client.Operations.PatchGuestDriveByID(&params); err != nil {
+			return fmt.Errorf("error patching rate limiter of fc drive %s: %w", config.DriveID, err)
+		}
+	}
+	telemetry.ReportEvent(ctx, "patched fc disk rate limit",
+		attribute.Int64("disk.bandwidth_bps", fc.config.DiskRateLimit.BandwidthBps),
+		attribute.Int64("disk.iops", fc.config.DiskRateLimit.Iops),
+	)
 	return nil
 }
 
@@ -176,8 +328,15 @@ func (fc *Firecracker) configMachine(ctx context.Context) error {
 		TrackDirtyPages: &trackDirtyPages,
 	}
 
-	if fc.config.EnableHugepage {
+	switch fc.config.HugePageSize {
+	case 0:
+	case 2 << 20:
 		machineConfig.HugePages = models.MachineConfigurationHugePagesNr2M
+	default:
+		return fmt.Errorf("firecracker does not support huge page size %d", fc.config.HugePageSize)
+	}
+	if fc.config.CpuTemplate != "" {
+		machineConfig.CPUTemplate = models.NewCPUTemplate(models.CPUTemplate(fc.config.CpuTemplate))
 	}
 
 	machineConfigParams := operations.PutMachineConfigurationParams{
@@ -189,6 +348,21 @@ func (fc *Firecracker) configMachine(ctx context.Context) error {
 	return err
 }
 
+func (fc *Firecracker) configBalloon(ctx context.Context) error {
+	amountMiB := int64(0)
+	deflateOnOom := true
+	balloonParams := operations.PutBalloonParams{
+		Context: ctx,
+		Body: &models.Balloon{
+			AmountMib:    &amountMiB,
+			DeflateOnOom: &deflateOnOom,
+		},
+	}
+	_, err := fc.client.Operations.PutBalloon(&balloonParams)
+	return err
+}
+
+// 0. setup logger and metrics (if configured)
 // 1. setup boot args (including ip=xxx)
 // 2. setup drivers (rootfs.ext4)
 // 3. setup network interface (tap device)
@@ -196,6 +370,11 @@ func (fc *Firecracker) configMachine(ctx context.Context) error {
 // 5. machine config (including vpu, mem)
 // 6. finally start vm
 func (fc *Firecracker) Configure(ctx context.Context) error {
+	if err := fc.configLogging(ctx); err != nil {
+		telemetry.ReportCriticalError(ctx, err)
+		return err
+	}
+
 	if err := fc.configBootSource(ctx); err != nil {
 		errMsg := fmt.Errorf("error setting fc boot source config: %w", err)
 		telemetry.ReportCriticalError(ctx, errMsg)
@@ -235,6 +414,24 @@ func (fc *Firecracker) Configure(ctx context.Context) error {
 	}
 	telemetry.ReportEvent(ctx, "set fc mmds config")
 
+	// the metadata of sandbox when cold booted (instead of restored),
+	// which is nil when building the template
+	if fc.config.MmdsData != nil {
+		if err := fc.putMmdsData(ctx); err != nil {
+			return fmt.Errorf("error setting fc mmds data: %w", err)
+		}
+	}
+
+	if fc.config.EnableBalloon {
+		if err := fc.configBalloon(ctx); err != nil {
+			errMsg := fmt.Errorf("error setting fc balloon config: %w", err)
+			telemetry.ReportCriticalError(ctx, errMsg)
+
+			return errMsg
+		}
+		telemetry.ReportEvent(ctx, "set fc balloon config")
+	}
+
 	// We may need to sleep before start - previous configuration is processes asynchronously. How to do this sync or in one go?
 	time.Sleep(consts.WaitTimeForConfig)
 
@@ -310,14 +507,79 @@ func (fc *Firecracker) Resume(ctx context.Context) error {
 	return nil
 }
 
-func (fc *Firecracker) Snapshot(ctx context.Context, dir string) error {
-	memfilePath := filepath.Join(dir, consts.FcMemfileName)
-	snapfileName := filepath.Join(dir, consts.FcSnapfileName)
+func (fc *Firecracker) SetBalloon(ctx context.Context, amountMiB int64) error {
+	balloonParams := operations.PatchBalloonParams{
+		Context: ctx,
+		Body: &models.BalloonUpdate{
+			AmountMib: &amountMiB,
+		},
+	}
+	if _, err := fc.client.Operations.PatchBalloon(&balloonParams); err != nil {
+		errMsg := fmt.Errorf("error setting fc balloon: %w", err)
+		telemetry.ReportCriticalError(ctx, errMsg)
+
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "set fc balloon", attribute.Int64("balloon.amount_mib", amountMiB))
+	return nil
+}
+
+func (fc *Firecracker) Balloon(ctx context.Context) (int64, error) {
+	balloonParams := operations.DescribeBalloonConfigParams{
+		Context: ctx,
+	}
+	resp, err := fc.client.Operations.DescribeBalloonConfig(&balloonParams)
+	if err != nil {
+		return 0, fmt.Errorf("error getting fc balloon: %w", err)
+	}
+	if resp.Payload == nil || resp.Payload.AmountMib == nil {
+		return 0, fmt.Errorf("fc balloon is not configured")
+	}
+	return *resp.Payload.AmountMib, nil
+}
+
+// The drive of slot is always writable (as the slots are
+// configured when building), so readOnly is not supported here.
+func (fc *Firecracker) AttachDisk(ctx context.Context, id, path string, readOnly bool) error {
+	if readOnly {
+		return fmt.Errorf("firecracker does not support read-only extra disk %s", id)
+	}
+	// patching the drive (even with the same path) makes fc reopen
+	// the backing file and notify guest the new capacity
+	driveParams := operations.PatchGuestDriveByIDParams{
+		Context: ctx,
+		DriveID: id,
+		Body: &models.PartialDrive{
+			DriveID:    &id,
+			PathOnHost: path,
+		},
+	}
+	if _, err := fc.client.Operations.PatchGuestDriveByID(&driveParams); err != nil {
+		errMsg := fmt.Errorf("error attaching fc extra disk %s: %w", id, err)
+		telemetry.ReportCriticalError(ctx, errMsg)
 
+		return errMsg
+	}
+	telemetry.ReportEvent(ctx, "attached fc extra disk", attribute.String("disk.id", id))
+	return nil
+}
+
+func (fc *Firecracker) Snapshot(ctx context.Context, dir string) error {
 	snapshotType := models.SnapshotCreateParamsSnapshotTypeFull
 	if fc.config.EnableDiffSnapshot {
 		snapshotType = models.SnapshotCreateParamsSnapshotTypeDiff
 	}
+	return fc.snapshot(ctx, dir, snapshotType)
+}
+
+// Create a full snapshot even if EnableDiffSnapshot, see FullSnapshotter.
+func (fc *Firecracker) SnapshotFull(ctx context.Context, dir string) error {
+	return fc.snapshot(ctx, dir, models.SnapshotCreateParamsSnapshotTypeFull)
+}
+
+func (fc *Firecracker) snapshot(ctx context.Context, dir string, snapshotType string) error {
+	memfilePath := filepath.Join(dir, consts.FcMemfileName)
+	snapfileName := filepath.Join(dir, consts.FcSnapfileName)
 
 	params := operations.CreateSnapshotParams{
 		Context: ctx,
@@ -340,15 +602,64 @@ func (fc *Firecracker) Snapshot(ctx context.Context, dir string) error {
 		return errMsg
 	}
 
-	telemetry.ReportEvent(ctx, "created vm snapshot")
+	telemetry.ReportEvent(ctx, "created vm snapshot", attribute.String("snapshot.type", snapshotType))
 
 	return nil
 }
 
+// Compress the memfile of snapshot in dir, the uncompressed one is removed.
+//
+// The snapshot should be restored with MemfileDecompressPath set.
+func CompressMemfile(ctx context.Context, dir string) error {
+	memfilePath := filepath.Join(dir, consts.FcMemfileName)
+	start := time.Now()
+	if err := utils.CompressFile(ctx, memfilePath, filepath.Join(dir, consts.FcCompressedMemfileName)); err != nil {
+		return fmt.Errorf("error compressing fc memfile: %w", err)
+	}
+	if err := os.Remove(memfilePath); err != nil {
+		return fmt.Errorf("error removing uncompressed fc memfile: %w", err)
+	}
+	telemetry.ReportEvent(ctx, "fc memfile compressed",
+		attribute.Int64("compress.duration_ms", time.Since(start).Milliseconds()),
+	)
+	return nil
+}
+
 func (fc *Firecracker) Restore(ctx context.Context, dir string) error {
 	memfilePath := filepath.Join(dir, consts.FcMemfileName)
 	snapfileName := filepath.Join(dir, consts.FcSnapfileName)
 
+	// the logger and metrics are not part of the snapshot
+	if err := fc.configLogging(ctx); err != nil {
+		telemetry.ReportCriticalError(ctx, err)
+		return err
+	}
+
+	if fc.config.MemfileDecompressPath != "" {
+		start := time.Now()
+		memfilePath = fc.config.MemfileDecompressPath
+		if err := utils.DecompressFile(ctx, filepath.Join(dir, consts.FcCompressedMemfileName), memfilePath); err != nil {
+			errMsg := fmt.Errorf("error decompressing fc memfile: %w", err)
+			telemetry.ReportCriticalError(ctx, errMsg)
+			return errMsg
+		}
+		telemetry.ReportEvent(ctx, "fc memfile decompressed",
+			attribute.Int64("decompress.duration_ms", time.Since(start).Milliseconds()),
+		)
+	}
+
+	if fc.config.PrefaultMemory {
+		start := time.Now()
+		if err := utils.PrefaultFile(memfilePath); err != nil {
+			errMsg := fmt.Errorf("error prefaulting fc memfile: %w", err)
+			telemetry.ReportCriticalError(ctx, errMsg)
+			return errMsg
+		}
+		telemetry.ReportEvent(ctx, "fc memfile prefaulted",
+			attribute.Int64("prefault.duration_ms", time.Since(start).Milliseconds()),
+		)
+	}
+
 	membackendType := models.MemoryBackendBackendTypeFile
 	snapshotLoadParams := models.SnapshotLoadParams{
 		MemBackend: &models.MemoryBackend{
@@ -359,28 +670,40 @@ func (fc *Firecracker) Restore(ctx context.Context, dir string) error {
 		ResumeVM:            true,
 		EnableDiffSnapshots: fc.config.EnableDiffSnapshot,
 	}
+	loadCtx, cancel := fc.config.Restore.withTimeout(ctx)
+	defer cancel()
 	snapshotConfig := operations.LoadSnapshotParams{
-		Context: ctx,
+		Context: loadCtx,
 		Body:    &snapshotLoadParams,
 	}
-	// retry for 3 times
-	retryTimes, err := utils.RetryHttpRequest(ctx, func() error {
+	retryTimes, err := utils.RetryHttpRequest(loadCtx, func() error {
 		_, err := fc.client.Operations.LoadSnapshot(&snapshotConfig)
 		return err
-	}, 3)
+	}, fc.config.Restore.retries())
 	if err != nil {
-		telemetry.ReportCriticalError(ctx, err)
+		telemetry.ReportCriticalError(ctx, err, attribute.Int("retry_times", retryTimes))
 		return err
 	}
 	telemetry.ReportEvent(ctx, "fc snapshot loaded", attribute.Int("retry_times", retryTimes))
 
+	if !fc.config.DiskRateLimit.IsZero() {
+		if err := fc.patchDiskRateLimit(ctx); err != nil {
+			telemetry.ReportCriticalError(ctx, err)
+			return err
+		}
+	}
+
+	return fc.putMmdsData(ctx)
+}
+
+func (fc *Firecracker) putMmdsData(ctx context.Context) error {
 	mmdsConfig := operations.PutMmdsParams{
 		Context: ctx,
 		Body:    fc.config.MmdsData,
 	}
 	// retry for 3 times
-	retryTimes, err = utils.RetryHttpRequest(ctx, func() error {
-		_, err = fc.client.Operations.PutMmds(&mmdsConfig)
+	retryTimes, err := utils.RetryHttpRequest(ctx, func() error {
+		_, err := fc.client.Operations.PutMmds(&mmdsConfig)
 		return err
 	}, 3)
 	if err != nil {
@@ -388,6 +711,5 @@ func (fc *Firecracker) Restore(ctx context.Context, dir string) error {
 		return err
 	}
 	telemetry.ReportEvent(ctx, "mmds data set", attribute.Int("retry_times", retryTimes))
-
 	return nil
 }
@@ -0,0 +1,48 @@
+package hypervisor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestValidateFcLogLevel(t *testing.T) {
+	for _, level := range []string{"Error", "Warning", "Info", "Debug", "Trace", "Off"} {
+		if err := ValidateFcLogLevel(level); err != nil {
+			t.Fatalf("expect %q valid, got %s", level, err)
+		}
+	}
+	for _, level := range []string{"", "warning", "Warn"} {
+		if err := ValidateFcLogLevel(level); !errors.Is(err, ErrInvalidFcLogLevel) {
+			t.Fatalf("expect %q invalid, got %v", level, err)
+		}
+	}
+}
+
+func TestFcDiskRateLimit(t *testing.T) {
+	cfg := &FcConfig{
+		RootfsPath:         "/rootfs",
+		EnableOverlayFS:    true,
+		WritableRootfsPath: "/writable",
+		ExtraDiskPaths:     []string{"/extra-0"},
+		DiskRateLimit:      DiskRateLimit{BandwidthBps: 100 << 20},
+	}
+	drives := NewFirecracker(cfg, nil).blkDriverConfigs(context.Background())
+	if len(drives) != 3 {
+		t.Fatalf("expect 3 drives, got %d", len(drives))
+	}
+	for _, drive := range drives {
+		limiter := drive.Body.RateLimiter
+		if limiter == nil || limiter.Ops != nil || *limiter.Bandwidth.Size != 100<<20 ||
+			*limiter.Bandwidth.RefillTime != rateLimitRefillMs {
+			t.Fatalf("unexpected rate limiter of drive %s: %+v", drive.DriveID, limiter)
+		}
+	}
+
+	cfg.DiskRateLimit = DiskRateLimit{}
+	for _, drive := range NewFirecracker(cfg, nil).blkDriverConfigs(context.Background()) {
+		if drive.Body.RateLimiter != nil {
+			t.Fatalf("expect drive %s unlimited, got %+v", drive.DriveID, drive.Body.RateLimiter)
+		}
+	}
+}
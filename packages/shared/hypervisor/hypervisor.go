@@ -2,8 +2,16 @@ package hypervisor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 )
 
+var ErrConflictHypervisorArg = errors.New("extra hypervisor arg conflicts with the generated one")
+
 // The abstract interface provided by Sandbox implementation
 // (e.g., Cloud Hypervisor or Firecracker), which will be used
 // by template manager.
@@ -15,4 +23,86 @@ type Hypervisor interface {
 	Restore(ctx context.Context, dir string) error
 	Snapshot(ctx context.Context, dir string) error
 	Cleanup(ctx context.Context) error
+	// Set the target size (in MiB) of the balloon device, which must be
+	// configured when the vm is created.
+	SetBalloon(ctx context.Context, amountMiB int64) error
+	// Get the current target size (in MiB) of the balloon device.
+	Balloon(ctx context.Context) (int64, error)
+	// Attach an extra disk to the running vm. For firecracker, the disk
+	// should have been configured (as a slot) before boot, this only makes the
+	// guest rescan it; for cloud-hypervisor, the disk is hotplugged.
+	AttachDisk(ctx context.Context, id, path string, readOnly bool) error
+}
+
+// Implemented by the hypervisors supporting live migration, i.e., cloud
+// hypervisor. The url is where the receiver listens, e.g., unix:/path/to/sock.
+type Migrator interface {
+	// Stream the state of the running vm to the receiver, the vm is shut
+	// down after migrated successfully, otherwise it keeps running.
+	SendMigration(ctx context.Context, destinationURL string) error
+	// Wait for the vm migrated from the sender, the vm is running after
+	// it returns successfully. It should be called instead of Configure
+	// and Restore.
+	ReceiveMigration(ctx context.Context, receiverURL string) error
+}
+
+// Implemented by the hypervisors able to resize the memory of a running vm,
+// i.e., cloud hypervisor with MemoryHotplugMB configured when the vm is created.
+type MemoryResizer interface {
+	// Set the memory (in MiB) of vm, which should be in
+	// [MemoryMB, MemoryMB + MemoryHotplugMB].
+	ResizeMemory(ctx context.Context, memoryMB int64) error
+}
+
+// Implemented by the hypervisors whose Snapshot may create a diff snapshot,
+// i.e., firecracker with EnableDiffSnapshot. A diff snapshot only contains
+// the pages dirtied since the last snapshot (and resets the tracking), so
+// the retry of a failed one must be a full snapshot.
+type FullSnapshotter interface {
+	// Create a full snapshot of the paused vm into dir.
+	SnapshotFull(ctx context.Context, dir string) error
+}
+
+const DefaultRestoreRetries = 3
+
+// How to retry the restore request on transient errors (e.g., the vmm
+// is not ready to accept the request on loaded hosts).
+type RestoreOptions struct {
+	// the max number of retries, 0 means DefaultRestoreRetries
+	Retries int
+	// the timeout of the restore request (including all retries),
+	// 0 means no timeout
+	Timeout time.Duration
+}
+
+func (o RestoreOptions) retries() int {
+	if o.Retries <= 0 {
+		return DefaultRestoreRetries
+	}
+	return o.Retries
+}
+
+func (o RestoreOptions) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, o.Timeout)
+}
+
+// The device id of the i-th extra disk.
+func ExtraDiskID(i int) string {
+	return fmt.Sprintf("extra-disk-%d", i)
+}
+
+// Append the extra args (quoted, as the command is executed by `bash -c`) to cmd.
+// The args which (re-)specify the api socket are rejected, as the socket is
+// managed by us.
+func appendExtraArgs(cmd, socketArg string, extraArgs []string) (string, error) {
+	for _, arg := range extraArgs {
+		if arg == socketArg || strings.HasPrefix(arg, socketArg+"=") {
+			return "", fmt.Errorf("%w: %s", ErrConflictHypervisorArg, arg)
+		}
+		cmd += " " + utils.ShellQuote(arg)
+	}
+	return cmd, nil
 }
@@ -0,0 +1,55 @@
+package hypervisor
+
+import (
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/ch"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/fc/models"
+)
+
+// The token buckets are refilled every second, i.e., the limits are per second.
+const rateLimitRefillMs int64 = 1000
+
+// The limits of io of each disk, 0 means unlimited. Both vmms limit the
+// bandwidth and operations (of reads and writes together) by token buckets.
+type DiskRateLimit struct {
+	BandwidthBps int64
+	Iops         int64
+}
+
+func (l DiskRateLimit) IsZero() bool {
+	return l.BandwidthBps == 0 && l.Iops == 0
+}
+
+// nil if unlimited
+func (l DiskRateLimit) fcRateLimiter() *models.RateLimiter {
+	if l.IsZero() {
+		return nil
+	}
+	bucket := func(size int64) *models.TokenBucket {
+		if size == 0 {
+			return nil
+		}
+		refillTime := rateLimitRefillMs
+		return &models.TokenBucket{Size: &size, RefillTime: &refillTime}
+	}
+	return &models.RateLimiter{
+		Bandwidth: bucket(l.BandwidthBps),
+		Ops:       bucket(l.Iops),
+	}
+}
+
+// nil if unlimited
+func (l DiskRateLimit) chRateLimiter() *ch.RateLimiterConfig {
+	if l.IsZero() {
+		return nil
+	}
+	bucket := func(size int64) *ch.TokenBucket {
+		if size == 0 {
+			return nil
+		}
+		return &ch.TokenBucket{Size: size, RefillTime: rateLimitRefillMs}
+	}
+	return &ch.RateLimiterConfig{
+		Bandwidth: bucket(l.BandwidthBps),
+		Ops:       bucket(l.Iops),
+	}
+}
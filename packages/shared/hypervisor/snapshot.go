@@ -0,0 +1,126 @@
+package hypervisor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+)
+
+const DefaultSnapshotAttempts = 3
+
+var ErrInvalidSnapshot = errors.New("invalid snapshot")
+
+// The magic id at the beginning of the snapfile of firecracker, the low
+// 16 bits are the version of data format (in older versions of firecracker).
+const (
+	fcSnapshotMagicX86_64  uint64 = 0x0710_1984_8664_0000
+	fcSnapshotMagicAarch64 uint64 = 0x0710_1984_AAAA_0000
+	fcSnapshotMagicMask    uint64 = 0xFFFF_FFFF_FFFF_0000
+)
+
+// Create the snapshot of the paused vm into dir, and verify it (see
+// VerifySnapshot) on host, as occasionally the memfile is truncated or the
+// state is inconsistent. On failure, the files of snapshot are removed and it
+// is retried up to attempts times (0 means DefaultSnapshotAttempts), the vm
+// stays paused meanwhile. Return the number of attempts made.
+//
+// The retries create full snapshots if h is a FullSnapshotter, since the
+// dirty pages tracked for a diff snapshot were reset by the failed attempt.
+//
+// @hostDir: where the files of snapshot are on host, which differs from dir
+// when the vmm runs inside a chroot.
+func SnapshotVerified(ctx context.Context, h Hypervisor, dir, hostDir string, attempts int) (int, error) {
+	if attempts <= 0 {
+		attempts = DefaultSnapshotAttempts
+	}
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = ctx.Err(); err != nil {
+			return attempt - 1, err
+		}
+		if full, ok := h.(FullSnapshotter); ok && attempt > 1 {
+			err = full.SnapshotFull(ctx, dir)
+		} else {
+			err = h.Snapshot(ctx, dir)
+		}
+		if err == nil {
+			err = VerifySnapshot(h, hostDir)
+		}
+		if err == nil {
+			telemetry.ReportEvent(ctx, "vm snapshot verified", attribute.Int("snapshot.attempts", attempt))
+			return attempt, nil
+		}
+		telemetry.ReportError(ctx, fmt.Errorf("snapshot attempt %d of %d failed: %w", attempt, attempts, err))
+		if rmErr := removeSnapshotFiles(h, hostDir); rmErr != nil {
+			telemetry.ReportError(ctx, fmt.Errorf("error removing failed snapshot: %w", rmErr))
+		}
+	}
+	errMsg := fmt.Errorf("error creating vm snapshot after %d attempts: %w", attempts, err)
+	telemetry.ReportCriticalError(ctx, errMsg, attribute.Int("snapshot.attempts", attempts))
+	return attempts, errMsg
+}
+
+// The files of snapshot created by h.
+func snapshotFiles(h Hypervisor) []string {
+	if _, ok := h.(*CloudHypervisor); ok {
+		return consts.ChSnapshotFiles[:]
+	}
+	return []string{consts.FcSnapfileName, consts.FcMemfileName}
+}
+
+func removeSnapshotFiles(h Hypervisor, dir string) (finalErr error) {
+	for _, name := range snapshotFiles(h) {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			finalErr = errors.Join(finalErr, err)
+		}
+	}
+	return finalErr
+}
+
+// Fail with ErrInvalidSnapshot if any file of the snapshot (created by h) in dir
+// is missing or empty, or the snapfile of firecracker cannot be parsed.
+func VerifySnapshot(h Hypervisor, dir string) error {
+	for _, name := range snapshotFiles(h) {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		}
+		if info.Size() == 0 {
+			return fmt.Errorf("%w: %s is empty", ErrInvalidSnapshot, name)
+		}
+	}
+	if _, ok := h.(*Firecracker); ok {
+		return verifyFcSnapfile(filepath.Join(dir, consts.FcSnapfileName))
+	}
+	return nil
+}
+
+func verifyFcSnapfile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+	}
+	defer f.Close()
+	var magic uint64
+	if err := binary.Read(f, binary.LittleEndian, &magic); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("snapfile is truncated")
+		}
+		return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+	}
+	switch magic & fcSnapshotMagicMask {
+	case fcSnapshotMagicX86_64, fcSnapshotMagicAarch64:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown magic id %#x of snapfile", ErrInvalidSnapshot, magic)
+	}
+}
@@ -0,0 +1,129 @@
+package hypervisor
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+// Only Snapshot is implemented, which writes the snapshot by write.
+type fakeSnapshotter struct {
+	Hypervisor
+	calls int
+	write func(dir string, call int) error
+}
+
+func (f *fakeSnapshotter) Snapshot(ctx context.Context, dir string) error {
+	f.calls++
+	return f.write(dir, f.calls)
+}
+
+// Like fakeSnapshotter, but SnapshotFull counts fullCalls besides.
+type fakeFullSnapshotter struct {
+	fakeSnapshotter
+	fullCalls int
+}
+
+func (f *fakeFullSnapshotter) SnapshotFull(ctx context.Context, dir string) error {
+	f.fullCalls++
+	return f.Snapshot(ctx, dir)
+}
+
+func writeFcSnapshot(t *testing.T, dir string, magic uint64, memfile []byte) {
+	t.Helper()
+	snapfile := binary.LittleEndian.AppendUint64(nil, magic)
+	if err := os.WriteFile(filepath.Join(dir, consts.FcSnapfileName), snapfile, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, consts.FcMemfileName), memfile, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyFcSnapshot(t *testing.T) {
+	fc := &Firecracker{}
+	dir := t.TempDir()
+	if err := VerifySnapshot(fc, dir); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("expect invalid snapshot without files, got %v", err)
+	}
+
+	writeFcSnapshot(t, dir, fcSnapshotMagicX86_64|2, []byte("memory"))
+	if err := VerifySnapshot(fc, dir); err != nil {
+		t.Fatalf("expect valid snapshot, got %v", err)
+	}
+	writeFcSnapshot(t, dir, fcSnapshotMagicAarch64, []byte("memory"))
+	if err := VerifySnapshot(fc, dir); err != nil {
+		t.Fatalf("expect valid snapshot, got %v", err)
+	}
+
+	writeFcSnapshot(t, dir, fcSnapshotMagicX86_64, nil)
+	if err := VerifySnapshot(fc, dir); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("expect invalid snapshot with empty memfile, got %v", err)
+	}
+	writeFcSnapshot(t, dir, 0xdeadbeef, []byte("memory"))
+	if err := VerifySnapshot(fc, dir); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("expect invalid snapshot with unknown magic, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, consts.FcSnapfileName), []byte{0x07}, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifySnapshot(fc, dir); !errors.Is(err, ErrInvalidSnapshot) {
+		t.Fatalf("expect invalid snapshot with truncated snapfile, got %v", err)
+	}
+}
+
+func TestSnapshotVerified(t *testing.T) {
+	dir := t.TempDir()
+	// the memfile of the first attempt is truncated
+	fake := &fakeSnapshotter{write: func(dir string, call int) error {
+		if call == 1 {
+			writeFcSnapshot(t, dir, fcSnapshotMagicX86_64, nil)
+			return nil
+		}
+		writeFcSnapshot(t, dir, fcSnapshotMagicX86_64, []byte("memory"))
+		return nil
+	}}
+	attempts, err := SnapshotVerified(context.Background(), fake, dir, dir, 0)
+	if err != nil || attempts != 2 {
+		t.Fatalf("expect succeeded in 2 attempts, got %d (err %v)", attempts, err)
+	}
+
+	snapshotErr := errors.New("snapshot failed")
+	fake = &fakeSnapshotter{write: func(dir string, call int) error {
+		writeFcSnapshot(t, dir, fcSnapshotMagicX86_64, nil)
+		return snapshotErr
+	}}
+	attempts, err = SnapshotVerified(context.Background(), fake, dir, dir, 2)
+	if !errors.Is(err, snapshotErr) || attempts != 2 || fake.calls != 2 {
+		t.Fatalf("expect failed after 2 attempts, got %d (err %v)", attempts, err)
+	}
+	// the files of the failed snapshot are removed
+	if _, err := os.Stat(filepath.Join(dir, consts.FcMemfileName)); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expect memfile removed, got %v", err)
+	}
+}
+
+func TestSnapshotVerifiedRetryFull(t *testing.T) {
+	dir := t.TempDir()
+	// the (diff) snapshot of the first attempt is truncated
+	fake := &fakeFullSnapshotter{fakeSnapshotter: fakeSnapshotter{write: func(dir string, call int) error {
+		if call == 1 {
+			writeFcSnapshot(t, dir, fcSnapshotMagicX86_64, nil)
+			return nil
+		}
+		writeFcSnapshot(t, dir, fcSnapshotMagicX86_64, []byte("memory"))
+		return nil
+	}}}
+	attempts, err := SnapshotVerified(context.Background(), fake, dir, dir, 0)
+	if err != nil || attempts != 2 {
+		t.Fatalf("expect succeeded in 2 attempts, got %d (err %v)", attempts, err)
+	}
+	if fake.fullCalls != 1 {
+		t.Fatalf("expect only the retry to be a full snapshot, got %d full snapshots", fake.fullCalls)
+	}
+}
@@ -0,0 +1,101 @@
+package network
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+var ErrInvalidClonedIPPool = errors.New("invalid cloned ip pool")
+
+// How the HostClonedIP of a network env is derived from its index
+// (see NetworkEnv.WithClonedIPs).
+type ClonedIPStrategy interface {
+	ClonedIP(idx int) net.IP
+	// the max index whose cloned ip can be represented
+	MaxIdx() int
+}
+
+// The default strategy, which packs the cloned ips into
+// 192.168.{168+high}.{low} (low in [1, 254]).
+type defaultClonedIPs struct{}
+
+func (defaultClonedIPs) ClonedIP(idx int) net.IP {
+	low := idx%254 + 1 // range from [1, 254]
+	high := idx / 254
+	return net.IPv4(192, 168, byte(168+high), byte(low)).To4()
+}
+
+func (defaultClonedIPs) MaxIdx() int {
+	return (256-168)*254 - 1
+}
+
+// Allocate the cloned ips from an operator-chosen ipv4 subnet, the cloned
+// ip of idx is the idx-th address of subnet, so the network and broadcast
+// addresses are never used (as idx starts from 1).
+type SubnetClonedIPs struct {
+	subnet *net.IPNet
+}
+
+func NewSubnetClonedIPs(subnet *net.IPNet) (*SubnetClonedIPs, error) {
+	ones, bits := subnet.Mask.Size()
+	if subnet.IP.To4() == nil || bits != 32 {
+		return nil, fmt.Errorf("%w: %s is not an ipv4 subnet", ErrInvalidClonedIPPool, subnet)
+	}
+	if ones > 30 {
+		return nil, fmt.Errorf("%w: prefix length of %s should be at most 30", ErrInvalidClonedIPPool, subnet)
+	}
+	for _, addr := range []string{consts.GuestNetIPAddr, consts.HostTapIPAddress} {
+		if subnet.Contains(net.ParseIP(addr)) {
+			return nil, fmt.Errorf("%w: %s contains %s used inside the sandbox netns", ErrInvalidClonedIPPool, subnet, addr)
+		}
+	}
+	return &SubnetClonedIPs{subnet: subnet}, nil
+}
+
+func (s *SubnetClonedIPs) ClonedIP(idx int) net.IP {
+	base := binary.BigEndian.Uint32(s.subnet.IP.To4())
+	result := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(result, base+uint32(idx))
+	return result
+}
+
+func (s *SubnetClonedIPs) MaxIdx() int {
+	ones, bits := s.subnet.Mask.Size()
+	return 1<<(bits-ones) - 2
+}
+
+// Make sure the cloned ips of the network envs in [1, maxIdx] can be
+// represented by the strategy, and do not overlap with the veth subnet.
+func ValidateClonedIPStrategy(strategy ClonedIPStrategy, maxIdx int, vethSubnet *net.IPNet) error {
+	if strategy.MaxIdx() < maxIdx {
+		return fmt.Errorf("%w: only %d addresses, while up to %d networks can be created",
+			ErrInvalidClonedIPPool, strategy.MaxIdx(), maxIdx)
+	}
+	for _, idx := range []int{1, maxIdx} {
+		if ip := strategy.ClonedIP(idx); vethSubnet != nil && vethSubnet.Contains(ip) {
+			return fmt.Errorf("%w: cloned ip %s overlaps with the veth subnet %s", ErrInvalidClonedIPPool, ip, vethSubnet)
+		}
+	}
+	if s, ok := strategy.(*SubnetClonedIPs); ok && vethSubnet != nil && s.subnet.Contains(vethSubnet.IP) {
+		return fmt.Errorf("%w: %s overlaps with the veth subnet %s", ErrInvalidClonedIPPool, s.subnet, vethSubnet)
+	}
+	return nil
+}
+
+// Use the strategy to derive the HostClonedIP, nil means the default one
+// (i.e., 192.168.168.0 ~ 192.168.255.255).
+func (n NetworkEnv) WithClonedIPs(strategy ClonedIPStrategy) NetworkEnv {
+	n.clonedIPs = strategy
+	return n
+}
+
+func (n *NetworkEnv) clonedIPStrategy() ClonedIPStrategy {
+	if n.clonedIPs == nil {
+		return defaultClonedIPs{}
+	}
+	return n.clonedIPs
+}
@@ -0,0 +1,28 @@
+package network
+
+import (
+	"context"
+	"net"
+)
+
+// Dial addr from the sandbox netns, so that the guest can be reached before
+// the veth and iptables are setup (e.g., when building the template).
+// The returned connection stays in the sandbox netns.
+func (n *SandboxNetwork) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	var (
+		conn   net.Conn
+		dialer net.Dialer
+	)
+	err := n.enterSandboxNs(func() (err error) {
+		conn, err = dialer.DialContext(ctx, network, addr)
+		return err
+	})
+	if err != nil {
+		if conn != nil {
+			// failed to set back to the host netns
+			conn.Close()
+		}
+		return nil, err
+	}
+	return conn, nil
+}
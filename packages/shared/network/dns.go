@@ -1,17 +1,39 @@
 package network
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+
 	"github.com/txn2/txeh"
+	"golang.org/x/sys/unix"
 )
 
+const etcHostsPath = "/etc/hosts"
+
 type DNS struct {
 	// This already hold a mutex
 	*txeh.Hosts
+	path string
+	// the mutex inside txeh.Hosts only protects a single
+	// operation, while we need to serialize the modification and saving
+	// (e.g., when creating sandboxes concurrently).
+	mu sync.Mutex
 }
 
 func NewDNS() (*DNS, error) {
-	hosts, err := txeh.NewHostsDefault()
+	return NewDNSWithPath(etcHostsPath)
+}
+
+// Manage the hosts file at path instead of /etc/hosts (e.g., in tests).
+func NewDNSWithPath(path string) (*DNS, error) {
+	hosts, err := txeh.NewHosts(&txeh.HostsConfig{
+		ReadFilePath:  path,
+		WriteFilePath: path,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error initializing etc hosts handler: %w", err)
 	}
@@ -22,15 +44,14 @@ func NewDNS() (*DNS, error) {
 	}
 
 	return &DNS{
-		hosts,
+		Hosts: hosts,
+		path:  path,
 	}, nil
 }
 
 // ip: for example 10.5.8.2
 func (d *DNS) Add(ip, sandboxID string) error {
-	d.AddHost(ip, sandboxID)
-
-	err := d.Save()
+	err := d.update(func() { d.AddHost(ip, sandboxID) })
 	if err != nil {
 		return fmt.Errorf("error adding sandbox to etc hosts: %w", err)
 	}
@@ -39,12 +60,78 @@ func (d *DNS) Add(ip, sandboxID string) error {
 }
 
 func (d *DNS) Remove(sandboxID string) error {
-	d.RemoveHost(sandboxID)
-
-	err := d.Save()
+	err := d.update(func() { d.RemoveHost(sandboxID) })
 	if err != nil {
 		return fmt.Errorf("error removing sandbox to etc hosts: %w", err)
 	}
 
 	return nil
 }
+
+func (d *DNS) RemoveAddress(ip string) error {
+	err := d.update(func() { d.Hosts.RemoveAddress(ip) })
+	if err != nil {
+		return fmt.Errorf("error removing address from etc hosts: %w", err)
+	}
+
+	return nil
+}
+
+// Apply modify to the hosts file atomically.
+//
+// The hosts file is also modified by other processes (e.g., orchestrator and
+// template manager), so the modification is serialized by a flock on a
+// separate lock file and the file is reloaded before modify. The new content
+// is written to a temp file and then renamed, so that the readers (e.g., the
+// resolver) never see a half-written file.
+func (d *DNS) update(modify func()) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lockFile, err := os.OpenFile(d.path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("open lock file failed: %w", err)
+	}
+	defer lockFile.Close()
+	if err := unix.Flock(int(lockFile.Fd()), unix.LOCK_EX); err != nil {
+		return fmt.Errorf("lock %s failed: %w", lockFile.Name(), err)
+	}
+	defer unix.Flock(int(lockFile.Fd()), unix.LOCK_UN)
+
+	if err := d.Reload(); err != nil {
+		return fmt.Errorf("reload failed: %w", err)
+	}
+	modify()
+	return d.save()
+}
+
+func (d *DNS) save() error {
+	content := []byte(d.RenderHostsFile())
+	tmp, err := os.CreateTemp(filepath.Dir(d.path), "."+filepath.Base(d.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("create temp file failed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := tmp.Write(content); err != nil {
+		return fmt.Errorf("write temp file failed: %w", err)
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		return fmt.Errorf("chmod temp file failed: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		return fmt.Errorf("sync temp file failed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file failed: %w", err)
+	}
+	err = os.Rename(tmp.Name(), d.path)
+	// the hosts file may be a mount point (e.g., bind mounted
+	// by docker), which cannot be replaced, so overwrite it in place (still
+	// serialized by the lock).
+	if errors.Is(err, syscall.EBUSY) {
+		err = os.WriteFile(d.path, content, 0o644)
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Check every line of hosts file is either empty, comment or "$IP $HOST...".
+func checkHostsFile(content string) error {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+			return fmt.Errorf("malformed line %q", line)
+		}
+	}
+	return nil
+}
+
+// The address of each host in hosts file, regardless of how the columns
+// are aligned (txeh pads the address).
+func parseHostsFile(content string) map[string]string {
+	hosts := make(map[string]string)
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, host := range fields[1:] {
+			if strings.HasPrefix(host, "#") {
+				break
+			}
+			hosts[host] = fields[0]
+		}
+	}
+	return hosts
+}
+
+func TestDNSConcurrentAddRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hosts")
+	if err := os.WriteFile(path, []byte("127.0.0.1 localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// two handlers on the same file, like orchestrator and template manager
+	var handlers []*DNS
+	for i := 0; i < 2; i++ {
+		dns, err := NewDNSWithPath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlers = append(handlers, dns)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		done    atomic.Bool
+		readErr atomic.Value
+	)
+	// the readers should never see a half-written file
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for !done.Load() {
+			content, err := os.ReadFile(path)
+			if err == nil {
+				err = checkHostsFile(string(content))
+			}
+			if err != nil {
+				readErr.Store(err)
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			dns := handlers[i%len(handlers)]
+			for j := 0; j < 50; j++ {
+				sandboxID := fmt.Sprintf("sandbox-%d-%d", i, j)
+				if err := dns.Add(fmt.Sprintf("10.0.%d.%d", i, j), sandboxID); err != nil {
+					t.Error(err)
+					return
+				}
+				if err := dns.Remove(sandboxID); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+			// left one entry to check no update is lost
+			if err := dns.Add(fmt.Sprintf("10.1.0.%d", i), fmt.Sprintf("sandbox-%d", i)); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	done.Store(true)
+	<-readerDone
+	if err, ok := readErr.Load().(error); ok {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkHostsFile(string(content)); err != nil {
+		t.Fatal(err)
+	}
+	hosts := parseHostsFile(string(content))
+	for i := 0; i < 16; i++ {
+		if ip := hosts[fmt.Sprintf("sandbox-%d", i)]; ip != fmt.Sprintf("10.1.0.%d", i) {
+			t.Errorf("entry of sandbox-%d is lost:\n%s", i, content)
+		}
+	}
+	if hosts["localhost"] != "127.0.0.1" || len(hosts) != 17 {
+		t.Errorf("unexpected hosts file:\n%s", content)
+	}
+}
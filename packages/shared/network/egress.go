@@ -0,0 +1,267 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+
+	"github.com/coreos/go-iptables/iptables"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+// The chain (of filter table in sandbox netns) holding the egress rules,
+// jumped from FORWARD for the packets sent by the guest.
+const egressChain = "SANDBOX-EGRESS"
+
+var ErrInvalidEgressPolicy = errors.New("invalid egress policy")
+
+type EgressRule struct {
+	// ip or cidr (ipv4 or ipv6) of destination
+	CIDR string
+	// "tcp" or "udp", empty means all protocols
+	Protocol string
+	// destination port, 0 means all ports (requires Protocol if not 0)
+	Port uint16
+}
+
+// The outbound destinations the guest can reach.
+//
+// The Deny rules are matched first, then if Allow is not empty, only the
+// destinations matched by Allow are reachable. Otherwise (i.e., no Allow
+// rules), all the destinations not denied are reachable.
+//
+// The host side of veth (e.g., log collector) and the DNS servers
+// (port 53) are always reachable.
+type EgressPolicy struct {
+	Allow []EgressRule
+	Deny  []EgressRule
+	// ip of the guest's dns servers
+	DNS []string
+}
+
+func (p *EgressPolicy) Validate() error {
+	for _, rules := range [][]EgressRule{p.Allow, p.Deny} {
+		for _, rule := range rules {
+			if _, err := parseCIDR(rule.CIDR); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidEgressPolicy, err)
+			}
+			switch rule.Protocol {
+			case "", "tcp", "udp":
+			default:
+				return fmt.Errorf("%w: unsupported protocol %q", ErrInvalidEgressPolicy, rule.Protocol)
+			}
+			if rule.Port != 0 && rule.Protocol == "" {
+				return fmt.Errorf("%w: port %d of %s requires protocol", ErrInvalidEgressPolicy, rule.Port, rule.CIDR)
+			}
+		}
+	}
+	for _, server := range p.DNS {
+		if net.ParseIP(server) == nil {
+			return fmt.Errorf("%w: invalid dns server %q", ErrInvalidEgressPolicy, server)
+		}
+	}
+	return nil
+}
+
+// Accept both ip and cidr.
+func parseCIDR(s string) (*net.IPNet, error) {
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		return ipNet, nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid cidr %q", s)
+	}
+	if ip.To4() != nil {
+		return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+func (rule *EgressRule) ruleSpec(target string) []string {
+	spec := []string{"-d", rule.CIDR}
+	if rule.Protocol != "" {
+		spec = append(spec, "-p", rule.Protocol)
+	}
+	if rule.Port != 0 {
+		spec = append(spec, "--dport", strconv.Itoa(int(rule.Port)))
+	}
+	return append(spec, "-j", target)
+}
+
+// Install the egress policy in sandbox netns, replacing the previous one (if any).
+// The policy should have been validated.
+//
+// Start at host ns
+// end at host ns
+func (n *SandboxNetwork) ApplyEgressPolicy(policy *EgressPolicy) error {
+	return n.inSandboxNs(func() error {
+		if err := n.deleteEgressRules(iptables.ProtocolIPv4); err != nil {
+			return err
+		}
+		if err := n.addEgressRules(iptables.ProtocolIPv4, policy); err != nil {
+			return err
+		}
+		if n.IPv6Enabled() {
+			if err := n.deleteEgressRules(iptables.ProtocolIPv6); err != nil {
+				return err
+			}
+			if err := n.addEgressRules(iptables.ProtocolIPv6, policy); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Remove the egress policy in sandbox netns, it is a no-op if there is
+// no policy (or the netns does not exist).
+//
+// Start at host ns
+// end at host ns
+func (n *SandboxNetwork) RemoveEgressPolicy() error {
+	ns, err := netns.GetFromName(n.NetNsName())
+	if errors.Is(err, unix.ENOENT) {
+		return nil
+	} else if err == nil {
+		ns.Close()
+	}
+	return n.inSandboxNs(func() error {
+		err := n.deleteEgressRules(iptables.ProtocolIPv4)
+		if n.IPv6Enabled() {
+			err = errors.Join(err, n.deleteEgressRules(iptables.ProtocolIPv6))
+		}
+		return err
+	})
+}
+
+func (n *SandboxNetwork) addEgressRules(proto iptables.Protocol, policy *EgressPolicy) error {
+	tables, err := iptables.NewWithProtocol(proto)
+	if err != nil {
+		return fmt.Errorf("error initializing iptables: %w", err)
+	}
+	if err := tables.NewChain("filter", egressChain); err != nil {
+		return fmt.Errorf("error creating egress chain: %w", err)
+	}
+
+	vethIP := n.VethIP().String()
+	if proto == iptables.ProtocolIPv6 {
+		vethIP = n.VethIPv6().String()
+	}
+	rules := policy.rules(vethIP, proto == iptables.ProtocolIPv6)
+	for _, rule := range rules {
+		if err := tables.Append("filter", egressChain, rule...); err != nil {
+			return fmt.Errorf("error adding egress rule %v: %w", rule, err)
+		}
+	}
+
+	err = tables.Insert("filter", "FORWARD", 1, "-i", n.TapName(), "-o", n.VpeerName(), "-j", egressChain)
+	if err != nil {
+		return fmt.Errorf("error adding forwarding rule to egress chain: %w", err)
+	}
+	return nil
+}
+
+// The rules (in order) of egress chain for ipv4 or ipv6 destinations.
+func (p *EgressPolicy) rules(vethIP string, ipv6 bool) [][]string {
+	rules := [][]string{{"-d", vethIP, "-j", "ACCEPT"}}
+	for _, server := range p.DNS {
+		dns := EgressRule{CIDR: server, Port: 53}
+		if dns.isIPv6() != ipv6 {
+			continue
+		}
+		for _, protocol := range []string{"udp", "tcp"} {
+			dns.Protocol = protocol
+			rules = append(rules, dns.ruleSpec("ACCEPT"))
+		}
+	}
+	for _, rule := range p.Deny {
+		if rule.isIPv6() == ipv6 {
+			rules = append(rules, rule.ruleSpec("REJECT"))
+		}
+	}
+	if len(p.Allow) > 0 {
+		for _, rule := range p.Allow {
+			if rule.isIPv6() == ipv6 {
+				rules = append(rules, rule.ruleSpec("ACCEPT"))
+			}
+		}
+		rules = append(rules, []string{"-j", "REJECT"})
+	}
+	return rules
+}
+
+func (n *SandboxNetwork) deleteEgressRules(proto iptables.Protocol) error {
+	tables, err := iptables.NewWithProtocol(proto)
+	if err != nil {
+		return fmt.Errorf("error initializing iptables: %w", err)
+	}
+	exists, err := tables.ChainExists("filter", egressChain)
+	if err != nil {
+		return fmt.Errorf("error checking egress chain: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+	err = tables.DeleteIfExists("filter", "FORWARD", "-i", n.TapName(), "-o", n.VpeerName(), "-j", egressChain)
+	if err != nil {
+		return fmt.Errorf("error deleting forwarding rule to egress chain: %w", err)
+	}
+	if err := tables.ClearAndDeleteChain("filter", egressChain); err != nil {
+		return fmt.Errorf("error deleting egress chain: %w", err)
+	}
+	return nil
+}
+
+func (rule *EgressRule) isIPv6() bool {
+	ipNet, err := parseCIDR(rule.CIDR)
+	return err == nil && ipNet.IP.To4() == nil
+}
+
+// Run fn in the sandbox netns, used to modify the network after it has
+// been configured (i.e., StartConfigure and EndConfigure).
+func (n *SandboxNetwork) inSandboxNs(fn func() error) error {
+	return n.enterSandboxNs(func() error {
+		lower, err := raiseAmbientCaps([]uintptr{unix.CAP_NET_ADMIN, unix.CAP_NET_RAW})
+		if err == nil {
+			err = fn()
+		}
+		for _, f := range lower {
+			err = errors.Join(err, f())
+		}
+		return err
+	})
+}
+
+// Same as inSandboxNs, but without raising the caps.
+func (n *SandboxNetwork) enterSandboxNs(fn func() error) error {
+	runtime.LockOSThread()
+	hostNS, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("cannot get current (host) namespace: %w", err)
+	}
+	defer hostNS.Close()
+	sbxNs, err := netns.GetFromName(n.NetNsName())
+	if err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("get netns by name error: %w", err)
+	}
+	defer sbxNs.Close()
+
+	if err := netns.Set(sbxNs); err != nil {
+		runtime.UnlockOSThread()
+		return fmt.Errorf("error setting to sandbox ns: %w", err)
+	}
+	err = fn()
+	if setErr := netns.Set(hostNS); setErr != nil {
+		// keep the thread locked, so that it is terminated
+		// (instead of reused) when the goroutine exits.
+		return errors.Join(err, fmt.Errorf("set back to host netns failed: %w", setErr))
+	}
+	runtime.UnlockOSThread()
+	return err
+}
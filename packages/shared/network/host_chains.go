@@ -0,0 +1,157 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/coreos/go-iptables/iptables"
+)
+
+// The default prefix of the chains holding the host rules of sandboxes
+// (see NetworkEnv.WithHostChainPrefix).
+const DefaultHostChainPrefix = "CI"
+
+// The longest name of chain accepted by iptables is 28, and the longest
+// suffix is "-POSTROUTING" (the "-PF-<idx>" of port forwards is not longer).
+const maxHostChainPrefixLen = 28 - len("-POSTROUTING")
+
+var (
+	ErrInvalidHostChainPrefix = errors.New("invalid iptables chain prefix")
+	hostChainPrefixRegex      = regexp.MustCompile(`^[A-Za-z0-9_]+(-[A-Za-z0-9_]+)*$`)
+)
+
+// A chain (in host netns) holding the host rules of sandboxes, which is
+// jumped from the built-in chain, so that the rules of sandboxes do not
+// mix up with the ones of host firewall (e.g., firewalld, kube-proxy).
+type hostChain struct {
+	table   string
+	builtin string
+	// jump at the head of the built-in chain, e.g., the DNAT of port forwards
+	// should take effect before the other rules of host.
+	first bool
+}
+
+var hostChains = []hostChain{
+	{table: "filter", builtin: "FORWARD"},
+	{table: "nat", builtin: "POSTROUTING"},
+	{table: "nat", builtin: "PREROUTING", first: true},
+	{table: "nat", builtin: "OUTPUT", first: true},
+}
+
+func (c hostChain) name(prefix string) string {
+	return prefix + "-" + c.builtin
+}
+
+func ValidateHostChainPrefix(prefix string) error {
+	if len(prefix) > maxHostChainPrefixLen || !hostChainPrefixRegex.MatchString(prefix) {
+		return fmt.Errorf("%w %q: should be at most %d letters, digits, '_' or '-'",
+			ErrInvalidHostChainPrefix, prefix, maxHostChainPrefixLen)
+	}
+	return nil
+}
+
+func hostChainProtocols(ipv6 bool) []iptables.Protocol {
+	if ipv6 {
+		return []iptables.Protocol{iptables.ProtocolIPv4, iptables.ProtocolIPv6}
+	}
+	return []iptables.Protocol{iptables.ProtocolIPv4}
+}
+
+// Create the chains holding the host rules of sandboxes and jump to them from
+// the built-in chains. The existing chains are kept (e.g., the ones of the
+// sandboxes reattached after orchestrator restarted).
+//
+// Start at host ns
+// end at host ns
+func SetupHostChains(prefix string, ipv6 bool) error {
+	for _, proto := range hostChainProtocols(ipv6) {
+		tables, err := iptables.NewWithProtocol(proto)
+		if err != nil {
+			return fmt.Errorf("error initializing iptables (%v): %w", proto, err)
+		}
+		for _, c := range hostChains {
+			chain := c.name(prefix)
+			exists, err := tables.ChainExists(c.table, chain)
+			if err != nil {
+				return fmt.Errorf("error checking chain %s: %w", chain, err)
+			}
+			if !exists {
+				if err := tables.NewChain(c.table, chain); err != nil {
+					return fmt.Errorf("error creating chain %s: %w", chain, err)
+				}
+			}
+			if c.first {
+				exists, err = tables.Exists(c.table, c.builtin, "-j", chain)
+				if err == nil && !exists {
+					err = tables.Insert(c.table, c.builtin, 1, "-j", chain)
+				}
+			} else {
+				err = tables.AppendUnique(c.table, c.builtin, "-j", chain)
+			}
+			if err != nil {
+				return fmt.Errorf("error adding %s rule to chain %s: %w", c.builtin, chain, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Remove the chains created by SetupHostChains (i.e., all of the host
+// rules of sandboxes), which should be called after the sandboxes are deleted.
+//
+// Start at host ns
+// end at host ns
+func DeleteHostChains(prefix string, ipv6 bool) (finalErr error) {
+	for _, proto := range hostChainProtocols(ipv6) {
+		tables, err := iptables.NewWithProtocol(proto)
+		if err != nil {
+			finalErr = errors.Join(finalErr, fmt.Errorf("error initializing iptables (%v): %w", proto, err))
+			continue
+		}
+		for _, c := range hostChains {
+			chain := c.name(prefix)
+			if err := tables.DeleteIfExists(c.table, c.builtin, "-j", chain); err != nil {
+				finalErr = errors.Join(finalErr, fmt.Errorf("error deleting %s rule to chain %s: %w", c.builtin, chain, err))
+				continue
+			}
+			exists, err := tables.ChainExists(c.table, chain)
+			if err == nil && exists {
+				err = tables.ClearAndDeleteChain(c.table, chain)
+			}
+			if err != nil {
+				finalErr = errors.Join(finalErr, fmt.Errorf("error deleting chain %s: %w", chain, err))
+			}
+		}
+	}
+	return finalErr
+}
+
+// Use the chains with prefix (e.g., "<prefix>-FORWARD") for the host rules of
+// sandboxes, so that multiple orchestrators on the same host do not clobber
+// each other. Empty means DefaultHostChainPrefix.
+func (n NetworkEnv) WithHostChainPrefix(prefix string) NetworkEnv {
+	n.chainPrefix = prefix
+	return n
+}
+
+func (n *NetworkEnv) hostChainPrefix() string {
+	if n.chainPrefix == "" {
+		return DefaultHostChainPrefix
+	}
+	return n.chainPrefix
+}
+
+func (n *NetworkEnv) hostChain(builtin string) string {
+	return hostChain{builtin: builtin}.name(n.hostChainPrefix())
+}
+
+// The chain (of filter table in host netns) holding the FORWARD rules of sandboxes.
+func (n *NetworkEnv) HostForwardChain() string {
+	return n.hostChain("FORWARD")
+}
+
+// The chain (of nat table in host netns) holding the POSTROUTING rules of sandboxes.
+func (n *NetworkEnv) HostPostroutingChain() string {
+	return n.hostChain("POSTROUTING")
+}
@@ -0,0 +1,60 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/vishvananda/netlink"
+)
+
+var ErrInvalidMAC = errors.New("invalid mac address")
+
+// The deterministic mac address of the guest interface derived from the
+// index of network. It is in the locally administered range, and differs
+// from consts.GuestMacAddress (i.e., the one of templates).
+func (n *NetworkEnv) GuestMAC() string {
+	return fmt.Sprintf("02:fd:%02x:%02x:%02x:%02x",
+		byte(n.idx>>24), byte(n.idx>>16), byte(n.idx>>8), byte(n.idx))
+}
+
+// The mac address of guest should be a (non-zero) unicast ethernet address.
+func ValidateMAC(mac string) error {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidMAC, err)
+	}
+	if len(hw) != 6 {
+		return fmt.Errorf("%w: %s is not an ethernet address", ErrInvalidMAC, mac)
+	}
+	if hw[0]&1 != 0 {
+		return fmt.Errorf("%w: %s is a multicast address", ErrInvalidMAC, mac)
+	}
+	if hw.String() == "00:00:00:00:00:00" {
+		return fmt.Errorf("%w: %s is all zero", ErrInvalidMAC, mac)
+	}
+	return nil
+}
+
+// Remove the neighbor (arp and ndp) entries on the tap device, e.g., after
+// the mac address of guest is changed, so that they are resolved again
+// instead of pointing to the stale one.
+func (n *SandboxNetwork) FlushTapNeigh() error {
+	return n.inSandboxNs(func() error {
+		tap, err := netlink.LinkByName(n.TapName())
+		if err != nil {
+			return fmt.Errorf("error finding tap device: %w", err)
+		}
+		neighs, err := netlink.NeighList(tap.Attrs().Index, netlink.FAMILY_ALL)
+		if err != nil {
+			return fmt.Errorf("error listing neighbors of tap device: %w", err)
+		}
+		var finalErr error
+		for _, neigh := range neighs {
+			if err := netlink.NeighDel(&neigh); err != nil {
+				finalErr = errors.Join(finalErr, fmt.Errorf("error deleting neighbor %s: %w", neigh.IP, err))
+			}
+		}
+		return finalErr
+	})
+}
@@ -0,0 +1,50 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestGuestMAC(t *testing.T) {
+	seen := make(map[string]int)
+	for _, idx := range []int{0, 1, 5, 255, 256, 15360} {
+		env := NetworkEnv{idx: idx}
+		mac := env.GuestMAC()
+		if err := ValidateMAC(mac); err != nil {
+			t.Fatalf("invalid guest mac of network %d: %s", idx, err)
+		}
+		if prev, ok := seen[mac]; ok {
+			t.Fatalf("network %d and %d have the same guest mac %s", prev, idx, mac)
+		}
+		if strings.EqualFold(mac, consts.GuestMacAddress) {
+			t.Fatalf("guest mac of network %d should differ from the one of templates", idx)
+		}
+		seen[mac] = idx
+	}
+}
+
+func TestValidateMAC(t *testing.T) {
+	cases := []struct {
+		mac   string
+		valid bool
+	}{
+		{"02:fd:00:00:00:01", true},
+		{"02:FC:00:00:00:05", true},
+		{"01:00:5e:00:00:01", false},
+		{"00:00:00:00:00:00", false},
+		{"02:fd:00:00:00:00:00:01", false},
+		{"not-a-mac", false},
+	}
+	for _, c := range cases {
+		err := ValidateMAC(c.mac)
+		if c.valid && err != nil {
+			t.Fatalf("expect %s valid, got %s", c.mac, err)
+		}
+		if !c.valid && !errors.Is(err, ErrInvalidMAC) {
+			t.Fatalf("expect %s invalid, got %v", c.mac, err)
+		}
+	}
+}
@@ -15,10 +15,49 @@ type NetworkEnv struct {
 	idx int
 	// Subnet of the veth and vpeer device
 	subnet *net.IPNet
+	// (optional) ipv6 subnet of the veth and vpeer device and the
+	// host cloned ipv6, nil means ipv6 is disabled.
+	subnet6 *net.IPNet
+	// (optional) mtu of the tap, veth and vpeer device, 0 means the default of kernel.
+	mtu int
+	// (optional) the owner of the tap device, so that the unprivileged vmm can
+	// open it, 0 means root.
+	tapUID int
+	tapGID int
+	// (optional) the prefix of the chains holding the host rules
+	// (see WithHostChainPrefix), empty means DefaultHostChainPrefix.
+	chainPrefix string
+	// (optional) how the HostClonedIP is derived from idx
+	// (see WithClonedIPs), nil means the default one.
+	clonedIPs ClonedIPStrategy
 }
 
 func NewNetworkEnv(idx int, subnet *net.IPNet) NetworkEnv {
-	return NetworkEnv{idx, subnet}
+	return NetworkEnv{idx: idx, subnet: subnet}
+}
+
+// Enable ipv6 for the network env, subnet6 should be a ULA subnet
+// with prefix length <= 64.
+func (n NetworkEnv) WithIPv6(subnet6 *net.IPNet) NetworkEnv {
+	n.subnet6 = subnet6
+	return n
+}
+
+// Set the mtu of the devices (see ValidateMTU), e.g., lower than 1500
+// when the host is on an overlay network.
+func (n NetworkEnv) WithMTU(mtu int) NetworkEnv {
+	n.mtu = mtu
+	return n
+}
+
+func (n *NetworkEnv) MTU() int {
+	return n.mtu
+}
+
+// Set the owner of the tap device, e.g., when the vmm runs as an unprivileged user.
+func (n NetworkEnv) WithTapOwner(uid, gid int) NetworkEnv {
+	n.tapUID, n.tapGID = uid, gid
+	return n
 }
 
 func (n *NetworkEnv) NetNsName() string {
@@ -138,12 +177,61 @@ func (n *NetworkEnv) GuestIP() string {
 //
 // We can take this HostClonedIP as the ip address of each VM from the view on host.
 func (n *NetworkEnv) HostClonedIP() string {
-	low := n.idx%254 + 1 // range from [1, 254]
-	high := n.idx / 254
 	// TODO: remove host cloned ip and use veth address directly?
-	return fmt.Sprintf("192.168.%d.%d", 168+high, low)
+	return n.clonedIPStrategy().ClonedIP(n.idx).String()
 }
 
 func (n *NetworkEnv) HostClonedCIDR() string {
 	return fmt.Sprintf("%s/%d", n.HostClonedIP(), 32)
 }
+
+func (n *NetworkEnv) IPv6Enabled() bool {
+	return n.subnet6 != nil
+}
+
+// add offset to the interface identifier (i.e., the lower 64 bits) of subnet6
+func (n *NetworkEnv) ipv6WithOffset(offset uint64) net.IP {
+	result := make(net.IP, net.IPv6len)
+	copy(result, n.subnet6.IP.To16())
+	low := binary.BigEndian.Uint64(result[8:])
+	binary.BigEndian.PutUint64(result[8:], low+offset)
+	return result
+}
+
+// The ipv6 address of veth device in host netns, only valid when ipv6 is enabled.
+// Each network env uses a /126 of subnet6.
+func (n *NetworkEnv) VethIPv6() net.IP {
+	return n.ipv6WithOffset(uint64(n.idx)*4 + 1)
+}
+
+// The ipv6 address of veth device in sandbox netns, only valid when ipv6 is enabled.
+func (n *NetworkEnv) VpeerIPv6() net.IP {
+	return n.ipv6WithOffset(uint64(n.idx)*4 + 2)
+}
+
+func (n *NetworkEnv) VethIPv6CIDR() string {
+	return fmt.Sprintf("%s/%d", n.VethIPv6(), consts.VethIPv6Mask)
+}
+
+func (n *NetworkEnv) VpeerIPv6CIDR() string {
+	return fmt.Sprintf("%s/%d", n.VpeerIPv6(), consts.VethIPv6Mask)
+}
+
+func (n *NetworkEnv) TapIPv6CIDR() string {
+	return fmt.Sprintf("%s/%d", consts.HostTapIPv6Address, consts.GuestNetIPv6Mask)
+}
+
+func (n *NetworkEnv) GuestIPv6() string {
+	return consts.GuestNetIPv6Addr
+}
+
+// Same as HostClonedIP but for ipv6, only valid when ipv6 is enabled.
+// They are allocated from the upper half of the interface identifier,
+// so will not conflict with the veth addresses.
+func (n *NetworkEnv) HostClonedIPv6() string {
+	return n.ipv6WithOffset(1<<32 + uint64(n.idx)).String()
+}
+
+func (n *NetworkEnv) HostClonedIPv6CIDR() string {
+	return fmt.Sprintf("%s/%d", n.HostClonedIPv6(), 128)
+}
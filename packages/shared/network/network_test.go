@@ -1,6 +1,7 @@
 package network
 
 import (
+	"errors"
 	"net"
 	"runtime/debug"
 	"slices"
@@ -61,3 +62,192 @@ func TestFcNetwork(t *testing.T) {
 		hostClonedIps[hIp] = struct{}{}
 	}
 }
+
+func TestEgressPolicyValidate(t *testing.T) {
+	valid := EgressPolicy{
+		Allow: []EgressRule{{CIDR: "8.8.8.8", Protocol: "udp", Port: 53}, {CIDR: "10.0.0.0/8"}},
+		Deny:  []EgressRule{{CIDR: "fd00::/8", Protocol: "tcp"}},
+	}
+	assert(t, valid.Validate() == nil)
+	assert(t, valid.Deny[0].isIPv6())
+	assert(t, !valid.Allow[0].isIPv6())
+
+	for _, rule := range []EgressRule{
+		{CIDR: "10.0.0.0/33"},
+		{CIDR: "8.8.8.8", Protocol: "icmp"},
+		{CIDR: "8.8.8.8", Port: 53},
+	} {
+		invalid := EgressPolicy{Deny: []EgressRule{rule}}
+		assert(t, errors.Is(invalid.Validate(), ErrInvalidEgressPolicy))
+	}
+	invalid := EgressPolicy{DNS: []string{"dns.google"}}
+	assert(t, errors.Is(invalid.Validate(), ErrInvalidEgressPolicy))
+}
+
+func TestEgressPolicyRules(t *testing.T) {
+	policy := EgressPolicy{
+		Allow: []EgressRule{{CIDR: "10.0.0.0/8"}, {CIDR: "fd00::/8", Protocol: "tcp", Port: 443}},
+		Deny:  []EgressRule{{CIDR: "10.1.0.0/16", Protocol: "tcp"}},
+		DNS:   []string{"1.1.1.1", "2606:4700:4700::1111"},
+	}
+	equal := func(a, b [][]string) bool {
+		return slices.EqualFunc(a, b, func(x, y []string) bool { return slices.Equal(x, y) })
+	}
+
+	expected := [][]string{
+		{"-d", "10.0.0.1", "-j", "ACCEPT"},
+		{"-d", "1.1.1.1", "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
+		{"-d", "1.1.1.1", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"},
+		{"-d", "10.1.0.0/16", "-p", "tcp", "-j", "REJECT"},
+		{"-d", "10.0.0.0/8", "-j", "ACCEPT"},
+		{"-j", "REJECT"},
+	}
+	rules := policy.rules("10.0.0.1", false)
+	assert(t, equal(rules, expected))
+
+	expected = [][]string{
+		{"-d", "fd00::1", "-j", "ACCEPT"},
+		{"-d", "2606:4700:4700::1111", "-p", "udp", "--dport", "53", "-j", "ACCEPT"},
+		{"-d", "2606:4700:4700::1111", "-p", "tcp", "--dport", "53", "-j", "ACCEPT"},
+		{"-d", "fd00::/8", "-p", "tcp", "--dport", "443", "-j", "ACCEPT"},
+		{"-j", "REJECT"},
+	}
+	rules = policy.rules("fd00::1", true)
+	assert(t, equal(rules, expected))
+
+	// without allow rules, the not denied destinations are reachable
+	policy.Allow = nil
+	rules = policy.rules("10.0.0.1", false)
+	assert(t, len(rules) == 4)
+	assert(t, slices.Equal(rules[3], []string{"-d", "10.1.0.0/16", "-p", "tcp", "-j", "REJECT"}))
+}
+
+func TestValidatePortForwards(t *testing.T) {
+	valid := []PortForward{
+		{HostPort: 8080, GuestPort: 80},
+		{HostPort: 8080, GuestPort: 53, Protocol: "udp"},
+		// allocated later
+		{GuestPort: 22},
+		{GuestPort: 23},
+	}
+	assert(t, ValidatePortForwards(valid) == nil)
+
+	for _, forwards := range [][]PortForward{
+		{{HostPort: 8080}},
+		{{HostPort: 8080, GuestPort: 80, Protocol: "sctp"}},
+		{{HostPort: 8080, GuestPort: 80}, {HostPort: 8080, GuestPort: 81, Protocol: "tcp"}},
+	} {
+		assert(t, errors.Is(ValidatePortForwards(forwards), ErrInvalidPortForward))
+	}
+}
+
+func TestPortForwardRules(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.140.0.0/16")
+	n := NewSandboxNetwork(NewNetworkEnv(3, subnet), "")
+	rules := n.portForwardRules([]PortForward{
+		{HostPort: 30000, GuestPort: 80},
+		{HostPort: 30001, GuestPort: 53, Protocol: "udp"},
+	})
+	expected := [][]string{
+		{"-p", "tcp", "--dport", "30000", "-j", "DNAT", "--to-destination", n.HostClonedIP() + ":80"},
+		{"-p", "udp", "--dport", "30001", "-j", "DNAT", "--to-destination", n.HostClonedIP() + ":53"},
+	}
+	assert(t, slices.EqualFunc(rules, expected, func(x, y []string) bool { return slices.Equal(x, y) }))
+	assert(t, n.portForwardChain() == DefaultHostChainPrefix+"-PF-3")
+
+	// not collided with the one of another orchestrator
+	other := NewSandboxNetwork(NewNetworkEnv(3, subnet).WithHostChainPrefix("CI2"), "")
+	assert(t, other.portForwardChain() == "CI2-PF-3")
+}
+
+func TestIPv6NetworkEnv(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.140.0.0/16")
+	_, subnet6, _ := net.ParseCIDR("fd00:1::/64")
+
+	disabled := NewNetworkEnv(1, subnet)
+	assert(t, !disabled.IPv6Enabled())
+
+	addrs := make(map[string]struct{})
+	for i := 0; i < 5000; i++ {
+		v4 := NewNetworkEnv(i, subnet)
+		n := v4.WithIPv6(subnet6)
+		next := NewNetworkEnv(i+1, subnet).WithIPv6(subnet6)
+		assert(t, n.IPv6Enabled())
+		// the ipv4 addresses are not changed
+		assert(t, n.HostClonedIP() == v4.HostClonedIP())
+
+		veth, vethNet, err := net.ParseCIDR(n.VethIPv6CIDR())
+		assert(t, err == nil)
+		assert(t, veth.Equal(n.VethIPv6()))
+		// veth and vpeer are in the same /126, but not the others
+		assert(t, vethNet.Contains(n.VpeerIPv6()))
+		assert(t, !vethNet.Contains(next.VethIPv6()))
+
+		hostCloned, _, err := net.ParseCIDR(n.HostClonedIPv6CIDR())
+		assert(t, err == nil)
+		assert(t, hostCloned.String() == n.HostClonedIPv6())
+
+		for _, ip := range []net.IP{n.VethIPv6(), n.VpeerIPv6(), hostCloned} {
+			assert(t, subnet6.Contains(ip))
+			_, ok := addrs[ip.String()]
+			assert(t, !ok)
+			addrs[ip.String()] = struct{}{}
+		}
+	}
+}
+
+func TestHostChains(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.140.0.0/16")
+	env := NewNetworkEnv(1, subnet)
+	assert(t, env.HostForwardChain() == "CI-FORWARD")
+	assert(t, env.HostPostroutingChain() == "CI-POSTROUTING")
+	env = env.WithHostChainPrefix("SBX-1")
+	assert(t, env.HostForwardChain() == "SBX-1-FORWARD")
+	assert(t, env.hostChain("OUTPUT") == "SBX-1-OUTPUT")
+
+	for _, prefix := range []string{"CI", "sbx_backend-2"} {
+		assert(t, ValidateHostChainPrefix(prefix) == nil)
+	}
+	for _, prefix := range []string{"", "a b", "-CI", "CI-", "A-VERY-LONG-CHAIN-PREFIX"} {
+		assert(t, errors.Is(ValidateHostChainPrefix(prefix), ErrInvalidHostChainPrefix))
+	}
+}
+
+func TestClonedIPStrategy(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.140.0.0/16")
+	env := NewNetworkEnv(1, subnet)
+	assert(t, env.HostClonedIP() == "192.168.168.2")
+	next := NewNetworkEnv(254, subnet)
+	assert(t, next.HostClonedIP() == "192.168.169.1")
+	assert(t, ValidateClonedIPStrategy(defaultClonedIPs{}, 256*60, subnet) == nil)
+	assert(t, defaultClonedIPs{}.ClonedIP(defaultClonedIPs{}.MaxIdx()).String() == "192.168.255.254")
+
+	_, pool, _ := net.ParseCIDR("100.64.0.0/15")
+	strategy, err := NewSubnetClonedIPs(pool)
+	assert(t, err == nil)
+	assert(t, strategy.MaxIdx() == 1<<17-2)
+	assert(t, ValidateClonedIPStrategy(strategy, 100000, subnet) == nil)
+	env = env.WithClonedIPs(strategy)
+	assert(t, env.HostClonedIP() == "100.64.0.1")
+	assert(t, env.HostClonedCIDR() == "100.64.0.1/32")
+	next = NewNetworkEnv(70000, subnet).WithClonedIPs(strategy)
+	assert(t, next.HostClonedIP() == "100.65.17.112")
+	assert(t, strategy.ClonedIP(strategy.MaxIdx()).String() == "100.65.255.254")
+
+	// too small for the networks
+	_, small, _ := net.ParseCIDR("100.64.0.0/24")
+	strategy, err = NewSubnetClonedIPs(small)
+	assert(t, err == nil)
+	assert(t, errors.Is(ValidateClonedIPStrategy(strategy, 256*60, subnet), ErrInvalidClonedIPPool))
+	// overlaps with the veth subnet
+	_, overlap, _ := net.ParseCIDR("10.140.0.0/15")
+	strategy, err = NewSubnetClonedIPs(overlap)
+	assert(t, err == nil)
+	assert(t, errors.Is(ValidateClonedIPStrategy(strategy, 256*60, subnet), ErrInvalidClonedIPPool))
+
+	for _, cidr := range []string{"fd00::/64", "100.64.0.0/31", "169.254.0.0/16"} {
+		_, invalid, _ := net.ParseCIDR(cidr)
+		_, err := NewSubnetClonedIPs(invalid)
+		assert(t, errors.Is(err, ErrInvalidClonedIPPool))
+	}
+}
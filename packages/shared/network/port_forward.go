@@ -0,0 +1,143 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/coreos/go-iptables/iptables"
+)
+
+var ErrInvalidPortForward = errors.New("invalid port forward")
+
+// Forward a port of host to a port of guest, e.g., to reach a service in
+// guest from outside the host.
+type PortForward struct {
+	// 0 means to be allocated (see NetworkManager of orchestrator)
+	HostPort  uint16 `json:"hostPort"`
+	GuestPort uint16 `json:"guestPort"`
+	// "tcp" or "udp", empty means "tcp"
+	Protocol string `json:"protocol,omitempty"`
+}
+
+func (f *PortForward) Proto() string {
+	if f.Protocol == "" {
+		return "tcp"
+	}
+	return f.Protocol
+}
+
+func (f PortForward) String() string {
+	return fmt.Sprintf("%d->%d/%s", f.HostPort, f.GuestPort, f.Proto())
+}
+
+// Check the port forwards of a sandbox, the host ports (if not 0) should
+// not be duplicated. Whether they are used by other sandboxes is checked
+// when allocating.
+func ValidatePortForwards(forwards []PortForward) error {
+	seen := make(map[PortForward]struct{}, len(forwards))
+	for _, f := range forwards {
+		switch f.Protocol {
+		case "", "tcp", "udp":
+		default:
+			return fmt.Errorf("%w: unsupported protocol %q", ErrInvalidPortForward, f.Protocol)
+		}
+		if f.GuestPort == 0 {
+			return fmt.Errorf("%w: guest port of %s cannot be 0", ErrInvalidPortForward, f)
+		}
+		if f.HostPort == 0 {
+			continue
+		}
+		key := PortForward{HostPort: f.HostPort, Protocol: f.Proto()}
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("%w: duplicated host port %d/%s", ErrInvalidPortForward, f.HostPort, f.Proto())
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// The chain (of nat table in host netns) holding the port forwards of
+// the network, jumped from the PREROUTING and OUTPUT chains of host rules
+// (see SetupHostChains) for the packets sent to the addresses of host.
+// It is named after the host chain prefix, as the network indexes of
+// orchestrators on the same host are overlapped.
+func (n *SandboxNetwork) portForwardChain() string {
+	return n.hostChainPrefix() + "-PF-" + strconv.Itoa(n.NetworkIdx())
+}
+
+// The rules of port forward chain, the packets are routed to the
+// host cloned ip through veth (see SetupIptablesAndRoute).
+func (n *SandboxNetwork) portForwardRules(forwards []PortForward) [][]string {
+	rules := make([][]string, 0, len(forwards))
+	for _, f := range forwards {
+		rules = append(rules, []string{
+			"-p", f.Proto(), "--dport", strconv.Itoa(int(f.HostPort)),
+			"-j", "DNAT", "--to-destination", n.HostClonedIP() + ":" + strconv.Itoa(int(f.GuestPort)),
+		})
+	}
+	return rules
+}
+
+func portForwardJump(chain string) []string {
+	return []string{"-m", "addrtype", "--dst-type", "LOCAL", "-j", chain}
+}
+
+// Install the port forwards in host netns, replacing the previous ones (if
+// any). The host ports should have been allocated.
+//
+// Start at host ns
+// end at host ns
+func (n *SandboxNetwork) ApplyPortForwards(forwards []PortForward) error {
+	if err := n.RemovePortForwards(); err != nil {
+		return err
+	}
+	tables, err := iptables.New()
+	if err != nil {
+		return fmt.Errorf("error initializing iptables: %w", err)
+	}
+	chain := n.portForwardChain()
+	if err := tables.NewChain("nat", chain); err != nil {
+		return fmt.Errorf("error creating port forward chain: %w", err)
+	}
+	for _, rule := range n.portForwardRules(forwards) {
+		if err := tables.Append("nat", chain, rule...); err != nil {
+			return fmt.Errorf("error adding port forward rule %v: %w", rule, err)
+		}
+	}
+	// OUTPUT for the connections from host itself
+	for _, hook := range []string{"PREROUTING", "OUTPUT"} {
+		if err := tables.Append("nat", n.hostChain(hook), portForwardJump(chain)...); err != nil {
+			return fmt.Errorf("error adding %s rule to port forward chain: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// Remove the port forwards in host netns, it is a no-op if there is none.
+//
+// Start at host ns
+// end at host ns
+func (n *SandboxNetwork) RemovePortForwards() error {
+	tables, err := iptables.New()
+	if err != nil {
+		return fmt.Errorf("error initializing iptables: %w", err)
+	}
+	chain := n.portForwardChain()
+	exists, err := tables.ChainExists("nat", chain)
+	if err != nil {
+		return fmt.Errorf("error checking port forward chain: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+	for _, hook := range []string{"PREROUTING", "OUTPUT"} {
+		if err := tables.DeleteIfExists("nat", n.hostChain(hook), portForwardJump(chain)...); err != nil {
+			return fmt.Errorf("error deleting %s rule to port forward chain: %w", hook, err)
+		}
+	}
+	if err := tables.ClearAndDeleteChain("nat", chain); err != nil {
+		return fmt.Errorf("error deleting port forward chain: %w", err)
+	}
+	return nil
+}
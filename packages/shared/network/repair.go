@@ -0,0 +1,237 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/coreos/go-iptables/iptables"
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"golang.org/x/sys/unix"
+)
+
+var (
+	ErrNoNetnsProcess = errors.New("no process found in the netns of sandbox")
+	// the tap is owned by the vmm, so it cannot be recreated without restarting the vm
+	ErrTapGone = errors.New("tap device of sandbox is gone")
+)
+
+// Find the process (among pid and its descendants) in a netns other than
+// the one of pid, e.g., the vmm started through `ip netns exec`, which is
+// still found after the name of its netns has been deleted.
+func NetnsPidOfTree(pid int) (int, error) {
+	root, err := os.Stat(filepath.Join(procRoot, strconv.Itoa(pid), "ns", "net"))
+	if err != nil {
+		return 0, fmt.Errorf("error getting netns of pid %d: %w", pid, err)
+	}
+	queue := []int{pid}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		// the process might have exited
+		info, err := os.Stat(filepath.Join(procRoot, strconv.Itoa(p), "ns", "net"))
+		if err != nil {
+			continue
+		}
+		if !os.SameFile(root, info) {
+			return p, nil
+		}
+		queue = append(queue, processChildren(p)...)
+	}
+	return 0, fmt.Errorf("%w: under pid %d", ErrNoNetnsProcess, pid)
+}
+
+// The children of all threads of pid (needs CONFIG_PROC_CHILDREN).
+func processChildren(pid int) []int {
+	files, _ := filepath.Glob(filepath.Join(procRoot, strconv.Itoa(pid), "task", "*", "children"))
+	var children []int
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for _, field := range strings.Fields(string(b)) {
+			if child, err := strconv.Atoi(field); err == nil {
+				children = append(children, child)
+			}
+		}
+	}
+	return children
+}
+
+// Bind the name of netns to the netns of pid, like `ip netns attach`.
+func attachNetns(name string, pid int) error {
+	if err := os.MkdirAll(netnsDir, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(netnsDir, name)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL, 0o444)
+	if err != nil {
+		return err
+	}
+	f.Close()
+	nsPath := filepath.Join(procRoot, strconv.Itoa(pid), "ns", "net")
+	if err := unix.Mount(nsPath, path, "bind", unix.MS_BIND, ""); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
+// Rebuild the network of a running vm, e.g., after its netns (i.e., the
+// name) or veth has been deleted externally. The netns of the vm (the one
+// pid is in, see NetnsPidOfTree) is kept, as the tap opened by the vmm
+// cannot be replaced. The name of netns is bound to it again, and the
+// others (i.e., the veth pair, routes and iptables rules of network) are
+// torn down and set up again. The egress policy and port forwards are kept,
+// as their rules only refer to the names of devices and addresses.
+//
+// Start at host ns
+// end at host ns
+func (n *SandboxNetwork) Repair(pid int) (finalErr error) {
+	// the cleanup of the network is not changed, since the
+	// resources recreated have the same names
+	r := NewSandboxNetwork(n.NetworkEnv, n.SandboxID)
+	runtime.LockOSThread()
+	r.end = append(r.end, func() error {
+		runtime.UnlockOSThread()
+		return nil
+	})
+	defer func() {
+		finalErr = errors.Join(finalErr, r.EndConfigure())
+	}()
+
+	hostNS, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("cannot get current (host) namespace: %w", err)
+	}
+	r.hostNS = hostNS
+	r.end = append(r.end, func() error {
+		defer r.hostNS.Close()
+		if err := netns.Set(r.hostNS); err != nil {
+			return fmt.Errorf("set back to host netns failed: %w", err)
+		}
+		return nil
+	})
+	sbxNs, err := netns.GetFromPid(pid)
+	if err != nil {
+		return fmt.Errorf("cannot get netns of pid %d: %w", pid, err)
+	}
+	r.sbxNs = sbxNs
+	r.end = append(r.end, r.sbxNs.Close)
+
+	if err := r.reattachNetns(pid); err != nil {
+		return err
+	}
+
+	if err := r.SetSandboxNs(); err != nil {
+		return fmt.Errorf("error setting to sandbox ns: %w", err)
+	}
+	if _, err := netlink.LinkByName(r.TapName()); err != nil {
+		return fmt.Errorf("%w: %w", ErrTapGone, err)
+	}
+	if err := r.raiseAmbientCaps([]uintptr{unix.CAP_NET_ADMIN, unix.CAP_NET_RAW}); err != nil {
+		return err
+	}
+	// the host veth (and the routes through it) is deleted along with vpeer
+	if vpeer, err := netlink.LinkByName(r.VpeerName()); err == nil {
+		if err := netlink.LinkDel(vpeer); err != nil {
+			return fmt.Errorf("error deleting vpeer device: %w", err)
+		}
+	}
+	if err := r.deleteSbxIptables(); err != nil {
+		return err
+	}
+
+	if err := r.SetHostNs(); err != nil {
+		return fmt.Errorf("error setting to host ns: %w", err)
+	}
+	if veth, err := netlink.LinkByName(r.VethName()); err == nil {
+		if err := netlink.LinkDel(veth); err != nil {
+			return fmt.Errorf("error deleting veth device: %w", err)
+		}
+	}
+	// the rules and routes might have been partially deleted
+	_ = r.DeleteHostRoute()
+	_ = r.DeleteHostIptables()
+
+	if err := r.SetSandboxNs(); err != nil {
+		return fmt.Errorf("error setting to sandbox ns: %w", err)
+	}
+	if err := r.SetupSbxLoDev(); err != nil {
+		return err
+	}
+	if err := r.SetupVethPair(); err != nil {
+		return err
+	}
+	if err := r.SetSandboxNs(); err != nil {
+		return fmt.Errorf("error setting to sandbox ns: %w", err)
+	}
+	return r.SetupIptablesAndRoute()
+}
+
+// Make the name of netns refer to the netns of pid.
+func (n *SandboxNetwork) reattachNetns(pid int) error {
+	named, err := netns.GetFromName(n.NetNsName())
+	switch {
+	case err == nil:
+		same := named.Equal(n.sbxNs)
+		named.Close()
+		if same {
+			return nil
+		}
+		// e.g., created by others after ours was deleted
+		if err := netns.DeleteNamed(n.NetNsName()); err != nil {
+			return fmt.Errorf("error deleting netns %s: %w", n.NetNsName(), err)
+		}
+	case !errors.Is(err, syscall.ENOENT):
+		return fmt.Errorf("get netns by name error: %w", err)
+	}
+	if err := attachNetns(n.NetNsName(), pid); err != nil {
+		return fmt.Errorf("error attaching netns %s to pid %d: %w", n.NetNsName(), pid, err)
+	}
+	return nil
+}
+
+// Delete the nat rules in sandbox ns added by SetupIptablesAndRoute.
+//
+// Start at sandbox ns
+// end at sandbox ns
+func (n *SandboxNetwork) deleteSbxIptables() error {
+	tables, err := iptables.New()
+	if err != nil {
+		return fmt.Errorf("error initializing iptables in guest netns: %w", err)
+	}
+	err = errors.Join(
+		tables.DeleteIfExists("nat", "POSTROUTING", "-o", n.VpeerName(),
+			"-s", n.GuestIP(), "-j", "SNAT", "--to-source", n.HostClonedIP()),
+		tables.DeleteIfExists("nat", "PREROUTING", "-i", n.VpeerName(),
+			"-d", n.HostClonedIP(), "-j", "DNAT", "--to-destination", n.GuestIP()),
+	)
+	if err != nil {
+		return fmt.Errorf("error deleting nat rules in guest netns: %w", err)
+	}
+	if !n.IPv6Enabled() {
+		return nil
+	}
+	tables, err = iptables.NewWithProtocol(iptables.ProtocolIPv6)
+	if err != nil {
+		return fmt.Errorf("error initializing ip6tables in guest netns: %w", err)
+	}
+	err = errors.Join(
+		tables.DeleteIfExists("nat", "POSTROUTING", "-o", n.VpeerName(),
+			"-s", n.GuestIPv6(), "-j", "SNAT", "--to-source", n.HostClonedIPv6()),
+		tables.DeleteIfExists("nat", "PREROUTING", "-i", n.VpeerName(),
+			"-d", n.HostClonedIPv6(), "-j", "DNAT", "--to-destination", n.GuestIPv6()),
+	)
+	if err != nil {
+		return fmt.Errorf("error deleting ipv6 nat rules in guest netns: %w", err)
+	}
+	return nil
+}
@@ -5,9 +5,14 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"os"
+	"path/filepath"
 	"runtime"
 	"slices"
+	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 	"github.com/coreos/go-iptables/iptables"
@@ -17,7 +22,36 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-var hostDefaultGateway = Must(getDefaultGateway())
+// Returned when the netns to be created already exists,
+// usually left by a crashed process.
+var ErrNetnsExists = errors.New("netns already exists")
+
+// Returned when reclaiming a network whose netns still contains live processes
+// (e.g., the vmm of an orphan sandbox).
+var ErrNetnsInUse = errors.New("netns is still in use")
+
+var ErrInvalidMTU = errors.New("invalid mtu")
+
+const (
+	MinMTU = 576
+	MaxMTU = 65535
+)
+
+// The interval of checking the processes in netns, see [SandboxNetwork.WaitUnused].
+const netnsPollInterval = 50 * time.Millisecond
+
+var (
+	// where the named netns are mounted (same as `ip netns`)
+	netnsDir = "/var/run/netns"
+	procRoot = "/proc"
+)
+
+var hostDefaultGateway = Must(getDefaultGateway(netlink.FAMILY_ALL))
+
+// The interface of host default ipv6 route, only needed when ipv6 is enabled.
+var hostDefaultGatewayV6 = sync.OnceValues(func() (string, error) {
+	return getDefaultGateway(netlink.FAMILY_V6)
+})
 
 func Must[T any](obj T, err error) T {
 	if err != nil {
@@ -27,8 +61,8 @@ func Must[T any](obj T, err error) T {
 	return obj
 }
 
-func getDefaultGateway() (string, error) {
-	routes, err := netlink.RouteList(nil, netlink.FAMILY_ALL)
+func getDefaultGateway(family int) (string, error) {
+	routes, err := netlink.RouteList(nil, family)
 	if err != nil {
 		return "", fmt.Errorf("error fetching routes: %w", err)
 	}
@@ -48,6 +82,23 @@ func getDefaultGateway() (string, error) {
 	return "", fmt.Errorf("cannot find default gateway")
 }
 
+// The mtu of the host default gateway interface, used as the mtu of
+// the sandbox devices when it is not configured.
+func HostDefaultMTU() (int, error) {
+	link, err := netlink.LinkByName(hostDefaultGateway)
+	if err != nil {
+		return 0, fmt.Errorf("error fetching default gateway %s: %w", hostDefaultGateway, err)
+	}
+	return link.Attrs().MTU, nil
+}
+
+func ValidateMTU(mtu int) error {
+	if mtu < MinMTU || mtu > MaxMTU {
+		return fmt.Errorf("%w: %d is not in [%d, %d]", ErrInvalidMTU, mtu, MinMTU, MaxMTU)
+	}
+	return nil
+}
+
 // WARNING: Please lock the os thread when using network env
 // runtime.LockOSThread()
 // defer runtime.UnlockOSThread()
@@ -75,6 +126,20 @@ func NewSandboxNetwork(env NetworkEnv, sandboxID string) SandboxNetwork {
 	}
 }
 
+// Used for the network which has already been setup (e.g., by previous orchestrator).
+// Calling Cleanup() on it will delete the netns, veth, iptables rules and route.
+func NewExistingSandboxNetwork(env NetworkEnv, sandboxID string) SandboxNetwork {
+	n := NewSandboxNetwork(env, sandboxID)
+	n.cleanup = append(n.cleanup,
+		n.DeleteNetns,
+		n.DeleteHostVethDev,
+		n.DeleteHostIptables,
+		n.DeleteHostRoute,
+		n.RemovePortForwards,
+	)
+	return n
+}
+
 func (n *SandboxNetwork) SetSandboxNs() error {
 	return netns.Set(n.sbxNs)
 }
@@ -91,7 +156,7 @@ func (n *SandboxNetwork) StartConfigure() error {
 	ns, err := netns.GetFromName(n.NetNsName())
 	if err == nil {
 		ns.Close()
-		return fmt.Errorf("netns for %d already exists", n.idx)
+		return fmt.Errorf("%w: %s", ErrNetnsExists, n.NetNsName())
 	} else if !errors.Is(err, syscall.ENOENT) {
 		return fmt.Errorf("get netns by name error: %w", err)
 	}
@@ -151,12 +216,18 @@ func (n *SandboxNetwork) SetupSbxTapDev() error {
 	tap := &netlink.Tuntap{
 		Mode:      netlink.TUNTAP_MODE_TAP,
 		LinkAttrs: tapAttrs,
+		Owner:     uint32(n.tapUID),
+		Group:     uint32(n.tapGID),
 	}
 	err := netlink.LinkAdd(tap)
 	if err != nil {
 		return fmt.Errorf("error creating tap device: %w", err)
 	}
 
+	if err := n.setMTU(tap); err != nil {
+		return fmt.Errorf("error setting mtu of tap device: %w", err)
+	}
+
 	err = netlink.LinkSetUp(tap)
 	if err != nil {
 		return fmt.Errorf("error setting tap device up: %w", err)
@@ -178,9 +249,38 @@ func (n *SandboxNetwork) SetupSbxTapDev() error {
 		return fmt.Errorf("error setting address of the tap device: %w", err)
 	}
 
+	if n.IPv6Enabled() {
+		if err := addIPv6Addr(tap, n.TapIPv6CIDR()); err != nil {
+			return fmt.Errorf("error setting ipv6 address of the tap device: %w", err)
+		}
+	}
+
 	return nil
 }
 
+// Set the mtu of link (in current netns) if configured.
+func (n *SandboxNetwork) setMTU(link netlink.Link) error {
+	if n.MTU() == 0 {
+		return nil
+	}
+	return netlink.LinkSetMTU(link, n.MTU())
+}
+
+// NODAD makes the address usable immediately (i.e., skip duplicate address detection).
+func addIPv6Addr(link netlink.Link, cidr string) error {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return fmt.Errorf("error parsing CIDR %s: %w", cidr, err)
+	}
+	return netlink.AddrAdd(link, &netlink.Addr{
+		IPNet: &net.IPNet{
+			IP:   ip,
+			Mask: ipNet.Mask,
+		},
+		Flags: unix.IFA_F_NODAD,
+	})
+}
+
 // start at sandbox ns
 // end at sandbox ns
 func (n *SandboxNetwork) SetupSbxLoDev() error {
@@ -223,6 +323,10 @@ func (n *SandboxNetwork) SetupVethPair() error {
 		return fmt.Errorf("error finding vpeer %s: %w", n.VpeerName(), err)
 	}
 
+	if err := n.setMTU(vpeer); err != nil {
+		return fmt.Errorf("error setting mtu of vpeer device: %w", err)
+	}
+
 	err = netlink.LinkSetUp(vpeer)
 	if err != nil {
 		return fmt.Errorf("error setting vpeer device up: %w", err)
@@ -241,6 +345,11 @@ func (n *SandboxNetwork) SetupVethPair() error {
 	if err != nil {
 		return fmt.Errorf("error adding vpeer device address: %w", err)
 	}
+	if n.IPv6Enabled() {
+		if err := addIPv6Addr(vpeer, n.VpeerIPv6CIDR()); err != nil {
+			return fmt.Errorf("error adding vpeer device ipv6 address: %w", err)
+		}
+	}
 
 	// Start configure veth (in the host ns)
 	err = n.SetHostNs()
@@ -248,6 +357,10 @@ func (n *SandboxNetwork) SetupVethPair() error {
 		return fmt.Errorf("error setting to host ns: %w", err)
 	}
 
+	if err := n.setMTU(veth); err != nil {
+		return fmt.Errorf("error setting mtu of veth device: %w", err)
+	}
+
 	err = netlink.LinkSetUp(veth)
 	if err != nil {
 		return fmt.Errorf("error setting veth device up: %w", err)
@@ -267,6 +380,11 @@ func (n *SandboxNetwork) SetupVethPair() error {
 	if err != nil {
 		return fmt.Errorf("error adding veth device address: %w", err)
 	}
+	if n.IPv6Enabled() {
+		if err := addIPv6Addr(veth, n.VethIPv6CIDR()); err != nil {
+			return fmt.Errorf("error adding veth device ipv6 address: %w", err)
+		}
+	}
 
 	return nil
 }
@@ -295,6 +413,9 @@ func (n *SandboxNetwork) SetupIptablesAndRoute() error {
 	}
 
 	n.cleanup = append(n.cleanup, n.DeleteHostIptables)
+	// the egress policy and port forwards are applied per sandbox
+	// (see ApplyEgressPolicy and ApplyPortForwards)
+	n.cleanup = append(n.cleanup, n.RemoveEgressPolicy, n.RemovePortForwards)
 
 	// Add NAT routing rules to sandbox netns: the high-level guideline can
 	// be found in firecracker doc: network-for-clones.md
@@ -317,6 +438,12 @@ func (n *SandboxNetwork) SetupIptablesAndRoute() error {
 		return fmt.Errorf("error creating postrouting rule for packet targeting guest: %w", err)
 	}
 
+	if n.IPv6Enabled() {
+		if err := n.setupSbxIPv6(); err != nil {
+			return err
+		}
+	}
+
 	// Go back to host network namespace
 	err = n.SetHostNs()
 	if err != nil {
@@ -339,34 +466,133 @@ func (n *SandboxNetwork) SetupIptablesAndRoute() error {
 	}
 
 	// 4. (HostNS) Need add FORWARD entries in iptables, to allow packet from veth to outside and
-	//             from outside to veth (routed through host to guest, or from guest)
-	err = tables.Append("filter", "FORWARD", "-i", n.VethName(), "-o", hostDefaultGateway, "-j", "ACCEPT")
+	//             from outside to veth (routed through host to guest, or from guest).
+	//             The host rules are added to the chains created by SetupHostChains.
+	err = tables.Append("filter", n.HostForwardChain(), "-i", n.VethName(), "-o", hostDefaultGateway, "-j", "ACCEPT")
 	if err != nil {
 		return fmt.Errorf("error creating forwarding rule to packet leaving host default gateway: %w", err)
 	}
 
-	err = tables.Append("filter", "FORWARD", "-i", hostDefaultGateway, "-o", n.VethName(), "-j", "ACCEPT")
+	err = tables.Append("filter", n.HostForwardChain(), "-i", hostDefaultGateway, "-o", n.VethName(), "-j", "ACCEPT")
 	if err != nil {
 		return fmt.Errorf("error creating forwarding rule to packet coming from default gateway: %w", err)
 	}
 
 	// 5. (HostNS) Add host postrouting rules, change packet source ip address is it is from host cloned ip
 	// to make guest can connected to outside internet
-	err = tables.Append("nat", "POSTROUTING", "-s", n.HostClonedIP(), "-o", hostDefaultGateway, "-j", "MASQUERADE")
+	err = tables.Append("nat", n.HostPostroutingChain(), "-s", n.HostClonedIP(), "-o", hostDefaultGateway, "-j", "MASQUERADE")
 	if err != nil {
 		return fmt.Errorf("error creating postrouting rule to packet leaving host default gateway: %w", err)
 	}
 
+	if n.IPv6Enabled() {
+		if err := n.setupHostIPv6(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The same as ipv4 (see SetupIptablesAndRoute) but with ip6tables.
+//
+// Start at sandbox ns
+// end at sandbox ns
+func (n *SandboxNetwork) setupSbxIPv6() error {
+	// forwarding of ipv6 is disabled by default in new netns
+	if err := os.WriteFile("/proc/sys/net/ipv6/conf/all/forwarding", []byte("1"), 0o644); err != nil {
+		return fmt.Errorf("error enabling ipv6 forwarding in sandbox ns: %w", err)
+	}
+
+	err := netlink.RouteAdd(&netlink.Route{
+		Scope: netlink.SCOPE_UNIVERSE,
+		Gw:    n.VethIPv6(),
+	})
+	if err != nil {
+		return fmt.Errorf("error adding default ipv6 NS route: %w", err)
+	}
+
+	tables, err := iptables.NewWithProtocol(iptables.ProtocolIPv6)
+	if err != nil {
+		return fmt.Errorf("error initializing ip6tables in guest netns: %w", err)
+	}
+
+	err = tables.Append("nat", "POSTROUTING", "-o", n.VpeerName(),
+		"-s", n.GuestIPv6(), "-j", "SNAT",
+		"--to-source", n.HostClonedIPv6(),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating ipv6 postrouting rule for packet leaving guest: %w", err)
+	}
+
+	err = tables.Append("nat", "PREROUTING", "-i", n.VpeerName(),
+		"-d", n.HostClonedIPv6(), "-j", "DNAT",
+		"--to-destination", n.GuestIPv6(),
+	)
+	if err != nil {
+		return fmt.Errorf("error creating ipv6 prerouting rule for packet targeting guest: %w", err)
+	}
+	return nil
+}
+
+// Start at host ns
+// end at host ns
+func (n *SandboxNetwork) setupHostIPv6() error {
+	gateway, err := hostDefaultGatewayV6()
+	if err != nil {
+		return fmt.Errorf("error getting host default ipv6 gateway: %w", err)
+	}
+
+	_, ipNet, err := net.ParseCIDR(n.HostClonedIPv6CIDR())
+	if err != nil {
+		return fmt.Errorf("error parsing host cloned ipv6 CIDR %s: %w", n.HostClonedIPv6CIDR(), err)
+	}
+	err = netlink.RouteAdd(&netlink.Route{
+		Gw:  n.VpeerIPv6(),
+		Dst: ipNet,
+	})
+	if err != nil {
+		return fmt.Errorf("error adding ipv6 route from host to guest vpeer: %w", err)
+	}
+
+	tables, err := iptables.NewWithProtocol(iptables.ProtocolIPv6)
+	if err != nil {
+		return fmt.Errorf("error initializing ip6tables: %w", err)
+	}
+
+	err = tables.Append("filter", n.HostForwardChain(), "-i", n.VethName(), "-o", gateway, "-j", "ACCEPT")
+	if err != nil {
+		return fmt.Errorf("error creating ipv6 forwarding rule to packet leaving host default gateway: %w", err)
+	}
+
+	err = tables.Append("filter", n.HostForwardChain(), "-i", gateway, "-o", n.VethName(), "-j", "ACCEPT")
+	if err != nil {
+		return fmt.Errorf("error creating ipv6 forwarding rule to packet coming from default gateway: %w", err)
+	}
+
+	err = tables.Append("nat", n.HostPostroutingChain(), "-s", n.HostClonedIPv6(), "-o", gateway, "-j", "MASQUERADE")
+	if err != nil {
+		return fmt.Errorf("error creating ipv6 postrouting rule to packet leaving host default gateway: %w", err)
+	}
 	return nil
 }
 
 func (n *SandboxNetwork) raiseAmbientCaps(caps []uintptr) error {
+	lower, err := raiseAmbientCaps(caps)
+	n.end = append(n.end, lower...)
+	return err
+}
+
+// Raise the ambient caps of current thread, so that they are inherited by the
+// child processes (e.g., iptables). Return the functions to lower the raised caps.
+func raiseAmbientCaps(caps []uintptr) ([]func() error, error) {
 	var (
 		hdr = unix.CapUserHeader{
 			Version: unix.LINUX_CAPABILITY_VERSION_3,
 		}
 		data      unix.CapUserData
 		updateCap bool
+		lower     []func() error
 	)
 	// The inheritable cap set of a running process cannot be changed
 	// (i.e., only inherited from parent process).
@@ -374,7 +600,7 @@ func (n *SandboxNetwork) raiseAmbientCaps(caps []uintptr) error {
 	// permitted and inheritable cap sets.
 	// Thus, we manually set the inheritable and permitted cap set here.
 	if err := unix.Capget(&hdr, &data); err != nil {
-		return fmt.Errorf("error getting capabilities: %w", err)
+		return nil, fmt.Errorf("error getting capabilities: %w", err)
 	}
 	for _, cap := range caps {
 		if (1<<cap)&data.Inheritable == 0 {
@@ -388,19 +614,19 @@ func (n *SandboxNetwork) raiseAmbientCaps(caps []uintptr) error {
 	}
 	if updateCap {
 		if err := unix.Capset(&hdr, &data); err != nil {
-			return fmt.Errorf("error setting capabilities: %w", err)
+			return nil, fmt.Errorf("error setting capabilities: %w", err)
 		}
 	}
 
 	for _, cap := range caps {
 		if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_RAISE, cap, 0, 0); err != nil {
-			return fmt.Errorf("error raising ambient capability %d: %w", cap, err)
+			return lower, fmt.Errorf("error raising ambient capability %d: %w", cap, err)
 		}
-		n.end = append(n.end, func() error {
+		lower = append(lower, func() error {
 			return unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_LOWER, cap, 0, 0)
 		})
 	}
-	return nil
+	return lower, nil
 }
 
 func (n *SandboxNetwork) Cleanup(ctx context.Context) error {
@@ -456,22 +682,118 @@ func (n *SandboxNetwork) Cleanup(ctx context.Context) error {
 	// return finalErr
 }
 
+// Delete all the resources of the network env on host, including those not
+// created by this instance (e.g., leaked by a crashed process).
+//
+// Return [ErrNetnsInUse] without deleting anything if there are still processes
+// in the netns, as the network might be used by an orphan sandbox.
+func (n *SandboxNetwork) Reclaim() error {
+	pid, err := netnsPid(n.NetNsName())
+	if err != nil {
+		return fmt.Errorf("error finding processes in netns %s: %w", n.NetNsName(), err)
+	}
+	if pid != 0 {
+		return fmt.Errorf("%w: %s is used by pid %d", ErrNetnsInUse, n.NetNsName(), pid)
+	}
+	return errors.Join(
+		n.DeleteNetns(),
+		n.DeleteHostVethDev(),
+		n.DeleteHostIptables(),
+		n.DeleteHostRoute(),
+		n.RemovePortForwards(),
+	)
+}
+
+// Wait until there is no process in the netns, e.g., the vmm killed is
+// fully torn down by the kernel, so that the network can be safely reused.
+// Return [ErrNetnsInUse] if there is still a process after timeout.
+func (n *SandboxNetwork) WaitUnused(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(netnsPollInterval)
+	defer ticker.Stop()
+	for {
+		pid, err := netnsPid(n.NetNsName())
+		if err != nil {
+			return fmt.Errorf("error finding processes in netns %s: %w", n.NetNsName(), err)
+		}
+		if pid == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %s is still used by pid %d after %s", ErrNetnsInUse, n.NetNsName(), pid, timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// Find a process in the named netns, return 0 if there is none.
+func netnsPid(name string) (int, error) {
+	target, err := os.Stat(filepath.Join(netnsDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return 0, err
+	}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		// the process might have exited
+		info, err := os.Stat(filepath.Join(procRoot, entry.Name(), "ns", "net"))
+		if err != nil {
+			continue
+		}
+		if os.SameFile(target, info) {
+			return pid, nil
+		}
+	}
+	return 0, nil
+}
+
+// Whether the error of setting up network is caused by the resources left by
+// previous (crashed) process, which can be recovered by [SandboxNetwork.Reclaim].
+func IsLeftover(err error) bool {
+	return errors.Is(err, ErrNetnsExists) || errors.Is(err, syscall.EEXIST)
+}
+
+// Whether the error of deleting a resource of network env is caused by it
+// does not exist, e.g., the setup failed midway or it is already deleted,
+// which is treated as already clean so that the deletes are idempotent.
+func isAlreadyDeleted(err error) bool {
+	var notFound netlink.LinkNotFoundError
+	return errors.As(err, &notFound) ||
+		errors.Is(err, syscall.ESRCH) ||
+		errors.Is(err, syscall.ENODEV)
+}
+
 func (n *SandboxNetwork) DeleteHostVethDev() error {
 	// Delete veth device
 	// We explicitly delete the veth device from the host namespace because even though deleting
 	// is deleting the device there may be a race condition when creating a new veth device with
 	// the same name immediately after deleting the namespace.
 	veth, err := netlink.LinkByName(n.VethName())
-	if err != nil {
+	if isAlreadyDeleted(err) {
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("error finding veth: %w", err)
 	}
+	// the veth is also deleted along with its peer in netns, which might happen meanwhile
 	err = netlink.LinkDel(veth)
-	if err != nil {
+	if err != nil && !isAlreadyDeleted(err) {
 		return fmt.Errorf("error deleting veth device: %w", err)
 	}
 	return nil
 }
 
+// The routes not exist are ignored, e.g., the setup failed before adding them.
 func (n *SandboxNetwork) DeleteHostRoute() (finalErr error) {
 	// Delete routing from host to guest namespace
 	_, ipNet, err := net.ParseCIDR(n.HostClonedCIDR())
@@ -482,36 +804,87 @@ func (n *SandboxNetwork) DeleteHostRoute() (finalErr error) {
 		Gw:  n.VpeerIP(),
 		Dst: ipNet,
 	})
-	if err != nil {
-		return fmt.Errorf("error deleting route from host to guest vpeer: %w", err)
+	if err != nil && !isAlreadyDeleted(err) {
+		finalErr = errors.Join(finalErr, fmt.Errorf("error deleting route from host to guest vpeer: %w", err))
 	}
-	return nil
+
+	if n.IPv6Enabled() {
+		_, ipNet, err := net.ParseCIDR(n.HostClonedIPv6CIDR())
+		if err != nil {
+			return errors.Join(finalErr, fmt.Errorf("error parsing host cloned ipv6 CIDR: %w", err))
+		}
+		err = netlink.RouteDel(&netlink.Route{
+			Gw:  n.VpeerIPv6(),
+			Dst: ipNet,
+		})
+		// the network env might be created without ipv6 (e.g., when purging orphans)
+		if err != nil && !isAlreadyDeleted(err) {
+			finalErr = errors.Join(finalErr, fmt.Errorf("error deleting ipv6 route from host to guest vpeer: %w", err))
+		}
+	}
+	return finalErr
 }
 
+// The rules not exist are ignored, e.g., the setup failed before adding them.
 func (n *SandboxNetwork) DeleteHostIptables() (finalErr error) {
 	tables, err := iptables.New()
 	if err != nil {
 		return fmt.Errorf("error initializing iptables: %w", err)
 	}
-	err = tables.Delete("filter", "FORWARD", "-i", n.VethName(), "-o", hostDefaultGateway, "-j", "ACCEPT")
+	err = tables.DeleteIfExists("filter", n.HostForwardChain(), "-i", n.VethName(), "-o", hostDefaultGateway, "-j", "ACCEPT")
 	if err != nil {
 		errMsg := fmt.Errorf("error deleting forwarding rule to packet leaving host default gateway: %w", err)
 		finalErr = errors.Join(finalErr, errMsg)
 	}
 
-	err = tables.Delete("filter", "FORWARD", "-i", hostDefaultGateway, "-o", n.VethName(), "-j", "ACCEPT")
+	err = tables.DeleteIfExists("filter", n.HostForwardChain(), "-i", hostDefaultGateway, "-o", n.VethName(), "-j", "ACCEPT")
 	if err != nil {
 		errMsg := fmt.Errorf("error deleting forwarding rule to packet coming from default gateway: %w", err)
 		finalErr = errors.Join(finalErr, errMsg)
 	}
 
 	// Delete host postrouting rules
-	err = tables.Delete("nat", "POSTROUTING", "-s", n.HostClonedIP(), "-o", hostDefaultGateway, "-j", "MASQUERADE")
+	err = tables.DeleteIfExists("nat", n.HostPostroutingChain(), "-s", n.HostClonedIP(), "-o", hostDefaultGateway, "-j", "MASQUERADE")
 	if err != nil {
 		errMsg := fmt.Errorf("error deleting postrouting rule to packet leaving host default gateway: %w", err)
 		finalErr = errors.Join(finalErr, errMsg)
 	}
 
+	if n.IPv6Enabled() {
+		finalErr = errors.Join(finalErr, n.deleteHostIPv6Iptables())
+	}
+
+	return finalErr
+}
+
+// The rules not exist are ignored, as the network env might be created
+// without ipv6 (e.g., when purging orphans).
+func (n *SandboxNetwork) deleteHostIPv6Iptables() (finalErr error) {
+	gateway, err := hostDefaultGatewayV6()
+	if err != nil {
+		return fmt.Errorf("error getting host default ipv6 gateway: %w", err)
+	}
+	tables, err := iptables.NewWithProtocol(iptables.ProtocolIPv6)
+	if err != nil {
+		return fmt.Errorf("error initializing ip6tables: %w", err)
+	}
+	err = tables.DeleteIfExists("filter", n.HostForwardChain(), "-i", n.VethName(), "-o", gateway, "-j", "ACCEPT")
+	if err != nil {
+		errMsg := fmt.Errorf("error deleting ipv6 forwarding rule to packet leaving host default gateway: %w", err)
+		finalErr = errors.Join(finalErr, errMsg)
+	}
+
+	err = tables.DeleteIfExists("filter", n.HostForwardChain(), "-i", gateway, "-o", n.VethName(), "-j", "ACCEPT")
+	if err != nil {
+		errMsg := fmt.Errorf("error deleting ipv6 forwarding rule to packet coming from default gateway: %w", err)
+		finalErr = errors.Join(finalErr, errMsg)
+	}
+
+	err = tables.DeleteIfExists("nat", n.HostPostroutingChain(), "-s", n.HostClonedIPv6(), "-o", gateway, "-j", "MASQUERADE")
+	if err != nil {
+		errMsg := fmt.Errorf("error deleting ipv6 postrouting rule to packet leaving host default gateway: %w", err)
+		finalErr = errors.Join(finalErr, errMsg)
+	}
 	return finalErr
 }
 
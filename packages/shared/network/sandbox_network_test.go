@@ -0,0 +1,203 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+)
+
+func TestIsLeftover(t *testing.T) {
+	testCases := []struct {
+		name     string
+		err      error
+		leftover bool
+	}{
+		{"netns exists", fmt.Errorf("%w: ns-1", ErrNetnsExists), true},
+		{"device exists", fmt.Errorf("error creating veth device: %w", syscall.EEXIST), true},
+		{"joined", errors.Join(errors.New("other"), syscall.EEXIST), true},
+		{"permission denied", fmt.Errorf("error creating veth device: %w", syscall.EPERM), false},
+		{"netns in use", fmt.Errorf("%w: ns-1", ErrNetnsInUse), false},
+		{"nil", nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsLeftover(tc.err); got != tc.leftover {
+				t.Fatalf("expect leftover %v, got %v", tc.leftover, got)
+			}
+		})
+	}
+}
+
+func TestIsAlreadyDeleted(t *testing.T) {
+	testCases := []struct {
+		name    string
+		err     error
+		deleted bool
+	}{
+		{"link not found", fmt.Errorf("error finding veth: %w", netlink.LinkNotFoundError{}), true},
+		{"no such route", fmt.Errorf("error deleting route: %w", syscall.ESRCH), true},
+		{"no such device", syscall.ENODEV, true},
+		{"joined", errors.Join(errors.New("other"), syscall.ESRCH), true},
+		{"permission denied", fmt.Errorf("error deleting veth device: %w", syscall.EPERM), false},
+		{"nil", nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isAlreadyDeleted(tc.err); got != tc.deleted {
+				t.Fatalf("expect already deleted %v, got %v", tc.deleted, got)
+			}
+		})
+	}
+}
+
+// Fake the netns dir and procfs, the process "ns/net" is a link of the netns file.
+func fakeNetns(t *testing.T, name string, pids ...string) {
+	t.Helper()
+	oldNetnsDir, oldProcRoot := netnsDir, procRoot
+	netnsDir, procRoot = t.TempDir(), t.TempDir()
+	t.Cleanup(func() {
+		netnsDir, procRoot = oldNetnsDir, oldProcRoot
+	})
+
+	if err := os.WriteFile(filepath.Join(netnsDir, name), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// a process in other netns and a non-process entry
+	for _, dir := range []string{"1/ns", "self/ns"} {
+		if err := os.MkdirAll(filepath.Join(procRoot, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(procRoot, "1/ns/net"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, pid := range pids {
+		if err := os.MkdirAll(filepath.Join(procRoot, pid, "ns"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(netnsDir, name), filepath.Join(procRoot, pid, "ns", "net")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNetnsPid(t *testing.T) {
+	fakeNetns(t, "ns-1", "42")
+	if pid, err := netnsPid("ns-1"); err != nil || pid != 42 {
+		t.Fatalf("expect pid 42, got %d (err: %v)", pid, err)
+	}
+	if pid, err := netnsPid("ns-2"); err != nil || pid != 0 {
+		t.Fatalf("expect no process for missing netns, got %d (err: %v)", pid, err)
+	}
+
+	fakeNetns(t, "ns-1")
+	if pid, err := netnsPid("ns-1"); err != nil || pid != 0 {
+		t.Fatalf("expect no process in netns, got %d (err: %v)", pid, err)
+	}
+}
+
+func TestReclaimNetnsInUse(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.140.0.0/16")
+	n := NewSandboxNetwork(NewNetworkEnv(1, subnet), "")
+	fakeNetns(t, n.NetNsName(), "42")
+
+	err := n.Reclaim()
+	if !errors.Is(err, ErrNetnsInUse) {
+		t.Fatalf("expect netns in use, got %v", err)
+	}
+	if IsLeftover(err) {
+		t.Fatal("the netns in use should not be treated as leftover")
+	}
+	// nothing is deleted
+	if _, err := os.Stat(filepath.Join(netnsDir, n.NetNsName())); err != nil {
+		t.Fatalf("netns should not be deleted: %s", err)
+	}
+}
+
+func TestWaitUnused(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.140.0.0/16")
+	n := NewSandboxNetwork(NewNetworkEnv(1, subnet), "")
+	fakeNetns(t, n.NetNsName(), "42")
+
+	err := n.WaitUnused(context.Background(), 200*time.Millisecond)
+	if !errors.Is(err, ErrNetnsInUse) {
+		t.Fatalf("expect netns in use after timeout, got %v", err)
+	}
+
+	// the process exits during waiting
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		os.RemoveAll(filepath.Join(procRoot, "42"))
+	}()
+	if err := n.WaitUnused(context.Background(), 5*time.Second); err != nil {
+		t.Fatalf("wait unused failed: %s", err)
+	}
+}
+
+func TestValidateMTU(t *testing.T) {
+	testCases := []struct {
+		mtu   int
+		valid bool
+	}{
+		{0, false},
+		{MinMTU - 1, false},
+		{MinMTU, true},
+		{1450, true},
+		{MaxMTU, true},
+		{MaxMTU + 1, false},
+	}
+	for _, tc := range testCases {
+		err := ValidateMTU(tc.mtu)
+		if tc.valid && err != nil {
+			t.Fatalf("expect mtu %d valid, got %s", tc.mtu, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidMTU) {
+			t.Fatalf("expect mtu %d invalid, got %v", tc.mtu, err)
+		}
+	}
+	if env := NewNetworkEnv(1, nil).WithMTU(1450); env.MTU() != 1450 {
+		t.Fatalf("expect mtu 1450, got %d", env.MTU())
+	}
+}
+
+// Fake the processes in host netns (see fakeNetns), with their children.
+func fakeHostProcesses(t *testing.T, children map[string]string) {
+	t.Helper()
+	for pid, c := range children {
+		if err := os.MkdirAll(filepath.Join(procRoot, pid, "task", pid), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(procRoot, pid, "task", pid, "children"), []byte(c), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(filepath.Join(procRoot, pid, "ns"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join(procRoot, "1/ns/net"), filepath.Join(procRoot, pid, "ns", "net")); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestNetnsPidOfTree(t *testing.T) {
+	// 10 -> 11 -> 12 (in netns) and 13 (exited)
+	fakeNetns(t, "ns-1", "12")
+	fakeHostProcesses(t, map[string]string{"10": "11 ", "11": "12 13 "})
+	if pid, err := NetnsPidOfTree(10); err != nil || pid != 12 {
+		t.Fatalf("expect pid 12, got %d (err: %v)", pid, err)
+	}
+
+	fakeNetns(t, "ns-1")
+	fakeHostProcesses(t, map[string]string{"10": "11 ", "11": ""})
+	if _, err := NetnsPidOfTree(10); !errors.Is(err, ErrNoNetnsProcess) {
+		t.Fatalf("expect no process in netns, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"golang.org/x/sys/unix"
+)
+
+var ErrCgroupV1 = errors.New("cgroup v2 unified hierarchy is required")
+
+// Fail with ErrCgroupV1 if the cgroupfs mounted at path is not cgroup v2
+// (i.e., the host is using cgroup v1 or the hybrid hierarchy, where path is
+// a tmpfs containing the v1 controllers and maybe a cgroup2 at "unified").
+func CheckCgroupV2(path string) error {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return fmt.Errorf("error statfs cgroupfs %s: %w", path, err)
+	}
+	if st.Type == unix.CGROUP2_SUPER_MAGIC {
+		return nil
+	}
+	mode := "v1"
+	var unified unix.Statfs_t
+	if err := unix.Statfs(filepath.Join(path, "unified"), &unified); err == nil && unified.Type == unix.CGROUP2_SUPER_MAGIC {
+		mode = "hybrid"
+	}
+	return fmt.Errorf("%w: %s is not a cgroup2 filesystem, the host is using cgroup %s "+
+		"(boot with systemd.unified_cgroup_hierarchy=1 or set cgroupfs_path to the mountpoint of cgroup2)",
+		ErrCgroupV1, path, mode)
+}
@@ -0,0 +1,15 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckCgroupV2(t *testing.T) {
+	if err := CheckCgroupV2(t.TempDir()); !errors.Is(err, ErrCgroupV1) {
+		t.Fatalf("expect %s for a non cgroup2 dir, got %v", ErrCgroupV1, err)
+	}
+	if err := CheckCgroupV2("/not-exist"); err == nil || errors.Is(err, ErrCgroupV1) {
+		t.Fatalf("expect statfs error, got %v", err)
+	}
+}
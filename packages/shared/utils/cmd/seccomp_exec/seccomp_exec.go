@@ -0,0 +1,74 @@
+// seccomp_exec installs a seccomp filter and then execs the given program.
+//
+// The filter is a raw, pre-compiled classic BPF program (i.e., an array of
+// struct sock_filter), for example the output of libseccomp's
+// seccomp_export_bpf(). The filter is inherited by the exec-ed program and
+// all of its children.
+//
+// Usage: seccomp_exec <profile> -- <program> [args...]
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// sizeof(struct sock_filter)
+const sockFilterSize = 8
+
+func loadFilter(path string) ([]unix.SockFilter, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 || len(b)%sockFilterSize != 0 {
+		return nil, fmt.Errorf("invalid bpf program size %d in %s", len(b), path)
+	}
+	if len(b)/sockFilterSize > unix.BPF_MAXINSNS {
+		return nil, fmt.Errorf("bpf program in %s exceeds %d instructions", path, unix.BPF_MAXINSNS)
+	}
+	filters := make([]unix.SockFilter, 0, len(b)/sockFilterSize)
+	for off := 0; off < len(b); off += sockFilterSize {
+		filters = append(filters, unix.SockFilter{
+			Code: binary.NativeEndian.Uint16(b[off:]),
+			Jt:   b[off+2],
+			Jf:   b[off+3],
+			K:    binary.NativeEndian.Uint32(b[off+4:]),
+		})
+	}
+	return filters, nil
+}
+
+func main() {
+	if len(os.Args) < 4 || os.Args[2] != "--" {
+		panic("Usage: seccomp_exec <profile> -- <program> [args...]")
+	}
+	profile, args := os.Args[1], os.Args[3:]
+
+	filters, err := loadFilter(profile)
+	if err != nil {
+		panic(fmt.Sprintf("Error loading seccomp profile: %v\n", err))
+	}
+	binPath, err := exec.LookPath(args[0])
+	if err != nil {
+		panic(fmt.Sprintf("Error looking up %s: %v\n", args[0], err))
+	}
+
+	prog := unix.SockFprog{
+		Len:    uint16(len(filters)),
+		Filter: &filters[0],
+	}
+	// we do not set PR_SET_NO_NEW_PRIVS here, as the vmm relies
+	// on the ambient CAP_SYS_ADMIN, which also allows us to install the filter.
+	if err := unix.Prctl(unix.PR_SET_SECCOMP, unix.SECCOMP_MODE_FILTER, uintptr(unsafe.Pointer(&prog)), 0, 0); err != nil {
+		panic(fmt.Sprintf("Error installing seccomp filter: %v\n", err))
+	}
+	if err := unix.Exec(binPath, args, os.Environ()); err != nil {
+		panic(fmt.Sprintf("Error executing %s: %v\n", binPath, err))
+	}
+}
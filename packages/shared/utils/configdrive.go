@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	// The volume label cloud-init (NoCloud datasource) looks for.
+	ConfigDriveLabel = "CIDATA"
+	// the size of config drive must be fixed, as the guest
+	// kernel in snapshot has already recorded the size of the block device.
+	ConfigDriveSizeKB = 2048
+	// Leave some space for the fat metadata.
+	MaxConfigDriveUserDataSize = ConfigDriveSizeKB * 1024 * 3 / 4
+	// The file of the sandbox metadata (in json) in config drive.
+	ConfigDriveMetadataName = "sandbox-metadata.json"
+)
+
+// CreateConfigDrive creates a vfat formatted image at path, which can be used
+// as the cloud-init NoCloud config drive. It contains two files: user-data and meta-data,
+// and ConfigDriveMetadataName if sandboxMetadata is not nil.
+//
+// It relies on mkfs.vfat (dosfstools) and mcopy (mtools) on the host.
+func CreateConfigDrive(ctx context.Context, path, instanceID string, userData, sandboxMetadata []byte) error {
+	if size := len(userData) + len(sandboxMetadata); size > MaxConfigDriveUserDataSize {
+		return fmt.Errorf("user data too large: %d > %d bytes", size, MaxConfigDriveUserDataSize)
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(path), "config-drive-")
+	if err != nil {
+		return fmt.Errorf("error creating tmp dir for config drive: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	metaData := fmt.Sprintf("instance-id: %s\nlocal-hostname: %s\n", instanceID, instanceID)
+	files := map[string][]byte{
+		"user-data": userData,
+		"meta-data": []byte(metaData),
+	}
+	if sandboxMetadata != nil {
+		files[ConfigDriveMetadataName] = sandboxMetadata
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return fmt.Errorf("error writing %s: %w", name, err)
+		}
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing old config drive: %w", err)
+	}
+	mkfs := exec.CommandContext(ctx, "mkfs.vfat", "-n", ConfigDriveLabel, "-C", path, strconv.Itoa(ConfigDriveSizeKB))
+	if out, err := mkfs.CombinedOutput(); err != nil {
+		return fmt.Errorf("error mkfs.vfat config drive: %w (%s)", err, out)
+	}
+	args := []string{"-i", path}
+	for name := range files {
+		args = append(args, filepath.Join(dir, name))
+	}
+	mcopy := exec.CommandContext(ctx, "mcopy", append(args, "::")...)
+	if out, err := mcopy.CombinedOutput(); err != nil {
+		return fmt.Errorf("error copying files into config drive: %w (%s)", err, out)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCreateConfigDriveTooLarge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config-drive.img")
+	userData := bytes.Repeat([]byte("a"), MaxConfigDriveUserDataSize+1)
+	err := CreateConfigDrive(context.Background(), path, "sandbox", userData, nil)
+	if err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("expect user data too large error, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("config drive should not be created, stat err: %v", err)
+	}
+}
+
+func TestCreateConfigDrive(t *testing.T) {
+	for _, bin := range []string{"mkfs.vfat", "mcopy", "mtype"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s not found in PATH", bin)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "config-drive.img")
+	// the old drive is replaced
+	if err := os.WriteFile(path, []byte("stale"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	userData := "#cloud-config\nhostname: test\n"
+	sandboxMetadata := `{"job":"1"}`
+	if err := CreateConfigDrive(context.Background(), path, "sandbox-id", []byte(userData), []byte(sandboxMetadata)); err != nil {
+		t.Fatalf("create config drive failed: %s", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() != ConfigDriveSizeKB*1024 {
+		t.Fatalf("expect config drive of %d KiB, got %d bytes", ConfigDriveSizeKB, info.Size())
+	}
+
+	read := func(name string) string {
+		out, err := exec.Command("mtype", "-i", path, "::"+name).Output()
+		if err != nil {
+			t.Fatalf("read %s from config drive failed: %s", name, err)
+		}
+		return string(out)
+	}
+	if got := read("user-data"); got != userData {
+		t.Fatalf("unexpected user-data %q", got)
+	}
+	if got := read("meta-data"); !strings.Contains(got, "instance-id: sandbox-id") {
+		t.Fatalf("unexpected meta-data %q", got)
+	}
+	if got := read(ConfigDriveMetadataName); got != sandboxMetadata {
+		t.Fatalf("unexpected sandbox metadata %q", got)
+	}
+}
@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+var ErrInsufficientDiskSpace = errors.New("insufficient disk space")
+
+// The size of the placeholder of extra disk slot (see CreateExtraDiskPlaceholder).
+const extraDiskPlaceholderSize = 1 << 20
+
+// Create an empty (sparse) ext4 image of sizeMB at path, which can be attached
+// to the vm as a scratch disk.
+//
+// It relies on mkfs.ext4 (e2fsprogs) on the host.
+func CreateScratchDisk(ctx context.Context, path string, sizeMB int64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("error creating scratch disk: %w", err)
+	}
+	err = f.Truncate(sizeMB << 20)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("error truncating scratch disk: %w", err)
+	}
+	mkfs := exec.CommandContext(ctx, "mkfs.ext4", "-F", "-q", path)
+	if out, err := mkfs.CombinedOutput(); err != nil {
+		return fmt.Errorf("error mkfs.ext4 scratch disk: %w (%s)", err, out)
+	}
+	return nil
+}
+
+// Create the placeholder of an unused extra disk slot, its content does not
+// matter as the guest should not use it.
+func CreateExtraDiskPlaceholder(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("error creating extra disk placeholder: %w", err)
+	}
+	defer f.Close()
+	if err := f.Truncate(extraDiskPlaceholderSize); err != nil {
+		return fmt.Errorf("error truncating extra disk placeholder: %w", err)
+	}
+	return nil
+}
+
+// The free space (in bytes) of the filesystem containing path,
+// which is available to unprivileged users.
+func FreeDiskSpace(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+// Fail with ErrInsufficientDiskSpace if the free space of the filesystem
+// containing path is less than need (in bytes).
+func EnsureFreeDiskSpace(path string, need int64) error {
+	free, err := FreeDiskSpace(path)
+	if err != nil {
+		return fmt.Errorf("error statfs %s: %w", path, err)
+	}
+	if free < need {
+		return fmt.Errorf("%w on %s: need %d MiB, free %d MiB",
+			ErrInsufficientDiskSpace, path, need>>20, free>>20)
+	}
+	return nil
+}
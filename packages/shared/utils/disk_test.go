@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEnsureFreeDiskSpace(t *testing.T) {
+	dir := t.TempDir()
+	free, err := FreeDiskSpace(dir)
+	if err != nil {
+		t.Fatalf("statfs failed: %s", err)
+	}
+	if err := EnsureFreeDiskSpace(dir, free/2); err != nil {
+		t.Fatalf("expect enough disk space, got %s", err)
+	}
+	if err := EnsureFreeDiskSpace(dir, free+1<<40); !errors.Is(err, ErrInsufficientDiskSpace) {
+		t.Fatalf("expect insufficient disk space, got %v", err)
+	}
+	if err := EnsureFreeDiskSpace(dir+"/not-exist", 0); err == nil || errors.Is(err, ErrInsufficientDiskSpace) {
+		t.Fatalf("expect statfs error, got %v", err)
+	}
+}
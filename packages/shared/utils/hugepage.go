@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const hugePagesSysfsDir = "/sys/kernel/mm/hugepages"
+
+// Check whether the host has enough free pre-allocated huge pages (of pageSize bytes)
+// to back memBytes of guest memory.
+func CheckFreeHugePages(pageSize, memBytes int64) error {
+	dir := filepath.Join(hugePagesSysfsDir, fmt.Sprintf("hugepages-%dkB", pageSize>>10))
+	data, err := os.ReadFile(filepath.Join(dir, "free_hugepages"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("huge page of %d kB is not supported by host (%s does not exist)", pageSize>>10, dir)
+		}
+		return fmt.Errorf("read free huge pages failed: %w", err)
+	}
+	free, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse free huge pages failed: %w", err)
+	}
+	required := (memBytes + pageSize - 1) / pageSize
+	if free < required {
+		return fmt.Errorf(
+			"not enough free huge pages of %d kB: require %d, free %d (pre-allocate more via %s)",
+			pageSize>>10, required, free, filepath.Join(dir, "nr_hugepages"),
+		)
+	}
+	return nil
+}
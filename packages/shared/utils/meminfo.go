@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const meminfoPath = "/proc/meminfo"
+
+// The total memory (in MiB) of host, i.e., MemTotal of /proc/meminfo.
+func HostMemoryMB() (int64, error) {
+	data, err := os.ReadFile(meminfoPath)
+	if err != nil {
+		return 0, fmt.Errorf("read %s failed: %w", meminfoPath, err)
+	}
+	return parseMemTotalMB(data)
+}
+
+func parseMemTotalMB(meminfo []byte) (int64, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(meminfo))
+	for scanner.Scan() {
+		// e.g., "MemTotal:       16303428 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemTotal:" || fields[2] != "kB" {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parse MemTotal failed: %w", err)
+		}
+		return kb >> 10, nil
+	}
+	return 0, fmt.Errorf("MemTotal not found in %s", meminfoPath)
+}
@@ -0,0 +1,17 @@
+package utils
+
+import "testing"
+
+func TestParseMemTotalMB(t *testing.T) {
+	meminfo := []byte("MemTotal:       16303428 kB\nMemFree:         1024000 kB\n")
+	mb, err := parseMemTotalMB(meminfo)
+	if err != nil {
+		t.Fatalf("parse meminfo failed: %s", err)
+	}
+	if mb != 16303428>>10 {
+		t.Fatalf("unexpected MemTotal %d MiB", mb)
+	}
+	if _, err := parseMemTotalMB([]byte("MemFree: 1024 kB\n")); err == nil {
+		t.Fatalf("expect error without MemTotal")
+	}
+}
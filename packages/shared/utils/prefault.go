@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Load the whole file into the host page cache, so that the later page faults
+// on the file (e.g., the memfile mmap'd by the vmm on restore) will not read
+// from disk.
+//
+// It uses MADV_POPULATE_READ (Linux >= 5.14) which waits until all pages are
+// read, and falls back to MADV_WILLNEED (async readahead) on older kernels.
+func PrefaultFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open %s failed: %w", path, err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat %s failed: %w", path, err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	data, err := unix.Mmap(int(f.Fd()), 0, int(info.Size()), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return fmt.Errorf("mmap %s failed: %w", path, err)
+	}
+	defer unix.Munmap(data)
+
+	err = unix.Madvise(data, unix.MADV_POPULATE_READ)
+	if errors.Is(err, unix.EINVAL) {
+		err = unix.Madvise(data, unix.MADV_WILLNEED)
+	}
+	if err != nil {
+		return fmt.Errorf("madvise %s failed: %w", path, err)
+	}
+	return nil
+}
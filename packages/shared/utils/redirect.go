@@ -9,7 +9,9 @@ import (
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 )
 
-func RedirectVmmOutput(ctx context.Context, tag string, output io.ReadCloser) {
+// Report each line of output as an event, and also write it into tee
+// (e.g., a console log) if it is not nil.
+func RedirectVmmOutput(ctx context.Context, tag string, output io.ReadCloser, tee io.Writer) {
 	writer := telemetry.NewEventWriter(ctx, tag)
 
 	defer func() {
@@ -24,6 +26,12 @@ func RedirectVmmOutput(ctx context.Context, tag string, output io.ReadCloser) {
 	for scanner.Scan() {
 		line := scanner.Text()
 		writer.Write([]byte(line))
+		if tee != nil {
+			if _, err := tee.Write([]byte(line + "\n")); err != nil {
+				telemetry.ReportError(ctx, fmt.Errorf("error writing %s: %w", tag, err))
+				tee = nil
+			}
+		}
 	}
 
 	readerErr := scanner.Err()
@@ -10,7 +10,12 @@ import (
 	"time"
 )
 
-// Retry http request when encounter EOF error
+// Wrapped by the error of the request (e.g., 5xx response) which
+// should be retried by RetryHttpRequest.
+var ErrTransientResponse = errors.New("transient response")
+
+// Retry http request when encounter EOF error (or ErrTransientResponse),
+// return the number of retries.
 func RetryHttpRequest(ctx context.Context, httpReqFunc func() error, maxRetryTimes int) (int, error) {
 	var err error
 	retryTimes := 0
@@ -22,6 +27,9 @@ func RetryHttpRequest(ctx context.Context, httpReqFunc func() error, maxRetryTim
 		if err == nil {
 			return retryTimes, nil
 		}
+		if errors.Is(err, ErrTransientResponse) {
+			goto cont
+		}
 		// we only retry with EOF error
 		if e := (&url.Error{}); errors.As(err, &e) {
 			switch {
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetryHttpRequest(t *testing.T) {
+	errBadRequest := errors.New("bad request")
+	testCases := []struct {
+		name          string
+		errs          []error
+		maxRetryTimes int
+		expectErr     error
+		retryTimes    int
+	}{
+		{"succeed", []error{nil}, 3, nil, 0},
+		{"retry transient", []error{ErrTransientResponse, fmt.Errorf("%w: 503", ErrTransientResponse), nil}, 3, nil, 2},
+		{"no retry", []error{errBadRequest}, 3, errBadRequest, 0},
+		{"max retry times", []error{ErrTransientResponse, ErrTransientResponse, ErrTransientResponse}, 2, ErrTransientResponse, 3},
+	}
+	for _, tc := range testCases {
+		calls := 0
+		retryTimes, err := RetryHttpRequest(context.Background(), func() error {
+			err := tc.errs[min(calls, len(tc.errs)-1)]
+			calls++
+			return err
+		}, tc.maxRetryTimes)
+		if !errors.Is(err, tc.expectErr) || (tc.expectErr == nil && err != nil) {
+			t.Fatalf("%s: expect error %v, got %v", tc.name, tc.expectErr, err)
+		}
+		if retryTimes != tc.retryTimes {
+			t.Fatalf("%s: expect %d retries, got %d", tc.name, tc.retryTimes, retryTimes)
+		}
+	}
+}
+
+func TestRetryHttpRequestTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := RetryHttpRequest(ctx, func() error { return ErrTransientResponse }, 100)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// A file writer which rotates the file into path.1 (replacing the previous
+// one) once its size exceeds maxSize, so at most about 2*maxSize is used.
+// It is safe for concurrent use.
+type RotatingFile struct {
+	mu   sync.Mutex
+	path string
+	RotateOptions
+	f    *os.File
+	size int64
+	// the in-flight compressions of rotated files
+	compressing sync.WaitGroup
+}
+
+// How RotatingFile rotates and keeps the rotated files.
+type RotateOptions struct {
+	// rotate once the size exceeds MaxSize (in bytes)
+	MaxSize int64
+	// the number of rotated files kept, i.e., path.1 ... path.N, where path.1
+	// is the newest one. The file is truncated instead of rotated when it is 0.
+	MaxBackups int
+	// gzip the rotated files into path.N.gz in background, so that the writes
+	// are not blocked. The file is left uncompressed if it fails.
+	Compress bool
+	// the level of gzip, 0 means gzip.DefaultCompression
+	CompressLevel int
+}
+
+func OpenRotatingFile(path string, maxSize int64) (*RotatingFile, error) {
+	return OpenRotatingFileWithOptions(path, RotateOptions{MaxSize: maxSize, MaxBackups: 1})
+}
+
+// Same as OpenRotatingFile, but keeps (and compresses) the rotated files as
+// opts specifies, so at most about (MaxBackups+1)*MaxSize is used.
+func OpenRotatingFileWithOptions(path string, opts RotateOptions) (*RotatingFile, error) {
+	if opts.CompressLevel == 0 {
+		opts.CompressLevel = gzip.DefaultCompression
+	}
+	r := &RotatingFile{path: path, RotateOptions: opts}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %w", r.path, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("error stat %s: %w", r.path, err)
+	}
+	r.f = f
+	r.size = info.Size()
+	return nil
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %w", r.path, err)
+	}
+	r.f = nil
+	if r.MaxBackups == 0 {
+		if err := os.Remove(r.path); err != nil {
+			return fmt.Errorf("error removing %s: %w", r.path, err)
+		}
+		return r.open()
+	}
+	// drop the oldest one (either compressed or not), then shift
+	// path.N-1 to path.N, ..., path.1 to path.2
+	oldest := RotatedPathN(r.path, r.MaxBackups)
+	for _, p := range []string{oldest, CompressedPath(oldest)} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing %s: %w", p, err)
+		}
+	}
+	for i := r.MaxBackups - 1; i > 0; i-- {
+		src, dst := RotatedPathN(r.path, i), RotatedPathN(r.path, i+1)
+		for _, pair := range [][2]string{{src, dst}, {CompressedPath(src), CompressedPath(dst)}} {
+			if err := os.Rename(pair[0], pair[1]); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("error rotating %s: %w", pair[0], err)
+			}
+		}
+	}
+	if err := os.Rename(r.path, RotatedPath(r.path)); err != nil {
+		return fmt.Errorf("error rotating %s: %w", r.path, err)
+	}
+	if r.Compress {
+		// open it now, as it may be shifted before compressed
+		if src, err := os.Open(RotatedPath(r.path)); err == nil {
+			r.compressing.Add(1)
+			go r.compress(src)
+		}
+	}
+	return r.open()
+}
+
+// Compress the rotated file src into where it is (i.e., path.N.gz) now.
+func (r *RotatingFile) compress(src *os.File) {
+	defer r.compressing.Done()
+	defer src.Close()
+	tmp, err := r.gzipToTemp(src)
+	if err != nil {
+		return
+	}
+	info, err := src.Stat()
+	if err != nil {
+		os.Remove(tmp)
+		return
+	}
+	// prevent it from being shifted by the rotation
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := 1; i <= r.MaxBackups; i++ {
+		p := RotatedPathN(r.path, i)
+		if cur, err := os.Stat(p); err == nil && os.SameFile(info, cur) {
+			if err := os.Rename(tmp, CompressedPath(p)); err == nil {
+				os.Remove(p)
+				return
+			}
+			break
+		}
+	}
+	// has been dropped, or failed to rename
+	os.Remove(tmp)
+}
+
+func (r *RotatingFile) gzipToTemp(src *os.File) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*.gz.tmp")
+	if err != nil {
+		return "", err
+	}
+	err = func() error {
+		defer tmp.Close()
+		w, err := gzip.NewWriterLevel(tmp, r.CompressLevel)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+		if err := w.Close(); err != nil {
+			return err
+		}
+		return tmp.Sync()
+	}()
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return 0, os.ErrClosed
+	}
+	if r.size > 0 && r.size+int64(len(p)) > r.MaxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// It is fine to close more than once. It waits for the in-flight
+// compressions of rotated files.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.f != nil {
+		err = r.f.Close()
+		r.f = nil
+	}
+	r.mu.Unlock()
+	r.compressing.Wait()
+	return err
+}
+
+// Where the file at path is rotated to.
+func RotatedPath(path string) string {
+	return RotatedPathN(path, 1)
+}
+
+// The n-th newest rotated file of path (see RotateOptions.MaxBackups).
+func RotatedPathN(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// Where the rotated file at path is compressed to (see RotateOptions.Compress).
+func CompressedPath(path string) string {
+	return path + ".gz"
+}
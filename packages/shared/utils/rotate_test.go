@@ -0,0 +1,141 @@
+package utils
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "console.log")
+	r, err := OpenRotatingFile(path, 8)
+	if err != nil {
+		t.Fatalf("open rotating file failed: %s", err)
+	}
+	for _, line := range []string{"abc\n", "def\n", "ghi\n", "jkl\n", "mno\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %s", err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close failed: %s", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatalf("close again failed: %s", err)
+	}
+	if _, err := r.Write([]byte("pqr\n")); err == nil {
+		t.Fatalf("expect error when writing closed file")
+	}
+
+	cases := map[string]string{
+		path:              "mno\n",
+		RotatedPath(path): "ghi\njkl\n",
+	}
+	for p, expect := range cases {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expect {
+			t.Fatalf("expect %q in %s, got %q", expect, p, b)
+		}
+	}
+}
+
+func TestRotatingFileWithBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sandbox.log")
+	r, err := OpenRotatingFileWithOptions(path, RotateOptions{MaxSize: 4, MaxBackups: 2})
+	if err != nil {
+		t.Fatalf("open rotating file failed: %s", err)
+	}
+	defer r.Close()
+	for _, line := range []string{"abc\n", "def\n", "ghi\n", "jkl\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %s", err)
+		}
+	}
+	cases := map[string]string{
+		path:                  "jkl\n",
+		RotatedPathN(path, 1): "ghi\n",
+		RotatedPathN(path, 2): "def\n",
+	}
+	for p, expect := range cases {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expect {
+			t.Fatalf("expect %q in %s, got %q", expect, p, b)
+		}
+	}
+	if _, err := os.Stat(RotatedPathN(path, 3)); !os.IsNotExist(err) {
+		t.Fatalf("expect no more than 2 backups, got %v", err)
+	}
+
+	// truncated without backups
+	path = filepath.Join(t.TempDir(), "sandbox.log")
+	r, err = OpenRotatingFileWithOptions(path, RotateOptions{MaxSize: 4})
+	if err != nil {
+		t.Fatalf("open rotating file failed: %s", err)
+	}
+	defer r.Close()
+	for _, line := range []string{"abc\n", "def\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %s", err)
+		}
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "def\n" {
+		t.Fatalf("expect truncated file, got %q (%v)", b, err)
+	}
+	if _, err := os.Stat(RotatedPath(path)); !os.IsNotExist(err) {
+		t.Fatalf("expect no backup, got %v", err)
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sandbox.log")
+	r, err := OpenRotatingFileWithOptions(path, RotateOptions{MaxSize: 4, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("open rotating file failed: %s", err)
+	}
+	for _, line := range []string{"abc\n", "def\n", "ghi\n", "jkl\n"} {
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatalf("write failed: %s", err)
+		}
+	}
+	// wait for the compressions
+	if err := r.Close(); err != nil {
+		t.Fatalf("close failed: %s", err)
+	}
+	for i, expect := range []string{"ghi\n", "def\n"} {
+		p := RotatedPathN(path, i+1)
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Fatalf("expect %s removed after compressed, got %v", p, err)
+		}
+		f, err := os.Open(CompressedPath(p))
+		if err != nil {
+			t.Fatalf("expect %s compressed: %s", p, err)
+		}
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(gr)
+		f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != expect {
+			t.Fatalf("expect %q in %s, got %q", expect, CompressedPath(p), b)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expect 3 files left, got %d", len(entries))
+	}
+}
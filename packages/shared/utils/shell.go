@@ -0,0 +1,8 @@
+package utils
+
+import "strings"
+
+// Quote s as a single argument of the command executed by `bash -c`.
+func ShellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+// How to wait for the api socket of vmm to be ready.
+type SocketWaitOptions struct {
+	Timeout time.Duration
+	// The socket file (and the api) is polled with exponential backoff, i.e.,
+	// starting from PollInterval and doubled until MaxPollInterval.
+	PollInterval    time.Duration
+	MaxPollInterval time.Duration
+	// Send a trivial request (e.g., GET /version of firecracker) until it
+	// succeeds, otherwise only wait for the socket file to be created.
+	Probe bool
+}
+
+func DefaultSocketWaitOptions() SocketWaitOptions {
+	return SocketWaitOptions{
+		Timeout:         consts.WaitTimeForHypervisorSocket,
+		PollInterval:    10 * time.Millisecond,
+		MaxPollInterval: time.Second,
+		Probe:           true,
+	}
+}
+
+func (opts SocketWaitOptions) nextInterval(interval time.Duration) time.Duration {
+	return max(min(2*interval, opts.MaxPollInterval), interval)
+}
+
+// Call poll until it returns true (or an error) with exponential backoff,
+// return the number of retries.
+func PollWithBackoff(ctx context.Context, opts SocketWaitOptions, poll func() (bool, error)) (int, error) {
+	interval := opts.PollInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	retryTimes := 0
+	for {
+		if done, err := poll(); done || err != nil {
+			return retryTimes, err
+		}
+		select {
+		case <-ctx.Done():
+			return retryTimes, ctx.Err()
+		case <-timer.C:
+		}
+		retryTimes += 1
+		interval = opts.nextInterval(interval)
+		timer.Reset(interval)
+	}
+}
+
+// Wait until the socket file is created, return the number of polls.
+func WaitForSocketFile(ctx context.Context, socketPath string, opts SocketWaitOptions) (int, error) {
+	return PollWithBackoff(ctx, opts, func() (bool, error) {
+		if _, err := os.Stat(socketPath); err != nil {
+			if os.IsNotExist(err) {
+				return false, nil
+			}
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+const (
+	// the max length of the path of unix socket, i.e., the size of sun_path
+	// excluding the trailing NUL.
+	MaxUnixSocketPathLen = 107
+	// the ids (e.g., sandbox id) longer than it are hashed in the socket name,
+	// which is the length of uuid.
+	maxSocketIDLen = 36
+	socketSuffix   = ".sock"
+)
+
+var ErrInvalidSocketDir = errors.New("invalid socket dir")
+
+// The path of the socket of id (e.g., sandbox id) under dir, the id longer than
+// maxSocketIDLen is hashed, so that the name does not exceed a fixed length.
+func SocketPath(dir, prefix, id string) string {
+	if len(id) > maxSocketIDLen {
+		sum := sha256.Sum256([]byte(id))
+		id = hex.EncodeToString(sum[:])[:maxSocketIDLen]
+	}
+	return filepath.Join(dir, prefix+id+socketSuffix)
+}
+
+// Create dir if not exists, and check it is writable and short enough for
+// the sockets with prefix (see SocketPath) to fit in sun_path.
+func ValidateSocketDir(dir, prefix string) error {
+	if !filepath.IsAbs(dir) {
+		return fmt.Errorf("%w: %s is not absolute", ErrInvalidSocketDir, dir)
+	}
+	if longest := len(filepath.Join(dir, prefix)) + maxSocketIDLen + len(socketSuffix); longest > MaxUnixSocketPathLen {
+		return fmt.Errorf("%w: %s is too long, the socket path may reach %d bytes (max %d)",
+			ErrInvalidSocketDir, dir, longest, MaxUnixSocketPathLen)
+	}
+	if err := CreateDirAllIfNotExists(dir, 0o755); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSocketDir, err)
+	}
+	if err := unix.Access(dir, unix.W_OK|unix.X_OK); err != nil {
+		return fmt.Errorf("%w: %s is not writable: %w", ErrInvalidSocketDir, dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForSocketFile(t *testing.T) {
+	opts := SocketWaitOptions{PollInterval: time.Millisecond, MaxPollInterval: 8 * time.Millisecond}
+	socketPath := filepath.Join(t.TempDir(), "api.sock")
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(socketPath, nil, 0o644)
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	retryTimes, err := WaitForSocketFile(ctx, socketPath, opts)
+	if err != nil {
+		t.Fatalf("wait for socket file failed: %s", err)
+	}
+	// at least 1+2+4+8+8+8+8 ms, and the interval is capped, so
+	// the retries should not be too few
+	if retryTimes < 5 || retryTimes > 50 {
+		t.Fatalf("unexpected retry times %d", retryTimes)
+	}
+
+	// exists already
+	retryTimes, err = WaitForSocketFile(context.Background(), socketPath, opts)
+	if err != nil || retryTimes != 0 {
+		t.Fatalf("expect no retry for existing socket file, got %d (err: %v)", retryTimes, err)
+	}
+}
+
+func TestWaitForSocketFileTimeout(t *testing.T) {
+	opts := SocketWaitOptions{PollInterval: time.Millisecond, MaxPollInterval: 4 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := WaitForSocketFile(ctx, filepath.Join(t.TempDir(), "api.sock"), opts)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expect deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("should return soon after timeout, took %s", elapsed)
+	}
+}
+
+func TestSocketWaitBackoff(t *testing.T) {
+	opts := SocketWaitOptions{PollInterval: 10 * time.Millisecond, MaxPollInterval: 50 * time.Millisecond}
+	interval := opts.PollInterval
+	var intervals []time.Duration
+	for i := 0; i < 5; i++ {
+		interval = opts.nextInterval(interval)
+		intervals = append(intervals, interval)
+	}
+	expected := []time.Duration{20, 40, 50, 50, 50}
+	for i := range expected {
+		if intervals[i] != expected[i]*time.Millisecond {
+			t.Fatalf("unexpected intervals %v", intervals)
+		}
+	}
+
+	// the max interval less than the initial one means no backoff
+	opts.MaxPollInterval = 0
+	if next := opts.nextInterval(opts.PollInterval); next != opts.PollInterval {
+		t.Fatalf("expect no backoff, got %s", next)
+	}
+}
+
+func TestSocketPath(t *testing.T) {
+	short := SocketPath("/tmp", "vmm-", "554a78c8-b80b-48ab-ac60-97c1b4912993")
+	if short != "/tmp/vmm-554a78c8-b80b-48ab-ac60-97c1b4912993.sock" {
+		t.Fatalf("expect the short id to be kept, got %s", short)
+	}
+	long := SocketPath("/tmp", "vmm-", strings.Repeat("a", 200))
+	if len(long) != len(short) {
+		t.Fatalf("expect the long id to be hashed into a fixed length, got %s", long)
+	}
+	if other := SocketPath("/tmp", "vmm-", strings.Repeat("a", 199)+"b"); other == long {
+		t.Fatalf("expect different ids to have different sockets, got %s", other)
+	}
+}
+
+func TestValidateSocketDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sockets")
+	if err := ValidateSocketDir(dir, "vmm-"); err != nil {
+		t.Fatalf("validate socket dir failed: %s", err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("expect the socket dir to be created: %s", err)
+	}
+	if err := ValidateSocketDir("sockets", "vmm-"); !errors.Is(err, ErrInvalidSocketDir) {
+		t.Fatalf("expect relative dir to be invalid, got %v", err)
+	}
+	if err := ValidateSocketDir("/"+strings.Repeat("d", 70), "vmm-"); !errors.Is(err, ErrInvalidSocketDir) {
+		t.Fatalf("expect long dir to be invalid, got %v", err)
+	}
+}
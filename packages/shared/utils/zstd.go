@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Compress src into dst with zstd, dst is replaced atomically (i.e., it
+// does not exist or is complete).
+//
+// It relies on zstd on the host.
+func CompressFile(ctx context.Context, src, dst string) error {
+	return runZstd(ctx, dst, "-q", "-f", "-T0", src)
+}
+
+// Decompress the zstd compressed src into dst, dst is replaced atomically.
+// The zero blocks are written as holes, so that the sparse file (e.g., the
+// memfile of diff snapshot) is still sparse after decompression.
+func DecompressFile(ctx context.Context, src, dst string) error {
+	return runZstd(ctx, dst, "-d", "-q", "-f", "--sparse", src)
+}
+
+func runZstd(ctx context.Context, dst string, args ...string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	err = tmp.Chmod(0o644)
+	tmp.Close()
+	if err != nil {
+		return fmt.Errorf("error chmod temp file: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "zstd", append(args, "-o", tmp.Name())...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error running zstd: %w (%s)", err, out)
+	}
+	return os.Rename(tmp.Name(), dst)
+}
@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestZstdRoundTrip(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd is not installed")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "memfile")
+	compressed := filepath.Join(dir, "memfile.zst")
+	dst := filepath.Join(dir, "memfile.out")
+
+	// 64 MiB sparse file with data at the beginning and the end
+	const size = 64 << 20
+	head := bytes.Repeat([]byte("head"), 1024)
+	tail := bytes.Repeat([]byte("tail"), 1024)
+	f, err := os.Create(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write(head); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteAt(tail, size-int64(len(tail))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	ctx := context.Background()
+	if err := CompressFile(ctx, src, compressed); err != nil {
+		t.Fatalf("compress failed: %s", err)
+	}
+	if err := DecompressFile(ctx, compressed, dst); err != nil {
+		t.Fatalf("decompress failed: %s", err)
+	}
+	expected, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	actual, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Fatal("the decompressed file differs from the original one")
+	}
+
+	// the holes are kept, i.e., far less blocks than the size
+	var st syscall.Stat_t
+	if err := syscall.Stat(dst, &st); err != nil {
+		t.Fatal(err)
+	}
+	if allocated := st.Blocks * 512; allocated >= size/2 {
+		t.Fatalf("expect the decompressed file sparse, allocated %d bytes of %d", allocated, size)
+	}
+
+	// no temporary files are left
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("unexpected files %v", entries)
+	}
+}
+
+func TestZstdDecompressInvalid(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd is not installed")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "memfile.zst")
+	if err := os.WriteFile(src, []byte("not zstd"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dst := filepath.Join(dir, "memfile")
+	if err := DecompressFile(context.Background(), src, dst); err == nil {
+		t.Fatal("expect error for invalid input")
+	}
+	// dst is not created on failure
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("unexpected files %v", entries)
+	}
+}
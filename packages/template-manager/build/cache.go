@@ -0,0 +1,155 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"github.com/KarpelesLab/reflink"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const rootfsCacheMetaName = "meta.toml"
+
+// What the cached rootfs is built from, so that the next build can reuse it
+// (as SkipBuildRootfs) when nothing changes.
+type rootfsCacheMeta struct {
+	// the id (i.e., the digest of config) of the docker image
+	ImageDigest string `toml:"image_digest"`
+	// the hash of what is provisioned into the image besides the docker image,
+	// see [Rootfs.provisionHash]
+	ProvisionHash string `toml:"provision_hash"`
+}
+
+func (c *TemplateManagerConfig) CachedRootfsMetaPath() string {
+	return filepath.Join(filepath.Dir(c.CachedRootfsPath()), rootfsCacheMetaName)
+}
+
+// Whether the cached rootfs is built from the same image and provision as meta.
+func (c *TemplateManagerConfig) rootfsCacheHit(meta rootfsCacheMeta) bool {
+	var cached rootfsCacheMeta
+	if _, err := toml.DecodeFile(c.CachedRootfsMetaPath(), &cached); err != nil {
+		return false
+	}
+	if cached != meta {
+		return false
+	}
+	paths := []string{c.CachedRootfsPath()}
+	if c.Overlay {
+		paths = append(paths, c.CachedWritableRootfsPath())
+	}
+	for _, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Remove the metadata before changing the cached rootfs, so that a half
+// updated cache is never reused.
+func (c *TemplateManagerConfig) invalidateRootfsCache() error {
+	if err := os.Remove(c.CachedRootfsMetaPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error removing rootfs cache metadata: %w", err)
+	}
+	return nil
+}
+
+func (c *TemplateManagerConfig) writeRootfsCacheMeta(meta rootfsCacheMeta) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(meta); err != nil {
+		return fmt.Errorf("error encode rootfs cache metadata: %w", err)
+	}
+	path := c.CachedRootfsMetaPath()
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing rootfs cache metadata: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("error renaming rootfs cache metadata: %w", err)
+	}
+	return nil
+}
+
+// Copy the rootfs just built into cache, which will be used by build mode
+// Normal, so that the next build can reuse it.
+func (c *TemplateManagerConfig) saveRootfsCache(ctx context.Context, meta rootfsCacheMeta) error {
+	if err := c.invalidateRootfsCache(); err != nil {
+		return err
+	}
+	if err := utils.CreateDirAllIfNotExists(filepath.Dir(c.CachedRootfsPath()), 0o755); err != nil {
+		return fmt.Errorf("error creating cache dir for rootfs: %w", err)
+	}
+	paths := []struct{ src, dst string }{
+		{
+			c.PrivateRootfsPath(c.DataRoot),
+			c.CachedRootfsPath(),
+		},
+	}
+	if c.Overlay {
+		paths = append(paths, struct{ src, dst string }{
+			c.PrivateWritableRootfsPath(c.DataRoot),
+			c.CachedWritableRootfsPath(),
+		})
+	}
+	for _, path := range paths {
+		// reflink.Auto does not overwrite the existing file
+		if err := os.Remove(path.dst); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := reflink.Auto(path.src, path.dst); err != nil {
+			return err
+		}
+		telemetry.ReportEvent(ctx, "copied rootfs into cache",
+			attribute.String("src", path.src),
+			attribute.String("dst", path.dst),
+		)
+	}
+	return c.writeRootfsCacheMeta(meta)
+}
+
+// The cache key of the rootfs, the docker image should have been pulled.
+func (r *Rootfs) resolveCacheMeta(ctx context.Context) (rootfsCacheMeta, error) {
+	inspect, _, err := r.docker.ImageInspectWithRaw(ctx, r.dockerTag())
+	if err != nil {
+		return rootfsCacheMeta{}, fmt.Errorf("error inspecting image %s: %w", r.dockerTag(), err)
+	}
+	hash, err := r.provisionHash()
+	if err != nil {
+		return rootfsCacheMeta{}, err
+	}
+	return rootfsCacheMeta{ImageDigest: inspect.ID, ProvisionHash: hash}, nil
+}
+
+// Hash the provision script (rendered with the template config), envd,
+// the overlay-init (if overlay) and the disk size.
+func (r *Rootfs) provisionHash() (string, error) {
+	script, err := r.provisionScript()
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "overlay=%t disk_size_mb=%d\n", r.cfg.Overlay, r.cfg.DiskSizeMB)
+	io.WriteString(h, script)
+	envd, err := os.Open(r.cfg.EnvdPath)
+	if err != nil {
+		return "", fmt.Errorf("error opening envd %s: %w", r.cfg.EnvdPath, err)
+	}
+	defer envd.Close()
+	if _, err := io.Copy(h, envd); err != nil {
+		return "", fmt.Errorf("error reading envd %s: %w", r.cfg.EnvdPath, err)
+	}
+	if r.cfg.Overlay {
+		h.Write(overlayInitContent)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,52 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+)
+
+func TestRootfsCacheHit(t *testing.T) {
+	cfg := &TemplateManagerConfig{
+		DataRoot:   t.TempDir(),
+		VMTemplate: config.VMTemplate{TemplateID: "default", Overlay: true},
+	}
+	meta := rootfsCacheMeta{ImageDigest: "sha256:image", ProvisionHash: "provision"}
+	if cfg.rootfsCacheHit(meta) {
+		t.Fatalf("expect miss without cache")
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfg.CachedRootfsPath()), 0o755); err != nil {
+		t.Fatalf("create cache dir failed: %s", err)
+	}
+	if err := os.WriteFile(cfg.CachedRootfsPath(), []byte("rootfs"), 0o644); err != nil {
+		t.Fatalf("write cached rootfs failed: %s", err)
+	}
+	if err := cfg.writeRootfsCacheMeta(meta); err != nil {
+		t.Fatalf("write cache meta failed: %s", err)
+	}
+	if cfg.rootfsCacheHit(meta) {
+		t.Fatalf("expect miss without cached writable rootfs")
+	}
+	if err := os.WriteFile(cfg.CachedWritableRootfsPath(), []byte("writable"), 0o644); err != nil {
+		t.Fatalf("write cached writable rootfs failed: %s", err)
+	}
+	if !cfg.rootfsCacheHit(meta) {
+		t.Fatalf("expect hit with the same meta")
+	}
+	if cfg.rootfsCacheHit(rootfsCacheMeta{ImageDigest: "sha256:other", ProvisionHash: "provision"}) {
+		t.Fatalf("expect miss with another image")
+	}
+	if cfg.rootfsCacheHit(rootfsCacheMeta{ImageDigest: "sha256:image", ProvisionHash: "other"}) {
+		t.Fatalf("expect miss with another provision")
+	}
+
+	if err := cfg.invalidateRootfsCache(); err != nil {
+		t.Fatalf("invalidate cache failed: %s", err)
+	}
+	if cfg.rootfsCacheHit(meta) {
+		t.Fatalf("expect miss after invalidated")
+	}
+}
@@ -0,0 +1,163 @@
+package build
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
+)
+
+// A problem of the config found by Lint, the field is the key in config file.
+type LintProblem struct {
+	Field string
+	Err   error
+}
+
+func (p LintProblem) String() string {
+	return fmt.Sprintf("%s: %s", p.Field, p.Err)
+}
+
+type configCheck struct {
+	field string
+	check func() error
+}
+
+// The checks of Validate, which are independent of each other, so that
+// Lint can report all the problems at once.
+func (c *TemplateManagerConfig) checks() []configCheck {
+	templateField := "template." + c.TemplateID
+	hypervisorField := "fc_binary_path"
+	if c.VmmType == config.CLOUDHYPERVISOR {
+		hypervisorField = "ch_binary_path"
+	}
+	return []configCheck{
+		{templateField, c.VMTemplate.Validate},
+		{templateField + ".target_arch", func() error {
+			// the rootfs can be built for another arch (by docker), but the
+			// vm cannot be booted for the snapshot
+			if c.RootfsBuildMode == BuildRootfsOnly {
+				return nil
+			}
+			return c.ValidateHostArch()
+		}},
+		{"data_root", func() error {
+			if c.DataRoot == "" {
+				return fmt.Errorf("data_root cannot be empty")
+			}
+			return nil
+		}},
+		{hypervisorField, func() error {
+			if _, err := exec.LookPath(c.HypervisorBinaryPath); err != nil {
+				return fmt.Errorf("hypervisor binary %s not found: %w", c.HypervisorBinaryPath, err)
+			}
+			return nil
+		}},
+		{"template_manager.envd_path", func() error {
+			if _, err := exec.LookPath(c.EnvdPath); err != nil {
+				return fmt.Errorf("envd binary %s not found: %w", c.EnvdPath, err)
+			}
+			return nil
+		}},
+		{"template_manager.build_cpu_quota", func() error {
+			if c.BuildCpuQuota < 0 {
+				return fmt.Errorf("build_cpu_quota cannot be negative")
+			}
+			return nil
+		}},
+		{"template_manager.build_memory_mb", func() error {
+			if c.BuildMemoryMB < 0 {
+				return fmt.Errorf("build_memory_mb cannot be negative")
+			}
+			return nil
+		}},
+		{"template_manager.prune_cache_timeout", func() error {
+			if _, err := time.ParseDuration(c.pruneCacheTimeout()); err != nil {
+				return fmt.Errorf("invalid prune_cache_timeout %q: %w", c.PruneCacheTimeout, err)
+			}
+			return nil
+		}},
+		{"template_manager.snapshot_attempts", func() error {
+			if c.SnapshotAttempts < 0 {
+				return fmt.Errorf("snapshot_attempts cannot be negative")
+			}
+			return nil
+		}},
+		{"template_manager.tar_progress_interval", func() error {
+			if c.TarProgressInterval == "" {
+				return nil
+			}
+			if d, err := time.ParseDuration(c.TarProgressInterval); err != nil || d < 0 {
+				return fmt.Errorf("invalid tar_progress_interval %q: should be a non-negative duration", c.TarProgressInterval)
+			}
+			return nil
+		}},
+		{"template_manager.subnet", func() error { return validateSubnet(c.Subnet.IPNet) }},
+		{"mtu", func() error { return network.ValidateMTU(c.MTU) }},
+		{"socket_dir", func() error { return utils.ValidateSocketDir(c.SocketDir, socketPrefix) }},
+	}
+}
+
+// The network of build needs the first two addresses of subnet
+// (for veth and vpeer, see [network.NetworkEnv]).
+func validateSubnet(subnet *net.IPNet) error {
+	ones, bits := subnet.Mask.Size()
+	if subnet.IP.To4() == nil || bits != 32 || ones > 30 {
+		return fmt.Errorf("subnet %s should be an ipv4 subnet with prefix length at most 30", subnet)
+	}
+	return nil
+}
+
+// The checks only run by Lint, which are not required by the build
+// (e.g., data_root is created if not exists), but likely mistakes.
+func (c *TemplateManagerConfig) lintOnlyChecks() []configCheck {
+	return []configCheck{
+		{"data_root", func() error {
+			if c.DataRoot == "" {
+				// reported by checks
+				return nil
+			}
+			info, err := os.Stat(c.DataRoot)
+			if err != nil {
+				return fmt.Errorf("data_root %s: %w", c.DataRoot, err)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("data_root %s is not a directory", c.DataRoot)
+			}
+			return nil
+		}},
+		{"template." + c.TemplateID + ".kernel_version", func() error {
+			path := c.HostKernelPath(c.DataRoot)
+			if _, err := os.Stat(path); err != nil {
+				return fmt.Errorf("kernel %s of version %s: %w", path, c.KernelVersion, err)
+			}
+			return nil
+		}},
+	}
+}
+
+// Run all the checks (of Validate and more) without building anything,
+// return all the problems found, empty if the config is valid.
+func (c *TemplateManagerConfig) Lint() []LintProblem {
+	var problems []LintProblem
+	for _, check := range append(c.checks(), c.lintOnlyChecks()...) {
+		if err := check.check(); err != nil {
+			problems = append(problems, LintProblem{Field: check.field, Err: err})
+		}
+	}
+	return problems
+}
+
+// Parse the config file (same as ParseTemplateManagerConfig) and lint it,
+// the error is returned only when the config file cannot be parsed.
+func LintTemplateManagerConfig(configFile string) (*TemplateManagerConfig, []LintProblem, error) {
+	cfg, err := decodeTemplateManagerConfig(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, cfg.Lint(), nil
+}
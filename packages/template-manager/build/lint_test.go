@@ -0,0 +1,137 @@
+package build
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func newLintConfig(t *testing.T) *TemplateManagerConfig {
+	t.Helper()
+	_, subnet, _ := net.ParseCIDR("10.160.0.0/30")
+	cfg := &TemplateManagerConfig{
+		Subnet: config.IPNet{IPNet: subnet},
+		// the test binary itself is an executable
+		EnvdPath:             os.Args[0],
+		HypervisorBinaryPath: os.Args[0],
+		DataRoot:             t.TempDir(),
+		MTU:                  1500,
+		SocketDir:            t.TempDir(),
+		VMTemplate: config.VMTemplate{
+			TemplateID:    "default",
+			VCpuCount:     1,
+			MemoryMB:      512,
+			DiskSizeMB:    1024,
+			KernelVersion: consts.DefaultKernelVersion,
+			VmmType:       config.FIRECRACKER,
+			TargetArch:    config.TargetArch(runtime.GOARCH),
+		},
+	}
+	kernel := cfg.HostKernelPath(cfg.DataRoot)
+	if err := os.MkdirAll(filepath.Dir(kernel), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(kernel, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestLint(t *testing.T) {
+	cfg := newLintConfig(t)
+	if problems := cfg.Lint(); len(problems) != 0 {
+		t.Fatalf("expect no problem, got %v", problems)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expect valid, got %s", err)
+	}
+
+	cfg.VCpuCount = 0
+	cfg.EnvdPath = "/not-exist/envd"
+	cfg.MTU = 1
+	cfg.KernelVersion = "not-exist"
+	_, cfg.Subnet.IPNet, _ = net.ParseCIDR("10.160.0.0/31")
+	var fields []string
+	for _, problem := range cfg.Lint() {
+		fields = append(fields, problem.Field)
+	}
+	expected := []string{
+		"template.default",
+		"template_manager.envd_path",
+		"template_manager.subnet",
+		"mtu",
+		"template.default.kernel_version",
+	}
+	if !slices.Equal(fields, expected) {
+		t.Fatalf("expect problems of %v, got %v", expected, fields)
+	}
+	// only the first problem is returned by Validate
+	if err := cfg.Validate(); err != config.InvalidVcpuCount {
+		t.Fatalf("expect invalid vcpu count, got %v", err)
+	}
+}
+
+func TestLintDataRoot(t *testing.T) {
+	cfg := newLintConfig(t)
+	cfg.DataRoot = filepath.Join(cfg.DataRoot, "not-exist")
+	problems := cfg.Lint()
+	if len(problems) == 0 || problems[0].Field != "data_root" {
+		t.Fatalf("expect data_root not exist, got %v", problems)
+	}
+	// the build creates it if not exists
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("expect valid, got %s", err)
+	}
+}
+
+func TestBuildResources(t *testing.T) {
+	cfg := newLintConfig(t)
+	if quota, mem := cfg.buildCPUQuota(), cfg.buildMemoryMB(); quota != buildCPUPeriod || mem != 512 {
+		t.Fatalf("expect the resources of vm by default, got quota %d and memory %d MiB", quota, mem)
+	}
+
+	cfg.BuildCpuQuota = 8
+	cfg.BuildMemoryMB = 4096
+	if quota, mem := cfg.buildCPUQuota(), cfg.buildMemoryMB(); quota != 8*buildCPUPeriod || mem != 4096 {
+		t.Fatalf("expect the overrides, got quota %d and memory %d MiB", quota, mem)
+	}
+
+	cfg.BuildCpuQuota = -1
+	cfg.BuildMemoryMB = -1
+	var fields []string
+	for _, problem := range cfg.Lint() {
+		fields = append(fields, problem.Field)
+	}
+	expected := []string{"template_manager.build_cpu_quota", "template_manager.build_memory_mb"}
+	if !slices.Equal(fields, expected) {
+		t.Fatalf("expect problems of %v, got %v", expected, fields)
+	}
+}
+
+func TestPruneOptions(t *testing.T) {
+	cfg := newLintConfig(t)
+	if !cfg.PruneAfterBuild() || cfg.pruneCacheTimeout() != defaultPruneCacheTimeout {
+		t.Fatalf("expect pruning after %s by default", defaultPruneCacheTimeout)
+	}
+	disabled := false
+	cfg.PruneAfterBuildEnabled = &disabled
+	if cfg.PruneAfterBuild() {
+		t.Fatal("expect pruning disabled")
+	}
+
+	cfg.PruneCacheTimeout = "1d"
+	problems := cfg.Lint()
+	if len(problems) != 1 || problems[0].Field != "template_manager.prune_cache_timeout" {
+		t.Fatalf("expect invalid prune_cache_timeout, got %v", problems)
+	}
+	cfg.PruneCacheTimeout = "24h"
+	if problems := cfg.Lint(); len(problems) != 0 {
+		t.Fatalf("expect no problem, got %v", problems)
+	}
+}
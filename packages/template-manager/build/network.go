@@ -2,11 +2,13 @@ package build
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 	"github.com/X-code-interpreter/sandbox-backend/packages/template-manager/constants"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -21,10 +23,26 @@ func NewNetworkEnvForSnapshot(
 	var err error
 	// id and sandboxID here is meaningless, we just set some dummy values.
 	// BTW, the orchestrator will use idx started from 1, so 0 here is safe.
-	netEnv := network.NewNetworkEnv(0, c.Subnet.IPNet)
+	netEnv := network.NewNetworkEnv(0, c.Subnet.IPNet).WithMTU(c.MTU)
 	net := network.NewSandboxNetwork(netEnv, constants.NetnsNamePrefix+c.TemplateID)
 
 	err = net.StartConfigure()
+	if err != nil && c.ForceReclaimNetwork && network.IsLeftover(err) {
+		// the netns is left by a crashed build
+		telemetry.ReportError(childCtx, fmt.Errorf("reclaim leaked template network: %w", err))
+		reclaimErr := net.Reclaim()
+		if errors.Is(reclaimErr, network.ErrNetnsInUse) {
+			// e.g., another build of the same template is running
+			err = fmt.Errorf("%w (%w)", err, reclaimErr)
+		} else {
+			if reclaimErr != nil {
+				telemetry.ReportEvent(childCtx, "reclaim template network partially failed",
+					attribute.String("error", reclaimErr.Error()),
+				)
+			}
+			err = net.StartConfigure()
+		}
+	}
 	defer func() {
 		if endCfgErr := net.EndConfigure(); endCfgErr != nil {
 			errMsg := fmt.Errorf("end network configuration err: %w", endCfgErr)
@@ -0,0 +1,85 @@
+package build
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// The buffer between the rootfs tar streamed from the container and the
+// converter. The body of docker response is only read when the converter
+// asks for more, so the memory used is bounded by it however slow the
+// converter is.
+const rootfsTarBufferSize = 1 << ToMBShift
+
+// Count the bytes of the rootfs tar read by the converter, and report the
+// progress every interval (0 means never), with the ETA estimated from the
+// expected size (i.e., the size of container, 0 means unknown).
+type tarProgressReader struct {
+	ctx      context.Context
+	reader   io.Reader
+	total    int64
+	interval time.Duration
+
+	read       int64
+	start      time.Time
+	lastReport time.Time
+}
+
+func newTarProgressReader(ctx context.Context, reader io.Reader, total int64, interval time.Duration) *tarProgressReader {
+	now := time.Now()
+	return &tarProgressReader{
+		ctx:        ctx,
+		reader:     reader,
+		total:      total,
+		interval:   interval,
+		start:      now,
+		lastReport: now,
+	}
+}
+
+func (p *tarProgressReader) Read(b []byte) (int, error) {
+	n, err := p.reader.Read(b)
+	p.read += int64(n)
+	if p.interval > 0 && time.Since(p.lastReport) >= p.interval {
+		p.lastReport = time.Now()
+		p.report("rootfs tar progress", false)
+	}
+	return n, err
+}
+
+// The percent (capped at 99 before finished, as the tar is a little larger
+// than the files because of the headers) and the ETA of reading the tar,
+// both are negative when the total is unknown or nothing is read yet.
+func estimateTarProgress(read, total int64, elapsed time.Duration) (float64, time.Duration) {
+	if total <= 0 || read <= 0 {
+		return -1, -1
+	}
+	percent := min(float64(read)*100/float64(total), 99)
+	remaining := max(total-read, 0)
+	return percent, time.Duration(float64(elapsed) * float64(remaining) / float64(read))
+}
+
+// Report the bytes read so far, and the estimated progress if not done.
+func (p *tarProgressReader) report(name string, done bool) {
+	elapsed := time.Since(p.start)
+	attrs := []attribute.KeyValue{
+		attribute.Int64("rootfs.tar_read_mb", p.read>>ToMBShift),
+		attribute.Int64("rootfs.elapsed_s", int64(elapsed.Seconds())),
+	}
+	if done {
+		telemetry.ReportEvent(p.ctx, name, attrs...)
+		return
+	}
+	if percent, eta := estimateTarProgress(p.read, p.total, elapsed); percent >= 0 {
+		attrs = append(attrs,
+			attribute.Int64("rootfs.size_mb", p.total>>ToMBShift),
+			attribute.Float64("rootfs.percent", percent),
+			attribute.Int64("rootfs.eta_s", int64(eta.Seconds())),
+		)
+	}
+	telemetry.ReportEvent(p.ctx, name, attrs...)
+}
@@ -0,0 +1,42 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestEstimateTarProgress(t *testing.T) {
+	if percent, eta := estimateTarProgress(10, 0, time.Second); percent >= 0 || eta >= 0 {
+		t.Fatalf("expect unknown progress without total, got %v and %v", percent, eta)
+	}
+	if percent, eta := estimateTarProgress(0, 100, time.Second); percent >= 0 || eta >= 0 {
+		t.Fatalf("expect unknown progress before reading, got %v and %v", percent, eta)
+	}
+	percent, eta := estimateTarProgress(25, 100, 10*time.Second)
+	if percent != 25 || eta != 30*time.Second {
+		t.Fatalf("expect 25%% with 30s left, got %v and %v", percent, eta)
+	}
+	// the tar is larger than the container because of the headers
+	percent, eta = estimateTarProgress(120, 100, 10*time.Second)
+	if percent != 99 || eta != 0 {
+		t.Fatalf("expect 99%% with nothing left, got %v and %v", percent, eta)
+	}
+}
+
+func TestTarProgressReader(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 4096)
+	progress := newTarProgressReader(context.Background(), bytes.NewReader(content), int64(len(content)), time.Nanosecond)
+	b, err := io.ReadAll(progress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b, content) || progress.read != int64(len(content)) {
+		t.Fatalf("expect %d bytes read, got %d (counted %d)", len(content), len(b), progress.read)
+	}
+	if !progress.lastReport.After(progress.start) {
+		t.Fatal("expect the progress reported")
+	}
+}
@@ -41,7 +41,7 @@ User=root
 Group=root
 Environment=GOTRACEBACK=all
 LimitCORE=infinity
-ExecStart=/bin/bash -l -c "/usr/bin/envd"
+ExecStart=/bin/bash -l -c "/usr/bin/envd -port {{ .EnvdPort }}"
 OOMPolicy=continue
 OOMScoreAdjust=-1000
 
@@ -107,6 +107,28 @@ mkdir -p /home/user
 mkdir -p /overlay
 mkdir -p /rom
 
+# The rootfs is mounted read-only when immutable, the init mounts a tmpfs and
+# puts the writable dirs on it (as the upper dirs of overlayfs, so that their
+# content in the rootfs is kept), then starts the actual init.
+{{ if .ImmutableRootfs -}}
+cat <<'EOF' >{{ .ImmutableInitPath }}
+#!/bin/sh
+set -e
+/bin/mount -n -t tmpfs -o noatime,mode=0755 tmpfs /overlay
+for dir in /etc /var /root /home; do
+	mkdir -p "/overlay$dir/upper" "/overlay$dir/work"
+	chown --reference="$dir" "/overlay$dir/upper"
+	chmod --reference="$dir" "/overlay$dir/upper"
+	/bin/mount -n -t overlay \
+		-o "noatime,lowerdir=$dir,upperdir=/overlay$dir/upper,workdir=/overlay$dir/work" \
+		overlay "$dir"
+done
+/bin/mount -n -t tmpfs -o noatime,mode=1777 tmpfs /tmp
+exec /usr/sbin/init "$@"
+EOF
+chmod 755 {{ .ImmutableInitPath }}
+{{ end -}}
+
 chmod 777 -R /home/user
 chmod 777 -R /usr/local
 chmod 777 -R /code
@@ -115,12 +137,35 @@ chmod 777 -R /code
 # It may be because of the way we are starting the FC VM?
 
 # Add DNS.
-echo "nameserver 8.8.8.8" >/etc/resolv.conf
+: >/etc/resolv.conf
+{{ range .GuestDNS -}}
+echo "nameserver {{ . }}" >>/etc/resolv.conf
+{{ end -}}
 
 # Start systemd services
 systemctl enable envd
 systemctl enable chrony 2>&1
 
+# Configure the ipv6 address of guest (the ipv4 one is configured by kernel args).
+{{ if .IPv6 -}}
+cat <<EOF >/etc/systemd/system/ipv6-addr.service
+[Unit]
+Description=Configure IPv6 Address
+Before=network-online.target envd.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/sbin/ip -6 addr replace {{ .GuestIPv6CIDR }} dev {{ .GuestIface }} nodad
+ExecStart=/sbin/ip -6 route replace default via {{ .TapIPv6 }} dev {{ .GuestIface }}
+
+[Install]
+WantedBy=multi-user.target
+EOF
+
+ln -s /etc/systemd/system/ipv6-addr.service /etc/systemd/system/multi-user.target.wants/ipv6-addr.service
+{{ end -}}
+
 # Add start command service if the start command is not empty.
 {{ if .StartCmd -}}
 cat <<EOF >/etc/systemd/system/start_cmd.service
@@ -151,4 +196,21 @@ EOF
 systemctl enable start_cmd
 {{ end -}}
 
+# Run the extra provision script of the template at last, so that it can
+# rely on (and customize) the mandatory setup above.
+{{ if .ExtraProvisionScript -}}
+echo "Running extra provision script."
+cat <<'EXTRA_PROVISION_SCRIPT_EOF' >/tmp/extra-provision.sh
+{{ .ExtraProvisionScript }}
+EXTRA_PROVISION_SCRIPT_EOF
+# the stderr is printed after the script exits, so that the
+# template manager can collect it from the tail of the container logs.
+if ! /bin/bash -e /tmp/extra-provision.sh 2>/tmp/extra-provision.stderr; then
+	cat /tmp/extra-provision.stderr >&2
+	exit 1
+fi
+cat /tmp/extra-provision.stderr >&2
+rm -f /tmp/extra-provision.sh /tmp/extra-provision.stderr
+{{ end -}}
+
 echo "Finished provisioning script"
@@ -0,0 +1,111 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// the timeout of each probe, so that an unresponsive guest
+// does not take the whole timeout of probe
+const readinessAttemptTimeout = 5 * time.Second
+
+// The url polled by probe, the file probe is checked by downloading
+// the file through envd (listening on envdPort).
+func readinessURL(probe *config.ReadinessProbe, envdPort int64) string {
+	if probe.FilePath != "" {
+		return fmt.Sprintf("http://%s/file?%s",
+			guestAddr(envdPort),
+			url.Values{"path": {probe.FilePath}}.Encode(),
+		)
+	}
+	port := probe.HTTPPort
+	if port == 0 {
+		port = envdPort
+	}
+	return fmt.Sprintf("http://%s%s", guestAddr(port), probe.HTTPPath)
+}
+
+func guestAddr(port int64) string {
+	return consts.GuestNetIPAddr + ":" + strconv.FormatInt(port, 10)
+}
+
+// Poll the probe until it succeeds, or fail after the timeout of probe.
+// The guest is reached through the netns of the build, as there
+// is no veth between it and host.
+func waitForReadiness(
+	ctx context.Context,
+	tracer trace.Tracer,
+	net *network.SandboxNetwork,
+	probe *config.ReadinessProbe,
+	envdPort int64,
+) error {
+	childCtx, childSpan := tracer.Start(ctx, "wait-for-readiness")
+	defer childSpan.End()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:       net.DialContext,
+			DisableKeepAlives: true,
+		},
+		Timeout: readinessAttemptTimeout,
+	}
+	start := time.Now()
+	attempts, err := pollReadiness(childCtx, client, readinessURL(probe, envdPort), probe.Interval(), probe.Timeout())
+	if err != nil {
+		telemetry.ReportCriticalError(childCtx, err)
+		return err
+	}
+	telemetry.ReportEvent(childCtx, "guest is ready",
+		attribute.Int("attempts", attempts),
+		attribute.Float64("seconds", time.Since(start).Seconds()),
+	)
+	return nil
+}
+
+// GET url every interval until it returns 2xx, return the number of attempts.
+func pollReadiness(ctx context.Context, client *http.Client, url string, interval, timeout time.Duration) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+	for attempts := 1; ; attempts++ {
+		lastErr = probeOnce(ctx, client, url)
+		if lastErr == nil {
+			return attempts, nil
+		}
+		select {
+		case <-ctx.Done():
+			return attempts, fmt.Errorf("not ready after %s (%d attempts): %w", timeout, attempts, lastErr)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func probeOnce(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s of %s", resp.Status, url)
+	}
+	return nil
+}
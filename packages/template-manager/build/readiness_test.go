@@ -0,0 +1,57 @@
+package build
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+)
+
+func TestReadinessURL(t *testing.T) {
+	testCases := []struct {
+		probe  config.ReadinessProbe
+		expect string
+	}{
+		{config.ReadinessProbe{HTTPPath: "/health", HTTPPort: 8080}, "http://169.254.0.21:8080/health"},
+		{config.ReadinessProbe{HTTPPath: "/health"}, "http://169.254.0.21:49982/health"},
+		{config.ReadinessProbe{FilePath: "/tmp/ready file"}, "http://169.254.0.21:49982/file?path=%2Ftmp%2Fready+file"},
+	}
+	for _, tc := range testCases {
+		if got := readinessURL(&tc.probe, consts.DefaultEnvdServerPort); got != tc.expect {
+			t.Fatalf("expect %s, got %s", tc.expect, got)
+		}
+	}
+	// the custom envd port of template
+	probe := config.ReadinessProbe{HTTPPath: "/health"}
+	if got := readinessURL(&probe, 50000); got != "http://169.254.0.21:50000/health" {
+		t.Fatalf("expect the envd port of template, got %s", got)
+	}
+}
+
+func TestPollReadiness(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	attempts, err := pollReadiness(context.Background(), server.Client(), server.URL, 10*time.Millisecond, time.Second)
+	if err != nil {
+		t.Fatalf("poll readiness failed: %s", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("expect ready at the 3rd attempt, got %d", attempts)
+	}
+
+	requests.Store(-1000)
+	if _, err := pollReadiness(context.Background(), server.Client(), server.URL, 10*time.Millisecond, 100*time.Millisecond); err == nil {
+		t.Fatalf("expect not ready after timeout")
+	}
+}
@@ -2,6 +2,7 @@ package build
 
 import (
 	"archive/tar"
+	"bufio"
 	"bytes"
 	"context"
 	_ "embed"
@@ -21,6 +22,7 @@ import (
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/image"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
 	"github.com/docker/docker/pkg/stdcopy"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"go.opentelemetry.io/otel/attribute"
@@ -31,7 +33,11 @@ const (
 	ToMBShift int64 = 20
 	// Max size of the rootfs file in MB.
 	maxRootfsSize = 15000 << ToMBShift
-	cacheTimeout  = "48h"
+	// The label of the containers created by the build, only the containers
+	// with it are pruned after build.
+	buildLabel = "sandbox-backend.template-manager"
+	// The number of stderr lines attached to the error when provisioning failed.
+	containerStderrTailLines = "50"
 )
 
 //go:embed overlay-init
@@ -44,6 +50,13 @@ var EnvInstanceTemplate = text_template.Must(text_template.New("provisioning-scr
 type Rootfs struct {
 	docker *client.Client
 	cfg    *TemplateManagerConfig
+	// what the rootfs is built from
+	cacheMeta rootfsCacheMeta
+	// whether the rootfs is reused from cache instead of built
+	Cached bool
+	// the image the docker tag referred to before pulled, which is left
+	// dangling (and removed after build) as the pull moved the tag
+	replacedImageID string
 }
 
 func NewRootfs(ctx context.Context, tracer trace.Tracer, docker *client.Client, c *TemplateManagerConfig) (*Rootfs, error) {
@@ -65,7 +78,30 @@ func NewRootfs(ctx context.Context, tracer trace.Tracer, docker *client.Client,
 		}
 	}
 
-	err := rootfs.createRootfsFile(childCtx, tracer)
+	meta, err := rootfs.resolveCacheMeta(childCtx)
+	if err != nil {
+		errMsg := fmt.Errorf("error resolving rootfs cache key: %w", err)
+		return nil, errMsg
+	}
+	rootfs.cacheMeta = meta
+	if !c.ForceRebuild && c.rootfsCacheHit(meta) {
+		telemetry.ReportEvent(childCtx, "rootfs cache hit",
+			attribute.String("image_digest", meta.ImageDigest),
+			attribute.String("provision_hash", meta.ProvisionHash),
+		)
+		rootfs.Cached = true
+		// the cache is already up to date when build rootfs only
+		if c.RootfsBuildMode == BuildRootfsOnly {
+			return rootfs, nil
+		}
+		if err := c.prepareRootfsFromCache(childCtx, tracer); err != nil {
+			errMsg := fmt.Errorf("error preparing rootfs from cache: %w", err)
+			return nil, errMsg
+		}
+		return rootfs, nil
+	}
+
+	err = rootfs.createRootfsFile(childCtx, tracer)
 	if err != nil {
 		errMsg := fmt.Errorf("error creating rootfs file: %w", err)
 		return nil, errMsg
@@ -79,8 +115,13 @@ func (r *Rootfs) pullDockerImage(ctx context.Context, tracer trace.Tracer) error
 	childCtx, childSpan := tracer.Start(ctx, "pull-docker-image")
 	defer childSpan.End()
 
+	var previousID string
+	if inspect, _, err := r.docker.ImageInspectWithRaw(childCtx, r.dockerTag()); err == nil {
+		previousID = inspect.ID
+	}
+
 	logs, err := r.docker.ImagePull(childCtx, r.dockerTag(), image.PullOptions{
-		Platform: "linux/amd64",
+		Platform: r.cfg.DockerPlatform(),
 	})
 	if err != nil {
 		errMsg := fmt.Errorf("error pulling image: %w", err)
@@ -107,6 +148,14 @@ func (r *Rootfs) pullDockerImage(ctx context.Context, tracer trace.Tracer) error
 
 	telemetry.ReportEvent(childCtx, "pulled image")
 
+	if inspect, _, err := r.docker.ImageInspectWithRaw(childCtx, r.dockerTag()); err == nil &&
+		previousID != "" && inspect.ID != previousID {
+		r.replacedImageID = previousID
+		telemetry.ReportEvent(childCtx, "pulled image replaced the previous one",
+			attribute.String("previous_image_id", previousID),
+		)
+	}
+
 	return nil
 }
 
@@ -117,18 +166,8 @@ func (r *Rootfs) dockerTag() string {
 	return r.cfg.DockerImage
 }
 
-// This is a complex function
-// it will
-//  1. create a docker container with base image
-//  2. the container will execute the intialized process as in provision.sh,
-//     including populate the necessary systemd service.
-//  3. use docker CopyFromContainer, dumping the container root image, which will
-//     be used by firecracker.
-func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) error {
-	childCtx, childSpan := tracer.Start(ctx, "create-rootfs-file")
-	defer childSpan.End()
-
-	var err error
+// Render the provision script (provision.sh) with the template config.
+func (r *Rootfs) provisionScript() (string, error) {
 	var scriptDef bytes.Buffer
 
 	// we only pass constants.StartCmdEnvFilePath
@@ -137,22 +176,108 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 	if len(r.cfg.StartCmd.EnvFilePath) > 0 {
 		startCmdEnvFilePath = constants.StartCmdEnvFilePath
 	}
-	err = EnvInstanceTemplate.Execute(&scriptDef, struct {
+	extraProvisionScript := ""
+	if r.cfg.ProvisionScriptPath != "" {
+		content, err := os.ReadFile(r.cfg.ProvisionScriptPath)
+		if err != nil {
+			return "", fmt.Errorf("error reading provision script %s: %w", r.cfg.ProvisionScriptPath, err)
+		}
+		extraProvisionScript = string(content)
+	}
+	err := EnvInstanceTemplate.Execute(&scriptDef, struct {
 		TemplateID               string
 		StartCmd                 string
 		StartCmdEnvFilePath      string
 		StartCmdWorkingDirectory string
+		IPv6                     bool
+		GuestIPv6CIDR            string
+		TapIPv6                  string
+		GuestIface               string
+		GuestDNS                 []string
+		EnvdPort                 int64
+		ImmutableRootfs          bool
+		ImmutableInitPath        string
+		ExtraProvisionScript     string
 	}{
 		TemplateID:               r.cfg.TemplateID,
 		StartCmd:                 strings.ReplaceAll(r.cfg.StartCmd.Cmd, "\"", "\\\""),
 		StartCmdEnvFilePath:      startCmdEnvFilePath,
 		StartCmdWorkingDirectory: r.cfg.StartCmd.WorkingDir,
+		IPv6:                     r.cfg.IPv6,
+		GuestIPv6CIDR:            fmt.Sprintf("%s/%d", consts.GuestNetIPv6Addr, consts.GuestNetIPv6Mask),
+		TapIPv6:                  consts.HostTapIPv6Address,
+		GuestIface:               consts.GuestIfaceName,
+		GuestDNS:                 r.cfg.GuestDNSServers(),
+		EnvdPort:                 r.cfg.EnvdServerPort(),
+		ImmutableRootfs:          r.cfg.ImmutableRootfs,
+		ImmutableInitPath:        constants.ImmutableInitPath,
+		ExtraProvisionScript:     extraProvisionScript,
 	})
 	if err != nil {
-		errMsg := fmt.Errorf("error executing provision script: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
+		return "", fmt.Errorf("error executing provision script: %w", err)
+	}
+	return scriptDef.String(), nil
+}
 
-		return errMsg
+// Prune the build cache and the stopped containers created by builds, which
+// are older than the prune_cache_timeout, and the image replaced when pulling.
+// The containers of others are not affected as they do not have buildLabel.
+func (r *Rootfs) pruneAfterBuild(ctx context.Context) {
+	untilArg := filters.Arg("until", r.cfg.pruneCacheTimeout())
+	_, err := r.docker.BuildCachePrune(ctx, types.BuildCachePruneOptions{
+		Filters: filters.NewArgs(untilArg),
+		All:     true,
+	})
+	if err != nil {
+		errMsg := fmt.Errorf("error pruning build cache: %w", err)
+		telemetry.ReportError(ctx, errMsg)
+	} else {
+		telemetry.ReportEvent(ctx, "pruned build cache")
+	}
+
+	// the images are pulled rather than built, so they cannot be labeled
+	if r.replacedImageID != "" {
+		_, err := r.docker.ImageRemove(ctx, r.replacedImageID, image.RemoveOptions{PruneChildren: true})
+		if errdefs.IsConflict(err) {
+			// still used by other containers or tags
+			telemetry.ReportEvent(ctx, "skipped removing the replaced image in use",
+				attribute.String("image_id", r.replacedImageID),
+			)
+		} else if err != nil && !errdefs.IsNotFound(err) {
+			errMsg := fmt.Errorf("error removing replaced image %s: %w", r.replacedImageID, err)
+			telemetry.ReportError(ctx, errMsg)
+		} else {
+			telemetry.ReportEvent(ctx, "removed replaced image",
+				attribute.String("image_id", r.replacedImageID),
+			)
+		}
+	}
+
+	args := filters.NewArgs(untilArg, filters.Arg("label", buildLabel))
+	if _, err := r.docker.ContainersPrune(ctx, args); err != nil {
+		errMsg := fmt.Errorf("error pruning containers: %w", err)
+		telemetry.ReportError(ctx, errMsg)
+	} else {
+		telemetry.ReportEvent(ctx, "pruned containers")
+	}
+}
+
+// This is a complex function
+// it will
+//  1. create a docker container with base image
+//  2. the container will execute the intialized process as in provision.sh,
+//     including populate the necessary systemd service.
+//  3. use docker CopyFromContainer, dumping the container root image, which will
+//     be used by firecracker.
+func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) error {
+	childCtx, childSpan := tracer.Start(ctx, "create-rootfs-file")
+	defer childSpan.End()
+
+	script, err := r.provisionScript()
+	if err != nil {
+		telemetry.ReportCriticalError(childCtx, err)
+
+		return err
 	}
 
 	telemetry.ReportEvent(childCtx, "executed provision script env")
@@ -163,10 +288,11 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 		Image:        r.dockerTag(),
 		Entrypoint:   []string{"/bin/bash", "-c"},
 		User:         "root",
-		Cmd:          []string{scriptDef.String()},
+		Cmd:          []string{script},
 		Tty:          false,
 		AttachStdout: true,
 		AttachStderr: true,
+		Labels:       map[string]string{buildLabel: r.cfg.TemplateID},
 		// TODO(huang-jl) provide option to setup proxy
 		// Env: []string{"https_proxy=http://172.17.0.1:7890", "http_proxy=http://172.17.0.1:7890"},
 	}, &container.HostConfig{
@@ -176,13 +302,13 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 		// TODO: Network mode is causing problems with /etc/hosts - we want to find a way to fix this and enable network mode again
 		// NetworkMode: container.NetworkMode(network.ID),
 		Resources: container.Resources{
-			Memory:     r.cfg.MemoryMB << ToMBShift,
-			CPUPeriod:  100000,
-			CPUQuota:   r.cfg.VCpuCount * 100000,
-			MemorySwap: r.cfg.MemoryMB << ToMBShift,
+			Memory:     r.cfg.buildMemoryMB() << ToMBShift,
+			CPUPeriod:  buildCPUPeriod,
+			CPUQuota:   r.cfg.buildCPUQuota(),
+			MemorySwap: r.cfg.buildMemoryMB() << ToMBShift,
 			PidsLimit:  &pidsLimit,
 		},
-	}, nil, &v1.Platform{}, "")
+	}, nil, &v1.Platform{OS: "linux", Architecture: string(r.cfg.Arch())}, "")
 	if err != nil {
 		errMsg := fmt.Errorf("error creating container: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
@@ -211,34 +337,8 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 				telemetry.ReportEvent(cleanupContext, "removed container")
 			}
 
-			// Move prunning to separate goroutine
-			cacheTimeoutArg := filters.Arg("until", cacheTimeout)
-
-			_, pruneErr := r.docker.BuildCachePrune(cleanupContext, types.BuildCachePruneOptions{
-				Filters: filters.NewArgs(cacheTimeoutArg),
-				All:     true,
-			})
-			if pruneErr != nil {
-				errMsg := fmt.Errorf("error pruning build cache: %w", pruneErr)
-				telemetry.ReportError(cleanupContext, errMsg)
-			} else {
-				telemetry.ReportEvent(cleanupContext, "pruned build cache")
-			}
-
-			_, pruneErr = r.docker.ImagesPrune(cleanupContext, filters.NewArgs(cacheTimeoutArg))
-			if pruneErr != nil {
-				errMsg := fmt.Errorf("error pruning images: %w", pruneErr)
-				telemetry.ReportError(cleanupContext, errMsg)
-			} else {
-				telemetry.ReportEvent(cleanupContext, "pruned images")
-			}
-
-			_, pruneErr = r.docker.ContainersPrune(cleanupContext, filters.NewArgs(cacheTimeoutArg))
-			if pruneErr != nil {
-				errMsg := fmt.Errorf("error pruning containers: %w", pruneErr)
-				telemetry.ReportError(cleanupContext, errMsg)
-			} else {
-				telemetry.ReportEvent(cleanupContext, "pruned containers")
+			if r.cfg.PruneAfterBuild() {
+				r.pruneAfterBuild(cleanupContext)
 			}
 		}()
 	}()
@@ -395,7 +495,8 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 
 	telemetry.ReportEvent(childCtx, "waited for container exit")
 
-	inspection, err := r.docker.ContainerInspect(ctx, cont.ID)
+	// with the size, which is the expected size of the rootfs tar
+	inspection, _, err := r.docker.ContainerInspectWithRaw(ctx, cont.ID, true)
 	if err != nil {
 		errMsg := fmt.Errorf("error inspecting container: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
@@ -414,6 +515,11 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 
 	if inspection.State.ExitCode != 0 {
 		errMsg := fmt.Errorf("container exited with status %d: %s", inspection.State.ExitCode, inspection.State.Error)
+		// the xtrace of provision script goes to stdout, so the stderr
+		// only contains the real errors (e.g., of the extra provision script)
+		if stderr := r.containerStderrTail(ctx, cont.ID); stderr != "" {
+			errMsg = fmt.Errorf("%w, stderr:\n%s", errMsg, stderr)
+		}
 		telemetry.ReportCriticalError(
 			childCtx,
 			errMsg,
@@ -457,13 +563,25 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 	if downloadErr != nil {
 		errMsg := fmt.Errorf("error downloading from container: %w", downloadErr)
 		telemetry.ReportCriticalError(childCtx, errMsg)
+
+		return errMsg
 	}
 	telemetry.ReportEvent(childCtx, "downloaded from container")
 	defer rootTar.Close()
 
+	var rootfsSize int64
+	if inspection.SizeRootFs != nil {
+		rootfsSize = *inspection.SizeRootFs
+	}
+	progress := newTarProgressReader(childCtx, rootTar, rootfsSize, r.cfg.tarProgressInterval())
+
 	// This package creates a read-only ext4 filesystem from a tar archive.
 	// We need to use another program to make the filesystem writable.
-	err = tar2ext4.ConvertTarToExt4(rootTar, rootfsFile, tar2ext4.MaximumDiskSize(maxRootfsSize))
+	err = tar2ext4.ConvertTarToExt4(
+		bufio.NewReaderSize(progress, rootfsTarBufferSize),
+		rootfsFile,
+		tar2ext4.MaximumDiskSize(maxRootfsSize),
+	)
 	if err != nil {
 		errMsg := fmt.Errorf("error converting tar to ext4: %w", err)
 		telemetry.ReportCriticalError(childCtx, errMsg)
@@ -471,7 +589,7 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 		return errMsg
 	}
 
-	telemetry.ReportEvent(childCtx, "converted container tar to ext4")
+	progress.report("converted container tar to ext4", true)
 
 	if r.cfg.Overlay {
 		return r.createOverlayRootfsFile(childCtx, tracer, rootfsFile)
@@ -480,6 +598,25 @@ func (r *Rootfs) createRootfsFile(ctx context.Context, tracer trace.Tracer) erro
 	}
 }
 
+// Return the last lines of the stderr of an exited container,
+// empty if failed to get the logs.
+func (r *Rootfs) containerStderrTail(ctx context.Context, containerID string) string {
+	logs, err := r.docker.ContainerLogs(ctx, containerID, container.LogsOptions{
+		ShowStderr: true,
+		Tail:       containerStderrTailLines,
+	})
+	if err != nil {
+		telemetry.ReportError(ctx, fmt.Errorf("error getting container stderr: %w", err))
+		return ""
+	}
+	defer logs.Close()
+	var stderr bytes.Buffer
+	if _, err := stdcopy.StdCopy(io.Discard, &stderr, logs); err != nil {
+		telemetry.ReportError(ctx, fmt.Errorf("error copy container stderr: %w", err))
+	}
+	return strings.TrimSpace(stderr.String())
+}
+
 // Create single rootfs file for firecracker
 //
 // @rootfsFile: the rootfs file for rootfs
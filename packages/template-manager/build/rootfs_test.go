@@ -25,13 +25,23 @@ func TestProvision(t *testing.T) {
 	}
 
 	err = EnvInstanceTemplate.Execute(&scriptDef, struct {
-		TemplateID          string
-		StartCmd            string
-		StartCmdEnvFilePath string
+		TemplateID           string
+		StartCmd             string
+		StartCmdEnvFilePath  string
+		IPv6                 bool
+		GuestDNS             []string
+		ExtraProvisionScript string
+		EnvdPort             int64
+		ImmutableRootfs      bool
+		ImmutableInitPath    string
 	}{
 		TemplateID:          cfg.TemplateID,
 		StartCmd:            strings.ReplaceAll(cfg.StartCmd.Cmd, "\"", "\\\""),
 		StartCmdEnvFilePath: constants.StartCmdEnvFilePath,
+		GuestDNS:            cfg.GuestDNSServers(),
+		EnvdPort:            cfg.EnvdServerPort(),
+		ImmutableRootfs:     cfg.ImmutableRootfs,
+		ImmutableInitPath:   constants.ImmutableInitPath,
 	})
 	if err != nil {
 		t.Fatal("error executing provision script: %w", err)
@@ -6,13 +6,15 @@ import (
 	"fmt"
 	"net"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/KarpelesLab/reflink"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/config"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/hypervisor"
+	"github.com/X-code-interpreter/sandbox-backend/packages/shared/network"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/telemetry"
 	"github.com/X-code-interpreter/sandbox-backend/packages/shared/utils"
 	"github.com/docker/docker/client"
@@ -26,10 +28,42 @@ type TemplateManagerConfig struct {
 	RootfsBuildMode   RootfsBuildMode `toml:"rootfs_build_mode"`
 	TemplateToBuild   string          `toml:"template_id"`
 	EnvdPath          string          `toml:"envd_path"`
+	// the cpus and memory of the container building the rootfs, which can be
+	// more than the vm (e.g., to speed up the provision), 0 means the same
+	// as the vcpu_count and memory_mb of template.
+	BuildCpuQuota int64 `toml:"build_cpu_quota"`
+	BuildMemoryMB int64 `toml:"build_memory_mb"`
+	// prune the build cache, the containers created by builds and the image
+	// replaced by pulling after build, nil means true. Only the cache and
+	// containers older than the prune_cache_timeout (e.g., "48h", empty
+	// means the default) are pruned.
+	PruneAfterBuildEnabled *bool  `toml:"prune_after_build"`
+	PruneCacheTimeout      string `toml:"prune_cache_timeout"`
+	// the max attempts of creating the snapshot, which is verified (e.g., the
+	// memfile is not truncated) after created, 0 means the default (i.e., 3).
+	SnapshotAttempts int `toml:"snapshot_attempts"`
+	// how often the progress of converting the rootfs tar (exported from the
+	// container) into ext4 is reported, e.g., "10s" (empty means the default),
+	// "0s" means never.
+	TarProgressInterval string `toml:"tar_progress_interval"`
+
+	// rebuild the rootfs even if the cached one is built from the
+	// same docker image and provision (see [rootfsCacheMeta])
+	ForceRebuild bool `toml:"-"`
 
 	HypervisorBinaryPath string `toml:"-"`
 	DataRoot             string `toml:"-"`
-	config.VMTemplate    `toml:"-"`
+	ForceReclaimNetwork  bool   `toml:"-"`
+	// how to wait for the api socket of vmm
+	SocketWait utils.SocketWaitOptions `toml:"-"`
+	// the free disk space (in bytes) kept when building,
+	// negative means the check is disabled.
+	DiskHeadroom int64 `toml:"-"`
+	// the mtu of the tap device
+	MTU int `toml:"-"`
+	// where the api socket of vmm is
+	SocketDir         string `toml:"-"`
+	config.VMTemplate `toml:"-"`
 }
 
 type RootfsBuildMode string
@@ -62,18 +96,59 @@ func (c *TemplateManagerConfig) CachedWritableRootfsPath() string {
 	return filepath.Join(c.TemplateDir(c.DataRoot), "cache", consts.WritableFsName)
 }
 
-func (c *TemplateManagerConfig) Validate() error {
-	if err := c.VMTemplate.Validate(); err != nil {
-		return err
+// the cpu period (in us) of the container building the rootfs
+const buildCPUPeriod = 100000
+
+// The cpu quota (in us per buildCPUPeriod) of the container building the rootfs.
+func (c *TemplateManagerConfig) buildCPUQuota() int64 {
+	cpus := c.BuildCpuQuota
+	if cpus == 0 {
+		cpus = c.VCpuCount
 	}
-	if c.DataRoot == "" {
-		return fmt.Errorf("data_root cannot be empty")
+	return cpus * buildCPUPeriod
+}
+
+// the default of prune_cache_timeout
+const defaultPruneCacheTimeout = "48h"
+
+func (c *TemplateManagerConfig) PruneAfterBuild() bool {
+	return c.PruneAfterBuildEnabled == nil || *c.PruneAfterBuildEnabled
+}
+
+func (c *TemplateManagerConfig) pruneCacheTimeout() string {
+	if c.PruneCacheTimeout == "" {
+		return defaultPruneCacheTimeout
+	}
+	return c.PruneCacheTimeout
+}
+
+// the default of tar_progress_interval
+const defaultTarProgressInterval = "10s"
+
+// The interval of reporting the progress of converting the rootfs tar,
+// which has been validated (see checks).
+func (c *TemplateManagerConfig) tarProgressInterval() time.Duration {
+	interval := c.TarProgressInterval
+	if interval == "" {
+		interval = defaultTarProgressInterval
 	}
-	if _, err := exec.LookPath(c.HypervisorBinaryPath); err != nil {
-		return fmt.Errorf("hypervisor binary %s not found: %w", c.HypervisorBinaryPath, err)
+	d, _ := time.ParseDuration(interval)
+	return d
+}
+
+// The memory (in MiB) of the container building the rootfs.
+func (c *TemplateManagerConfig) buildMemoryMB() int64 {
+	if c.BuildMemoryMB == 0 {
+		return c.MemoryMB
 	}
-	if _, err := exec.LookPath(c.EnvdPath); err != nil {
-		return fmt.Errorf("envd binary %s not found: %w", c.EnvdPath, err)
+	return c.BuildMemoryMB
+}
+
+func (c *TemplateManagerConfig) Validate() error {
+	for _, check := range c.checks() {
+		if err := check.check(); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -83,26 +158,54 @@ func (c *TemplateManagerConfig) dumpVMTemplate(ctx context.Context, tracer trace
 	childCtx, childSpan := tracer.Start(ctx, "dump-vm-template")
 	defer childSpan.End()
 
-	f, err := os.Create(c.TemplateFilePath(c.DataRoot))
-	if err != nil {
-		errMsg := fmt.Errorf("error creating template file: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
+	if err := c.VMTemplate.ComputeChecksums(c.DataRoot); err != nil {
+		telemetry.ReportCriticalError(childCtx, err)
 
-		return errMsg
+		return err
 	}
-	defer f.Close()
+	telemetry.ReportEvent(childCtx, "checksums computed")
 
-	enc := toml.NewEncoder(f)
-	if err = enc.Encode(c.VMTemplate); err != nil {
-		errMsg := fmt.Errorf("error encode template: %w", err)
-		telemetry.ReportCriticalError(childCtx, errMsg)
+	// not fatal, the template is then assumed to be compatible with all hosts
+	if cpu, err := config.ReadHostCPU(); err != nil {
+		telemetry.ReportError(childCtx, fmt.Errorf("read host cpu failed: %w", err))
+	} else {
+		c.VMTemplate.SnapshotCPU = cpu
+	}
 
-		return errMsg
+	if err := c.VMTemplate.Dump(c.DataRoot); err != nil {
+		telemetry.ReportCriticalError(childCtx, err)
+
+		return err
 	}
 
 	return nil
 }
 
+// The estimated disk space (in bytes) needed by the build, besides the content
+// of docker image (which is unknown before exporting, and left to the headroom):
+// the free disk of rootfs (or the writable rootfs if overlay), the memfile and
+// its compressed one.
+func (c *TemplateManagerConfig) buildDiskSize() int64 {
+	size := c.DiskSizeMB<<ToMBShift + c.MemoryMB<<ToMBShift
+	if c.CompressMemfile {
+		size += c.MemoryMB << ToMBShift
+	}
+	return size
+}
+
+// Fail early if the free disk space of data_root is less than
+// the estimated need of the build and the headroom.
+func (c *TemplateManagerConfig) ensureDiskSpace(ctx context.Context) error {
+	if c.DiskHeadroom < 0 {
+		return nil
+	}
+	telemetry.ReportEvent(ctx, "estimated disk space",
+		attribute.Int64("disk.build_mb", c.buildDiskSize()>>ToMBShift),
+		attribute.Int64("disk.headroom_mb", c.DiskHeadroom>>ToMBShift),
+	)
+	return utils.EnsureFreeDiskSpace(c.DataRoot, c.buildDiskSize()+c.DiskHeadroom)
+}
+
 func (c *TemplateManagerConfig) initialize(ctx context.Context, tracer trace.Tracer) error {
 	childCtx, childSpan := tracer.Start(ctx, "initialize")
 	defer childSpan.End()
@@ -186,10 +289,14 @@ func (c *TemplateManagerConfig) moveSnapshot() error {
 }
 
 // moveRootfsForCache will be used by build mode BuildRootfsOnly.
-func (c *TemplateManagerConfig) moveRootfsForCache(ctx context.Context, tracer trace.Tracer) error {
+func (c *TemplateManagerConfig) moveRootfsForCache(ctx context.Context, tracer trace.Tracer, meta rootfsCacheMeta) error {
 	childCtx, childSpan := tracer.Start(ctx, "move-rootfs-for-build-rootfs-only")
 	defer childSpan.End()
 
+	if err := c.invalidateRootfsCache(); err != nil {
+		telemetry.ReportCriticalError(childCtx, err)
+		return err
+	}
 	targetPath := c.CachedRootfsPath()
 	if err := utils.CreateDirAllIfNotExists(filepath.Dir(targetPath), 0o755); err != nil {
 		return fmt.Errorf("error creating cache dir for rootfs: %w", err)
@@ -208,6 +315,10 @@ func (c *TemplateManagerConfig) moveRootfsForCache(ctx context.Context, tracer t
 		}
 		telemetry.ReportEvent(childCtx, "moved writable rootfs")
 	}
+	if err := c.writeRootfsCacheMeta(meta); err != nil {
+		telemetry.ReportCriticalError(childCtx, err)
+		return err
+	}
 	return nil
 }
 
@@ -266,6 +377,13 @@ func (c *TemplateManagerConfig) BuildTemplate(ctx context.Context, tracer trace.
 	childCtx, childSpan := tracer.Start(ctx, "build")
 	defer childSpan.End()
 
+	if err := c.ensureDiskSpace(childCtx); err != nil {
+		errMsg := fmt.Errorf("error checking disk space for env '%s' before build: %w", c.TemplateID, err)
+		telemetry.ReportCriticalError(childCtx, errMsg)
+
+		return errMsg
+	}
+
 	err := c.initialize(childCtx, tracer)
 	if err != nil {
 		errMsg := fmt.Errorf("error initializing directories for building env '%s' during build : %w", c.TemplateID, err)
@@ -276,9 +394,10 @@ func (c *TemplateManagerConfig) BuildTemplate(ctx context.Context, tracer trace.
 
 	defer c.Cleanup(childCtx, tracer)
 
+	var rootfs *Rootfs
 	switch c.RootfsBuildMode {
 	case Normal, BuildRootfsOnly:
-		_, err = NewRootfs(childCtx, tracer, docker, c)
+		rootfs, err = NewRootfs(childCtx, tracer, docker, c)
 		if err != nil {
 			errMsg := fmt.Errorf("error creating rootfs for env '%s' during build: %w", c.TemplateID, err)
 			telemetry.ReportCriticalError(childCtx, errMsg)
@@ -298,7 +417,44 @@ func (c *TemplateManagerConfig) BuildTemplate(ctx context.Context, tracer trace.
 	}
 
 	if c.RootfsBuildMode == BuildRootfsOnly {
-		return c.moveRootfsForCache(childCtx, tracer)
+		if rootfs.Cached {
+			return nil
+		}
+		return c.moveRootfsForCache(childCtx, tracer, rootfs.cacheMeta)
+	}
+	if rootfs != nil && !rootfs.Cached {
+		// before the snapshot boots (and modifies) the rootfs
+		if err := c.saveRootfsCache(childCtx, rootfs.cacheMeta); err != nil {
+			// only the next build is slower
+			telemetry.ReportError(childCtx, fmt.Errorf("error saving rootfs cache for env '%s': %w", c.TemplateID, err))
+		}
+	}
+
+	if c.ConfigDrive {
+		// this is only a placeholder, each sandbox will
+		// have its own config drive (with the same size) when restoring.
+		err = utils.CreateConfigDrive(childCtx, c.PrivateConfigDrivePath(c.DataRoot), c.TemplateID, nil, nil)
+		if err != nil {
+			errMsg := fmt.Errorf("error creating config drive for env '%s' during build: %w", c.TemplateID, err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+
+			return errMsg
+		}
+		telemetry.ReportEvent(childCtx, "created placeholder config drive")
+	}
+
+	// same as config drive, each sandbox replaces the
+	// placeholders with its own extra disks (or placeholders) when restoring.
+	for i := 0; i < c.ExtraDiskSlots; i++ {
+		if err = utils.CreateExtraDiskPlaceholder(c.PrivateExtraDiskPath(c.DataRoot, i)); err != nil {
+			errMsg := fmt.Errorf("error creating extra disk slot for env '%s' during build: %w", c.TemplateID, err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+
+			return errMsg
+		}
+	}
+	if c.ExtraDiskSlots > 0 {
+		telemetry.ReportEvent(childCtx, "created placeholder extra disks", attribute.Int("slots", c.ExtraDiskSlots))
 	}
 
 	network, err := NewNetworkEnvForSnapshot(childCtx, tracer, c)
@@ -335,6 +491,16 @@ func (c *TemplateManagerConfig) BuildTemplate(ctx context.Context, tracer trace.
 		return errMsg
 	}
 
+	if c.CompressMemfile {
+		err = hypervisor.CompressMemfile(childCtx, c.TemplateImgDir(c.DataRoot))
+		if err != nil {
+			errMsg := fmt.Errorf("error compressing memfile while building env '%s': %w", c.TemplateID, err)
+			telemetry.ReportCriticalError(childCtx, errMsg)
+
+			return errMsg
+		}
+	}
+
 	err = c.dumpVMTemplate(childCtx, tracer)
 	if err != nil {
 		errMsg := fmt.Errorf("error dump template while building env '%s' : %w", c.TemplateID, err)
@@ -346,13 +512,27 @@ func (c *TemplateManagerConfig) BuildTemplate(ctx context.Context, tracer trace.
 	return nil
 }
 
+// the prefix of the name of the api socket of vmm
+const socketPrefix = "vmm-build-"
+
 // api-socket of vm
 func (c *TemplateManagerConfig) GetSocketPath() string {
-	socketFileName := fmt.Sprintf("vmm-build-sock-%s.sock", c.TemplateID)
-	return filepath.Join(os.TempDir(), socketFileName)
+	return utils.SocketPath(c.SocketDir, socketPrefix, c.TemplateID)
 }
 
 func ParseTemplateManagerConfig(configFile string) (*TemplateManagerConfig, error) {
+	tmConfig, err := decodeTemplateManagerConfig(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := tmConfig.Validate(); err != nil {
+		return nil, fmt.Errorf("error validating template manager config: %w", err)
+	}
+	return tmConfig, nil
+}
+
+// Decode the config file and set the defaults, without validating it.
+func decodeTemplateManagerConfig(configFile string) (*TemplateManagerConfig, error) {
 	var (
 		globalConfig struct {
 			config.CommonConfig
@@ -380,6 +560,11 @@ func ParseTemplateManagerConfig(configFile string) (*TemplateManagerConfig, erro
 		return nil, fmt.Errorf("error decoding template manager: %w", err)
 	}
 	tmConfig.DataRoot = globalConfig.DataRoot
+	tmConfig.ForceReclaimNetwork = globalConfig.ForceReclaim()
+	tmConfig.SocketWait = globalConfig.SocketWaitOptions()
+	tmConfig.DiskHeadroom = globalConfig.DiskHeadroom()
+	tmConfig.MTU = globalConfig.MTU
+	tmConfig.SocketDir = globalConfig.VMMSocketDir()
 
 	templateName := tmConfig.TemplateToBuild
 	if templatePrimitive, ok := globalConfig.Templates[templateName]; ok {
@@ -399,9 +584,10 @@ func ParseTemplateManagerConfig(configFile string) (*TemplateManagerConfig, erro
 	}
 
 	tmConfig.setDefaultVal()
-	// validate
-	if err := tmConfig.Validate(); err != nil {
-		return nil, fmt.Errorf("error validating template manager config: %w", err)
+	if tmConfig.MTU == 0 {
+		if tmConfig.MTU, err = network.HostDefaultMTU(); err != nil {
+			return nil, err
+		}
 	}
 	return &tmConfig, nil
 }
@@ -7,7 +7,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -53,6 +52,13 @@ func (s *Snapshot) startVMM(
 		return err
 	}
 
+	if pageSize := cfg.HugePage().Bytes(); pageSize != 0 {
+		if err := utils.CheckFreeHugePages(pageSize, cfg.MemoryMB<<20); err != nil {
+			telemetry.ReportCriticalError(childCtx, err)
+			return err
+		}
+	}
+
 	if err := utils.CreateDirAllIfNotExists(cfg.PrivateDir(cfg.DataRoot), 0o755); err != nil {
 		return err
 	}
@@ -72,11 +78,13 @@ func (s *Snapshot) startVMM(
 	var hypervisorCmd string
 	switch cfg.VmmType {
 	case config.FIRECRACKER:
-		hypervisorCmd = hypervisor.FirecrackerCmd(s.cfg.HypervisorBinaryPath, s.socketPath)
+		hypervisorCmd, err = hypervisor.FirecrackerCmd(s.cfg.HypervisorBinaryPath, s.socketPath, cfg.ExtraHypervisorArgs)
 	case config.CLOUDHYPERVISOR:
-		hypervisorCmd = hypervisor.CloudHypervisorCmd(s.cfg.HypervisorBinaryPath, s.socketPath)
+		hypervisorCmd, err = hypervisor.CloudHypervisorCmd(s.cfg.HypervisorBinaryPath, s.socketPath, cfg.ExtraHypervisorArgs)
 	default:
-		err := config.InvalidVmmType
+		err = config.InvalidVmmType
+	}
+	if err != nil {
 		telemetry.ReportCriticalError(childCtx, err)
 		return err
 	}
@@ -170,7 +178,7 @@ func (s *Snapshot) startVMM(
 	switch s.cfg.VmmType {
 	case config.FIRECRACKER:
 		// Wait for the FC process to start so we can use FC API
-		client, err := firecracker.WaitForSocket(childCtx, tracer, s.socketPath, consts.WaitTimeForHypervisorSocket)
+		client, err := firecracker.WaitForSocket(childCtx, tracer, s.socketPath, s.cfg.SocketWait)
 		if err != nil {
 			errMsg := fmt.Errorf("error waiting for vmm socket: %w", err)
 
@@ -178,7 +186,7 @@ func (s *Snapshot) startVMM(
 		}
 		s.vmm.Hypervisor = hypervisor.NewFirecracker(s.generateFcConfig(), client)
 	case config.CLOUDHYPERVISOR:
-		client, err := ch.WaitForSocket(childCtx, tracer, s.socketPath, consts.WaitTimeForHypervisorSocket)
+		client, err := ch.WaitForSocket(childCtx, tracer, s.socketPath, s.cfg.SocketWait)
 		if err != nil {
 			errMsg := fmt.Errorf("error waiting for vmm socket: %w", err)
 
@@ -196,90 +204,65 @@ func (s *Snapshot) startVMM(
 }
 
 func (s *Snapshot) generateFcConfig() *hypervisor.FcConfig {
-	kernelArgs := []string{
-		"reboot=k",
-		"panic=1",
-		"nomodules",
-		"ipv6.disable=1",
-		"random.trust_cpu=on",
-		"pci=off",
-		"i8042.nokbd i8042.noaux",
-		// client-ip,server-ip,gateway-ip,netmask,hostname,device,autoconf,dns0-ip
-		fmt.Sprintf("ip=%s::%s:%s:fc-instance:%s:off:8.8.8.8",
-			consts.GuestNetIPAddr,
-			consts.HostTapIPAddress,
-			consts.GuestNetIPMaskLong,
-			consts.GuestIfaceName,
-		),
+	var configDrivePath string
+	if s.cfg.ConfigDrive {
+		configDrivePath = s.cfg.PrivateConfigDrivePath(s.cfg.DataRoot)
 	}
-
-	if s.cfg.KernelDebugOutput {
-		kernelArgs = append(kernelArgs, "loglevel=6 console=ttyS0")
-	} else {
-		kernelArgs = append(kernelArgs, "loglevel=1 quiet")
-	}
-
-	// If want to check what's happening during boot
-	// use the following commented kernel args
-	// kernelArgs := fmt.Sprintf("quiet loglevel=6 console=ttyS0 ip=%s reboot=k panic=1 pci=off nomodules i8042.nokbd i8042.noaux ipv6.disable=1 random.trust_cpu=on overlay_root=vdb init=%s", ip, constants.OverlayInitPath)
-	if s.cfg.Overlay {
-		kernelArgs = append(kernelArgs, "overlay_root=vdb init="+constants.OverlayInitPath)
+	var extraDiskPaths []string
+	for i := 0; i < s.cfg.ExtraDiskSlots; i++ {
+		extraDiskPaths = append(extraDiskPaths, s.cfg.PrivateExtraDiskPath(s.cfg.DataRoot, i))
 	}
 	return &hypervisor.FcConfig{
 		VcpuCount:          s.cfg.VCpuCount,
 		MemoryMB:           s.cfg.MemoryMB,
 		KernelImagePath:    s.cfg.PrivateKernelPath(s.cfg.DataRoot),
-		KernelBootCmd:      strings.Join(kernelArgs, " "),
+		KernelBootCmd:      s.cfg.KernelArgs(s.cfg.KernelDebugOutput),
 		EnableDiffSnapshot: true,
 		EnableOverlayFS:    s.cfg.Overlay,
+		ReadOnlyRootfs:     s.cfg.ImmutableRootfs,
 		RootfsPath:         s.cfg.PrivateRootfsPath(s.cfg.DataRoot),
 		WritableRootfsPath: s.cfg.PrivateWritableRootfsPath(s.cfg.DataRoot),
 		TapDevName:         consts.HostTapName,
 		GuestNetIfaceName:  consts.GuestIfaceName,
 		GuestNetMacAddr:    consts.GuestMacAddress,
-		EnableHugepage:     s.cfg.HugePages,
+		HugePageSize:       s.cfg.HugePage().Bytes(),
+		CpuTemplate:        s.cfg.CpuTemplate,
+		ConfigDrivePath:    configDrivePath,
+		ExtraDiskPaths:     extraDiskPaths,
+		EnableBalloon:      s.cfg.Balloon,
+		DiskRateLimit:      s.diskRateLimit(),
 	}
 }
 
 func (s *Snapshot) generateChConfig() *hypervisor.ChConfig {
-	kernelArgs := []string{
-		"reboot=k",
-		"nomodules",
-		"ipv6.disable=1",
-		"random.trust_cpu=on",
-		// client-ip,server-ip,gateway-ip,netmask,hostname,device,autoconf,dns0-ip
-		fmt.Sprintf("ip=%s::%s:%s:ch-instance:%s:off:8.8.8.8",
-			consts.GuestNetIPAddr,
-			consts.HostTapIPAddress,
-			consts.GuestNetIPMaskLong,
-			consts.GuestIfaceName,
-		),
-	}
-	if s.cfg.KernelDebugOutput {
-		kernelArgs = append(kernelArgs, "loglevel=6 console=hvc0")
-	} else {
-		kernelArgs = append(kernelArgs, "loglevel=1 quiet panic=1")
-	}
-	if s.cfg.Overlay {
-		kernelArgs = append(kernelArgs,
-			"root=/dev/pmem0 ro rootflags=dax=always",
-			"overlay_root=vda init="+constants.OverlayInitPath,
-			// "overlay_root=pmem1 overlay_root_flags=dax=always init="+constants.OverlayInitPath,
-		)
-	} else {
-		kernelArgs = append(kernelArgs, "root=/dev/pmem0 rw rootflags=dax=always")
+	var configDrivePath string
+	if s.cfg.ConfigDrive {
+		configDrivePath = s.cfg.PrivateConfigDrivePath(s.cfg.DataRoot)
 	}
 	return &hypervisor.ChConfig{
 		VcpuCount:          s.cfg.VCpuCount,
 		MemoryMB:           s.cfg.MemoryMB,
+		MemoryHotplugMB:    s.cfg.MemoryHotplugMB,
 		KernelImagePath:    s.cfg.PrivateKernelPath(s.cfg.DataRoot),
-		KernelBootCmd:      strings.Join(kernelArgs, " "),
+		KernelBootCmd:      s.cfg.KernelArgs(s.cfg.KernelDebugOutput),
 		EnableOverlayFS:    s.cfg.Overlay,
+		ReadOnlyRootfs:     s.cfg.ImmutableRootfs,
 		RootfsPath:         s.cfg.PrivateRootfsPath(s.cfg.DataRoot),
 		WritableRootfsPath: s.cfg.PrivateWritableRootfsPath(s.cfg.DataRoot),
 		TapDevName:         consts.HostTapName,
 		GuestNetMacAddr:    consts.GuestMacAddress,
-		EnableHugepage:     s.cfg.HugePages,
+		HugePageSize:       s.cfg.HugePage().Bytes(),
+		ConfigDrivePath:    configDrivePath,
+		EnableBalloon:      s.cfg.Balloon,
+		DiskRateLimit:      s.diskRateLimit(),
+	}
+}
+
+// The disk limits of template, which are kept in the snapshot.
+func (s *Snapshot) diskRateLimit() hypervisor.DiskRateLimit {
+	return hypervisor.DiskRateLimit{
+		BandwidthBps: s.cfg.DiskBandwidthBps,
+		Iops:         s.cfg.DiskIops,
 	}
 }
 
@@ -350,23 +333,30 @@ func NewSnapshot(
 	}(); err != nil {
 		return nil, err
 	}
-	// Wait for all necessary things in FC to start
-	// TODO: Maybe init should signalize when it's ready?
-	time.Sleep(constants.WaitTimeForVmStart)
-	telemetry.ReportEvent(
-		childCtx,
-		"waited for sandbox to start",
-		attribute.Float64("seconds",
-			float64(constants.WaitTimeForVmStart/time.Second)),
-	)
+	if probe := cfg.ReadinessProbe; probe != nil {
+		if err := waitForReadiness(childCtx, tracer, network, probe, cfg.EnvdServerPort()); err != nil {
+			errMsg := fmt.Errorf("error waiting for readiness: %w", err)
 
-	if cfg.StartCmd.Cmd != "" {
-		time.Sleep(constants.WaitTimeForStartCmd)
+			return nil, errMsg
+		}
+	} else {
+		// Wait for all necessary things in FC to start
+		time.Sleep(constants.WaitTimeForVmStart)
 		telemetry.ReportEvent(
 			childCtx,
-			"waited for start command",
-			attribute.Float64("seconds", float64(constants.WaitTimeForStartCmd/time.Second)),
+			"waited for sandbox to start",
+			attribute.Float64("seconds",
+				float64(constants.WaitTimeForVmStart/time.Second)),
 		)
+
+		if cfg.StartCmd.Cmd != "" {
+			time.Sleep(constants.WaitTimeForStartCmd)
+			telemetry.ReportEvent(
+				childCtx,
+				"waited for start command",
+				attribute.Float64("seconds", float64(constants.WaitTimeForStartCmd/time.Second)),
+			)
+		}
 	}
 
 	err = snapshot.vmm.Pause(childCtx)
@@ -378,7 +368,8 @@ func NewSnapshot(
 
 	{
 		ctx, span := tracer.Start(childCtx, "snapshot-vm")
-		err = snapshot.vmm.Snapshot(ctx, cfg.PrivateDir(cfg.DataRoot))
+		dir := cfg.PrivateDir(cfg.DataRoot)
+		_, err = hypervisor.SnapshotVerified(ctx, snapshot.vmm.Hypervisor, dir, dir, cfg.SnapshotAttempts)
 		span.End()
 		if err != nil {
 			errMsg := fmt.Errorf("error snapshotting vmm: %w", err)
@@ -1,8 +1,12 @@
 package constants
 
+import "github.com/X-code-interpreter/sandbox-backend/packages/shared/consts"
+
 const (
-	OverlayInitPath = "/sbin/overlay-init"
+	OverlayInitPath = consts.GuestOverlayInitPath
+	// The init (in guest) mounting the writable dirs when immutable rootfs
+	ImmutableInitPath = consts.GuestImmutableInitPath
 
-  // The environment file (in guest) for start cmd
+	// The environment file (in guest) for start cmd
 	StartCmdEnvFilePath = "/home/user/start_cmd.conf"
 )
@@ -29,15 +29,23 @@ func Fatalf(format string, a ...any) {
 // a long-running template-manager, so we use it as a one-shot binary
 func main() {
 	var (
-		cfgPath string
-		start   = time.Now()
+		cfgPath      string
+		forceRebuild bool
+		validateOnly bool
+		start        = time.Now()
 	)
 	flag.StringVar(&cfgPath, "config", "", "path to the template configuration files (e.g., /path/to/config.toml)")
+	flag.BoolVar(&forceRebuild, "force-rebuild", false, "rebuild the rootfs even if the cached one is built from the same docker image and provision")
+	flag.BoolVar(&validateOnly, "validate-only", false, "validate the configuration and report all the problems, without building anything")
 	flag.Parse()
+	if validateOnly {
+		os.Exit(validateConfig(cfgPath))
+	}
 	cfg, err := build.ParseTemplateManagerConfig(cfgPath)
 	if err != nil {
 		Fatal("cannot parse configuration file: ", err)
 	}
+	cfg.ForceRebuild = forceRebuild
 
 	// init otel environment
 	ctx := context.Background()
@@ -67,3 +75,21 @@ func main() {
 	}
 	fmt.Printf("build succeed: take %s", time.Since(start))
 }
+
+// Print the problems of the configuration, return the exit code.
+func validateConfig(cfgPath string) int {
+	cfg, problems, err := build.LintTemplateManagerConfig(cfgPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cannot parse configuration file:", err)
+		return 1
+	}
+	if len(problems) == 0 {
+		fmt.Printf("PASS: template %s\n", cfg.TemplateID)
+		return 0
+	}
+	fmt.Fprintf(os.Stderr, "FAIL: template %s, %d problem(s) found\n", cfg.TemplateID, len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+	}
+	return 1
+}